@@ -0,0 +1,66 @@
+// Command libecdsaaffine is the C shared library build of the recovery math, for
+// forensic frameworks that would rather call it than run the CLI:
+//
+//	go build -buildmode=c-shared -o libecdsaaffine.so ./cmd/libecdsaaffine
+//
+// writes the library and its header, libecdsaaffine.h. Both functions take and return
+// NUL-terminated UTF-8 JSON; every string returned must be given back to
+// ecdsa_affine_free.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"unsafe"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/jsonapi"
+)
+
+// recover_ecdsa_affine recovers an ECDSA key from signatures_json, an array of
+// signature objects as in a signatures file (r, s and z or message). public_key_hex
+// may be NULL or empty; when given, the key is verified against it. The result is the
+// response of internal/jsonapi: the key, or an "error" field.
+//
+//export recover_ecdsa_affine
+func recover_ecdsa_affine(signaturesJSON, publicKeyHex *C.char) *C.char {
+	signatures := []byte(goString(signaturesJSON))
+	if !json.Valid(signatures) {
+		response, _ := json.Marshal(jsonapi.Response{Error: "signatures are not valid JSON"})
+		return C.CString(string(response))
+	}
+	request, _ := json.Marshal(jsonapi.Request{
+		Curve:      "ecdsa",
+		Signatures: signatures,
+		PublicKey:  goString(publicKeyHex),
+	})
+	return C.CString(string(jsonapi.Recover(context.Background(), request)))
+}
+
+// ecdsa_affine_recover takes a full request of internal/jsonapi (curve, signatures,
+// public_key, strategy, timeout_ms), for EdDSA or another strategy.
+//
+//export ecdsa_affine_recover
+func ecdsa_affine_recover(requestJSON *C.char) *C.char {
+	return C.CString(string(jsonapi.Recover(context.Background(), []byte(goString(requestJSON)))))
+}
+
+// ecdsa_affine_free releases a string returned by the library.
+//
+//export ecdsa_affine_free
+func ecdsa_affine_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+func goString(s *C.char) string {
+	if s == nil {
+		return ""
+	}
+	return C.GoString(s)
+}
+
+func main() {}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// runAnalyze implements "recovery analyze": reports what a dataset looks like and which
+// strategies are worth running, without attempting recovery.
+func runAnalyze(args []string) {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	var (
+		signaturesFile = fs.String("signatures", "", "Path or URL (https://, s3://, gs://) of signatures file (JSON, CSV or local signature store)")
+		format         = fs.String("format", "json", "Signature file format (json, csv, store, pkcs11 or keystore)")
+		estimate       = fs.Bool("estimate", true, "Calibrate the search rate and estimate the smart-brute worst case for the largest signer")
+		maxPairs       = fs.Int("max-pairs", 100, "Maximum signature pairs to test in brute-force, for the estimate")
+		jsonOutput     = fs.Bool("json", false, "Print the analysis as JSON")
+		emitPatterns   = fs.String("emit-patterns", "", "Write the patterns the analysis suggests, ranked by confidence, to this pattern file for --patterns")
+	)
+	filters := addFilterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery analyze --signatures <file> [--format json|csv|store|pkcs11|keystore]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *signaturesFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: --signatures is required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	signatures, err := filters.apply(newParser(*format)).ParseSignatures(*signaturesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	analysis := ecdsaaffine.AnalyzeDataset(signatures)
+	if *emitPatterns != "" {
+		if err := writePatternFile(*emitPatterns, ecdsaaffine.SuggestPatterns(signatures)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(analysis); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	analysis.WriteText(os.Stdout)
+
+	largest := 0
+	for _, signer := range analysis.Signers {
+		largest = max(largest, signer.Signatures)
+	}
+	if !*estimate || largest < 2 {
+		return
+	}
+	for _, strategy := range analysis.Strategies {
+		if strategy.Name == "smart-brute" && !strategy.Worthwhile {
+			return
+		}
+	}
+
+	fmt.Printf("\nSmart-brute estimate for the largest signer (%d signatures):\n", largest)
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	config := ecdsaaffine.DefaultRangeConfig()
+	config.MaxPairs = *maxPairs
+	printEstimate(ecdsaaffine.EstimateSearch(config, largest), largest)
+}
+
+// writePatternFile writes suggestions to path as a pattern file, and says how many to
+// stderr, which keeps stdout for the analysis.
+func writePatternFile(path string, suggestions []ecdsaaffine.PatternSuggestion) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ecdsaaffine.WritePatternFile(f, suggestions); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d suggested patterns to %s\n", len(suggestions), path)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/eddsaaffine"
+)
+
+// runBench implements "recovery bench": measures this machine's brute-force search rate
+// and estimates how long typical ranges take.
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var (
+		curve    = fs.String("curve", "ecdsa", "Signature scheme to measure (ecdsa or eddsa)")
+		duration = fs.Duration("duration", 3*time.Second, "How long to measure")
+		workers  = fs.Int("workers", 0, "Number of parallel workers (0 = auto-detect based on CPU cores)")
+		pairs    = fs.Int("max-pairs", 100, "Signature pairs per search, for the estimates")
+	)
+	fs.Parse(args)
+
+	// The search logs its own progress; only the summary matters here
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	fmt.Printf("Measuring %s search rate for %v...\n", *curve, *duration)
+	ctx := context.Background()
+
+	var rate ecdsaaffine.SearchRate
+	switch *curve {
+	case "ecdsa":
+		rate = ecdsaaffine.MeasureSearchRate(ctx, *duration, *workers)
+	case "eddsa":
+		r := eddsaaffine.MeasureSearchRate(ctx, *duration, *workers)
+		rate = ecdsaaffine.SearchRate{Workers: r.Workers, Candidates: r.Candidates, Elapsed: r.Elapsed}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --curve must be ecdsa or eddsa\n")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Workers:    %d\n", rate.Workers)
+	fmt.Printf("Candidates: %d in %v\n", rate.Candidates, rate.Elapsed.Round(time.Millisecond))
+	fmt.Printf("Rate:       %.0f candidates/sec\n", rate.PerSecond())
+	if rate.PerSecond() == 0 {
+		return
+	}
+
+	fmt.Printf("\nEstimated worst-case time over %d signature pairs:\n", *pairs)
+	for _, r := range []struct {
+		aRange [2]int
+		bRange [2]int
+	}{
+		{[2]int{1, 1}, [2]int{-1000, 10000}},
+		{[2]int{1, 10}, [2]int{-5000, 50000}},
+		{[2]int{1, 100}, [2]int{-100000, 1000000}},
+		{[2]int{1, 100}, [2]int{-500000, 500000000}},
+	} {
+		candidates := float64(r.aRange[1]-r.aRange[0]+1) * float64(r.bRange[1]-r.bRange[0]+1) * float64(*pairs)
+		fmt.Printf("  a in [%d, %d], b in [%d, %d]: %v\n",
+			r.aRange[0], r.aRange[1], r.bRange[0], r.bRange[1], rate.Estimate(candidates).Round(time.Second))
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/vulnclass"
+)
+
+// classification writes the vulnerability classification of a run as JSON. A nil
+// classification writes nothing.
+type classification struct {
+	path           string
+	signaturesFile string
+	parser         ecdsaaffine.SignatureParser
+}
+
+// write classifies the dataset with result, which is nil if the search failed.
+func (c *classification) write(result *ecdsaaffine.RecoveryResult) error {
+	if c == nil {
+		return nil
+	}
+	signatures, err := c.parser.ParseSignatures(c.signaturesFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	evidence := vulnclass.Evidence{
+		Dataset:  c.signaturesFile,
+		Analysis: ecdsaaffine.AnalyzeDataset(signatures),
+		Result:   result,
+	}
+	if result != nil {
+		if evidence.Nonces, err = ecdsaaffine.RecoverNonces(result, signatures); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(vulnclass.Classify(evidence), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// runCompare implements "recovery compare": checks a fix for a nonce flaw with signatures
+// by the same key from before and after it. It exits with status 1 if the flaw persists.
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	var (
+		beforeFile = fs.String("before", "", "Signatures made before the fix (path or URL; JSON, CSV or local signature store)")
+		afterFile  = fs.String("after", "", "Signatures made after the fix by the same key")
+		format     = fs.String("format", "json", "Signature file format (json, csv, store, pkcs11 or keystore)")
+		publicKey  = fs.String("public-key", "", "Key to verify against (optional, as for recovery)")
+		maxPairs   = fs.Int("max-pairs", 100, "Maximum signature pairs to test in brute-force")
+		jsonOutput = fs.Bool("json", false, "Print the comparison as JSON")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery compare --before <file> --after <file> [--public-key <key>] [--format json|csv|store|pkcs11|keystore]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *beforeFile == "" || *afterFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: --before and --after are required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	parser := newParser(*format)
+	before, err := parser.ParseSignatures(*beforeFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	after, err := parser.ParseSignatures(*afterFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	strategy := ecdsaaffine.NewSmartBruteForceStrategy()
+	strategy.RangeConfig.MaxPairs = *maxPairs
+	client := ecdsaaffine.NewClient(ecdsaaffine.WithStrategy(strategy))
+	comparison, err := client.CompareDatasets(context.Background(), before, after, *publicKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(comparison); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		comparison.WriteText(os.Stdout)
+	}
+	if comparison.Verdict == ecdsaaffine.RemediationVulnerable {
+		os.Exit(1)
+	}
+}
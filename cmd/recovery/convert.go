@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// runConvert implements "recovery convert": signatures in any format to a signature
+// store, a store with the signer context (a binary fixture), or JSON.
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	var (
+		in        = fs.String("in", "", "Input signatures file or URL")
+		format    = fs.String("format", "json", "Input format (json, csv, store, pkcs11 or keystore)")
+		out       = fs.String("out", "", "Output path (- writes JSON to stdout)")
+		to        = fs.String("to", "store", "Output format: store (z, r and s only), fixture (a store keeping public keys, recovery ids, timestamps, sequences and block heights) or json")
+		publicKey = fs.String("public-key", "", "Public key in hex format (compressed, uncompressed or raw X||Y) to record in the store")
+	)
+	filters := addFilterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery convert --in <file> --out <file> [--format json|csv|store|pkcs11|keystore] [--to store|fixture|json]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *in == "" || *out == "" {
+		fmt.Fprintf(os.Stderr, "Error: --in and --out are required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *to != "store" && *to != "fixture" && *to != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --to must be store, fixture or json\n")
+		os.Exit(1)
+	}
+	if *to == "json" && *publicKey != "" {
+		fmt.Fprintf(os.Stderr, "Error: --public-key is recorded in stores only\n")
+		os.Exit(1)
+	}
+	if *out == "-" && *to != "json" {
+		fmt.Fprintf(os.Stderr, "Error: only --to json can write to stdout\n")
+		os.Exit(1)
+	}
+
+	var pubKey []byte
+	if *publicKey != "" {
+		var err error
+		pubKey, err = ecdsaaffine.ParsePublicKeyHex(*publicKey)
+		if err == nil && len(pubKey) != 33 {
+			err = fmt.Errorf("a signature store records a public key, not an address or key set")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	signatures, err := filters.apply(newParser(*format)).ParseSignatures(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	switch *to {
+	case "json":
+		err = writeSignaturesJSON(*out, signatures)
+	case "fixture":
+		err = ecdsaaffine.WriteSignatureStore(*out, signatures, pubKey, ecdsaaffine.WithStoreSignerContext())
+	default:
+		err = ecdsaaffine.WriteSignatureStore(*out, signatures, pubKey)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *out != "-" {
+		fmt.Printf("Wrote %d signatures to %s\n", len(signatures), *out)
+	}
+}
+
+// writeSignaturesJSON writes signatures as JSON to path, or to stdout for "-".
+func writeSignaturesJSON(path string, signatures []*ecdsaaffine.Signature) error {
+	if path == "-" {
+		return ecdsaaffine.WriteSignaturesJSON(os.Stdout, signatures)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ecdsaaffine.WriteSignaturesJSON(file, signatures); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
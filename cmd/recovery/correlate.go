@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
+)
+
+// runCorrelate implements "recovery correlate": fingerprints the nonce generator of each
+// recovered key and clusters keys that share one, pointing at a common flawed signer.
+func runCorrelate(args []string) {
+	fs := flag.NewFlagSet("correlate", flag.ExitOnError)
+	var (
+		format     = fs.String("format", "json", "Signature file format (json, csv, store, pkcs11 or keystore)")
+		jsonOutput = fs.Bool("json", false, "Print the fingerprints and clusters as JSON")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery correlate [--format json|csv|store|pkcs11|keystore] <signatures>=<private key> ...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintf(os.Stderr, "Error: at least two <signatures>=<private key> datasets are required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	parser := newParser(*format)
+	var fingerprints []nonceanalysis.Fingerprint
+	for _, arg := range fs.Args() {
+		i := strings.LastIndex(arg, "=")
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "Error: %q: want <signatures>=<private key>\n", arg)
+			os.Exit(1)
+		}
+		file := arg[:i]
+		key, ok := new(big.Int).SetString(arg[i+1:], 0)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: %s: invalid private key (want decimal or 0x hex)\n", file)
+			os.Exit(1)
+		}
+
+		signatures, err := parser.ParseSignatures(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for j, sig := range signatures {
+			if !ecdsaaffine.NonceMatchesR(sig, key) {
+				fmt.Fprintf(os.Stderr, "Error: %s: signature %d was not made by this key\n", file, j)
+				os.Exit(1)
+			}
+		}
+		nonces, err := ecdsaaffine.RecoverNonces(&ecdsaaffine.RecoveryResult{PrivateKey: key}, signatures)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		fingerprints = append(fingerprints, nonceanalysis.FingerprintNonces(file, nonces, ecdsaaffine.Secp256k1CurveOrder, nonceanalysis.DefaultOptions()))
+	}
+
+	correlation := nonceanalysis.Correlate(fingerprints)
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(correlation); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	correlation.WriteText(os.Stdout)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/pyref"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// crossCheckPython re-signs every signature's hash with the recovered key and its
+// computed nonce using the reference Python signer in scripts, and reports on stderr
+// whether all of them come out identical. A difference means the Go and Python
+// conventions (hashing, encodings) have drifted apart, or a nonce is wrong.
+func crossCheckPython(scripts string, key *big.Int, signatures []*ecdsaaffine.Signature, nonces []*big.Int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	hashes := make([]*big.Int, len(signatures))
+	for i, sig := range signatures {
+		hashes[i] = sig.Z
+	}
+	ref, err := pyref.Find(scripts)
+	var data []byte
+	if err == nil {
+		data, err = ref.SignECDSAHashes(ctx, key, hashes, nonces)
+	}
+	var reference []struct {
+		R json.Number `json:"r"`
+		S json.Number `json:"s"`
+	}
+	if err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&reference)
+	}
+	if err == nil && len(reference) != len(signatures) {
+		err = fmt.Errorf("got %d signatures for %d", len(reference), len(signatures))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cross-check against the Python reference signer: %v\n", err)
+		return false
+	}
+
+	var differ []int
+	for i, sig := range signatures {
+		r, _ := new(big.Int).SetString(reference[i].R.String(), 10)
+		s, _ := new(big.Int).SetString(reference[i].S.String(), 10)
+		if r == nil || s == nil || r.Cmp(sig.R) != 0 || s.Cmp(sig.S) != 0 {
+			differ = append(differ, i)
+		}
+	}
+	if len(differ) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: cross-check: %d of %d signatures differ from the Python reference signer: %v\n", len(differ), len(signatures), differ)
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Cross-check: all %d signatures reproduced by the Python reference signer\n", len(signatures))
+	return true
+}
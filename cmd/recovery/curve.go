@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// curveFlags are the flags of the main command that work with --curve; the others
+// assume secp256k1 (point formats, addresses, nonce reports, the fast search engines).
+var curveFlags = map[string]bool{
+	"curve": true, "signatures": true, "format": true, "plugin": true, "public-key": true,
+	"known-a": true, "known-b": true, "known-nonce": true, "nonce-index": true,
+	"smart-brute": true, "pairs": true, "max-pairs": true, "timeout": true, "json": true,
+	"audit-log": true, "notify-url": true,
+	"since": true, "until": true, "min-block": true, "max-block": true, "message-prefix": true,
+}
+
+// checkCurveFlags returns an error naming the flags set on fs that --curve cannot honour.
+func checkCurveFlags(fs *flag.FlagSet) error {
+	var unsupported []string
+	fs.Visit(func(f *flag.Flag) {
+		if !curveFlags[f.Name] {
+			unsupported = append(unsupported, "--"+f.Name)
+		}
+	})
+	if len(unsupported) == 0 {
+		return nil
+	}
+	sort.Strings(unsupported)
+	return fmt.Errorf("--curve cannot be combined with %s (it supports --known-a/--known-b, --known-nonce and --smart-brute)", strings.Join(unsupported, ", "))
+}
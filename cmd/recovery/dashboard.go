@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/eta"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/metrics"
+)
+
+// dashboardRefresh is how often the dashboard redraws.
+const dashboardRefresh = 250 * time.Millisecond
+
+// dashboard is the --tui progress display for long searches. It replaces the search's
+// log lines with a panel redrawn in place on stderr from the search metrics, and reads
+// single-key commands from the terminal: s skips the current phase, q cancels the search.
+type dashboard struct {
+	metrics       *metrics.Search
+	numSignatures int
+	skip          chan<- struct{}
+	cancel        context.CancelFunc
+
+	mu     sync.Mutex
+	phases map[string]float64 // candidates per phase, from the search estimates (see Plan)
+	status string
+
+	started time.Time
+	lines   int // lines drawn by the previous frame
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startDashboard starts drawing the search of numSignatures signatures. skip and cancel
+// are triggered by the s and q keys.
+func startDashboard(m *metrics.Search, numSignatures int, skip chan<- struct{}, cancel context.CancelFunc) (*dashboard, error) {
+	if info, err := os.Stderr.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil, fmt.Errorf("--tui needs a terminal on stderr")
+	}
+
+	d := &dashboard{
+		metrics:       m,
+		numSignatures: numSignatures,
+		skip:          skip,
+		cancel:        cancel,
+		phases:        make(map[string]float64),
+		started:       time.Now(),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	log.SetOutput(io.Discard)
+	restore := rawTerminal()
+	go d.readKeys()
+	go func() {
+		defer close(d.done)
+		defer restore()
+		d.run()
+	}()
+	return d, nil
+}
+
+// Plan adds the phases of a search with config to those the dashboard can show
+// progress and ETA for. Phases already planned keep their size.
+func (d *dashboard) Plan(config ecdsaaffine.RangeConfig) {
+	if d == nil {
+		return
+	}
+	estimate := ecdsaaffine.EstimateSearchWithRate(config, d.numSignatures, ecdsaaffine.SearchRate{})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, phase := range estimate.Phases {
+		if _, ok := d.phases[phase.Phase]; !ok {
+			d.phases[phase.Phase] = phase.Candidates
+		}
+	}
+}
+
+// setStatus shows a message under the panel.
+func (d *dashboard) setStatus(status string) {
+	d.mu.Lock()
+	d.status = status
+	d.mu.Unlock()
+}
+
+// Stop stops drawing and restores the terminal and the log output. It is safe to
+// call on a nil dashboard.
+func (d *dashboard) Stop() {
+	if d == nil {
+		return
+	}
+	close(d.stop)
+	<-d.done
+	log.SetOutput(os.Stderr)
+}
+
+// run redraws the panel until Stop.
+func (d *dashboard) run() {
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
+
+	var (
+		phase          string
+		phaseBase      int64 // candidates counter when the phase started
+		lastCandidates int64
+		lastTick       = time.Now()
+		rate           float64
+	)
+	for {
+		snapshot := d.metrics.Snapshot()
+		if snapshot.Phase != phase {
+			phase, phaseBase = snapshot.Phase, snapshot.Candidates
+		}
+
+		// Exponentially smoothed throughput, so the ETA does not jump with every batch
+		now := time.Now()
+		if elapsed := now.Sub(lastTick).Seconds(); elapsed > 0 {
+			current := float64(snapshot.Candidates-lastCandidates) / elapsed
+			if rate == 0 {
+				rate = current
+			} else {
+				rate = 0.7*rate + 0.3*current
+			}
+		}
+		lastCandidates, lastTick = snapshot.Candidates, now
+
+		d.draw(snapshot, snapshot.Candidates-phaseBase, rate)
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// draw renders one frame over the previous one.
+func (d *dashboard) draw(snapshot metrics.Snapshot, phaseCandidates int64, rate float64) {
+	var sb strings.Builder
+	if d.lines > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", d.lines)
+	}
+	line := func(format string, args ...any) {
+		sb.WriteString("\x1b[2K")
+		fmt.Fprintf(&sb, format, args...)
+		sb.WriteString("\n")
+	}
+
+	line("ECDSA affine search, %v elapsed    [s] skip phase  [q] cancel", time.Since(d.started).Round(time.Second))
+	phase := snapshot.Phase
+	if phase == "" {
+		phase = "starting"
+	}
+	running := time.Duration(0)
+	if !snapshot.PhaseStarted.IsZero() {
+		running = time.Since(snapshot.PhaseStarted).Round(time.Second)
+	}
+	line("  Phase:       %s (running %v)", phase, running)
+
+	d.mu.Lock()
+	total, known := d.phases[snapshot.Phase]
+	status := d.status
+	d.mu.Unlock()
+	if known && total > 0 && phaseCandidates > 0 {
+		done := min(float64(phaseCandidates)/total, 1)
+		const width = 30
+		filled := int(done * width)
+		line("  Progress:    %s%s %5.1f%%  %.3g / %.3g candidates",
+			strings.Repeat("█", filled), strings.Repeat("░", width-filled), done*100, float64(phaseCandidates), total)
+	} else {
+		line("  Progress:    %d candidates", phaseCandidates)
+	}
+	line("  Throughput:  %.0f candidates/sec (%d workers)", rate, snapshot.Workers)
+	phaseETA := "unknown"
+	if known && rate > 0 {
+		remaining := max(total-float64(phaseCandidates), 0)
+		phaseETA = eta.Duration(remaining, rate).Round(time.Second).String()
+	}
+	line("  Phase ETA:   %s", phaseETA)
+	line("  Pairs:       %d completed", snapshot.Pairs)
+	if status != "" {
+		line("  %s", status)
+	}
+
+	d.lines = strings.Count(sb.String(), "\n")
+	io.WriteString(os.Stderr, sb.String())
+}
+
+// readKeys handles key presses until the process exits.
+func (d *dashboard) readKeys() {
+	buf := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(buf); err != nil {
+			return
+		}
+		switch buf[0] {
+		case 's', 'S':
+			// Only a running phase listens; a press between phases does nothing
+			phase := d.metrics.Snapshot().Phase
+			select {
+			case d.skip <- struct{}{}:
+				d.setStatus("Skipped " + phase)
+			default:
+			}
+		case 'q', 'Q', 3: // 3 is Ctrl-C with the terminal in raw mode
+			d.setStatus("Cancelling...")
+			d.cancel()
+		}
+	}
+}
+
+// rawTerminal switches the terminal to unbuffered input without echo, so keys arrive
+// without Enter, and returns a function restoring it. Without stty, keys need Enter.
+func rawTerminal() (restore func()) {
+	state, err := stty("-g")
+	if err != nil {
+		return func() {}
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return func() {}
+	}
+	return func() { stty(strings.TrimSpace(state)) }
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/eddsaaffine"
+)
+
+// runDiagnose implements "recovery diagnose": given one Ed25519 signature, from flags or
+// from a signatures file, reports which reading of its fields verifies and how to make
+// the dataset match what the EdDSA parser expects.
+func runDiagnose(args []string) {
+	fs := flag.NewFlagSet("diagnose", flag.ExitOnError)
+	var (
+		signaturesFile = fs.String("signatures", "", "EdDSA signatures file (JSON) to take the signature from")
+		index          = fs.Int("index", 0, "Index in --signatures of the signature to diagnose")
+		r              = fs.String("r", "", "R as written in the dataset")
+		s              = fs.String("s", "", "S as written in the dataset")
+		signature      = fs.String("signature", "", "The 64-byte signature R || S in hex, instead of --r and --s")
+		message        = fs.String("message", "", "Message as written in the dataset")
+		publicKey      = fs.String("public-key", "", "Ed25519 public key (hex, OpenSSH, PEM or JWK); overrides the file's public_key")
+		jsonOutput     = fs.Bool("json", false, "Print the diagnosis as JSON")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery diagnose --signatures file [--index n] [--public-key key]\n")
+		fmt.Fprintf(os.Stderr, "       recovery diagnose (--r r --s s | --signature sig) --message m --public-key key\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	fields := eddsaaffine.SignatureFields{R: *r, S: *s, Signature: *signature, Message: *message}
+	if *signaturesFile != "" {
+		var err error
+		if fields, err = readSignatureFields(*signaturesFile, *index); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *publicKey != "" {
+		fields.PublicKey = *publicKey
+	}
+
+	d, err := eddsaaffine.Diagnose(fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(d)
+	} else {
+		printDiagnosis(d)
+	}
+	if d.Match == nil {
+		os.Exit(1)
+	}
+}
+
+// readSignatureFields returns the fields of the signature at index in a JSON signatures
+// file, as written there.
+func readSignatureFields(path string, index int) (eddsaaffine.SignatureFields, error) {
+	var fields eddsaaffine.SignatureFields
+	file, err := source.Open(path)
+	if err != nil {
+		return fields, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	var items []map[string]interface{}
+	if err := decoder.Decode(&items); err != nil {
+		return fields, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if index < 0 || index >= len(items) {
+		return fields, fmt.Errorf("--index %d is out of range: the file has %d signatures", index, len(items))
+	}
+
+	field := func(name string) string {
+		if v, ok := items[index][name]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	return eddsaaffine.SignatureFields{
+		R:         field("r"),
+		S:         field("s"),
+		Signature: field("signature"),
+		Message:   field("message"),
+		PublicKey: field("public_key"),
+	}, nil
+}
+
+func printDiagnosis(d *eddsaaffine.Diagnosis) {
+	fmt.Printf("Tried %d interpretations\n", len(d.Tried))
+	if d.Parser.Verifies && len(d.Fixes) == 0 {
+		fmt.Println("[+] The signature verifies as the EdDSA parser reads it; no changes needed")
+		return
+	}
+	if d.Match == nil {
+		fmt.Println("[-] No interpretation verifies: the message, public key or signature is not the one signed,")
+		fmt.Println("    or the signer does not use standard Ed25519 (SHA-512 over R || A || M)")
+		return
+	}
+
+	m := d.Match
+	if d.Parser.Verifies {
+		fmt.Println("[+] The signature verifies as the EdDSA parser reads it")
+	} else {
+		fmt.Println("[!] The signature does not verify as the EdDSA parser reads it")
+	}
+	fmt.Printf("    It verifies with r as %s, s as %s, the message as %s", m.R, m.S, m.Message)
+	if m.PublicKeyReversed {
+		fmt.Printf(", the public key reversed")
+	}
+	fmt.Println()
+	if d.Endianness == eddsaaffine.LittleEndian {
+		fmt.Println("    r and s are little-endian: read the dataset with --endianness little")
+	}
+	fmt.Println("\nFixes:")
+	for _, fix := range d.Fixes {
+		fmt.Printf("  - %s\n", fix)
+	}
+	fmt.Println("\nThe signature as the parser expects it:")
+	data, _ := json.MarshalIndent(d.Fixed, "  ", "  ")
+	fmt.Printf("  %s\n", data)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/auditlog"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/engagement"
+)
+
+// engagementReport writes the engagement report of a run, Markdown for a .md path and
+// HTML otherwise. A nil report writes nothing.
+type engagementReport struct {
+	path           string
+	signaturesFile string
+	parser         ecdsaaffine.SignatureParser
+}
+
+// write renders the report of result, or of a search that failed with searchErr. The
+// dataset is read again for its analysis and the evidence for the key.
+func (e *engagementReport) write(result *ecdsaaffine.RecoveryResult, searchErr error) error {
+	if e == nil {
+		return nil
+	}
+	config := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		config[f.Name] = f.Value.String()
+	})
+	report := &engagement.Report{
+		Dataset:     e.signaturesFile,
+		ToolVersion: toolVersion(),
+		Config:      config,
+		Result:      result,
+		Err:         searchErr,
+	}
+	if fingerprint, err := auditlog.FingerprintFile(e.signaturesFile); err == nil {
+		report.DatasetSHA256 = fingerprint
+	}
+	signatures, err := e.parser.ParseSignatures(e.signaturesFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	report.Analysis = ecdsaaffine.AnalyzeDataset(signatures)
+	if err := report.AddEvidence(signatures); err != nil {
+		return err
+	}
+
+	f, err := os.Create(e.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(e.path), ".md") {
+		return report.WriteMarkdown(f)
+	}
+	return report.WriteHTML(f)
+}
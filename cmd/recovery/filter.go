@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// filterFlags are the flags that select the signatures of a dataset as it is parsed.
+type filterFlags struct {
+	since, until       *string
+	minBlock, maxBlock *int64
+	signers            *string
+	messagePrefix      *string
+}
+
+// addFilterFlags defines the filter flags on fs.
+func addFilterFlags(fs *flag.FlagSet) *filterFlags {
+	return &filterFlags{
+		since:         fs.String("since", "", "Only signatures made at or after this time (RFC 3339, YYYY-MM-DD or Unix seconds)"),
+		until:         fs.String("until", "", "Only signatures made before this time (RFC 3339, YYYY-MM-DD or Unix seconds)"),
+		minBlock:      fs.Int64("min-block", -1, "Only signatures included at or above this block height"),
+		maxBlock:      fs.Int64("max-block", -1, "Only signatures included at or below this block height"),
+		signers:       fs.String("signer", "", "Only signatures by these signers: comma-separated hex public keys or Ethereum addresses"),
+		messagePrefix: fs.String("message-prefix", "", "Only signatures over messages starting with this text (json and csv files with a message field)"),
+	}
+}
+
+// apply wraps parser with the filter the flags describe, or returns it unchanged if none
+// is set. It exits on an invalid flag value.
+func (f *filterFlags) apply(parser ecdsaaffine.SignatureParser) ecdsaaffine.SignatureParser {
+	filter := &ecdsaaffine.SignatureFilter{MessagePrefix: []byte(*f.messagePrefix)}
+	set := *f.messagePrefix != ""
+	for _, bound := range []struct {
+		flag  string
+		value string
+		dst   *time.Time
+	}{{"since", *f.since, &filter.Since}, {"until", *f.until, &filter.Until}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := parseFilterTime(bound.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --%s: %v\n", bound.flag, err)
+			os.Exit(1)
+		}
+		*bound.dst, set = t, true
+	}
+	if *f.minBlock >= 0 {
+		filter.MinBlock, set = f.minBlock, true
+	}
+	if *f.maxBlock >= 0 {
+		filter.MaxBlock, set = f.maxBlock, true
+	}
+	if *f.signers != "" {
+		for _, signer := range strings.Split(*f.signers, ",") {
+			key, err := ecdsaaffine.ParsePublicKeyHex(strings.TrimSpace(signer))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --signer: %v\n", err)
+				os.Exit(1)
+			}
+			filter.PublicKeys = append(filter.PublicKeys, key)
+		}
+		set = true
+	}
+	if !set {
+		return parser
+	}
+
+	filtered, err := ecdsaaffine.Filter(parser, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return filtered
+}
+
+// parseFilterTime parses an RFC 3339 time, a date (midnight UTC) or Unix seconds.
+func parseFilterTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected RFC 3339, YYYY-MM-DD or Unix seconds", value)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+// runGenerateFixtures implements "recovery generate-fixtures": write the standard fixture
+// set, one signature set with a chosen nonce flaw, or the Ed25519 edge-case sets.
+func runGenerateFixtures(args []string) {
+	fs := flag.NewFlagSet("generate-fixtures", flag.ExitOnError)
+	var (
+		out   = fs.String("out", "fixtures", "Output directory")
+		seed  = fs.Int64("seed", 0, "Seed for reproducible fixtures (0 = random)")
+		curve = fs.String("curve", "ecdsa", "Curve for --flaw: ecdsa (secp256k1) or eddsa (Ed25519)")
+		flaw  = fs.String("flaw", "", "Write one set with this nonce flaw instead of the standard set: "+strings.Join(flawedsigner.FlawKinds, ", "))
+		count = fs.Int("count", 5, "Signatures in the --flaw set")
+		a     = fs.Int64("a", 2, "Coefficient for --flaw affine (k2 = a*k1 + b)")
+		b     = fs.Int64("b", 1, "Offset for --flaw affine (k2 = a*k1 + b)")
+		step  = fs.Int64("step", 12345, "Step for --flaw hardcoded_step")
+		bits  = fs.Int("bits", 64, "Nonce bits for --flaw truncated")
+		edge  = fs.Bool("edge-cases", false, "Write the adversarial Ed25519 sets ("+strings.Join(flawedsigner.EdDSAEdgeCases, ", ")+") instead of the standard set")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery generate-fixtures [--out dir] [--seed n] [--flaw kind --curve ecdsa|eddsa ... | --edge-cases]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var random io.Reader = rand.Reader
+	if *seed != 0 {
+		random = flawedsigner.NewSeededReader(*seed)
+	}
+
+	if *edge {
+		if err := flawedsigner.WriteEdDSAEdgeCaseFixtures(*out, random); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote EdDSA edge-case fixtures to %s\n", *out)
+		return
+	}
+
+	if *flaw == "" {
+		if err := flawedsigner.WriteFixtures(*out, random); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote ECDSA and EdDSA fixtures to %s\n", *out)
+		return
+	}
+
+	if *count < 2 {
+		fmt.Fprintf(os.Stderr, "Error: --count must be at least 2\n")
+		os.Exit(1)
+	}
+	nonces, err := flawedsigner.Flaw{Kind: *flaw, A: *a, B: *b, Step: *step, Bits: *bits}.Nonces(random)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var signatures any
+	var keyInfo map[string]any
+	switch *curve {
+	case "ecdsa":
+		key, err := flawedsigner.NewECDSAKey(random)
+		if err == nil {
+			signatures, err = key.Sign(flawedsigner.ECDSAMessages(*count), nonces)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		keyInfo = map[string]any{"private_key": key.D, "public_key_hex": hex.EncodeToString(key.PublicKey())}
+	case "eddsa":
+		key, err := flawedsigner.NewEdDSAKey(random)
+		if err == nil {
+			signatures, err = key.Sign(flawedsigner.EdDSAMessages(*count), nonces)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		keyInfo = map[string]any{"private_key": key.Scalar, "public_key_hex": hex.EncodeToString(key.Public)}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --curve must be ecdsa or eddsa\n")
+		os.Exit(1)
+	}
+
+	base := filepath.Join(*out, fmt.Sprintf("%s_%s", *curve, *flaw))
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for path, v := range map[string]any{base + ".json": signatures, base + "_key_info.json": keyInfo} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, append(data, '\n'), 0o644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Wrote %d signatures to %s.json (key in %s_key_info.json)\n", *count, base, base)
+	fmt.Printf("Public key: %s\n", keyInfo["public_key_hex"])
+}
@@ -2,29 +2,148 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 
 	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/metrics"
 )
 
 func main() {
+	loadEnvPlugins()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "convert":
+			runConvert(os.Args[2:])
+			return
+		case "bench":
+			runBench(os.Args[2:])
+			return
+		case "analyze":
+			runAnalyze(os.Args[2:])
+			return
+		case "audit":
+			runAudit(os.Args[2:])
+			return
+		case "scope":
+			runScope(os.Args[2:])
+			return
+		case "correlate":
+			runCorrelate(os.Args[2:])
+			return
+		case "compare":
+			runCompare(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		case "stream":
+			runStream(os.Args[2:])
+			return
+		case "keygen":
+			runKeygen(os.Args[2:])
+			return
+		case "decrypt":
+			runDecrypt(os.Args[2:])
+			return
+		case "verify-proof":
+			runVerifyProof(os.Args[2:])
+			return
+		case "generate-fixtures":
+			runGenerateFixtures(os.Args[2:])
+			return
+		case "diagnose":
+			runDiagnose(os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "sanitize":
+			runSanitize(os.Args[2:])
+			return
+		case "merge":
+			runMerge(os.Args[2:])
+			return
+		case "sample":
+			runSample(os.Args[2:])
+			return
+		}
+	}
+
 	var (
-		signaturesFile = flag.String("signatures", "", "Path to signatures file (JSON or CSV)")
-		format         = flag.String("format", "json", "Signature file format (json or csv)")
-		publicKey      = flag.String("public-key", "", "Public key in hex format (compressed, 66 chars) for verification")
+		signaturesFile = flag.String("signatures", "", "Path or URL (https://, s3://, gs://) of signatures file (JSON, CSV or local signature store)")
+		format         = flag.String("format", "json", "Signature file format (json, csv, store, pkcs11, keystore or a parser registered by a --plugin)")
+		storeWindow    = flag.Int("store-window", 256, "With --format store, search the store this many signatures at a time, in windows overlapping by half (filters load the whole store instead)")
+		publicKey      = flag.String("public-key", "", "Key to verify against: hex (compressed, uncompressed, hybrid, raw X||Y), an Ethereum or Bitcoin address (P2PKH, P2SH-P2WPKH, P2WPKH, P2TR), an xpub, or an npub")
+		xpubDepth      = flag.Int("xpub-depth", ecdsaaffine.DefaultXpubDepth, "Levels of descendants to check when --public-key is an xpub")
+		xpubGap        = flag.Int("xpub-gap", ecdsaaffine.DefaultXpubGap, "Children to derive at each level when --public-key is an xpub")
 		knownA         = flag.Int("known-a", 0, "Known affine coefficient a (k2 = a*k1 + b)")
 		knownB         = flag.Int("known-b", 0, "Known affine offset b (k2 = a*k1 + b)")
 		bruteForce     = flag.Bool("brute-force", false, "Brute-force search for affine relationship")
+		knownNonce     = flag.String("known-nonce", "", "Known nonce k of one signature (decimal or 0x hex); recovers the key from that signature alone")
+		nonceIndex     = flag.Int("nonce-index", 0, "Index in the input of the signature whose nonce --known-nonce gives")
 		smartBrute     = flag.Bool("smart-brute", false, "Use smart brute-force (tries common patterns first)")
+		bsgs           = flag.Bool("bsgs", false, "Solve counter nonces (k2 = k1 + b) with baby-step giant-step instead of scanning b")
+		bsgsBits       = flag.Int("bsgs-bits", 40, "Search |b| < 2^bits with --bsgs (table memory grows with 2^(bits/2))")
+		kangaroo       = flag.Bool("kangaroo", false, "Solve counter nonces (k2 = k1 + b) with Pollard's kangaroo (low memory, parallel)")
+		kangarooBits   = flag.Int("kangaroo-bits", 48, "Search |b| < 2^bits with --kangaroo")
+		tablePath      = flag.String("table", "", "Load (or build and save) the BSGS baby-step table or kangaroo distinguished points at this path")
 		aRange         = flag.String("a-range", "-100,100", "Range for a values in brute-force (format: min,max)")
-		bRange         = flag.String("b-range", "-100,100", "Range for b values in brute-force (format: min,max)")
+		bRange         = flag.String("b-range", "-100,100", "Range for b values in brute-force (format: min,max; q-c is the group order minus c)")
 		maxPairs       = flag.Int("max-pairs", 100, "Maximum signature pairs to test in brute-force")
+		pairList       = flag.String("pairs", "", "Only search these signature pairs, by index in the input (format: i:j,i:j, e.g. 3:17,4:18)")
 		numWorkers     = flag.Int("workers", 0, "Number of parallel workers (0 = auto-detect based on CPU cores)")
+		maxRate        = flag.Float64("max-rate", 0, "Limit the brute-force search to this many candidates/sec (0 = unlimited)")
+		maxCPU         = flag.Int("max-cpu", 0, "Limit each brute-force worker to this percentage of a core (0 = unlimited)")
+		maxCandidates  = flag.Int64("max-candidates", 0, "Stop the brute-force search after testing this many candidates (0 = unlimited; see --report)")
+		maxCPUTime     = flag.Duration("max-cpu-time", 0, "Stop the brute-force search after its workers used this much CPU time, e.g. 10m (0 = unlimited; see --report)")
+		skipOverBudget = flag.Bool("skip-over-budget", false, "Skip brute-force phases predicted not to finish within --timeout or --max-cpu-time (noted in --report)")
+		autoTune       = flag.Duration("auto-tune", 0, "Measure the brute-force search for this long before its first parallel phase, e.g. 5s, and keep the fastest worker count and chunk size (0 = off)")
+		verification   = flag.String("verification", "fast", "How brute-force candidates are checked: fast (point comparison and stepping) or reference (derive and compare each public key; slower, for cross-checking)")
+		arithmetic     = flag.String("arithmetic", "auto", "Modular arithmetic of brute-force key recovery: fixed (256-bit Montgomery), big (math/big) or auto (fixed on 64-bit platforms)")
+		deterministic  = flag.Bool("deterministic", false, "Make the brute-force search report the same key/pair on every run regardless of --workers (slower: workers finish earlier candidates first)")
+		seed           = flag.Int64("seed", 0, "Random seed for --kangaroo walks (same seed and --workers 1 repeat a run exactly)")
+		proofOnly      = flag.Bool("proof-only", false, "Print a proof of compromise (signature over --challenge by the recovered key) instead of the key")
+		challenge      = flag.String("challenge", "", "Challenge signed with --proof-only (e.g. a bug bounty report ID)")
+		encryptTo      = flag.String("encrypt-to", "", "Encrypt the result (key and nonces) to this age recipient (e.g. from \"recovery keygen\") instead of printing it")
+		passphraseFile = flag.String("passphrase-file", "", "Encrypt the result with the passphrase on the first line of this file instead of printing it")
+		encryptOut     = flag.String("encrypt-out", "", "Write the encrypted result to this file instead of stdout")
+		auditLog       = flag.String("audit-log", "", "Append every recovered key and unverified candidate to this tamper-evident audit log (JSONL)")
+		metricsAddr    = flag.String("metrics-addr", "", "Serve Prometheus metrics for the brute-force search at http://<addr>/metrics (e.g. :9090)")
+		excludePath    = flag.String("exclude", "", "Skip signature pairs and ranges that this search report (from --report) shows were already searched")
+		reportPath     = flag.String("report", "", "Write the ranges and signature pairs searched without finding a key to this search report (with --exclude, adds to it)")
+		tui            = flag.Bool("tui", false, "Show a live dashboard (phase, throughput, ETA) for --smart-brute or --brute-force instead of log lines; press s to skip a phase, q to cancel")
+		jsonOutput     = flag.Bool("json", false, "Print the recovery result as JSON")
+		showNonces     = flag.Bool("nonces", false, "Compute and print the nonce of every signature after recovery, and the generator pattern the nonces show (see --matrix)")
+		showMatrix     = flag.Bool("matrix", false, "Print the pairwise nonce relationship report after recovery")
+		matrixDOT      = flag.String("matrix-dot", "", "Write the nonce relationship graph in Graphviz DOT format to this file")
+		crossCheck     = flag.String("cross-check-python", "", "After recovery, re-sign every signature with the recovered key and nonces using the reference Python signer in this scripts directory (needs python3 and scripts/requirements.txt) and fail if any differs")
+		engagementPath = flag.String("engagement-report", "", "Write an engagement report (dataset, findings, redacted result, nonce diagram, evidence, configuration) to this file: Markdown for .md, HTML otherwise")
+		classifyPath   = flag.String("classify", "", "Write the vulnerabilities found (nonce reuse, counter, affine or biased nonces) as OSV-style JSON with CWE ids, severity and remediation to this file")
+		strategyName   = flag.String("strategy", "", "Search with this registered strategy, built in (smart, same-nonce, patterns, adaptive, bsgs) or from a --plugin")
+		plugins        = flag.String("plugin", "", "Load these Go plugins (comma-separated .so files) to register third-party strategies and parsers; RECOVERY_PLUGINS also lists plugins for every command")
+		corroborate    = flag.Int("corroborate", -1, "After recovery, check the key against up to this many other signatures by the signer (0 = all, -1 = off) and report how many it explains")
+		notifyURL      = flag.String("notify-url", "", "POST a JSON summary of the outcome (no key material) to this webhook when the search ends")
+		timeout        = flag.Duration("timeout", 0, "Give up the search after this long (e.g. 30m, 12h; 0 = no limit)")
+		dryRun         = flag.Bool("dry-run", false, "Print the phases, patterns, pairs and candidates the search would run, with the worst-case time and memory, without searching")
+		patternsPath   = flag.String("patterns", "", "Try the patterns in this pattern file (e.g. from \"recovery analyze --emit-patterns\") after the common patterns, with --smart-brute or --brute-force; on SIGHUP, patterns added to the file join the running search")
+		curveName      = flag.String("curve", "", "Recover on this curve instead of secp256k1: secp256r1 (P-256), secp224k1, brainpoolP256r1 or brainpoolP384r1; --public-key is then hex of the curve's point encoding")
 	)
+	filters := addFilterFlags(flag.CommandLine)
 	flag.Parse()
 
 	if *signaturesFile == "" {
@@ -33,33 +152,302 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := loadPlugins(splitPlugins(*plugins)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Set up parser based on format
-	var parser ecdsaaffine.SignatureParser
-	if *format == "json" {
-		parser = &ecdsaaffine.JSONParser{
-			MessageField: "message",
-			RField:       "r",
-			SField:       "s",
-			ZField:       "z",
-		}
-	} else {
-		parser = &ecdsaaffine.CSVParser{
-			MessageCol: "message",
-			RCol:       "r",
-			SCol:       "s",
-			ZCol:       "z",
-		}
+	parser := newParser(*format)
+	if store, ok := parser.(*ecdsaaffine.StoreParser); ok {
+		store.Window = *storeWindow
+	}
+	parser = filters.apply(parser)
+
+	if *dryRun && !*smartBrute && !*bruteForce && *strategyName == "" {
+		fmt.Fprintf(os.Stderr, "Error: --dry-run needs --smart-brute, --brute-force or --strategy\n")
+		os.Exit(1)
 	}
 
 	// Create client with parser
 	client := ecdsaaffine.NewClient().WithParser(parser)
+	switch *verification {
+	case "fast":
+	case "reference":
+		client = client.WithVerification(ecdsaaffine.ReferenceVerification)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --verification must be fast or reference\n")
+		os.Exit(1)
+	}
+	switch *arithmetic {
+	case "auto":
+	case "fixed":
+		client = client.WithArithmetic(ecdsaaffine.FixedArithmetic)
+	case "big":
+		client = client.WithArithmetic(ecdsaaffine.BigArithmetic)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --arithmetic must be auto, fixed or big\n")
+		os.Exit(1)
+	}
+	if *corroborate >= 0 {
+		client = client.WithCrossCheck(*corroborate)
+	}
+	if *pairList != "" {
+		pairs, err := parsePairs(*pairList)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing pairs: %v\n", err)
+			os.Exit(1)
+		}
+		client = client.WithPairs(pairs...)
+	}
+
+	var searchMetrics *metrics.Search
+	if *metricsAddr != "" {
+		searchMetrics = metrics.NewSearch("secp256k1")
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", searchMetrics.Handler())
+		go func() {
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: metrics server: %v\n", err)
+			}
+		}()
+	}
+
+	// A search report is both the exclusion set and the record of this search
+	var searchReport *ecdsaaffine.SearchReport
+	if *excludePath != "" {
+		loaded, err := ecdsaaffine.LoadSearchReport(*excludePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		searchReport = loaded
+	} else if *reportPath != "" {
+		searchReport = &ecdsaaffine.SearchReport{}
+	}
+
+	// The dashboard draws from the search metrics and skips phases through the range config
+	var skipPhase chan struct{}
+	if *tui {
+		if !*smartBrute && !*bruteForce {
+			fmt.Fprintf(os.Stderr, "Error: --tui needs --smart-brute or --brute-force\n")
+			os.Exit(1)
+		}
+		if searchMetrics == nil {
+			searchMetrics = metrics.NewSearch("secp256k1")
+		}
+		skipPhase = make(chan struct{})
+	}
+
+	patternConfig := ecdsaaffine.DefaultPatternConfig()
+	if *patternsPath != "" {
+		patterns, err := ecdsaaffine.LoadPatternFile(*patternsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --patterns: %v\n", err)
+			os.Exit(1)
+		}
+		patternConfig.CustomPatterns = patterns
+		// SIGHUP adds the patterns written to the file since to the running search
+		patternConfig.Inject = reloadPatternsOnHangup(*patternsPath, patterns)
+	}
 
-	ctx := context.Background()
+	// searchConfig is the range config of the --smart-brute search, for --dry-run
+	searchConfig := ecdsaaffine.DefaultRangeConfig()
+	if *maxRate > 0 || *maxCPU > 0 || *maxCandidates > 0 || *maxCPUTime > 0 || *skipOverBudget || *autoTune > 0 || *deterministic || searchMetrics != nil || searchReport != nil || *patternsPath != "" {
+		config := ecdsaaffine.DefaultRangeConfig()
+		config.MaxRate = *maxRate
+		config.MaxCPUPercent = *maxCPU
+		config.MaxCandidates = *maxCandidates
+		config.MaxCPUTime = *maxCPUTime
+		config.SkipOverBudget = *skipOverBudget
+		config.AutoTune = *autoTune
+		config.Deterministic = *deterministic
+		config.SkipPhase = skipPhase
+		strategy := ecdsaaffine.NewSmartBruteForceStrategy().WithRangeConfig(config).WithPatternConfig(patternConfig)
+		strategy.Metrics = searchMetrics
+		strategy.Report = searchReport
+		client = client.WithStrategy(strategy)
+		searchConfig = config
+	}
+
+	// Other curves run on a CurveStrategy and take the public key as hex of their point
+	// encoding
+	var curve *ecdsaaffine.Curve
+	if *curveName != "" {
+		if err := checkCurveFlags(flag.CommandLine); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		var err error
+		curve, err = ecdsaaffine.CurveByName(*curveName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --curve: %v\n", err)
+			os.Exit(1)
+		}
+		strategy := ecdsaaffine.NewCurveStrategy(curve)
+		strategy.MaxPairs = *maxPairs
+		client = client.WithStrategy(strategy).WithCurve(curve)
+	}
+
+	output := outputOptions{
+		JSON:      *jsonOutput,
+		Nonces:    *showNonces,
+		Matrix:    *showMatrix,
+		MatrixDOT: *matrixDOT,
+		Notify:    newNotifier(*notifyURL, *signaturesFile),
+		Curve:     curve,
+
+		CrossCheck: *crossCheck,
+	}
+	if *engagementPath != "" {
+		output.Engagement = &engagementReport{path: *engagementPath, signaturesFile: *signaturesFile, parser: parser}
+	}
+	if *classifyPath != "" {
+		output.Classify = &classification{path: *classifyPath, signaturesFile: *signaturesFile, parser: parser}
+	}
+	sealOpts, err := newSealOptions(*encryptTo, *passphraseFile, *encryptOut)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	output.Seal = sealOpts
+	if *proofOnly {
+		if *challenge == "" {
+			fmt.Fprintf(os.Stderr, "Error: --proof-only requires --challenge\n")
+			os.Exit(1)
+		}
+		if sealOpts != nil || *showNonces || *matrixDOT != "" {
+			fmt.Fprintf(os.Stderr, "Error: --proof-only cannot be combined with encryption, --nonces or --matrix-dot\n")
+			os.Exit(1)
+		}
+		output.Challenge = *challenge
+	}
+	if *auditLog != "" {
+		recorder, err := newAuditRecorder(*auditLog, *signaturesFile, *publicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: audit log: %v\n", err)
+			os.Exit(1)
+		}
+		recorder.redact = sealOpts != nil || *proofOnly
+		defer recorder.log.Close()
+		output.Audit = recorder
+	}
+
+	// Addresses, xpubs and npubs are resolved to a verification target up front; the
+	// audit log above keeps the victim key as the user gave it
+	if *publicKey != "" && curve == nil {
+		if ecdsaaffine.IsExtendedPublicKey(*publicKey) {
+			output.Xpub = &xpubOptions{Key: *publicKey, Depth: *xpubDepth, Gap: *xpubGap}
+		}
+		target, err := ecdsaaffine.ParseTarget(*publicKey, *xpubDepth, *xpubGap)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --public-key: %v\n", err)
+			os.Exit(1)
+		}
+		*publicKey = hex.EncodeToString(target)
+	}
+
+	// Progress messages go to stderr when stdout carries JSON or a sealed result
+	// and are dropped under the dashboard, which draws over the terminal
+	var info io.Writer = os.Stdout
+	if *jsonOutput || sealOpts != nil {
+		info = os.Stderr
+	}
+	if *tui {
+		info = io.Discard
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	if *reportPath != "" {
+		// Ctrl-C cancels the search instead of exiting, so the ranges it completed are saved
+		ctx, cancel = signal.NotifyContext(ctx, os.Interrupt)
+		defer cancel()
+	}
+
+	var dash *dashboard
+	if *tui {
+		signatures, err := parser.ParseSignatures(*signaturesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dash, err = startDashboard(searchMetrics, len(signatures), skipPhase, cancel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dash.Plan(ecdsaaffine.DefaultRangeConfig())
+	}
+
+	// searchFailed reports a search that returned no key and exits
+	searchFailed := func(err error) {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("search timed out after %v", *timeout)
+		}
+		output.Notify.failed(err)
+		if reportErr := output.Engagement.write(nil, err); reportErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write engagement report: %v\n", reportErr)
+		}
+		if classifyErr := output.Classify.write(nil); classifyErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write vulnerability classification: %v\n", classifyErr)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// finishSearch runs when a brute-force search returns, before its result is printed
+	finishSearch := func() {
+		dash.Stop()
+		if *reportPath != "" {
+			if err := searchReport.Save(*reportPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to save search report: %v\n", err)
+			}
+		}
+	}
+
+	// planSearch prints, for --dry-run, the plan of the search client would run with config
+	planSearch := func(client *ecdsaaffine.Client, config ecdsaaffine.RangeConfig) {
+		plan, err := client.Plan(*signaturesFile, *publicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *jsonOutput {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(plan)
+			return
+		}
+		fmt.Printf("Calibrating search rate...\n")
+		log.SetOutput(io.Discard)
+		printPlan(plan, ecdsaaffine.EstimatePlan(plan, config))
+	}
 
 	// Recover key based on mode
-	if *knownA != 0 || *knownB != 0 {
+	if *knownNonce != "" {
+		// Known nonce of a single signature
+		k, ok := new(big.Int).SetString(*knownNonce, 0)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: invalid --known-nonce %q (want decimal or 0x hex)\n", *knownNonce)
+			os.Exit(1)
+		}
+		fmt.Fprintf(info, "Using known nonce of signature %d\n", *nonceIndex)
+
+		result, err := client.RecoverKeyWithKnownNonce(ctx, *signaturesFile, *nonceIndex, k, *publicKey)
+		if err != nil {
+			searchFailed(err)
+		}
+
+		printResult(result, parser, *signaturesFile, output)
+
+	} else if *knownA != 0 || *knownB != 0 {
 		// Known relationship
-		fmt.Printf("Using known relationship: k2 = %d*k1 + %d\n", *knownA, *knownB)
+		fmt.Fprintf(info, "Using known relationship: k2 = %d*k1 + %d\n", *knownA, *knownB)
 
 		publicKeyStr := ""
 		if *publicKey != "" {
@@ -68,44 +456,81 @@ func main() {
 
 		result, err := client.RecoverKeyWithKnownRelationship(ctx, *signaturesFile, int64(*knownA), int64(*knownB), publicKeyStr)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			searchFailed(err)
+		}
+
+		printResult(result, parser, *signaturesFile, output)
+
+	} else if *bsgs {
+		// Baby-step giant-step over the counter offset b
+		if *bsgsBits < 1 || *bsgsBits > 48 {
+			fmt.Fprintf(os.Stderr, "Error: --bsgs-bits must be between 1 and 48 (the baby-step table for 48 bits holds about 24M entries)\n")
 			os.Exit(1)
 		}
+		fmt.Fprintf(info, "Solving k2 = k1 + b for |b| < 2^%d with baby-step giant-step...\n", *bsgsBits)
+
+		strategy := ecdsaaffine.NewBSGSStrategy().WithBound(int64(1) << uint(*bsgsBits))
+		strategy.MaxPairs = *maxPairs
+		strategy.TablePath = *tablePath
+		defer strategy.Close()
+		client = client.WithStrategy(strategy)
 
-		fmt.Printf("\n[+] Recovered private key from signatures %d and %d:\n", result.SignaturePair[0], result.SignaturePair[1])
-		fmt.Printf("    Private key: %s\n", result.PrivateKey.String())
-		if result.Verified {
-			fmt.Println("    ✓ Verified against public key!")
+		result, err := client.RecoverKey(ctx, *signaturesFile, *publicKey)
+		if err != nil {
+			searchFailed(err)
 		}
 
+		printResult(result, parser, *signaturesFile, output)
+
+	} else if *kangaroo {
+		// Pollard's kangaroo over the counter offset b
+		if *kangarooBits < 1 || *kangarooBits > 60 {
+			fmt.Fprintf(os.Stderr, "Error: --kangaroo-bits must be between 1 and 60\n")
+			os.Exit(1)
+		}
+		fmt.Fprintf(info, "Solving k2 = k1 + b for |b| < 2^%d with Pollard's kangaroo...\n", *kangarooBits)
+
+		bound := int64(1) << uint(*kangarooBits)
+		strategy := ecdsaaffine.NewKangarooStrategy(-bound, bound)
+		strategy.Solver.Workers = *numWorkers
+		strategy.Solver.Seed = *seed
+		strategy.MaxPairs = *maxPairs
+		strategy.StorePath = *tablePath
+		client = client.WithStrategy(strategy)
+
+		result, err := client.RecoverKey(ctx, *signaturesFile, *publicKey)
+		if err != nil {
+			searchFailed(err)
+		}
+
+		printResult(result, parser, *signaturesFile, output)
+
 	} else if *smartBrute {
 		// Smart brute-force (uses default multi-phase strategy)
-		fmt.Printf("Loading signatures from %s...\n", *signaturesFile)
+		fmt.Fprintf(info, "Loading signatures from %s...\n", *signaturesFile)
 
 		publicKeyStr := ""
 		if *publicKey != "" {
 			publicKeyStr = *publicKey
 		}
 
+		if *dryRun {
+			planSearch(client, searchConfig)
+			return
+		}
+
 		result, err := client.RecoverKey(ctx, *signaturesFile, publicKeyStr)
+		finishSearch()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			searchFailed(err)
 		}
 
-		fmt.Printf("\n[+] Successfully recovered private key!\n")
-		fmt.Printf("    Private key: %s\n", result.PrivateKey.String())
-		fmt.Printf("    Relationship: k2 = %s*k1 + %s\n", result.Relationship.A.String(), result.Relationship.B.String())
-		fmt.Printf("    Signature pair: (%d, %d)\n", result.SignaturePair[0], result.SignaturePair[1])
-		fmt.Printf("    Pattern: %s\n", result.Pattern)
-		if result.Verified {
-			fmt.Println("    ✓ Verified against public key!")
-		}
+		printResult(result, parser, *signaturesFile, output)
 
 	} else if *bruteForce {
 		// Brute-force - try common patterns first for efficiency
-		fmt.Printf("Loading signatures from %s...\n", *signaturesFile)
-		fmt.Println("Trying common patterns first (fast path)...")
+		fmt.Fprintf(info, "Loading signatures from %s...\n", *signaturesFile)
+		fmt.Fprintln(info, "Trying common patterns first (fast path)...")
 
 		publicKeyStr := ""
 		if *publicKey != "" {
@@ -113,21 +538,24 @@ func main() {
 		}
 
 		// First try with default smart brute-force (common patterns)
-		result, err := client.RecoverKey(ctx, *signaturesFile, publicKeyStr)
-		if err == nil && result != nil {
-			fmt.Printf("\n[+] Successfully recovered private key!\n")
-			fmt.Printf("    Private key: %s\n", result.PrivateKey.String())
-			fmt.Printf("    Relationship: k2 = %s*k1 + %s\n", result.Relationship.A.String(), result.Relationship.B.String())
-			fmt.Printf("    Signature pair: (%d, %d)\n", result.SignaturePair[0], result.SignaturePair[1])
-			fmt.Printf("    Pattern: %s\n", result.Pattern)
-			if result.Verified {
-				fmt.Println("    ✓ Verified against public key!")
+		var result *ecdsaaffine.RecoveryResult
+		var err error
+		if *dryRun {
+			fmt.Fprintln(info, "Fast path (common patterns and the default phases):")
+			planSearch(client, searchConfig)
+		} else {
+			result, err = client.RecoverKey(ctx, *signaturesFile, publicKeyStr)
+			if err == nil && result != nil {
+				finishSearch()
+				printResult(result, parser, *signaturesFile, output)
+				return
 			}
-			return
 		}
 
 		// If common patterns didn't work, use specified ranges
-		fmt.Println("Common patterns didn't work, using specified ranges...")
+		if !*dryRun {
+			fmt.Fprintln(info, "Common patterns didn't work, using specified ranges...")
+		}
 
 		// Parse ranges
 		aMin, aMax, err := parseRange(*aRange)
@@ -143,36 +571,68 @@ func main() {
 		}
 
 		// Create strategy with custom ranges
+		rangeConfig := ecdsaaffine.RangeConfig{
+			ARange:     [2]int{aMin, aMax},
+			BRange:     [2]int{bMin, bMax},
+			MaxPairs:   *maxPairs,
+			NumWorkers: *numWorkers,
+			SkipZeroA:  true,
+
+			KeyCacheSize:  ecdsaaffine.DefaultRangeConfig().KeyCacheSize,
+			MaxRate:       *maxRate,
+			MaxCPUPercent: *maxCPU,
+			MaxCandidates: *maxCandidates,
+			MaxCPUTime:    *maxCPUTime,
+			Deterministic: *deterministic,
+			SkipPhase:     skipPhase,
+		}
+		dash.Plan(rangeConfig)
 		strategy := ecdsaaffine.NewSmartBruteForceStrategy().
-			WithRangeConfig(ecdsaaffine.RangeConfig{
-				ARange:     [2]int{aMin, aMax},
-				BRange:     [2]int{bMin, bMax},
-				MaxPairs:   *maxPairs,
-				NumWorkers: *numWorkers,
-				SkipZeroA:  true,
-			}).
+			WithRangeConfig(rangeConfig).
 			WithPatternConfig(ecdsaaffine.PatternConfig{
 				IncludeCommonPatterns: false, // Skip common patterns, use only custom range
 			})
+		strategy.Metrics = searchMetrics
+		strategy.Report = searchReport
 
 		client = client.WithStrategy(strategy)
+		if *dryRun {
+			fmt.Fprintln(info, "\nThen the specified ranges:")
+			planSearch(client, rangeConfig)
+			return
+		}
 
 		result, err = client.RecoverKey(ctx, *signaturesFile, publicKeyStr)
+		finishSearch()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			searchFailed(err)
+		}
+
+		printResult(result, parser, *signaturesFile, output)
+
+	} else if *strategyName != "" {
+		// A registered strategy, possibly from a plugin
+		strategy, err := ecdsaaffine.NewStrategy(*strategyName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --strategy: %v\n", err)
 			os.Exit(1)
 		}
+		fmt.Fprintf(info, "Searching with strategy %s...\n", strategy.Name())
+		client = client.WithStrategy(strategy)
+		if *dryRun {
+			planSearch(client, ecdsaaffine.DefaultRangeConfig())
+			return
+		}
 
-		fmt.Printf("\n[+] Successfully recovered private key!\n")
-		fmt.Printf("    Private key: %s\n", result.PrivateKey.String())
-		fmt.Printf("    Relationship: k2 = %s*k1 + %s\n", result.Relationship.A.String(), result.Relationship.B.String())
-		fmt.Printf("    Signature pair: (%d, %d)\n", result.SignaturePair[0], result.SignaturePair[1])
-		if result.Verified {
-			fmt.Println("    ✓ Verified against public key!")
+		result, err := client.RecoverKey(ctx, *signaturesFile, *publicKey)
+		if err != nil {
+			searchFailed(err)
 		}
 
+		printResult(result, parser, *signaturesFile, output)
+
 	} else {
-		fmt.Fprintf(os.Stderr, "Error: Must specify --known-a/--known-b, --brute-force, or --smart-brute\n")
+		fmt.Fprintf(os.Stderr, "Error: Must specify --known-a/--known-b, --brute-force, --smart-brute, --bsgs, --kangaroo or --strategy\n")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -184,15 +644,55 @@ func parseRange(s string) (int, int, error) {
 		return 0, 0, fmt.Errorf("invalid range format: %s", s)
 	}
 
-	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	min, err := parseRangeBound(parts[0])
 	if err != nil {
 		return 0, 0, err
 	}
 
-	max, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	max, err := parseRangeBound(parts[1])
 	if err != nil {
 		return 0, 0, err
 	}
 
 	return min, max, nil
 }
+
+// parseRangeBound parses one end of a range: an integer, or "q-c" (also "n-c" or "L-c"),
+// the group order minus c, which the search takes mod the order as -c.
+func parseRangeBound(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	for _, order := range []string{"q", "n", "L"} {
+		if s == order {
+			return 0, nil
+		}
+		if c, ok := strings.CutPrefix(s, order+"-"); ok {
+			v, err := strconv.Atoi(c)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid range bound %q: expected %s-c with c >= 0", s, order)
+			}
+			return -v, nil
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// parsePairs parses a --pairs value such as "3:17,4:18".
+func parsePairs(s string) ([][2]int, error) {
+	var pairs [][2]int
+	for _, part := range strings.Split(s, ",") {
+		i, j, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid pair format: %s", part)
+		}
+		first, err := strconv.Atoi(strings.TrimSpace(i))
+		if err != nil {
+			return nil, err
+		}
+		second, err := strconv.Atoi(strings.TrimSpace(j))
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, [2]int{first, second})
+	}
+	return pairs, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// Events posted by --notify-url.
+const (
+	notifyKeyFound     = "key_found"     // a key verified against the public key
+	notifyCandidate    = "candidate"     // a key that could not be verified
+	notifySearchFailed = "search_failed" // the search ended without a key
+)
+
+// notifyTimeout bounds each webhook request, so an unreachable endpoint cannot hold up
+// the end of a search.
+const notifyTimeout = 10 * time.Second
+
+// notification is the JSON body posted to the webhook. It never carries key material:
+// a recovered key is identified by its public key.
+type notification struct {
+	Event          string  `json:"event"`
+	Dataset        string  `json:"dataset"`
+	PublicKey      string  `json:"public_key,omitempty"`
+	Verified       bool    `json:"verified"`
+	Pattern        string  `json:"pattern,omitempty"`
+	SignaturePair  *[2]int `json:"signature_pair,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// notifier posts the outcome of a search to a webhook. A nil notifier posts nothing.
+type notifier struct {
+	url     string
+	dataset string
+	started time.Time
+	client  *http.Client
+}
+
+func newNotifier(url, dataset string) *notifier {
+	if url == "" {
+		return nil
+	}
+	return &notifier{url: url, dataset: dataset, started: time.Now(), client: &http.Client{Timeout: notifyTimeout}}
+}
+
+// found posts a redacted summary of result.
+func (n *notifier) found(result *ecdsaaffine.RecoveryResult) {
+	if n == nil {
+		return
+	}
+	note := n.notification(notifyCandidate)
+	if result.Verified {
+		note.Event = notifyKeyFound
+	}
+	note.PublicKey = fmt.Sprintf("%x", secp256k1.PrivKeyFromBytes(result.PrivateKey.Bytes()).PubKey().SerializeCompressed())
+	note.Verified = result.Verified
+	note.Pattern = result.Pattern
+	note.SignaturePair = &result.SignaturePair
+	n.post(note)
+}
+
+// failed posts that the search ended without a key.
+func (n *notifier) failed(err error) {
+	if n == nil {
+		return
+	}
+	note := n.notification(notifySearchFailed)
+	note.Error = err.Error()
+	n.post(note)
+}
+
+func (n *notifier) notification(event string) notification {
+	return notification{
+		Event:          event,
+		Dataset:        n.dataset,
+		ElapsedSeconds: time.Since(n.started).Seconds(),
+	}
+}
+
+// post sends note, reporting a failure on stderr without failing the run.
+func (n *notifier) post(note notification) {
+	body, err := json.Marshal(note)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: notification: %v\n", err)
+		return
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: notification: %v\n", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		fmt.Fprintf(os.Stderr, "Warning: notification: webhook returned %s\n", resp.Status)
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
+)
+
+// outputOptions controls what is printed after a successful recovery.
+type outputOptions struct {
+	JSON      bool           // Print the result as JSON
+	Nonces    bool           // Include every signature's nonce and the generator pattern they show
+	Matrix    bool           // Print the nonce relationship report
+	MatrixDOT string         // Write the nonce relationship graph to this file
+	Audit     *auditRecorder // Record the result in an audit log
+	Seal      *sealOptions   // Encrypt the result instead of printing key material
+	Challenge string         // Print a proof of compromise over this challenge instead of the key
+	Xpub      *xpubOptions   // Report the derivation path of the recovered key below this xpub
+	Notify    *notifier      // Post a redacted summary of the result to a webhook
+
+	Engagement *engagementReport // Write an engagement report of the run
+	Classify   *classification   // Write the vulnerability classification of the dataset
+
+	// CrossCheck re-signs every signature with the Python reference signer in this scripts
+	// directory and exits with an error status if any differs
+	CrossCheck string
+
+	// Curve is the --curve the key was recovered on (nil for secp256k1); the result's
+	// confidence, which is scored on secp256k1, is not reported for it
+	Curve *ecdsaaffine.Curve
+}
+
+// xpubOptions is the extended public key the victim key was given as.
+type xpubOptions struct {
+	Key   string
+	Depth int
+	Gap   int
+}
+
+// resultJSON is the machine-readable form of a recovery result.
+type resultJSON struct {
+	PrivateKey    string                  `json:"private_key"`
+	PrivateKeyHex string                  `json:"private_key_hex"`
+	A             string                  `json:"a"`
+	B             string                  `json:"b"`
+	SignaturePair [2]int                  `json:"signature_pair"`
+	Verified      bool                    `json:"verified"`
+	Confidence    *ecdsaaffine.Confidence `json:"confidence,omitempty"`
+	Corroborating int                     `json:"corroborating,omitempty"`
+	CrossChecked  int                     `json:"cross_checked,omitempty"`
+	Pattern       string                  `json:"pattern"`
+	Derivation    string                  `json:"derivation_path,omitempty"`
+	Nonces        []string                `json:"nonces,omitempty"`
+	NoncePattern  string                  `json:"nonce_pattern,omitempty"`
+	Relationships *nonceanalysis.Report   `json:"relationships,omitempty"`
+}
+
+// printResult prints a recovery result as text or JSON, optionally with nonce analysis.
+func printResult(result *ecdsaaffine.RecoveryResult, parser ecdsaaffine.SignatureParser, signaturesFile string, opts outputOptions) {
+	if err := opts.Audit.record(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write audit log: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Notify.found(result)
+	if err := opts.Engagement.write(result, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write engagement report: %v\n", err)
+		os.Exit(1)
+	}
+	if err := opts.Classify.write(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write vulnerability classification: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.Challenge != "" {
+		printProof(result, opts.Challenge, opts.JSON)
+		return
+	}
+
+	var nonces []*big.Int
+	var report *nonceanalysis.Report
+	var confidence *ecdsaaffine.Confidence
+	if opts.Nonces || opts.Matrix || opts.MatrixDOT != "" || opts.CrossCheck != "" || (!result.Verified && parser != nil && opts.Curve == nil) {
+		signatures, err := parser.ParseSignatures(signaturesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to re-read signatures: %v\n", err)
+			os.Exit(1)
+		}
+		if !result.Verified {
+			c := ecdsaaffine.ScoreResult(result, signatures)
+			confidence = &c
+		}
+		// The nonces go through the relationship analysis, whose pattern --nonces reports
+		nonces, report, err = ecdsaaffine.AnalyzeNonces(result, signatures, nonceanalysis.DefaultOptions())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to compute nonces: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.CrossCheck != "" && !crossCheckPython(opts.CrossCheck, result.PrivateKey, signatures, nonces) {
+			defer os.Exit(1) // once the result is printed
+		}
+	}
+
+	if opts.MatrixDOT != "" {
+		if err := writeDOT(report, opts.MatrixDOT); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write relationship graph: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var nonceHex []string
+	var noncePattern string
+	if opts.Nonces {
+		for _, k := range nonces {
+			nonceHex = append(nonceHex, "0x"+k.Text(16))
+		}
+		noncePattern = report.Pattern
+	}
+
+	out := resultJSON{
+		PrivateKey:    result.PrivateKey.String(),
+		PrivateKeyHex: "0x" + result.PrivateKey.Text(16),
+		A:             result.Relationship.A.String(),
+		B:             result.Relationship.B.String(),
+		SignaturePair: result.SignaturePair,
+		Verified:      result.Verified,
+		Confidence:    confidence,
+		Corroborating: result.Corroborating,
+		CrossChecked:  result.CrossChecked,
+		Pattern:       result.Pattern,
+		Nonces:        nonceHex,
+		NoncePattern:  noncePattern,
+	}
+	if opts.Xpub != nil {
+		path, ok, err := ecdsaaffine.FindXpubPath(opts.Xpub.Key, opts.Xpub.Depth, opts.Xpub.Gap, result.PrivateKey)
+		if err == nil && ok {
+			out.Derivation = path
+		}
+	}
+	if opts.Matrix {
+		out.Relationships = report
+	}
+
+	if opts.Seal != nil {
+		if err := opts.Seal.write(out); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to encrypt result: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "\n[+] Successfully recovered private key (encrypted to %s)\n", opts.Seal.destination())
+		fmt.Fprintf(os.Stderr, "    Relationship: k2 = %s*k1 + %s\n", out.A, out.B)
+		fmt.Fprintf(os.Stderr, "    Signature pair: (%d, %d)\n", out.SignaturePair[0], out.SignaturePair[1])
+		fmt.Fprintf(os.Stderr, "    Verified: %v\n", out.Verified)
+		if out.Derivation != "" {
+			fmt.Fprintf(os.Stderr, "    Derivation path: %s\n", out.Derivation)
+		}
+		return
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(out); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("\n[+] Successfully recovered private key!\n")
+	fmt.Printf("    Private key: %s\n", result.PrivateKey.String())
+	fmt.Printf("    Relationship: k2 = %s*k1 + %s\n", result.Relationship.A.String(), result.Relationship.B.String())
+	fmt.Printf("    Signature pair: (%d, %d)\n", result.SignaturePair[0], result.SignaturePair[1])
+	fmt.Printf("    Pattern: %s\n", result.Pattern)
+	if result.Verified {
+		fmt.Println("    ✓ Verified against public key!")
+	} else if confidence != nil {
+		fmt.Printf("    Not verified (no public key); confidence %.2f: the key signs %d of %d signatures checked\n", confidence.Score, confidence.Explained, confidence.Checked)
+	}
+	if result.CrossChecked > 0 {
+		fmt.Printf("    Corroborated by %d of %d other signatures\n", result.Corroborating, result.CrossChecked)
+		if result.Corroborating < result.CrossChecked {
+			fmt.Println("    ⚠️  The key does not explain every signature: check for records of other keys or z computed differently")
+		}
+	}
+	if out.Derivation != "" {
+		fmt.Printf("    Derivation path: %s (relative to the xpub)\n", out.Derivation)
+	}
+	if len(nonceHex) > 0 {
+		fmt.Println("    Nonces:")
+		for i, k := range nonceHex {
+			fmt.Printf("      [%d] %s\n", i, k)
+		}
+		fmt.Printf("    Nonce pattern: %s\n", out.NoncePattern)
+	}
+	if opts.Matrix {
+		fmt.Println()
+		report.WriteText(os.Stdout)
+	}
+}
+
+func writeDOT(report *nonceanalysis.Report, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.WriteDOT(f)
+}
+
+// printEstimate prints a dry-run search estimate.
+func printEstimate(estimate ecdsaaffine.SearchEstimate, numSignatures int) {
+	fmt.Printf("Signatures: %d (%d pairs searched)\n", numSignatures, estimate.Pairs)
+	fmt.Printf("Rate:       %.0f candidates/sec on %d workers\n\n", estimate.Rate.PerSecond(), estimate.Rate.Workers)
+
+	for _, phase := range estimate.Phases {
+		name := phase.Name
+		if phase.ARange != [2]int{} || phase.BRange != [2]int{} {
+			name = fmt.Sprintf("%s (a in [%d, %d], b in [%d, %d])", phase.Name, phase.ARange[0], phase.ARange[1], phase.BRange[0], phase.BRange[1])
+		}
+		fmt.Printf("  %-70s %12.3g candidates  %v\n", name, phase.Candidates, phase.Duration.Round(time.Second))
+	}
+
+	fmt.Printf("\nWorst case: %.3g candidates, %v, ~%.1f MB memory\n",
+		estimate.Candidates, estimate.Duration.Round(time.Second), float64(estimate.MemoryBytes)/(1<<20))
+	if estimate.Duration > 24*time.Hour {
+		fmt.Println("Warning: this search can take more than a day; consider narrowing --a-range/--b-range or using --bsgs/--kangaroo for counter nonces")
+	}
+}
+
+// printPlan prints a dry-run search plan with the estimated time of each phase (the
+// estimate's phases are the plan's, in order).
+func printPlan(plan *ecdsaaffine.SearchPlan, estimate ecdsaaffine.SearchEstimate) {
+	fmt.Printf("Strategy:   %s\n", plan.Strategy)
+	fmt.Printf("Signatures: %d\n", plan.Signatures)
+	fmt.Printf("Rate:       %.0f candidates/sec on %d workers\n", estimate.Rate.PerSecond(), estimate.Rate.Workers)
+
+	k := 0
+	for n, search := range plan.Searches {
+		if len(plan.Searches) > 1 {
+			signatures := len(search.Indices)
+			if search.Indices == nil {
+				signatures = plan.Signatures
+			}
+			target := search.Target
+			if target == "" {
+				target = "none"
+			}
+			fmt.Printf("\nSearch %d of %d: %d signatures, target %s\n", n+1, len(plan.Searches), signatures, target)
+		} else {
+			fmt.Println()
+		}
+		for _, phase := range search.Phases {
+			name := phase.Name
+			if phase.ARange != [2]int{} || phase.BRange != [2]int{} {
+				name = fmt.Sprintf("%s (a in [%d, %d], b in [%d, %d])", phase.Name, phase.ARange[0], phase.ARange[1], phase.BRange[0], phase.BRange[1])
+			}
+			pairs := fmt.Sprintf("%d pairs", phase.Pairs)
+			if phase.ExcludedPairs > 0 {
+				pairs += fmt.Sprintf(" (%d excluded)", phase.ExcludedPairs)
+			}
+			fmt.Printf("  %-70s %-24s %12.3g candidates  %v\n", name, pairs, phase.Candidates, estimate.Phases[k].Duration.Round(time.Second))
+			for _, pattern := range phase.Patterns {
+				fmt.Printf("      %-40s a=%s, b=%s\n", pattern.Name, pattern.A, pattern.B)
+			}
+			k++
+		}
+	}
+
+	fmt.Printf("\nWorst case: %.3g candidates, %v, ~%.1f MB memory\n",
+		estimate.Candidates, estimate.Duration.Round(time.Second), float64(estimate.MemoryBytes)/(1<<20))
+	if estimate.Duration > 24*time.Hour {
+		fmt.Println("Warning: this search can take more than a day; consider narrowing --a-range/--b-range or using --bsgs/--kangaroo for counter nonces")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// pluginsEnv lists Go plugins to load before any command runs, separated like PATH.
+const pluginsEnv = "RECOVERY_PLUGINS"
+
+// loadPlugins opens each Go plugin (built with go build -buildmode=plugin against the
+// same version of this module) in paths. Opening a plugin runs its init functions,
+// which register its strategies and parsers with ecdsaaffine.RegisterStrategy and
+// RegisterParser; the plugin needs no exported symbols.
+func loadPlugins(paths []string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("plugin %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// loadEnvPlugins loads the plugins in RECOVERY_PLUGINS and exits on failure.
+func loadEnvPlugins() {
+	if err := loadPlugins(filepath.SplitList(os.Getenv(pluginsEnv))); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", pluginsEnv, err)
+		os.Exit(1)
+	}
+}
+
+// newParser returns the signature parser registered for a --format value, exiting if
+// there is none.
+func newParser(format string) ecdsaaffine.SignatureParser {
+	parser, err := ecdsaaffine.NewParser(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --format: %v\n", err)
+		os.Exit(1)
+	}
+	return parser
+}
+
+// splitPlugins splits a --plugin value, a comma-separated list of paths.
+func splitPlugins(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// proofJSON is the machine-readable form of a proof of compromise. It carries no key
+// material: the key, nonces and anything derived from them stay inside the tool.
+type proofJSON struct {
+	PublicKey     string `json:"public_key"`
+	Challenge     string `json:"challenge"`
+	Digest        string `json:"digest"`    // SHA-256 signed by the proof
+	Signature     string `json:"signature"` // DER-encoded ECDSA signature
+	A             string `json:"a"`
+	B             string `json:"b"`
+	SignaturePair [2]int `json:"signature_pair"`
+	Verified      bool   `json:"verified"`
+	Pattern       string `json:"pattern"`
+}
+
+// printProof prints a proof of compromise for result instead of the key.
+func printProof(result *ecdsaaffine.RecoveryResult, challenge string, asJSON bool) {
+	proof, err := ecdsaaffine.ProveCompromise(result.PrivateKey, []byte(challenge))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to create proof: %v\n", err)
+		os.Exit(1)
+	}
+	out := proofJSON{
+		PublicKey:     hex.EncodeToString(proof.PublicKey),
+		Challenge:     challenge,
+		Digest:        hex.EncodeToString(ecdsaaffine.ProofDigest(proof.Challenge)),
+		Signature:     hex.EncodeToString(proof.Signature),
+		A:             result.Relationship.A.String(),
+		B:             result.Relationship.B.String(),
+		SignaturePair: result.SignaturePair,
+		Verified:      result.Verified,
+		Pattern:       result.Pattern,
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(out); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("\n[+] Successfully recovered private key (not shown: --proof-only)\n")
+	fmt.Printf("    Public key: %s\n", out.PublicKey)
+	fmt.Printf("    Relationship: k2 = %s*k1 + %s\n", out.A, out.B)
+	fmt.Printf("    Signature pair: (%d, %d)\n", out.SignaturePair[0], out.SignaturePair[1])
+	if result.Verified {
+		fmt.Println("    ✓ Verified against public key!")
+	}
+	fmt.Printf("\n    Proof of compromise\n")
+	fmt.Printf("    Challenge: %q\n", out.Challenge)
+	fmt.Printf("    Digest:    %s\n", out.Digest)
+	fmt.Printf("    Signature: %s\n", out.Signature)
+}
+
+// runVerifyProof implements "recovery verify-proof": check a --proof-only --json output.
+func runVerifyProof(args []string) {
+	fs := flag.NewFlagSet("verify-proof", flag.ExitOnError)
+	var (
+		in        = fs.String("in", "", "Proof file written by --proof-only --json")
+		publicKey = fs.String("public-key", "", "Public key the proof must be for (hex key, Ethereum or Bitcoin address, xpub or npub)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery verify-proof --in <proof.json> --public-key <key|address>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *in == "" || *publicKey == "" {
+		fmt.Fprintf(os.Stderr, "Error: --in and --public-key are required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var out proofJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid proof file: %v\n", err)
+		os.Exit(1)
+	}
+	proofPub, err1 := hex.DecodeString(out.PublicKey)
+	signature, err2 := hex.DecodeString(out.Signature)
+	for _, err := range []error{err1, err2} {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid hex: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	pub, err := ecdsaaffine.ParseTarget(*publicKey, 0, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	proof := &ecdsaaffine.CompromiseProof{PublicKey: proofPub, Challenge: []byte(out.Challenge), Signature: signature}
+	if err := ecdsaaffine.VerifyCompromiseProof(proof, pub); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: proof rejected: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Proof OK: holder of %s signed challenge %q\n", *publicKey, out.Challenge)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// reloadPatternsOnHangup re-reads the pattern file at path on every SIGHUP and sends the
+// patterns that are new to it on the returned channel, for PatternConfig.Inject. loaded
+// are the patterns the search started with. A file that no longer parses is reported
+// and ignored until the next SIGHUP.
+func reloadPatternsOnHangup(path string, loaded []ecdsaaffine.Pattern) <-chan []ecdsaaffine.Pattern {
+	seen := make(map[string]bool)
+	for _, p := range loaded {
+		seen[patternKey(p)] = true
+	}
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	inject := make(chan []ecdsaaffine.Pattern)
+	go func() {
+		for range hangups {
+			patterns, err := ecdsaaffine.LoadPatternFile(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: reloading --patterns: %v\n", err)
+				continue
+			}
+			var added []ecdsaaffine.Pattern
+			for _, p := range patterns {
+				if key := patternKey(p); !seen[key] {
+					seen[key] = true
+					added = append(added, p)
+				}
+			}
+			fmt.Fprintf(os.Stderr, "Reloaded %s: %d new patterns, tried at the next safe point\n", path, len(added))
+			if len(added) > 0 {
+				inject <- added
+			}
+		}
+	}()
+	return inject
+}
+
+// patternKey identifies a pattern by its relationship, whatever its name and priority.
+func patternKey(p ecdsaaffine.Pattern) string {
+	return p.A.String() + "," + p.B.String()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// runSample implements "recovery sample": a representative subset of a large dataset,
+// for a quick search before the full one.
+func runSample(args []string) {
+	fs := flag.NewFlagSet("sample", flag.ExitOnError)
+	var (
+		signaturesFile = fs.String("signatures", "", "Signatures file or URL")
+		format         = fs.String("format", "json", "Signature file format (json, csv, store, pkcs11 or keystore)")
+		size           = fs.Int("size", 1000, "Signatures to sample, besides every signature with a repeated r")
+		run            = fs.Int("run", 2, "Consecutive signatures of a signer taken at each sampled point")
+		out            = fs.String("out", "", "Output JSON path (- for stdout)")
+		indices        = fs.String("indices", "", "Write the dataset position of each sampled signature to this JSON file")
+	)
+	filters := addFilterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery sample --signatures <file> --out <file> [--size n] [--run n] [--indices file]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *signaturesFile == "" || *out == "" {
+		fmt.Fprintf(os.Stderr, "Error: --signatures and --out are required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *size < 2 {
+		fmt.Fprintf(os.Stderr, "Error: --size must be at least 2\n")
+		os.Exit(1)
+	}
+
+	signatures, err := filters.apply(newParser(*format)).ParseSignatures(*signaturesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	sample := ecdsaaffine.SampleSignatures(signatures, ecdsaaffine.SampleOptions{Size: *size, Run: *run})
+	err = writeSignaturesJSON(*out, sample.Signatures)
+	if err == nil && *indices != "" {
+		var data []byte
+		if data, err = json.Marshal(sample.Indices); err == nil {
+			err = os.WriteFile(*indices, append(data, '\n'), 0o644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Sampled %d of %d signatures (%d kept for a repeated r)\n", len(sample.Signatures), len(signatures), sample.DuplicateR)
+}
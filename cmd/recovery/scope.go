@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
+)
+
+// runScope implements "recovery scope": given a known or recovered private key, computes
+// every signature's nonce and reports which signatures used weak nonces, and when.
+func runScope(args []string) {
+	fs := flag.NewFlagSet("scope", flag.ExitOnError)
+	var (
+		signaturesFile = fs.String("signatures", "", "Path or URL (https://, s3://, gs://) of signatures file (JSON, CSV or local signature store) by the key")
+		format         = fs.String("format", "json", "Signature file format (json, csv, store, pkcs11 or keystore)")
+		privateKey     = fs.String("private-key", "", "Known or recovered private key (decimal or 0x hex)")
+		jsonOutput     = fs.Bool("json", false, "Print the findings as JSON")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery scope --signatures <file> --private-key <key> [--format json|csv|store|pkcs11|keystore]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *signaturesFile == "" || *privateKey == "" {
+		fmt.Fprintf(os.Stderr, "Error: --signatures and --private-key are required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	key, ok := new(big.Int).SetString(*privateKey, 0)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: invalid --private-key (want decimal or 0x hex)\n")
+		os.Exit(1)
+	}
+
+	signatures, err := newParser(*format).ParseSignatures(*signaturesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	nonces, err := ecdsaaffine.RecoverNonces(&ecdsaaffine.RecoveryResult{PrivateKey: key}, signatures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	timestamps := make([]time.Time, len(signatures))
+	for i, sig := range signatures {
+		if !ecdsaaffine.NonceMatchesR(sig, key) {
+			fmt.Fprintf(os.Stderr, "Error: signature %d was not made by this key\n", i)
+			os.Exit(1)
+		}
+		timestamps[i] = sig.Timestamp
+	}
+
+	exposure := nonceanalysis.Scope(nonces, timestamps, ecdsaaffine.Secp256k1CurveOrder, nonceanalysis.DefaultOptions())
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(exposure); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	exposure.WriteText(os.Stdout)
+}
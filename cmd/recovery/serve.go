@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/jsonapi"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/jobqueue"
+)
+
+// runServe implements "recovery serve": a daemon running recovery requests (the JSON of
+// the WebAssembly and C builds) as jobs behind the jobqueue REST API, with /healthz and
+// /readyz for container orchestrators. Every flag defaults to an environment variable.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		addr            = fs.String("addr", envString("RECOVERY_ADDR", "127.0.0.1:8080"), "Listen address; any but a loopback one needs RECOVERY_API_TOKEN (RECOVERY_ADDR)")
+		workers         = fs.Int("workers", envInt("RECOVERY_WORKERS", 1), "Jobs run at once (RECOVERY_WORKERS)")
+		queueSize       = fs.Int("queue-size", envInt("RECOVERY_QUEUE_SIZE", 100), "Jobs waiting to run before submissions are refused (RECOVERY_QUEUE_SIZE)")
+		keep            = fs.Int("keep", envInt("RECOVERY_KEEP", 1000), "Finished jobs kept for retrieval (RECOVERY_KEEP)")
+		shutdownTimeout = fs.Duration("shutdown-timeout", envDuration("RECOVERY_SHUTDOWN_TIMEOUT", 30*time.Second), "On SIGTERM, how long running jobs may finish before they are cancelled (RECOVERY_SHUTDOWN_TIMEOUT)")
+		maxCandidates   = fs.Int("max-candidates", envInt("RECOVERY_MAX_CANDIDATES", 0), "Most range-search candidates a job may test, 0 for no limit (RECOVERY_MAX_CANDIDATES)")
+		maxCPUTime      = fs.Duration("max-cpu-time", envDuration("RECOVERY_MAX_CPU_TIME", 0), "Most CPU time a job's range search may use, 0 for no limit (RECOVERY_MAX_CPU_TIME)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery serve [--addr 127.0.0.1:8080] [--workers n] [--queue-size n] [--shutdown-timeout 30s] [--max-candidates n] [--max-cpu-time d]\n")
+		fmt.Fprintf(os.Stderr, "  Set RECOVERY_API_TOKEN to require \"Authorization: Bearer <token>\" on /v1/; it is required to listen beyond loopback.\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// Results hold recovered private keys, so only loopback is served without a token
+	token := os.Getenv("RECOVERY_API_TOKEN")
+	if token == "" && !isLoopback(*addr) {
+		fmt.Fprintf(os.Stderr, "Error: --addr %s is reachable from other hosts; set RECOVERY_API_TOKEN or listen on 127.0.0.1\n", *addr)
+		os.Exit(1)
+	}
+
+	limits := jsonapi.Options{MaxCandidates: int64(*maxCandidates), MaxCPUTime: *maxCPUTime}
+	run := func(ctx context.Context, request []byte) (json.RawMessage, error) {
+		return runJob(ctx, request, limits)
+	}
+	queue := jobqueue.New(run, jobqueue.Options{Workers: *workers, Capacity: *queueSize, Keep: *keep, CheckUpdate: jsonapi.CheckUpdate})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !queue.Accepting() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("/v1/", requireToken(token, jobqueue.NewHandler(queue)))
+	server := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "Serving on %s with %d worker(s)\n", *addr, *workers)
+
+	select {
+	case err := <-serveErr:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Refuse new jobs (and fail /readyz) first, then let running jobs finish while
+	// clients can still fetch results, and stop the server last
+	fmt.Fprintf(os.Stderr, "Shutting down (waiting up to %s for running jobs)...\n", *shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := queue.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Cancelled running jobs: %v\n", err)
+	}
+	serverCtx, cancelServer := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelServer()
+	if err := server.Shutdown(serverCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runJob runs one recovery request under the daemon's quota caps, pausing when the queue
+// preempts it and adding the patterns of the pattern files sent as updates. A request that finds no key fails the job; one stopped by its quota fails
+// with the partial response (its search report) as the result.
+func runJob(ctx context.Context, request []byte, limits jsonapi.Options) (json.RawMessage, error) {
+	limits.Pauses = jobqueue.Pauses(ctx)
+	limits.Updates = jobqueue.Updates(ctx)
+	resp, err := jsonapi.Run(ctx, request, limits)
+	if resp == nil {
+		return nil, err
+	}
+	result, marshalErr := json.Marshal(resp)
+	if err == nil {
+		err = marshalErr
+	}
+	return result, err
+}
+
+// requireToken rejects requests without the bearer token, unless token is empty.
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback reports whether the listen address addr only accepts local connections: a
+// loopback IP or localhost. An empty host listens on every interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func envString(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return n
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return d
+}
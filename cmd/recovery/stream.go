@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// detectionJSON is one line of "recovery stream --json" output.
+type detectionJSON struct {
+	PublicKey     string    `json:"public_key"`
+	PrivateKeyHex string    `json:"private_key_hex"`
+	A             string    `json:"a"`
+	B             string    `json:"b"`
+	Pattern       string    `json:"pattern"`
+	EventIDs      [2]string `json:"event_ids"`
+}
+
+// runStream implements "recovery stream": reads signature events (one JSON object per
+// line, see ecdsaaffine.SignatureEvent) from stdin, e.g. piped from a Kafka or NATS
+// consumer, and reports every signer whose key a new signature gives away. With
+// --reuse-only, only nonce reuse is detected (ecdsaaffine.ReuseMonitor).
+func runStream(args []string) {
+	fs := flag.NewFlagSet("stream", flag.ExitOnError)
+	var (
+		window     = fs.Int("window", ecdsaaffine.DefaultStreamWindow, "Recent signatures kept per signer; each new signature is paired with its signer's window")
+		reuseOnly  = fs.Bool("reuse-only", false, "Only detect nonce reuse, remembering each signer's r values for --ttl (for streams too busy for the affine search)")
+		ttl        = fs.Duration("ttl", ecdsaaffine.DefaultReuseTTL, "How long --reuse-only remembers an r value")
+		aRange     = fs.String("a-range", "1,1", "Range for a values searched on each new pair, after the common patterns (format: min,max)")
+		bRange     = fs.String("b-range", "-1000,10000", "Range for b values searched on each new pair (format: min,max)")
+		notifyURL  = fs.String("notify-url", "", "POST a JSON summary (no key material) to this webhook for every key found")
+		jsonOutput = fs.Bool("json", false, "Print each recovered key as a JSON line")
+		verbose    = fs.Bool("verbose", false, "Log every pattern and range tried on each pair")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: <consumer> | recovery stream [--window n | --reuse-only [--ttl 24h]] [--json]\n")
+		fmt.Fprintf(os.Stderr, "  e.g. kcat -C -b broker:9092 -t signatures -u | recovery stream\n")
+		fmt.Fprintf(os.Stderr, "       nats sub signatures --raw | recovery stream\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var consume func(context.Context, io.Reader, func(*ecdsaaffine.Detection)) error
+	if *reuseOnly {
+		consume = ecdsaaffine.NewReuseMonitor(*ttl).Consume
+	} else {
+		consume = ecdsaaffine.NewDetector(newPairClient(*aRange, *bRange, *verbose), *window).Consume
+	}
+	notify := newNotifier(*notifyURL, "stdin")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	fmt.Fprintf(os.Stderr, "Reading signature events from stdin (Ctrl-C to stop)...\n")
+	found := 0
+	err := consume(ctx, os.Stdin, func(d *ecdsaaffine.Detection) {
+		found++
+		notify.found(d.Result)
+		if *jsonOutput {
+			json.NewEncoder(os.Stdout).Encode(detectionJSON{
+				PublicKey:     fmt.Sprintf("%x", d.PublicKey),
+				PrivateKeyHex: "0x" + d.Result.PrivateKey.Text(16),
+				A:             d.Result.Relationship.A.String(),
+				B:             d.Result.Relationship.B.String(),
+				Pattern:       d.Result.Pattern,
+				EventIDs:      d.EventIDs,
+			})
+			return
+		}
+		fmt.Printf("[+] Recovered the key of signer %x\n", d.PublicKey)
+		fmt.Printf("    Private key: 0x%s\n", d.Result.PrivateKey.Text(16))
+		fmt.Printf("    Relationship: k2 = %s*k1 + %s (%s)\n", d.Result.Relationship.A, d.Result.Relationship.B, d.Result.Pattern)
+		fmt.Printf("    Events: %q and %q\n", d.EventIDs[0], d.EventIDs[1])
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		notify.failed(err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Stream ended, %d keys recovered\n", found)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/eddsaaffine"
+)
+
+// maxListedFailures caps the signatures "recovery verify" lists by index.
+const maxListedFailures = 10
+
+// runVerify implements "recovery verify": checks every signature of a signatures file
+// as the parser reads it, so encoding mistakes (for ECDSA, a z computed differently from
+// the signer's hash) show up before a search that cannot succeed.
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	var (
+		signaturesFile = fs.String("signatures", "", "Signatures file")
+		curve          = fs.String("curve", "ecdsa", "Signature scheme (ecdsa or eddsa)")
+		format         = fs.String("format", "json", "ECDSA signature file format (json, csv, store, pkcs11 or keystore); EdDSA files are JSON")
+		publicKey      = fs.String("public-key", "", "Key to verify against instead of each signature's own key (any --public-key format of the curve)")
+		endianness     = fs.String("endianness", "big", "Byte order of hex r and s in EdDSA files: big (the integer) or little (the bytes as in the signature); recovery diagnose detects it")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery verify --signatures file [--curve ecdsa|eddsa] [--public-key key] [--endianness big|little]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *signaturesFile == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var (
+		verify func(i int) (bool, error)
+		count  int
+		err    error
+	)
+	switch *curve {
+	case "ecdsa":
+		var key []byte
+		if *publicKey != "" {
+			if key, err = ecdsaaffine.ParseTarget(*publicKey, 0, 0); err != nil {
+				break
+			}
+		}
+		var signatures []*ecdsaaffine.Signature
+		if signatures, err = newParser(*format).ParseSignatures(*signaturesFile); err != nil {
+			break
+		}
+		count = len(signatures)
+		verify = func(i int) (bool, error) { return ecdsaaffine.VerifySignature(signatures[i], key) }
+	case "eddsa":
+		var key []byte
+		if *publicKey != "" {
+			if key, err = eddsaaffine.ParsePublicKey([]byte(*publicKey)); err != nil {
+				break
+			}
+		}
+		var order eddsaaffine.Endianness
+		if order, err = eddsaaffine.ParseEndianness(*endianness); err != nil {
+			break
+		}
+		var signatures []*eddsaaffine.Signature
+		if signatures, err = (&eddsaaffine.JSONParser{Endianness: order}).ParseSignatures(*signaturesFile); err != nil {
+			break
+		}
+		count = len(signatures)
+		verify = func(i int) (bool, error) { return eddsaaffine.VerifySignature(signatures[i], key) }
+	default:
+		err = fmt.Errorf("--curve must be ecdsa or eddsa")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var failed []int
+	for i := 0; i < count; i++ {
+		ok, err := verify(i)
+		if err != nil || !ok {
+			failed = append(failed, i)
+		}
+		if err != nil && len(failed) <= maxListedFailures {
+			fmt.Printf("  Signature %d: %v\n", i, err)
+		}
+	}
+
+	fmt.Printf("%d of %d signatures verify\n", count-len(failed), count)
+	if len(failed) == 0 {
+		return
+	}
+	listed := failed
+	if len(listed) > maxListedFailures {
+		listed = listed[:maxListedFailures]
+	}
+	fmt.Printf("[-] Failing: %v", listed)
+	if len(failed) > len(listed) {
+		fmt.Printf(" and %d more", len(failed)-len(listed))
+	}
+	fmt.Println()
+	if *curve == "eddsa" {
+		fmt.Printf("    Find the encoding that verifies: recovery diagnose --signatures %s --index %d\n", *signaturesFile, failed[0])
+	} else {
+		fmt.Println("    Check that z is the message hash the signer used (--format json reads a \"z\" field if present)")
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// runWatch implements "recovery watch": follows a growing JSON Lines or CSV file, or a
+// directory of them, and searches each new signature pair as records are appended.
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	var (
+		signaturesFile = fs.String("signatures", "", "JSON Lines or CSV file (.csv) to follow, or a directory of them")
+		publicKey      = fs.String("public-key", "", "Key to verify against (as for recovery); without it, only records carrying signer keys can be verified")
+		interval       = fs.Duration("interval", ecdsaaffine.DefaultWatchInterval, "How often to check for new records")
+		window         = fs.Int("window", 0, "Pair each new signature with at most this many signatures before it (0 = all)")
+		aRange         = fs.String("a-range", "1,1", "Range for a values searched on each new pair, after the common patterns (format: min,max)")
+		bRange         = fs.String("b-range", "-1000,10000", "Range for b values searched on each new pair (format: min,max)")
+		notifyURL      = fs.String("notify-url", "", "POST a JSON summary (no key material) to this webhook when a key is found or the watch fails")
+		jsonOutput     = fs.Bool("json", false, "Print the recovery result as JSON")
+		verbose        = fs.Bool("verbose", false, "Log every pattern and range tried on each pair")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: recovery watch --signatures <file|dir> [--public-key <key>] [--interval 5s] [--window n]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *signaturesFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: --signatures is required\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	client := newPairClient(*aRange, *bRange, *verbose)
+	notify := newNotifier(*notifyURL, *signaturesFile)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	fmt.Fprintf(os.Stderr, "Watching %s (Ctrl-C to stop)...\n", *signaturesFile)
+	result, err := client.WatchSignatures(ctx, *signaturesFile, *publicKey, ecdsaaffine.WatchOptions{
+		Interval: *interval,
+		Window:   *window,
+		OnPoll: func(p ecdsaaffine.WatchProgress) {
+			fmt.Fprintf(os.Stderr, "%d signatures (+%d), %d new pairs searched, no key found\n", p.Signatures, p.New, p.Pairs)
+		},
+	})
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Stopped watching, no key found\n")
+		os.Exit(1)
+	}
+	if err != nil {
+		notify.failed(err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printResult(result, nil, *signaturesFile, outputOptions{JSON: *jsonOutput, Notify: notify})
+}
+
+// newPairClient returns the client "watch" and "stream" search each new signature pair
+// with: same-nonce detection, the common patterns, then one bounded range, so that the
+// search of a pair ends quickly. The strategy's log is discarded unless verbose.
+func newPairClient(aRange, bRange string, verbose bool) *ecdsaaffine.Client {
+	aMin, aMax, err := parseRange(aRange)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --a-range: %v\n", err)
+		os.Exit(1)
+	}
+	bMin, bMax, err := parseRange(bRange)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --b-range: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger := log.New(io.Discard, "", 0)
+	if verbose {
+		logger = log.Default()
+	}
+	return ecdsaaffine.NewClient(
+		ecdsaaffine.WithStrategy(ecdsaaffine.NewChainStrategy(
+			ecdsaaffine.NewSameNonceStrategy(),
+			ecdsaaffine.NewPatternStrategy(),
+			ecdsaaffine.NewRangeStrategy([2]int{aMin, aMax}, [2]int{bMin, bMax}),
+		)),
+		ecdsaaffine.WithLogger(logger),
+	)
+}
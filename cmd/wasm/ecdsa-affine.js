@@ -0,0 +1,27 @@
+// Loader for the WebAssembly build (make wasm). Needs wasm_exec.js from the Go
+// distribution ($(go env GOROOT)/misc/wasm or lib/wasm), loaded first.
+//
+//   const recover = await loadEcdsaAffine("ecdsa-affine.wasm");
+//   const result = await recover({signatures: [{r: "0x..", s: "0x..", z: "0x.."}, ...]});
+//   if (result.error) { ... } else { console.log(result.private_key_hex); }
+//
+// recover takes the request object of internal/jsonapi (curve, signatures, public_key,
+// strategy, timeout_ms) and resolves to the response object; it never rejects. Give r, s
+// and z as strings (hex or decimal): JavaScript numbers lose the low digits of 256-bit
+// values. A request already encoded as JSON text can be passed as a string.
+async function loadEcdsaAffine(url) {
+  const go = new Go();
+  const source = fetch(url);
+  const { instance } = WebAssembly.instantiateStreaming
+    ? await WebAssembly.instantiateStreaming(source, go.importObject)
+    : await WebAssembly.instantiate(await (await source).arrayBuffer(), go.importObject);
+  go.run(instance);
+  return async (request) => {
+    const text = typeof request === "string" ? request : JSON.stringify(request);
+    return JSON.parse(await globalThis.ecdsaAffineRecover(text));
+  };
+}
+
+if (typeof module !== "undefined") {
+  module.exports = { loadEcdsaAffine };
+}
@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+// Command wasm is the WebAssembly build of the recovery math, for browsers and Node.js.
+// It registers one global function, ecdsaAffineRecover(requestJSON), which returns a
+// Promise of the response JSON (see internal/jsonapi for both layouts). The search runs
+// in a goroutine so that the page stays responsive while it does.
+//
+// Build it with make wasm; ecdsa-affine.js loads it.
+package main
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/jsonapi"
+)
+
+func main() {
+	js.Global().Set("ecdsaAffineRecover", js.FuncOf(recoverKey))
+	select {} // keep the exports alive
+}
+
+func recoverKey(this js.Value, args []js.Value) any {
+	request := ""
+	if len(args) > 0 {
+		request = args[0].String()
+	}
+	handler := js.FuncOf(func(this js.Value, promise []js.Value) any {
+		resolve := promise[0]
+		go func() {
+			resolve.Invoke(string(jsonapi.Recover(context.Background(), []byte(request))))
+		}()
+		return nil
+	})
+	defer handler.Release()
+	return js.Global().Get("Promise").New(handler)
+}
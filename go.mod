@@ -4,4 +4,10 @@ go 1.21
 
 require github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
 
-require filippo.io/edwards25519 v1.1.0 // indirect
+require (
+	filippo.io/age v1.2.1
+	filippo.io/edwards25519 v1.1.0
+	golang.org/x/crypto v0.24.0
+)
+
+require golang.org/x/sys v0.21.0 // indirect
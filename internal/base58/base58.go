@@ -0,0 +1,83 @@
+// Package base58 implements the Bitcoin base58 alphabet and the base58check encoding
+// (a payload followed by the first four bytes of its double SHA-256).
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ErrChecksum is returned by CheckDecode when the checksum does not match.
+var ErrChecksum = errors.New("base58: checksum mismatch")
+
+// Encode encodes data in base58; each leading zero byte becomes a leading '1'.
+func Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	var digits []byte
+	n := new(big.Int).SetBytes(data)
+	radix, mod := big.NewInt(58), new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, alphabet[mod.Int64()])
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return strings.Repeat("1", zeros) + string(digits)
+}
+
+// Decode decodes a base58 string.
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := zeros; i < len(s); i++ {
+		digit := strings.IndexByte(alphabet, s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("base58: invalid character %q at position %d", s[i], i)
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(digit)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// CheckEncode appends the 4-byte checksum to payload and encodes the result.
+func CheckEncode(payload []byte) string {
+	return Encode(append(append([]byte(nil), payload...), checksum(payload)...))
+}
+
+// CheckDecode decodes a base58check string and returns the payload without its checksum.
+func CheckDecode(s string) ([]byte, error) {
+	data, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errors.New("base58: too short for a checksum")
+	}
+	payload := data[:len(data)-4]
+	if !bytes.Equal(checksum(payload), data[len(data)-4:]) {
+		return nil, ErrChecksum
+	}
+	return payload, nil
+}
+
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
@@ -0,0 +1,56 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string
+	}{
+		{"", ""},
+		{"00", "1"},
+		{"0000", "11"},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.hex)
+		if got := Encode(data); got != tt.want {
+			t.Errorf("Encode(%s) = %q, want %q", tt.hex, got, tt.want)
+		}
+		decoded, err := Decode(tt.want)
+		if err != nil || !bytes.Equal(decoded, data) {
+			t.Errorf("Decode(%q) = %x, %v; want %s", tt.want, decoded, err, tt.hex)
+		}
+	}
+
+	if _, err := Decode("0OIl"); err == nil {
+		t.Error("expected an error for characters outside the alphabet")
+	}
+}
+
+func TestCheckEncodeDecode(t *testing.T) {
+	// P2PKH address of the compressed public key for private key 1
+	const address = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	payload, err := CheckDecode(address)
+	if err != nil {
+		t.Fatalf("CheckDecode failed: %v", err)
+	}
+	if want := "00751e76e8199196d454941c45d1b3a323f1433bd6"; hex.EncodeToString(payload) != want {
+		t.Errorf("payload = %x, want %s", payload, want)
+	}
+	if got := CheckEncode(payload); got != address {
+		t.Errorf("CheckEncode = %q, want %q", got, address)
+	}
+
+	if _, err := CheckDecode("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMh"); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expected ErrChecksum for a mistyped address, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package bech32 implements the bech32 (BIP-173) and bech32m (BIP-350) encodings, and
+// segwit address decoding on top of them.
+package bech32
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Variant is the checksum constant distinguishing bech32 from bech32m.
+type Variant uint32
+
+const (
+	Bech32  Variant = 1
+	Bech32m Variant = 0x2bc830a3
+)
+
+func polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// Encode encodes 5-bit data groups under hrp with the given checksum variant.
+func Encode(hrp string, data []byte, variant Variant) string {
+	values := append(hrpExpand(hrp), data...)
+	mod := polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ uint32(variant)
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(charset[(mod>>(5*(5-i)))&31])
+	}
+	return sb.String()
+}
+
+// Decode decodes a bech32 or bech32m string into its lowercase human-readable part and
+// 5-bit data groups (checksum removed), reporting which variant the checksum matched.
+func Decode(s string) (hrp string, data []byte, variant Variant, err error) {
+	if len(s) > 90 {
+		return "", nil, 0, fmt.Errorf("bech32: string too long (%d characters)", len(s))
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.New("bech32: mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, 0, errors.New("bech32: missing separator or checksum")
+	}
+	hrp = s[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, errors.New("bech32: invalid character in human-readable part")
+		}
+	}
+	for _, c := range s[sep+1:] {
+		d := strings.IndexRune(charset, c)
+		if d < 0 {
+			return "", nil, 0, fmt.Errorf("bech32: invalid character %q", c)
+		}
+		data = append(data, byte(d))
+	}
+
+	switch Variant(polymod(append(hrpExpand(hrp), data...))) {
+	case Bech32:
+		variant = Bech32
+	case Bech32m:
+		variant = Bech32m
+	default:
+		return "", nil, 0, errors.New("bech32: checksum mismatch")
+	}
+	return hrp, data[:len(data)-6], variant, nil
+}
+
+// ConvertBits regroups data from fromBits-bit to toBits-bit groups. With pad, a final
+// partial group is zero-padded; without it, leftover bits must be zero padding.
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+	var out []byte
+	for _, v := range data {
+		if uint(v)>>fromBits != 0 {
+			return nil, fmt.Errorf("bech32: value %d exceeds %d bits", v, fromBits)
+		}
+		acc = acc<<fromBits | uint(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errors.New("bech32: invalid padding")
+	}
+	return out, nil
+}
+
+// DecodeSegwit decodes a segwit address, checking that the human-readable part is hrp,
+// version 0 programs use bech32 and later versions bech32m.
+func DecodeSegwit(hrp, address string) (version byte, program []byte, err error) {
+	gotHRP, data, variant, err := Decode(address)
+	if err != nil {
+		return 0, nil, err
+	}
+	if gotHRP != hrp {
+		return 0, nil, fmt.Errorf("bech32: human-readable part %q, expected %q", gotHRP, hrp)
+	}
+	if len(data) < 1 || data[0] > 16 {
+		return 0, nil, errors.New("bech32: invalid witness version")
+	}
+	version = data[0]
+	if (version == 0) != (variant == Bech32) {
+		return 0, nil, fmt.Errorf("bech32: witness version %d with the wrong checksum variant", version)
+	}
+	program, err = ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(program) < 2 || len(program) > 40 || (version == 0 && len(program) != 20 && len(program) != 32) {
+		return 0, nil, fmt.Errorf("bech32: invalid witness program length %d", len(program))
+	}
+	return version, program, nil
+}
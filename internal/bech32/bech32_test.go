@@ -0,0 +1,76 @@
+package bech32
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	// Valid strings from BIP-173 and BIP-350
+	for _, tt := range []struct {
+		s       string
+		variant Variant
+	}{
+		{"A12UEL5L", Bech32},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", Bech32},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", Bech32},
+		{"A1LQFN3A", Bech32m},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", Bech32m},
+	} {
+		hrp, data, variant, err := Decode(tt.s)
+		if err != nil {
+			t.Errorf("Decode(%q): %v", tt.s, err)
+			continue
+		}
+		if variant != tt.variant {
+			t.Errorf("Decode(%q) variant = %x, want %x", tt.s, variant, tt.variant)
+		}
+		if got := Encode(hrp, data, variant); got != strings.ToLower(tt.s) {
+			t.Errorf("Encode round trip = %q, want %q", got, strings.ToLower(tt.s))
+		}
+	}
+
+	for _, s := range []string{
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty human-readable part
+		"x1b4n0q5v",     // invalid data character
+		"A1G7SGD8",      // checksum computed over an uppercase hrp
+		"a12UEL5L",      // mixed case
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", // corrupted checksum
+	} {
+		if _, _, _, err := Decode(s); err == nil {
+			t.Errorf("Decode(%q): expected an error", s)
+		}
+	}
+}
+
+func TestDecodeSegwit(t *testing.T) {
+	for _, tt := range []struct {
+		address string
+		version byte
+		program string
+	}{
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", 0, "751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", 1, "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	} {
+		version, program, err := DecodeSegwit("bc", tt.address)
+		if err != nil {
+			t.Errorf("DecodeSegwit(%q): %v", tt.address, err)
+			continue
+		}
+		if version != tt.version || hex.EncodeToString(program) != tt.program {
+			t.Errorf("DecodeSegwit(%q) = %d %x, want %d %s", tt.address, version, program, tt.version, tt.program)
+		}
+	}
+
+	for _, address := range []string{
+		"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",                     // wrong network
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", // v1 with a bech32 checksum
+		"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",                           // v0 program of 16 bytes
+	} {
+		if _, _, err := DecodeSegwit("bc", address); err == nil {
+			t.Errorf("DecodeSegwit(%q): expected an error", address)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package eta turns search sizes and rates into durations for the estimators of
+// pkg/ecdsaaffine and pkg/eddsaaffine.
+//
+// A wide search at a slow or throttled rate runs for longer than a time.Duration can
+// hold (about 292 years), and converting such a float64 overflows to a negative or
+// arbitrary value. Every duration here saturates at Max instead.
+package eta
+
+import (
+	"math"
+	"runtime"
+	"time"
+)
+
+// Max is the longest duration an estimate reports, standing for "practically never".
+const Max = time.Duration(math.MaxInt64)
+
+// Duration returns how long trying candidates takes at perSecond, capped at Max. It
+// returns 0 when perSecond is not positive (no calibrated rate).
+func Duration(candidates, perSecond float64) time.Duration {
+	if perSecond <= 0 || candidates <= 0 {
+		return 0
+	}
+	seconds := candidates / perSecond
+	if math.IsNaN(seconds) || seconds >= Max.Seconds() {
+		return Max
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Add returns a + b for non-negative durations, capped at Max.
+func Add(a, b time.Duration) time.Duration {
+	if a > Max-b {
+		return Max
+	}
+	return a + b
+}
+
+// Rates returns the search rate of parallel phases and of sequential phases, which run
+// on a single worker, from a rate of perSecond measured on workers workers (0 = the
+// number of CPUs). Both are capped by the throttling settings: maxCPUPercent of each
+// worker's time (0 = unlimited) and maxRate candidates/sec (0 = unlimited).
+func Rates(perSecond float64, workers, maxCPUPercent int, maxRate float64) (parallel, sequential float64) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	parallel = perSecond
+	sequential = perSecond / float64(workers)
+
+	if p := maxCPUPercent; p > 0 && p < 100 {
+		parallel *= float64(p) / 100
+		sequential *= float64(p) / 100
+	}
+	if maxRate > 0 {
+		parallel = math.Min(parallel, maxRate)
+		sequential = math.Min(sequential, maxRate)
+	}
+	return parallel, sequential
+}
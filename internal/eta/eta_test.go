@@ -0,0 +1,49 @@
+package eta
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	for _, tc := range []struct {
+		candidates, perSecond float64
+		want                  time.Duration
+	}{
+		{4995, 1000, 4995 * time.Millisecond},
+		{1000, 0, 0},
+		{0, 1000, 0},
+		// 199 * 500500001 * 45 candidates at one a second: thousands of years
+		{199 * 500500001 * 45, 1, Max},
+		{math.Inf(1), 1000, Max},
+		{1e300, 1e-300, Max},
+	} {
+		if got := Duration(tc.candidates, tc.perSecond); got != tc.want {
+			t.Errorf("Duration(%g, %g) = %v, want %v", tc.candidates, tc.perSecond, got, tc.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	if got := Add(time.Second, time.Minute); got != 61*time.Second {
+		t.Errorf("Add(1s, 1m) = %v", got)
+	}
+	if got := Add(Max, time.Second); got != Max {
+		t.Errorf("Add(Max, 1s) = %v, want Max", got)
+	}
+	if got := Add(Max-time.Hour, 2*time.Hour); got != Max {
+		t.Errorf("Add(Max-1h, 2h) = %v, want Max", got)
+	}
+}
+
+func TestRates(t *testing.T) {
+	parallel, sequential := Rates(4000, 4, 0, 0)
+	if parallel != 4000 || sequential != 1000 {
+		t.Errorf("Rates(4000, 4) = %v, %v, want 4000, 1000", parallel, sequential)
+	}
+	parallel, sequential = Rates(4000, 4, 50, 1500)
+	if parallel != 1500 || sequential != 500 {
+		t.Errorf("Throttled rates = %v, %v, want 1500, 500", parallel, sequential)
+	}
+}
@@ -0,0 +1,287 @@
+// Package jsonapi is the JSON interface of the embedded builds, the WebAssembly module
+// (cmd/wasm) and the C shared library (cmd/libecdsaaffine): a request carries the
+// signatures themselves, in the layout JSONParser reads, so nothing touches a file
+// system. It builds on every platform so that it can be tested natively; the commands
+// only move the bytes between their host and Recover.
+package jsonapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/eddsaaffine"
+)
+
+// Request is a recovery request.
+type Request struct {
+	Curve      string          `json:"curve"`      // "ecdsa" (default) or "eddsa"
+	Signatures json.RawMessage `json:"signatures"` // array of signature objects, as in a signatures file
+	PublicKey  string          `json:"public_key"` // optional, to verify the key
+	Strategy   string          `json:"strategy"`   // a registered ECDSA strategy (default "smart"); ignored for EdDSA
+	TimeoutMS  int64           `json:"timeout_ms"` // 0 for no timeout
+
+	// Quotas of the range search (RangeConfig.MaxCandidates and MaxCPUTime), 0 for none
+	MaxCandidates int64   `json:"max_candidates"`
+	MaxCPUSeconds float64 `json:"max_cpu_seconds"`
+}
+
+// Response is the result of a request: the key, or Error.
+type Response struct {
+	PrivateKey    string  `json:"private_key,omitempty"` // decimal
+	PrivateKeyHex string  `json:"private_key_hex,omitempty"`
+	A             string  `json:"a,omitempty"`
+	B             string  `json:"b,omitempty"`
+	SignaturePair []int   `json:"signature_pair,omitempty"`
+	Verified      bool    `json:"verified,omitempty"`
+	Pattern       string  `json:"pattern,omitempty"`
+	Confidence    float64 `json:"confidence,omitempty"` // ECDSA only, see ecdsaaffine.ScoreResult
+	Error         string  `json:"error,omitempty"`
+
+	// A search stopped by its quota reports the ECDSA pairs and ranges it finished, to
+	// exclude from a later search (see ecdsaaffine.SearchReport)
+	QuotaExceeded bool                      `json:"quota_exceeded,omitempty"`
+	SearchReport  *ecdsaaffine.SearchReport `json:"search_report,omitempty"`
+}
+
+// Options are the host's settings for Run.
+type Options struct {
+	// Pauses, if set, pauses (true) and resumes (false) the range search of the "smart"
+	// strategy, as ThrottleSetting.Paused does; other strategies and phases run on
+	Pauses <-chan bool
+
+	// Updates, if set, carries pattern files (see CheckUpdate) whose patterns are added to
+	// the running search of the "smart" strategy (PatternConfig.Inject); other strategies
+	// ignore them
+	Updates <-chan []byte
+
+	// MaxCandidates and MaxCPUTime cap the quotas of every request (0 for no cap). A
+	// request asks for less, not more; with a cap, only the "smart" strategy is accepted,
+	// as it is the one enforcing quotas.
+	MaxCandidates int64
+	MaxCPUTime    time.Duration
+}
+
+// Recover decodes a Request, runs the recovery and returns the encoded Response.
+// Failures are reported in the response's error field, never as a Go error, so that
+// the host only has one shape to handle.
+func Recover(ctx context.Context, request []byte) []byte {
+	resp, err := Run(ctx, request, Options{})
+	if err != nil {
+		if resp == nil {
+			resp = &Response{}
+		}
+		resp.Error = err.Error()
+	}
+	out, _ := json.Marshal(resp)
+	return out
+}
+
+// Run decodes a Request and runs the recovery. A search stopped by its quota returns an
+// error wrapping ErrQuotaExceeded together with a response carrying its SearchReport.
+func Run(ctx context.Context, request []byte, opts Options) (*Response, error) {
+	var req Request
+	if err := json.Unmarshal(request, &req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if len(req.Signatures) == 0 {
+		return nil, fmt.Errorf("request has no signatures")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops forwarding pauses and updates
+	if req.TimeoutMS > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMS)*time.Millisecond)
+		defer cancel()
+	}
+	q := quotas{
+		candidates: capQuota(req.MaxCandidates, opts.MaxCandidates),
+		cpu:        capQuota(time.Duration(req.MaxCPUSeconds*float64(time.Second)), opts.MaxCPUTime),
+		capped:     opts.MaxCandidates > 0 || opts.MaxCPUTime > 0,
+	}
+
+	switch req.Curve {
+	case "", "ecdsa":
+		return recoverECDSA(ctx, req, opts, q)
+	case "eddsa":
+		return recoverEdDSA(ctx, req, opts, q)
+	default:
+		return nil, fmt.Errorf("unknown curve %q (want ecdsa or eddsa)", req.Curve)
+	}
+}
+
+// CheckUpdate reports whether update is a pattern file that Options.Updates can carry,
+// in the format of ecdsaaffine.WritePatternFile, for either curve.
+func CheckUpdate(update []byte) error {
+	_, err := ecdsaaffine.ParsePatternFile(update)
+	return err
+}
+
+// quotas are the quotas of one request.
+type quotas struct {
+	candidates int64
+	cpu        time.Duration
+	capped     bool // the host caps quotas, so the search must enforce them
+}
+
+func (q quotas) set() bool {
+	return q.candidates > 0 || q.cpu > 0
+}
+
+// capQuota returns the smaller of a requested quota and a cap, 0 meaning none.
+func capQuota[T int64 | time.Duration](requested, limit T) T {
+	if requested <= 0 || (limit > 0 && limit < requested) {
+		return limit
+	}
+	return requested
+}
+
+func recoverECDSA(ctx context.Context, req Request, opts Options, q quotas) (*Response, error) {
+	parser := &ecdsaaffine.JSONParser{MessageField: "message", RField: "r", SField: "s", ZField: "z"}
+	signatures, err := parser.Parse(bytes.NewReader(req.Signatures))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	name := req.Strategy
+	if name == "" {
+		name = "smart"
+	}
+	strategy, err := ecdsaaffine.NewStrategy(name)
+	if err != nil {
+		return nil, err
+	}
+	smart, ok := strategy.(*ecdsaaffine.SmartBruteForceStrategy)
+	if !ok && (q.set() || q.capped) {
+		return nil, fmt.Errorf("quotas are only enforced by the smart strategy, not %q", name)
+	}
+	var report *ecdsaaffine.SearchReport
+	if ok {
+		if opts.Pauses != nil {
+			control := make(chan ecdsaaffine.ThrottleSetting)
+			smart.RangeConfig.ThrottleControl = control
+			go forwardPauses(ctx, opts.Pauses, control, func(paused bool) ecdsaaffine.ThrottleSetting {
+				return ecdsaaffine.ThrottleSetting{Paused: paused}
+			})
+		}
+		if opts.Updates != nil {
+			inject := make(chan []ecdsaaffine.Pattern)
+			smart.PatternConfig.Inject = inject
+			go forwardUpdates(ctx, opts.Updates, inject, func(p ecdsaaffine.Pattern) ecdsaaffine.Pattern { return p })
+		}
+		if q.set() {
+			smart.RangeConfig.MaxCandidates, smart.RangeConfig.MaxCPUTime = q.candidates, q.cpu
+			report = &ecdsaaffine.SearchReport{}
+			smart.Report = report
+		}
+	}
+
+	result, err := ecdsaaffine.NewClient(ecdsaaffine.WithStrategy(strategy)).RecoverKeyFromSignatures(ctx, signatures, req.PublicKey)
+	if errors.Is(err, ecdsaaffine.ErrQuotaExceeded) {
+		return &Response{QuotaExceeded: true, SearchReport: report}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &Response{
+		PrivateKey:    result.PrivateKey.String(),
+		PrivateKeyHex: fmt.Sprintf("%064x", result.PrivateKey),
+		SignaturePair: result.SignaturePair[:],
+		Verified:      result.Verified,
+		Pattern:       result.Pattern,
+		Confidence:    ecdsaaffine.ScoreResult(result, signatures).Score,
+	}
+	if result.Relationship.A != nil && result.Relationship.B != nil {
+		resp.A, resp.B = result.Relationship.A.String(), result.Relationship.B.String()
+	}
+	return resp, nil
+}
+
+func recoverEdDSA(ctx context.Context, req Request, opts Options, q quotas) (*Response, error) {
+	signatures, err := (&eddsaaffine.JSONParser{}).Parse(bytes.NewReader(req.Signatures))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	strategy := eddsaaffine.NewSmartBruteForceStrategy()
+	if opts.Pauses != nil {
+		control := make(chan eddsaaffine.ThrottleSetting)
+		strategy.RangeConfig.ThrottleControl = control
+		go forwardPauses(ctx, opts.Pauses, control, func(paused bool) eddsaaffine.ThrottleSetting {
+			return eddsaaffine.ThrottleSetting{Paused: paused}
+		})
+	}
+	if opts.Updates != nil {
+		inject := make(chan []eddsaaffine.Pattern)
+		strategy.PatternConfig.Inject = inject
+		go forwardUpdates(ctx, opts.Updates, inject, func(p ecdsaaffine.Pattern) eddsaaffine.Pattern {
+			return eddsaaffine.Pattern{A: p.A, B: p.B, Name: p.Name, Priority: p.Priority}
+		})
+	}
+	strategy.RangeConfig.MaxCandidates, strategy.RangeConfig.MaxCPUTime = q.candidates, q.cpu
+	result, err := eddsaaffine.NewClient().WithStrategy(strategy).RecoverKeyFromSignatures(ctx, signatures, req.PublicKey)
+	if errors.Is(err, eddsaaffine.ErrQuotaExceeded) {
+		return &Response{QuotaExceeded: true}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &Response{
+		PrivateKey:    result.PrivateKey.String(),
+		PrivateKeyHex: fmt.Sprintf("%064x", result.PrivateKey),
+		SignaturePair: result.SignaturePair[:],
+		Verified:      result.Verified,
+		Pattern:       result.Pattern,
+	}
+	if result.Relationship.A != nil && result.Relationship.B != nil {
+		resp.A, resp.B = result.Relationship.A.String(), result.Relationship.B.String()
+	}
+	return resp, nil
+}
+
+// forwardPauses sends the values of pauses to a strategy's throttle control until ctx is
+// done, then closes it. The strategy reads control from its range phase on, so a pause
+// sent earlier takes effect there.
+func forwardPauses[T any](ctx context.Context, pauses <-chan bool, control chan<- T, setting func(paused bool) T) {
+	defer close(control)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case paused := <-pauses:
+			select {
+			case control <- setting(paused):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// forwardUpdates sends the patterns of each pattern file on updates to a strategy's
+// PatternConfig.Inject until ctx is done, then closes it. Files that do not parse are
+// dropped; hosts reject them up front with CheckUpdate.
+func forwardUpdates[P any](ctx context.Context, updates <-chan []byte, inject chan<- []P, convert func(ecdsaaffine.Pattern) P) {
+	defer close(inject)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update := <-updates:
+			parsed, err := ecdsaaffine.ParsePatternFile(update)
+			if err != nil {
+				continue
+			}
+			patterns := make([]P, len(parsed))
+			for i, p := range parsed {
+				patterns[i] = convert(p)
+			}
+			select {
+			case inject <- patterns:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
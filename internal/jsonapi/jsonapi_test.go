@@ -0,0 +1,134 @@
+package jsonapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+func decode(t *testing.T, out []byte) Response {
+	t.Helper()
+	var resp Response
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("Invalid response %s: %v", out, err)
+	}
+	return resp
+}
+
+func TestRecover_ECDSA(t *testing.T) {
+	key, _ := flawedsigner.NewECDSAKey(flawedsigner.NewSeededReader(1898))
+	signed, err := key.Sign(flawedsigner.ECDSAMessages(3), flawedsigner.AffineFrom(big.NewInt(123456789), big.NewInt(1), big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures, _ := json.Marshal(signed)
+	request, _ := json.Marshal(Request{Signatures: signatures, PublicKey: hex.EncodeToString(key.PublicKey())})
+
+	resp := decode(t, Recover(context.Background(), request))
+	if resp.Error != "" {
+		t.Fatalf("Recovery failed: %s", resp.Error)
+	}
+	if resp.PrivateKeyHex != fmt.Sprintf("%064x", key.D) || !resp.Verified {
+		t.Errorf("Expected the verified key %064x, got %+v", key.D, resp)
+	}
+	if resp.A != "1" || resp.B != "1" || resp.Confidence != 1 {
+		t.Errorf("Expected a=1, b=1 with full confidence, got %+v", resp)
+	}
+}
+
+func TestRecover_Errors(t *testing.T) {
+	tests := []struct {
+		request string
+		want    string
+	}{
+		{`not json`, "invalid request"},
+		{`{}`, "no signatures"},
+		{`{"curve": "rsa", "signatures": []}`, "unknown curve"},
+		{`{"signatures": [{"r": 1, "s": 2, "z": 3}], "strategy": "nope"}`, "unknown strategy"},
+		{`{"signatures": [{"r": 1, "s": 2, "z": 3}]}`, "need at least 2 signatures"},
+	}
+	for _, tt := range tests {
+		resp := decode(t, Recover(context.Background(), []byte(tt.request)))
+		if !strings.Contains(resp.Error, tt.want) || resp.PrivateKey != "" {
+			t.Errorf("%s: expected an error containing %q, got %+v", tt.request, tt.want, resp)
+		}
+	}
+}
+
+func TestRun_Quota(t *testing.T) {
+	key, _ := flawedsigner.NewECDSAKey(flawedsigner.NewSeededReader(1902))
+	signed, err := key.Sign(flawedsigner.ECDSAMessages(3), flawedsigner.AffineFrom(big.NewInt(123456789), big.NewInt(977), big.NewInt(9876543)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures, _ := json.Marshal(signed)
+	request, _ := json.Marshal(Request{Signatures: signatures, MaxCandidates: 1 << 30})
+
+	// The daemon's cap wins over the larger quota the request asks for
+	resp, err := Run(context.Background(), request, Options{MaxCandidates: 10000})
+	if !errors.Is(err, ecdsaaffine.ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if resp == nil || !resp.QuotaExceeded || resp.SearchReport == nil || resp.PrivateKey != "" {
+		t.Errorf("Expected a partial response with a search report, got %+v", resp)
+	}
+
+	request, _ = json.Marshal(Request{Signatures: signatures, Strategy: "patterns"})
+	if _, err := Run(context.Background(), request, Options{MaxCandidates: 10000}); err == nil || !strings.Contains(err.Error(), "smart strategy") {
+		t.Errorf("Expected quotas to require the smart strategy, got %v", err)
+	}
+}
+
+func TestRun_Updates(t *testing.T) {
+	key, _ := flawedsigner.NewECDSAKey(flawedsigner.NewSeededReader(1924))
+	signed, err := key.Sign(flawedsigner.ECDSAMessages(3), flawedsigner.AffineFrom(big.NewInt(123456789), big.NewInt(977), big.NewInt(9876543)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures, _ := json.Marshal(signed)
+	request, _ := json.Marshal(Request{Signatures: signatures, PublicKey: hex.EncodeToString(key.PublicKey())})
+
+	// a = 977 is beyond every built-in phase: only the pattern sent as an update finds it
+	update := []byte(`{"patterns": [{"name": "vendor_lcg", "a": 977, "b": "9876543", "priority": 1}]}`)
+	if err := CheckUpdate(update); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckUpdate([]byte(`{"patterns": [{"a": "x"}]}`)); err == nil {
+		t.Error("Expected CheckUpdate to reject an invalid pattern file")
+	}
+	updates := make(chan []byte, 1)
+	updates <- update
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resp, err := Run(ctx, request, Options{Updates: updates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PrivateKeyHex != fmt.Sprintf("%064x", key.D) || resp.Pattern != "vendor_lcg" {
+		t.Errorf("Expected the key from the injected pattern, got %+v", resp)
+	}
+}
+
+func TestCapQuota(t *testing.T) {
+	tests := []struct{ requested, limit, want int64 }{
+		{0, 0, 0},
+		{5, 0, 5},
+		{0, 5, 5},
+		{3, 5, 3},
+		{8, 5, 5},
+	}
+	for _, tt := range tests {
+		if got := capQuota(tt.requested, tt.limit); got != tt.want {
+			t.Errorf("capQuota(%d, %d) = %d, want %d", tt.requested, tt.limit, got, tt.want)
+		}
+	}
+}
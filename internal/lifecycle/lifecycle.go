@@ -0,0 +1,137 @@
+// Package lifecycle manages the goroutines of a parallel search so that every goroutine
+// has exited, and nothing is left blocked on a channel, by the time the search returns.
+//
+// It is shared by pkg/ecdsaaffine and pkg/eddsaaffine. Group follows the errgroup API
+// (the module has no dependency on golang.org/x/sync); Lowest collects the first result
+// without a channel, so late finders never block; Ticker runs periodic work such as
+// progress logging and can be stopped any number of times.
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group is a set of goroutines sharing a context. The context is cancelled when the
+// first goroutine returns an error, when Stop is called, or when Wait returns.
+type Group struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+}
+
+// WithContext returns a Group and the context its goroutines should watch.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go runs f in a new goroutine. The first non-nil error cancels the group.
+func (g *Group) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Stop cancels the group's context, asking every goroutine to return.
+// It does not wait; call Wait for that.
+func (g *Group) Stop() {
+	g.cancel()
+}
+
+// Wait blocks until every goroutine has returned, then cancels the context and returns
+// the first error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// Lowest holds the offered value with the lowest order. Ordering results by their
+// position in a sequential scan makes a parallel search report the same match as the
+// scan would, whatever the scheduling; offering with order 0 gives first-come semantics.
+type Lowest[T any] struct {
+	mu    sync.Mutex
+	order int64
+	value T
+	ok    bool
+
+	// OnFirst, if set, runs once when the first value is accepted (e.g. a Group's Stop)
+	OnFirst func()
+}
+
+// Offer records v if it orders below the recorded value (or none is recorded) and reports
+// whether it did. It never blocks, so any number of goroutines may offer.
+func (l *Lowest[T]) Offer(order int64, v T) bool {
+	l.mu.Lock()
+	if l.ok && l.order <= order {
+		l.mu.Unlock()
+		return false
+	}
+	first := !l.ok
+	l.order, l.value, l.ok = order, v, true
+	l.mu.Unlock()
+
+	if first && l.OnFirst != nil {
+		l.OnFirst()
+	}
+	return true
+}
+
+// Below reports whether a value ordered below order is recorded, i.e. whether work at
+// order or later can no longer change the result.
+func (l *Lowest[T]) Below(order int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ok && l.order < order
+}
+
+// Get returns the recorded value and whether there is one.
+func (l *Lowest[T]) Get() (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.value, l.ok
+}
+
+// Ticker runs a function periodically on its own goroutine until stopped.
+type Ticker struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewTicker calls f every interval until Stop is called.
+func NewTicker(interval time.Duration, f func()) *Ticker {
+	t := &Ticker{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				f()
+			}
+		}
+	}()
+	return t
+}
+
+// Stop stops the ticker and waits for its goroutine to exit; a call to f in progress
+// completes first. Stop may be called more than once.
+func (t *Ticker) Stop() {
+	t.once.Do(func() { close(t.stop) })
+	<-t.done
+}
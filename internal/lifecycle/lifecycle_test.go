@@ -0,0 +1,164 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// checkNoLeaks fails the test if goroutines started during it are still running.
+func checkNoLeaks(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Errorf("Leaked %d goroutines", runtime.NumGoroutine()-before)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+func TestGroup_WaitsForAll(t *testing.T) {
+	checkNoLeaks(t)
+	group, ctx := WithContext(context.Background())
+	var done int32
+	for i := 0; i < 8; i++ {
+		group.Go(func() error {
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&done, 1)
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if done != 8 {
+		t.Errorf("Wait returned with %d of 8 goroutines done", done)
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the context to be cancelled after Wait")
+	}
+}
+
+func TestGroup_FirstErrorCancels(t *testing.T) {
+	checkNoLeaks(t)
+	group, ctx := WithContext(context.Background())
+	errFirst := errors.New("first")
+	group.Go(func() error { return errFirst })
+	for i := 0; i < 4; i++ {
+		group.Go(func() error {
+			<-ctx.Done()
+			return errors.New("later")
+		})
+	}
+	if err := group.Wait(); err != errFirst {
+		t.Errorf("Expected the first error, got %v", err)
+	}
+}
+
+func TestGroup_ParentCancel(t *testing.T) {
+	checkNoLeaks(t)
+	parent, cancel := context.WithCancel(context.Background())
+	group, ctx := WithContext(parent)
+	group.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+	cancel()
+	if err := group.Wait(); err != nil {
+		t.Errorf("Expected no error after parent cancel, got %v", err)
+	}
+}
+
+func TestLowest_ConcurrentOffers(t *testing.T) {
+	checkNoLeaks(t)
+	group, ctx := WithContext(context.Background())
+	first := &Lowest[int]{OnFirst: group.Stop}
+
+	var accepted int32
+	for i := 1; i <= 50; i++ {
+		i := i
+		group.Go(func() error {
+			if first.Offer(0, i) {
+				atomic.AddInt32(&accepted, 1)
+			}
+			<-ctx.Done()
+			return nil
+		})
+	}
+	group.Wait()
+
+	if accepted != 1 {
+		t.Errorf("Expected exactly one accepted offer, got %d", accepted)
+	}
+	if v, ok := first.Get(); !ok || v < 1 || v > 50 {
+		t.Errorf("Unexpected first value %d (ok=%v)", v, ok)
+	}
+}
+
+func TestLowest_Order(t *testing.T) {
+	checkNoLeaks(t)
+	var lowest Lowest[int]
+	if _, ok := lowest.Get(); ok {
+		t.Error("Expected no value")
+	}
+	if lowest.Below(0) {
+		t.Error("Expected nothing below 0 when empty")
+	}
+
+	// Offers arrive in any order; the lowest wins
+	var wg sync.WaitGroup
+	for _, order := range []int64{40, 7, 93, 12, 7, 55} {
+		wg.Add(1)
+		go func(order int64) {
+			defer wg.Done()
+			lowest.Offer(order, int(order))
+		}(order)
+	}
+	wg.Wait()
+
+	if v, _ := lowest.Get(); v != 7 {
+		t.Errorf("Expected lowest value 7, got %d", v)
+	}
+	if !lowest.Below(8) || lowest.Below(7) {
+		t.Error("Below does not match the recorded order")
+	}
+	if lowest.Offer(7, 70) {
+		t.Error("Expected an equal order not to replace the recorded value")
+	}
+}
+
+func TestTicker_StopTwice(t *testing.T) {
+	checkNoLeaks(t)
+	var mu sync.Mutex
+	calls := 0
+	ticker := NewTicker(time.Millisecond, func() {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+	time.Sleep(20 * time.Millisecond)
+	ticker.Stop()
+	ticker.Stop()
+
+	mu.Lock()
+	after := calls
+	mu.Unlock()
+	if after == 0 {
+		t.Error("Expected the ticker to fire")
+	}
+	time.Sleep(5 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != after {
+		t.Error("Ticker fired after Stop")
+	}
+}
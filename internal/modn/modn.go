@@ -0,0 +1,243 @@
+// Package modn implements arithmetic modulo a fixed odd modulus below 2^256 on four
+// 64-bit words in Montgomery form, for the inner loops of the range searches: no
+// allocation and no normalization per operation, which math/big spends most of its time
+// on for numbers of this size. Secp256k1N and Ed25519L are the moduli the searches use.
+//
+// The multiplications rely on math/bits.Mul64, which compiles to a single instruction
+// on 64-bit platforms and is emulated elsewhere; Native reports which, so that callers
+// can keep math/big where it is the faster of the two.
+package modn
+
+import (
+	"math/big"
+	"math/bits"
+	"runtime"
+)
+
+// Elem is a residue in Montgomery form, x·2^256 mod m, least significant word first.
+// The zero value is 0. An Elem belongs to the Modulus that produced it.
+type Elem [4]uint64
+
+// Modulus is an odd modulus m < 2^256 with its Montgomery constants.
+type Modulus struct {
+	m   [4]uint64
+	inv uint64 // -m^-1 mod 2^64
+	r2  Elem   // 2^512 mod m, to convert into Montgomery form
+	one Elem   // 2^256 mod m, the Montgomery form of 1
+	big *big.Int
+}
+
+// Secp256k1N and Ed25519L are the group orders of secp256k1 and Ed25519.
+var (
+	Secp256k1N = NewModulus(mustHex("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"))
+	Ed25519L   = NewModulus(mustHex("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed"))
+)
+
+func mustHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("modn: invalid constant " + s)
+	}
+	return v
+}
+
+// NewModulus returns the modulus m. It panics unless m is odd, greater than 2 and below
+// 2^256. Inverse additionally requires m to be prime.
+func NewModulus(m *big.Int) *Modulus {
+	if m.Bit(0) == 0 || m.BitLen() > 256 || m.Cmp(big.NewInt(2)) <= 0 {
+		panic("modn: modulus must be odd, greater than 2 and below 2^256")
+	}
+	mod := &Modulus{big: new(big.Int).Set(m)}
+	words(&mod.m, m)
+
+	// Newton's iteration doubles the correct low bits of m^-1 mod 2^64 each step
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - mod.m[0]*inv
+	}
+	mod.inv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), 256)
+	words((*[4]uint64)(&mod.one), new(big.Int).Mod(r, m))
+	words((*[4]uint64)(&mod.r2), new(big.Int).Mod(new(big.Int).Mul(r, r), m))
+	return mod
+}
+
+// Big returns the modulus as a big.Int, which must not be modified.
+func (m *Modulus) Big() *big.Int {
+	return m.big
+}
+
+// words sets w to v, which must be non-negative and below 2^256.
+func words(w *[4]uint64, v *big.Int) {
+	var buf [32]byte
+	v.FillBytes(buf[:])
+	for i := range w {
+		w[i] = uint64(buf[31-8*i]) | uint64(buf[30-8*i])<<8 | uint64(buf[29-8*i])<<16 | uint64(buf[28-8*i])<<24 |
+			uint64(buf[27-8*i])<<32 | uint64(buf[26-8*i])<<40 | uint64(buf[25-8*i])<<48 | uint64(buf[24-8*i])<<56
+	}
+}
+
+// SetBig sets z to x mod m, for any x, and returns z.
+func (m *Modulus) SetBig(z *Elem, x *big.Int) *Elem {
+	if x.Sign() < 0 || x.Cmp(m.big) >= 0 {
+		x = new(big.Int).Mod(x, m.big)
+	}
+	words((*[4]uint64)(z), x)
+	return m.Mul(z, z, &m.r2)
+}
+
+// SetInt64 sets z to v mod m and returns z.
+func (m *Modulus) SetInt64(z *Elem, v int64) *Elem {
+	*z = Elem{}
+	if v >= 0 {
+		z[0] = uint64(v)
+		if m.m[1]|m.m[2]|m.m[3] == 0 && z[0] >= m.m[0] {
+			z[0] %= m.m[0]
+		}
+		return m.Mul(z, z, &m.r2)
+	}
+	m.SetInt64(z, -(v + 1)) // -v - 1 cannot overflow
+	m.Add(z, z, &m.one)
+	return m.Neg(z, z)
+}
+
+// ToBig returns x as a big.Int in [0, m).
+func (m *Modulus) ToBig(x *Elem) *big.Int {
+	buf := m.Bytes(x)
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// Bytes returns x in [0, m) as 32 big-endian bytes.
+func (m *Modulus) Bytes(x *Elem) [32]byte {
+	var plain Elem
+	m.Mul(&plain, x, &Elem{1})
+	var buf [32]byte
+	for i, w := range plain {
+		for k := 0; k < 8; k++ {
+			buf[31-8*i-k] = byte(w >> (8 * k))
+		}
+	}
+	return buf
+}
+
+// IsZero reports whether x is 0.
+func (x *Elem) IsZero() bool {
+	return x[0]|x[1]|x[2]|x[3] == 0
+}
+
+// Add sets z = x + y mod m and returns z.
+func (m *Modulus) Add(z, x, y *Elem) *Elem {
+	var c uint64
+	var t Elem
+	t[0], c = bits.Add64(x[0], y[0], 0)
+	t[1], c = bits.Add64(x[1], y[1], c)
+	t[2], c = bits.Add64(x[2], y[2], c)
+	t[3], c = bits.Add64(x[3], y[3], c)
+	m.reduce(z, &t, c)
+	return z
+}
+
+// Sub sets z = x - y mod m and returns z.
+func (m *Modulus) Sub(z, x, y *Elem) *Elem {
+	var b uint64
+	var t Elem
+	t[0], b = bits.Sub64(x[0], y[0], 0)
+	t[1], b = bits.Sub64(x[1], y[1], b)
+	t[2], b = bits.Sub64(x[2], y[2], b)
+	t[3], b = bits.Sub64(x[3], y[3], b)
+	if b != 0 {
+		var c uint64
+		t[0], c = bits.Add64(t[0], m.m[0], 0)
+		t[1], c = bits.Add64(t[1], m.m[1], c)
+		t[2], c = bits.Add64(t[2], m.m[2], c)
+		t[3], _ = bits.Add64(t[3], m.m[3], c)
+	}
+	*z = t
+	return z
+}
+
+// Neg sets z = -x mod m and returns z.
+func (m *Modulus) Neg(z, x *Elem) *Elem {
+	return m.Sub(z, &Elem{}, x)
+}
+
+// Mul sets z = x·y mod m and returns z, by word-wise (CIOS) Montgomery multiplication.
+func (m *Modulus) Mul(z, x, y *Elem) *Elem {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += x·y[i]
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[j], y[i])
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		var cc uint64
+		t[4], cc = bits.Add64(t[4], c, 0)
+		t[5] = cc
+
+		// t = (t + u·m) / 2^64, with u chosen to clear the low word
+		u := t[0] * m.inv
+		hi, lo := bits.Mul64(u, m.m[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(u, m.m[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[3], cc = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + cc
+	}
+	m.reduce(z, (*Elem)(t[:4]), t[4])
+	return z
+}
+
+// reduce sets z to t + carry·2^256 minus m if that is at least m; the value must be
+// below 2m.
+func (m *Modulus) reduce(z, t *Elem, carry uint64) {
+	var b uint64
+	var r Elem
+	r[0], b = bits.Sub64(t[0], m.m[0], 0)
+	r[1], b = bits.Sub64(t[1], m.m[1], b)
+	r[2], b = bits.Sub64(t[2], m.m[2], b)
+	r[3], b = bits.Sub64(t[3], m.m[3], b)
+	if carry != 0 || b == 0 {
+		*z = r
+	} else {
+		*z = *t
+	}
+}
+
+// Inverse sets z = x^-1 mod m, for a prime m, and returns z, or returns nil if x is 0.
+// It goes through math/big, whose extended GCD is tens of times faster than the
+// exponentiation x^(m-2) in this package: inversions belong outside the inner loops.
+func (m *Modulus) Inverse(z, x *Elem) *Elem {
+	if x.IsZero() {
+		return nil
+	}
+	inv := new(big.Int).ModInverse(m.ToBig(x), m.big)
+	if inv == nil {
+		return nil
+	}
+	return m.SetBig(z, inv)
+}
+
+// Native reports whether this platform multiplies 64-bit words in a single instruction,
+// where the package outruns math/big. Elsewhere (32-bit platforms and WebAssembly)
+// math/bits emulates the multiplication and math/big is the faster choice.
+func Native() bool {
+	switch runtime.GOARCH {
+	case "amd64", "arm64", "ppc64", "ppc64le", "s390x", "riscv64", "loong64", "mips64", "mips64le":
+		return true
+	}
+	return false
+}
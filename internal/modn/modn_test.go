@@ -0,0 +1,123 @@
+package modn
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// testValues returns edge cases and random values in [0, m), and a few outside it.
+func testValues(m *Modulus, rng *rand.Rand) []*big.Int {
+	n := m.Big()
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(n, big.NewInt(1)),
+		new(big.Int).Sub(n, big.NewInt(2)),
+		new(big.Int).Rsh(n, 1),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1)),
+		new(big.Int).Neg(big.NewInt(7)),
+		new(big.Int).Add(n, big.NewInt(5)),
+	}
+	for i := 0; i < 64; i++ {
+		values = append(values, new(big.Int).Rand(rng, n))
+	}
+	return values
+}
+
+func TestModulus_MatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for name, m := range map[string]*Modulus{"secp256k1": Secp256k1N, "ed25519": Ed25519L} {
+		n := m.Big()
+		values := testValues(m, rng)
+		for _, x := range values {
+			var ex Elem
+			m.SetBig(&ex, x)
+			if got, want := m.ToBig(&ex), new(big.Int).Mod(x, n); got.Cmp(want) != 0 {
+				t.Fatalf("%s: round trip of %v = %v, want %v", name, x, got, want)
+			}
+			var want [32]byte
+			new(big.Int).Mod(x, n).FillBytes(want[:])
+			if got := m.Bytes(&ex); got != want {
+				t.Fatalf("%s: Bytes(%v) = %x, want %x", name, x, got, want)
+			}
+
+			var inv Elem
+			if x.Sign() == 0 {
+				if m.Inverse(&inv, &ex) != nil {
+					t.Errorf("%s: Inverse(0) != nil", name)
+				}
+			} else if got, want := m.ToBig(m.Inverse(&inv, &ex)), new(big.Int).ModInverse(x, n); got.Cmp(want) != 0 {
+				t.Errorf("%s: Inverse(%v) = %v, want %v", name, x, got, want)
+			}
+
+			for _, y := range values[:16] {
+				var ey, z Elem
+				m.SetBig(&ey, y)
+				check := func(op string, got *Elem, want *big.Int) {
+					t.Helper()
+					want.Mod(want, n)
+					if m.ToBig(got).Cmp(want) != 0 {
+						t.Errorf("%s: %v %s %v = %v, want %v", name, x, op, y, m.ToBig(got), want)
+					}
+				}
+				check("+", m.Add(&z, &ex, &ey), new(big.Int).Add(x, y))
+				check("-", m.Sub(&z, &ex, &ey), new(big.Int).Sub(x, y))
+				check("*", m.Mul(&z, &ex, &ey), new(big.Int).Mul(x, y))
+			}
+		}
+	}
+}
+
+func TestModulus_SetInt64(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 42, -42, math.MaxInt64, math.MinInt64} {
+		for name, m := range map[string]*Modulus{"secp256k1": Secp256k1N, "ed25519": Ed25519L} {
+			var z Elem
+			want := new(big.Int).Mod(big.NewInt(v), m.Big())
+			if got := m.ToBig(m.SetInt64(&z, v)); got.Cmp(want) != 0 {
+				t.Errorf("%s: SetInt64(%d) = %v, want %v", name, v, got, want)
+			}
+		}
+	}
+
+	// A modulus of a single word reduces the value itself
+	m := NewModulus(big.NewInt(1000003))
+	var z Elem
+	if got := m.ToBig(m.SetInt64(&z, 5000000)); got.Int64() != 5000000%1000003 {
+		t.Errorf("SetInt64 mod 1000003 = %v", got)
+	}
+}
+
+func TestNewModulus_Invalid(t *testing.T) {
+	for _, m := range []*big.Int{big.NewInt(2), big.NewInt(1), big.NewInt(10), new(big.Int).Lsh(big.NewInt(1), 256)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewModulus(%v) did not panic", m)
+				}
+			}()
+			NewModulus(m)
+		}()
+	}
+}
+
+func BenchmarkMul(b *testing.B) {
+	m := Secp256k1N
+	var x, y Elem
+	m.SetInt64(&x, 123456789)
+	m.SetInt64(&y, -987654321)
+	for i := 0; i < b.N; i++ {
+		m.Mul(&x, &x, &y)
+	}
+}
+
+func BenchmarkMul_Big(b *testing.B) {
+	n := Secp256k1N.Big()
+	x, y := big.NewInt(123456789), new(big.Int).Sub(n, big.NewInt(987654321))
+	for i := 0; i < b.N; i++ {
+		x.Mul(x, y)
+		x.Mod(x, n)
+	}
+}
@@ -0,0 +1,169 @@
+// Package pyref runs the reference Python signers in scripts/ (flawed_signer.py and
+// flawed_eddsa_signer.py) as a subprocess, so Go results can be cross-checked against
+// the implementation the fixtures were first generated with. Hash, encoding and
+// endianness conventions are easy to get subtly different between the two, as the EdDSA
+// path has shown.
+//
+// It is used by the cross-validation tests and by "recovery --cross-check-python". The
+// scripts need python3 with the ecdsa and PyNaCl packages (scripts/requirements.txt);
+// when they are missing, the functions return an error wrapping ErrUnavailable.
+package pyref
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnavailable means Python, the scripts or their dependencies were not found.
+var ErrUnavailable = errors.New("python reference implementation not available")
+
+// driver signs each message with its own nonce through the scripts' own code paths
+// (sign_with_affine_nonces with a=1, b=0 and the nonce as start). With hashes instead of
+// messages, the ECDSA signer's hash_message is replaced so each signature uses the given
+// z. It prints the signature list as the scripts write it to their fixture files.
+const driver = `
+import json, sys
+sys.path.insert(0, sys.argv[1])
+req = json.load(sys.stdin)
+out = []
+if req["curve"] == "ecdsa":
+    from flawed_signer import FlawedSigner
+    signer = FlawedSigner(private_key=int(req["key"], 16))
+    for i, k in enumerate(req["nonces"]):
+        message = b""
+        if req.get("hashes"):
+            signer.hash_message = lambda m, z=int(req["hashes"][i], 16): z
+        else:
+            message = bytes.fromhex(req["messages"][i])
+        out += signer.sign_with_affine_nonces([message], 1, 0, start_nonce=int(k, 16))
+else:
+    from flawed_eddsa_signer import FlawedEdDSASigner
+    signer = FlawedEdDSASigner(private_key=bytes.fromhex(req["key"]))
+    for i, k in enumerate(req["nonces"]):
+        out += signer.sign_with_affine_nonces([bytes.fromhex(req["messages"][i])], 1, 0, start_nonce=int(k, 16))
+json.dump(out, sys.stdout)
+`
+
+// Reference is a Python interpreter and the scripts directory it runs the signers from.
+type Reference struct {
+	Python  string
+	Scripts string
+}
+
+// Find locates python3 ($PYTHON overrides it) and the scripts directory: dir if given,
+// otherwise the first scripts/ with flawed_signer.py in the working directory or one of
+// its parents, so tests find it from any package directory.
+func Find(dir string) (*Reference, error) {
+	python := os.Getenv("PYTHON")
+	if python == "" {
+		python = "python3"
+	}
+	path, err := exec.LookPath(python)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		for d := wd; ; d = filepath.Dir(d) {
+			if _, err := os.Stat(filepath.Join(d, "scripts", "flawed_signer.py")); err == nil {
+				dir = filepath.Join(d, "scripts")
+				break
+			}
+			if filepath.Dir(d) == d {
+				return nil, fmt.Errorf("%w: no scripts/flawed_signer.py above %s", ErrUnavailable, wd)
+			}
+		}
+	}
+	for _, script := range []string{"flawed_signer.py", "flawed_eddsa_signer.py"} {
+		if _, err := os.Stat(filepath.Join(dir, script)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+	}
+	return &Reference{Python: path, Scripts: dir}, nil
+}
+
+// request is the driver's input. Numbers are hex.
+type request struct {
+	Curve    string   `json:"curve"`
+	Key      string   `json:"key"`
+	Messages []string `json:"messages,omitempty"`
+	Hashes   []string `json:"hashes,omitempty"`
+	Nonces   []string `json:"nonces"`
+}
+
+// SignECDSA signs each message with private key d and the nonce at the same index using
+// FlawedSigner, and returns the JSON signature list it produces (message, z, r, s).
+// Messages must be UTF-8, as the script writes them as text.
+func (r *Reference) SignECDSA(ctx context.Context, d *big.Int, messages [][]byte, nonces []*big.Int) ([]byte, error) {
+	req := request{Curve: "ecdsa", Key: d.Text(16), Messages: hexAll(messages), Nonces: hexInts(nonces)}
+	return r.run(ctx, req, len(messages), len(nonces))
+}
+
+// SignECDSAHashes is SignECDSA for signatures known only by their message hash z.
+func (r *Reference) SignECDSAHashes(ctx context.Context, d *big.Int, hashes, nonces []*big.Int) ([]byte, error) {
+	req := request{Curve: "ecdsa", Key: d.Text(16), Hashes: hexInts(hashes), Nonces: hexInts(nonces)}
+	return r.run(ctx, req, len(hashes), len(nonces))
+}
+
+// SignEdDSA signs each message with the key of the 32-byte seed and the nonce scalar at
+// the same index using FlawedEdDSASigner, and returns the JSON signature list it
+// produces (message, r, s, public_key).
+func (r *Reference) SignEdDSA(ctx context.Context, seed []byte, messages [][]byte, nonces []*big.Int) ([]byte, error) {
+	req := request{Curve: "eddsa", Key: hex.EncodeToString(seed), Messages: hexAll(messages), Nonces: hexInts(nonces)}
+	return r.run(ctx, req, len(messages), len(nonces))
+}
+
+func (r *Reference) run(ctx context.Context, req request, signatures, nonces int) ([]byte, error) {
+	if signatures != nonces {
+		return nil, fmt.Errorf("%d signatures but %d nonces", signatures, nonces)
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, r.Python, "-c", driver, r.Scripts)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if i := strings.LastIndex(detail, "\n"); i >= 0 {
+			detail = detail[i+1:] // the exception, without the traceback
+		}
+		if strings.Contains(detail, "ModuleNotFoundError") {
+			return nil, fmt.Errorf("%w: %s (pip install -r %s)", ErrUnavailable, detail, filepath.Join(r.Scripts, "requirements.txt"))
+		}
+		return nil, fmt.Errorf("reference signer failed: %v: %s", err, detail)
+	}
+	return out, nil
+}
+
+func hexAll(values [][]byte) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = hex.EncodeToString(v)
+	}
+	return out
+}
+
+func hexInts(values []*big.Int) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v.Text(16)
+	}
+	return out
+}
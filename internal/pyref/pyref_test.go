@@ -0,0 +1,181 @@
+package pyref
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/eddsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+// reference returns the Python reference, skipping the test when it is not installed.
+func reference(t *testing.T) *Reference {
+	t.Helper()
+	ref, err := Find("")
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+// skipUnavailable skips the test if a signer failed for lack of its dependencies.
+func skipUnavailable(t *testing.T, err error) {
+	t.Helper()
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFile writes the reference output where the Go parsers can read it.
+func writeFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// counterNonces returns k, k+1, ... for count signatures.
+func counterNonces(k *big.Int, count int) []*big.Int {
+	nonces := make([]*big.Int, count)
+	for i := range nonces {
+		nonces[i] = new(big.Int).Add(k, big.NewInt(int64(i)))
+	}
+	return nonces
+}
+
+// TestCrossValidate_ECDSA signs with the Python FlawedSigner and checks that the Go
+// hashing, parsing, signing, key recovery and nonce computation all agree with it.
+func TestCrossValidate_ECDSA(t *testing.T) {
+	ref := reference(t)
+	key, _ := flawedsigner.NewECDSAKey(flawedsigner.NewSeededReader(1884))
+	// Nonces just below the group order, where encodings of large values are tested
+	k := new(big.Int).Sub(flawedsigner.Secp256k1Order, big.NewInt(10))
+	messages := flawedsigner.ECDSAMessages(3)
+	nonces := counterNonces(k, len(messages))
+
+	data, err := ref.SignECDSA(context.Background(), key.D, messages, nonces)
+	skipUnavailable(t, err)
+	path := writeFile(t, data)
+
+	withZ, err := (&ecdsaaffine.JSONParser{ZField: "z"}).ParseSignatures(path)
+	if err != nil {
+		t.Fatalf("Go parser rejects the reference output: %v", err)
+	}
+	hashed, err := (&ecdsaaffine.JSONParser{}).ParseSignatures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSignatures, err := key.Sign(messages, flawedsigner.AffineFrom(k, big.NewInt(1), big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range withZ {
+		if sig.Z.Cmp(hashed[i].Z) != 0 {
+			t.Errorf("Signature %d: reference z differs from the Go hash of the message", i)
+		}
+		if sig.R.Cmp(goSignatures[i].R) != 0 || sig.S.Cmp(goSignatures[i].S) != 0 {
+			t.Errorf("Signature %d: reference (r, s) differs from flawedsigner", i)
+		}
+	}
+
+	d, err := ecdsaaffine.RecoverPrivateKey(withZ[0], withZ[1], big.NewInt(1), big.NewInt(1))
+	if err != nil || d.Cmp(key.D) != 0 {
+		t.Fatalf("Recovered a different key from the reference signatures (%v)", err)
+	}
+	result := &ecdsaaffine.RecoveryResult{PrivateKey: d}
+	recovered, err := ecdsaaffine.RecoverNonces(result, withZ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range nonces {
+		if recovered[i].Cmp(nonces[i]) != 0 {
+			t.Errorf("Nonce %d: computed %x, the reference used %x", i, recovered[i], nonces[i])
+		}
+	}
+
+	// Signing by hash reproduces the same signatures
+	byHash, err := ref.SignECDSAHashes(context.Background(), key.D, []*big.Int{withZ[0].Z}, nonces[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := (&ecdsaaffine.JSONParser{ZField: "z"}).ParseSignatures(writeFile(t, byHash))
+	if err != nil || again[0].S.Cmp(withZ[0].S) != 0 {
+		t.Errorf("Signing by hash gave a different signature (%v)", err)
+	}
+}
+
+// TestCrossValidate_EdDSA does the same with the Python FlawedEdDSASigner, whose R and
+// message encodings differ from the ECDSA script's.
+func TestCrossValidate_EdDSA(t *testing.T) {
+	ref := reference(t)
+	key, _ := flawedsigner.NewEdDSAKey(flawedsigner.NewSeededReader(1884))
+	k := big.NewInt(0x1234567890)
+	messages := flawedsigner.EdDSAMessages(3)
+	nonces := counterNonces(k, len(messages))
+
+	data, err := ref.SignEdDSA(context.Background(), key.Seed, messages, nonces)
+	skipUnavailable(t, err)
+	signatures, err := (&eddsaaffine.JSONParser{}).ParseSignatures(writeFile(t, data))
+	if err != nil {
+		t.Fatalf("Go parser rejects the reference output: %v", err)
+	}
+	goSignatures, err := key.Sign(messages, flawedsigner.AffineFrom(k, big.NewInt(1), big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range signatures {
+		if sig.R.Cmp(goSignatures[i].R) != 0 || sig.S.Cmp(goSignatures[i].S) != 0 {
+			t.Errorf("Signature %d: reference (R, S) differs from flawedsigner", i)
+		}
+		if string(sig.Message) != string(messages[i]) || string(sig.PublicKey) != string(key.Public) {
+			t.Errorf("Signature %d: message or public key decoded differently", i)
+		}
+	}
+
+	a, err := eddsaaffine.RecoverPrivateKey(signatures[0], signatures[1], big.NewInt(1), big.NewInt(1))
+	if err != nil || a.Cmp(key.Scalar) != 0 {
+		t.Fatalf("Recovered a different scalar from the reference signatures (%v)", err)
+	}
+	recovered, err := eddsaaffine.RecoverNonces(&eddsaaffine.RecoveryResult{PrivateKey: a}, signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range nonces {
+		if recovered[i].Cmp(nonces[i]) != 0 {
+			t.Errorf("Nonce %d: computed %x, the reference used %x", i, recovered[i], nonces[i])
+		}
+	}
+}
+
+func TestReference_Unavailable(t *testing.T) {
+	ref := reference(t)
+	if _, err := Find(t.TempDir()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable for a directory without the scripts, got %v", err)
+	}
+
+	// A missing Python package is reported as unavailable, not as a failure
+	dir := t.TempDir()
+	for _, script := range []string{"flawed_signer.py", "flawed_eddsa_signer.py"} {
+		os.WriteFile(filepath.Join(dir, script), []byte("import no_such_module_for_pyref\n"), 0o600)
+	}
+	stub := &Reference{Python: ref.Python, Scripts: dir}
+	if _, err := stub.SignECDSA(context.Background(), big.NewInt(1), [][]byte{{'a'}}, []*big.Int{big.NewInt(2)}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable for a missing module, got %v", err)
+	}
+	if _, err := stub.SignECDSA(context.Background(), big.NewInt(1), [][]byte{{'a'}}, nil); err == nil {
+		t.Error("Expected an error for mismatched nonces")
+	}
+}
@@ -0,0 +1,211 @@
+package source
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	gcsReadScope     = "https://www.googleapis.com/auth/devstorage.read_only"
+	googleTokenURL   = "https://oauth2.googleapis.com/token"
+	gceMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// metadataClient asks the Compute Engine metadata server for a token. Off Compute
+// Engine the server does not exist, so it gives up quickly.
+var metadataClient = &http.Client{Timeout: time.Second}
+
+// googleCredentials is a credentials file as written by gcloud or the Cloud console.
+type googleCredentials struct {
+	Type string `json:"type"`
+
+	// service_account
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	// authorized_user
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// openGCS streams gs://bucket/object through the Cloud Storage JSON API. The access token
+// comes from GOOGLE_OAUTH_ACCESS_TOKEN, or else from Application Default Credentials: the
+// file named by GOOGLE_APPLICATION_CREDENTIALS or gcloud's default credentials file
+// (a service account key or a user's refresh token), then the Compute Engine metadata
+// server. Without a token the request is sent anonymously. STORAGE_EMULATOR_HOST
+// points at an emulator.
+func openGCS(path string) (io.ReadCloser, error) {
+	bucket, object, err := splitObject(path)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "https://storage.googleapis.com"
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		endpoint = strings.TrimSuffix(host, "/")
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+	req, err := http.NewRequest(http.MethodGet,
+		endpoint+"/storage/v1/b/"+url.PathEscape(bucket)+"/o/"+url.PathEscape(object)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := googleAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a Google Cloud access token: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return get(req)
+}
+
+// googleAccessToken returns an access token for reading Cloud Storage, or "" if no
+// credentials are configured.
+func googleAccessToken() (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "gcloud", "application_default_credentials.json")
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var creds googleCredentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return creds.token()
+	}
+	if os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") != "" {
+		return "", err
+	}
+	return metadataToken(), nil
+}
+
+// token exchanges the credentials for an access token.
+func (c *googleCredentials) token() (string, error) {
+	form := url.Values{}
+	tokenURL := googleTokenURL
+	switch c.Type {
+	case "service_account":
+		assertion, err := c.assertion(time.Now())
+		if err != nil {
+			return "", err
+		}
+		if c.TokenURI != "" {
+			tokenURL = c.TokenURI
+		}
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+		form.Set("assertion", assertion)
+	case "authorized_user":
+		form.Set("grant_type", "refresh_token")
+		form.Set("client_id", c.ClientID)
+		form.Set("client_secret", c.ClientSecret)
+		form.Set("refresh_token", c.RefreshToken)
+	default:
+		return "", fmt.Errorf("unsupported credentials type %q", c.Type)
+	}
+
+	resp, err := httpClient.PostForm(tokenURL, form)
+	if err != nil {
+		return "", err
+	}
+	return decodeToken(resp)
+}
+
+// assertion is the signed JWT a service account trades for an access token.
+func (c *googleCredentials) assertion(now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account private key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("service account private key is not RSA")
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	aud := c.TokenURI
+	if aud == "" {
+		aud = googleTokenURL
+	}
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   c.ClientEmail,
+		"scope": gcsReadScope,
+		"aud":   aud,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// metadataToken returns the default service account's token from the Compute Engine
+// metadata server, or "" if there is none.
+func metadataToken() string {
+	req, err := http.NewRequest(http.MethodGet, gceMetadataToken, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	token, err := decodeToken(resp)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// decodeToken reads an OAuth 2.0 token response.
+func decodeToken(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request: %s: %s", resp.Status, errorDetail(resp))
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("token response has no access_token")
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,100 @@
+// Package source opens signature files by path or URI, so large dumps kept in object
+// storage are streamed straight into the parsers instead of being downloaded first.
+//
+// It is shared by pkg/ecdsaaffine, pkg/eddsaaffine and cmd/recovery. A source is a
+// local path, an http:// or https:// URL, s3://bucket/key or gs://bucket/object.
+// Credentials come from the standard environment variables and configuration files of
+// each service (see openS3 and openGCS); without any, the object is read anonymously,
+// which works for public objects. The module has no dependency on the cloud SDKs: the
+// requests are plain HTTP, signed with the stdlib.
+package source
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// httpClient sends every remote request. It has no overall timeout, since reading a large
+// object may take long; a stalled connection fails on the transport's own timeouts.
+var httpClient = &http.Client{Transport: http.DefaultTransport}
+
+// IsRemote reports whether uri names a remote object rather than a local path.
+func IsRemote(uri string) bool {
+	scheme, _, ok := strings.Cut(uri, "://")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "s3", "gs":
+		return true
+	}
+	return false
+}
+
+// Open opens a local path or remote object for reading. The caller must close it.
+func Open(uri string) (io.ReadCloser, error) {
+	if !IsRemote(uri) {
+		return os.Open(uri)
+	}
+	scheme, rest, _ := strings.Cut(uri, "://")
+	switch strings.ToLower(scheme) {
+	case "s3":
+		return openS3(rest)
+	case "gs":
+		return openGCS(rest)
+	default:
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		return get(req)
+	}
+}
+
+// splitObject splits "bucket/key" into its parts.
+func splitObject(path string) (bucket, key string, err error) {
+	bucket, key, _ = strings.Cut(path, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("object URI must name a bucket and a key, got %q", path)
+	}
+	return bucket, key, nil
+}
+
+// get sends req and returns the response body, or an error unless the status is 200.
+func get(req *http.Request) (io.ReadCloser, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		err := fmt.Errorf("GET %s: %s", redactURL(req), resp.Status)
+		if detail := errorDetail(resp); detail != "" {
+			err = fmt.Errorf("%w: %s", err, detail)
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// errorDetail returns the start of an error response's body on one line, such as an S3
+// or Cloud Storage error document, or "" for an HTML error page.
+func errorDetail(resp *http.Response) string {
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return ""
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return strings.Join(strings.Fields(string(body)), " ")
+}
+
+// redactURL returns the request URL without its query, which may carry a presigned
+// URL's credentials.
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
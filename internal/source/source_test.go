@@ -0,0 +1,188 @@
+package source
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBody = `[{"z":"0x1","r":"0x2","s":"0x3"}]`
+
+// readAll opens uri and returns its content.
+func readAll(t *testing.T, uri string) string {
+	t.Helper()
+	r, err := Open(uri)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", uri, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return string(data)
+}
+
+func TestOpen_LocalAndHTTP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, []byte(testBody), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if IsRemote(path) || !IsRemote("HTTPS://example.com/x") || IsRemote("ftp://example.com/x") {
+		t.Error("IsRemote misclassified a source")
+	}
+	if got := readAll(t, path); got != testBody {
+		t.Errorf("Local file: got %q", got)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/signatures.json" {
+			http.Error(w, "no such object", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, testBody)
+	}))
+	defer server.Close()
+
+	if got := readAll(t, server.URL+"/signatures.json"); got != testBody {
+		t.Errorf("HTTP: got %q", got)
+	}
+	_, err := Open(server.URL + "/missing.json?X-Amz-Signature=secret")
+	if err == nil || !strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected a 404 error without the query, got %v", err)
+	}
+}
+
+func TestOpen_S3(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		io.WriteString(w, testBody)
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	if body := readAll(t, "s3://dumps/2024/sigs (1).json"); body != testBody {
+		t.Errorf("S3: got %q", body)
+	}
+	if got.URL.EscapedPath() != "/dumps/2024/sigs%20%281%29.json" {
+		t.Errorf("Unexpected path %s", got.URL.EscapedPath())
+	}
+	auth := got.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+		!strings.Contains(auth, "/eu-west-1/s3/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token") {
+		t.Errorf("Unexpected Authorization %q", auth)
+	}
+	if got.Header.Get("X-Amz-Security-Token") != "session" {
+		t.Error("Session token not sent")
+	}
+
+	// Without credentials the request is anonymous
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "none"))
+	readAll(t, "s3://dumps/sigs.json")
+	if got.Header.Get("Authorization") != "" {
+		t.Error("Expected an unsigned request")
+	}
+
+	if _, err := Open("s3://dumps"); err == nil {
+		t.Error("Expected an error for a URI without a key")
+	}
+}
+
+func TestOpen_S3SharedCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	file := "[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = a\n\n" +
+		"[audit]\naws_access_key_id = AKIDAUDIT\naws_secret_access_key = b\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "audit")
+
+	creds, err := loadAWSCredentials()
+	if err != nil || creds == nil || creds.accessKey != "AKIDAUDIT" || creds.secretKey != "b" {
+		t.Errorf("Expected the audit profile, got %+v, %v", creds, err)
+	}
+}
+
+func TestOpen_GCS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	var auth string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			http.Error(w, "bad assertion", http.StatusBadRequest)
+			return
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"access_token":"from-service-account","expires_in":3600}`)
+	})
+	mux.HandleFunc("/storage/v1/b/dumps/o/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/storage/v1/b/dumps/o/2024%2Fsigs.json" || r.URL.Query().Get("alt") != "media" {
+			http.Error(w, "unexpected object "+r.URL.String(), http.StatusNotFound)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		io.WriteString(w, testBody)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "from-env")
+	if body := readAll(t, "gs://dumps/2024/sigs.json"); body != testBody {
+		t.Errorf("GCS: got %q", body)
+	}
+	if auth != "Bearer from-env" {
+		t.Errorf("Expected the token from the environment, got %q", auth)
+	}
+
+	creds, _ := json.Marshal(googleCredentials{
+		Type:        "service_account",
+		ClientEmail: "reader@example.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    server.URL + "/token",
+	})
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, creds, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+	readAll(t, "gs://dumps/2024/sigs.json")
+	if auth != "Bearer from-service-account" {
+		t.Errorf("Expected the service account's token, got %q", auth)
+	}
+}
@@ -0,0 +1,206 @@
+package auditlog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
+)
+
+// Events recorded in the log.
+const (
+	EventRecovered = "recovered" // key verified against the public key
+	EventCandidate = "candidate" // key recovered without a public key to verify it
+)
+
+// maxEntrySize bounds a single log line (entries embed the search configuration).
+const maxEntrySize = 1 << 20
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Seq  int64     `json:"seq"`  // 1-based position in the log, set by Append
+	Time time.Time `json:"time"` // UTC, set by Append if zero
+
+	Event string `json:"event"` // EventRecovered or EventCandidate
+	Curve string `json:"curve"` // e.g. "secp256k1" or "ed25519"
+
+	PrivateKey    string `json:"private_key,omitempty"` // hex, omitted when key material is redacted
+	PublicKey     string `json:"public_key,omitempty"`
+	A             string `json:"a"`
+	B             string `json:"b"`
+	SignaturePair [2]int `json:"signature_pair"`
+	Pattern       string `json:"pattern,omitempty"`
+
+	Dataset       string `json:"dataset"`        // signature file path
+	DatasetSHA256 string `json:"dataset_sha256"` // see FingerprintFile
+	ToolVersion   string `json:"tool_version"`
+
+	// Config is the search configuration, as set with SetConfig
+	Config json.RawMessage `json:"config,omitempty"`
+
+	PrevHash string `json:"prev_hash"`      // Hash of the previous entry ("" for the first)
+	Hash     string `json:"hash,omitempty"` // SHA-256 of this entry with Hash empty
+}
+
+// SetConfig stores v, encoded as JSON, as the entry's configuration.
+func (e *Entry) SetConfig(v any) error {
+	config, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.Config = config
+	return nil
+}
+
+// hash computes the entry's hash: SHA-256 of its JSON encoding with Hash empty.
+func (e Entry) hash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an audit log opened for appending. It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+	seq  int64
+	head string
+}
+
+// Open opens (or creates) the log at path for appending. It verifies the existing
+// entries first and refuses to extend a log whose chain is broken.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	seq, head, err := verify(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &Log{file: file, seq: seq, head: head}, nil
+}
+
+// Append completes e (sequence number, time, chain hashes) and writes it to the log.
+// The entry is synced to disk before Append returns.
+func (l *Log) Append(e *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.PrevHash = l.head
+	hash, err := e.hash()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.seq = e.Seq
+	l.head = e.Hash
+	return nil
+}
+
+// Head returns the hash of the last entry ("" for an empty log).
+func (l *Log) Head() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.head
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	return l.file.Close()
+}
+
+// Verify checks the hash chain of the log at path and returns the number of entries and
+// the hash of the last one.
+func Verify(path string) (entries int64, head string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	entries, head, err = verify(file)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, head, err
+}
+
+func verify(r io.Reader) (int64, string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+
+	var seq int64
+	head := ""
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return 0, "", fmt.Errorf("entry %d: %w", seq+1, err)
+		}
+		if e.Seq != seq+1 {
+			return 0, "", fmt.Errorf("entry %d: sequence number %d (entries removed or reordered)", seq+1, e.Seq)
+		}
+		if e.PrevHash != head {
+			return 0, "", fmt.Errorf("entry %d: previous hash does not match entry %d", e.Seq, seq)
+		}
+		hash, err := e.hash()
+		if err != nil {
+			return 0, "", err
+		}
+		if hash != e.Hash {
+			return 0, "", fmt.Errorf("entry %d: hash mismatch (entry modified)", e.Seq)
+		}
+		seq = e.Seq
+		head = e.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return 0, "", fmt.Errorf("entry %d: longer than %d bytes", seq+1, maxEntrySize)
+		}
+		return 0, "", err
+	}
+	return seq, head, nil
+}
+
+// FingerprintFile returns the hex SHA-256 of the file at path (or a URL source.Open
+// accepts), identifying the exact dataset a key was recovered from.
+func FingerprintFile(path string) (string, error) {
+	file, err := source.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,83 @@
+package auditlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func appendEntries(t *testing.T, path string, keys ...string) string {
+	t.Helper()
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer log.Close()
+	for _, key := range keys {
+		e := &Entry{Event: EventRecovered, Curve: "secp256k1", PrivateKey: key, A: "1", B: "12345", Dataset: "sigs.json"}
+		if err := e.SetConfig(map[string]any{"mode": "smart-brute", "workers": 4}); err != nil {
+			t.Fatalf("SetConfig failed: %v", err)
+		}
+		if err := log.Append(e); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	return log.Head()
+}
+
+func TestLog_AppendAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	appendEntries(t, path, "deadbeef", "cafe")
+	head := appendEntries(t, path, "f00d") // reopening continues the chain
+
+	entries, verifiedHead, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if entries != 3 || verifiedHead != head {
+		t.Errorf("Expected 3 entries with head %s, got %d with head %s", head, entries, verifiedHead)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	for name, tamper := range map[string]func(lines []string) []string{
+		"modified": func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"cafe"`, `"cafd"`, 1)
+			return lines
+		},
+		"removed": func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		},
+		"reordered": func(lines []string) []string {
+			lines[0], lines[1] = lines[1], lines[0]
+			return lines
+		},
+	} {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		appendEntries(t, path, "deadbeef", "cafe", "f00d")
+
+		data, _ := os.ReadFile(path)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		os.WriteFile(path, []byte(strings.Join(tamper(lines), "\n")+"\n"), 0o600)
+
+		if _, _, err := Verify(path); err == nil {
+			t.Errorf("%s: expected Verify to fail", name)
+		}
+		if _, err := Open(path); err == nil {
+			t.Errorf("%s: expected Open to refuse a broken log", name)
+		}
+	}
+}
+
+func TestFingerprintFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sigs.json")
+	os.WriteFile(path, []byte("abc"), 0o600)
+	got, err := FingerprintFile(path)
+	if err != nil {
+		t.Fatalf("FingerprintFile failed: %v", err)
+	}
+	if want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
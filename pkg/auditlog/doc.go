@@ -0,0 +1,14 @@
+// Package auditlog records recovered keys and unverified candidates in an append-only,
+// tamper-evident log, for chain of custody in professional engagements.
+//
+// The log is JSON Lines. Every entry carries a sequence number, a UTC timestamp, the
+// hash of the previous entry and its own SHA-256 hash, so editing, reordering or
+// removing an entry breaks the chain and is reported by Verify:
+//
+//	log, err := auditlog.Open("audit.jsonl")
+//	...
+//	err = log.Append(&auditlog.Entry{Event: auditlog.EventRecovered, Curve: "secp256k1", ...})
+//
+// Truncating the tail of the log leaves a valid chain, so record the head hash returned
+// by Verify (or Log.Head) in the engagement report.
+package auditlog
@@ -0,0 +1,91 @@
+package dsaaffine
+
+import (
+	"context"
+	"crypto"
+	_ "crypto/sha512" // registers crypto.SHA384 and crypto.SHA512 for Digest
+	"fmt"
+	"math/big"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// Client provides a high-level API for DSA key recovery, on ecdsaaffine's client run in
+// the key's subgroup (see PublicKey.Curve).
+type Client struct {
+	key  *PublicKey
+	hash crypto.Hash
+	opts []ecdsaaffine.Option
+}
+
+// NewClient creates a client for the signatures of key, hashing messages with SHA-1 (as
+// ssh-dss and DNSSEC algorithms 3 and 6 do). opts configure the underlying ecdsaaffine
+// client, e.g. ecdsaaffine.WithParser, WithPairs, WithLogger, or WithStrategy with a
+// CurveStrategy of other ranges.
+func NewClient(key *PublicKey, opts ...ecdsaaffine.Option) *Client {
+	return &Client{key: key, hash: crypto.SHA1, opts: opts}
+}
+
+// WithHash sets the hash messages are signed with (e.g. crypto.SHA256 for FIPS 186-3
+// keys); signatures that carry z are used as they are.
+func (c *Client) WithHash(hash crypto.Hash) *Client {
+	c.hash = hash
+	return c
+}
+
+// engine returns the ecdsaaffine client that runs the recovery.
+func (c *Client) engine() (*ecdsaaffine.Client, error) {
+	if err := c.key.Validate(); err != nil {
+		return nil, err
+	}
+	if !c.hash.Available() {
+		return nil, fmt.Errorf("hash %v is not available", c.hash)
+	}
+	params, hash := c.key.Parameters, c.hash
+	hasher := func(message []byte) *big.Int {
+		z, _ := params.Digest(hash, message)
+		return z
+	}
+	opts := append([]ecdsaaffine.Option{ecdsaaffine.WithHasher(hasher), ecdsaaffine.WithCurve(c.key.Curve())}, c.opts...)
+	return ecdsaaffine.NewClient(opts...), nil
+}
+
+// RecoverKey attempts to recover the private key from signatures in a file, given as a
+// path or URL (JSON or CSV, see ecdsaaffine.JSONParser). The key is verified against y
+// when the client's key has one.
+func (c *Client) RecoverKey(ctx context.Context, source string) (*RecoveryResult, error) {
+	engine, err := c.engine()
+	if err != nil {
+		return nil, err
+	}
+	return engine.RecoverKey(ctx, source, "")
+}
+
+// RecoverKeyFromSignatures is RecoverKey for signatures already parsed.
+func (c *Client) RecoverKeyFromSignatures(ctx context.Context, signatures []*Signature) (*RecoveryResult, error) {
+	engine, err := c.engine()
+	if err != nil {
+		return nil, err
+	}
+	return engine.RecoverKeyFromSignatures(ctx, signatures, "")
+}
+
+// RecoverKeyWithKnownRelationship recovers the private key when the nonces are known to
+// satisfy k2 = a*k1 + b, trying every signature pair.
+func (c *Client) RecoverKeyWithKnownRelationship(ctx context.Context, source string, a, b int64) (*RecoveryResult, error) {
+	engine, err := c.engine()
+	if err != nil {
+		return nil, err
+	}
+	return engine.RecoverKeyWithKnownRelationship(ctx, source, a, b, "")
+}
+
+// RecoverKeyWithKnownNonce recovers the private key from signature index alone, given
+// its nonce k.
+func (c *Client) RecoverKeyWithKnownNonce(ctx context.Context, source string, index int, k *big.Int) (*RecoveryResult, error) {
+	engine, err := c.engine()
+	if err != nil {
+		return nil, err
+	}
+	return engine.RecoverKeyWithKnownNonce(ctx, source, index, k, "")
+}
@@ -0,0 +1,116 @@
+package dsaaffine
+
+import (
+	"context"
+	"crypto"
+	"crypto/dsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// testKey is the private key of testdata/dsa_public.pem.
+var testKey = big.NewInt(0x0d5a5eed)
+
+// writeTestSignatures signs count messages with nonces k0, k0+step, ... and writes them
+// as a JSON file of message, r and s, returning its path and the signatures.
+func writeTestSignatures(t *testing.T, key *PublicKey, hash crypto.Hash, k0, step int64, count int) (string, []*Signature) {
+	t.Helper()
+	var records []map[string]string
+	var signatures []*Signature
+	for i := 0; i < count; i++ {
+		message := fmt.Sprintf("legacy message %d", i)
+		z, err := key.Digest(hash, []byte(message))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := key.Sign(testKey, big.NewInt(k0+step*int64(i)), z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signatures = append(signatures, sig)
+		records = append(records, map[string]string{"message": message, "r": "0x" + hex.EncodeToString(sig.R.Bytes()), "s": "0x" + hex.EncodeToString(sig.S.Bytes())})
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, signatures
+}
+
+func TestSign(t *testing.T) {
+	// Signatures made by Sign verify with crypto/dsa
+	key := loadTestKey(t)
+	z, err := key.Digest(crypto.SHA256, []byte("fips 186"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.BitLen() > key.Q.BitLen() {
+		t.Errorf("Digest is %d bits, longer than q", z.BitLen())
+	}
+	sig, err := key.Sign(testKey, big.NewInt(0x5eed), z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := &dsa.PublicKey{Parameters: dsa.Parameters{P: key.P, Q: key.Q, G: key.G}, Y: key.Y}
+	if !dsa.Verify(publicKey, z.Bytes(), sig.R, sig.S) {
+		t.Error("crypto/dsa rejects the signature")
+	}
+}
+
+func TestClient_RecoverKey(t *testing.T) {
+	key := loadTestKey(t)
+	quiet := ecdsaaffine.WithLogger(log.New(io.Discard, "", 0))
+	ctx := context.Background()
+	path, _ := writeTestSignatures(t, key, crypto.SHA1, 0x1234567, 4, 4)
+
+	result, err := NewClient(key, quiet).RecoverKey(ctx, path)
+	if err != nil || result.PrivateKey.Cmp(testKey) != 0 || !result.Verified || result.Pattern != "counter_+4" {
+		t.Fatalf("Expected the key verified, got %+v, %v", result, err)
+	}
+
+	// Messages hashed with another hash than the signer's give nothing
+	strategy := ecdsaaffine.NewCurveStrategy(nil)
+	strategy.ARange, strategy.BRange = [2]int{1, 1}, [2]int{-5, 5}
+	if _, err := NewClient(key, quiet, ecdsaaffine.WithStrategy(strategy)).WithHash(crypto.SHA256).RecoverKey(ctx, path); err == nil {
+		t.Error("Expected no key with the wrong hash")
+	}
+
+	// Parameters alone find the key from the chain, unverified
+	params := &PublicKey{Parameters: key.Parameters}
+	result, err = NewClient(params, quiet).RecoverKey(ctx, path)
+	if err != nil || result.PrivateKey.Cmp(testKey) != 0 || result.Verified {
+		t.Errorf("Expected the key unverified, got %+v, %v", result, err)
+	}
+}
+
+func TestClient_KnownRelationshipAndNonce(t *testing.T) {
+	key := loadTestKey(t)
+	path, _ := writeTestSignatures(t, key, crypto.SHA256, 0xabcdef, 0, 2)
+	client := NewClient(key).WithHash(crypto.SHA256)
+	ctx := context.Background()
+
+	result, err := client.RecoverKeyWithKnownRelationship(ctx, path, 1, 0)
+	if err != nil || result.PrivateKey.Cmp(testKey) != 0 || !result.Verified {
+		t.Errorf("Known relationship: %+v, %v", result, err)
+	}
+	result, err = client.RecoverKeyWithKnownNonce(ctx, path, 1, big.NewInt(0xabcdef))
+	if err != nil || result.PrivateKey.Cmp(testKey) != 0 || !result.Verified {
+		t.Errorf("Known nonce: %+v, %v", result, err)
+	}
+	if _, err := client.RecoverKeyWithKnownNonce(ctx, path, 1, big.NewInt(0xabcdee)); err == nil {
+		t.Error("Expected an error for the wrong nonce")
+	}
+}
@@ -0,0 +1,22 @@
+// Package dsaaffine recovers classic finite-field DSA private keys when the nonces used
+// in signing have an affine relationship (k2 = a*k1 + b), including reuse (a = 1, b = 0).
+//
+// DSA signs with the same equation as ECDSA, s = k⁻¹(z + r·x) mod q, with
+// r = (g^k mod p) mod q, so the search is ecdsaaffine's CurveStrategy run in the
+// subgroup of order q. A recovered key is verified against the public key y = g^x mod p.
+// Legacy DSA keys are still found in audits: old SSH host and user keys, and DNSSEC zones
+// signed with algorithms 3 and 6.
+//
+// WARNING: This package is for security research and testing purposes only.
+// It should only be used to analyze your own signatures or with explicit permission.
+//
+// Basic Usage:
+//
+//	key, err := dsaaffine.LoadPublicKey("id_dsa.pub") // PEM, OpenSSH or DNSKEY
+//	client := dsaaffine.NewClient(key).WithHash(crypto.SHA1)
+//	result, err := client.RecoverKey(ctx, "path/to/signatures.json")
+//
+// Signature files are ecdsaaffine's JSON or CSV formats: r and s with either the digest
+// z or the message, which is hashed with the client's hash and truncated to the bit
+// length of q (FIPS 186-4, section 4.6).
+package dsaaffine
@@ -0,0 +1,69 @@
+package dsaaffine
+
+import (
+	"crypto"
+	_ "crypto/sha1"   // registers crypto.SHA1 for Digest
+	_ "crypto/sha256" // registers crypto.SHA224 and crypto.SHA256 for Digest
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// Signature and RecoveryResult are ecdsaaffine's: DSA signatures are (r, s) over a
+// digest z, as ECDSA signatures are.
+type (
+	Signature      = ecdsaaffine.Signature
+	RecoveryResult = ecdsaaffine.RecoveryResult
+)
+
+// Digest hashes message with hash and keeps the leftmost bits, as many as q has (FIPS
+// 186-4, section 4.6): the z a DSA signer with these parameters signs.
+func (p *Parameters) Digest(hash crypto.Hash, message []byte) (*big.Int, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash %v is not available", hash)
+	}
+	h := hash.New()
+	h.Write(message)
+	z := new(big.Int).SetBytes(h.Sum(nil))
+	if excess := 8*hash.Size() - p.Q.BitLen(); excess > 0 {
+		z.Rsh(z, uint(excess))
+	}
+	return z, nil
+}
+
+// Sign signs z with key x and nonce k: r = (g^k mod p) mod q, s = k⁻¹(z + r·x) mod q.
+// Use it to make datasets with chosen nonces.
+func (p *Parameters) Sign(x, k, z *big.Int) (*Signature, error) {
+	k = new(big.Int).Mod(k, p.Q)
+	kInv := new(big.Int).ModInverse(k, p.Q)
+	r := new(big.Int).Exp(p.G, k, p.P)
+	r.Mod(r, p.Q)
+	if kInv == nil || r.Sign() == 0 {
+		return nil, errors.New("nonce gives r = 0 or has no inverse; pick another")
+	}
+	s := new(big.Int).Mul(r, x)
+	s.Add(s, z).Mul(s, kInv).Mod(s, p.Q)
+	if s.Sign() == 0 {
+		return nil, errors.New("nonce gives s = 0; pick another")
+	}
+	return &Signature{Z: new(big.Int).Set(z), R: r, S: s}, nil
+}
+
+// Verify reports whether x is the private key of y = g^x mod p. Keys without y are never
+// verified.
+func (k *PublicKey) Verify(x *big.Int) bool {
+	return k.Y != nil && x.Sign() > 0 && new(big.Int).Exp(k.G, x, k.P).Cmp(k.Y) == 0
+}
+
+// Curve returns the key's subgroup as an ecdsaaffine.Curve, for its CurveStrategy and
+// client: the order q, checked against y when the key has one. Without y a candidate is
+// accepted, unverified, when the next signature continues the relationship with it.
+func (k *PublicKey) Curve() *ecdsaaffine.Curve {
+	curve := &ecdsaaffine.Curve{Name: fmt.Sprintf("DSA (%d, %d)", k.P.BitLen(), k.Q.BitLen()), N: k.Q}
+	if k.Y != nil {
+		curve.Verify = func(privateKey *big.Int, publicKey []byte) bool { return k.Verify(privateKey) }
+	}
+	return curve
+}
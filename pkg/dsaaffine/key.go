@@ -0,0 +1,209 @@
+package dsaaffine
+
+import (
+	"bytes"
+	"crypto/dsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// Parameters are the domain parameters of a DSA key: the prime p, the prime q dividing
+// p - 1, and the generator g of the subgroup of order q.
+type Parameters struct {
+	P, Q, G *big.Int
+}
+
+// Validate checks that q divides p - 1 and that g has order q mod p. p and q are not
+// tested for primality; audit data comes from keys that were in use.
+func (p *Parameters) Validate() error {
+	if p.P == nil || p.Q == nil || p.G == nil {
+		return errors.New("DSA parameters need p, q and g")
+	}
+	one := big.NewInt(1)
+	if p.Q.Cmp(one) <= 0 || new(big.Int).Mod(new(big.Int).Sub(p.P, one), p.Q).Sign() != 0 {
+		return errors.New("DSA parameter q does not divide p - 1")
+	}
+	if p.G.Cmp(one) <= 0 || p.G.Cmp(p.P) >= 0 || new(big.Int).Exp(p.G, p.Q, p.P).Cmp(one) != 0 {
+		return errors.New("DSA generator g does not have order q")
+	}
+	return nil
+}
+
+// PublicKey is a DSA public key y = g^x mod p. Y is nil for bare parameters, whose
+// recovered keys cannot be verified.
+type PublicKey struct {
+	Parameters
+	Y *big.Int
+}
+
+// ParsePublicKey parses a DSA public key or its parameters from any of:
+//   - PEM "PUBLIC KEY" (PKIX, as written by openssl dsa -pubout) or a certificate
+//   - PEM "DSA PARAMETERS" (openssl dsaparam), which has no y
+//   - an OpenSSH "ssh-dss" public key line (authorized_keys, known_hosts, .pub files)
+//   - a DNSKEY record of algorithm 3 or 6 (RFC 2536), in zone file form
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	var key *PublicKey
+	var err error
+	text := string(data)
+	switch {
+	case bytes.Contains(data, []byte("-----BEGIN")):
+		key, err = parsePEMPublicKey(data)
+	case strings.Contains(text, sshKeyType):
+		key, err = parseSSHPublicKey(text)
+	case strings.Contains(text, "DNSKEY"):
+		key, err = parseDNSKEY(text)
+	default:
+		return nil, errors.New("unrecognized DSA public key format (want PEM, ssh-dss or DNSKEY)")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LoadPublicKey reads a public key file in any format ParsePublicKey accepts.
+func LoadPublicKey(path string) (*PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// parsePEMPublicKey decodes the first PEM block, a PKIX public key, a certificate or
+// DSA parameters.
+func parsePEMPublicKey(data []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	var parsed interface{}
+	switch block.Type {
+	case "DSA PARAMETERS":
+		var params struct{ P, Q, G *big.Int }
+		if _, err := asn1.Unmarshal(block.Bytes, &params); err != nil {
+			return nil, fmt.Errorf("invalid DSA parameters: %w", err)
+		}
+		return &PublicKey{Parameters: Parameters{P: params.P, Q: params.Q, G: params.G}}, nil
+	case "PUBLIC KEY":
+		var err error
+		if parsed, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("invalid PKIX public key: %w", err)
+		}
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		parsed = cert.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	key, ok := parsed.(*dsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("PEM key is %T, not a DSA public key", parsed)
+	}
+	return &PublicKey{Parameters: Parameters{P: key.P, Q: key.Q, G: key.G}, Y: key.Y}, nil
+}
+
+// sshKeyType is the OpenSSH key type of DSA keys.
+const sshKeyType = "ssh-dss"
+
+// parseSSHPublicKey decodes the base64 wire blob after "ssh-dss": the key type, then p,
+// q, g and y as mpints (RFC 4253 section 6.6).
+func parseSSHPublicKey(text string) (*PublicKey, error) {
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] != sshKeyType {
+				continue
+			}
+			blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid OpenSSH public key: %w", err)
+			}
+			keyType, rest, ok := readSSHString(blob)
+			if !ok || string(keyType) != sshKeyType {
+				return nil, errors.New("invalid OpenSSH public key: key type mismatch")
+			}
+			values := make([]*big.Int, 4)
+			for j := range values {
+				var v []byte
+				if v, rest, ok = readSSHString(rest); !ok {
+					return nil, errors.New("invalid OpenSSH public key: malformed key data")
+				}
+				values[j] = new(big.Int).SetBytes(v)
+			}
+			if len(rest) != 0 {
+				return nil, errors.New("invalid OpenSSH public key: malformed key data")
+			}
+			return &PublicKey{Parameters: Parameters{P: values[0], Q: values[1], G: values[2]}, Y: values[3]}, nil
+		}
+	}
+	return nil, errors.New("no ssh-dss public key found")
+}
+
+// readSSHString reads one length-prefixed string from an SSH wire blob.
+func readSSHString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
+
+// parseDNSKEY decodes the key of a DNSKEY record ("... DNSKEY flags 3 alg base64..."):
+// T, then q (20 bytes), p, g and y (64 + 8T bytes each), per RFC 2536.
+func parseDNSKEY(text string) (*PublicKey, error) {
+	fields := strings.Fields(text)
+	for i, field := range fields {
+		if field != "DNSKEY" || i+4 >= len(fields) {
+			continue
+		}
+		if alg := fields[i+3]; alg != "3" && alg != "6" {
+			return nil, fmt.Errorf("DNSKEY algorithm %s is not DSA (3 or 6)", alg)
+		}
+		var encoded strings.Builder
+		for _, part := range fields[i+4:] {
+			if strings.HasPrefix(part, ";") {
+				break // comment
+			}
+			encoded.WriteString(strings.Trim(part, "()"))
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid DNSKEY key data: %w", err)
+		}
+		if len(data) < 1 || data[0] > 8 {
+			return nil, errors.New("invalid DNSKEY key data: bad T")
+		}
+		size := 64 + 8*int(data[0])
+		if len(data) != 1+20+3*size {
+			return nil, fmt.Errorf("invalid DNSKEY key data: %d bytes for T = %d", len(data), data[0])
+		}
+		at := func(offset, n int) *big.Int { return new(big.Int).SetBytes(data[offset : offset+n]) }
+		return &PublicKey{
+			Parameters: Parameters{Q: at(1, 20), P: at(21, size), G: at(21+size, size)},
+			Y:          at(21+2*size, size),
+		}, nil
+	}
+	return nil, errors.New("no DNSKEY record found")
+}
@@ -0,0 +1,79 @@
+package dsaaffine
+
+import (
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+func loadTestKey(t *testing.T) *PublicKey {
+	t.Helper()
+	key, err := LoadPublicKey(filepath.Join("testdata", "dsa_public.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestParsePublicKey(t *testing.T) {
+	want := loadTestKey(t)
+	if want.P.BitLen() != 1024 || want.Q.BitLen() != 160 || want.Y == nil {
+		t.Fatalf("Unexpected PEM key: p %d bits, q %d bits", want.P.BitLen(), want.Q.BitLen())
+	}
+
+	ssh, err := LoadPublicKey(filepath.Join("testdata", "dsa_public.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same key as a DNSKEY record (T = 8 for a 1024-bit p)
+	data := []byte{8}
+	data = append(data, want.Q.FillBytes(make([]byte, 20))...)
+	for _, v := range []*big.Int{want.P, want.G, want.Y} {
+		data = append(data, v.FillBytes(make([]byte, 128))...)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	record := fmt.Sprintf("legacy.example. 3600 IN DNSKEY 256 3 3 (\n %s\n %s ) ; ZSK", encoded[:100], encoded[100:])
+	dnskey, err := ParsePublicKey([]byte(record))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, key := range map[string]*PublicKey{"ssh-dss": ssh, "DNSKEY": dnskey} {
+		if key.P.Cmp(want.P) != 0 || key.Q.Cmp(want.Q) != 0 || key.G.Cmp(want.G) != 0 || key.Y.Cmp(want.Y) != 0 {
+			t.Errorf("%s: key differs from the PEM key", name)
+		}
+	}
+
+	// Bare parameters have no y
+	der, err := asn1.Marshal(struct{ P, Q, G *big.Int }{want.P, want.Q, want.G})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "DSA PARAMETERS", Bytes: der}))
+	if err != nil || params.Y != nil || params.Q.Cmp(want.Q) != 0 {
+		t.Errorf("Expected the parameters without y, got %+v, %v", params, err)
+	}
+}
+
+func TestParsePublicKey_Invalid(t *testing.T) {
+	key := loadTestKey(t)
+	badG, err := asn1.Marshal(struct{ P, Q, G *big.Int }{key.P, key.Q, big.NewInt(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"unknown":   "not a key",
+		"generator": string(pem.EncodeToMemory(&pem.Block{Type: "DSA PARAMETERS", Bytes: badG})),
+		"algorithm": "example. IN DNSKEY 256 3 8 AwEAAQ==",
+		"ssh":       "ssh-dss AAAA",
+	} {
+		if _, err := ParsePublicKey([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package ecdsaaffine
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Probable nonce sources reported by AnalyzeDataset.
+const (
+	NonceSourceReused        = "reused"        // the same r under different messages
+	NonceSourceDeterministic = "deterministic" // a repeated message re-signed with the same r (RFC 6979)
+	NonceSourceRandomized    = "randomized"    // a repeated message re-signed with a different r
+	NonceSourceUnknown       = "unknown"       // r is uniform whatever the nonce source
+)
+
+// prngMinNonces is the number of consecutive 256-bit nonces that clone MT19937 (624
+// 32-bit words), the largest window pkg/prngrecovery needs.
+const prngMinNonces = 78
+
+// DatasetAnalysis describes a signature dataset without attempting recovery, so users
+// can triage it before committing compute. See AnalyzeDataset.
+type DatasetAnalysis struct {
+	Signatures   int              `json:"signatures"`
+	Signers      []SignerAnalysis `json:"signers"`
+	Unattributed int              `json:"unattributed"` // signatures without signer context in a multi-signer dataset
+
+	DuplicateR       int `json:"duplicate_r"`       // signatures whose r repeats an earlier signature by the same signer
+	RepeatedMessages int `json:"repeated_messages"` // hashes signed more than once by the same signer
+
+	Timestamped int       `json:"timestamped"` // signatures with a Timestamp
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+
+	NonceSource string               `json:"nonce_source"` // one of the NonceSource constants
+	Strategies  []StrategyAssessment `json:"strategies"`
+}
+
+// SignerAnalysis is the part of a dataset made by one signer.
+type SignerAnalysis struct {
+	PublicKey  []byte `json:"public_key,omitempty"` // nil for signatures without signer context
+	Signatures int    `json:"signatures"`
+	Pairs      int    `json:"pairs"` // signature pairs a search has to consider
+	DuplicateR int    `json:"duplicate_r"`
+}
+
+// StrategyAssessment says whether a recovery strategy is worth running on a dataset.
+type StrategyAssessment struct {
+	Name       string `json:"name"`
+	Worthwhile bool   `json:"worthwhile"`
+	Reason     string `json:"reason"`
+}
+
+// TimestampSpread is the time between the first and last timestamped signature.
+func (a *DatasetAnalysis) TimestampSpread() time.Duration {
+	return a.LastSeen.Sub(a.FirstSeen)
+}
+
+// AnalyzeDataset reports the characteristics of a dataset that decide which strategies
+// can succeed: signatures per signer (see GroupByPublicKey), repeated r values, the
+// timestamp spread and the probable nonce source. It does no curve arithmetic beyond
+// recovering signer keys from recovery ids, so it is cheap on large datasets.
+//
+// The nonce source can only be told from r values when a signer repeats itself: a
+// repeated r is a reused nonce, and a message signed twice shows whether nonces are
+// derived from the message (same r) or drawn afresh (different r).
+func AnalyzeDataset(signatures []*Signature) *DatasetAnalysis {
+	analysis := &DatasetAnalysis{Signatures: len(signatures), NonceSource: NonceSourceUnknown}
+
+	groups := GroupByPublicKey(signatures)
+	deterministic, randomized := false, false
+	for _, group := range groups {
+		signer := SignerAnalysis{
+			PublicKey:  group.PublicKey,
+			Signatures: len(group.Signatures),
+			Pairs:      len(group.Signatures) * (len(group.Signatures) - 1) / 2,
+		}
+		if len(groups) > 1 && len(group.PublicKey) == 0 {
+			analysis.Unattributed += len(group.Signatures)
+		}
+
+		rByZ := make(map[string]string)
+		zByR := make(map[string]string)
+		counted := make(map[string]bool)
+		for _, sig := range group.Signatures {
+			r, z := sig.R.String(), sig.Z.String()
+			if prevR, ok := rByZ[z]; ok {
+				if !counted[z] {
+					analysis.RepeatedMessages++
+					counted[z] = true
+				}
+				if prevR == r {
+					deterministic = true
+				} else {
+					randomized = true
+				}
+			} else {
+				rByZ[z] = r
+			}
+			if prevZ, ok := zByR[r]; ok && prevZ != z {
+				signer.DuplicateR++
+			} else if !ok {
+				zByR[r] = z
+			}
+		}
+		analysis.DuplicateR += signer.DuplicateR
+		analysis.Signers = append(analysis.Signers, signer)
+	}
+
+	for _, sig := range signatures {
+		if sig.Timestamp.IsZero() {
+			continue
+		}
+		if analysis.Timestamped == 0 || sig.Timestamp.Before(analysis.FirstSeen) {
+			analysis.FirstSeen = sig.Timestamp
+		}
+		if analysis.Timestamped == 0 || sig.Timestamp.After(analysis.LastSeen) {
+			analysis.LastSeen = sig.Timestamp
+		}
+		analysis.Timestamped++
+	}
+
+	switch {
+	case analysis.DuplicateR > 0:
+		analysis.NonceSource = NonceSourceReused
+	case deterministic && !randomized:
+		analysis.NonceSource = NonceSourceDeterministic
+	case randomized:
+		analysis.NonceSource = NonceSourceRandomized
+	}
+	analysis.Strategies = analysis.assessStrategies()
+	return analysis
+}
+
+// assessStrategies decides which strategies are worth running on the analyzed dataset.
+func (a *DatasetAnalysis) assessStrategies() []StrategyAssessment {
+	largest := 0
+	for _, signer := range a.Signers {
+		if signer.Signatures > largest {
+			largest = signer.Signatures
+		}
+	}
+
+	if largest < 2 {
+		reason := "no signer has two signatures, so there is no pair to relate"
+		return []StrategyAssessment{
+			{Name: "same-nonce", Reason: reason},
+			{Name: "smart-brute", Reason: reason},
+			{Name: "bsgs/kangaroo", Reason: reason},
+			{Name: "prng", Reason: reason},
+		}
+	}
+
+	var strategies []StrategyAssessment
+	if a.DuplicateR > 0 {
+		strategies = append(strategies, StrategyAssessment{
+			Name:       "same-nonce",
+			Worthwhile: true,
+			Reason:     fmt.Sprintf("%d signature(s) reuse an r value; --known-a 1 --known-b 0 recovers the key from one pair instantly", a.DuplicateR),
+		})
+	} else {
+		strategies = append(strategies, StrategyAssessment{Name: "same-nonce", Reason: "no r value repeats"})
+	}
+
+	if a.NonceSource == NonceSourceDeterministic {
+		reason := "nonces look deterministic (RFC 6979): a repeated message was re-signed with the same r, so nonces are unrelated hashes"
+		strategies = append(strategies,
+			StrategyAssessment{Name: "smart-brute", Reason: reason},
+			StrategyAssessment{Name: "bsgs/kangaroo", Reason: reason},
+		)
+	} else {
+		strategies = append(strategies,
+			StrategyAssessment{
+				Name:       "smart-brute",
+				Worthwhile: true,
+				Reason:     fmt.Sprintf("%d signer(s) with several signatures; covers counters, small multipliers and common affine patterns", a.multiSigners()),
+			},
+			StrategyAssessment{
+				Name:       "bsgs/kangaroo",
+				Worthwhile: true,
+				Reason:     "counter nonces (k2 = k1 + b) with offsets too large for smart-brute; run if smart-brute fails",
+			},
+		)
+	}
+
+	prng := StrategyAssessment{Name: "prng"}
+	switch {
+	case a.NonceSource == NonceSourceDeterministic:
+		prng.Reason = "nonces look deterministic, not drawn from a PRNG"
+	case largest < prngMinNonces:
+		prng.Reason = fmt.Sprintf("cloning MT19937 needs %d consecutive nonces from one signer, the largest signer has %d", prngMinNonces, largest)
+	default:
+		prng.Worthwhile = true
+		prng.Reason = fmt.Sprintf("after one key is recovered, its %d nonces may clone a flawed PRNG and predict other keys' nonces (pkg/prngrecovery)", largest)
+		if a.Timestamped > 1 {
+			prng.Reason += fmt.Sprintf("; signatures span %v, order them by time", a.TimestampSpread().Round(time.Second))
+		}
+	}
+	return append(strategies, prng)
+}
+
+// multiSigners counts signers with at least two signatures.
+func (a *DatasetAnalysis) multiSigners() int {
+	count := 0
+	for _, signer := range a.Signers {
+		if signer.Signatures >= 2 {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteText renders the analysis as plain text.
+func (a *DatasetAnalysis) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Dataset analysis (%d signatures)\n", a.Signatures)
+	fmt.Fprintf(&sb, "  Signers: %d\n", len(a.Signers))
+	for _, signer := range a.Signers {
+		key := "(no signer context)"
+		if len(signer.PublicKey) > 0 {
+			key = fmt.Sprintf("%x", signer.PublicKey)
+		}
+		fmt.Fprintf(&sb, "    %s: %d signatures, %d pairs, %d duplicate r\n", key, signer.Signatures, signer.Pairs, signer.DuplicateR)
+	}
+	if a.Unattributed > 0 {
+		fmt.Fprintf(&sb, "  Warning: %d signatures have no signer key and are searched as one more signer\n", a.Unattributed)
+	}
+	fmt.Fprintf(&sb, "  Duplicate r: %d\n", a.DuplicateR)
+	fmt.Fprintf(&sb, "  Repeated messages: %d\n", a.RepeatedMessages)
+	if a.Timestamped > 0 {
+		fmt.Fprintf(&sb, "  Timestamps: %d, %s to %s (spread %v)\n", a.Timestamped,
+			a.FirstSeen.Format(time.RFC3339), a.LastSeen.Format(time.RFC3339), a.TimestampSpread().Round(time.Second))
+	} else {
+		sb.WriteString("  Timestamps: none\n")
+	}
+	fmt.Fprintf(&sb, "  Probable nonce source: %s\n", a.NonceSource)
+
+	sb.WriteString("\n  Strategies:\n")
+	for _, strategy := range a.Strategies {
+		verdict := "skip"
+		if strategy.Worthwhile {
+			verdict = "run "
+		}
+		fmt.Fprintf(&sb, "    [%s] %-14s %s\n", verdict, strategy.Name, strategy.Reason)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,129 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// assessment returns the named strategy's assessment.
+func assessment(t *testing.T, analysis *DatasetAnalysis, name string) StrategyAssessment {
+	t.Helper()
+	for _, strategy := range analysis.Strategies {
+		if strategy.Name == name {
+			return strategy
+		}
+	}
+	t.Fatalf("No assessment for %s", name)
+	return StrategyAssessment{}
+}
+
+func TestAnalyzeDataset_ReusedNonce(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k := big.NewInt(0x1234567)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var signatures []*Signature
+	for i := 0; i < 4; i++ {
+		nonce := new(big.Int).Add(k, big.NewInt(int64(i%3)))
+		sig := signWithNonce(d, nonce, HashMessage([]byte{byte(i)}))
+		sig.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		signatures = append(signatures, sig)
+	}
+
+	analysis := AnalyzeDataset(signatures)
+	if analysis.Signatures != 4 || len(analysis.Signers) != 1 || analysis.Signers[0].Pairs != 6 {
+		t.Errorf("Unexpected signer breakdown: %+v", analysis.Signers)
+	}
+	if analysis.DuplicateR != 1 || analysis.NonceSource != NonceSourceReused {
+		t.Errorf("DuplicateR = %d, NonceSource = %s; expected 1, reused", analysis.DuplicateR, analysis.NonceSource)
+	}
+	if analysis.Timestamped != 4 || analysis.TimestampSpread() != 3*time.Hour {
+		t.Errorf("Timestamps: %d spanning %v", analysis.Timestamped, analysis.TimestampSpread())
+	}
+	if !assessment(t, analysis, "same-nonce").Worthwhile || !assessment(t, analysis, "smart-brute").Worthwhile {
+		t.Error("Expected same-nonce and smart-brute to be worthwhile")
+	}
+	if assessment(t, analysis, "prng").Worthwhile {
+		t.Error("Expected prng to need more signatures")
+	}
+
+	var sb strings.Builder
+	if err := analysis.WriteText(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "Probable nonce source: reused") {
+		t.Errorf("Unexpected report:\n%s", sb.String())
+	}
+}
+
+func TestAnalyzeDataset_NonceSource(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	z := HashMessage([]byte("same message"))
+	k := big.NewInt(0xabcdef)
+
+	// Re-signing a message with the same nonce is what RFC 6979 does
+	deterministic := AnalyzeDataset([]*Signature{
+		signWithNonce(d, k, z),
+		signWithNonce(d, k, z),
+		signWithNonce(d, big.NewInt(77), HashMessage([]byte("other"))),
+	})
+	if deterministic.NonceSource != NonceSourceDeterministic || deterministic.RepeatedMessages != 1 || deterministic.DuplicateR != 0 {
+		t.Errorf("Got %s, %d repeated, %d duplicate r; expected deterministic, 1, 0",
+			deterministic.NonceSource, deterministic.RepeatedMessages, deterministic.DuplicateR)
+	}
+	if assessment(t, deterministic, "smart-brute").Worthwhile {
+		t.Error("Expected smart-brute not to be worthwhile for deterministic nonces")
+	}
+
+	randomized := AnalyzeDataset([]*Signature{
+		signWithNonce(d, k, z),
+		signWithNonce(d, new(big.Int).Add(k, big.NewInt(1)), z),
+	})
+	if randomized.NonceSource != NonceSourceRandomized {
+		t.Errorf("Got %s, expected randomized", randomized.NonceSource)
+	}
+
+	unknown := AnalyzeDataset([]*Signature{
+		signWithNonce(d, k, HashMessage([]byte("a"))),
+		signWithNonce(d, new(big.Int).Add(k, big.NewInt(1)), HashMessage([]byte("b"))),
+	})
+	if unknown.NonceSource != NonceSourceUnknown {
+		t.Errorf("Got %s, expected unknown", unknown.NonceSource)
+	}
+}
+
+func TestAnalyzeDataset_Signers(t *testing.T) {
+	d1, d2 := big.NewInt(111), big.NewInt(222)
+	pub1 := secp256k1.PrivKeyFromBytes(d1.Bytes()).PubKey().SerializeCompressed()
+	pub2 := secp256k1.PrivKeyFromBytes(d2.Bytes()).PubKey().SerializeCompressed()
+	k := big.NewInt(0x5151)
+
+	var signatures []*Signature
+	for i, tt := range []struct {
+		d   *big.Int
+		pub []byte
+	}{{d1, pub1}, {d2, pub2}, {d1, pub1}, {d2, nil}} {
+		sig := signWithNonce(tt.d, new(big.Int).Add(k, big.NewInt(int64(i))), HashMessage([]byte{byte(i)}))
+		sig.PublicKey = tt.pub
+		signatures = append(signatures, sig)
+	}
+
+	analysis := AnalyzeDataset(signatures)
+	if len(analysis.Signers) != 3 || analysis.Unattributed != 1 {
+		t.Fatalf("Got %d signers, %d unattributed; expected 3, 1", len(analysis.Signers), analysis.Unattributed)
+	}
+	if !bytes.Equal(analysis.Signers[0].PublicKey, pub1) || analysis.Signers[0].Signatures != 2 {
+		t.Errorf("First signer = %x with %d signatures", analysis.Signers[0].PublicKey, analysis.Signers[0].Signatures)
+	}
+
+	single := AnalyzeDataset(signatures[:2])
+	for _, strategy := range single.Strategies {
+		if strategy.Worthwhile {
+			t.Errorf("%s: expected nothing to be worthwhile with one signature per signer", strategy.Name)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/modn"
+)
+
+// ArithmeticBackend selects the modular arithmetic the range search recovers candidate
+// keys with (RangeConfig.Arithmetic, Client.WithArithmetic). Both backends recover the
+// same keys.
+type ArithmeticBackend int
+
+const (
+	// AutoArithmetic uses FixedArithmetic on platforms with a 64×64-bit multiply
+	// instruction (amd64, arm64 and other 64-bit platforms) and BigArithmetic elsewhere.
+	// It is the default.
+	AutoArithmetic ArithmeticBackend = iota
+
+	// BigArithmetic recovers every candidate key with RecoverPrivateKey (math/big).
+	BigArithmetic
+
+	// FixedArithmetic recovers candidate keys on fixed-width 256-bit Montgomery residues
+	// mod n: the products of the signature values are computed once per pair and the
+	// denominator's inverse once per a, leaving two multiplications and no allocation
+	// per candidate until a key is returned.
+	FixedArithmetic
+)
+
+// fixed reports whether the backend resolves to FixedArithmetic on this platform.
+func (b ArithmeticBackend) fixed() bool {
+	return b == FixedArithmetic || b == AutoArithmetic && modn.Native()
+}
+
+// pairRecovery recovers keys from one signature pair, as RecoverPrivateKey does, in
+// fixed-width arithmetic:
+//
+//	d = (a·s2·z1 - s1·z2 + b·s1·s2) / (r2·s1 - a·r1·s2) mod n
+//
+// For a fixed a, the numerator's a term and the denominator's inverse do not depend on
+// b; they are kept for calls with the same a (the range search's enumeration order). A
+// pairRecovery is not safe for concurrent use: each search worker has its own.
+type pairRecovery struct {
+	s2z1, s1z2, s1s2 modn.Elem
+	r2s1, r1s2       modn.Elem
+
+	a     int64
+	ready bool      // a is set
+	valid bool      // the denominator for a is invertible
+	base  modn.Elem // a·s2·z1 - s1·z2
+	inv   modn.Elem // (r2·s1 - a·r1·s2)^-1
+}
+
+// newPairRecovery precomputes the pair's products for k2 = a·k1 + b.
+func newPairRecovery(sig1, sig2 *Signature) *pairRecovery {
+	n := modn.Secp256k1N
+	var r1, s1, z1, r2, s2, z2 modn.Elem
+	n.SetBig(&r1, sig1.R)
+	n.SetBig(&s1, sig1.S)
+	n.SetBig(&z1, sig1.Z)
+	n.SetBig(&r2, sig2.R)
+	n.SetBig(&s2, sig2.S)
+	n.SetBig(&z2, sig2.Z)
+
+	p := &pairRecovery{}
+	n.Mul(&p.s2z1, &s2, &z1)
+	n.Mul(&p.s1z2, &s1, &z2)
+	n.Mul(&p.s1s2, &s1, &s2)
+	n.Mul(&p.r2s1, &r2, &s1)
+	n.Mul(&p.r1s2, &r1, &s2)
+	return p
+}
+
+// pairRecovery returns a pairRecovery for (sig1, sig2), or nil when the strategy
+// recovers keys with BigArithmetic.
+func (s *SmartBruteForceStrategy) pairRecovery(sig1, sig2 *Signature) *pairRecovery {
+	if !s.RangeConfig.Arithmetic.fixed() {
+		return nil
+	}
+	return newPairRecovery(sig1, sig2)
+}
+
+// setA prepares the terms that depend on a alone, and reports whether any key solves
+// the pair for it.
+func (p *pairRecovery) setA(a int64) bool {
+	if p.ready && p.a == a {
+		return p.valid
+	}
+	n := modn.Secp256k1N
+	p.a, p.ready, p.valid = a, true, false
+
+	var aElem, den modn.Elem
+	n.SetInt64(&aElem, a)
+	n.Mul(&den, &aElem, &p.r1s2)
+	n.Sub(&den, &p.r2s1, &den)
+	if n.Inverse(&p.inv, &den) == nil {
+		return false
+	}
+	n.Mul(&p.base, &aElem, &p.s2z1)
+	n.Sub(&p.base, &p.base, &p.s1z2)
+	p.valid = true
+	return true
+}
+
+// solve sets d to the key for (a, b), possibly zero, and reports whether there is one.
+func (p *pairRecovery) solve(a, b int64, d *modn.Elem) bool {
+	if !p.setA(a) {
+		return false
+	}
+	n := modn.Secp256k1N
+	n.SetInt64(d, b)
+	n.Mul(d, d, &p.s1s2)
+	n.Add(d, d, &p.base)
+	n.Mul(d, d, &p.inv)
+	return true
+}
+
+// key sets key to the key for (a, b) as 32 big-endian bytes, and reports whether there
+// is a key in [1, n-1]. It does not allocate.
+func (p *pairRecovery) key(a, b int64, key *[32]byte) bool {
+	var d modn.Elem
+	if !p.solve(a, b, &d) || d.IsZero() {
+		return false
+	}
+	*key = modn.Secp256k1N.Bytes(&d)
+	return true
+}
+
+// step sets key to the key for (a, b) and delta to the difference between the keys for
+// b+1 and b, (s1·s2)/(r2·s1 - a·r1·s2), as 32 big-endian bytes, for keyStepper. Either
+// may be zero. It reports whether there is a key for a.
+func (p *pairRecovery) step(a, b int64, key, delta *[32]byte) bool {
+	var d modn.Elem
+	if !p.solve(a, b, &d) {
+		return false
+	}
+	n := modn.Secp256k1N
+	*key = n.Bytes(&d)
+	n.Mul(&d, &p.s1s2, &p.inv)
+	*delta = n.Bytes(&d)
+	return true
+}
+
+// recoverRangeKey recovers the key for k2 = a·k1 + b from the pair (sig1, sig2) with
+// recovery, or with RecoverPrivateKey when recovery is nil. It returns nil when no key
+// in [1, n-1] solves the pair.
+func recoverRangeKey(sig1, sig2 *Signature, recovery *pairRecovery, a, b int64) *big.Int {
+	if recovery != nil {
+		var key [32]byte
+		if !recovery.key(a, b, &key) {
+			return nil
+		}
+		return new(big.Int).SetBytes(key[:])
+	}
+	priv, err := RecoverPrivateKey(sig1, sig2, big.NewInt(a), big.NewInt(b))
+	if err != nil || priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
+		return nil
+	}
+	return priv
+}
+
+// verifiedRangeKey is recoverRangeKey for a candidate the verifier must accept: it
+// returns the key if the verifier accepts it, or nil. With a pairRecovery, a candidate
+// whose key is rejected costs no allocation (for public key targets).
+func verifiedRangeKey(verifier *KeyVerifier, sig1, sig2 *Signature, recovery *pairRecovery, a, b int64) *big.Int {
+	if recovery == nil {
+		priv := recoverRangeKey(sig1, sig2, nil, a, b)
+		if priv == nil || !verifier.Verify(priv) {
+			return nil
+		}
+		return priv
+	}
+	var key [32]byte
+	if !recovery.key(a, b, &key) || !verifier.verifyKey(&key) {
+		return nil
+	}
+	return new(big.Int).SetBytes(key[:])
+}
@@ -0,0 +1,150 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestPairRecovery_MatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() *big.Int { return new(big.Int).Rand(rng, Secp256k1CurveOrder) }
+
+	d := big.NewInt(0xdeadbeef)
+	valid := []*Signature{
+		signWithNonce(d, big.NewInt(1000), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(3*1000-41), HashMessage([]byte("message 2"))),
+	}
+	pairs := [][2]*Signature{
+		{valid[0], valid[1]},
+		{valid[1], valid[0]},
+		// The same signature twice has no key for a = 1 (zero denominator)
+		{valid[0], valid[0]},
+	}
+	for i := 0; i < 8; i++ {
+		// Arbitrary values, z not reduced mod n
+		pairs = append(pairs, [2]*Signature{
+			{R: random(), S: random(), Z: new(big.Int).Add(random(), Secp256k1CurveOrder)},
+			{R: random(), S: random(), Z: random()},
+		})
+	}
+
+	as := []int64{-100, -3, -1, 0, 1, 2, 3, 977, math.MinInt64, math.MaxInt64}
+	bs := []int64{-1000, -41, -1, 0, 1, 41, 123456789, math.MinInt64, math.MaxInt64}
+	for p, pair := range pairs {
+		recovery := newPairRecovery(pair[0], pair[1])
+		for _, a := range as {
+			for _, b := range bs {
+				want, err := RecoverPrivateKey(pair[0], pair[1], big.NewInt(a), big.NewInt(b))
+				var key, delta [32]byte
+				if !recovery.step(a, b, &key, &delta) {
+					if err == nil {
+						t.Fatalf("pair %d, a=%d, b=%d: fixed has no key, big has %v", p, a, b, want)
+					}
+					continue
+				}
+				if err != nil || new(big.Int).SetBytes(key[:]).Cmp(want) != 0 {
+					t.Fatalf("pair %d, a=%d, b=%d: fixed = %x, big = %v, %v", p, a, b, key, want, err)
+				}
+
+				// The step is the difference to the next b's key
+				if b < math.MaxInt64 {
+					next, _ := RecoverPrivateKey(pair[0], pair[1], big.NewInt(a), big.NewInt(b+1))
+					wantDelta := new(big.Int).Sub(next, want)
+					wantDelta.Mod(wantDelta, Secp256k1CurveOrder)
+					if new(big.Int).SetBytes(delta[:]).Cmp(wantDelta) != 0 {
+						t.Fatalf("pair %d, a=%d, b=%d: fixed step = %x, big = %v", p, a, b, delta, wantDelta)
+					}
+				}
+
+				fixed := recoverRangeKey(pair[0], pair[1], recovery, a, b)
+				reference := recoverRangeKey(pair[0], pair[1], nil, a, b)
+				if (fixed == nil) != (reference == nil) || fixed != nil && fixed.Cmp(reference) != 0 {
+					t.Fatalf("pair %d, a=%d, b=%d: recoverRangeKey fixed = %v, big = %v", p, a, b, fixed, reference)
+				}
+			}
+		}
+	}
+
+	if got := recoverRangeKey(valid[0], valid[1], newPairRecovery(valid[0], valid[1]), 3, -41); got == nil || got.Cmp(d) != 0 {
+		t.Errorf("recoverRangeKey(3, -41) = %v, want %v", got, d)
+	}
+}
+
+// TestPairRecovery_Allocs checks a rejected candidate costs no allocation with
+// FixedArithmetic: recovered, screened by the stepper and verified.
+func TestPairRecovery_Allocs(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	sig1 := signWithNonce(d, big.NewInt(1000), HashMessage([]byte("message 1")))
+	sig2 := signWithNonce(d, big.NewInt(1777), HashMessage([]byte("message 2")))
+	verifier := newKeyVerifier(publicKey)
+	recovery := newPairRecovery(sig1, sig2)
+	stepper := newKeyStepper(sig1, sig2, verifier, recovery)
+
+	b := int64(0)
+	allocs := testing.AllocsPerRun(1000, func() {
+		b++
+		stepper.mayMatch(2, b)
+		if verifiedRangeKey(verifier, sig1, sig2, recovery, 2, b) != nil {
+			t.Fatal("Unexpected key")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations per candidate, want 0", allocs)
+	}
+}
+
+func TestSmartBruteForceStrategy_Arithmetic(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(5000), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(-7*5000+23), HashMessage([]byte("message 2"))),
+	}
+
+	for _, backend := range []ArithmeticBackend{AutoArithmetic, BigArithmetic, FixedArithmetic} {
+		config := DefaultRangeConfig()
+		config.Arithmetic = backend
+		strategy := NewSmartBruteForceStrategy().WithRangeConfig(config)
+		if (strategy.pairRecovery(signatures[0], signatures[1]) != nil) != backend.fixed() {
+			t.Errorf("backend %d: pairRecovery does not match fixed() = %v", backend, backend.fixed())
+		}
+
+		aRange, bRange := [2]int{-10, 10}, [2]int{0, 50}
+		for name, result := range map[string]*RecoveryResult{
+			"sequential": strategy.rangeSearchSequential(context.Background(), signatures, publicKey, aRange, bRange, 1),
+			"parallel":   strategy.rangeSearchParallel(context.Background(), signatures, publicKey, aRange, bRange, 1, 4),
+		} {
+			if result == nil || result.PrivateKey.Cmp(d) != 0 || result.Relationship.A.Int64() != -7 || result.Relationship.B.Int64() != 23 {
+				t.Errorf("backend %d, %s: got %+v", backend, name, result)
+			}
+		}
+	}
+
+	strategy := NewSmartBruteForceStrategy()
+	NewClient(WithArithmetic(BigArithmetic), WithStrategy(strategy))
+	if strategy.RangeConfig.Arithmetic != BigArithmetic {
+		t.Error("Expected WithArithmetic to carry over to the strategy")
+	}
+}
+
+func BenchmarkRecoverRangeKey(b *testing.B) {
+	d := big.NewInt(0xdeadbeef)
+	sig1 := signWithNonce(d, big.NewInt(1000), HashMessage([]byte("message 1")))
+	sig2 := signWithNonce(d, big.NewInt(1777), HashMessage([]byte("message 2")))
+	for _, backend := range []struct {
+		name     string
+		recovery *pairRecovery
+	}{{"big", nil}, {"fixed", newPairRecovery(sig1, sig2)}} {
+		b.Run(backend.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				recoverRangeKey(sig1, sig2, backend.recovery, 1, int64(i))
+			}
+		})
+	}
+}
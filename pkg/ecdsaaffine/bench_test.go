@@ -0,0 +1,181 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// benchSignatures returns count signatures with unrelated nonces, so no search phase finds the key.
+func benchSignatures(d *big.Int, count int) []*Signature {
+	var signatures []*Signature
+	for i := 0; i < count; i++ {
+		k := new(big.Int).Lsh(big.NewInt(int64(1000+i)), 200)
+		k.Add(k, big.NewInt(int64(i*i*7919)))
+		signatures = append(signatures, signWithNonce(d, k, HashMessage([]byte{byte(i)})))
+	}
+	return signatures
+}
+
+// quietLogs silences the search progress logging for the duration of a benchmark.
+func quietLogs(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+func BenchmarkRecoverPrivateKey(b *testing.B) {
+	signatures := benchSignatures(big.NewInt(0xdeadbeef), 2)
+	a, offset := big.NewInt(3), big.NewInt(-57)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RecoverPrivateKey(signatures[0], signatures[1], a, offset)
+	}
+}
+
+func BenchmarkVerifyRecoveredKey(b *testing.B) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyRecoveredKey(d, publicKey)
+	}
+}
+
+func BenchmarkKeyVerifier(b *testing.B) {
+	d := big.NewInt(0xdeadbeef)
+	verifier, _ := NewKeyVerifier(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifier.Verify(d)
+	}
+}
+
+func BenchmarkTryCommonPatterns(b *testing.B) {
+	quietLogs(b)
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := benchSignatures(d, 5)
+	strategy := NewSmartBruteForceStrategy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if strategy.tryCommonPatterns(context.Background(), signatures, publicKey) != nil {
+			b.Fatal("Unexpected recovery")
+		}
+	}
+}
+
+func BenchmarkRangeSearch(b *testing.B) {
+	quietLogs(b)
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := benchSignatures(d, 2)
+	strategy := NewSmartBruteForceStrategy()
+	var tested int64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, n := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{1, 2}, [2]int{-500, 500}, 1, 0)
+		tested += n
+	}
+	b.ReportMetric(float64(tested)/b.Elapsed().Seconds(), "candidates/sec")
+}
+
+// BenchmarkKeyStepper measures the per-candidate screen of the range search: a point
+// addition and an x comparison per b, with a scalar multiplication per a.
+func BenchmarkKeyStepper(b *testing.B) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := benchSignatures(d, 2)
+	verifier := newKeyVerifier(publicKey)
+	stepper := newKeyStepper(signatures[0], signatures[1], verifier, newPairRecovery(signatures[0], signatures[1]))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stepper.mayMatch(int64(1+i/100000), int64(i%100000))
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "candidates/sec")
+}
+
+// BenchmarkScreenX compares the keyStepper's window screen in assembly, where the
+// platform has it, with the math/bits version.
+func BenchmarkScreenX(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	var x fieldLimbs
+	xs, zs, match := make([]fieldLimbs, screenWindow), make([]fieldLimbs, screenWindow), make([]bool, screenWindow)
+	for i := range xs {
+		xs[i] = limbsOf(new(big.Int).Rand(random, secp256k1.Params().P))
+		zs[i] = limbsOf(new(big.Int).Rand(random, secp256k1.Params().P))
+	}
+	for _, bench := range []struct {
+		name   string
+		screen func(*fieldLimbs, []fieldLimbs, []fieldLimbs, []bool)
+	}{{"screenX", screenX}, {"generic", screenXGeneric}} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bench.screen(&x, xs, zs, match)
+			}
+			b.ReportMetric(float64(b.N*screenWindow)/b.Elapsed().Seconds(), "candidates/sec")
+		})
+	}
+}
+
+// BenchmarkLoadSignatures compares parsing a 100k-signature JSON fixture with loading its
+// binary copy, as LoadCachedSignatures does after the first run.
+func BenchmarkLoadSignatures(b *testing.B) {
+	// Loading does not check the signatures, so random values stand in for real ones
+	random := rand.New(rand.NewSource(1))
+	signatures := make([]*Signature, 100000)
+	for i := range signatures {
+		values := make([]*big.Int, 3)
+		for k := range values {
+			values[k] = new(big.Int).Rand(random, Secp256k1CurveOrder)
+		}
+		signatures[i] = &Signature{Z: values[0], R: values[1], S: values[2]}
+	}
+	dir := b.TempDir()
+	jsonPath, storePath := filepath.Join(dir, "signatures.json"), filepath.Join(dir, "signatures.store")
+	var buf bytes.Buffer
+	if err := WriteSignaturesJSON(&buf, signatures); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, buf.Bytes(), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	if err := WriteSignatureStore(storePath, signatures, nil, WithStoreSignerContext()); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, format := range []string{"json", "store"} {
+		parser, _ := NewParser(format)
+		path := map[string]string{"json": jsonPath, "store": storePath}[format]
+		b.Run(format, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseSignatures(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestMeasureSearchRate checks the measurement itself; throughput regressions are tracked
+// with BenchmarkRangeSearch, not with a floor that a loaded CI machine can miss.
+func TestMeasureSearchRate(t *testing.T) {
+	rate := MeasureSearchRate(context.Background(), 200*time.Millisecond, 1)
+	if rate.Workers != 1 {
+		t.Errorf("Expected 1 worker, got %d", rate.Workers)
+	}
+	if rate.Candidates == 0 || rate.Elapsed <= 0 {
+		t.Errorf("Expected candidates tried over some time, got %+v", rate)
+	}
+	if est := rate.Estimate(rate.PerSecond() * 10); est < 9*time.Second || est > 11*time.Second {
+		t.Errorf("Expected Estimate of 10s worth of candidates to be ~10s, got %v", est)
+	}
+}
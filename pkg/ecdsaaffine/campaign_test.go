@@ -0,0 +1,107 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// fleetTarget is a device whose firmware steps its nonce by step from k.
+func fleetTarget(name string, d, k *big.Int, step int64) CampaignTarget {
+	var signatures []*Signature
+	for i := 0; i < 3; i++ {
+		nonce := new(big.Int).Add(k, big.NewInt(int64(i)*step))
+		signatures = append(signatures, signWithNonce(d, nonce, HashMessage([]byte(fmt.Sprintf("%s message %d", name, i)))))
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	return CampaignTarget{Name: name, Signatures: signatures, PublicKey: fmt.Sprintf("%x", publicKey)}
+}
+
+func TestClient_RecoverCampaign(t *testing.T) {
+	k, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	keys := []*big.Int{big.NewInt(0xdeadbeef), big.NewInt(0xc0ffee), big.NewInt(0xbad), big.NewInt(0xf00d)}
+	targets := []CampaignTarget{
+		fleetTarget("device-1", keys[0], k, 13511),
+		fleetTarget("device-2", keys[1], new(big.Int).Add(k, big.NewInt(1000)), 13511),
+		fleetTarget("device-3", keys[2], new(big.Int).Add(k, big.NewInt(2000)), 77777), // other firmware
+		fleetTarget("device-4", keys[3], new(big.Int).Add(k, big.NewInt(3000)), 13511),
+	}
+
+	// The strategy only reaches step 13511, so a later key found outside it comes from the prior
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{13500, 13520}
+	results, priors, err := NewClient().WithStrategy(strategy).RecoverCampaign(context.Background(), targets)
+	if err != nil {
+		t.Fatalf("RecoverCampaign failed: %v", err)
+	}
+	if len(results) != len(targets) {
+		t.Fatalf("Expected %d results, got %d", len(targets), len(results))
+	}
+	for i, want := range []bool{false, true, false, true} {
+		result := results[i]
+		if result.Target != targets[i].Name {
+			t.Errorf("Result %d is for %q, expected %q", i, result.Target, targets[i].Name)
+		}
+		if i == 2 {
+			if result.Result != nil || result.Err == nil {
+				t.Errorf("Expected no key for %s, got %+v", result.Target, result)
+			}
+			continue
+		}
+		if result.Result == nil || result.Result.PrivateKey.Cmp(keys[i]) != 0 || result.Prior != want {
+			t.Errorf("%s: expected key %s (prior=%v), got %+v", result.Target, keys[i], want, result)
+			continue
+		}
+		if result.Result.Pattern != results[0].Result.Pattern {
+			t.Errorf("%s: expected the prior's pattern %q, got %q", result.Target, results[0].Result.Pattern, result.Result.Pattern)
+		}
+	}
+	if len(priors) != 1 || priors[0].Keys != 3 || priors[0].Relationship.B.Int64() != 13511 {
+		t.Errorf("Expected one prior b=13511 with 3 keys, got %+v", priors)
+	}
+
+	// A target that cannot be read is reported, and the campaign goes on
+	results, _, err = NewClient().WithStrategy(strategy).RecoverCampaign(context.Background(), []CampaignTarget{
+		{Name: "missing", Source: "testdata/no_such_file.json"},
+		targets[0],
+	})
+	if err != nil || results[0].Err == nil || results[1].Result == nil {
+		t.Errorf("Expected an error for the missing file and a key for the next target, got %+v, %+v (%v)", results[0], results[1], err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if results, _, err := NewClient().RecoverCampaign(ctx, targets); err == nil || len(results) != 0 {
+		t.Errorf("Expected a cancelled campaign to stop before the first target, got %d results (%v)", len(results), err)
+	}
+}
+
+func TestLearnPrior(t *testing.T) {
+	result := func(b int64, pattern string) *RecoveryResult {
+		return &RecoveryResult{Relationship: AffineRelationship{A: big.NewInt(1), B: big.NewInt(b)}, Pattern: pattern, Verified: true}
+	}
+	var priors []CampaignPrior
+	priors = learnPrior(priors, result(5, "counter_+5"))
+	priors = learnPrior(priors, result(13511, "step_13511"))
+	priors = learnPrior(priors, result(13511, "step_13511"))
+	// The same relationship written mod n counts as the same prior
+	priors = learnPrior(priors, &RecoveryResult{Relationship: AffineRelationship{A: big.NewInt(1), B: OrderMinus(-5)}})
+	priors = learnPrior(priors, result(7, "counter_+7"))
+
+	if len(priors) != 3 {
+		t.Fatalf("Expected 3 priors, got %+v", priors)
+	}
+	// Among equals, the prior that reached its count first stays first
+	for i, want := range []struct {
+		b    int64
+		keys int
+	}{{13511, 2}, {5, 2}, {7, 1}} {
+		if priors[i].Relationship.B.Int64() != want.b || priors[i].Keys != want.keys {
+			t.Errorf("Prior %d: expected b=%d with %d keys, got b=%s with %d", i, want.b, want.keys, priors[i].Relationship.B, priors[i].Keys)
+		}
+	}
+}
@@ -2,49 +2,108 @@ package ecdsaaffine
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
+	"log"
 	"math/big"
-	"strings"
 )
 
 // Client provides a high-level API for ECDSA key recovery operations.
 type Client struct {
 	strategy BruteForceStrategy
 	parser   SignatureParser
+	pairs    [][2]int
+
+	crossCheck *int // limit of WithCrossCheck, nil if off
+
+	verification *VerificationBackend
+	arithmetic   *ArithmeticBackend
+	logger       *log.Logger
+	hash         func(message []byte) *big.Int
+	curve        *Curve
 }
 
-// NewClient creates a new client with default settings.
-func NewClient() *Client {
-	return &Client{
+// NewClient creates a new client with default settings, changed by opts.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		strategy: NewSmartBruteForceStrategy(),
 		parser: &JSONParser{
 			ZField: "z", // Use "z" field if present, otherwise hash "message" field
 		},
 	}
+	return c.apply(opts...)
 }
 
-// WithStrategy sets a custom brute-force strategy.
+// WithStrategy sets a custom brute-force strategy (see the WithStrategy option).
 func (c *Client) WithStrategy(strategy BruteForceStrategy) *Client {
-	c.strategy = strategy
-	return c
+	return c.apply(WithStrategy(strategy))
 }
 
-// WithParser sets a custom signature parser.
+// WithParser sets a custom signature parser (see the WithParser option).
 func (c *Client) WithParser(parser SignatureParser) *Client {
-	c.parser = parser
-	return c
+	return c.apply(WithParser(parser))
+}
+
+// WithVerification selects how the client's brute-force strategy checks candidate keys
+// (see the WithVerification option).
+func (c *Client) WithVerification(backend VerificationBackend) *Client {
+	return c.apply(WithVerification(backend))
+}
+
+// WithArithmetic selects the modular arithmetic of the client's brute-force strategy
+// (see the WithArithmetic option).
+func (c *Client) WithArithmetic(backend ArithmeticBackend) *Client {
+	return c.apply(WithArithmetic(backend))
+}
+
+// WithPairs restricts recovery to the given signature pairs (see the WithPairs option).
+func (c *Client) WithPairs(pairs ...[2]int) *Client {
+	return c.apply(WithPairs(pairs...))
+}
+
+// WithCrossCheck checks recovered keys against other signatures (see the WithCrossCheck
+// option).
+func (c *Client) WithCrossCheck(limit int) *Client {
+	return c.apply(WithCrossCheck(limit))
+}
+
+// WithLogger sends the strategy's progress messages to logger (see the WithLogger option).
+func (c *Client) WithLogger(logger *log.Logger) *Client {
+	return c.apply(WithLogger(logger))
+}
+
+// WithHasher sets how messages are hashed to z (see the WithHasher option).
+func (c *Client) WithHasher(hash func(message []byte) *big.Int) *Client {
+	return c.apply(WithHasher(hash))
+}
+
+// WithCurve runs the recovery math in curve's group (see the WithCurve option).
+func (c *Client) WithCurve(curve *Curve) *Client {
+	return c.apply(WithCurve(curve))
 }
 
 // RecoverKey attempts to recover a private key from signatures in a file.
 //
 // Args:
-//   - source: Path to signature file (JSON or CSV)
-//   - publicKeyHex: Optional public key in hex format for verification
+//   - source: Path or URL (https://, s3://, gs://) of signature file (JSON or CSV), or
+//     path of a signature store with a StoreParser
+//   - publicKeyHex: Optional public key for verification: hex (compressed, uncompressed,
+//     hybrid, raw 64-byte X||Y, or an Ethereum address), or a Bitcoin address, xpub or
+//     npub (see ParseTarget)
 //
 // Returns:
 //   - RecoveryResult if successful, error otherwise
 func (c *Client) RecoverKey(ctx context.Context, source string, publicKeyHex string) (*RecoveryResult, error) {
+	// Stores are searched window by window rather than loaded whole, unless explicit
+	// pairs index into the whole input
+	if p, ok := c.parser.(*StoreParser); ok && len(c.pairs) == 0 {
+		store, err := p.open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signatures: %w", err)
+		}
+		defer store.Close()
+		return c.RecoverKeyFromStore(ctx, store, p.Window, publicKeyHex)
+	}
+
 	signatures, err := c.parser.ParseSignatures(source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse signatures: %w", err)
@@ -54,7 +113,10 @@ func (c *Client) RecoverKey(ctx context.Context, source string, publicKeyHex str
 
 // RecoverKeyFromSignatures attempts to recover a private key from in-memory signatures.
 // Use this when you have already parsed signatures (e.g. from your own parser, blockchain, or API).
-// Public key is optional; when provided, the recovered key is verified.
+// Public key is optional; when provided, the recovered key is verified. Otherwise it is
+// verified against the signer key carried by the signatures (PublicKey or RecoveryID).
+// Signatures by different signers are never paired; use RecoverKeysFromSignatures to get
+// a result for every signer.
 func (c *Client) RecoverKeyFromSignatures(ctx context.Context, signatures []*Signature, publicKeyHex string) (*RecoveryResult, error) {
 	if len(signatures) < 2 {
 		return nil, fmt.Errorf("need at least 2 signatures, got %d", len(signatures))
@@ -63,29 +125,47 @@ func (c *Client) RecoverKeyFromSignatures(ctx context.Context, signatures []*Sig
 	var publicKey []byte
 	if publicKeyHex != "" {
 		var err error
-		publicKey, err = hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
+		publicKey, err = c.parseTarget(publicKeyHex)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse public key: %w", err)
-		}
-		if len(publicKey) != 33 {
-			return nil, fmt.Errorf("public key must be 33 bytes (compressed format), got %d", len(publicKey))
+			return nil, err
 		}
 	}
+	if c.curve != nil {
+		return c.recoverOnCurve(ctx, signatures, publicKey)
+	}
 
-	result := c.strategy.Search(ctx, signatures, publicKey)
+	var result *RecoveryResult
+	if len(c.pairs) > 0 {
+		if err := c.checkPairs(len(signatures)); err != nil {
+			return nil, err
+		}
+		result = c.searchPairs(ctx, signatures, publicKey)
+	} else {
+		result = c.searchByKey(ctx, signatures, publicKey)
+	}
 	if result == nil {
-		return nil, fmt.Errorf("failed to recover private key")
+		return nil, c.searchFailed()
 	}
+	c.corroborate(result, signatures)
 	return result, nil
 }
 
+// searchFailed returns the error of a search that found no key, wrapping
+// ErrQuotaExceeded when the strategy stopped at its quota.
+func (c *Client) searchFailed() error {
+	if s, ok := c.strategy.(smartStrategy); ok && s.smart().QuotaExceeded() {
+		return fmt.Errorf("failed to recover private key: %w", ErrQuotaExceeded)
+	}
+	return fmt.Errorf("failed to recover private key")
+}
+
 // RecoverKeyWithKnownRelationship recovers a private key when the affine relationship is known.
 //
 // Args:
-//   - source: Path to signature file
+//   - source: Path or URL of signature file
 //   - a: Affine coefficient (k2 = a*k1 + b)
 //   - b: Affine offset (k2 = a*k1 + b)
-//   - publicKeyHex: Optional public key for verification
+//   - publicKeyHex: Optional public key for verification (defaults to the signatures' signer key)
 //
 // Returns:
 //   - RecoveryResult if successful, error otherwise
@@ -103,48 +183,193 @@ func (c *Client) RecoverKeyWithKnownRelationship(ctx context.Context, source str
 	// Parse public key if provided
 	var publicKey []byte
 	if publicKeyHex != "" {
-		publicKey, err = hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
+		publicKey, err = c.parseTarget(publicKeyHex)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse public key: %w", err)
+			return nil, err
 		}
-		if len(publicKey) != 33 {
-			return nil, fmt.Errorf("public key must be 33 bytes (compressed format), got %d", len(publicKey))
+	}
+
+	if err := c.checkPairs(len(signatures)); err != nil {
+		return nil, err
+	}
+	if c.curve != nil {
+		if err := c.curve.Validate(); err != nil {
+			return nil, err
+		}
+		strategy := NewCurveStrategy(c.curve)
+		if result := strategy.TryRelationship(ctx, signatures, c.pairs, publicKey, big.NewInt(a), big.NewInt(b)); result != nil {
+			result.Pattern = fmt.Sprintf("known_a%d_b%d", a, b)
+			return result, nil
 		}
+	} else if result := c.searchRelationship(signatures, publicKey, big.NewInt(a), big.NewInt(b)); result != nil {
+		result.Pattern = fmt.Sprintf("known_a%d_b%d", a, b)
+		c.corroborate(result, signatures)
+		return result, nil
 	}
 
-	// Try all signature pairs
-	aBig := big.NewInt(a)
-	bBig := big.NewInt(b)
+	return nil, fmt.Errorf("failed to recover private key with known relationship a=%d, b=%d", a, b)
+}
 
-	for i := 0; i < len(signatures); i++ {
-		for j := i + 1; j < len(signatures); j++ {
-			priv, err := RecoverPrivateKey(signatures[i], signatures[j], aBig, bBig)
-			if err != nil {
-				continue
+// searchRelationship tries k2 = a*k1 + b on the pairs set with WithPairs (all pairs if
+// none), skipping pairs by different signers, and returns the first key that verifies
+// against publicKey or the pair's signer key (unverified if there is neither), or nil.
+// The result's Pattern is left for the caller to set.
+func (c *Client) searchRelationship(signatures []*Signature, publicKey []byte, a, b *big.Int) *RecoveryResult {
+	pairs := c.pairs
+	if len(pairs) == 0 {
+		for i := 0; i < len(signatures); i++ {
+			for j := i + 1; j < len(signatures); j++ {
+				pairs = append(pairs, [2]int{i, j})
 			}
+		}
+	}
+
+	// Only signatures by the same signer can share a nonce relationship
+	groups := GroupByPublicKey(signatures)
+	signer := make([]*SignatureGroup, len(signatures))
+	for _, group := range groups {
+		for _, idx := range group.Indices {
+			signer[idx] = group
+		}
+	}
+
+	// Try the signature pairs
+	for _, pair := range pairs {
+		i, j := pair[0], pair[1]
+		if signer[i] != signer[j] {
+			continue
+		}
+		pairKey := publicKey
+		if len(pairKey) == 0 {
+			pairKey = signer[i].PublicKey
+		} else if len(groups) > 1 && !sameSigner(signer[i].PublicKey, publicKey) {
+			continue
+		}
+
+		priv, err := RecoverPrivateKey(signatures[i], signatures[j], a, b)
+		if err != nil {
+			continue
+		}
 
-			// Verify recovered key against public key (required for real-world use)
-			verified := false
-			if len(publicKey) > 0 {
-				verified, _ = VerifyRecoveredKey(priv, publicKey)
-				if !verified {
-					continue
-				}
-			} else {
-				// No public key provided - cannot verify in real-world scenario
-				// Set verified to false since we cannot confirm the key is correct
-				verified = false
+		// Verify recovered key against public key (required for real-world use)
+		verified := false
+		if len(pairKey) > 0 {
+			verified, _ = VerifyRecoveredKey(priv, pairKey)
+			if !verified {
+				continue
+			}
+		} else {
+			// No public key provided - cannot verify in real-world scenario
+			// Set verified to false since we cannot confirm the key is correct
+			verified = false
+			if !explainsPair(signatures, i, j, priv) {
+				continue
 			}
+		}
 
-			return &RecoveryResult{
-				PrivateKey:    priv,
-				Relationship:  AffineRelationship{A: aBig, B: bBig},
-				SignaturePair: [2]int{i, j},
-				Verified:      verified,
-				Pattern:       fmt.Sprintf("known_a%d_b%d", a, b),
-			}, nil
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: a, B: b},
+			SignaturePair: [2]int{i, j},
+			Verified:      verified,
 		}
 	}
 
-	return nil, fmt.Errorf("failed to recover private key with known relationship a=%d, b=%d", a, b)
+	return nil
+}
+
+// RecoverKeyWithKnownNonce recovers a private key from one signature whose nonce k is
+// known outright (see RecoverPrivateKeyFromKnownNonce).
+//
+// Args:
+//   - source: Path or URL of signature file
+//   - index: Index of the signature the nonce belongs to
+//   - k: The signature's nonce
+//   - publicKeyHex: Optional public key for verification (defaults to the signature's signer key)
+//
+// Returns:
+//   - RecoveryResult (SignaturePair holds index twice) if successful, error otherwise
+func (c *Client) RecoverKeyWithKnownNonce(ctx context.Context, source string, index int, k *big.Int, publicKeyHex string) (*RecoveryResult, error) {
+	signatures, err := c.parser.ParseSignatures(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	if index < 0 || index >= len(signatures) {
+		return nil, fmt.Errorf("signature %d out of range for %d signatures", index, len(signatures))
+	}
+	sig := signatures[index]
+
+	publicKey := sig.PublicKey
+	if publicKeyHex != "" {
+		publicKey, err = c.parseTarget(publicKeyHex)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.curve != nil {
+		if publicKeyHex == "" {
+			publicKey = nil // the dataset's keys are secp256k1 context
+		}
+		return c.recoverOnCurveFromNonce(signatures, index, k, publicKey)
+	}
+
+	priv, err := RecoverPrivateKeyFromKnownNonce(sig, k)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := false
+	if len(publicKey) > 0 {
+		verified, _ = VerifyRecoveredKey(priv, publicKey)
+		if !verified {
+			return nil, fmt.Errorf("key recovered from the nonce of signature %d does not match the public key", index)
+		}
+	} else if !NonceMatchesR(sig, priv) {
+		return nil, fmt.Errorf("nonce does not produce the r of signature %d", index)
+	}
+
+	return &RecoveryResult{
+		PrivateKey:    priv,
+		Relationship:  AffineRelationship{A: big.NewInt(1), B: big.NewInt(0)},
+		SignaturePair: [2]int{index, index},
+		Verified:      verified,
+		Pattern:       "known_nonce",
+	}, nil
+}
+
+// checkPairs validates the pairs set with WithPairs against a dataset of n signatures.
+func (c *Client) checkPairs(n int) error {
+	for _, pair := range c.pairs {
+		if pair[0] < 0 || pair[0] >= n || pair[1] < 0 || pair[1] >= n {
+			return fmt.Errorf("signature pair %d:%d out of range for %d signatures", pair[0], pair[1], n)
+		}
+		if pair[0] == pair[1] {
+			return fmt.Errorf("signature pair %d:%d pairs a signature with itself", pair[0], pair[1])
+		}
+	}
+	return nil
+}
+
+// searchPairs runs the strategy on each pair set with WithPairs in turn, as a dataset of
+// its two signatures, and returns the first recovered key.
+func (c *Client) searchPairs(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	for _, pair := range c.pairs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if result := c.searchPair(ctx, signatures, pair[0], pair[1], publicKey); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// searchPair runs the strategy on signatures i and j alone. The result's SignaturePair
+// indexes signatures.
+func (c *Client) searchPair(ctx context.Context, signatures []*Signature, i, j int, publicKey []byte) *RecoveryResult {
+	group := &SignatureGroup{
+		Signatures: []*Signature{signatures[i], signatures[j]},
+		Indices:    []int{i, j},
+	}
+	return group.remap(c.searchByKey(ctx, group.Signatures, publicKey))
 }
@@ -2,9 +2,16 @@ package ecdsaaffine
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
 	"math/big"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
 func TestClient_RecoverKeyWithKnownRelationship(t *testing.T) {
@@ -181,3 +188,136 @@ func TestCommonPatterns(t *testing.T) {
 		t.Error("CommonPatterns() should return a copy, not shared slice")
 	}
 }
+
+func TestClient_WithPairs(t *testing.T) {
+	d := big.NewInt(0xfeed)
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(5000), HashMessage([]byte("message 0"))),
+		signWithNonce(d, big.NewInt(90001), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(90002), HashMessage([]byte("message 2"))),
+		signWithNonce(d, big.NewInt(5002), HashMessage([]byte("message 3"))),
+	}
+	publicKeyHex := hex.EncodeToString(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	ctx := context.Background()
+
+	// Without pairs the counter pair (1, 2) is found first
+	result, err := NewClient().RecoverKeyFromSignatures(ctx, signatures, publicKeyHex)
+	if err != nil || result.SignaturePair != [2]int{1, 2} {
+		t.Fatalf("Expected the key from pair (1, 2), got %+v, %v", result, err)
+	}
+
+	// Pairs are searched in the order given, in the given direction, by dataset index
+	result, err = NewClient().WithPairs([2]int{3, 0}).RecoverKeyFromSignatures(ctx, signatures, publicKeyHex)
+	if err != nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the key from pair (3, 0), got %+v, %v", result, err)
+	}
+	if result.SignaturePair != [2]int{3, 0} || result.Relationship.B.Int64() != -2 {
+		t.Errorf("Expected k0 = k3 - 2 in pair (3, 0), got %+v", result)
+	}
+
+	// Pairs also limit a known relationship
+	var records []string
+	for _, sig := range signatures {
+		records = append(records, fmt.Sprintf(`{"z": "0x%064x", "r": "0x%064x", "s": "0x%064x"}`, sig.Z, sig.R, sig.S))
+	}
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, []byte("["+strings.Join(records, ",")+"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient().WithPairs([2]int{0, 3}).RecoverKeyWithKnownRelationship(ctx, path, 1, 1, publicKeyHex); err == nil {
+		t.Error("Expected no key from pair (0, 3) with b = 1")
+	}
+	result, err = NewClient().WithPairs([2]int{0, 3}).RecoverKeyWithKnownRelationship(ctx, path, 1, 2, publicKeyHex)
+	if err != nil || result.SignaturePair != [2]int{0, 3} {
+		t.Errorf("Expected the key from pair (0, 3) with b = 2, got %+v, %v", result, err)
+	}
+
+	for _, pair := range [][2]int{{0, 4}, {-1, 2}, {2, 2}} {
+		if _, err := NewClient().WithPairs(pair).RecoverKeyFromSignatures(ctx, signatures, publicKeyHex); err == nil {
+			t.Errorf("Expected an error for pair %v", pair)
+		}
+	}
+}
+
+func TestClient_RecoverKeyWithKnownNonce(t *testing.T) {
+	d := big.NewInt(0xbeef)
+	nonces := []*big.Int{big.NewInt(1234567), big.NewInt(7654321)}
+	var records []string
+	for i, k := range nonces {
+		sig := signWithNonce(d, k, HashMessage([]byte(fmt.Sprintf("message %d", i))))
+		records = append(records, fmt.Sprintf(`{"z": "0x%064x", "r": "0x%064x", "s": "0x%064x"}`, sig.Z, sig.R, sig.S))
+	}
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, []byte("["+strings.Join(records, ",")+"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	publicKeyHex := hex.EncodeToString(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	ctx := context.Background()
+
+	result, err := NewClient().RecoverKeyWithKnownNonce(ctx, path, 1, nonces[1], publicKeyHex)
+	if err != nil || result.PrivateKey.Cmp(d) != 0 || !result.Verified {
+		t.Fatalf("Expected the verified key, got %+v, %v", result, err)
+	}
+	if result.SignaturePair != [2]int{1, 1} || result.Pattern != "known_nonce" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// The nonce of another signature, an index out of range and a different public key fail
+	otherKey := hex.EncodeToString(secp256k1.PrivKeyFromBytes([]byte{1}).PubKey().SerializeCompressed())
+	for _, tt := range []struct {
+		index     int
+		publicKey string
+	}{{0, publicKeyHex}, {2, publicKeyHex}, {1, otherKey}} {
+		if _, err := NewClient().RecoverKeyWithKnownNonce(ctx, path, tt.index, nonces[1], tt.publicKey); err == nil {
+			t.Errorf("Expected an error for signature %d with key %s", tt.index, tt.publicKey)
+		}
+	}
+}
+
+func TestClient_Options(t *testing.T) {
+	hash := func(message []byte) *big.Int {
+		return HashMessage(append([]byte("prefixed: "), message...))
+	}
+	d := big.NewInt(0xc0ffee)
+	k := big.NewInt(777777)
+	var records []string
+	for i, nonce := range []*big.Int{k, new(big.Int).Add(k, big.NewInt(1))} {
+		message := fmt.Sprintf("message %d", i)
+		sig := signWithNonce(d, nonce, hash([]byte(message)))
+		records = append(records, fmt.Sprintf(`{"message": %q, "r": "0x%064x", "s": "0x%064x"}`, message, sig.R, sig.S))
+	}
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	if err := os.WriteFile(path, []byte("["+strings.Join(records, ",")+"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	publicKeyHex := hex.EncodeToString(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	ctx := context.Background()
+
+	// Messages are hashed with the default hasher unless one is set
+	if _, err := NewClient().RecoverKeyWithKnownRelationship(ctx, path, 1, 1, publicKeyHex); err == nil {
+		t.Error("Expected no key with the default hasher")
+	}
+
+	var logs strings.Builder
+	logger := log.New(&logs, "", 0)
+	client := NewClient(WithHasher(hash), WithLogger(logger))
+	result, err := client.RecoverKey(ctx, path, publicKeyHex)
+	if err != nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the key with the custom hasher, got %+v, %v", result, err)
+	}
+	if !strings.Contains(logs.String(), "Phase 0") {
+		t.Errorf("Expected the search to log to the client's logger, got %q", logs.String())
+	}
+
+	// Settings carry over to a strategy set later, as option or method
+	bsgs := NewBSGSStrategy()
+	client.WithStrategy(bsgs)
+	if bsgs.Logger != logger {
+		t.Error("Expected the logger to be applied to a strategy set later")
+	}
+	smart := NewSmartBruteForceStrategy()
+	NewClient(WithVerification(ReferenceVerification), WithStrategy(smart))
+	if smart.RangeConfig.Verification != ReferenceVerification {
+		t.Error("Expected the verification backend to be applied regardless of option order")
+	}
+}
@@ -0,0 +1,146 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
+)
+
+// Remediation verdicts reported by CompareDatasets.
+const (
+	RemediationFixed        = "fixed"        // no weak nonce after the fix
+	RemediationVulnerable   = "vulnerable"   // the flaw is still present after the fix
+	RemediationInconclusive = "inconclusive" // no flaw found before the fix to compare with
+)
+
+// DatasetComparison tells whether a nonce flaw in signatures made before a fix is gone
+// from signatures made after it by the same key. See Client.CompareDatasets.
+type DatasetComparison struct {
+	Before *DatasetAnalysis `json:"before"`
+	After  *DatasetAnalysis `json:"after"`
+
+	// BeforeResult is the key recovered from the signatures before the fix, nil if none
+	BeforeResult *RecoveryResult `json:"-"`
+
+	// AfterResult is a key recovered from the signatures after the fix alone, nil if
+	// none was (only searched when nothing was recovered before the fix)
+	AfterResult *RecoveryResult `json:"-"`
+
+	// WeakAfter lists the signatures after the fix (indices into that set) whose nonces,
+	// computed with the key recovered before it, are weak (see nonceanalysis.Scope),
+	// including nonces linked to one from before the fix
+	WeakAfter []int `json:"weak_after"`
+
+	Verdict string `json:"verdict"` // one of the Remediation constants
+	Reason  string `json:"reason"`
+}
+
+// CompareDatasets checks a fix for a nonce flaw, given signatures by the same key made
+// before and after it. It compares the two sets statistically (AnalyzeDataset) and runs
+// the client's strategy on the signatures from before the fix. With the key recovered,
+// every nonce is known: the fix holds if no nonce after it is weak, whether related to
+// another nonce after the fix or continuing one from before. Without a key from before
+// the fix, the signatures after it are searched on their own; a key found there means
+// the fix did not hold, and otherwise the comparison is inconclusive.
+//
+// publicKeyHex is optional, as for RecoverKeyFromSignatures, but without it (or signer
+// keys in the signatures) the strategy returns the first unverified candidate, which is
+// rarely the key; such a candidate is dropped unless it reproduces every signature's r.
+func (c *Client) CompareDatasets(ctx context.Context, before, after []*Signature, publicKeyHex string) (*DatasetComparison, error) {
+	if len(before) == 0 || len(after) == 0 {
+		return nil, fmt.Errorf("need signatures from before and after the fix, got %d and %d", len(before), len(after))
+	}
+	comparison := &DatasetComparison{
+		Before:  AnalyzeDataset(before),
+		After:   AnalyzeDataset(after),
+		Verdict: RemediationInconclusive,
+	}
+
+	var err error
+	comparison.BeforeResult, err = c.recoverSignerKey(ctx, before, publicKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if comparison.BeforeResult == nil {
+		comparison.AfterResult, err = c.recoverSignerKey(ctx, after, publicKeyHex)
+		if err != nil {
+			return nil, err
+		}
+		if comparison.AfterResult != nil {
+			comparison.Verdict = RemediationVulnerable
+			comparison.Reason = fmt.Sprintf("key recovered from signatures after the fix (%s)", comparison.AfterResult.Pattern)
+		} else {
+			comparison.Reason = "no key recovered before or after the fix"
+		}
+		return comparison, nil
+	}
+
+	key := comparison.BeforeResult.PrivateKey
+	all := append(append([]*Signature{}, before...), after...)
+	if i := notSignedBy(after, key); i >= 0 {
+		return nil, fmt.Errorf("signature %d after the fix was not made by the key recovered before it", i)
+	}
+	nonces, err := RecoverNonces(comparison.BeforeResult, all)
+	if err != nil {
+		return nil, err
+	}
+	exposure := nonceanalysis.Scope(nonces, nil, Secp256k1CurveOrder, nonceanalysis.DefaultOptions())
+	for _, f := range exposure.Findings[len(before):] {
+		if f.Weak {
+			comparison.WeakAfter = append(comparison.WeakAfter, f.Index-len(before))
+		}
+	}
+
+	if len(comparison.WeakAfter) > 0 {
+		comparison.Verdict = RemediationVulnerable
+		comparison.Reason = fmt.Sprintf("%d of %d signatures after the fix have weak nonces", len(comparison.WeakAfter), len(after))
+	} else {
+		comparison.Verdict = RemediationFixed
+		comparison.Reason = fmt.Sprintf("key recovered before the fix (%s); no nonce after it is weak", comparison.BeforeResult.Pattern)
+	}
+	return comparison, nil
+}
+
+// recoverSignerKey runs the client's strategy on signatures by one key. An unverified
+// candidate that does not reproduce every signature's r is not that key and is dropped.
+func (c *Client) recoverSignerKey(ctx context.Context, signatures []*Signature, publicKeyHex string) (*RecoveryResult, error) {
+	result, _ := c.RecoverKeyFromSignatures(ctx, signatures, publicKeyHex)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if result != nil && !result.Verified && notSignedBy(signatures, result.PrivateKey) >= 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// notSignedBy returns the index of the first signature key did not make, or -1.
+func notSignedBy(signatures []*Signature, key *big.Int) int {
+	for i, sig := range signatures {
+		if !NonceMatchesR(sig, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteText renders the comparison as a human-readable report.
+func (c *DatasetComparison) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Remediation check: %s\n", c.Verdict)
+	fmt.Fprintf(&sb, "  %s\n\n", c.Reason)
+	fmt.Fprintf(&sb, "                    %10s %10s\n", "before", "after")
+	fmt.Fprintf(&sb, "  Signatures        %10d %10d\n", c.Before.Signatures, c.After.Signatures)
+	fmt.Fprintf(&sb, "  Repeated r        %10d %10d\n", c.Before.DuplicateR, c.After.DuplicateR)
+	fmt.Fprintf(&sb, "  Nonce source      %10s %10s\n", c.Before.NonceSource, c.After.NonceSource)
+	if len(c.WeakAfter) > 0 {
+		fmt.Fprintf(&sb, "\n  Weak signatures after the fix: %v\n", c.WeakAfter)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
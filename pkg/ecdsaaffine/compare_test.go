@@ -0,0 +1,70 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestClient_CompareDatasets(t *testing.T) {
+	d := big.NewInt(0x5eed)
+	publicKeyHex := hex.EncodeToString(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	base, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	random := []*big.Int{
+		new(big.Int).SetBytes([]byte("a nonce from a proper generator!")),
+		new(big.Int).SetBytes([]byte("another nonce, also unpredictabl")),
+		new(big.Int).SetBytes([]byte("and a third one that is unrelate")),
+	}
+	sign := func(prefix string, nonces ...*big.Int) []*Signature {
+		var signatures []*Signature
+		for i, k := range nonces {
+			signatures = append(signatures, signWithNonce(d, k, HashMessage([]byte(fmt.Sprintf("%s %d", prefix, i)))))
+		}
+		return signatures
+	}
+	counter := func(start int64, count int) []*big.Int {
+		var nonces []*big.Int
+		for i := int64(0); i < int64(count); i++ {
+			nonces = append(nonces, new(big.Int).Add(base, big.NewInt(start+i)))
+		}
+		return nonces
+	}
+	quiet := WithLogger(log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		client        *Client
+		before, after []*Signature
+		verdict       string
+		weakAfter     int
+	}{
+		{"fixed", NewClient(quiet), sign("before", counter(0, 3)...), sign("after", random...), RemediationFixed, 0},
+		{"counter continues", NewClient(quiet), sign("before", counter(0, 3)...), sign("after", append(counter(3, 1), random[:2]...)...), RemediationVulnerable, 1},
+		{"flaw only after", NewClient(quiet, WithStrategy(NewPatternStrategy())), sign("before", random...), sign("after", counter(0, 2)...), RemediationVulnerable, 0},
+		{"no flaw", NewClient(quiet, WithStrategy(NewPatternStrategy())), sign("before", random[:2]...), sign("after", random[2:]...), RemediationInconclusive, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison, err := tt.client.CompareDatasets(ctx, tt.before, tt.after, publicKeyHex)
+			if err != nil {
+				t.Fatalf("CompareDatasets: %v", err)
+			}
+			if comparison.Verdict != tt.verdict || len(comparison.WeakAfter) != tt.weakAfter {
+				t.Errorf("Expected %s with %d weak signatures, got %+v", tt.verdict, tt.weakAfter, comparison)
+			}
+		})
+	}
+
+	// Signatures after the fix by another key are an error once the key is known
+	other := signWithNonce(big.NewInt(0xbad), random[0], HashMessage([]byte("other")))
+	if _, err := NewClient(quiet).CompareDatasets(ctx, sign("before", counter(0, 2)...), []*Signature{other}, publicKeyHex); err == nil {
+		t.Error("Expected an error for signatures by another key")
+	}
+}
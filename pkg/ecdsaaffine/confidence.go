@@ -0,0 +1,132 @@
+package ecdsaaffine
+
+import "math/big"
+
+// maxConfidenceChecks caps the signatures ScoreResult checks beyond the result's pair;
+// each check is a scalar multiplication.
+const maxConfidenceChecks = 1000
+
+// Confidence says how far a recovered key is borne out by the signatures it came from.
+type Confidence struct {
+	// Score is 1 for a key verified against a public key. Otherwise it is the share of
+	// the result's pair of signatures whose r the key reproduces: 1 for the signer's key,
+	// 0 for a coincidental algebraic hit of a wrong relationship (any pair gives some key
+	// for any (a, b)).
+	Score float64 `json:"score"`
+
+	PairExplained int `json:"pair_explained"` // signatures of the pair the key reproduces (0 to 2)
+	Explained     int `json:"explained"`      // signatures checked whose r the key reproduces
+	Checked       int `json:"checked"`        // signatures checked, the pair first
+
+	// PatternPairs counts the consecutive explained signatures whose nonces also satisfy
+	// the result's relationship, i.e. other records with the same pattern
+	PatternPairs int `json:"pattern_pairs"`
+}
+
+// ScoreResult checks result's key against signatures: a key explains a signature when the
+// nonce (z + r*d)/s it implies reproduces r, which holds for at most two keys per
+// signature. Without a public key this is the only evidence that a result is the signer's
+// key rather than the solution of a relationship the nonces do not have.
+func ScoreResult(result *RecoveryResult, signatures []*Signature) Confidence {
+	var c Confidence
+	if result == nil || result.PrivateKey == nil {
+		return c
+	}
+
+	order := make([]int, 0, len(signatures))
+	for _, i := range result.SignaturePair {
+		if i >= 0 && i < len(signatures) && (len(order) == 0 || order[0] != i) {
+			order = append(order, i)
+		}
+	}
+	pair := len(order)
+	for i := range signatures {
+		if len(order) >= pair+maxConfidenceChecks {
+			break
+		}
+		if i != result.SignaturePair[0] && i != result.SignaturePair[1] {
+			order = append(order, i)
+		}
+	}
+
+	nonces := make([]*big.Int, len(signatures))
+	for n, i := range order {
+		c.Checked++
+		k, err := RecoverNonce(signatures[i], result.PrivateKey)
+		if err != nil || k.Sign() == 0 || !nonceProducesR(signatures[i], k) {
+			continue
+		}
+		nonces[i] = k
+		c.Explained++
+		if n < pair {
+			c.PairExplained++
+		}
+	}
+
+	if a, b := result.Relationship.A, result.Relationship.B; a != nil && b != nil {
+		for i := 0; i+1 < len(nonces); i++ {
+			if nonces[i] == nil || nonces[i+1] == nil {
+				continue
+			}
+			next := new(big.Int).Mul(a, nonces[i])
+			next.Add(next, b)
+			next.Mod(next, Secp256k1CurveOrder)
+			if next.Cmp(nonces[i+1]) == 0 {
+				c.PatternPairs++
+			}
+		}
+	}
+
+	switch {
+	case result.Verified:
+		c.Score = 1
+	case pair > 0:
+		c.Score = float64(c.PairExplained) / float64(pair)
+	}
+	return c
+}
+
+// explainsPair reports whether key reproduces the r of both signatures i and j. Strategies
+// require it of keys they cannot verify against a public key.
+func explainsPair(signatures []*Signature, i, j int, key *big.Int) bool {
+	return NonceMatchesR(signatures[i], key) && NonceMatchesR(signatures[j], key)
+}
+
+// corroborate runs the WithCrossCheck check, if set, on the signatures by the signer of
+// result's pair.
+func (c *Client) corroborate(result *RecoveryResult, signatures []*Signature) {
+	if c.crossCheck == nil {
+		return
+	}
+	groups := GroupByPublicKey(signatures)
+	signer := signerIndex(groups, len(signatures))
+	first := result.SignaturePair[0]
+	if first < 0 || first >= len(signatures) {
+		return
+	}
+	var indices []int
+	for i := range signatures {
+		if signer[i] == signer[first] {
+			indices = append(indices, i)
+		}
+	}
+	crossCheck(result, signatures, indices, *c.crossCheck)
+}
+
+// crossCheck sets result's Corroborating and CrossChecked from up to limit (0: all) of
+// the signatures at indices other than result's pair.
+func crossCheck(result *RecoveryResult, signatures []*Signature, indices []int, limit int) {
+	result.Corroborating, result.CrossChecked = 0, 0
+	for _, i := range indices {
+		if limit > 0 && result.CrossChecked >= limit {
+			break
+		}
+		if i == result.SignaturePair[0] || i == result.SignaturePair[1] {
+			continue
+		}
+		result.CrossChecked++
+		if NonceMatchesR(signatures[i], result.PrivateKey) {
+			result.Corroborating++
+		}
+	}
+}
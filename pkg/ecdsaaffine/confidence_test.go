@@ -0,0 +1,99 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestScoreResult(t *testing.T) {
+	d := big.NewInt(0x5eed1893)
+	k := big.NewInt(987654321)
+	var signatures []*Signature
+	for i := 0; i < 4; i++ {
+		signatures = append(signatures, signWithNonce(d, new(big.Int).Add(k, big.NewInt(int64(i))), HashMessage([]byte{byte(i)})))
+	}
+
+	one := big.NewInt(1)
+	real := &RecoveryResult{PrivateKey: d, Relationship: AffineRelationship{A: one, B: one}, SignaturePair: [2]int{0, 1}}
+	c := ScoreResult(real, signatures)
+	if c.Score != 1 || c.PairExplained != 2 || c.Explained != 4 || c.Checked != 4 || c.PatternPairs != 3 {
+		t.Errorf("Signer key: got %+v", c)
+	}
+
+	// Any (a, b) gives a key for any pair; a wrong one explains nothing
+	wrong, err := RecoverPrivateKey(signatures[0], signatures[1], big.NewInt(2), big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = ScoreResult(&RecoveryResult{PrivateKey: wrong, Relationship: AffineRelationship{A: big.NewInt(2), B: big.NewInt(7)}, SignaturePair: [2]int{0, 1}}, signatures)
+	if c.Score != 0 || c.Explained != 0 || c.PatternPairs != 0 {
+		t.Errorf("Coincidental key: got %+v", c)
+	}
+
+	if c := ScoreResult(&RecoveryResult{PrivateKey: wrong, Verified: true}, signatures); c.Score != 1 {
+		t.Errorf("A verified result scores 1, got %v", c.Score)
+	}
+}
+
+// TestClient_RecoverKeyFromSignatures_NoPublicKey checks that without any public key the
+// strategy skips the keys of wrong patterns instead of returning the first one.
+func TestClient_RecoverKeyFromSignatures_NoPublicKey(t *testing.T) {
+	d := big.NewInt(0x1893)
+	k := big.NewInt(123456789)
+	var signatures []*Signature
+	for i := 0; i < 3; i++ {
+		// k, k+97, ... (a common pattern tried after the simpler ones)
+		signatures = append(signatures, signWithNonce(d, new(big.Int).Add(k, big.NewInt(int64(97*i))), HashMessage([]byte{byte(i)})))
+	}
+
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verified || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the unverified signer key %s, got %s (verified=%v)", d, result.PrivateKey, result.Verified)
+	}
+	if c := ScoreResult(result, signatures); c.Score != 1 || c.PatternPairs != 2 {
+		t.Errorf("Expected full confidence, got %+v", c)
+	}
+}
+
+func TestClient_WithCrossCheck(t *testing.T) {
+	d := big.NewInt(0x1894)
+	k := big.NewInt(555555)
+	var signatures []*Signature
+	for i := 0; i < 5; i++ {
+		signatures = append(signatures, signWithNonce(d, new(big.Int).Add(k, big.NewInt(int64(i))), HashMessage([]byte{byte(i)})))
+	}
+	// A record of another key mixed into the dataset
+	signatures = append(signatures, signWithNonce(big.NewInt(77), big.NewInt(31337), HashMessage([]byte("other"))))
+	publicKey := hex.EncodeToString(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CrossChecked != 0 || result.Corroborating != 0 {
+		t.Errorf("Expected no cross-check by default, got %d of %d", result.Corroborating, result.CrossChecked)
+	}
+
+	result, err = NewClient(WithCrossCheck(0)).RecoverKeyFromSignatures(context.Background(), signatures, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified || result.CrossChecked != 4 || result.Corroborating != 3 {
+		t.Errorf("Expected 3 of 4 other signatures corroborating, got %d of %d (verified=%v)", result.Corroborating, result.CrossChecked, result.Verified)
+	}
+
+	result, err = NewClient(WithCrossCheck(2)).RecoverKeyFromSignatures(context.Background(), signatures, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CrossChecked != 2 || result.Corroborating != 2 {
+		t.Errorf("Expected 2 of 2 with a limit of 2, got %d of %d", result.Corroborating, result.CrossChecked)
+	}
+}
@@ -0,0 +1,494 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+)
+
+// curveExplainChecks caps the signatures a candidate key must explain on a curve with a
+// generator and no public key; on a toy curve of order n a wrong key explains each with
+// probability about 2/n.
+const curveExplainChecks = 8
+
+// Curve is a group other than secp256k1 to run the recovery math in, such as the toy
+// curves with small orders used to teach the attack and in CTFs. Only the order is
+// required; the generator and Verify decide how candidate keys are checked.
+//
+//	// y² = x³ + 2x + 2 over F_17, generator (5, 1) of order 19
+//	curve := &ecdsaaffine.Curve{
+//		Name: "toy", N: big.NewInt(19),
+//		P: big.NewInt(17), A: big.NewInt(2), B: big.NewInt(2),
+//		Gx: big.NewInt(5), Gy: big.NewInt(1),
+//	}
+//	client := ecdsaaffine.NewClient(ecdsaaffine.WithCurve(curve))
+type Curve struct {
+	Name string
+
+	// N is the order of the group the nonces and keys live in
+	N *big.Int
+
+	// P, A, B, Gx and Gy, if set, define the short Weierstrass curve y² = x³ + A·x + B
+	// over F_P and its generator G, of order N. With them a candidate key must reproduce
+	// the r values of the signatures, and match the public key (d·G as 04 || X || Y or
+	// compressed) if there is one. Without them there is no point arithmetic, and a
+	// candidate is accepted when the same relationship gives the same key for the next
+	// signature too, as with counter nonces.
+	P, A, B, Gx, Gy *big.Int
+
+	// Verify, if set, decides whether a candidate key is the signer's, given the public
+	// key the search was given (nil if none), instead of the checks above. Keys it
+	// accepts are reported as verified.
+	Verify func(privateKey *big.Int, publicKey []byte) bool
+
+	// baseMult, if set, is a faster k·G for 0 < k < N than the generic affine
+	// arithmetic, as the named curves have (see CurveByName)
+	baseMult func(k *big.Int) (x, y *big.Int)
+}
+
+// Validate checks that the curve has an order and, if it has a generator, that the
+// generator is on the curve and has order N.
+func (c *Curve) Validate() error {
+	if c.N == nil || c.N.Cmp(big.NewInt(2)) < 0 {
+		return errors.New("curve order must be at least 2")
+	}
+	set := 0
+	for _, v := range []*big.Int{c.P, c.A, c.B, c.Gx, c.Gy} {
+		if v != nil {
+			set++
+		}
+	}
+	if set == 0 {
+		return nil
+	}
+	if set != 5 {
+		return errors.New("curve needs all of P, A, B, Gx and Gy, or none")
+	}
+	if c.P.Cmp(big.NewInt(3)) < 0 || !c.P.ProbablyPrime(20) {
+		return fmt.Errorf("curve field size %s is not an odd prime", c.P)
+	}
+	if !c.onCurve(c.Gx, c.Gy) {
+		return fmt.Errorf("generator (%s, %s) is not on the curve", c.Gx, c.Gy)
+	}
+	if x, _ := c.ScalarBaseMult(c.N); x != nil {
+		return fmt.Errorf("generator does not have order %s", c.N)
+	}
+	return nil
+}
+
+// HasGenerator reports whether the curve has point arithmetic (P, A, B, Gx and Gy).
+func (c *Curve) HasGenerator() bool {
+	return c.P != nil && c.Gx != nil && c.Gy != nil
+}
+
+// name returns the curve's name for messages.
+func (c *Curve) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("order %s", c.N)
+}
+
+// onCurve reports whether (x, y) satisfies the curve equation.
+func (c *Curve) onCurve(x, y *big.Int) bool {
+	lhs := new(big.Int).Mul(y, y)
+	rhs := new(big.Int).Mul(x, x)
+	rhs.Add(rhs, c.A).Mul(rhs, x).Add(rhs, c.B)
+	return lhs.Sub(lhs, rhs).Mod(lhs, c.P).Sign() == 0
+}
+
+// add returns (x1, y1) + (x2, y2) in affine coordinates; nil x is the point at infinity.
+func (c *Curve) add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	}
+	if x2 == nil {
+		return x1, y1
+	}
+	p := c.P
+	var lambda *big.Int
+	if x1.Cmp(x2) == 0 {
+		sum := new(big.Int).Add(y1, y2)
+		if sum.Mod(sum, p).Sign() == 0 {
+			return nil, nil
+		}
+		// λ = (3x² + A) / 2y
+		num := new(big.Int).Mul(x1, x1)
+		num.Mul(num, big.NewInt(3)).Add(num, c.A)
+		den := new(big.Int).Lsh(y1, 1)
+		lambda = num.Mul(num, new(big.Int).ModInverse(den.Mod(den, p), p))
+	} else {
+		// λ = (y2 - y1) / (x2 - x1)
+		num := new(big.Int).Sub(y2, y1)
+		den := new(big.Int).Sub(x2, x1)
+		lambda = num.Mul(num, new(big.Int).ModInverse(den.Mod(den, p), p))
+	}
+	lambda.Mod(lambda, p)
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, p)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda).Sub(y3, y1).Mod(y3, p)
+	return x3, y3
+}
+
+// ScalarBaseMult returns k·G (k reduced mod N) in affine coordinates, or nil for the
+// point at infinity. The curve must have a generator.
+func (c *Curve) ScalarBaseMult(k *big.Int) (x, y *big.Int) {
+	k = new(big.Int).Mod(k, c.N)
+	if k.Sign() == 0 {
+		// N itself, for Validate
+		k = new(big.Int).Set(c.N)
+	} else if c.baseMult != nil {
+		return c.baseMult(k)
+	}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		x, y = c.add(x, y, x, y)
+		if k.Bit(i) == 1 {
+			x, y = c.add(x, y, c.Gx, c.Gy)
+		}
+	}
+	return x, y
+}
+
+// PublicKey returns d·G as 04 || X || Y, each coordinate padded to the length of P. The
+// curve must have a generator.
+func (c *Curve) PublicKey(d *big.Int) []byte {
+	x, y := c.ScalarBaseMult(d)
+	if x == nil {
+		return nil
+	}
+	size := (c.P.BitLen() + 7) / 8
+	key := make([]byte, 1+2*size)
+	key[0] = 0x04
+	x.FillBytes(key[1 : 1+size])
+	y.FillBytes(key[1+size:])
+	return key
+}
+
+// Sign signs z with key d and nonce k: r = (k·G).x mod N, s = k⁻¹(z + r·d) mod N. Use it
+// to make datasets with chosen nonces on the curve. The curve must have a generator.
+func (c *Curve) Sign(d, k, z *big.Int) (*Signature, error) {
+	if !c.HasGenerator() {
+		return nil, errors.New("signing needs a curve with a generator")
+	}
+	x, _ := c.ScalarBaseMult(k)
+	if x == nil {
+		return nil, errors.New("nonce is zero mod the curve order")
+	}
+	r := new(big.Int).Mod(x, c.N)
+	kInv := new(big.Int).ModInverse(new(big.Int).Mod(k, c.N), c.N)
+	if r.Sign() == 0 || kInv == nil {
+		return nil, errors.New("nonce gives r = 0 or has no inverse; pick another")
+	}
+	s := new(big.Int).Mul(r, d)
+	s.Add(s, z).Mul(s, kInv).Mod(s, c.N)
+	if s.Sign() == 0 {
+		return nil, errors.New("nonce gives s = 0; pick another")
+	}
+	return &Signature{Z: new(big.Int).Set(z), R: r, S: s}, nil
+}
+
+// RecoverPrivateKey is the package's RecoverPrivateKey in the curve's group.
+func (c *Curve) RecoverPrivateKey(sig1, sig2 *Signature, a, b *big.Int) (*big.Int, error) {
+	return recoverPrivateKey(sig1, sig2, a, b, c.N)
+}
+
+// RecoverNonce is the package's RecoverNonce in the curve's group.
+func (c *Curve) RecoverNonce(sig *Signature, privateKey *big.Int) (*big.Int, error) {
+	return recoverNonce(sig, privateKey, c.N)
+}
+
+// RecoverPrivateKeyFromKnownNonce is the package's RecoverPrivateKeyFromKnownNonce in the
+// curve's group. On a curve with a generator the nonce must reproduce the signature's r.
+func (c *Curve) RecoverPrivateKeyFromKnownNonce(sig *Signature, k *big.Int) (*big.Int, error) {
+	nonce := new(big.Int).Mod(k, c.N)
+	if nonce.Sign() == 0 {
+		return nil, errors.New("nonce must be nonzero mod n")
+	}
+	if c.HasGenerator() && !c.nonceProducesR(sig, nonce) {
+		return nil, errors.New("nonce does not reproduce the signature's r")
+	}
+	rInv := new(big.Int).ModInverse(sig.R, c.N)
+	if rInv == nil {
+		return nil, errors.New("failed to compute modular inverse of r")
+	}
+	priv := new(big.Int).Mul(sig.S, nonce)
+	priv.Sub(priv, sig.Z).Mul(priv, rInv).Mod(priv, c.N)
+	if priv.Sign() == 0 {
+		return nil, errors.New("recovered private key is zero")
+	}
+	return priv, nil
+}
+
+// nonceProducesR reports whether (k·G).x mod N is the signature's r.
+func (c *Curve) nonceProducesR(sig *Signature, k *big.Int) bool {
+	x, _ := c.ScalarBaseMult(k)
+	return x != nil && new(big.Int).Mod(x, c.N).Cmp(sig.R) == 0
+}
+
+// explains reports whether key d reproduces the signature's r through the nonce it
+// implies.
+func (c *Curve) explains(sig *Signature, d *big.Int) bool {
+	k, err := c.RecoverNonce(sig, d)
+	return err == nil && k.Sign() != 0 && c.nonceProducesR(sig, k)
+}
+
+// matchesPublicKey reports whether d·G is publicKey, uncompressed or compressed.
+func (c *Curve) matchesPublicKey(d *big.Int, publicKey []byte) bool {
+	key := c.PublicKey(d)
+	if key == nil {
+		return false
+	}
+	if bytes.Equal(key, publicKey) {
+		return true
+	}
+	size := (len(key) - 1) / 2
+	compressed := append([]byte{0x02 | key[len(key)-1]&1}, key[1:1+size]...)
+	return bytes.Equal(compressed, publicKey)
+}
+
+// check decides whether d, recovered from signatures i and j with k_j = a*k_i + b, is
+// the signer's key, and whether that was verified (see Curve). On a small group any two
+// nonces are affinely related, so with a generator the key must also reproduce the r of
+// the pair and of up to curveExplainChecks other signatures, public key or not.
+func (c *Curve) check(d *big.Int, signatures []*Signature, i, j int, a, b *big.Int, publicKey []byte) (ok, verified bool) {
+	if d.Sign() == 0 {
+		return false, false
+	}
+	if c.Verify != nil {
+		ok = c.Verify(d, publicKey)
+		return ok, ok
+	}
+	if !c.HasGenerator() {
+		// The next signature must continue the relationship with the same key
+		if j+1 >= len(signatures) {
+			return false, false
+		}
+		next, err := c.RecoverPrivateKey(signatures[j], signatures[j+1], a, b)
+		return err == nil && next.Cmp(d) == 0, false
+	}
+
+	// The public key, one scalar multiplication, rules out most candidates first
+	if len(publicKey) > 0 && !c.matchesPublicKey(d, publicKey) {
+		return false, false
+	}
+	if !c.explains(signatures[i], d) || !c.explains(signatures[j], d) {
+		return false, false
+	}
+	checked := 0
+	for k := 0; k < len(signatures) && checked < curveExplainChecks; k++ {
+		if k == i || k == j {
+			continue
+		}
+		if !c.explains(signatures[k], d) {
+			return false, false
+		}
+		checked++
+	}
+	return true, len(publicKey) > 0
+}
+
+// CurveStrategy searches for affinely related nonces on a Curve, in plain modular
+// arithmetic: repeated r values, then Patterns, then every (a, b) in ARange × BRange,
+// over the first MaxPairs signature pairs. It is meant for small datasets on toy curves;
+// secp256k1 datasets are searched far faster by SmartBruteForceStrategy.
+type CurveStrategy struct {
+	Curve *Curve
+
+	// Patterns are tried before the range (default: CommonPatterns)
+	Patterns []Pattern
+
+	// ARange and BRange are the a and b searched after the patterns (a = 0 is skipped)
+	ARange, BRange [2]int
+
+	// MaxPairs limits the number of signature pairs to test
+	MaxPairs int
+
+	// Logger, if set, receives the search's progress messages instead of the standard logger
+	Logger *log.Logger
+}
+
+// NewCurveStrategy creates a curve strategy with the common patterns and a, b in
+// [-100, 100].
+func NewCurveStrategy(curve *Curve) *CurveStrategy {
+	return &CurveStrategy{
+		Curve:    curve,
+		Patterns: CommonPatterns(),
+		ARange:   [2]int{-100, 100},
+		BRange:   [2]int{-100, 100},
+		MaxPairs: 100,
+	}
+}
+
+func (s *CurveStrategy) logger() *log.Logger {
+	return loggerOrDefault(s.Logger)
+}
+
+// Name returns the name of this strategy.
+func (s *CurveStrategy) Name() string {
+	return "Curve"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *CurveStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 || s.Curve == nil {
+		return nil
+	}
+	if err := s.Curve.Validate(); err != nil {
+		s.logger().Printf("Curve %s: %v", s.Curve.name(), err)
+		return nil
+	}
+	pairs := s.pairs(len(signatures))
+
+	s.logger().Printf("Searching curve %s: repeated r, %d patterns, then a in [%d, %d], b in [%d, %d] on %d pairs",
+		s.Curve.name(), len(s.Patterns), s.ARange[0], s.ARange[1], s.BRange[0], s.BRange[1], len(pairs))
+	var repeated [][2]int
+	for _, pair := range pairs {
+		if signatures[pair[0]].R.Cmp(signatures[pair[1]].R) == 0 {
+			repeated = append(repeated, pair)
+		}
+	}
+	if len(repeated) > 0 {
+		if result := s.TryRelationship(ctx, signatures, repeated, publicKey, big.NewInt(1), big.NewInt(0)); result != nil {
+			result.Pattern = "same_nonce"
+			return result
+		}
+	}
+	for _, pattern := range s.Patterns {
+		if result := s.TryRelationship(ctx, signatures, pairs, publicKey, pattern.A, pattern.B); result != nil {
+			result.Pattern = pattern.Name
+			return result
+		}
+	}
+	for a := s.ARange[0]; a <= s.ARange[1]; a++ {
+		if a == 0 {
+			continue
+		}
+		for b := s.BRange[0]; b <= s.BRange[1]; b++ {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if result := s.TryRelationship(ctx, signatures, pairs, publicKey, big.NewInt(int64(a)), big.NewInt(int64(b))); result != nil {
+				result.Pattern = fmt.Sprintf("range_a%d_b%d", a, b)
+				return result
+			}
+		}
+	}
+	return nil
+}
+
+// pairs returns the first MaxPairs pairs (i < j) of n signatures.
+func (s *CurveStrategy) pairs(n int) [][2]int {
+	maxPairs := s.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = 100
+	}
+	var pairs [][2]int
+	for i := 0; i < n && len(pairs) < maxPairs; i++ {
+		for j := i + 1; j < n && len(pairs) < maxPairs; j++ {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	return pairs
+}
+
+// TryRelationship tries k_j = a*k_i + b on each of pairs (all pairs if nil) and returns
+// the first key the curve accepts, or nil. The result's Pattern is left for the caller.
+func (s *CurveStrategy) TryRelationship(ctx context.Context, signatures []*Signature, pairs [][2]int, publicKey []byte, a, b *big.Int) *RecoveryResult {
+	if pairs == nil {
+		pairs = s.pairs(len(signatures))
+	}
+	for _, pair := range pairs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		i, j := pair[0], pair[1]
+		d, err := s.Curve.RecoverPrivateKey(signatures[i], signatures[j], a, b)
+		if err != nil {
+			continue
+		}
+		if ok, verified := s.Curve.check(d, signatures, i, j, a, b, publicKey); ok {
+			return &RecoveryResult{
+				PrivateKey:    d,
+				Relationship:  AffineRelationship{A: new(big.Int).Set(a), B: new(big.Int).Set(b)},
+				SignaturePair: [2]int{i, j},
+				Verified:      verified,
+			}
+		}
+	}
+	return nil
+}
+
+// parseTarget parses a public key given to a client method: hex of the curve's point
+// encoding with WithCurve, else anything ParseTarget accepts.
+func (c *Client) parseTarget(publicKeyHex string) ([]byte, error) {
+	if c.curve == nil {
+		return ParseTarget(publicKeyHex, 0, 0)
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key for curve %s: %w", c.curve.name(), err)
+	}
+	return key, nil
+}
+
+// recoverOnCurve is RecoverKeyFromSignatures with WithCurve: the strategy runs on the
+// whole dataset, or on each pair set with WithPairs.
+func (c *Client) recoverOnCurve(ctx context.Context, signatures []*Signature, publicKey []byte) (*RecoveryResult, error) {
+	if err := c.curve.Validate(); err != nil {
+		return nil, err
+	}
+	if len(c.pairs) == 0 {
+		if result := c.strategy.Search(ctx, signatures, publicKey); result != nil {
+			return result, nil
+		}
+		return nil, c.searchFailed()
+	}
+	if err := c.checkPairs(len(signatures)); err != nil {
+		return nil, err
+	}
+	for _, pair := range c.pairs {
+		group := &SignatureGroup{
+			Signatures: []*Signature{signatures[pair[0]], signatures[pair[1]]},
+			Indices:    []int{pair[0], pair[1]},
+		}
+		if result := group.remap(c.strategy.Search(ctx, group.Signatures, publicKey)); result != nil {
+			return result, nil
+		}
+	}
+	return nil, c.searchFailed()
+}
+
+// recoverOnCurveFromNonce is RecoverKeyWithKnownNonce with WithCurve.
+func (c *Client) recoverOnCurveFromNonce(signatures []*Signature, index int, k *big.Int, publicKey []byte) (*RecoveryResult, error) {
+	if err := c.curve.Validate(); err != nil {
+		return nil, err
+	}
+	priv, err := c.curve.RecoverPrivateKeyFromKnownNonce(signatures[index], k)
+	if err != nil {
+		return nil, err
+	}
+	verified := false
+	switch {
+	case c.curve.Verify != nil:
+		if verified = c.curve.Verify(priv, publicKey); !verified {
+			return nil, fmt.Errorf("key recovered from the nonce of signature %d was rejected by the curve's Verify", index)
+		}
+	case c.curve.HasGenerator() && len(publicKey) > 0:
+		if verified = c.curve.matchesPublicKey(priv, publicKey); !verified {
+			return nil, fmt.Errorf("key recovered from the nonce of signature %d does not match the public key", index)
+		}
+	}
+	return &RecoveryResult{
+		PrivateKey:    priv,
+		Relationship:  AffineRelationship{A: big.NewInt(1), B: big.NewInt(0)},
+		SignaturePair: [2]int{index, index},
+		Verified:      verified,
+		Pattern:       "known_nonce",
+	}, nil
+}
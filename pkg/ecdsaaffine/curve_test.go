@@ -0,0 +1,168 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// toyCurve is the textbook curve y² = x³ + 2x + 2 over F_17, whose generator (5, 1) has
+// order 19.
+func toyCurve() *Curve {
+	return &Curve{
+		Name: "toy", N: big.NewInt(19),
+		P: big.NewInt(17), A: big.NewInt(2), B: big.NewInt(2),
+		Gx: big.NewInt(5), Gy: big.NewInt(1),
+	}
+}
+
+// toyCurveSignatures signs with key d and nonces k0, k0+step, ... on curve, skipping
+// nonces the curve cannot sign with.
+func toyCurveSignatures(t *testing.T, curve *Curve, d int64, k0, step int64, count int) []*Signature {
+	var signatures []*Signature
+	for i := int64(0); len(signatures) < count; i++ {
+		if i > 100 {
+			t.Fatal("Too few nonces sign on the curve")
+		}
+		sig, err := curve.Sign(big.NewInt(d), big.NewInt(k0+step*i), big.NewInt(3+i))
+		if err == nil {
+			signatures = append(signatures, sig)
+		}
+	}
+	return signatures
+}
+
+func TestCurve_Validate(t *testing.T) {
+	if err := toyCurve().Validate(); err != nil {
+		t.Errorf("Toy curve: %v", err)
+	}
+	if err := (&Curve{N: big.NewInt(1000003)}).Validate(); err != nil {
+		t.Errorf("Order-only curve: %v", err)
+	}
+
+	offCurve := toyCurve()
+	offCurve.Gy = big.NewInt(2)
+	wrongOrder := toyCurve()
+	wrongOrder.N = big.NewInt(23)
+	partial := &Curve{N: big.NewInt(19), P: big.NewInt(17)}
+	for name, curve := range map[string]*Curve{"off curve": offCurve, "wrong order": wrongOrder, "partial": partial, "no order": {}} {
+		if err := curve.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCurve_Secp256k1(t *testing.T) {
+	// secp256k1 as a Curve gives the package's keys and signatures
+	curve := &Curve{
+		N: Secp256k1CurveOrder,
+		P: secp256k1.S256().P, A: big.NewInt(0), B: big.NewInt(7),
+		Gx: secp256k1.S256().Gx, Gy: secp256k1.S256().Gy,
+	}
+	d, k, z := big.NewInt(0xc0ffee), big.NewInt(0x5eed), HashMessage([]byte("curve"))
+	want := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeUncompressed()
+	if got := curve.PublicKey(d); !bytes.Equal(got, want) {
+		t.Errorf("PublicKey = %x, want %x", got, want)
+	}
+	sig, err := curve.Sign(d, k, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := signWithNonce(d, k, z); sig.R.Cmp(ref.R) != 0 || sig.S.Cmp(ref.S) != 0 {
+		t.Errorf("Sign differs from the secp256k1 signer")
+	}
+}
+
+func TestClient_WithCurve(t *testing.T) {
+	curve := toyCurve()
+	signatures := toyCurveSignatures(t, curve, 7, 2, 3, 8)
+	quiet := log.New(io.Discard, "", 0)
+	ctx := context.Background()
+	publicKey := hex.EncodeToString(curve.PublicKey(big.NewInt(7)))
+
+	client := NewClient(WithCurve(curve), WithLogger(quiet))
+	for _, key := range []string{publicKey, ""} {
+		result, err := client.RecoverKeyFromSignatures(ctx, signatures, key)
+		if err != nil {
+			t.Fatalf("public key %q: %v", key, err)
+		}
+		if result.PrivateKey.Int64() != 7 || result.Verified != (key != "") {
+			t.Errorf("public key %q: got key %s, verified %v", key, result.PrivateKey, result.Verified)
+		}
+	}
+
+	i, j := 0, 1
+	client = client.WithPairs([2]int{i, j})
+	result, err := client.RecoverKeyFromSignatures(ctx, signatures, publicKey)
+	if err != nil || result.PrivateKey.Int64() != 7 || result.SignaturePair != [2]int{i, j} {
+		t.Errorf("WithPairs: %+v, %v", result, err)
+	}
+
+	// A wrong public key is never matched
+	if _, err := client.RecoverKeyFromSignatures(ctx, signatures, hex.EncodeToString(curve.PublicKey(big.NewInt(8)))); err == nil {
+		t.Error("Expected no key for another signer's public key")
+	}
+}
+
+func TestCurveStrategy_OrderOnly(t *testing.T) {
+	// No generator: signatures are built from the signing equation with arbitrary r
+	n := big.NewInt(2305843009213693951) // 2^61 - 1
+	d := big.NewInt(123456789)
+	signatures := make([]*Signature, 4)
+	for i := range signatures {
+		k := big.NewInt(1000 + 3*int64(i))
+		r := big.NewInt(99991 * int64(i+1))
+		z := big.NewInt(31337 + int64(i))
+		s := new(big.Int).Mul(r, d)
+		s.Add(s, z).Mul(s, new(big.Int).ModInverse(k, n)).Mod(s, n)
+		signatures[i] = &Signature{Z: z, R: r, S: s}
+	}
+
+	strategy := NewCurveStrategy(&Curve{N: n})
+	strategy.Logger = log.New(io.Discard, "", 0)
+	result := strategy.Search(context.Background(), signatures, nil)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 || result.Verified || result.Pattern != "counter_+3" {
+		t.Fatalf("Expected the counter key unverified, got %+v", result)
+	}
+
+	// A Verify callback decides instead
+	strategy.Curve.Verify = func(privateKey *big.Int, publicKey []byte) bool { return privateKey.Cmp(d) == 0 }
+	if result := strategy.Search(context.Background(), signatures[:2], nil); result == nil || !result.Verified {
+		t.Errorf("Expected Verify to accept the key from one pair, got %+v", result)
+	}
+}
+
+func TestClient_WithCurve_KnownRelationshipAndNonce(t *testing.T) {
+	curve := toyCurve()
+	signatures := toyCurveSignatures(t, curve, 11, 4, 2, 6)
+	var buf bytes.Buffer
+	if err := WriteSignaturesJSON(&buf, signatures); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "toy.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	publicKey := hex.EncodeToString(curve.PublicKey(big.NewInt(11)))
+	client := NewClient(WithCurve(curve), WithLogger(log.New(io.Discard, "", 0)))
+
+	result, err := client.RecoverKeyWithKnownRelationship(context.Background(), path, 1, 2, publicKey)
+	if err != nil || result.PrivateKey.Int64() != 11 || !result.Verified {
+		t.Errorf("Known relationship: %+v, %v", result, err)
+	}
+	result, err = client.RecoverKeyWithKnownNonce(context.Background(), path, 2, big.NewInt(8), publicKey)
+	if err != nil || result.PrivateKey.Int64() != 11 || !result.Verified {
+		t.Errorf("Known nonce: %+v, %v", result, err)
+	}
+	if _, err := client.RecoverKeyWithKnownNonce(context.Background(), path, 2, big.NewInt(9), ""); err == nil {
+		t.Error("Expected an error for a nonce that does not give r")
+	}
+}
@@ -0,0 +1,142 @@
+package ecdsaaffine
+
+import (
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// curvePresets are the curves CurveByName knows, by lowercase name.
+var curvePresets = map[string]func() *Curve{
+	"secp256k1":       Secp256k1Curve,
+	"secp256r1":       Secp256r1,
+	"secp224k1":       Secp224k1,
+	"brainpoolp256r1": BrainpoolP256r1,
+	"brainpoolp384r1": BrainpoolP384r1,
+}
+
+// curveAliases are other names in use for the curves in curvePresets.
+var curveAliases = map[string]string{
+	"p-256":      "secp256r1",
+	"p256":       "secp256r1",
+	"prime256v1": "secp256r1",
+}
+
+// CurveByName returns a named curve for WithCurve, case-insensitively: secp256k1,
+// secp256r1 (also P-256 and prime256v1), secp224k1, brainpoolP256r1 or brainpoolP384r1.
+func CurveByName(name string) (*Curve, error) {
+	key := strings.ToLower(name)
+	if alias, ok := curveAliases[key]; ok {
+		key = alias
+	}
+	if curve, ok := curvePresets[key]; ok {
+		return curve(), nil
+	}
+	return nil, fmt.Errorf("unknown curve %q (known: %s)", name, strings.Join(CurveNames(), ", "))
+}
+
+// CurveNames returns the names CurveByName accepts, without aliases, sorted.
+func CurveNames() []string {
+	var names []string
+	for _, curve := range curvePresets {
+		names = append(names, curve().Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Secp256k1Curve returns secp256k1 as a Curve, with point arithmetic from the package's
+// secp256k1 implementation, e.g. to check a CurveStrategy against the client's usual
+// secp256k1 search, which is far faster.
+func Secp256k1Curve() *Curve {
+	params := secp256k1.Params()
+	curve := &Curve{
+		Name: "secp256k1", N: params.N,
+		P: params.P, A: big.NewInt(0), B: big.NewInt(7),
+		Gx: params.Gx, Gy: params.Gy,
+	}
+	curve.baseMult = func(k *big.Int) (*big.Int, *big.Int) {
+		var scalar secp256k1.ModNScalar
+		scalar.SetByteSlice(k.Bytes())
+		var point secp256k1.JacobianPoint
+		secp256k1.ScalarBaseMultNonConst(&scalar, &point)
+		point.ToAffine()
+		return new(big.Int).SetBytes(point.X.Bytes()[:]), new(big.Int).SetBytes(point.Y.Bytes()[:])
+	}
+	return curve
+}
+
+// Secp256r1 returns NIST P-256 (secp256r1, prime256v1), with point arithmetic from
+// crypto/ecdh.
+func Secp256r1() *Curve {
+	params := elliptic.P256().Params()
+	curve := &Curve{
+		Name: "secp256r1", N: params.N,
+		P: params.P, A: new(big.Int).Sub(params.P, big.NewInt(3)), B: params.B,
+		Gx: params.Gx, Gy: params.Gy,
+	}
+	curve.baseMult = func(k *big.Int) (*big.Int, *big.Int) {
+		key, err := ecdh.P256().NewPrivateKey(k.FillBytes(make([]byte, 32)))
+		if err != nil {
+			return nil, nil
+		}
+		point := key.PublicKey().Bytes()
+		return new(big.Int).SetBytes(point[1:33]), new(big.Int).SetBytes(point[33:])
+	}
+	return curve
+}
+
+// Secp224k1 returns the SEC 2 Koblitz curve secp224k1. Its order is one bit longer than
+// its field, so r is x mod N without a wrap.
+func Secp224k1() *Curve {
+	return &Curve{
+		Name: "secp224k1",
+		N:    hexCurveParam("010000000000000000000000000001DCE8D2EC6184CAF0A971769FB1F7"),
+		P:    hexCurveParam("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFE56D"),
+		A:    big.NewInt(0),
+		B:    big.NewInt(5),
+		Gx:   hexCurveParam("A1455B334DF099DF30FC28A169A467E9E47075A90F7E650EB6B7A45C"),
+		Gy:   hexCurveParam("7E089FED7FBA344282CAFBD6F7E319F7C0B0BD59E2CA4BDB556D61A5"),
+	}
+}
+
+// BrainpoolP256r1 returns brainpoolP256r1 (RFC 5639), used in government and automotive
+// systems.
+func BrainpoolP256r1() *Curve {
+	return &Curve{
+		Name: "brainpoolP256r1",
+		N:    hexCurveParam("A9FB57DBA1EEA9BC3E660A909D838D718C397AA3B561A6F7901E0E82974856A7"),
+		P:    hexCurveParam("A9FB57DBA1EEA9BC3E660A909D838D726E3BF623D52620282013481D1F6E5377"),
+		A:    hexCurveParam("7D5A0975FC2C3057EEF67530417AFFE7FB8055C126DC5C6CE94A4B44F330B5D9"),
+		B:    hexCurveParam("26DC5C6CE94A4B44F330B5D9BBD77CBF958416295CF7E1CE6BCCDC18FF8C07B6"),
+		Gx:   hexCurveParam("8BD2AEB9CB7E57CB2C4B482FFC81B7AFB9DE27E1E3BD23C23A4453BD9ACE3262"),
+		Gy:   hexCurveParam("547EF835C3DAC4FD97F8461A14611DC9C27745132DED8E545C1D54C72F046997"),
+	}
+}
+
+// BrainpoolP384r1 returns brainpoolP384r1 (RFC 5639).
+func BrainpoolP384r1() *Curve {
+	return &Curve{
+		Name: "brainpoolP384r1",
+		N:    hexCurveParam("8CB91E82A3386D280F5D6F7E50E641DF152F7109ED5456B31F166E6CAC0425A7CF3AB6AF6B7FC3103B883202E9046565"),
+		P:    hexCurveParam("8CB91E82A3386D280F5D6F7E50E641DF152F7109ED5456B412B1DA197FB71123ACD3A729901D1A71874700133107EC53"),
+		A:    hexCurveParam("7BC382C63D8C150C3C72080ACE05AFA0C2BEA28E4FB22787139165EFBA91F90F8AA5814A503AD4EB04A8C7DD22CE2826"),
+		B:    hexCurveParam("04A8C7DD22CE28268B39B55416F0447C2FB77DE107DCD2A62E880EA53EEB62D57CB4390295DBC9943AB78696FA504C11"),
+		Gx:   hexCurveParam("1D1C64F068CF45FFA2A63A81B7C13F6B8847A3E77EF14FE3DB7FCAFE0CBD10E8E826E03436D646AAEF87B2E247D4AF1E"),
+		Gy:   hexCurveParam("8ABE1D7520F9C2A45CB1EB8E95CFD55262B70B29FEEC5864E19C054FF99129280E4646217791811142820341263C5315"),
+	}
+}
+
+// hexCurveParam parses a hex curve constant.
+func hexCurveParam(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid curve constant " + s)
+	}
+	return v
+}
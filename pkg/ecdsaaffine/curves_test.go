@@ -0,0 +1,79 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"io"
+	"log"
+	"math/big"
+	"testing"
+)
+
+func TestCurveByName(t *testing.T) {
+	for _, name := range CurveNames() {
+		curve, err := CurveByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := curve.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if curve, err := CurveByName("P-256"); err != nil || curve.Name != "secp256r1" {
+		t.Errorf("Expected P-256 to be secp256r1, got %v, %v", curve, err)
+	}
+	if _, err := CurveByName("curve25519"); err == nil {
+		t.Error("Expected an error for an unknown curve")
+	}
+}
+
+func TestCurve_FastBaseMult(t *testing.T) {
+	// The named curves' point arithmetic agrees with the generic one
+	k := hexCurveParam("5eed5eed5eed5eed5eed5eed5eed5eed")
+	for _, curve := range []*Curve{Secp256k1Curve(), Secp256r1()} {
+		x, y := curve.ScalarBaseMult(k)
+		generic := *curve
+		generic.baseMult = nil
+		gx, gy := generic.ScalarBaseMult(k)
+		if x.Cmp(gx) != 0 || y.Cmp(gy) != 0 {
+			t.Errorf("%s: ScalarBaseMult differs from the generic arithmetic", curve.Name)
+		}
+	}
+}
+
+func TestCurve_Secp256r1Signatures(t *testing.T) {
+	// Signatures made on the curve verify with crypto/ecdsa
+	curve := Secp256r1()
+	d, k, z := big.NewInt(0xb0b), big.NewInt(0xc0de), HashMessage([]byte("p-256"))
+	sig, err := curve.Sign(d, k, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := curve.ScalarBaseMult(d)
+	publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	if !ecdsa.Verify(publicKey, z.FillBytes(make([]byte, 32)), sig.R, sig.S) {
+		t.Error("crypto/ecdsa rejects the curve's signature")
+	}
+}
+
+func TestClient_WithCurve_Brainpool(t *testing.T) {
+	curve := BrainpoolP256r1()
+	d := hexCurveParam("b7a1b900b7a1b900b7a1b900b7a1b900")
+	signatures := make([]*Signature, 3)
+	for i := range signatures {
+		sig, err := curve.Sign(d, big.NewInt(int64(1000+5*i)), HashMessage([]byte{byte(i)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signatures[i] = sig
+	}
+
+	client := NewClient(WithCurve(curve), WithLogger(log.New(io.Discard, "", 0)))
+	publicKey := hex.EncodeToString(curve.PublicKey(d))
+	result, err := client.RecoverKeyFromSignatures(context.Background(), signatures, publicKey)
+	if err != nil || result.PrivateKey.Cmp(d) != 0 || !result.Verified || result.Pattern != "counter_+5" {
+		t.Errorf("Expected the key verified, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,182 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/eta"
+)
+
+// calibrationDuration is how long EstimateSearch measures the search rate.
+const calibrationDuration = 300 * time.Millisecond
+
+// Approximate heap cost of the search's per-signature and per-pair state.
+const (
+	signatureBytes = 256 // Signature with three big.Int values
+	pairBytes      = 16  // [2]int pair index
+)
+
+// PhaseEstimate is the size and worst-case duration of one search phase.
+type PhaseEstimate struct {
+	Name       string
+	Phase      string // the phase name the search reports in its Metrics while it runs
+	ARange     [2]int // zero for pattern phases
+	BRange     [2]int // zero for pattern phases
+	Candidates float64
+	Parallel   bool
+	Duration   time.Duration
+}
+
+// SearchEstimate is the size, worst-case wall time and memory of a SmartBruteForceStrategy search.
+type SearchEstimate struct {
+	// Pairs is the number of signature pairs searched
+	Pairs int
+
+	// Phases lists the pattern and range phases in search order
+	Phases []PhaseEstimate
+
+	// Candidates is the total number of (a, b) candidates over all phases
+	Candidates float64
+
+	// Duration is the worst-case wall time, i.e. when no phase finds the key
+	Duration time.Duration
+
+	// MemoryBytes is the approximate peak heap the search needs
+	MemoryBytes int64
+
+	// Rate is the calibrated search rate the durations are based on
+	Rate SearchRate
+}
+
+// EstimateSearch estimates the cost of searching numSignatures signatures with config,
+// using a short calibration run to measure this machine's search rate.
+// Use it before launching a wide search: Phase 4-sized ranges take days to weeks.
+func EstimateSearch(config RangeConfig, numSignatures int) SearchEstimate {
+	rate := MeasureSearchRate(context.Background(), calibrationDuration, config.NumWorkers)
+	return EstimateSearchWithRate(config, numSignatures, rate)
+}
+
+// EstimateSearchWithRate is EstimateSearch with a known search rate (e.g. from MeasureSearchRate).
+func EstimateSearchWithRate(config RangeConfig, numSignatures int, rate SearchRate) SearchEstimate {
+	s := NewSmartBruteForceStrategy().WithRangeConfig(config)
+
+	pairs := numSignatures * (numSignatures - 1) / 2
+	if config.MaxPairs > 0 && pairs > config.MaxPairs {
+		pairs = config.MaxPairs
+	}
+	if pairs < 0 {
+		pairs = 0
+	}
+
+	parallelRate, sequentialRate := phaseRates(config, rate)
+	estimate := SearchEstimate{
+		Pairs:       pairs,
+		Rate:        rate,
+		MemoryBytes: int64(numSignatures)*signatureBytes + int64(pairs)*pairBytes,
+	}
+
+	if s.PatternConfig.IncludeCommonPatterns {
+		patterns := 0
+		for _, p := range s.getCommonPatterns() {
+			patterns++
+			if s.RangeConfig.BothDirections && centered(p.A).CmpAbs(big.NewInt(1)) > 0 {
+				patterns++
+			}
+		}
+		candidates := float64(patterns * pairs)
+		estimate.Phases = append(estimate.Phases, PhaseEstimate{
+			Name:       "Common patterns",
+			Phase:      commonPatternsPhase,
+			Candidates: candidates,
+			Duration:   eta.Duration(candidates, sequentialRate),
+		})
+	}
+	for _, r := range s.rangePhases() {
+		estimate.Phases = append(estimate.Phases, s.estimateRange(r, pairs, parallelRate, sequentialRate))
+	}
+
+	for _, phase := range estimate.Phases {
+		estimate.Candidates += phase.Candidates
+		estimate.Duration = eta.Add(estimate.Duration, phase.Duration)
+	}
+	return estimate
+}
+
+// EstimatePlan estimates the time and memory of a planned search (see Client.Plan) run
+// with config, using a short calibration run to measure this machine's search rate. Its
+// Phases are those of every planned search, in order.
+func EstimatePlan(plan *SearchPlan, config RangeConfig) SearchEstimate {
+	rate := MeasureSearchRate(context.Background(), calibrationDuration, config.NumWorkers)
+	return EstimatePlanWithRate(plan, config, rate)
+}
+
+// EstimatePlanWithRate is EstimatePlan with a known search rate.
+func EstimatePlanWithRate(plan *SearchPlan, config RangeConfig, rate SearchRate) SearchEstimate {
+	parallelRate, sequentialRate := phaseRates(config, rate)
+	estimate := SearchEstimate{
+		Rate:        rate,
+		Candidates:  plan.Candidates,
+		MemoryBytes: int64(plan.Signatures) * signatureBytes,
+	}
+	for _, search := range plan.Searches {
+		pairs := 0
+		for _, phase := range search.Phases {
+			perSecond := sequentialRate
+			if phase.Parallel {
+				perSecond = parallelRate
+			}
+			estimate.Phases = append(estimate.Phases, PhaseEstimate{
+				Name:       phase.Name,
+				Phase:      phase.Name,
+				ARange:     phase.ARange,
+				BRange:     phase.BRange,
+				Candidates: phase.Candidates,
+				Parallel:   phase.Parallel,
+				Duration:   eta.Duration(phase.Candidates, perSecond),
+			})
+			if phase.ARange != [2]int{} || phase.BRange != [2]int{} {
+				pairs = max(pairs, phase.Pairs)
+			}
+		}
+		estimate.Pairs = max(estimate.Pairs, pairs)
+	}
+	estimate.MemoryBytes += int64(estimate.Pairs) * pairBytes
+	for _, phase := range estimate.Phases {
+		estimate.Duration = eta.Add(estimate.Duration, phase.Duration)
+	}
+	return estimate
+}
+
+// WillComplete reports whether phase is predicted to finish within budget, the time left
+// for it. The prediction is the phase's worst case, searched to the end without finding
+// the key, at the calibrated rate of its estimate; a phase without a duration (no
+// calibrated rate) is assumed to complete.
+func WillComplete(phase PhaseEstimate, budget time.Duration) bool {
+	return phase.Duration <= budget || phase.Duration == 0
+}
+
+// estimateRange estimates searching the range phase r on pairs signature pairs.
+func (s *SmartBruteForceStrategy) estimateRange(r rangePhase, pairs int, parallelRate, sequentialRate float64) PhaseEstimate {
+	candidates := float64(s.rangeCombinations(r.aRange, r.bRange)) * float64(pairs)
+	parallel := s.rangeCombinations(r.aRange, r.bRange) > parallelThreshold
+	perSecond := sequentialRate
+	if parallel {
+		perSecond = parallelRate
+	}
+	return PhaseEstimate{
+		Name:       r.name,
+		Phase:      r.name,
+		ARange:     r.aRange,
+		BRange:     r.bRange,
+		Candidates: candidates,
+		Parallel:   parallel,
+		Duration:   eta.Duration(candidates, perSecond),
+	}
+}
+
+// phaseRates returns the search rate of parallel and of sequential phases, which run on
+// a single worker, both capped by the configured throttling.
+func phaseRates(config RangeConfig, rate SearchRate) (parallelRate, sequentialRate float64) {
+	return eta.Rates(rate.PerSecond(), rate.Workers, config.MaxCPUPercent, config.MaxRate)
+}
@@ -0,0 +1,131 @@
+package ecdsaaffine
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEstimateSearchWithRate(t *testing.T) {
+	rate := SearchRate{Workers: 4, Candidates: 4000, Elapsed: time.Second}
+	estimate := EstimateSearchWithRate(DefaultRangeConfig(), 10, rate)
+
+	if estimate.Pairs != 45 {
+		t.Errorf("Expected 45 pairs, got %d", estimate.Pairs)
+	}
+	if len(estimate.Phases) != 8 {
+		t.Fatalf("Expected common patterns and 7 range phases, got %d phases", len(estimate.Phases))
+	}
+
+	// Phase 2a: 111 combinations per pair, sequential at 1000/sec
+	phase := estimate.Phases[1]
+	if phase.Candidates != 111*45 || phase.Parallel {
+		t.Errorf("Unexpected Phase 2a estimate: %+v", phase)
+	}
+	if phase.Duration != 4995*time.Millisecond {
+		t.Errorf("Expected Phase 2a to take 4.995s, got %v", phase.Duration)
+	}
+
+	// Phase 4: 100 * 500500001 combinations per pair, a >= 2 in both directions, parallel
+	// at 4000/sec
+	phase = estimate.Phases[7]
+	if !phase.Parallel || phase.Candidates != 199*500500001*45 {
+		t.Errorf("Unexpected Phase 4 estimate: %+v", phase)
+	}
+	if estimate.Duration < 7*24*time.Hour {
+		t.Errorf("Expected a default search to take weeks at this rate, got %v", estimate.Duration)
+	}
+	if estimate.MemoryBytes <= 0 {
+		t.Errorf("Expected a memory estimate, got %d", estimate.MemoryBytes)
+	}
+
+	// A custom range replaces the schedule; MaxPairs caps the pairs
+	config := DefaultRangeConfig()
+	config.ARange = [2]int{1, 2}
+	config.BRange = [2]int{0, 9}
+	config.MaxPairs = 5
+	estimate = EstimateSearchWithRate(config, 10, rate)
+	if estimate.Pairs != 5 || len(estimate.Phases) != 2 || estimate.Phases[1].Candidates != 150 {
+		t.Errorf("Unexpected custom range estimate: %+v", estimate)
+	}
+
+	// One direction only: a = 2 is tried once per pair
+	config.BothDirections = false
+	estimate = EstimateSearchWithRate(config, 10, rate)
+	if estimate.Phases[1].Candidates != 100 {
+		t.Errorf("Expected 100 candidates in one direction, got %v", estimate.Phases[1].Candidates)
+	}
+}
+
+func TestEstimateSearchWithRate_PhaseIdentity(t *testing.T) {
+	// Each estimate names the phase the search reports in its metrics
+	estimate := EstimateSearchWithRate(DefaultRangeConfig(), 10, SearchRate{})
+	if phase := estimate.Phases[0]; phase.Phase != commonPatternsPhase || phase.Name == phase.Phase {
+		t.Errorf("Unexpected pattern phase %+v", phase)
+	}
+	for i, r := range NewSmartBruteForceStrategy().rangePhases() {
+		if phase := estimate.Phases[i+1]; phase.Phase != r.name {
+			t.Errorf("Range phase %d reported as %q, estimated as %q", i, r.name, phase.Phase)
+		}
+	}
+}
+
+func TestEstimateSearchWithRate_Throttled(t *testing.T) {
+	rate := SearchRate{Workers: 4, Candidates: 4000, Elapsed: time.Second}
+	config := DefaultRangeConfig()
+	config.MaxRate = 500
+	estimate := EstimateSearchWithRate(config, 10, rate)
+
+	// Phase 2a: 4995 candidates, capped at 500/sec
+	if phase := estimate.Phases[1]; phase.Duration != 9990*time.Millisecond {
+		t.Errorf("Expected throttled Phase 2a to take 9.99s, got %v", phase.Duration)
+	}
+}
+
+// TestEstimateSearchWithRate_Saturates estimates a default search at one candidate a
+// minute, longer than a time.Duration holds: durations must saturate, not wrap negative.
+func TestEstimateSearchWithRate_Saturates(t *testing.T) {
+	rate := SearchRate{Workers: 1, Candidates: 1, Elapsed: time.Minute}
+	estimate := EstimateSearchWithRate(DefaultRangeConfig(), 1000, rate)
+	for _, phase := range estimate.Phases {
+		if phase.Duration < 0 {
+			t.Errorf("%s: negative duration %v", phase.Name, phase.Duration)
+		}
+	}
+	if estimate.Duration != time.Duration(math.MaxInt64) {
+		t.Errorf("Expected the total to saturate, got %v", estimate.Duration)
+	}
+}
+
+func TestEstimatePlanWithRate(t *testing.T) {
+	plan := &SearchPlan{Signatures: 10, Searches: []PlannedSearch{{Phases: []PlannedPhase{
+		{Name: "Phase 1: common patterns", Pairs: 45, Candidates: 900},
+		{Name: "Custom range", ARange: [2]int{1, 10}, BRange: [2]int{0, 99999}, Pairs: 5, Candidates: 5e6, Parallel: true},
+	}}}, Candidates: 900 + 5e6}
+	rate := SearchRate{Workers: 4, Candidates: 4000, Elapsed: time.Second}
+
+	estimate := EstimatePlanWithRate(plan, DefaultRangeConfig(), rate)
+	if len(estimate.Phases) != 2 || estimate.Pairs != 5 || estimate.Candidates != plan.Candidates {
+		t.Fatalf("Unexpected estimate: %+v", estimate)
+	}
+	// Patterns run sequentially at 1000/sec, the range in parallel at 4000/sec
+	if estimate.Phases[0].Duration != 900*time.Millisecond || estimate.Phases[1].Duration != 1250*time.Second {
+		t.Errorf("Unexpected phase durations %v, %v", estimate.Phases[0].Duration, estimate.Phases[1].Duration)
+	}
+	if estimate.Duration != 1250900*time.Millisecond {
+		t.Errorf("Expected 1250.9s in total, got %v", estimate.Duration)
+	}
+}
+
+func TestWillComplete(t *testing.T) {
+	phase := PhaseEstimate{Name: "Phase 3c", Duration: 16 * time.Second}
+	if !WillComplete(phase, 20*time.Second) {
+		t.Error("Expected a 16s phase to complete in 20s")
+	}
+	if WillComplete(phase, 10*time.Second) {
+		t.Error("Expected a 16s phase not to complete in 10s")
+	}
+	if !WillComplete(PhaseEstimate{Name: "Uncalibrated"}, 0) {
+		t.Error("Expected a phase without an estimate to be assumed to complete")
+	}
+}
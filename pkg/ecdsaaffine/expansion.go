@@ -0,0 +1,103 @@
+package ecdsaaffine
+
+import "time"
+
+// ExpansionPolicy decides the ranges searched by the adaptive range search, one phase
+// at a time, so schedules other than the built-in phases (geometric growth, ranges
+// biased by what earlier phases saw, time or work budgets) need no change to the
+// strategy. Set it as SmartBruteForceStrategy.Expansion.
+type ExpansionPolicy interface {
+	// NextRange returns the a and b ranges (inclusive) of the next phase, given the
+	// phases searched so far in order, or ok = false to end the range search.
+	NextRange(prev []PhaseResult, stats SearchStats) (aRange, bRange [2]int, ok bool)
+}
+
+// PhaseResult is the outcome of one range phase that did not find the key.
+type PhaseResult struct {
+	Name         string
+	ARange       [2]int
+	BRange       [2]int
+	Combinations int // (a, b) combinations per signature pair
+	Duration     time.Duration
+	Skipped      bool // abandoned through RangeConfig.SkipPhase or skipped by SkipOverBudget
+}
+
+// SearchStats describes the range search so far.
+type SearchStats struct {
+	Signatures int
+	Pairs      int           // signature pairs each phase searches (see RangeConfig.MaxPairs)
+	Candidates int64         // candidates in the phases searched so far, skipped ones included
+	Elapsed    time.Duration // since the range search started
+	Tuning     Tuning        // the configuration RangeConfig.AutoTune chose, once it has run
+}
+
+// GeometricExpansion searches b in [-bound, bound] for a in ARange, starting with
+// bound = Start and multiplying it by Factor each phase until it exceeds Max.
+type GeometricExpansion struct {
+	ARange [2]int
+	Start  int
+	Factor float64 // at least 2 in effect
+	Max    int
+}
+
+// NextRange implements ExpansionPolicy.
+func (g *GeometricExpansion) NextRange(prev []PhaseResult, stats SearchStats) (aRange, bRange [2]int, ok bool) {
+	bound := float64(max(g.Start, 1))
+	if len(prev) > 0 {
+		bound = float64(prev[len(prev)-1].BRange[1]) * max(g.Factor, 2)
+	}
+	if bound > float64(g.Max) {
+		return aRange, bRange, false
+	}
+	return g.ARange, [2]int{-int(bound), int(bound)}, true
+}
+
+// BudgetExpansion ends the phases of Policy once the next one would take the search
+// past MaxCandidates candidates, or once MaxDuration has passed. Zero limits are ignored.
+type BudgetExpansion struct {
+	Policy        ExpansionPolicy
+	MaxCandidates int64
+	MaxDuration   time.Duration
+	SkipZeroA     bool // count a = 0 as the strategy does (RangeConfig.SkipZeroA)
+}
+
+// NextRange implements ExpansionPolicy.
+func (b *BudgetExpansion) NextRange(prev []PhaseResult, stats SearchStats) (aRange, bRange [2]int, ok bool) {
+	if b.MaxDuration > 0 && stats.Elapsed >= b.MaxDuration {
+		return aRange, bRange, false
+	}
+	aRange, bRange, ok = b.Policy.NextRange(prev, stats)
+	if !ok || b.MaxCandidates <= 0 {
+		return aRange, bRange, ok
+	}
+	next := combinations(aRange, bRange, b.SkipZeroA) * float64(stats.Pairs)
+	if float64(stats.Candidates)+next > float64(b.MaxCandidates) {
+		return aRange, bRange, false
+	}
+	return aRange, bRange, true
+}
+
+// phaseList is the fixed schedule of named phases used without an ExpansionPolicy.
+type phaseList []rangePhase
+
+// NextRange implements ExpansionPolicy.
+func (p phaseList) NextRange(prev []PhaseResult, stats SearchStats) (aRange, bRange [2]int, ok bool) {
+	if len(prev) >= len(p) {
+		return aRange, bRange, false
+	}
+	return p[len(prev)].aRange, p[len(prev)].bRange, true
+}
+
+// combinations is the number of (a, b) combinations in a rectangle, as a float64 so
+// policies can size ranges of any width.
+func combinations(aRange, bRange [2]int, skipZeroA bool) float64 {
+	aCount := float64(aRange[1]) - float64(aRange[0]) + 1
+	if skipZeroA && aRange[0] <= 0 && aRange[1] >= 0 {
+		aCount--
+	}
+	bCount := float64(bRange[1]) - float64(bRange[0]) + 1
+	if aCount <= 0 || bCount <= 0 {
+		return 0
+	}
+	return aCount * bCount
+}
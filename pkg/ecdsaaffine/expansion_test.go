@@ -0,0 +1,78 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// recordingPolicy passes calls to Policy and keeps what it was given.
+type recordingPolicy struct {
+	Policy ExpansionPolicy
+	prev   []PhaseResult
+	stats  []SearchStats
+}
+
+func (r *recordingPolicy) NextRange(prev []PhaseResult, stats SearchStats) (aRange, bRange [2]int, ok bool) {
+	r.prev = prev
+	r.stats = append(r.stats, stats)
+	return r.Policy.NextRange(prev, stats)
+}
+
+func expansionTestStrategy(policy ExpansionPolicy) *SmartBruteForceStrategy {
+	strategy := NewSmartBruteForceStrategy().
+		WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig.MaxPairs = 10
+	strategy.Expansion = policy
+	return strategy
+}
+
+func TestGeometricExpansion_NextRange(t *testing.T) {
+	policy := &GeometricExpansion{ARange: [2]int{1, 2}, Start: 10, Factor: 10, Max: 5000}
+	var prev []PhaseResult
+	for _, want := range []int{10, 100, 1000} {
+		aRange, bRange, ok := policy.NextRange(prev, SearchStats{})
+		if !ok || aRange != [2]int{1, 2} || bRange != [2]int{-want, want} {
+			t.Fatalf("Phase %d: got %v, %v, %v; want b in [-%d, %d]", len(prev)+1, aRange, bRange, ok, want, want)
+		}
+		prev = append(prev, PhaseResult{ARange: aRange, BRange: bRange})
+	}
+	if _, _, ok := policy.NextRange(prev, SearchStats{}); ok {
+		t.Error("Expected the policy to stop past Max")
+	}
+}
+
+func TestSmartBruteForceStrategy_Expansion(t *testing.T) {
+	d := big.NewInt(0x5eed)
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(424242), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(424242+7000), HashMessage([]byte("message 2"))),
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	policy := &recordingPolicy{Policy: &GeometricExpansion{ARange: [2]int{1, 1}, Start: 10, Factor: 10, Max: 100000}}
+	result := expansionTestStrategy(policy).Search(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the key from the b = 7000 phase, got %+v", result)
+	}
+
+	// The policy saw the three phases before the one that found the key
+	if len(policy.stats) != 4 || len(policy.prev) != 3 {
+		t.Fatalf("Policy called %d times with %d previous phases, want 4 and 3", len(policy.stats), len(policy.prev))
+	}
+	last := policy.prev[2]
+	if last.Name != "Expansion phase 3" || last.BRange != [2]int{-1000, 1000} || last.Combinations != 2001 || last.Skipped {
+		t.Errorf("Unexpected third phase %+v", last)
+	}
+	if stats := policy.stats[3]; stats.Signatures != 2 || stats.Pairs != 1 || stats.Candidates != 21+201+2001 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// A budget too small for the fourth phase ends the search first
+	budget := &BudgetExpansion{Policy: &GeometricExpansion{ARange: [2]int{1, 1}, Start: 10, Factor: 10, Max: 100000}, MaxCandidates: 5000}
+	if result := expansionTestStrategy(budget).Search(context.Background(), signatures, publicKey); result != nil {
+		t.Errorf("Expected the budget to stop the search before b = 7000, got %+v", result)
+	}
+}
@@ -0,0 +1,121 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SignatureFilter selects the signatures of a dataset to search, so a time window, a
+// block range or one signer can be searched without preprocessing the file. Zero fields
+// match every signature; a signature lacking a field a bound applies to (no timestamp,
+// no block height, no signer key) does not match it.
+type SignatureFilter struct {
+	Since time.Time // earliest timestamp, inclusive
+	Until time.Time // latest timestamp, exclusive
+
+	MinBlock, MaxBlock *int64 // block height range, inclusive
+
+	// PublicKeys are the signers to keep, in any ParsePublicKey encoding or as Ethereum
+	// addresses, compared with each signature's SignerKey
+	PublicKeys [][]byte
+
+	// MessagePrefix keeps signatures over messages starting with it. Only JSONParser
+	// and CSVParser read messages, and only from datasets with a message field.
+	MessagePrefix []byte
+}
+
+// errNoMessage is returned by Filter for a MessagePrefix on a parser without messages.
+var errNoMessage = errors.New("message prefix filters need a parser that reads messages (json or csv)")
+
+// Match reports whether sig, signed over message (nil if unknown), passes the filter.
+func (f *SignatureFilter) Match(sig *Signature, message []byte) bool {
+	if !f.Since.IsZero() && (sig.Timestamp.IsZero() || sig.Timestamp.Before(f.Since)) {
+		return false
+	}
+	if !f.Until.IsZero() && (sig.Timestamp.IsZero() || !sig.Timestamp.Before(f.Until)) {
+		return false
+	}
+	if f.MinBlock != nil && (sig.BlockHeight == nil || *sig.BlockHeight < *f.MinBlock) {
+		return false
+	}
+	if f.MaxBlock != nil && (sig.BlockHeight == nil || *sig.BlockHeight > *f.MaxBlock) {
+		return false
+	}
+	if len(f.PublicKeys) > 0 {
+		key := sig.SignerKey()
+		if len(key) == 0 {
+			return false
+		}
+		found := false
+		for _, publicKey := range f.PublicKeys {
+			if sameSigner(key, publicKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.MessagePrefix) > 0 && (message == nil || !bytes.HasPrefix(message, f.MessagePrefix)) {
+		return false
+	}
+	return true
+}
+
+// Filter returns a parser that reads what parser reads and keeps the signatures filter
+// matches. JSON and CSV parsers apply the filter as they read each record, with access
+// to its message; other parsers are filtered after parsing, and fail for a
+// MessagePrefix.
+func Filter(parser SignatureParser, filter *SignatureFilter) (SignatureParser, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	switch p := parser.(type) {
+	case *JSONParser:
+		filtered := *p
+		filtered.Filter = filter
+		return &filtered, nil
+	case *CSVParser:
+		filtered := *p
+		filtered.Filter = filter
+		return &filtered, nil
+	}
+	if len(filter.MessagePrefix) > 0 {
+		return nil, errNoMessage
+	}
+	return &filteredParser{parser: parser, filter: filter}, nil
+}
+
+// filteredParser filters the signatures of a parser that has no Filter field.
+type filteredParser struct {
+	parser SignatureParser
+	filter *SignatureFilter
+}
+
+func (p *filteredParser) ParseSignatures(source string) ([]*Signature, error) {
+	signatures, err := p.parser.ParseSignatures(source)
+	if err != nil {
+		return nil, err
+	}
+	kept := signatures[:0]
+	for _, sig := range signatures {
+		if p.filter.Match(sig, nil) {
+			kept = append(kept, sig)
+		}
+	}
+	return kept, nil
+}
+
+// validate checks the filter's ranges.
+func (f *SignatureFilter) validate() error {
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		return fmt.Errorf("filter time window is empty: %s is not before %s", f.Since.Format(time.RFC3339), f.Until.Format(time.RFC3339))
+	}
+	if f.MinBlock != nil && f.MaxBlock != nil && *f.MinBlock > *f.MaxBlock {
+		return fmt.Errorf("filter block range is empty: %d > %d", *f.MinBlock, *f.MaxBlock)
+	}
+	return nil
+}
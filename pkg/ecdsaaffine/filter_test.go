@@ -0,0 +1,141 @@
+package ecdsaaffine
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestSignatureFilter_Match(t *testing.T) {
+	d := big.NewInt(0xf117e5)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	other := secp256k1.PrivKeyFromBytes([]byte{0x42}).PubKey().SerializeUncompressed()
+	at := time.Unix(1700000000, 0).UTC()
+	height := int64(800000)
+	sig := &Signature{Z: big.NewInt(1), R: big.NewInt(2), S: big.NewInt(3), PublicKey: publicKey, Timestamp: at, BlockHeight: &height}
+	bare := &Signature{Z: big.NewInt(1), R: big.NewInt(2), S: big.NewInt(3)}
+	lower, higher := height+1, height-1
+
+	tests := []struct {
+		name   string
+		filter SignatureFilter
+		sig    *Signature
+		want   bool
+	}{
+		{"empty", SignatureFilter{}, bare, true},
+		{"since inclusive", SignatureFilter{Since: at}, sig, true},
+		{"since after", SignatureFilter{Since: at.Add(time.Second)}, sig, false},
+		{"until exclusive", SignatureFilter{Until: at}, sig, false},
+		{"until after", SignatureFilter{Until: at.Add(time.Second)}, sig, true},
+		{"no timestamp", SignatureFilter{Since: at.Add(-time.Hour)}, bare, false},
+		{"block in range", SignatureFilter{MinBlock: &height, MaxBlock: &height}, sig, true},
+		{"block below", SignatureFilter{MinBlock: &lower}, sig, false},
+		{"block above", SignatureFilter{MaxBlock: &higher}, sig, false},
+		{"no block", SignatureFilter{MinBlock: &higher}, bare, false},
+		{"signer", SignatureFilter{PublicKeys: [][]byte{other, publicKey}}, sig, true},
+		{"other signer", SignatureFilter{PublicKeys: [][]byte{other}}, sig, false},
+		{"no signer", SignatureFilter{PublicKeys: [][]byte{publicKey}}, bare, false},
+		{"no message", SignatureFilter{MessagePrefix: []byte("login:")}, sig, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.sig, nil); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The signer's Ethereum address selects its signatures too
+	address := EthereumAddress(d)
+	if !(&SignatureFilter{PublicKeys: [][]byte{address}}).Match(sig, nil) {
+		t.Error("Expected the signer's address to match its public key")
+	}
+}
+
+func TestFilter_MessagePrefix(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "signatures.json")
+	data := `[
+		{"message": "login:alice", "z": "0x01", "r": "0x02", "s": "0x03"},
+		{"message": "transfer:bob", "z": "0x04", "r": "0x05", "s": "0x06"},
+		{"message": "login:carol", "z": "0x07", "r": "0x08", "s": "0x09"}
+	]`
+	if err := os.WriteFile(jsonPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	csvPath := filepath.Join(dir, "signatures.csv")
+	data = "message,z,r,s\nlogin:alice,0x01,0x02,0x03\ntransfer:bob,0x04,0x05,0x06\nlogin:carol,0x07,0x08,0x09\n"
+	if err := os.WriteFile(csvPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := &SignatureFilter{MessagePrefix: []byte("login:")}
+	for path, parser := range map[string]SignatureParser{
+		jsonPath: &JSONParser{ZField: "z"},
+		csvPath:  &CSVParser{ZCol: "z"},
+	} {
+		filtered, err := Filter(parser, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signatures, err := filtered.ParseSignatures(path)
+		if err != nil {
+			t.Fatalf("%s: %v", filepath.Base(path), err)
+		}
+		if len(signatures) != 2 || signatures[0].Z.Int64() != 1 || signatures[1].Z.Int64() != 7 {
+			t.Errorf("%s: expected the two login signatures, got %d", filepath.Base(path), len(signatures))
+		}
+	}
+
+	// Filter copies the parser rather than changing it
+	parser := &JSONParser{ZField: "z"}
+	if _, err := Filter(parser, filter); err != nil {
+		t.Fatal(err)
+	}
+	if parser.Filter != nil {
+		t.Error("Filter modified the parser it was given")
+	}
+
+	if _, err := Filter(&StoreParser{}, filter); !errors.Is(err, errNoMessage) {
+		t.Errorf("Expected errNoMessage for a store parser, got %v", err)
+	}
+}
+
+func TestFilter_Store(t *testing.T) {
+	signatures := contextTestSignatures(big.NewInt(0xf17e), 10)
+	path := filepath.Join(t.TempDir(), "signatures.store")
+	if err := WriteSignatureStore(path, signatures, nil, WithStoreSignerContext()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Even-indexed signatures carry a timestamp 1700000000+i
+	filtered, err := Filter(&StoreParser{}, &SignatureFilter{Since: time.Unix(1700000004, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := filtered.ParseSignatures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Z.Cmp(signatures[4].Z) != 0 || got[2].Z.Cmp(signatures[8].Z) != 0 {
+		t.Errorf("Expected signatures 4, 6 and 8, got %d signatures", len(got))
+	}
+}
+
+func TestFilter_EmptyRange(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	low, high := int64(10), int64(5)
+	for _, filter := range []*SignatureFilter{
+		{Since: at, Until: at},
+		{MinBlock: &low, MaxBlock: &high},
+	} {
+		if _, err := Filter(&JSONParser{}, filter); err == nil {
+			t.Errorf("Expected an error for %+v", filter)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ecdsaaffine
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
+)
+
+// fixtureJSON is the JSON object WriteSignaturesJSON writes for a signature.
+type fixtureJSON struct {
+	Z           *big.Int `json:"z"`
+	R           *big.Int `json:"r"`
+	S           *big.Int `json:"s"`
+	PublicKey   string   `json:"public_key,omitempty"`
+	RecoveryID  *int     `json:"recovery_id,omitempty"`
+	Timestamp   string   `json:"timestamp,omitempty"`
+	Sequence    *int64   `json:"sequence,omitempty"`
+	BlockHeight *int64   `json:"block_height,omitempty"`
+	Message     *string  `json:"message,omitempty"`
+}
+
+// WriteSignaturesJSON writes signatures as a JSON array, one object per line, in the
+// format the "json" parser reads: z, r and s as decimal numbers, and the signer context
+// fields and message that are set. It converts a signature store, or any parsed dataset, back to
+// JSON.
+func WriteSignaturesJSON(w io.Writer, signatures []*Signature) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[\n")
+	for k, sig := range signatures {
+		item := fixtureJSON{Z: sig.Z, R: sig.R, S: sig.S, RecoveryID: sig.RecoveryID, Sequence: sig.Sequence, BlockHeight: sig.BlockHeight}
+		if len(sig.PublicKey) > 0 {
+			item.PublicKey = hex.EncodeToString(sig.PublicKey)
+		}
+		if !sig.Timestamp.IsZero() {
+			item.Timestamp = sig.Timestamp.UTC().Format(time.RFC3339Nano)
+		}
+		if sig.Message != nil {
+			message := string(sig.Message)
+			item.Message = &message
+		}
+		line, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("signature %d: %w", k, err)
+		}
+		bw.WriteString("  ")
+		bw.Write(line)
+		if k < len(signatures)-1 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('\n')
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+// LoadCachedSignatures parses a local signatures file with parser, keeping a binary copy
+// (a signature store with the signer context) in cacheDir that later calls load instead.
+// The copy is named by a SHA-256 of the file's contents and the parser configuration, so
+// an edited file or a different parser never loads a stale copy, and concurrent callers
+// share copies safely. An empty cacheDir means an "ecdsa-affine" directory under
+// os.UserCacheDir. Loading a store skips JSON and CSV decoding entirely, which is most of
+// the time tests and benchmarks spend on datasets of 100k signatures. A cache that cannot
+// be written is not an error.
+func LoadCachedSignatures(path, cacheDir string, parser SignatureParser) ([]*Signature, error) {
+	if source.IsRemote(path) {
+		return nil, fmt.Errorf("%s: only local files are cached", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if cacheDir == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			cacheDir = filepath.Join(dir, "ecdsa-affine")
+		}
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%T %+v\n", parser, parser)
+	h.Write(data)
+	cache := filepath.Join(cacheDir, hex.EncodeToString(h.Sum(nil)[:16])+".store")
+	if store, err := OpenSignatureStore(cache); err == nil {
+		defer store.Close()
+		if store.HasSignerContext() {
+			return store.Slice(0, store.Len()), nil
+		}
+	}
+
+	signatures, err := parser.ParseSignatures(path)
+	if err != nil {
+		return nil, err
+	}
+	if cacheDir == "" || os.MkdirAll(cacheDir, 0o755) != nil {
+		return signatures, nil
+	}
+	// Through a temporary file, so concurrent test binaries never read a partial store
+	tmp, err := os.CreateTemp(cacheDir, filepath.Base(cache)+".tmp*")
+	if err != nil {
+		return signatures, nil
+	}
+	tmp.Close()
+	if err := WriteSignatureStore(tmp.Name(), signatures, nil, WithStoreSignerContext()); err != nil || os.Rename(tmp.Name(), cache) != nil {
+		os.Remove(tmp.Name())
+	}
+	return signatures, nil
+}
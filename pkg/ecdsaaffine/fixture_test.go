@@ -0,0 +1,147 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// contextTestSignatures returns storeTestSignatures with every signer context field, and
+// the message, set on some signatures.
+func contextTestSignatures(d *big.Int, count int) []*Signature {
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := storeTestSignatures(d, count)
+	for i, sig := range signatures {
+		if i%2 == 0 {
+			sig.PublicKey = publicKey
+			sig.Timestamp = time.Unix(1700000000+int64(i), 123456789).UTC()
+		}
+		if i%2 == 1 {
+			sig.Message = []byte("message " + strconv.Itoa(i))
+		}
+		if i%3 == 0 {
+			id, n := i%4, int64(-i)
+			sig.RecoveryID = &id
+			sig.Sequence = &n
+			sig.BlockHeight = &n
+		}
+	}
+	return signatures
+}
+
+func TestSignatureStore_SignerContext(t *testing.T) {
+	signatures := contextTestSignatures(big.NewInt(0xf17e), 7)
+	signatures[5].PublicKey = bytes.Repeat([]byte{0xab}, EthereumAddressLen)
+	path := filepath.Join(t.TempDir(), "fixture.store")
+	if err := WriteSignatureStore(path, signatures, nil, WithStoreSignerContext()); err != nil {
+		t.Fatal(err)
+	}
+	messages := 0
+	for _, sig := range signatures {
+		messages += len(sig.Message)
+	}
+	info, _ := os.Stat(path)
+	if info.Size() != int64(storeHeaderSize+7*storeMessageRecordSize+messages) {
+		t.Errorf("Expected %d-byte records and %d bytes of messages, got a %d-byte file", storeMessageRecordSize, messages, info.Size())
+	}
+	if leftover, _ := filepath.Glob(path + ".messages-*"); len(leftover) != 0 {
+		t.Errorf("Message spool not removed: %v", leftover)
+	}
+
+	store, err := OpenSignatureStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if !store.HasSignerContext() {
+		t.Error("Expected a store with the signer context")
+	}
+	if got := store.Slice(0, store.Len()); !reflect.DeepEqual(got, signatures) {
+		t.Errorf("Signatures do not round-trip:\ngot  %+v\nwant %+v", got[0], signatures[0])
+	}
+
+	// Version 1 stores drop the context
+	plain := filepath.Join(t.TempDir(), "plain.store")
+	if err := WriteSignatureStore(plain, signatures, nil); err != nil {
+		t.Fatal(err)
+	}
+	signatures, err = (&StoreParser{}).ParseSignatures(plain)
+	if err != nil || signatures[0].PublicKey != nil || !signatures[0].Timestamp.IsZero() {
+		t.Errorf("Expected z, r and s only, got %+v, %v", signatures[0], err)
+	}
+
+	bad := 7
+	sig := storeTestSignatures(big.NewInt(1), 1)[0]
+	sig.RecoveryID = &bad
+	if err := WriteSignatureStore(path, []*Signature{sig}, nil, WithStoreSignerContext()); err == nil {
+		t.Error("Expected an error for recovery id 7")
+	}
+}
+
+func TestWriteSignaturesJSON(t *testing.T) {
+	signatures := contextTestSignatures(big.NewInt(0x15011), 4)
+	var buf bytes.Buffer
+	if err := WriteSignaturesJSON(&buf, signatures); err != nil {
+		t.Fatal(err)
+	}
+	parser, _ := NewParser("json")
+	got, err := parser.(*JSONParser).Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, signatures) {
+		t.Errorf("Signatures do not round-trip through JSON:\ngot  %+v\nwant %+v", got[0], signatures[0])
+	}
+}
+
+func TestLoadCachedSignatures(t *testing.T) {
+	signatures := contextTestSignatures(big.NewInt(0xcac4e), 5)
+	dir, cacheDir := t.TempDir(), t.TempDir()
+	path := filepath.Join(dir, "signatures.json")
+	var buf bytes.Buffer
+	WriteSignaturesJSON(&buf, signatures)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parser, _ := NewParser("json")
+
+	got, err := LoadCachedSignatures(path, cacheDir, parser)
+	if err != nil || !reflect.DeepEqual(got, signatures) {
+		t.Fatalf("Expected the parsed signatures, got %v", err)
+	}
+	caches, _ := filepath.Glob(filepath.Join(cacheDir, "*.store"))
+	if len(caches) != 1 {
+		t.Fatalf("Expected one cache, got %v", caches)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected nothing written next to the file, got %d entries", len(entries))
+	}
+
+	// The cache is read instead of any file with the same contents
+	if err := WriteSignatureStore(caches[0], signatures[:2], nil, WithStoreSignerContext()); err != nil {
+		t.Fatal(err)
+	}
+	copied := filepath.Join(dir, "copy.json")
+	os.WriteFile(copied, buf.Bytes(), 0o644)
+	if got, err := LoadCachedSignatures(copied, cacheDir, parser); err != nil || !reflect.DeepEqual(got, signatures[:2]) {
+		t.Errorf("Expected the cached signatures for the same contents, got %v", err)
+	}
+	if _, err := LoadCachedSignatures(copied, cacheDir, &JSONParser{RField: "missing"}); err == nil {
+		t.Error("Expected another parser configuration to parse the file")
+	}
+
+	// An edited file is parsed again, whatever its modification time
+	stale := time.Now().Add(-time.Hour)
+	os.WriteFile(path, []byte("not json"), 0o644)
+	os.Chtimes(path, stale, stale)
+	if _, err := LoadCachedSignatures(path, cacheDir, parser); err == nil {
+		t.Error("Expected the changed file to be parsed")
+	}
+}
@@ -0,0 +1,49 @@
+package ecdsaaffine
+
+import "math/big"
+
+// HypothesisGenerator proposes relationships k2 = a*k1 + b for one signature pair
+// (sig1 made before sig2 in dataset order), most likely first. See
+// PatternConfig.Hypotheses.
+type HypothesisGenerator func(sig1, sig2 *Signature) []Pattern
+
+// MetadataHypotheses proposes counter relationships from the pair's metadata: when a
+// signer derives nonces from a counter, the sequence number, block height or signing
+// time often advances with it, so k2 = k1 + Δ for the difference Δ in sequence number,
+// block height, timestamp in seconds, or timestamp in milliseconds (when either time has
+// sub-second precision), in that order. Zero and repeated differences are left out.
+func MetadataHypotheses(sig1, sig2 *Signature) []Pattern {
+	var deltas []int64
+	var names []string
+	add := func(delta int64, name string) {
+		if delta == 0 {
+			return
+		}
+		for _, d := range deltas {
+			if d == delta {
+				return
+			}
+		}
+		deltas = append(deltas, delta)
+		names = append(names, name)
+	}
+
+	if sig1.Sequence != nil && sig2.Sequence != nil {
+		add(*sig2.Sequence-*sig1.Sequence, "metadata_sequence")
+	}
+	if sig1.BlockHeight != nil && sig2.BlockHeight != nil {
+		add(*sig2.BlockHeight-*sig1.BlockHeight, "metadata_block_height")
+	}
+	if !sig1.Timestamp.IsZero() && !sig2.Timestamp.IsZero() {
+		add(sig2.Timestamp.Unix()-sig1.Timestamp.Unix(), "metadata_timestamp")
+		if sig1.Timestamp.Nanosecond() != 0 || sig2.Timestamp.Nanosecond() != 0 {
+			add(sig2.Timestamp.UnixMilli()-sig1.Timestamp.UnixMilli(), "metadata_timestamp_ms")
+		}
+	}
+
+	patterns := make([]Pattern, len(deltas))
+	for i, delta := range deltas {
+		patterns[i] = Pattern{A: big.NewInt(1), B: big.NewInt(delta), Name: names[i], Priority: i}
+	}
+	return patterns
+}
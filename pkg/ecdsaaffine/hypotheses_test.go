@@ -0,0 +1,103 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestMetadataHypotheses(t *testing.T) {
+	seq := func(n int64) *int64 { return &n }
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	sig1 := &Signature{Sequence: seq(10), BlockHeight: seq(840000), Timestamp: start}
+	sig2 := &Signature{Sequence: seq(12), BlockHeight: seq(840002), Timestamp: start.Add(1500 * time.Millisecond)}
+
+	// The block height delta repeats the sequence delta; the sub-second time adds milliseconds
+	var got []string
+	for _, p := range MetadataHypotheses(sig1, sig2) {
+		if p.A.Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("%s: a = %s, expected 1", p.Name, p.A)
+		}
+		got = append(got, p.Name+"="+p.B.String())
+	}
+	want := []string{"metadata_sequence=2", "metadata_timestamp=1", "metadata_timestamp_ms=1500"}
+	if len(got) != len(want) {
+		t.Fatalf("MetadataHypotheses = %v, expected %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MetadataHypotheses = %v, expected %v", got, want)
+			break
+		}
+	}
+
+	if hypotheses := MetadataHypotheses(&Signature{}, sig2); len(hypotheses) != 0 {
+		t.Errorf("Expected no hypotheses without metadata on both sides, got %d", len(hypotheses))
+	}
+}
+
+func TestSmartBruteForceStrategy_Hypotheses(t *testing.T) {
+	// A counter that advances once per block, far beyond the common patterns and early phases
+	d := big.NewInt(0xb10c)
+	height := func(n int64) *int64 { return &n }
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(99999), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(5555555), HashMessage([]byte("message 2"))),
+		signWithNonce(d, big.NewInt(99999+123456789), HashMessage([]byte("message 3"))),
+	}
+	signatures[0].BlockHeight = height(700000000)
+	signatures[1].BlockHeight = height(700000001)
+	signatures[2].BlockHeight = height(700000000 + 123456789)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	result := strategy.Search(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the key from the block height hypothesis, got %+v", result)
+	}
+	if result.Pattern != "metadata_block_height" || result.SignaturePair != [2]int{0, 2} || result.Relationship.B.Int64() != 123456789 {
+		t.Errorf("Unexpected result: pattern %s, pair %v, b %s", result.Pattern, result.SignaturePair, result.Relationship.B)
+	}
+}
+
+func TestSmartBruteForceStrategy_SuggestedPatterns(t *testing.T) {
+	// A nonce counter driven by a one-minute clock; only the unrelated signatures kept
+	// their signing times, so no pair's own metadata gives the step
+	d := big.NewInt(0x5ca1e)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var signatures []*Signature
+	for i, k := range []int64{1000003, 2000029, 3000017, 4000037} {
+		sig := signWithNonce(d, big.NewInt(k), HashMessage([]byte{byte(i)}))
+		sig.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		signatures = append(signatures, sig)
+	}
+	signatures = append(signatures,
+		signWithNonce(d, big.NewInt(77777777), HashMessage([]byte("untimed 1"))),
+		signWithNonce(d, big.NewInt(77777777+60), HashMessage([]byte("untimed 2"))),
+	)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{Hypotheses: MetadataHypotheses, SuggestedPatterns: true})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	result := strategy.Search(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the suggested step to recover the key, got %+v", result)
+	}
+	if result.Pattern != "metadata_timestamp_+60" || result.SignaturePair != [2]int{4, 5} {
+		t.Errorf("Expected metadata_timestamp_+60 on [4, 5], got %s on %v", result.Pattern, result.SignaturePair)
+	}
+
+	// Per-pair hypotheses alone do not find it
+	strategy = NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{Hypotheses: MetadataHypotheses})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	if result := strategy.Search(context.Background(), signatures, publicKey); result != nil {
+		t.Errorf("Expected no key without suggested patterns, got %+v", result)
+	}
+}
@@ -0,0 +1,87 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"sort"
+)
+
+// takeInjected returns first and the patterns waiting on PatternConfig.Inject, by
+// priority (stable), without blocking.
+func (s *SmartBruteForceStrategy) takeInjected(first []Pattern) []Pattern {
+	patterns := first
+	for more := true; more; {
+		select {
+		case next, ok := <-s.PatternConfig.Inject:
+			patterns = append(patterns, next...)
+			more = ok
+		default:
+			more = false
+		}
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return patterns[i].Priority < patterns[j].Priority })
+	return patterns
+}
+
+// tryInjected tries the patterns injected since the last safe point, if any. The search
+// calls it between phases and, through watchInjections, while a range phase runs.
+func (s *SmartBruteForceStrategy) tryInjected(ctx context.Context, signatures []*Signature, publicKey []byte, first ...Pattern) *RecoveryResult {
+	patterns := s.takeInjected(first)
+	if len(patterns) == 0 {
+		return nil
+	}
+	s.logger().Printf("Trying %d injected patterns...", len(patterns))
+	if result := s.tryPatternList(ctx, signatures, publicKey, patterns); result != nil {
+		s.logger().Printf("✅ Found injected pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+		return result
+	}
+	s.logger().Println("No injected patterns matched")
+	return nil
+}
+
+// watchInjections tries patterns injected while a range phase runs: the range workers
+// are held at their next batch, keeping their place, while the patterns are tried, and
+// resume after them. The phase runs with the returned context, which is cancelled when
+// an injected pattern finds the key; call stop when the phase returns for that key. An
+// injected pattern being tried when the phase ends is finished first.
+func (s *SmartBruteForceStrategy) watchInjections(ctx context.Context, signatures []*Signature, publicKey []byte) (phaseCtx context.Context, stop func() *RecoveryResult) {
+	if s.PatternConfig.Inject == nil {
+		return ctx, func() *RecoveryResult { return nil }
+	}
+
+	phaseCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	var found *RecoveryResult
+	go func() {
+		defer close(finished)
+		for {
+			var first []Pattern
+			select {
+			case patterns, ok := <-s.PatternConfig.Inject:
+				if !ok {
+					return
+				}
+				first = patterns
+			case <-done:
+				return
+			case <-phaseCtx.Done():
+				return
+			}
+			limiter := s.rangeThrottle()
+			limiter.hold()
+			result := s.tryInjected(ctx, signatures, publicKey, first...)
+			limiter.release()
+			if result != nil {
+				found = result
+				cancel()
+				return
+			}
+		}
+	}()
+	return phaseCtx, func() *RecoveryResult {
+		close(done)
+		<-finished
+		cancel()
+		return found
+	}
+}
@@ -0,0 +1,75 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSmartBruteForceStrategy_Inject(t *testing.T) {
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	unrelated := signatures[2:] // k = 7777777, 123456789, 987654321
+	step := PatternsForSteps([]int64{123456789 - 7777777})
+
+	// Injected before the search: tried at the first safe point, before any range phase
+	inject := make(chan []Pattern, 1)
+	inject <- step
+	strategy := quotaTestStrategy(nil, [2]int{0, 9})
+	strategy.PatternConfig.Inject = inject
+	result := strategy.Search(context.Background(), unrelated, publicKey)
+	if result == nil || result.Pattern != step[0].Name {
+		t.Fatalf("Expected the injected pattern to find the key, got %+v", result)
+	}
+
+	// Injected while a range phase runs: the phase is interrupted for the key
+	inject = make(chan []Pattern)
+	defer close(inject)
+	strategy = quotaTestStrategy(nil, [2]int{0, math.MaxInt32}) // fits int on 32-bit platforms
+	strategy.PatternConfig.Inject = inject
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		inject <- step
+	}()
+	result = strategy.Search(ctx, unrelated, publicKey)
+	if result == nil || result.Pattern != step[0].Name {
+		t.Fatalf("Expected the pattern injected during the range phase to find the key, got %+v", result)
+	}
+	if ctx.Err() != nil {
+		t.Error("Expected the key before the timeout")
+	}
+}
+
+func TestThrottle_Hold(t *testing.T) {
+	limiter := &throttle{}
+	limiter.hold()
+	released := make(chan struct{})
+	go func() {
+		limiter.wait(context.Background(), 1, 0)
+		close(released)
+	}()
+	select {
+	case <-released:
+		t.Fatal("Expected a held worker to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A pause lifted during a hold leaves the worker held
+	limiter.set(ThrottleSetting{Paused: true})
+	limiter.set(ThrottleSetting{})
+	select {
+	case <-released:
+		t.Fatal("Expected the worker to stay held after the pause was lifted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.release()
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the worker to resume on release")
+	}
+}
@@ -0,0 +1,106 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+func TestKangarooStrategy_Search(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f80", 16)
+
+	for _, step := range []int64{200000000, -123456789} {
+		k2 := new(big.Int).Add(k1, big.NewInt(step))
+		signatures := []*Signature{
+			signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+			signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+		}
+
+		strategy := NewKangarooStrategy(-1<<28, 1<<28)
+		strategy.Solver.Workers = 2
+		result := strategy.Search(context.Background(), signatures, nil)
+		if result == nil {
+			t.Fatalf("Expected recovery for step %d", step)
+		}
+		if result.PrivateKey.Cmp(d) != 0 {
+			t.Errorf("Expected private key %s, got %s", d, result.PrivateKey)
+		}
+		if result.Relationship.B.Int64() != step {
+			t.Errorf("Expected b=%d, got %s", step, result.Relationship.B)
+		}
+	}
+}
+
+func TestKangarooSolver_ReusesTameStore(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+
+	store := NewMemoryDPStore()
+	solver := NewKangarooSolver(0, 1<<26)
+	solver.Workers = 2
+	solver.Store = store
+
+	for _, step := range []int64{12345678, 56789012} {
+		k2 := new(big.Int).Add(k1, big.NewInt(step))
+		sig1 := signWithNonce(d, k1, HashMessage([]byte("a")))
+		sig2 := signWithNonce(d, k2, HashMessage([]byte("b")))
+
+		found := false
+		for _, b := range solver.Solve(context.Background(), sig1, sig2) {
+			if b.Int64() == step {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected candidate b=%d", step)
+		}
+	}
+	if store.Len() == 0 {
+		t.Error("Expected tame distinguished points in the shared store")
+	}
+}
+
+func TestKangarooSolver_SaveStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tame.tbl")
+	solver := NewKangarooSolver(0, 1<<26)
+	solver.Workers = 2
+	solver.Store.(*MemoryDPStore).Put(DistinguishedPoint{Key: 0xabc000, Log: 12345})
+
+	if err := solver.SaveStore(path); err != nil {
+		t.Fatalf("SaveStore: %v", err)
+	}
+	loaded, err := LoadKangarooSolver(path)
+	if err != nil {
+		t.Fatalf("LoadKangarooSolver: %v", err)
+	}
+	if loaded.Lower != 0 || loaded.Upper != 1<<26 || loaded.Workers != 2 {
+		t.Errorf("Parameters not restored: %+v", loaded)
+	}
+	if p, ok := loaded.Store.Get(0xabc000); !ok || p.Log != 12345 {
+		t.Errorf("Expected stored point, got %+v, %v", p, ok)
+	}
+}
+
+func TestKangarooStrategy_LoadStoreKeepsWorkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tame.tbl")
+	saved := NewKangarooSolver(0, 1<<26)
+	saved.Workers = 1
+	saved.Store.(*MemoryDPStore).Put(DistinguishedPoint{Key: 0xabc000, Log: 12345})
+	if err := saved.SaveStore(path); err != nil {
+		t.Fatalf("SaveStore: %v", err)
+	}
+
+	strategy := NewKangarooStrategy(0, 1<<26)
+	strategy.Solver.Workers = 3
+	strategy.Solver.Seed = 7
+	strategy.StorePath = path
+	strategy.loadStore()
+	if strategy.Solver.Workers != 3 || strategy.Solver.Seed != 7 {
+		t.Errorf("Expected the caller's workers and seed to stay, got %+v", strategy.Solver)
+	}
+	if p, ok := strategy.Solver.Store.Get(0xabc000); !ok || p.Log != 12345 {
+		t.Errorf("Expected the saved point in the solver's store, got %+v, %v", p, ok)
+	}
+}
@@ -0,0 +1,73 @@
+package ecdsaaffine
+
+import "sync"
+
+// keyCacheShards is the number of independently locked parts of a keyCache.
+const keyCacheShards = 64
+
+// keyCache remembers private key values that failed verification, so a key recovered
+// again from another candidate (a different pair, a or b) is rejected without a scalar
+// multiplication. Keys are stored exactly, never hashed, so a cached key is certainly
+// not the target's. The cache is sharded by key so workers rarely contend, and each
+// shard forgets its oldest key once full.
+type keyCache struct {
+	shards [keyCacheShards]keyCacheShard
+}
+
+type keyCacheShard struct {
+	mu   sync.Mutex
+	keys map[[32]byte]struct{}
+	ring [][32]byte // insertion order, oldest at next once full
+	next int
+}
+
+// newKeyCache returns a cache holding about size keys, or nil (no caching) for size <= 0.
+func newKeyCache(size int) *keyCache {
+	if size <= 0 {
+		return nil
+	}
+	perShard := max(size/keyCacheShards, 1)
+	c := &keyCache{}
+	for i := range c.shards {
+		c.shards[i].keys = make(map[[32]byte]struct{}, perShard)
+		c.shards[i].ring = make([][32]byte, 0, perShard)
+	}
+	return c
+}
+
+func (c *keyCache) shard(key *[32]byte) *keyCacheShard {
+	return &c.shards[key[31]%keyCacheShards]
+}
+
+// contains reports whether key was rejected before. A nil cache contains nothing.
+func (c *keyCache) contains(key *[32]byte) bool {
+	if c == nil {
+		return false
+	}
+	shard := c.shard(key)
+	shard.mu.Lock()
+	_, ok := shard.keys[*key]
+	shard.mu.Unlock()
+	return ok
+}
+
+// add records a rejected key, evicting its shard's oldest key if the shard is full.
+func (c *keyCache) add(key *[32]byte) {
+	if c == nil {
+		return
+	}
+	shard := c.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if _, ok := shard.keys[*key]; ok {
+		return
+	}
+	if len(shard.ring) < cap(shard.ring) {
+		shard.ring = append(shard.ring, *key)
+	} else {
+		delete(shard.keys, shard.ring[shard.next])
+		shard.ring[shard.next] = *key
+		shard.next = (shard.next + 1) % len(shard.ring)
+	}
+	shard.keys[*key] = struct{}{}
+}
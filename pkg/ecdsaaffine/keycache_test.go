@@ -0,0 +1,84 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestKeyCache(t *testing.T) {
+	keyOf := func(i int) *[32]byte {
+		var key [32]byte
+		big.NewInt(int64(i)).FillBytes(key[:])
+		return &key
+	}
+
+	// Two keys per shard; the third added to a shard evicts its first
+	cache := newKeyCache(2 * keyCacheShards)
+	for _, i := range []int{1, 1 + keyCacheShards, 1 + 2*keyCacheShards} {
+		cache.add(keyOf(i))
+	}
+	if cache.contains(keyOf(1)) {
+		t.Error("Expected the oldest key evicted")
+	}
+	for _, i := range []int{1 + keyCacheShards, 1 + 2*keyCacheShards} {
+		if !cache.contains(keyOf(i)) {
+			t.Errorf("Expected key %d cached", i)
+		}
+	}
+	if cache.contains(keyOf(2)) {
+		t.Error("Unexpected key in another shard")
+	}
+
+	var none *keyCache
+	none.add(keyOf(1))
+	if newKeyCache(0) != nil || none.contains(keyOf(1)) {
+		t.Error("Expected a zero-size cache to cache nothing")
+	}
+
+	// Workers share the cache; run with -race
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.add(keyOf(w*1000 + i))
+				cache.contains(keyOf(i))
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestSmartBruteForceStrategy_Verifier_SharesRejectedKeys(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	wrong := big.NewInt(12345)
+	var key [32]byte
+	wrong.FillBytes(key[:])
+
+	strategy := NewSmartBruteForceStrategy()
+	if strategy.verifier(publicKey).Verify(wrong) {
+		t.Fatal("Verified the wrong key")
+	}
+	if !strategy.verifier(publicKey).rejected.contains(&key) {
+		t.Error("Expected the rejected key shared with the next verifier")
+	}
+	if !strategy.verifier(publicKey).Verify(d) {
+		t.Error("Expected the right key verified with a cache")
+	}
+
+	// Another target starts over
+	other := secp256k1.PrivKeyFromBytes([]byte{0x02}).PubKey().SerializeCompressed()
+	if strategy.verifier(other).rejected.contains(&key) {
+		t.Error("Rejected keys carried over to another target")
+	}
+
+	strategy.RangeConfig.KeyCacheSize = 0
+	if strategy.verifier(publicKey).rejected != nil {
+		t.Error("Expected no cache with KeyCacheSize 0")
+	}
+}
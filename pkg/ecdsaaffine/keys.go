@@ -0,0 +1,323 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// RecoveryIDFromV converts an Ethereum v value to a public key recovery id: 0-3 as is,
+// 27/28 (legacy transactions and eth_sign) and chainID*2 + 35/36 (EIP-155).
+func RecoveryIDFromV(v *big.Int) (int, error) {
+	switch {
+	case v.Sign() < 0:
+		return 0, fmt.Errorf("invalid v %s", v)
+	case v.Cmp(big.NewInt(4)) < 0:
+		return int(v.Int64()), nil
+	case v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0:
+		return int(v.Int64() - 27), nil
+	case v.Cmp(big.NewInt(35)) >= 0:
+		return int(new(big.Int).Sub(v, big.NewInt(35)).Bit(0)), nil
+	}
+	return 0, fmt.Errorf("invalid v %s", v)
+}
+
+// RecoverPublicKey recovers the compressed public key of the signer of sig from its
+// recovery id.
+func RecoverPublicKey(sig *Signature) ([]byte, error) {
+	if sig.RecoveryID == nil {
+		return nil, errors.New("signature has no recovery id")
+	}
+	if *sig.RecoveryID < 0 || *sig.RecoveryID > 3 {
+		return nil, fmt.Errorf("invalid recovery id %d", *sig.RecoveryID)
+	}
+	if sig.R.Sign() <= 0 || sig.R.BitLen() > 256 || sig.S.Sign() <= 0 || sig.S.BitLen() > 256 {
+		return nil, errors.New("r or s out of range")
+	}
+
+	// Compact format: <27 + recovery id + 4 (compressed)><R><S>
+	compact := make([]byte, 65)
+	compact[0] = byte(27 + *sig.RecoveryID + 4)
+	sig.R.FillBytes(compact[1:33])
+	sig.S.FillBytes(compact[33:65])
+	pub, _, err := ecdsa.RecoverCompact(compact, sig.Z.FillBytes(make([]byte, 32)))
+	if err != nil {
+		return nil, err
+	}
+	return pub.SerializeCompressed(), nil
+}
+
+// SignerKey returns the signer's key from the signature's context: its PublicKey if set,
+// otherwise the key recovered from its RecoveryID, otherwise nil.
+func (s *Signature) SignerKey() []byte {
+	if len(s.PublicKey) > 0 {
+		return s.PublicKey
+	}
+	if s.RecoveryID != nil {
+		if pub, err := RecoverPublicKey(s); err == nil {
+			return pub
+		}
+	}
+	return nil
+}
+
+// SignatureGroup is the signatures in a dataset made by one signer.
+type SignatureGroup struct {
+	PublicKey  []byte       // signer key shared by the group (nil if the signatures carry none)
+	Signatures []*Signature // signatures in dataset order
+	Indices    []int        // position of each signature in the original dataset
+}
+
+// GroupByPublicKey splits signatures by signer (see Signature.SignerKey), in order of
+// first appearance. Only signatures by the same signer can share a nonce relationship,
+// so each group is searched on its own. A signer identified by an Ethereum address in
+// some records and by a public key in others forms two groups.
+func GroupByPublicKey(signatures []*Signature) []*SignatureGroup {
+	var groups []*SignatureGroup
+	byKey := make(map[string]*SignatureGroup)
+	for i, sig := range signatures {
+		key := sig.SignerKey()
+		if len(key) != 0 && len(key) != EthereumAddressLen {
+			if normalized, err := ParsePublicKey(key); err == nil {
+				key = normalized
+			}
+		}
+		group, ok := byKey[string(key)]
+		if !ok {
+			group = &SignatureGroup{PublicKey: key}
+			byKey[string(key)] = group
+			groups = append(groups, group)
+		}
+		group.Signatures = append(group.Signatures, sig)
+		group.Indices = append(group.Indices, i)
+	}
+	return groups
+}
+
+// KeyResult is the outcome of recovery for one signer of a mixed-key dataset.
+type KeyResult struct {
+	PublicKey  []byte          // the signer's key (nil for signatures without signer context)
+	Signatures int             // number of signatures by this signer
+	Result     *RecoveryResult // nil if no key was recovered; SignaturePair indexes the whole dataset
+}
+
+// RecoverKeys recovers keys from a file whose signatures may come from many signers.
+// See RecoverKeysFromSignatures.
+func (c *Client) RecoverKeys(ctx context.Context, source string) ([]*KeyResult, error) {
+	signatures, err := c.parser.ParseSignatures(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	return c.RecoverKeysFromSignatures(ctx, signatures)
+}
+
+// RecoverKeysFromSignatures groups signatures by signer and runs the strategy on each
+// group, verifying candidates against the signer's key. Signers that share a nonce with
+// another signer are then recovered from it, even with a single signature (see
+// FindSharedNonces). It returns one KeyResult per signer, in order of first appearance;
+// signers whose key was not recovered are reported with a nil Result.
+func (c *Client) RecoverKeysFromSignatures(ctx context.Context, signatures []*Signature) ([]*KeyResult, error) {
+	if len(signatures) < 2 {
+		return nil, fmt.Errorf("need at least 2 signatures, got %d", len(signatures))
+	}
+
+	var results []*KeyResult
+	groups := GroupByPublicKey(signatures)
+	for _, group := range groups {
+		keyResult := &KeyResult{PublicKey: group.PublicKey, Signatures: len(group.Signatures)}
+		results = append(results, keyResult)
+		if len(group.Signatures) < 2 {
+			continue
+		}
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		keyResult.Result = group.remap(c.strategy.Search(ctx, group.Signatures, group.PublicKey))
+	}
+	recoverSharedNonces(signatures, groups, results)
+	for i, keyResult := range results {
+		if keyResult.Result != nil && c.crossCheck != nil {
+			crossCheck(keyResult.Result, signatures, groups[i].Indices, *c.crossCheck)
+		}
+	}
+	return results, nil
+}
+
+// FindSharedNonces returns the pairs of signatures (i < j, indexes into signatures) by
+// different signers (see GroupByPublicKey) with the same r: the two signers used the same
+// nonce, or its negation. Signatures without signer context are skipped.
+func FindSharedNonces(signatures []*Signature) [][2]int {
+	signer := signerIndex(GroupByPublicKey(signatures), len(signatures))
+	var pairs [][2]int
+	byR := make(map[string][]int)
+	for j, sig := range signatures {
+		if signer[j] < 0 {
+			continue
+		}
+		r := string(sig.R.Bytes())
+		for _, i := range byR[r] {
+			if signer[i] != signer[j] {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+		byR[r] = append(byR[r], j)
+	}
+	return pairs
+}
+
+// signerIndex maps each signature to its group, or -1 for the group without signer key.
+func signerIndex(groups []*SignatureGroup, n int) []int {
+	signer := make([]int, n)
+	for g, group := range groups {
+		for _, i := range group.Indices {
+			signer[i] = g
+			if len(group.PublicKey) == 0 {
+				signer[i] = -1
+			}
+		}
+	}
+	return signer
+}
+
+// recoverSharedNonces fills in the results of signers that share a nonce with another
+// signer (see FindSharedNonces), including signers with a single signature: a recovered
+// key gives the shared nonce and with it the other signer's key, and two nonces shared by
+// the same two signers give both keys (RecoverTwoKeysSharedNonce). It repeats until no
+// more keys are found, so a key propagates along chains of signers.
+func recoverSharedNonces(signatures []*Signature, groups []*SignatureGroup, results []*KeyResult) {
+	pairs := FindSharedNonces(signatures)
+	signer := signerIndex(groups, len(signatures))
+	for progress := true; progress; {
+		progress = false
+		for _, pair := range pairs {
+			for _, p := range [][2]int{pair, {pair[1], pair[0]}} {
+				known, unknown := results[signer[p[0]]], results[signer[p[1]]]
+				if known.Result == nil || unknown.Result != nil {
+					continue
+				}
+				k, err := RecoverNonce(signatures[p[0]], known.Result.PrivateKey)
+				if err != nil {
+					continue
+				}
+				for _, a := range []int64{1, -1} {
+					d, err := RecoverPrivateKeyFromKnownNonce(signatures[p[1]], new(big.Int).Mul(k, big.NewInt(a)))
+					if err != nil {
+						continue
+					}
+					if ok, _ := VerifyRecoveredKey(d, unknown.PublicKey); ok {
+						unknown.Result = sharedNonceResult(d, a, p, "shared_nonce_cross_key")
+						progress = true
+						break
+					}
+				}
+			}
+		}
+
+		for x, p := range pairs {
+			for _, q := range pairs[x+1:] {
+				if signer[p[0]] == signer[q[1]] && signer[p[1]] == signer[q[0]] {
+					q = [2]int{q[1], q[0]}
+				}
+				if signer[p[0]] != signer[q[0]] || signer[p[1]] != signer[q[1]] {
+					continue
+				}
+				first, second := results[signer[p[0]]], results[signer[p[1]]]
+				if first.Result != nil || second.Result != nil {
+					continue
+				}
+				d1, d2, err := RecoverTwoKeysSharedNonce(signatures[p[0]], signatures[p[1]], signatures[q[0]], signatures[q[1]])
+				if err != nil {
+					continue
+				}
+				k1, _ := RecoverNonce(signatures[p[0]], d1)
+				k2, _ := RecoverNonce(signatures[p[1]], d2)
+				a := int64(1)
+				if k1.Cmp(k2) != 0 {
+					a = -1
+				}
+				first.Result = sharedNonceResult(d1, a, p, "shared_nonce_two_keys")
+				second.Result = sharedNonceResult(d2, a, p, "shared_nonce_two_keys")
+				progress = true
+			}
+		}
+	}
+}
+
+// sharedNonceResult is the result for a key recovered from a nonce shared across signers:
+// the pair's nonces are k and a*k.
+func sharedNonceResult(privateKey *big.Int, a int64, pair [2]int, pattern string) *RecoveryResult {
+	return &RecoveryResult{
+		PrivateKey:    privateKey,
+		Relationship:  AffineRelationship{A: big.NewInt(a), B: big.NewInt(0)},
+		SignaturePair: pair,
+		Verified:      true,
+		Pattern:       pattern,
+	}
+}
+
+// searchByKey runs the strategy on signatures, honouring per-signature signer context.
+// A dataset with one signer is searched as a whole, verified against publicKey or else
+// the signer's own key. With several signers, each group is searched separately (only
+// the group matching publicKey, if one is given) and the first recovered key is returned.
+func (c *Client) searchByKey(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	groups := GroupByPublicKey(signatures)
+	if len(groups) == 1 {
+		if len(publicKey) == 0 {
+			publicKey = groups[0].PublicKey
+		}
+		return c.strategy.Search(ctx, signatures, publicKey)
+	}
+
+	for _, group := range groups {
+		if len(group.Signatures) < 2 || (len(publicKey) > 0 && !sameSigner(group.PublicKey, publicKey)) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		verifyAgainst := group.PublicKey
+		if len(publicKey) > 0 {
+			verifyAgainst = publicKey
+		}
+		if result := group.remap(c.strategy.Search(ctx, group.Signatures, verifyAgainst)); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// remap translates a result's SignaturePair from group positions to dataset positions.
+func (g *SignatureGroup) remap(result *RecoveryResult) *RecoveryResult {
+	if result == nil {
+		return nil
+	}
+	remapped := *result
+	remapped.SignaturePair = [2]int{g.Indices[result.SignaturePair[0]], g.Indices[result.SignaturePair[1]]}
+	return &remapped
+}
+
+// sameSigner reports whether two normalized keys or verification targets (see
+// ParseTarget) identify the same signer. At least one side must be a 33-byte compressed
+// key unless the two are identical.
+func sameSigner(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	if len(a) != 33 {
+		a, b = b, a
+	}
+	if len(a) != 33 {
+		return false
+	}
+	pub, err := secp256k1.ParsePubKey(a)
+	if err != nil {
+		return false
+	}
+	ok, err := matchesPublicKey(pub, b)
+	return err == nil && ok
+}
@@ -0,0 +1,221 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// signWithRecoveryID signs like signWithNonce and sets the recovery id of the signature.
+func signWithRecoveryID(d, k, z *big.Int) *Signature {
+	sig := signWithNonce(d, k, z)
+	R := secp256k1.PrivKeyFromBytes(k.Bytes()).PubKey()
+	id := int(R.Y().Bit(0))
+	if R.X().Cmp(Secp256k1CurveOrder) >= 0 {
+		id |= 2
+	}
+	sig.RecoveryID = &id
+	return sig
+}
+
+func TestRecoveryIDFromV(t *testing.T) {
+	for v, want := range map[int64]int{0: 0, 1: 1, 3: 3, 27: 0, 28: 1, 37: 0, 38: 1, 2*137 + 35: 0, 2*137 + 36: 1} {
+		if got, err := RecoveryIDFromV(big.NewInt(v)); err != nil || got != want {
+			t.Errorf("RecoveryIDFromV(%d) = %d, %v; expected %d", v, got, err, want)
+		}
+	}
+	for _, v := range []int64{-1, 4, 26, 29, 34} {
+		if _, err := RecoveryIDFromV(big.NewInt(v)); err == nil {
+			t.Errorf("RecoveryIDFromV(%d): expected an error", v)
+		}
+	}
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	want := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	k, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+
+	sig := signWithRecoveryID(d, k, HashMessage([]byte("message")))
+	got, err := RecoverPublicKey(sig)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("RecoverPublicKey = %x, %v; expected %x", got, err, want)
+	}
+	if !bytes.Equal(sig.SignerKey(), want) {
+		t.Error("Expected SignerKey to use the recovery id")
+	}
+
+	*sig.RecoveryID ^= 1
+	if got, _ := RecoverPublicKey(sig); bytes.Equal(got, want) {
+		t.Error("Expected the other recovery id to give another key")
+	}
+	sig.RecoveryID = nil
+	if _, err := RecoverPublicKey(sig); err == nil {
+		t.Error("Expected an error without a recovery id")
+	}
+}
+
+// mixedKeyDataset interleaves signatures by three signers: signer 1 uses counter nonces
+// and is identified only by recovery ids, signer 2 reuses a nonce and carries an
+// uncompressed public key, signer 3 signs once. All chains start from the same nonce, so
+// pairs across signers share r and must never be paired.
+func mixedKeyDataset() ([]*Signature, []*big.Int) {
+	keys := []*big.Int{big.NewInt(0xdeadbeef), big.NewInt(0xc0ffee), big.NewInt(0xbad)}
+	k, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2 := new(big.Int).Add(k, big.NewInt(1))
+	k3 := new(big.Int).Add(k, big.NewInt(2))
+	z := func(m string) *big.Int { return HashMessage([]byte(m)) }
+
+	uncompressed := secp256k1.PrivKeyFromBytes(keys[1].Bytes()).PubKey().SerializeUncompressed()
+	reused := []*Signature{signWithNonce(keys[1], k, z("b1")), signWithNonce(keys[1], k, z("b2"))}
+	for _, sig := range reused {
+		sig.PublicKey = uncompressed
+	}
+	single := signWithNonce(keys[2], k, z("c1"))
+	single.PublicKey = secp256k1.PrivKeyFromBytes(keys[2].Bytes()).PubKey().SerializeCompressed()
+
+	return []*Signature{
+		signWithRecoveryID(keys[0], k, z("a1")),
+		reused[0],
+		single,
+		signWithRecoveryID(keys[0], k2, z("a2")),
+		reused[1],
+		signWithRecoveryID(keys[0], k3, z("a3")),
+	}, keys
+}
+
+func TestGroupByPublicKey(t *testing.T) {
+	signatures, keys := mixedKeyDataset()
+	groups := GroupByPublicKey(signatures)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 signers, got %d", len(groups))
+	}
+	want := [][]int{{0, 3, 5}, {1, 4}, {2}}
+	for g, group := range groups {
+		if !bytes.Equal(group.PublicKey, secp256k1.PrivKeyFromBytes(keys[g].Bytes()).PubKey().SerializeCompressed()) {
+			t.Errorf("Group %d has the wrong (or unnormalized) public key %x", g, group.PublicKey)
+		}
+		if len(group.Indices) != len(want[g]) {
+			t.Fatalf("Group %d: expected indices %v, got %v", g, want[g], group.Indices)
+		}
+		for i, idx := range group.Indices {
+			if idx != want[g][i] {
+				t.Errorf("Group %d: expected indices %v, got %v", g, want[g], group.Indices)
+			}
+		}
+	}
+}
+
+func TestClient_RecoverKeysFromSignatures(t *testing.T) {
+	signatures, keys := mixedKeyDataset()
+	results, err := NewClient().RecoverKeysFromSignatures(context.Background(), signatures)
+	if err != nil {
+		t.Fatalf("RecoverKeysFromSignatures failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for g, keyResult := range results[:2] {
+		result := keyResult.Result
+		if result == nil {
+			t.Fatalf("Signer %d: no key recovered", g)
+		}
+		if result.PrivateKey.Cmp(keys[g]) != 0 || !result.Verified {
+			t.Errorf("Signer %d: expected verified key %s, got %s (verified=%v)", g, keys[g], result.PrivateKey, result.Verified)
+		}
+	}
+	if pair := results[1].Result.SignaturePair; pair != [2]int{1, 4} {
+		t.Errorf("Expected signer 2's pair indexed into the dataset as [1, 4], got %v", pair)
+	}
+	// Signer 3 signed once, with the nonce of signer 1's first signature
+	if result := results[2].Result; result == nil || result.PrivateKey.Cmp(keys[2]) != 0 ||
+		result.SignaturePair != [2]int{0, 2} || result.Pattern != "shared_nonce_cross_key" {
+		t.Errorf("Expected signer 3's key from the nonce shared with signature 0, got %+v", result)
+	}
+}
+
+// sharedNonceDataset is two signers that signed with the same two unrelated nonces, the
+// second signer normalizing s to the lower half as Bitcoin and Ethereum signers do.
+func sharedNonceDataset() ([]*Signature, []*big.Int) {
+	keys := []*big.Int{big.NewInt(0xdeadbeef), big.NewInt(0xc0ffee)}
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2, _ := new(big.Int).SetString("9a7b3c1d2e4f5061728394a5b6c7d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f", 16)
+	halfOrder := new(big.Int).Rsh(Secp256k1CurveOrder, 1)
+
+	var signatures []*Signature
+	for i, k := range []*big.Int{k1, k2} {
+		for signer, d := range keys {
+			sig := signWithNonce(d, k, HashMessage([]byte(fmt.Sprintf("signer %d message %d", signer, i))))
+			sig.PublicKey = secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+			if signer == 1 && sig.S.Cmp(halfOrder) > 0 {
+				sig.S.Sub(Secp256k1CurveOrder, sig.S)
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	return signatures, keys
+}
+
+func TestFindSharedNonces(t *testing.T) {
+	signatures, _ := mixedKeyDataset()
+	// Signatures 0, 1, 2 and 4 use the same nonce; 1 and 4 are by the same signer
+	want := [][2]int{{0, 1}, {0, 2}, {1, 2}, {0, 4}, {2, 4}}
+	if got := FindSharedNonces(signatures); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected shared nonces %v, got %v", want, got)
+	}
+
+	// Without signer context, signers cannot be told apart
+	for _, sig := range signatures {
+		sig.PublicKey, sig.RecoveryID = nil, nil
+	}
+	if got := FindSharedNonces(signatures); len(got) != 0 {
+		t.Errorf("Expected no shared nonces without signer keys, got %v", got)
+	}
+}
+
+func TestClient_RecoverKeysFromSignatures_SharedNonces(t *testing.T) {
+	signatures, keys := sharedNonceDataset()
+
+	// Each signer's own nonces are unrelated, so only the shared nonces give the keys
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	results, err := NewClient().WithStrategy(strategy).RecoverKeysFromSignatures(context.Background(), signatures)
+	if err != nil {
+		t.Fatalf("RecoverKeysFromSignatures failed: %v", err)
+	}
+	for g, keyResult := range results {
+		result := keyResult.Result
+		if result == nil || result.PrivateKey.Cmp(keys[g]) != 0 || !result.Verified || result.Pattern != "shared_nonce_two_keys" {
+			t.Errorf("Signer %d: expected key %s from the shared nonces, got %+v", g, keys[g], result)
+		}
+	}
+}
+
+func TestClient_RecoverKeyFromSignatures_MixedKeys(t *testing.T) {
+	signatures, keys := mixedKeyDataset()
+	client := NewClient()
+
+	result, err := client.RecoverKeyFromSignatures(context.Background(), signatures, "")
+	if err != nil {
+		t.Fatalf("RecoverKeyFromSignatures failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(keys[0]) != 0 || !result.Verified {
+		t.Errorf("Expected verified key %s, got %s (verified=%v)", keys[0], result.PrivateKey, result.Verified)
+	}
+
+	// An Ethereum address selects its signer among the per-signature public keys
+	address := hex.EncodeToString(EthereumAddress(keys[1]))
+	result, err = client.RecoverKeyFromSignatures(context.Background(), signatures, address)
+	if err != nil {
+		t.Fatalf("RecoverKeyFromSignatures failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(keys[1]) != 0 || result.SignaturePair != [2]int{1, 4} {
+		t.Errorf("Expected key %s from pair [1, 4], got %s from %v", keys[1], result.PrivateKey, result.SignaturePair)
+	}
+}
@@ -0,0 +1,148 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// maxStepGap is the largest jump in b (e.g. over candidates a CandidateFilter rejected)
+// a keyStepper covers by point additions before it starts over with a scalar
+// multiplication.
+const maxStepGap = 16
+
+// screenWindow is the most candidates a keyStepper steps to and screens at once.
+const screenWindow = 32
+
+// keyStepper screens the range search candidates of one signature pair against a target
+// public key without a scalar multiplication per candidate.
+//
+// For a fixed a, the key recovered from the pair is affine in b: d(b) = d(b0) + (b-b0)·δ
+// mod n, where δ = d(b0+1) - d(b0) does not depend on b. So d(b)·G follows from
+// d(b-1)·G by adding δ·G, a single point addition, mixed since δ·G is kept in affine
+// coordinates. The sums are kept in Jacobian coordinates and their x coordinates
+// compared with the target's (X = x·Z², one squaring and one multiplication), which
+// rules out all but the matching candidate (and one whose key is its negation). Only
+// candidates that pass are recovered and verified in full.
+//
+// The stepper works a window of consecutive b ahead: it adds up to screenWindow points
+// and compares them in one screenX call, which runs in assembly on amd64 and arm64.
+// Windows start at one candidate after each scalar multiplication and double, so a
+// short run of b wastes few additions past its end. The point addition is about a dozen
+// field multiplications, so a core screens on the order of a million candidates per
+// second (BenchmarkKeyStepper). For counter nonces (a = 1) with b ranges too wide for
+// that, BSGSStrategy and KangarooStrategy find b without enumerating it.
+type keyStepper struct {
+	sig1, sig2 *Signature
+	recovery   *pairRecovery // computes d(b) and δ, if set; RecoverPrivateKey otherwise
+	targetX    fieldLimbs
+
+	a     int64
+	valid bool  // point holds d(b)·G for the b after the window and step δ·G, for a
+	ready bool  // a is set
+	start int64 // b of the window's first candidate
+	size  int   // candidates in the window, whose verdicts match holds
+	next  int   // size of the next window
+	point secp256k1.JacobianPoint
+	step  secp256k1.JacobianPoint
+	xs    [screenWindow]fieldLimbs
+	zs    [screenWindow]fieldLimbs
+	match [screenWindow]bool
+}
+
+// newKeyStepper returns a stepper for the pair (sig1, sig2), or nil when the verifier's
+// target is not a public key (addresses and other targets are verified in full).
+// recovery, if not nil, is the pair's pairRecovery.
+func newKeyStepper(sig1, sig2 *Signature, verifier *KeyVerifier, recovery *pairRecovery) *keyStepper {
+	if verifier == nil || verifier.point == nil {
+		return nil
+	}
+	k := &keyStepper{sig1: sig1, sig2: sig2, recovery: recovery}
+	targetX := verifier.point.X
+	k.targetX.setField(targetX.Normalize())
+	return k
+}
+
+// mayMatch reports whether the key recovered for (a, b) can be the target's. Calls for
+// the same a with increasing b (the range search's enumeration order) cost a point
+// addition each; other calls start over with a scalar multiplication.
+func (k *keyStepper) mayMatch(a, b int64) bool {
+	end := k.start + int64(k.size)
+	if !k.ready || a != k.a || b < k.start || b-end > maxStepGap {
+		k.reset(a, b)
+		end = b
+	}
+	if !k.valid {
+		// Let the full check decide (and reject) what the stepper cannot represent
+		k.start = b
+		return true
+	}
+	for b >= end {
+		k.fill(end)
+		end = k.start + int64(k.size)
+	}
+	return k.match[b-k.start]
+}
+
+// fill steps to the next window, of candidates from start on, and screens it.
+func (k *keyStepper) fill(start int64) {
+	k.start, k.size = start, k.next
+	if k.next < screenWindow {
+		k.next *= 2
+	}
+	var sum secp256k1.JacobianPoint
+	for i := 0; i < k.size; i++ {
+		k.xs[i].setField(&k.point.X)
+		k.zs[i].setField(&k.point.Z)
+		secp256k1.AddNonConst(&k.point, &k.step, &sum)
+		k.point.Set(&sum)
+	}
+	screenX(&k.targetX, k.xs[:k.size], k.zs[:k.size], k.match[:k.size])
+	for i := 0; i < k.size; i++ {
+		if k.zs[i] == (fieldLimbs{}) {
+			// The point at infinity has no x to screen
+			k.match[i] = true
+		}
+	}
+}
+
+// reset computes d(b)·G and δ·G for (a, b) with scalar multiplications, and empties
+// the window.
+func (k *keyStepper) reset(a, b int64) {
+	k.a, k.start, k.size, k.next, k.ready, k.valid = a, b, 0, 1, true, false
+	if k.recovery != nil {
+		var d0, delta [32]byte
+		if !k.recovery.step(a, b, &d0, &delta) {
+			return
+		}
+		var scalar secp256k1.ModNScalar
+		scalar.SetBytes(&d0)
+		secp256k1.ScalarBaseMultNonConst(&scalar, &k.point)
+		scalar.SetBytes(&delta)
+		secp256k1.ScalarBaseMultNonConst(&scalar, &k.step)
+		k.step.ToAffine()
+		k.valid = true
+		return
+	}
+
+	aBig := big.NewInt(a)
+	d0, err := RecoverPrivateKey(k.sig1, k.sig2, aBig, big.NewInt(b))
+	if err != nil {
+		// The denominator depends on a alone, so no b works for this a
+		return
+	}
+	d1, err := RecoverPrivateKey(k.sig1, k.sig2, aBig, big.NewInt(b+1))
+	if err != nil {
+		return
+	}
+	delta := new(big.Int).Sub(d1, d0)
+	delta.Mod(delta, Secp256k1CurveOrder)
+
+	var scalar secp256k1.ModNScalar
+	scalar.SetByteSlice(d0.Bytes())
+	secp256k1.ScalarBaseMultNonConst(&scalar, &k.point)
+	scalar.SetByteSlice(delta.Bytes())
+	secp256k1.ScalarBaseMultNonConst(&scalar, &k.step)
+	k.step.ToAffine()
+	k.valid = true
+}
@@ -0,0 +1,62 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// TestKeyStepper walks a grid in range search order, with gaps and restarts, and checks
+// the stepper passes the key's candidate and rejects all others.
+func TestKeyStepper(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	k1 := big.NewInt(987654321)
+	k2 := new(big.Int).Mul(k1, big.NewInt(3))
+	k2.Add(k2, big.NewInt(-57))
+	sig1 := signWithNonce(d, k1, HashMessage([]byte("message 1")))
+	sig2 := signWithNonce(d, k2, HashMessage([]byte("message 2")))
+
+	// Both ways of computing the stepper's keys, math/big and pairRecovery
+	for _, recovery := range []*pairRecovery{nil, newPairRecovery(sig1, sig2)} {
+		name := "big"
+		if recovery != nil {
+			name = "fixed"
+		}
+		stepper := newKeyStepper(sig1, sig2, newKeyVerifier(publicKey), recovery)
+		if stepper == nil {
+			t.Fatalf("%s: expected a stepper for a compressed public key", name)
+		}
+		passed := 0
+		for a := int64(-3); a <= 3; a++ {
+			for b := int64(-100); b <= 100; b++ {
+				if b%7 == 0 || (b > 20 && b < 40) {
+					continue // gaps within and beyond maxStepGap
+				}
+				if !stepper.mayMatch(a, b) {
+					continue
+				}
+				passed++
+				priv, err := RecoverPrivateKey(sig1, sig2, big.NewInt(a), big.NewInt(b))
+				if err != nil || (a == 3 && b == -57) != (priv.Cmp(d) == 0) {
+					t.Errorf("%s: a=%d b=%d passed the stepper but recovers %v, %v", name, a, b, priv, err)
+				}
+			}
+		}
+		if passed != 1 {
+			t.Errorf("%s: %d candidates passed, want only the key's", name, passed)
+		}
+
+		// Out of order and repeated candidates start over and still pass the key
+		for _, b := range []int64{-57, -57, -60} {
+			if got := stepper.mayMatch(3, b); got != (b == -57) {
+				t.Errorf("%s: mayMatch(3, %d) = %v", name, b, got)
+			}
+		}
+	}
+
+	if newKeyStepper(sig1, sig2, newKeyVerifier(ethereumAddress(secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey())), nil) != nil {
+		t.Error("Expected no stepper for an address")
+	}
+}
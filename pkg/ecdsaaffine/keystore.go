@@ -0,0 +1,350 @@
+package ecdsaaffine
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	_ "crypto/sha1" // registered for the SHA*withECDSA algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
+)
+
+// KeystoreParser parses the DER signatures of the Java Signature API, as Android
+// Keystore returns them and attestation and integrity logs record them next to the
+// payload signed, so a device fleet's logs can be searched for nonce reuse without
+// conversion.
+//
+// The input is a JSON array of objects, or JSON Lines:
+//
+//	{"signature": "MEUCIQ...", "payload": "eyJub25jZSI6...", "certificate": "MIIC..."}
+//
+// signature is a base64 DER ECDSA-Sig-Value and payload the base64 bytes signed. The
+// optional algorithm is the JCA name the app signed with: SHA256withECDSA (the
+// default), SHA1withECDSA to SHA512withECDSA, or NONEwithECDSA for a payload that is
+// already the digest. The digest is truncated to the bit length of the curve order (see
+// TruncateDigest). The optional certificate (base64 DER or PEM, e.g. the leaf of an
+// attestation chain) or public_key (hex as for JSONParser, or a base64 DER
+// SubjectPublicKeyInfo) identifies the signer; the v, timestamp, sequence and
+// block_height fields are read as JSONParser reads them.
+type KeystoreParser struct {
+	// Order is the order n of the signing curve. By default it is the order of the
+	// curve of the record's certificate or public key, and Secp256k1CurveOrder for
+	// records with neither. Android Keystore keys are usually secp256r1 (P-256), which
+	// the search does not support yet: their signatures are parsed with the P-256 order
+	// and no public key.
+	Order *big.Int
+}
+
+// ParseSignatures parses signatures from a keystore log, given as a path or URI as for
+// JSONParser.
+func (p *KeystoreParser) ParseSignatures(logFile string) ([]*Signature, error) {
+	file, err := source.Open(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return p.Parse(file)
+}
+
+// Parse parses signatures in the format ParseSignatures reads from r.
+func (p *KeystoreParser) Parse(r io.Reader) ([]*Signature, error) {
+	items, err := decodeJSONItems(r)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]*Signature, 0, len(items))
+	for k, item := range items {
+		sig, err := p.parseItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", k+1, err)
+		}
+		signatures = append(signatures, sig)
+	}
+	return signatures, nil
+}
+
+// keystoreHashes are the hashes of the JCA SHA*withECDSA algorithms, by upper-case name.
+var keystoreHashes = map[string]crypto.Hash{
+	"SHA1WITHECDSA":   crypto.SHA1,
+	"SHA224WITHECDSA": crypto.SHA224,
+	"SHA256WITHECDSA": crypto.SHA256,
+	"SHA384WITHECDSA": crypto.SHA384,
+	"SHA512WITHECDSA": crypto.SHA512,
+}
+
+// parseItem parses one record.
+func (p *KeystoreParser) parseItem(item map[string]interface{}) (*Signature, error) {
+	der, err := base64Field(item, "signature")
+	if err != nil {
+		return nil, err
+	}
+	if der == nil {
+		return nil, fmt.Errorf("missing signature field")
+	}
+	r, s, err := ParseDERSignature(der)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := base64Field(item, "payload")
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("missing payload field")
+	}
+
+	sig := &Signature{R: r, S: s}
+	order, err := p.parseSigner(item, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := "SHA256withECDSA"
+	if val, ok := item["algorithm"]; ok && val != nil {
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("algorithm field must be a string")
+		}
+		algorithm = str
+	}
+	digest := payload
+	if !strings.EqualFold(algorithm, "NONEwithECDSA") {
+		hash, ok := keystoreHashes[strings.ToUpper(algorithm)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
+		}
+		h := hash.New()
+		h.Write(payload)
+		digest = h.Sum(nil)
+	} else if len(digest) == 0 {
+		return nil, fmt.Errorf("NONEwithECDSA: empty digest")
+	}
+	sig.Z = TruncateDigest(digest, order)
+
+	// The other signer fields are JSONParser's; public_key is parsed above
+	context := make(map[string]interface{}, len(item))
+	for field, val := range item {
+		if field != "public_key" {
+			context[field] = val
+		}
+	}
+	if err := (&JSONParser{}).parseSignerContext(context, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// parseSigner reads the certificate or public_key of a record, sets sig.PublicKey for a
+// secp256k1 key, and returns the order of the signing curve.
+func (p *KeystoreParser) parseSigner(item map[string]interface{}, sig *Signature) (*big.Int, error) {
+	order := p.Order
+	if order == nil {
+		order = Secp256k1CurveOrder
+	}
+
+	var spki []byte
+	var err error
+	if val, ok := item["public_key"]; ok && val != nil {
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("public_key field must be a string")
+		}
+		if _, err := hex.DecodeString(strings.TrimPrefix(str, "0x")); err == nil {
+			publicKey, err := ParsePublicKeyHex(str)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse public_key: %w", err)
+			}
+			sig.PublicKey = publicKey
+			return order, nil
+		}
+		if spki, err = decodeBase64(str); err != nil {
+			return nil, fmt.Errorf("public_key field must be hex or base64: %w", err)
+		}
+	} else if val, ok := item["certificate"]; ok && val != nil {
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("certificate field must be a string")
+		}
+		var cert []byte
+		cert, err = decodeCertificate(str)
+		if err != nil {
+			return nil, err
+		}
+		if spki, err = certificatePublicKeyInfo(cert); err != nil {
+			return nil, err
+		}
+	}
+	if spki == nil {
+		return order, nil
+	}
+
+	curve, point, err := parseECPublicKeyInfo(spki)
+	if err != nil {
+		return nil, err
+	}
+	if curve.Equal(oidSecp256k1) {
+		publicKey, err := ParsePublicKey(point)
+		if err != nil {
+			return nil, err
+		}
+		sig.PublicKey = publicKey
+		return order, nil
+	}
+	curveOrder, ok := namedCurveOrder(curve)
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve %v", curve)
+	}
+	if p.Order == nil {
+		order = curveOrder
+	}
+	return order, nil
+}
+
+// ecdsaSigValue is the ASN.1 ECDSA-Sig-Value of SEC1 and RFC 3279.
+type ecdsaSigValue struct {
+	R, S *big.Int
+}
+
+// ParseDERSignature decodes a DER ECDSA-Sig-Value, the SEQUENCE of r and s that the Java
+// Signature API, OpenSSL and most X.509 tooling produce.
+func ParseDERSignature(der []byte) (r, s *big.Int, err error) {
+	var value ecdsaSigValue
+	rest, err := asn1.Unmarshal(der, &value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid DER signature: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, nil, fmt.Errorf("invalid DER signature: %d trailing bytes", len(rest))
+	}
+	if value.R.Sign() <= 0 || value.S.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("invalid DER signature: r and s must be positive")
+	}
+	return value.R, value.S, nil
+}
+
+var (
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1   = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// namedCurves are the curves with a named-curve OID, other than secp256k1, whose keys a
+// record may carry.
+var namedCurves = []struct {
+	oid   asn1.ObjectIdentifier
+	curve elliptic.Curve
+}{
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 33}, elliptic.P224()},
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}, elliptic.P256()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 34}, elliptic.P384()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 35}, elliptic.P521()},
+}
+
+// namedCurveOrder returns the order of the curve named by oid.
+func namedCurveOrder(oid asn1.ObjectIdentifier) (*big.Int, bool) {
+	for _, named := range namedCurves {
+		if named.oid.Equal(oid) {
+			return named.curve.Params().N, true
+		}
+	}
+	return nil, false
+}
+
+// publicKeyInfo is an X.509 SubjectPublicKeyInfo, with the parameters of an EC key.
+type publicKeyInfo struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.RawValue `asn1:"optional"`
+	}
+	PublicKey asn1.BitString
+}
+
+// parseECPublicKeyInfo returns the named curve and the encoded point of a DER
+// SubjectPublicKeyInfo. crypto/x509 is not used as it rejects secp256k1 keys.
+func parseECPublicKeyInfo(der []byte) (asn1.ObjectIdentifier, []byte, error) {
+	var info publicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		return nil, nil, fmt.Errorf("invalid SubjectPublicKeyInfo: %v", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidECPublicKey) {
+		return nil, nil, fmt.Errorf("public key algorithm %v is not EC", info.Algorithm.Algorithm)
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil {
+		return nil, nil, fmt.Errorf("EC public key without a named curve: %v", err)
+	}
+	return curve, info.PublicKey.RightAlign(), nil
+}
+
+// certificate is the start of an X.509 certificate, up to its public key; encoding/asn1
+// ignores the fields after it.
+type certificate struct {
+	TBSCertificate struct {
+		Version            int `asn1:"optional,explicit,default:0,tag:0"`
+		SerialNumber       *big.Int
+		SignatureAlgorithm asn1.RawValue
+		Issuer             asn1.RawValue
+		Validity           asn1.RawValue
+		Subject            asn1.RawValue
+		PublicKey          asn1.RawValue
+	}
+}
+
+// certificatePublicKeyInfo returns the DER SubjectPublicKeyInfo of a DER certificate.
+func certificatePublicKeyInfo(der []byte) ([]byte, error) {
+	var cert certificate
+	if _, err := asn1.Unmarshal(der, &cert); err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	return cert.TBSCertificate.PublicKey.FullBytes, nil
+}
+
+// decodeCertificate decodes a PEM or base64 DER certificate.
+func decodeCertificate(str string) ([]byte, error) {
+	if block, _ := pem.Decode([]byte(str)); block != nil {
+		return block.Bytes, nil
+	}
+	der, err := decodeBase64(str)
+	if err != nil {
+		return nil, fmt.Errorf("certificate field must be PEM or base64: %w", err)
+	}
+	return der, nil
+}
+
+// base64Field decodes the base64 string field of item, returning nil if it is absent.
+func base64Field(item map[string]interface{}, field string) ([]byte, error) {
+	val, ok := item[field]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	str, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s field must be a base64 string", field)
+	}
+	b, err := decodeBase64(str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", field, err)
+	}
+	return b, nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, padded or not, as Android's
+// Base64.DEFAULT, NO_PADDING and URL_SAFE flags write it; line breaks are ignored.
+func decodeBase64(str string) ([]byte, error) {
+	str = strings.NewReplacer("\n", "", "\r", "").Replace(strings.TrimSpace(str))
+	str = strings.TrimRight(str, "=")
+	if strings.ContainsAny(str, "-_") {
+		return base64.RawURLEncoding.DecodeString(str)
+	}
+	return base64.RawStdEncoding.DecodeString(str)
+}
@@ -0,0 +1,160 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func derSignature(t *testing.T, r, s *big.Int) string {
+	der, err := asn1.Marshal(ecdsaSigValue{r, s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func TestKeystoreParser(t *testing.T) {
+	d := big.NewInt(0xa1d701d)
+	pub := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey()
+	spki, err := asn1.Marshal(publicKeyInfo{
+		Algorithm: struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.RawValue `asn1:"optional"`
+		}{oidECPublicKey, asn1.RawValue{FullBytes: mustMarshal(t, oidSecp256k1)}},
+		PublicKey: asn1.BitString{Bytes: pub.SerializeUncompressed(), BitLength: 65 * 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log strings.Builder
+	var want []*Signature
+	for k, payload := range []string{`{"nonce":"a1"}`, `{"nonce":"b2"}`} {
+		digest := sha256.Sum256([]byte(payload))
+		sig := signWithNonce(d, big.NewInt(int64(90001+k*3)), new(big.Int).SetBytes(digest[:]))
+		want = append(want, sig)
+		record, _ := json.Marshal(map[string]interface{}{
+			"signature":  derSignature(t, sig.R, sig.S),
+			"payload":    base64.RawURLEncoding.EncodeToString([]byte(payload)),
+			"public_key": base64.StdEncoding.EncodeToString(spki),
+			"timestamp":  1700000000 + k,
+		})
+		log.Write(record)
+		log.WriteString("\n")
+	}
+
+	signatures, err := (&KeystoreParser{}).Parse(strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != 2 {
+		t.Fatalf("Expected 2 signatures, got %d", len(signatures))
+	}
+	for k, got := range signatures {
+		if got.Z.Cmp(want[k].Z) != 0 || got.R.Cmp(want[k].R) != 0 || got.S.Cmp(want[k].S) != 0 {
+			t.Errorf("Signature %d: got (z, r, s) = (%x, %x, %x), want (%x, %x, %x)", k, got.Z, got.R, got.S, want[k].Z, want[k].R, want[k].S)
+		}
+		if hex.EncodeToString(got.PublicKey) != hex.EncodeToString(pub.SerializeCompressed()) || got.Timestamp.Unix() != int64(1700000000+k) {
+			t.Errorf("Signature %d: unexpected public key %x or timestamp %v", k, got.PublicKey, got.Timestamp)
+		}
+	}
+
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, "")
+	if err != nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Errorf("Expected the key from the log, got %v, %v", result, err)
+	}
+}
+
+// TestKeystoreParser_Certificate checks a P-256 attestation record: the z is truncated
+// to the certificate's curve, and the key is not attached.
+func TestKeystoreParser_Certificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Android Keystore Key"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(1<<32, 0),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("integrity verdict")
+	der, err := ecdsa.SignASN1(rand.Reader, key, func() []byte { h := sha256.Sum256(payload); return h[:] }())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, _ := json.Marshal([]map[string]string{{
+		"signature":   base64.StdEncoding.EncodeToString(der),
+		"payload":     base64.StdEncoding.EncodeToString(payload),
+		"algorithm":   "SHA256withECDSA",
+		"certificate": base64.StdEncoding.EncodeToString(cert),
+	}})
+	signatures, err := (&KeystoreParser{}).Parse(strings.NewReader(string(record)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := signatures[0]
+	if sig.PublicKey != nil {
+		t.Errorf("Expected no public key for a P-256 certificate, got %x", sig.PublicKey)
+	}
+	digest := sha256.Sum256(payload)
+	hashed := TruncateDigest(digest[:], elliptic.P256().Params().N)
+	if sig.Z.Cmp(hashed) != 0 || !ecdsa.Verify(&key.PublicKey, digest[:], sig.R, sig.S) {
+		t.Errorf("Expected the signed digest and a valid (r, s), got z = %x", sig.Z)
+	}
+}
+
+func TestKeystoreParser_Errors(t *testing.T) {
+	valid := derSignature(t, big.NewInt(5), big.NewInt(7))
+	payload := base64.StdEncoding.EncodeToString([]byte("payload"))
+	tests := []struct {
+		record string
+		want   string
+	}{
+		{`{"payload": "` + payload + `"}`, "missing signature"},
+		{`{"signature": "` + valid + `"}`, "missing payload"},
+		{`{"signature": "MAYCAQUCAQc=AA", "payload": "` + payload + `"}`, "signature"},
+		{`{"signature": "` + derSignature(t, big.NewInt(0), big.NewInt(7)) + `", "payload": "` + payload + `"}`, "must be positive"},
+		{`{"signature": "` + valid + `", "payload": "` + payload + `", "algorithm": "SHA3-256withECDSA"}`, "unsupported algorithm"},
+		{`{"signature": "` + valid + `", "payload": "` + payload + `", "certificate": "bm90IGEgY2VydA=="}`, "invalid certificate"},
+	}
+	for _, tt := range tests {
+		if _, err := (&KeystoreParser{}).Parse(strings.NewReader(tt.record)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.record, tt.want, err)
+		}
+	}
+
+	// Trailing bytes after the SEQUENCE are not DER
+	der, _ := base64.StdEncoding.DecodeString(valid)
+	if _, _, err := ParseDERSignature(append(der, 0)); err == nil {
+		t.Error("Expected an error for trailing bytes")
+	}
+}
+
+func mustMarshal(t *testing.T, val interface{}) []byte {
+	b, err := asn1.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,132 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+// SignatureSource is where a merged signature was read: a dataset and the record's
+// position in it.
+type SignatureSource struct {
+	Path  string `json:"path"`
+	Index int    `json:"index"`
+}
+
+// MergedDataset is the combined dataset MergeDatasets returns.
+type MergedDataset struct {
+	// Signatures are the distinct signatures, grouped by signer (see GroupByPublicKey)
+	// with the signers in order of first appearance, and each signer's signatures in the
+	// order they were read
+	Signatures []*Signature
+
+	// Provenance lists, for each of Signatures, every record it was merged from
+	Provenance [][]SignatureSource
+
+	Records    int // records read
+	Duplicates int // records dropped as copies of an earlier one
+	Conflicts  int // duplicates whose signer context disagreed with the kept record's
+}
+
+// MergeDatasets reads the datasets at paths with parser and merges them into one
+// dataset without duplicates. Harvest pipelines that crawl overlapping sources produce
+// the same signature many times, and every copy adds a pair per other signature to each
+// search phase without adding a relationship to find.
+//
+// Records with the same z and r and the same s, or its malleated form n - s, are one
+// signature. The kept record is the first read; the context it lacks (public key,
+// recovery id, timestamp, sequence, block height) is filled in from its duplicates, and
+// a duplicate that disagrees on a field both have counts as a conflict and loses.
+func MergeDatasets(parser SignatureParser, paths ...string) (*MergedDataset, error) {
+	merged := &MergedDataset{}
+	var signatures []*Signature
+	var provenance [][]SignatureSource
+	seen := make(map[string]int) // mergeKey -> position in signatures
+
+	for _, path := range paths {
+		records, err := parser.ParseSignatures(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for i, sig := range records {
+			merged.Records++
+			source := SignatureSource{Path: path, Index: i}
+			id := mergeKey(sig)
+			if k, ok := seen[id]; ok {
+				merged.Duplicates++
+				if !mergeContext(signatures[k], sig) {
+					merged.Conflicts++
+				}
+				provenance[k] = append(provenance[k], source)
+				continue
+			}
+			seen[id] = len(signatures)
+			kept := *sig
+			signatures = append(signatures, &kept)
+			provenance = append(provenance, []SignatureSource{source})
+		}
+	}
+
+	for _, group := range GroupByPublicKey(signatures) {
+		for k, sig := range group.Signatures {
+			merged.Signatures = append(merged.Signatures, sig)
+			merged.Provenance = append(merged.Provenance, provenance[group.Indices[k]])
+		}
+	}
+	return merged, nil
+}
+
+// mergeKey identifies a signature by z, r and low s, so a malleated copy has the same
+// key.
+func mergeKey(sig *Signature) string {
+	s := sig.S
+	if s.Cmp(secp256k1HalfOrder) > 0 {
+		s = new(big.Int).Sub(Secp256k1CurveOrder, s)
+	}
+	return sig.Z.Text(16) + ":" + sig.R.Text(16) + ":" + s.Text(16)
+}
+
+// secp256k1HalfOrder is n/2; s above it has the malleated form n - s below it.
+var secp256k1HalfOrder = new(big.Int).Rsh(Secp256k1CurveOrder, 1)
+
+// mergeContext fills the context kept lacks from dup, and reports whether every field
+// both have agrees. A malleated duplicate's recovery id is not comparable, as negating s
+// flips the parity of the nonce point.
+func mergeContext(kept, dup *Signature) bool {
+	agree := true
+	if len(dup.PublicKey) > 0 {
+		if len(kept.PublicKey) == 0 {
+			kept.PublicKey = dup.PublicKey
+		} else if !bytes.Equal(kept.PublicKey, dup.PublicKey) && !sameSigner(kept.PublicKey, dup.PublicKey) {
+			agree = false
+		}
+	}
+	if dup.RecoveryID != nil && dup.S.Cmp(kept.S) == 0 {
+		if kept.RecoveryID == nil {
+			kept.RecoveryID = dup.RecoveryID
+		} else if *kept.RecoveryID != *dup.RecoveryID {
+			agree = false
+		}
+	}
+	if !dup.Timestamp.IsZero() {
+		if kept.Timestamp.IsZero() {
+			kept.Timestamp = dup.Timestamp
+		} else if !kept.Timestamp.Equal(dup.Timestamp) {
+			agree = false
+		}
+	}
+	for _, field := range []struct{ kept, dup **int64 }{
+		{&kept.Sequence, &dup.Sequence},
+		{&kept.BlockHeight, &dup.BlockHeight},
+	} {
+		if *field.dup == nil {
+			continue
+		}
+		if *field.kept == nil {
+			*field.kept = *field.dup
+		} else if **field.kept != **field.dup {
+			agree = false
+		}
+	}
+	return agree
+}
@@ -0,0 +1,77 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestMergeDatasets(t *testing.T) {
+	d, other := big.NewInt(0x3e76e), big.NewInt(0x07e4)
+	first := storeTestSignatures(d, 4)
+	second := storeTestSignatures(other, 2)
+	for _, sig := range second {
+		sig.PublicKey = secp256k1.PrivKeyFromBytes(other.Bytes()).PubKey().SerializeCompressed()
+	}
+
+	// The second file repeats signature 1, malleated and with a sequence number, and
+	// signature 3 with a conflicting one
+	seq, conflicting := int64(11), int64(12)
+	malleated := &Signature{Z: first[1].Z, R: first[1].R, S: new(big.Int).Sub(Secp256k1CurveOrder, first[1].S), Sequence: &seq}
+	first[3].Sequence = new(int64)
+	repeated := *first[3]
+	repeated.Sequence = &conflicting
+
+	dir := t.TempDir()
+	write := func(name string, signatures []*Signature) string {
+		var buf bytes.Buffer
+		if err := WriteSignaturesJSON(&buf, signatures); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a.json", first)
+	b := write("b.json", []*Signature{second[0], malleated, &repeated, second[1]})
+
+	parser, _ := NewParser("json")
+	merged, err := MergeDatasets(parser, a, b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Records != 12 || len(merged.Signatures) != 6 || merged.Duplicates != 6 || merged.Conflicts != 1 {
+		t.Fatalf("Expected 12 records merged into 6 signatures with 6 duplicates and 1 conflict, got %d, %d, %d, %d",
+			merged.Records, len(merged.Signatures), merged.Duplicates, merged.Conflicts)
+	}
+
+	// Grouped by signer: the keyless signer first, as it was read first
+	for k, want := range []*Signature{first[0], first[1], first[2], first[3], second[0], second[1]} {
+		if got := merged.Signatures[k]; got.R.Cmp(want.R) != 0 {
+			t.Errorf("Signature %d: expected r %x, got %x", k, want.R, got.R)
+		}
+	}
+	if got := merged.Signatures[1]; got.S.Cmp(first[1].S) != 0 || got.Sequence == nil || *got.Sequence != 11 {
+		t.Errorf("Expected the first record's s and the duplicate's sequence, got %+v", got)
+	}
+	if got := merged.Signatures[3]; *got.Sequence != 0 {
+		t.Errorf("Expected the first record's sequence to win the conflict, got %d", *got.Sequence)
+	}
+	want := []SignatureSource{{a, 1}, {b, 1}, {a, 1}}
+	if got := merged.Provenance[1]; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("Expected provenance %v, got %v", want, got)
+	}
+	if got := merged.Provenance[5]; len(got) != 1 || got[0] != (SignatureSource{b, 3}) {
+		t.Errorf("Expected signature 5 from %s record 3, got %v", b, got)
+	}
+
+	if _, err := MergeDatasets(parser, a, filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,150 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// DefaultReuseTTL is how long a ReuseMonitor remembers a signature's r value.
+const DefaultReuseTTL = 24 * time.Hour
+
+// ReuseMonitor watches a stream of signatures for nonce reuse only: it remembers the r
+// value of each signer's signatures for a TTL and recovers the key the moment a signer
+// repeats one. Unlike a Detector it searches no affine relationships, so each signature
+// costs one map lookup, and a key recovery only on a collision. Use it for streams too
+// busy for a full search, or in front of a Detector on a sample.
+//
+// A ReuseMonitor is safe for concurrent use.
+type ReuseMonitor struct {
+	// Logger receives the events Consume skips (default log.Default())
+	Logger *log.Logger
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	seen      map[reuseKey]*reuseEntry
+	expiry    []reuseKey // insertion order, so expiry order
+	recovered map[string]bool
+}
+
+type reuseKey struct {
+	signer string
+	r      [32]byte
+}
+
+// reuseEntry is what recovering a key from a repeated r needs of the first signature.
+type reuseEntry struct {
+	z, s    *big.Int
+	id      string
+	expires time.Time
+}
+
+// NewReuseMonitor creates a monitor that remembers r values for ttl (0 = DefaultReuseTTL).
+func NewReuseMonitor(ttl time.Duration) *ReuseMonitor {
+	if ttl <= 0 {
+		ttl = DefaultReuseTTL
+	}
+	return &ReuseMonitor{
+		ttl:       ttl,
+		now:       time.Now,
+		seen:      make(map[reuseKey]*reuseEntry),
+		recovered: make(map[string]bool),
+	}
+}
+
+// Len returns the number of r values currently remembered.
+func (m *ReuseMonitor) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire()
+	return len(m.seen)
+}
+
+// Observe takes the signature of one event, with signer context, and returns the
+// signer's key if the signature reuses the nonce of one the signer made within the TTL,
+// or nil. A signature seen twice (same z and s) is not a reuse. The Detection's
+// SignaturePair is [0 1]: the remembered signature, then this one.
+func (m *ReuseMonitor) Observe(sig *Signature, id string) (*Detection, error) {
+	if sig.PublicKey == nil {
+		return nil, errors.New("signature has no signer public key")
+	}
+	if sig.R.Sign() <= 0 || sig.R.Cmp(Secp256k1CurveOrder) >= 0 {
+		return nil, errors.New("r out of range")
+	}
+	key := reuseKey{signer: string(sig.PublicKey)}
+	sig.R.FillBytes(key.r[:])
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire()
+	if m.recovered[key.signer] {
+		return nil, nil
+	}
+	first, ok := m.seen[key]
+	if !ok {
+		m.seen[key] = &reuseEntry{z: sig.Z, s: sig.S, id: id, expires: m.now().Add(m.ttl)}
+		m.expiry = append(m.expiry, key)
+		return nil, nil
+	}
+	if first.z.Cmp(sig.Z) == 0 && first.s.Cmp(sig.S) == 0 {
+		return nil, nil
+	}
+
+	result := recoverReusedNonce(&Signature{Z: first.z, R: sig.R, S: first.s}, sig)
+	if result == nil {
+		return nil, nil
+	}
+	m.recovered[key.signer] = true
+	return &Detection{PublicKey: sig.PublicKey, Result: result, EventIDs: [2]string{first.id, id}}, nil
+}
+
+// expire forgets the r values whose TTL has passed. m.mu must be held.
+func (m *ReuseMonitor) expire() {
+	now := m.now()
+	n := 0
+	for n < len(m.expiry) && !now.Before(m.seen[m.expiry[n]].expires) {
+		delete(m.seen, m.expiry[n])
+		n++
+	}
+	m.expiry = m.expiry[n:]
+}
+
+// recoverReusedNonce recovers the key from two signatures with the same r, whose nonces
+// are therefore equal or opposite (k and n-k give the same r), and returns it only if it
+// verifies against sig2's public key.
+func recoverReusedNonce(sig1, sig2 *Signature) *RecoveryResult {
+	for _, rel := range []struct {
+		a       int64
+		pattern string
+	}{{1, "same_nonce_reuse"}, {-1, "negated_nonce_reuse"}} {
+		a, b := big.NewInt(rel.a), big.NewInt(0)
+		priv, err := RecoverPrivateKey(sig1, sig2, a, b)
+		if err != nil {
+			continue
+		}
+		if ok, _ := VerifyRecoveredKey(priv, sig2.PublicKey); ok {
+			return &RecoveryResult{
+				PrivateKey:    priv,
+				Relationship:  AffineRelationship{A: a, B: b},
+				SignaturePair: [2]int{0, 1},
+				Verified:      true,
+				Pattern:       rel.pattern,
+			}
+		}
+	}
+	return nil
+}
+
+// Consume reads newline-delimited SignatureEvents from r as Detector.Consume does,
+// calling found for every key recovered.
+func (m *ReuseMonitor) Consume(ctx context.Context, r io.Reader, found func(*Detection)) error {
+	return consumeEvents(ctx, r, m.Logger, func(sig *Signature, id string) (*Detection, error) {
+		return m.Observe(sig, id)
+	}, found)
+}
@@ -0,0 +1,115 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// signedBy returns a signature by d with nonce k, with signer context.
+func signedBy(d, k *big.Int, message string) *Signature {
+	sig := signWithNonce(d, k, HashMessage([]byte(message)))
+	sig.PublicKey = secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	return sig
+}
+
+func TestReuseMonitor_Observe(t *testing.T) {
+	d := big.NewInt(0xfeed)
+	other := big.NewInt(0xbeef)
+	k := big.NewInt(123456789)
+	monitor := NewReuseMonitor(0)
+
+	// Another signer with the same nonce is not a reuse by d, nor is a repeated signature
+	for i, sig := range []*Signature{
+		signedBy(d, k, "a"),
+		signedBy(other, k, "b"),
+		signedBy(d, big.NewInt(987), "c"),
+		signedBy(d, k, "a"),
+	} {
+		if detection, err := monitor.Observe(sig, ""); err != nil || detection != nil {
+			t.Fatalf("Signature %d: unexpected detection %+v, %v", i, detection, err)
+		}
+	}
+	if monitor.Len() != 3 {
+		t.Errorf("Expected 3 r values remembered, got %d", monitor.Len())
+	}
+
+	detection, err := monitor.Observe(signedBy(d, k, "d"), "tx-d")
+	if err != nil || detection == nil {
+		t.Fatalf("Expected a detection, got %v", err)
+	}
+	if detection.Result.PrivateKey.Cmp(d) != 0 || detection.Result.Pattern != "same_nonce_reuse" {
+		t.Errorf("Unexpected result %+v", detection.Result)
+	}
+	if detection.EventIDs != [2]string{"", "tx-d"} {
+		t.Errorf("Unexpected event IDs %v", detection.EventIDs)
+	}
+
+	// A recovered signer is not reported again
+	if detection, _ := monitor.Observe(signedBy(d, k, "e"), ""); detection != nil {
+		t.Error("Expected no second detection for a recovered signer")
+	}
+}
+
+func TestReuseMonitor_NegatedNonce(t *testing.T) {
+	d := big.NewInt(0xabcdef)
+	k := big.NewInt(555)
+	monitor := NewReuseMonitor(0)
+
+	monitor.Observe(signedBy(d, k, "a"), "tx-a")
+	detection, err := monitor.Observe(signedBy(d, new(big.Int).Sub(Secp256k1CurveOrder, k), "b"), "tx-b")
+	if err != nil || detection == nil {
+		t.Fatalf("Expected a detection, got %v", err)
+	}
+	if detection.Result.PrivateKey.Cmp(d) != 0 || detection.Result.Pattern != "negated_nonce_reuse" {
+		t.Errorf("Unexpected result %+v", detection.Result)
+	}
+}
+
+func TestReuseMonitor_TTL(t *testing.T) {
+	d := big.NewInt(0x77)
+	k := big.NewInt(4242)
+	now := time.Unix(1700000000, 0)
+	monitor := NewReuseMonitor(time.Hour)
+	monitor.now = func() time.Time { return now }
+
+	monitor.Observe(signedBy(d, k, "a"), "tx-a")
+	now = now.Add(2 * time.Hour)
+	if monitor.Len() != 0 {
+		t.Errorf("Expected the r value forgotten after the TTL, got %d", monitor.Len())
+	}
+	if detection, _ := monitor.Observe(signedBy(d, k, "b"), "tx-b"); detection != nil {
+		t.Error("Expected no detection once the first signature expired")
+	}
+	now = now.Add(30 * time.Minute)
+	if detection, _ := monitor.Observe(signedBy(d, k, "c"), "tx-c"); detection == nil || detection.EventIDs[0] != "tx-b" {
+		t.Errorf("Expected a detection against tx-b, got %+v", detection)
+	}
+}
+
+func TestReuseMonitor_Consume(t *testing.T) {
+	d := big.NewInt(0x5150)
+	stream := signedEvent(t, d, 31337, "tx-1") +
+		"{\"r\": 1}\n" +
+		signedEvent(t, d, 31338, "tx-2") +
+		signedEvent(t, d, 31337, "tx-3")
+
+	monitor := NewReuseMonitor(0)
+	monitor.Logger = log.New(io.Discard, "", 0)
+	var detections []*Detection
+	err := monitor.Consume(context.Background(), strings.NewReader(stream), func(d *Detection) {
+		detections = append(detections, d)
+	})
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if len(detections) != 1 || detections[0].EventIDs != [2]string{"tx-1", "tx-3"} {
+		t.Fatalf("Expected one detection from tx-1 and tx-3, got %+v", detections)
+	}
+}
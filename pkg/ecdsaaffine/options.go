@@ -0,0 +1,162 @@
+package ecdsaaffine
+
+import (
+	"log"
+	"math/big"
+)
+
+// Option configures a Client:
+//
+//	client := ecdsaaffine.NewClient(
+//		ecdsaaffine.WithStrategy(ecdsaaffine.NewBSGSStrategy()),
+//		ecdsaaffine.WithLogger(log.New(os.Stderr, "recovery: ", log.LstdFlags)),
+//	)
+//
+// Every option also exists as a Client method of the same name, for changing a client
+// after it is created.
+type Option func(*Client)
+
+// WithStrategy sets the brute-force strategy (default: a SmartBruteForceStrategy).
+func WithStrategy(strategy BruteForceStrategy) Option {
+	return func(c *Client) {
+		c.strategy = strategy
+	}
+}
+
+// WithParser sets the signature parser used by RecoverKey and
+// RecoverKeyWithKnownRelationship (default: a JSONParser).
+func WithParser(parser SignatureParser) Option {
+	return func(c *Client) {
+		c.parser = parser
+	}
+}
+
+// WithVerification selects how the brute-force strategy checks candidate keys, for the
+// strategy set now and any set later. It applies to SmartBruteForceStrategy and the phase
+// strategies (RangeConfig.Verification), also within a ChainStrategy; other strategies
+// verify the few keys they recover with VerifyRecoveredKey.
+func WithVerification(backend VerificationBackend) Option {
+	return func(c *Client) {
+		c.verification = &backend
+	}
+}
+
+// WithArithmetic selects the modular arithmetic the brute-force strategy recovers range
+// candidates with, for the strategy set now and any set later. It applies where
+// WithVerification does (RangeConfig.Arithmetic).
+func WithArithmetic(backend ArithmeticBackend) Option {
+	return func(c *Client) {
+		c.arithmetic = &backend
+	}
+}
+
+// WithPairs restricts recovery to the given signature pairs (dataset indices, the first
+// signature of each pair taken as sig1 in k2 = a*k1 + b). Each pair is searched on its
+// own with the full strategy, in the order given, and the result's SignaturePair indexes
+// the whole dataset. It applies to RecoverKey, RecoverKeyFromSignatures and
+// RecoverKeyWithKnownRelationship; with no pairs every pair is searched.
+func WithPairs(pairs ...[2]int) Option {
+	return func(c *Client) {
+		c.pairs = pairs
+	}
+}
+
+// WithCrossCheck makes the client check each recovered key against up to limit other
+// signatures by the same signer (0: all of them), recomputing their nonces and checking
+// that each reproduces its r, and count the ones that do in the result's Corroborating.
+// A verified key that explains few of its signer's signatures points to a data mix-up,
+// e.g. records of other keys or z computed with the wrong hash. It applies to RecoverKey,
+// RecoverKeyFromSignatures, RecoverKeyWithKnownRelationship and RecoverKeys.
+func WithCrossCheck(limit int) Option {
+	return func(c *Client) {
+		c.crossCheck = &limit
+	}
+}
+
+// WithLogger sends the strategy's progress messages to logger instead of the standard
+// logger, for the strategy set now and any set later. It applies to the package's
+// strategies, also within a ChainStrategy.
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithHasher sets how the parser computes z from a signature's message when the input
+// has no z (default: HashMessage, SHA-256), for the parser set now and any set later.
+// It applies to JSONParser and CSVParser.
+func WithHasher(hash func(message []byte) *big.Int) Option {
+	return func(c *Client) {
+		c.hash = hash
+	}
+}
+
+// WithCurve runs the recovery math in curve's group instead of secp256k1's, for toy
+// curves and other groups (see Curve). Unless the strategy is already a CurveStrategy it
+// becomes NewCurveStrategy(curve), and public keys are taken as hex of the curve's point
+// encoding. It applies to RecoverKey, RecoverKeyFromSignatures,
+// RecoverKeyWithKnownRelationship and RecoverKeyWithKnownNonce, which treat the dataset
+// as one signer's and skip WithCrossCheck; the other methods stay on secp256k1.
+func WithCurve(curve *Curve) Option {
+	return func(c *Client) {
+		c.curve = curve
+		if _, ok := c.strategy.(*CurveStrategy); !ok {
+			c.strategy = NewCurveStrategy(curve)
+		}
+	}
+}
+
+// apply applies opts and passes the client's settings on to its strategy and parser.
+func (c *Client) apply(opts ...Option) *Client {
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.configure(c.strategy)
+
+	if c.hash != nil {
+		switch parser := c.parser.(type) {
+		case *JSONParser:
+			parser.Hash = c.hash
+		case *CSVParser:
+			parser.Hash = c.hash
+		}
+	}
+	return c
+}
+
+// configure passes the client's settings on to strategy, or to each strategy of a chain.
+func (c *Client) configure(strategy BruteForceStrategy) {
+	switch strategy := strategy.(type) {
+	case smartStrategy:
+		smart := strategy.smart()
+		if c.verification != nil {
+			smart.RangeConfig.Verification = *c.verification
+		}
+		if c.arithmetic != nil {
+			smart.RangeConfig.Arithmetic = *c.arithmetic
+		}
+		if c.logger != nil {
+			smart.Logger = c.logger
+		}
+	case *BSGSStrategy:
+		if c.logger != nil {
+			strategy.Logger = c.logger
+		}
+	case *KangarooStrategy:
+		if c.logger != nil {
+			strategy.Logger = c.logger
+		}
+	case *CurveStrategy:
+		if c.curve != nil {
+			strategy.Curve = c.curve
+		}
+		if c.logger != nil {
+			strategy.Logger = c.logger
+		}
+	case *ChainStrategy:
+		for _, s := range strategy.Strategies {
+			c.configure(s)
+		}
+	}
+}
@@ -1,14 +1,18 @@
 package ecdsaaffine
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
-	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
 )
 
 // SignatureParser defines the interface for parsing signatures from various sources.
@@ -19,36 +23,131 @@ type SignatureParser interface {
 
 // JSONParser parses signatures from JSON files.
 type JSONParser struct {
-	MessageField string // Field name for message (default: "message")
-	RField       string // Field name for r (default: "r")
-	SField       string // Field name for s (default: "s")
-	ZField       string // Field name for z/hash (default: "z", empty = hash message)
+	MessageField    string // Field name for message (default: "message")
+	RField          string // Field name for r (default: "r")
+	SField          string // Field name for s (default: "s")
+	ZField          string // Field name for z/hash (default: "z", empty = hash message)
+	PublicKeyField  string // Field name for the signer public key or address (default: "public_key")
+	RecoveryIDField string // Field name for the recovery id or Ethereum v (default: "v", then "recovery_id")
+	TimestampField  string // Field name for the signing time (default: "timestamp")
+	SequenceField   string // Field name for the sequence number (default: "sequence", then "nonce_index")
+	BlockField      string // Field name for the block height (default: "block_height", then "block_number")
+	SignatureField  string // Field name for the raw signature r || s [|| v], read when r and s are absent (default: "signature")
+
+	// Hash computes z from the message when there is no z field (default: HashMessage)
+	Hash func(message []byte) *big.Int
+
+	// Filter drops the records it does not match as they are parsed (see Filter)
+	Filter *SignatureFilter
 }
 
-// ParseSignatures parses signatures from a JSON file.
+// ParseSignatures parses signatures from a JSON file, given as a path or as an http(s)://,
+// s3:// or gs:// URI (streamed, with the service's standard credentials).
 //
 // Expected format:
 // [
 //   {"message": "...", "r": "...", "s": "..."},
-//   {"z": "0x...", "r": "0x...", "s": "0x..."}
+//   {"z": "0x...", "r": "0x...", "s": "0x..."},
+//   {"z": "0x...", "r": "0x...", "s": "0x...", "v": "0x25", "public_key": "02..."},
+//   {"message": "...", "signature": "0x<r><s><v>"}
 // ]
+//
+// A signature field holds the 64-byte r || s or the 65-byte r || s || v that Ethereum
+// tooling produces, in hex; v fills Signature.RecoveryID unless a v field is present.
+// The optional public_key and v (or recovery_id) fields fill Signature.PublicKey and
+// Signature.RecoveryID, and timestamp (Unix seconds or RFC 3339) fills Signature.Timestamp.
+// The optional sequence and block_height metadata fill Signature.Sequence and
+// Signature.BlockHeight (see MetadataHypotheses). JSON Lines (one object per line, no
+// enclosing array) is accepted too.
 func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
-	file, err := os.Open(jsonFile)
+	file, err := source.Open(jsonFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 	defer file.Close()
 
-	decoder := json.NewDecoder(file)
+	return p.Parse(file)
+}
+
+// Parse parses signatures in the format ParseSignatures reads from r, for input that is
+// not in a file (e.g. in a browser, see cmd/wasm).
+func (p *JSONParser) Parse(r io.Reader) ([]*Signature, error) {
+	items, err := decodeJSONItems(r)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]*Signature, 0, len(items))
+	for _, item := range items {
+		sig, err := p.parseItem(item)
+		if err != nil {
+			return nil, err
+		}
+		if p.Filter != nil && !p.Filter.Match(sig, p.message(item)) {
+			continue
+		}
+		signatures = append(signatures, sig)
+	}
+
+	return signatures, nil
+}
+
+// message returns the message of item, or nil if it has none.
+func (p *JSONParser) message(item map[string]interface{}) []byte {
+	messageField := p.MessageField
+	if messageField == "" {
+		messageField = "message"
+	}
+	if message, ok := item[messageField].(string); ok {
+		return []byte(message)
+	}
+	return nil
+}
+
+// decodeJSONItems decodes a JSON array of objects, or JSON Lines, keeping numbers as
+// json.Number.
+func decodeJSONItems(r io.Reader) ([]map[string]interface{}, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
 	decoder.UseNumber() // Preserve large numbers as json.Number instead of float64
 
 	var items []map[string]interface{}
-	if err := decoder.Decode(&items); err != nil {
+	if startsWithObject(reader) {
+		// JSON Lines: one object per line
+		for {
+			var item map[string]interface{}
+			err := decoder.Decode(&item)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse JSON line %d: %w", len(items)+1, err)
+			}
+			items = append(items, item)
+		}
+	} else if err := decoder.Decode(&items); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	return items, nil
+}
 
-	signatures := make([]*Signature, 0, len(items))
+// startsWithObject skips leading whitespace and reports whether the input starts with a
+// JSON object, as JSON Lines does, rather than an array.
+func startsWithObject(reader *bufio.Reader) bool {
+	for {
+		c, err := reader.ReadByte()
+		if err != nil {
+			return false
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			reader.UnreadByte()
+			return c == '{'
+		}
+	}
+}
 
+// parseItem parses one signature object.
+func (p *JSONParser) parseItem(item map[string]interface{}) (*Signature, error) {
 	messageField := p.MessageField
 	if messageField == "" {
 		messageField = "message"
@@ -62,77 +161,184 @@ func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
 		sField = "s"
 	}
 
-	for _, item := range items {
-		sig := &Signature{}
-
-		// Get z (message hash)
-		if p.ZField != "" {
-			if zVal, ok := item[p.ZField]; ok {
-				z, err := parseBigInt(zVal)
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse z: %w", err)
-				}
-				sig.Z = z
+	sig := &Signature{}
+
+	// Get z (message hash)
+	if p.ZField != "" {
+		if zVal, ok := item[p.ZField]; ok {
+			z, err := parseBigInt(zVal)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse z: %w", err)
 			}
+			sig.Z = z
 		}
+	}
 
-		// If z not found, hash the message
+	// Keep the message, and hash it if z not found
+	if msgVal, ok := item[messageField]; ok {
+		switch v := msgVal.(type) {
+		case string:
+			sig.Message = []byte(v)
+		case []byte:
+			sig.Message = v
+		default:
+			return nil, fmt.Errorf("message field must be string or bytes")
+		}
 		if sig.Z == nil {
-			if msgVal, ok := item[messageField]; ok {
-				var message []byte
-				switch v := msgVal.(type) {
-				case string:
-					message = []byte(v)
-				case []byte:
-					message = v
-				default:
-					return nil, fmt.Errorf("message field must be string or bytes")
-				}
-				sig.Z = HashMessage(message)
-			} else {
-				return nil, fmt.Errorf("missing message or z field")
-			}
+			sig.Z = hashMessage(p.Hash, sig.Message)
 		}
+	} else if sig.Z == nil {
+		return nil, fmt.Errorf("missing message or z field")
+	}
 
-		// Get r
-		rVal, ok := item[rField]
+	// A raw signature stands in for missing r and s
+	signatureField := p.SignatureField
+	if signatureField == "" {
+		signatureField = "signature"
+	}
+	_, hasR := item[rField]
+	_, hasS := item[sField]
+	if blob, ok := item[signatureField]; ok && !hasR && !hasS {
+		str, ok := blob.(string)
 		if !ok {
-			return nil, fmt.Errorf("missing r field")
+			return nil, fmt.Errorf("%s field must be a hex string", signatureField)
 		}
-		r, err := parseBigInt(rVal)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse r: %w", err)
+		if err := splitSignature(str, sig); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", signatureField, err)
 		}
-		sig.R = r
+		if err := p.parseSignerContext(item, sig); err != nil {
+			return nil, err
+		}
+		return sig, nil
+	}
 
-		// Get s
-		sVal, ok := item[sField]
+	// Get r
+	rVal, ok := item[rField]
+	if !ok {
+		return nil, fmt.Errorf("missing r field")
+	}
+	r, err := parseBigInt(rVal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse r: %w", err)
+	}
+	sig.R = r
+
+	// Get s
+	sVal, ok := item[sField]
+	if !ok {
+		return nil, fmt.Errorf("missing s field")
+	}
+	s, err := parseBigInt(sVal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse s: %w", err)
+	}
+	sig.S = s
+
+	if err := p.parseSignerContext(item, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// parseSignerContext reads the optional public key and recovery id fields of item.
+func (p *JSONParser) parseSignerContext(item map[string]interface{}, sig *Signature) error {
+	publicKeyField := p.PublicKeyField
+	if publicKeyField == "" {
+		publicKeyField = "public_key"
+	}
+	if val, ok := item[publicKeyField]; ok && val != nil {
+		str, ok := val.(string)
 		if !ok {
-			return nil, fmt.Errorf("missing s field")
+			return fmt.Errorf("%s field must be a hex string", publicKeyField)
 		}
-		s, err := parseBigInt(sVal)
+		publicKey, err := ParsePublicKeyHex(str)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse s: %w", err)
+			return fmt.Errorf("failed to parse %s: %w", publicKeyField, err)
 		}
-		sig.S = s
+		sig.PublicKey = publicKey
+	}
 
-		signatures = append(signatures, sig)
+	fields := []string{p.RecoveryIDField}
+	if p.RecoveryIDField == "" {
+		fields = []string{"v", "recovery_id"}
+	}
+	for _, field := range fields {
+		val, ok := item[field]
+		if !ok || val == nil {
+			continue
+		}
+		id, err := parseRecoveryID(val)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", field, err)
+		}
+		sig.RecoveryID = &id
+		break
 	}
 
-	return signatures, nil
+	timestampField := p.TimestampField
+	if timestampField == "" {
+		timestampField = "timestamp"
+	}
+	if val, ok := item[timestampField]; ok && val != nil {
+		timestamp, err := parseTimestamp(val)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", timestampField, err)
+		}
+		sig.Timestamp = timestamp
+	}
+
+	for _, metadata := range []struct {
+		field    string
+		defaults []string
+		dst      **int64
+	}{
+		{p.SequenceField, []string{"sequence", "nonce_index"}, &sig.Sequence},
+		{p.BlockField, []string{"block_height", "block_number"}, &sig.BlockHeight},
+	} {
+		fields := []string{metadata.field}
+		if metadata.field == "" {
+			fields = metadata.defaults
+		}
+		for _, field := range fields {
+			val, ok := item[field]
+			if !ok || val == nil {
+				continue
+			}
+			n, err := parseMetadataInt(val)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", field, err)
+			}
+			*metadata.dst = &n
+			break
+		}
+	}
+	return nil
 }
 
 // CSVParser parses signatures from CSV files.
 type CSVParser struct {
-	MessageCol string // Column name for message (default: "message")
-	RCol       string // Column name for r (default: "r")
-	SCol       string // Column name for s (default: "s")
-	ZCol       string // Column name for z/hash (default: empty = hash message)
+	MessageCol    string // Column name for message (default: "message")
+	RCol          string // Column name for r (default: "r")
+	SCol          string // Column name for s (default: "s")
+	ZCol          string // Column name for z/hash (default: empty = hash message)
+	PublicKeyCol  string // Column name for the signer public key or address (default: "public_key")
+	RecoveryIDCol string // Column name for the recovery id or Ethereum v (default: "v", then "recovery_id")
+	TimestampCol  string // Column name for the signing time (default: "timestamp")
+	SequenceCol   string // Column name for the sequence number (default: "sequence", then "nonce_index")
+	BlockCol      string // Column name for the block height (default: "block_height", then "block_number")
+	SignatureCol  string // Column name for the raw signature r || s [|| v], used without r and s columns (default: "signature")
+
+	// Hash computes z from the message when there is no z column (default: HashMessage)
+	Hash func(message []byte) *big.Int
+
+	// Filter drops the records it does not match as they are parsed (see Filter)
+	Filter *SignatureFilter
 }
 
-// ParseSignatures parses signatures from a CSV file.
+// ParseSignatures parses signatures from a CSV file, given as a path or URI as for
+// JSONParser.
 func (p *CSVParser) ParseSignatures(csvFile string) ([]*Signature, error) {
-	file, err := os.Open(csvFile)
+	file, err := source.Open(csvFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -146,7 +352,48 @@ func (p *CSVParser) ParseSignatures(csvFile string) ([]*Signature, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read header: %w", err)
 	}
+	columns, err := p.columns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]*Signature, 0)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		sig, err := columns.parse(record)
+		if err != nil {
+			return nil, err
+		}
+		if p.Filter != nil && !p.Filter.Match(sig, columns.message(record)) {
+			continue
+		}
+		signatures = append(signatures, sig)
+	}
+
+	return signatures, nil
+}
+
+// csvColumns locates the columns of a CSV file, found from its header.
+type csvColumns struct {
+	header       []string
+	hash         func(message []byte) *big.Int
+	publicKeyCol string
+	timestampCol string
 
+	messageIdx, rIdx, sIdx, zIdx              int
+	publicKeyIdx, recoveryIDIdx, timestampIdx int
+	sequenceIdx, blockIdx, signatureIdx       int
+}
+
+// columns finds the parser's columns in header.
+func (p *CSVParser) columns(header []string) (*csvColumns, error) {
 	// Find column indices
 	messageCol := p.MessageCol
 	if messageCol == "" {
@@ -161,10 +408,38 @@ func (p *CSVParser) ParseSignatures(csvFile string) ([]*Signature, error) {
 		sCol = "s"
 	}
 
+	publicKeyCol := p.PublicKeyCol
+	if publicKeyCol == "" {
+		publicKeyCol = "public_key"
+	}
+	timestampCol := p.TimestampCol
+	if timestampCol == "" {
+		timestampCol = "timestamp"
+	}
+	recoveryIDCols := []string{p.RecoveryIDCol}
+	if p.RecoveryIDCol == "" {
+		recoveryIDCols = []string{"v", "recovery_id"}
+	}
+	sequenceCols := []string{p.SequenceCol}
+	if p.SequenceCol == "" {
+		sequenceCols = []string{"sequence", "nonce_index"}
+	}
+	blockCols := []string{p.BlockCol}
+	if p.BlockCol == "" {
+		blockCols = []string{"block_height", "block_number"}
+	}
+	signatureCol := p.SignatureCol
+	if signatureCol == "" {
+		signatureCol = "signature"
+	}
+
 	messageIdx := -1
 	rIdx := -1
 	sIdx := -1
 	zIdx := -1
+	publicKeyIdx := -1
+	recoveryIDIdx := -1
+	timestampIdx := -1
 
 	for i, col := range header {
 		if col == messageCol {
@@ -179,63 +454,252 @@ func (p *CSVParser) ParseSignatures(csvFile string) ([]*Signature, error) {
 		if p.ZCol != "" && col == p.ZCol {
 			zIdx = i
 		}
+		if col == publicKeyCol {
+			publicKeyIdx = i
+		}
+		if col == timestampCol {
+			timestampIdx = i
+		}
+	}
+	for _, name := range recoveryIDCols {
+		for i, col := range header {
+			if col == name && recoveryIDIdx == -1 {
+				recoveryIDIdx = i
+			}
+		}
+	}
+	sequenceIdx := firstColumn(header, sequenceCols)
+	blockIdx := firstColumn(header, blockCols)
+	signatureIdx := -1
+	if rIdx == -1 && sIdx == -1 {
+		signatureIdx = firstColumn(header, []string{signatureCol})
 	}
 
-	if rIdx == -1 || sIdx == -1 {
+	if (rIdx == -1 || sIdx == -1) && signatureIdx == -1 {
 		return nil, fmt.Errorf("missing required columns: r or s")
 	}
 
-	signatures := make([]*Signature, 0)
+	return &csvColumns{
+		header:        header,
+		hash:          p.Hash,
+		publicKeyCol:  publicKeyCol,
+		timestampCol:  timestampCol,
+		messageIdx:    messageIdx,
+		rIdx:          rIdx,
+		sIdx:          sIdx,
+		zIdx:          zIdx,
+		publicKeyIdx:  publicKeyIdx,
+		recoveryIDIdx: recoveryIDIdx,
+		timestampIdx:  timestampIdx,
+		sequenceIdx:   sequenceIdx,
+		blockIdx:      blockIdx,
+		signatureIdx:  signatureIdx,
+	}, nil
+}
 
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read record: %w", err)
-		}
+// message returns the message of record, or nil if the file has no message column.
+func (c *csvColumns) message(record []string) []byte {
+	if c.messageIdx < 0 || c.messageIdx >= len(record) {
+		return nil
+	}
+	return []byte(record[c.messageIdx])
+}
 
-		sig := &Signature{}
+// parse parses one record.
+func (c *csvColumns) parse(record []string) (*Signature, error) {
+	sig := &Signature{}
 
-		// Get z
-		if zIdx >= 0 && zIdx < len(record) {
-			z, err := parseBigInt(record[zIdx])
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse z: %w", err)
-			}
-			sig.Z = z
-		} else if messageIdx >= 0 && messageIdx < len(record) {
-			message := []byte(record[messageIdx])
-			sig.Z = HashMessage(message)
-		} else {
-			return nil, fmt.Errorf("missing message or z column")
+	// Get z
+	if c.zIdx >= 0 && c.zIdx < len(record) {
+		z, err := parseBigInt(record[c.zIdx])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse z: %w", err)
 		}
+		sig.Z = z
+	} else if c.messageIdx < 0 || c.messageIdx >= len(record) {
+		return nil, fmt.Errorf("missing message or z column")
+	}
+	if message := c.message(record); message != nil {
+		sig.Message = message
+		if sig.Z == nil {
+			sig.Z = hashMessage(c.hash, message)
+		}
+	}
 
+	if c.signatureIdx >= 0 {
+		// Raw signature r || s [|| v]
+		if c.signatureIdx >= len(record) {
+			return nil, fmt.Errorf("signature column index out of range")
+		}
+		if err := splitSignature(record[c.signatureIdx], sig); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", c.header[c.signatureIdx], err)
+		}
+	} else {
 		// Get r
-		if rIdx >= len(record) {
+		if c.rIdx >= len(record) {
 			return nil, fmt.Errorf("r column index out of range")
 		}
-		r, err := parseBigInt(record[rIdx])
+		r, err := parseBigInt(record[c.rIdx])
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse r: %w", err)
 		}
 		sig.R = r
 
 		// Get s
-		if sIdx >= len(record) {
+		if c.sIdx >= len(record) {
 			return nil, fmt.Errorf("s column index out of range")
 		}
-		s, err := parseBigInt(record[sIdx])
+		s, err := parseBigInt(record[c.sIdx])
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse s: %w", err)
 		}
 		sig.S = s
+	}
 
-		signatures = append(signatures, sig)
+	// Optional signer context; empty cells are skipped
+	if c.publicKeyIdx >= 0 && c.publicKeyIdx < len(record) && record[c.publicKeyIdx] != "" {
+		publicKey, err := ParsePublicKeyHex(record[c.publicKeyIdx])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", c.publicKeyCol, err)
+		}
+		sig.PublicKey = publicKey
+	}
+	if c.recoveryIDIdx >= 0 && c.recoveryIDIdx < len(record) && record[c.recoveryIDIdx] != "" {
+		id, err := parseRecoveryID(record[c.recoveryIDIdx])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", c.header[c.recoveryIDIdx], err)
+		}
+		sig.RecoveryID = &id
+	}
+	if c.timestampIdx >= 0 && c.timestampIdx < len(record) && record[c.timestampIdx] != "" {
+		timestamp, err := parseTimestamp(record[c.timestampIdx])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", c.timestampCol, err)
+		}
+		sig.Timestamp = timestamp
+	}
+	for _, metadata := range []struct {
+		idx int
+		dst **int64
+	}{{c.sequenceIdx, &sig.Sequence}, {c.blockIdx, &sig.BlockHeight}} {
+		if metadata.idx >= 0 && metadata.idx < len(record) && record[metadata.idx] != "" {
+			n, err := parseMetadataInt(record[metadata.idx])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", c.header[metadata.idx], err)
+			}
+			*metadata.dst = &n
+		}
 	}
 
-	return signatures, nil
+	return sig, nil
+}
+
+// splitSignature fills sig's r, s and, for a 65-byte signature, recovery id from a raw
+// signature r || s [|| v] in hex, each of r and s 32 bytes big-endian.
+func splitSignature(blob string, sig *Signature) error {
+	blob = strings.TrimSpace(blob)
+	if strings.HasPrefix(blob, "0x") || strings.HasPrefix(blob, "0X") {
+		blob = blob[2:]
+	}
+	raw, err := hex.DecodeString(blob)
+	if err != nil {
+		return fmt.Errorf("invalid hex: %w", err)
+	}
+	if len(raw) != 64 && len(raw) != 65 {
+		return fmt.Errorf("signature is %d bytes, want 64 (r || s) or 65 (r || s || v)", len(raw))
+	}
+	sig.R = new(big.Int).SetBytes(raw[:32])
+	sig.S = new(big.Int).SetBytes(raw[32:64])
+	if len(raw) == 65 {
+		id, err := RecoveryIDFromV(big.NewInt(int64(raw[64])))
+		if err != nil {
+			return err
+		}
+		sig.RecoveryID = &id
+	}
+	return nil
+}
+
+// parseRecoveryID parses a recovery id or Ethereum v value (see RecoveryIDFromV). Strings
+// with a 0x prefix are hex, as in JSON-RPC responses; other strings are decimal.
+func parseRecoveryID(val interface{}) (int, error) {
+	var v *big.Int
+	if str, ok := val.(string); ok {
+		v = new(big.Int)
+		base := 10
+		if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+			str, base = str[2:], 16
+		}
+		if _, ok := v.SetString(str, base); !ok {
+			return 0, fmt.Errorf("invalid number format: %s", val)
+		}
+	} else {
+		var err error
+		if v, err = parseBigInt(val); err != nil {
+			return 0, err
+		}
+	}
+	return RecoveryIDFromV(v)
+}
+
+// firstColumn returns the index of the first of names found in header, or -1.
+func firstColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, col := range header {
+			if col == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseMetadataInt parses a sequence number or block height: a JSON number, a decimal
+// string, or a 0x-prefixed hex string as in JSON-RPC responses.
+func parseMetadataInt(val interface{}) (int64, error) {
+	var str string
+	switch v := val.(type) {
+	case string:
+		str = strings.TrimSpace(v)
+	case json.Number:
+		str = string(v)
+	case float64:
+		str = fmt.Sprintf("%.0f", v)
+	default:
+		return 0, fmt.Errorf("unsupported type: %T", val)
+	}
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		return strconv.ParseInt(str[2:], 16, 64)
+	}
+	return strconv.ParseInt(str, 10, 64)
+}
+
+// parseTimestamp parses a signing time: Unix seconds (a JSON number or decimal string;
+// values above 1e12 are taken as milliseconds) or an RFC 3339 string.
+func parseTimestamp(val interface{}) (time.Time, error) {
+	var str string
+	switch v := val.(type) {
+	case string:
+		str = strings.TrimSpace(v)
+	case json.Number:
+		str = string(v)
+	case float64:
+		str = fmt.Sprintf("%.0f", v)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported type: %T", val)
+	}
+
+	if seconds, err := strconv.ParseInt(str, 10, 64); err == nil {
+		if seconds > 1e12 {
+			return time.UnixMilli(seconds).UTC(), nil
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: expected Unix seconds or RFC 3339", str)
+	}
+	return timestamp, nil
 }
 
 // parseBigInt parses a big integer from various formats (hex string, decimal string, number).
@@ -293,3 +757,10 @@ func parseBigInt(val interface{}) (*big.Int, error) {
 	}
 }
 
+// hashMessage computes z for message with hash, or HashMessage if hash is nil.
+func hashMessage(hash func([]byte) *big.Int, message []byte) *big.Int {
+	if hash == nil {
+		return HashMessage(message)
+	}
+	return hash(message)
+}
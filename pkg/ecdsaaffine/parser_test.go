@@ -1,8 +1,19 @@
 package ecdsaaffine
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 )
 
 func TestJSONParser_ParseSignatures(t *testing.T) {
@@ -86,6 +97,55 @@ func TestJSONParser_ParseSignatures_CustomFields(t *testing.T) {
 	}
 }
 
+func TestJSONParser_ParseSignatures_URL(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir(fixturesDir())))
+	defer server.Close()
+
+	parser := &JSONParser{ZField: "z"}
+	local, err := parser.ParseSignatures(filepath.Join(fixturesDir(), "test_signatures_counter.json"))
+	if err != nil {
+		t.Fatalf("Failed to parse the local file: %v", err)
+	}
+	remote, err := parser.ParseSignatures(server.URL + "/test_signatures_counter.json")
+	if err != nil {
+		t.Fatalf("Failed to parse the URL: %v", err)
+	}
+	if len(remote) != len(local) || remote[0].R.Cmp(local[0].R) != 0 {
+		t.Errorf("Expected the same %d signatures from the URL, got %d", len(local), len(remote))
+	}
+
+	if _, err := parser.ParseSignatures(server.URL + "/missing.json"); err == nil {
+		t.Error("Expected an error for a missing object")
+	}
+	if _, err := (&StoreParser{}).ParseSignatures(server.URL + "/signatures.store"); err == nil {
+		t.Error("Expected an error for a remote signature store")
+	}
+}
+
+func TestJSONParser_ParseSignatures_JSONLines(t *testing.T) {
+	parser := &JSONParser{ZField: "z"}
+	array, err := parser.ParseSignatures(filepath.Join(fixturesDir(), "test_signatures_counter.json"))
+	if err != nil {
+		t.Fatalf("Failed to parse signatures: %v", err)
+	}
+
+	var lines bytes.Buffer
+	for _, sig := range array {
+		fmt.Fprintf(&lines, "{\"z\": \"0x%x\", \"r\": \"0x%x\", \"s\": \"0x%x\"}\n", sig.Z, sig.R, sig.S)
+	}
+	path := filepath.Join(t.TempDir(), "signatures.ndjson")
+	if err := os.WriteFile(path, lines.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parser.ParseSignatures(path)
+	if err != nil {
+		t.Fatalf("Failed to parse JSON Lines: %v", err)
+	}
+	if len(parsed) != len(array) || parsed[len(parsed)-1].S.Cmp(array[len(array)-1].S) != 0 {
+		t.Errorf("Expected the same %d signatures from JSON Lines, got %d", len(array), len(parsed))
+	}
+}
+
 func TestJSONParser_ParseSignatures_InvalidFile(t *testing.T) {
 	parser := &JSONParser{}
 
@@ -106,3 +166,207 @@ func TestJSONParser_ParseSignatures_InvalidJSON(t *testing.T) {
 	}
 }
 
+
+func TestJSONParser_ParseSignatures_SignerContext(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	compressed := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	uncompressed := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeUncompressed()
+
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	data := `[
+		{"z": "0x01", "r": "0x02", "s": "0x03", "v": "0x25", "public_key": "0x` + hex.EncodeToString(uncompressed) + `"},
+		{"z": "0x01", "r": "0x02", "s": "0x03", "v": 28},
+		{"z": "0x01", "r": "0x02", "s": "0x03", "recovery_id": 1},
+		{"z": "0x01", "r": "0x02", "s": "0x03"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	signatures, err := (&JSONParser{ZField: "z"}).ParseSignatures(path)
+	if err != nil {
+		t.Fatalf("ParseSignatures failed: %v", err)
+	}
+	if !bytes.Equal(signatures[0].PublicKey, compressed) {
+		t.Errorf("Expected the public key normalized to %x, got %x", compressed, signatures[0].PublicKey)
+	}
+	for i, want := range []int{0, 1, 1} {
+		if signatures[i].RecoveryID == nil || *signatures[i].RecoveryID != want {
+			t.Errorf("Signature %d: expected recovery id %d, got %v", i, want, signatures[i].RecoveryID)
+		}
+	}
+	if signatures[3].PublicKey != nil || signatures[3].RecoveryID != nil {
+		t.Error("Expected no signer context without the optional fields")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"z": "0x01", "r": "0x02", "s": "0x03", "v": 30}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&JSONParser{ZField: "z"}).ParseSignatures(path); err == nil {
+		t.Error("Expected an error for an invalid v")
+	}
+}
+
+func TestCSVParser_ParseSignatures_SignerContext(t *testing.T) {
+	address := "7e5f4552091a69125d5dfcb7b8c2659029395bdf"
+	path := filepath.Join(t.TempDir(), "signatures.csv")
+	data := "z,r,s,v,public_key\n0x01,0x02,0x03,27,0x" + address + "\n0x01,0x02,0x03,,\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	signatures, err := (&CSVParser{ZCol: "z"}).ParseSignatures(path)
+	if err != nil {
+		t.Fatalf("ParseSignatures failed: %v", err)
+	}
+	if hex.EncodeToString(signatures[0].PublicKey) != address || signatures[0].RecoveryID == nil || *signatures[0].RecoveryID != 0 {
+		t.Errorf("Unexpected signer context: %x, %v", signatures[0].PublicKey, signatures[0].RecoveryID)
+	}
+	if signatures[1].PublicKey != nil || signatures[1].RecoveryID != nil {
+		t.Error("Expected empty cells to leave the signer context unset")
+	}
+}
+
+func TestParsers_RawSignature(t *testing.T) {
+	// A personal_sign signature of "hello" as r || s || v
+	priv := secp256k1.PrivKeyFromBytes(big.NewInt(0xdeadbeef).Bytes())
+	z := HashEthereumMessage([]byte("hello"))
+	compact := ecdsa.SignCompact(priv, z.FillBytes(make([]byte, 32)), true)
+	blob := append(append([]byte(nil), compact[1:]...), compact[0]-4) // v = 27 or 28 for a compressed key
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "signatures.json")
+	data := `[{"message": "hello", "signature": "0x` + hex.EncodeToString(blob) + `"}, {"message": "hello", "signature": "` + hex.EncodeToString(blob[:64]) + `"}]`
+	if err := os.WriteFile(jsonPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	csvPath := filepath.Join(dir, "signatures.csv")
+	if err := os.WriteFile(csvPath, []byte("message,signature\nhello,0x"+hex.EncodeToString(blob)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, parser := range []SignatureParser{&JSONParser{Hash: HashEthereumMessage}, &CSVParser{Hash: HashEthereumMessage}} {
+		path := jsonPath
+		if _, ok := parser.(*CSVParser); ok {
+			path = csvPath
+		}
+		signatures, err := parser.ParseSignatures(path)
+		if err != nil {
+			t.Fatalf("%T: %v", parser, err)
+		}
+		sig := signatures[0]
+		if sig.Z.Cmp(z) != 0 || sig.R.Cmp(new(big.Int).SetBytes(blob[:32])) != 0 || sig.S.Cmp(new(big.Int).SetBytes(blob[32:64])) != 0 {
+			t.Errorf("%T: r || s || v read as z=%x r=%x s=%x", parser, sig.Z, sig.R, sig.S)
+		}
+		if key, err := RecoverPublicKey(sig); err != nil || !bytes.Equal(key, priv.PubKey().SerializeCompressed()) {
+			t.Errorf("%T: expected v to recover the signer's key, got %x, %v", parser, key, err)
+		}
+	}
+
+	signatures, _ := (&JSONParser{}).ParseSignatures(jsonPath)
+	if signatures[1].RecoveryID != nil || signatures[1].R.Cmp(signatures[0].R) != 0 {
+		t.Errorf("Expected a 64-byte signature to give r and s without a recovery id")
+	}
+	if _, err := (&JSONParser{}).Parse(bytes.NewReader([]byte(`[{"message": "m", "signature": "0x0102"}]`))); err == nil {
+		t.Error("Expected an error for a 2-byte signature")
+	}
+}
+
+func TestHashEthereumMessage(t *testing.T) {
+	// ethers.hashMessage("hello world")
+	if got := fmt.Sprintf("%064x", HashEthereumMessage([]byte("hello world"))); got != "d9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68" {
+		t.Errorf("Unexpected EIP-191 hash %s", got)
+	}
+}
+
+func TestParsers_Timestamp(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "signatures.json")
+	data := `[
+		{"z": "0x01", "r": "0x02", "s": "0x03", "timestamp": 1709294400},
+		{"z": "0x01", "r": "0x02", "s": "0x03", "timestamp": "2024-03-01T12:00:00Z"},
+		{"z": "0x01", "r": "0x02", "s": "0x03", "timestamp": 1709294400000}
+	]`
+	if err := os.WriteFile(jsonPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	signatures, err := (&JSONParser{ZField: "z"}).ParseSignatures(jsonPath)
+	if err != nil {
+		t.Fatalf("ParseSignatures failed: %v", err)
+	}
+	for i, sig := range signatures {
+		if !sig.Timestamp.Equal(want) {
+			t.Errorf("JSON signature %d: timestamp %v, expected %v", i, sig.Timestamp, want)
+		}
+	}
+
+	csvPath := filepath.Join(dir, "signatures.csv")
+	if err := os.WriteFile(csvPath, []byte("z,r,s,timestamp\n0x01,0x02,0x03,1709294400\n0x01,0x02,0x03,\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	signatures, err = (&CSVParser{ZCol: "z"}).ParseSignatures(csvPath)
+	if err != nil {
+		t.Fatalf("ParseSignatures failed: %v", err)
+	}
+	if !signatures[0].Timestamp.Equal(want) || !signatures[1].Timestamp.IsZero() {
+		t.Errorf("CSV timestamps = %v, %v", signatures[0].Timestamp, signatures[1].Timestamp)
+	}
+
+	if err := os.WriteFile(jsonPath, []byte(`[{"z": "0x01", "r": "0x02", "s": "0x03", "timestamp": "yesterday"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&JSONParser{ZField: "z"}).ParseSignatures(jsonPath); err == nil {
+		t.Error("Expected an error for an unparseable timestamp")
+	}
+}
+
+func TestParsers_Metadata(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "signatures.json")
+	data := `[
+		{"z": "0x01", "r": "0x02", "s": "0x03", "sequence": 7, "block_height": 840000},
+		{"z": "0x01", "r": "0x02", "s": "0x03", "nonce_index": "8", "block_number": "0xcd141"},
+		{"z": "0x01", "r": "0x02", "s": "0x03"}
+	]`
+	if err := os.WriteFile(jsonPath, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	signatures, err := (&JSONParser{ZField: "z"}).ParseSignatures(jsonPath)
+	if err != nil {
+		t.Fatalf("ParseSignatures failed: %v", err)
+	}
+	for i, want := range [][2]int64{{7, 840000}, {8, 840001}} {
+		sig := signatures[i]
+		if sig.Sequence == nil || *sig.Sequence != want[0] || sig.BlockHeight == nil || *sig.BlockHeight != want[1] {
+			t.Errorf("JSON signature %d: sequence %v, block height %v, expected %v", i, sig.Sequence, sig.BlockHeight, want)
+		}
+	}
+	if signatures[2].Sequence != nil || signatures[2].BlockHeight != nil {
+		t.Error("Expected no metadata on the third JSON signature")
+	}
+
+	csvPath := filepath.Join(dir, "signatures.csv")
+	if err := os.WriteFile(csvPath, []byte("z,r,s,nonce_index,block_height\n0x01,0x02,0x03,3,0x10\n0x01,0x02,0x03,,\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	signatures, err = (&CSVParser{ZCol: "z"}).ParseSignatures(csvPath)
+	if err != nil {
+		t.Fatalf("ParseSignatures failed: %v", err)
+	}
+	if signatures[0].Sequence == nil || *signatures[0].Sequence != 3 || signatures[0].BlockHeight == nil || *signatures[0].BlockHeight != 16 {
+		t.Errorf("CSV metadata = %v, %v", signatures[0].Sequence, signatures[0].BlockHeight)
+	}
+	if signatures[1].Sequence != nil || signatures[1].BlockHeight != nil {
+		t.Error("Expected empty CSV cells to leave metadata unset")
+	}
+
+	if err := os.WriteFile(jsonPath, []byte(`[{"z": "0x01", "r": "0x02", "s": "0x03", "sequence": "seven"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&JSONParser{ZField: "z"}).ParseSignatures(jsonPath); err == nil {
+		t.Error("Expected an error for an unparseable sequence number")
+	}
+}
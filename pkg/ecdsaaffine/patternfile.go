@@ -0,0 +1,277 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+)
+
+// maxSuggestionsPerSource caps the suggestions SuggestPatterns makes from one kind of
+// metadata; a jittery clock would otherwise suggest every interval it saw once.
+const maxSuggestionsPerSource = 8
+
+// minSuggestionConfidence is the share of a signer's consecutive pairs a metadata
+// difference must account for to be suggested.
+const minSuggestionConfidence = 0.1
+
+// PatternSuggestion is a relationship SuggestPatterns proposes, with how strongly the
+// dataset supports it.
+type PatternSuggestion struct {
+	Pattern
+
+	// Confidence is the share of consecutive signature pairs, among those with the
+	// metadata the suggestion came from, that show it (1 for a repeated r)
+	Confidence float64
+
+	Support int    // consecutive signature pairs that show it
+	Reason  string // what in the dataset suggests it
+}
+
+// SuggestPatterns reads the relationships a dataset's statistics point to, ranked most
+// likely first, for a search to try before its ranges (see WritePatternFile and
+// LoadPatternFile). A repeated r suggests the same nonce, and a signer's consecutive
+// signatures with nonces in arithmetic progression (FindProgressions) suggest its step,
+// both with confidence 1. Otherwise, for each signer's consecutive signatures, it counts the counter steps MetadataHypotheses proposes from
+// sequence numbers, block heights and signing times: a difference most pairs share is
+// likely the step of a nonce counter driven by the same clock. Datasets whose nonces
+// look deterministic (see AnalyzeDataset) get no metadata suggestions.
+func SuggestPatterns(signatures []*Signature) []PatternSuggestion {
+	analysis := AnalyzeDataset(signatures)
+	var suggestions []PatternSuggestion
+	if analysis.DuplicateR > 0 {
+		suggestions = append(suggestions, PatternSuggestion{
+			Pattern:    Pattern{A: big.NewInt(1), B: big.NewInt(0), Name: "same_nonce"},
+			Confidence: 1,
+			Support:    analysis.DuplicateR,
+			Reason:     fmt.Sprintf("%d signature(s) reuse an r value", analysis.DuplicateR),
+		})
+	}
+
+	// A repeated r ranks first; the rest by confidence
+	rest := progressionSuggestions(signatures)
+	if analysis.NonceSource != NonceSourceDeterministic {
+		rest = append(rest, metadataSuggestions(signatures)...)
+	}
+	sortSuggestions(rest)
+	suggestions = append(suggestions, rest...)
+	for i := range suggestions {
+		suggestions[i].Priority = i
+	}
+	return suggestions
+}
+
+// metadataSuggestions suggests the counter steps most of a signer's consecutive pairs
+// show in their metadata (see SuggestPatterns).
+func metadataSuggestions(signatures []*Signature) []PatternSuggestion {
+	// Steps by metadata source, over every signer's consecutive pairs
+	type step struct {
+		name  string
+		delta int64
+	}
+	counts := make(map[step]int)
+	pairs := make(map[string]int) // consecutive pairs each source had metadata for
+	for _, group := range GroupByPublicKey(signatures) {
+		for k := 1; k < len(group.Signatures); k++ {
+			for _, pattern := range MetadataHypotheses(group.Signatures[k-1], group.Signatures[k]) {
+				counts[step{pattern.Name, pattern.B.Int64()}]++
+			}
+			// Zero differences are not proposed but still count as pairs
+			for name, ok := range metadataSources(group.Signatures[k-1], group.Signatures[k]) {
+				if ok {
+					pairs[name]++
+				}
+			}
+		}
+	}
+
+	var suggestions []PatternSuggestion
+	bySource := make(map[string][]PatternSuggestion)
+	for s, count := range counts {
+		confidence := float64(count) / float64(pairs[s.name])
+		if confidence < minSuggestionConfidence {
+			continue
+		}
+		bySource[s.name] = append(bySource[s.name], PatternSuggestion{
+			Pattern:    Pattern{A: big.NewInt(1), B: big.NewInt(s.delta), Name: fmt.Sprintf("%s_%+d", s.name, s.delta)},
+			Confidence: confidence,
+			Support:    count,
+			Reason:     fmt.Sprintf("%d of %d consecutive pairs differ by %d in %s", count, pairs[s.name], s.delta, metadataSourceNames[s.name]),
+		})
+	}
+	for _, source := range bySource {
+		sortSuggestions(source)
+		suggestions = append(suggestions, source[:min(len(source), maxSuggestionsPerSource)]...)
+	}
+	return suggestions
+}
+
+// progressionSuggestions suggests the relationship of each distinct nonce progression
+// FindProgressions detects in a signer's signatures.
+func progressionSuggestions(signatures []*Signature) []PatternSuggestion {
+	var suggestions []PatternSuggestion
+	index := make(map[string]int)
+	for _, group := range GroupByPublicKey(signatures) {
+		for _, p := range FindProgressions(group.Signatures) {
+			name := fmt.Sprintf("progression_a%s_b%s", p.A, p.B)
+			if k, ok := index[name]; ok {
+				suggestions[k].Support += p.End - p.Start
+				continue
+			}
+			index[name] = len(suggestions)
+			suggestions = append(suggestions, PatternSuggestion{
+				Pattern:    Pattern{A: p.A, B: p.B, Name: name},
+				Confidence: 1,
+				Support:    p.End - p.Start,
+				Reason:     fmt.Sprintf("%d consecutive signatures have nonces in arithmetic progression", p.End-p.Start+1),
+			})
+		}
+	}
+	return suggestions
+}
+
+// metadataSourceNames describes the pattern names MetadataHypotheses gives each source.
+var metadataSourceNames = map[string]string{
+	"metadata_sequence":     "sequence number",
+	"metadata_block_height": "block height",
+	"metadata_timestamp":    "signing time (seconds)",
+	"metadata_timestamp_ms": "signing time (milliseconds)",
+}
+
+// metadataSources reports which sources MetadataHypotheses can read for a pair.
+func metadataSources(sig1, sig2 *Signature) map[string]bool {
+	timed := !sig1.Timestamp.IsZero() && !sig2.Timestamp.IsZero()
+	return map[string]bool{
+		"metadata_sequence":     sig1.Sequence != nil && sig2.Sequence != nil,
+		"metadata_block_height": sig1.BlockHeight != nil && sig2.BlockHeight != nil,
+		"metadata_timestamp":    timed,
+		"metadata_timestamp_ms": timed && (sig1.Timestamp.Nanosecond() != 0 || sig2.Timestamp.Nanosecond() != 0),
+	}
+}
+
+// sortSuggestions orders suggestions by confidence, then support, then smaller steps.
+func sortSuggestions(suggestions []PatternSuggestion) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.Support != b.Support {
+			return a.Support > b.Support
+		}
+		if c := a.B.CmpAbs(b.B); c != 0 {
+			return c < 0
+		}
+		return a.Name < b.Name
+	})
+}
+
+// PatternFile is the JSON form of a ranked pattern list, as written by WritePatternFile
+// and read by LoadPatternFile:
+//
+//	{"patterns": [{"name": "counter_+7", "a": "1", "b": "7", "priority": 0}]}
+//
+// a and b are decimal or 0x-prefixed hex, as strings or JSON numbers; confidence,
+// support and reason are informational.
+type PatternFile struct {
+	Patterns []PatternFileEntry `json:"patterns"`
+}
+
+// PatternFileEntry is one pattern of a PatternFile.
+type PatternFileEntry struct {
+	Name       string          `json:"name"`
+	A          json.RawMessage `json:"a"`
+	B          json.RawMessage `json:"b"`
+	Priority   int             `json:"priority"`
+	Confidence float64         `json:"confidence,omitempty"`
+	Support    int             `json:"support,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+}
+
+// WritePatternFile writes suggestions to w as a PatternFile, in order.
+func WritePatternFile(w io.Writer, suggestions []PatternSuggestion) error {
+	file := PatternFile{Patterns: make([]PatternFileEntry, len(suggestions))}
+	for i, s := range suggestions {
+		a, _ := json.Marshal(s.A.String())
+		b, _ := json.Marshal(s.B.String())
+		file.Patterns[i] = PatternFileEntry{
+			Name:       s.Name,
+			A:          a,
+			B:          b,
+			Priority:   s.Priority,
+			Confidence: s.Confidence,
+			Support:    s.Support,
+			Reason:     s.Reason,
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// LoadPatternFile reads the patterns of a PatternFile, ordered by priority (stable), for
+// PatternConfig.CustomPatterns.
+func LoadPatternFile(path string) ([]Pattern, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := ParsePatternFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// ParsePatternFile is LoadPatternFile for a file's contents.
+func ParsePatternFile(data []byte) ([]Pattern, error) {
+	var file PatternFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse pattern file: %w", err)
+	}
+
+	patterns := make([]Pattern, len(file.Patterns))
+	for i, entry := range file.Patterns {
+		a, err := patternFileValue(entry.A)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %d: a: %w", i, err)
+		}
+		b, err := patternFileValue(entry.B)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %d: b: %w", i, err)
+		}
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("pattern_%d", i)
+		}
+		patterns[i] = Pattern{A: a, B: b, Name: name, Priority: entry.Priority}
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return patterns[i].Priority < patterns[j].Priority })
+	return patterns, nil
+}
+
+// patternFileValue parses a or b: a JSON number, or a decimal or 0x hex string.
+func patternFileValue(raw json.RawMessage) (*big.Int, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("missing")
+	}
+	var val interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+	if s, ok := val.(string); ok {
+		v, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return v, nil
+	}
+	return parseBigInt(val)
+}
@@ -0,0 +1,110 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestSuggestPatterns(t *testing.T) {
+	// Nonces step by 7 with the sequence number, except for one gap
+	d := big.NewInt(0x5e9)
+	signatures := storeTestSignatures(d, 11)
+	for i, sig := range signatures {
+		n := int64(7 * i)
+		if i == 10 {
+			n += 7
+		}
+		sig.Sequence = &n
+	}
+
+	// The nonces themselves are in arithmetic progression, which ranks first
+	suggestions := SuggestPatterns(signatures)
+	if len(suggestions) != 3 {
+		t.Fatalf("Expected the progression, the step and the gap, got %+v", suggestions)
+	}
+	top := suggestions[0]
+	if top.Name != "progression_a1_b7" || top.B.Int64() != 7 || top.Support != 10 || top.Confidence != 1 || top.Priority != 0 {
+		t.Errorf("Unexpected top suggestion %+v", top)
+	}
+	step := suggestions[1]
+	if step.Name != "metadata_sequence_+7" || step.A.Int64() != 1 || step.B.Int64() != 7 || step.Support != 9 || step.Confidence != 0.9 || step.Priority != 1 {
+		t.Errorf("Unexpected second suggestion %+v", step)
+	}
+	if suggestions[2].B.Int64() != 14 || suggestions[2].Priority != 2 {
+		t.Errorf("Unexpected third suggestion %+v", suggestions[2])
+	}
+
+	// A repeated r ranks first
+	signatures[3].R = signatures[8].R
+	if suggestions := SuggestPatterns(signatures); suggestions[0].Name != "same_nonce" || suggestions[0].B.Sign() != 0 {
+		t.Errorf("Expected same_nonce first, got %+v", suggestions[0])
+	}
+
+	// Deterministic nonces suggest nothing
+	repeated := []*Signature{signatures[0], signatures[0], signatures[1]}
+	if suggestions := SuggestPatterns(repeated); len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions for deterministic nonces, got %+v", suggestions)
+	}
+}
+
+func TestPatternFile_RoundTrip(t *testing.T) {
+	d := big.NewInt(0x5e9)
+	signatures := storeTestSignatures(d, 6)
+	for i, sig := range signatures {
+		n := int64(100 + 7*i)
+		sig.Sequence = &n
+	}
+	var buf bytes.Buffer
+	if err := WritePatternFile(&buf, SuggestPatterns(signatures)); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := ParsePatternFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 2 || patterns[0].B.Int64() != 7 || patterns[1].Name != "progression_a1_b7" {
+		t.Fatalf("Unexpected patterns %+v", patterns)
+	}
+
+	// The suggested step finds the key in the dataset without its metadata
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{CustomPatterns: patterns})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	result := strategy.Search(context.Background(), Sanitize(signatures, SanitizeOptions{}), publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 || result.Pattern != "metadata_sequence_+7" {
+		t.Errorf("Expected the suggested pattern to recover the key, got %+v", result)
+	}
+}
+
+func TestParsePatternFile(t *testing.T) {
+	data := `{"patterns": [
+		{"name": "late", "a": 1, "b": "-0x10", "priority": 5},
+		{"a": "2", "b": 3, "priority": 1},
+		{"name": "huge", "a": "1", "b": "115792089237316195423570985008687907852837564279074904382605163141518161494336", "priority": 1}
+	]}`
+	patterns, err := ParsePatternFile([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 3 || patterns[0].Name != "pattern_1" || patterns[1].Name != "huge" || patterns[2].Name != "late" {
+		t.Fatalf("Expected patterns by priority, stable, got %+v", patterns)
+	}
+	if patterns[0].A.Int64() != 2 || patterns[2].B.Int64() != -16 || centered(patterns[1].B).Int64() != -1 {
+		t.Errorf("Unexpected values %+v", patterns)
+	}
+
+	for _, bad := range []string{
+		`{"patterns": [{"a": 1}]}`,
+		`{"patterns": [{"a": "x", "b": 1}]}`,
+		`[1, 2]`,
+	} {
+		if _, err := ParsePatternFile([]byte(bad)); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"strings"
+)
+
+// The phase strategies run one phase of SmartBruteForceStrategy on its own. Each embeds
+// a SmartBruteForceStrategy for its settings (those the phase does not use are ignored)
+// and is configured like one, including by Client options. Combine them, with each other
+// or with other strategies, in a ChainStrategy.
+
+// SameNonceStrategy only looks for signatures that reuse a nonce (identical r values).
+type SameNonceStrategy struct {
+	SmartBruteForceStrategy
+}
+
+// NewSameNonceStrategy creates a same nonce strategy.
+func NewSameNonceStrategy() *SameNonceStrategy {
+	return &SameNonceStrategy{}
+}
+
+// Name returns the name of this strategy.
+func (s *SameNonceStrategy) Name() string {
+	return "SameNonce"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *SameNonceStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 {
+		return nil
+	}
+	return s.found(s.sameNoncePhase(signatures, publicKey))
+}
+
+// PatternStrategy only tries the relationships its PatternConfig enables: metadata
+// hypotheses, common patterns and custom patterns, in that order.
+type PatternStrategy struct {
+	SmartBruteForceStrategy
+}
+
+// NewPatternStrategy creates a pattern strategy with the default pattern configuration.
+func NewPatternStrategy() *PatternStrategy {
+	s := &PatternStrategy{}
+	s.RangeConfig = DefaultRangeConfig()
+	s.PatternConfig = DefaultPatternConfig()
+	return s
+}
+
+// Name returns the name of this strategy.
+func (s *PatternStrategy) Name() string {
+	return "Pattern"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *PatternStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 {
+		return nil
+	}
+	return s.found(s.patternPhases(ctx, signatures, publicKey))
+}
+
+// RangeStrategy only searches the single range RangeConfig.ARange × RangeConfig.BRange,
+// without the adaptive search's schedule of ranges.
+type RangeStrategy struct {
+	SmartBruteForceStrategy
+}
+
+// NewRangeStrategy creates a range strategy for a in aRange and b in bRange (inclusive),
+// with the other settings of DefaultRangeConfig.
+func NewRangeStrategy(aRange, bRange [2]int) *RangeStrategy {
+	s := &RangeStrategy{}
+	s.RangeConfig = DefaultRangeConfig()
+	s.RangeConfig.ARange, s.RangeConfig.BRange = aRange, bRange
+	return s
+}
+
+// Name returns the name of this strategy.
+func (s *RangeStrategy) Name() string {
+	return "Range"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *RangeStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 {
+		return nil
+	}
+	r := rangePhase{s.RangeConfig.ARange, s.RangeConfig.BRange, "Range search"}
+	result, _ := s.searchRange(ctx, signatures, publicKey, r)
+	return s.found(result)
+}
+
+// AdaptiveStrategy only runs the adaptive range search: the built-in schedule of
+// expanding ranges, the configured range if it differs from the default, or the ranges
+// Expansion chooses.
+type AdaptiveStrategy struct {
+	SmartBruteForceStrategy
+}
+
+// NewAdaptiveStrategy creates an adaptive strategy with the default range configuration.
+func NewAdaptiveStrategy() *AdaptiveStrategy {
+	s := &AdaptiveStrategy{}
+	s.RangeConfig = DefaultRangeConfig()
+	return s
+}
+
+// Name returns the name of this strategy.
+func (s *AdaptiveStrategy) Name() string {
+	return "Adaptive"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *AdaptiveStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 {
+		return nil
+	}
+	return s.found(s.adaptiveRangeSearch(ctx, signatures, publicKey))
+}
+
+// ChainStrategy runs its strategies in order and returns the first key found, e.g. a
+// SameNonceStrategy and then a RangeStrategy over the range of interest.
+type ChainStrategy struct {
+	Strategies []BruteForceStrategy
+}
+
+// NewChainStrategy creates a strategy running strategies in order.
+func NewChainStrategy(strategies ...BruteForceStrategy) *ChainStrategy {
+	return &ChainStrategy{Strategies: strategies}
+}
+
+// Name returns the name of this strategy.
+func (s *ChainStrategy) Name() string {
+	names := make([]string, len(s.Strategies))
+	for i, strategy := range s.Strategies {
+		names[i] = strategy.Name()
+	}
+	return "Chain(" + strings.Join(names, ", ") + ")"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *ChainStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	for _, strategy := range s.Strategies {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if result := strategy.Search(ctx, signatures, publicKey); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// smartStrategy is implemented by SmartBruteForceStrategy and the phase strategies
+// embedding it, giving access to their settings.
+type smartStrategy interface {
+	smart() *SmartBruteForceStrategy
+}
+
+func (s *SmartBruteForceStrategy) smart() *SmartBruteForceStrategy {
+	return s
+}
@@ -0,0 +1,81 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestPhaseStrategies(t *testing.T) {
+	d := big.NewInt(0xabcdef)
+	k := big.NewInt(424242)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	sameNonce := []*Signature{
+		signWithNonce(d, k, HashMessage([]byte("message 0"))),
+		signWithNonce(d, k, HashMessage([]byte("message 1"))),
+	}
+	counter := []*Signature{
+		signWithNonce(d, k, HashMessage([]byte("message 0"))),
+		signWithNonce(d, new(big.Int).Add(k, big.NewInt(1)), HashMessage([]byte("message 1"))),
+	}
+	offset := []*Signature{
+		signWithNonce(d, k, HashMessage([]byte("message 0"))),
+		signWithNonce(d, new(big.Int).Add(k, big.NewInt(1500)), HashMessage([]byte("message 1"))),
+	}
+	quiet := log.New(io.Discard, "", 0)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		strategy   BruteForceStrategy
+		signatures []*Signature
+		found      bool
+	}{
+		{"same nonce finds reuse", NewSameNonceStrategy(), sameNonce, true},
+		{"same nonce ignores counters", NewSameNonceStrategy(), counter, false},
+		{"pattern finds counter", NewPatternStrategy(), counter, true},
+		{"pattern ignores large offset", NewPatternStrategy(), offset, false},
+		{"range finds offset inside", NewRangeStrategy([2]int{1, 1}, [2]int{1000, 2000}), offset, true},
+		{"range ignores offset outside", NewRangeStrategy([2]int{1, 1}, [2]int{-10, 100}), offset, false},
+		{"adaptive finds offset", NewAdaptiveStrategy(), offset, true},
+		{"chain finds with any member", NewChainStrategy(NewSameNonceStrategy(), NewPatternStrategy()), counter, true},
+		{"chain misses with no member", NewChainStrategy(NewSameNonceStrategy(), NewPatternStrategy()), offset, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NewClient(WithLogger(quiet), WithStrategy(tt.strategy))
+			result := tt.strategy.Search(ctx, tt.signatures, publicKey)
+			if !tt.found {
+				if result != nil {
+					t.Fatalf("Expected no key, got %+v", result)
+				}
+				return
+			}
+			if result == nil || result.PrivateKey.Cmp(d) != 0 || !result.Verified {
+				t.Fatalf("Expected the verified key, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestClient_ConfiguresChainMembers(t *testing.T) {
+	pattern := NewPatternStrategy()
+	bsgs := NewBSGSStrategy()
+	logger := log.New(io.Discard, "", 0)
+	chain := NewChainStrategy(NewSameNonceStrategy(), NewChainStrategy(pattern, bsgs))
+	NewClient(WithStrategy(chain), WithLogger(logger), WithVerification(ReferenceVerification))
+
+	if pattern.Logger != logger || bsgs.Logger != logger {
+		t.Error("Expected the logger to reach every strategy of the chain")
+	}
+	if pattern.RangeConfig.Verification != ReferenceVerification {
+		t.Error("Expected the verification backend to reach the pattern strategy")
+	}
+	if got, want := chain.Name(), "Chain(SameNonce, Chain(Pattern, BSGS))"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
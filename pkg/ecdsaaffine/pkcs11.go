@@ -0,0 +1,245 @@
+package ecdsaaffine
+
+import (
+	"bufio"
+	"crypto"
+	_ "crypto/sha1" // registered for the CKM_ECDSA_SHA* mechanisms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
+)
+
+// PKCS11Parser parses the ECDSA signatures of a PKCS#11 trace, as written by OpenSC's
+// pkcs11-spy (PKCS11SPY_OUTPUT), so HSM and smart-card audit logs can be searched
+// without conversion. Each successful C_Sign, or C_SignUpdate run ending in C_SignFinal,
+// of a session initialized with CKM_ECDSA or CKM_ECDSA_SHA1 to SHA512 becomes a
+// signature; other mechanisms and failed calls are skipped.
+//
+// CKM_ECDSA signs data the application hashed itself, so pData is the digest; the
+// CKM_ECDSA_SHA* mechanisms hash pData on the token. Either way the digest is
+// truncated to the bit length of the curve order (see TruncateDigest), which matters for
+// SHA-384 and SHA-512 digests signed with a 256-bit key. The signature is the raw
+// r||s concatenation PKCS#11 specifies.
+type PKCS11Parser struct {
+	// Order is the order n of the signing curve (default: Secp256k1CurveOrder). Set
+	// elliptic.P256().Params().N for secp256r1 (P-256) tokens.
+	Order *big.Int
+}
+
+// ParseSignatures parses signatures from a pkcs11-spy log, given as a path or URI as
+// for JSONParser.
+func (p *PKCS11Parser) ParseSignatures(logFile string) ([]*Signature, error) {
+	file, err := source.Open(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return p.Parse(file)
+}
+
+// pkcs11Call is one function call of a pkcs11-spy log.
+type pkcs11Call struct {
+	line      int
+	function  string
+	session   string
+	mechanism string
+	data      []byte
+	signature []byte
+	timestamp time.Time
+	ok        bool
+}
+
+var (
+	pkcs11CallLine   = regexp.MustCompile(`^\d+: (C_\w+)`)
+	pkcs11BufferLine = regexp.MustCompile(`^\[(in|out)\] (\w+)\[[^\]]*\] \S+ / (\d+)$`)
+	pkcs11DumpLine   = regexp.MustCompile(`^[0-9A-Fa-f]{8}  `)
+	pkcs11Mechanism  = regexp.MustCompile(`pMechanism->type=(CKM_\w+)`)
+)
+
+// pkcs11Timestamp is the layout of the time pkcs11-spy writes after each call's name.
+const pkcs11Timestamp = "2006-01-02 15:04:05.000"
+
+// Parse parses signatures in the format ParseSignatures reads from r.
+func (p *PKCS11Parser) Parse(r io.Reader) ([]*Signature, error) {
+	order := p.Order
+	if order == nil {
+		order = Secp256k1CurveOrder
+	}
+
+	var signatures []*Signature
+	mechanisms := map[string]string{} // session -> mechanism of its C_SignInit
+	pending := map[string][]byte{}    // session -> data of its C_SignUpdate calls
+	finish := func(call *pkcs11Call) error {
+		if call == nil || !call.ok {
+			return nil
+		}
+		switch call.function {
+		case "C_SignInit":
+			mechanisms[call.session] = call.mechanism
+			delete(pending, call.session)
+		case "C_SignUpdate":
+			pending[call.session] = append(pending[call.session], call.data...)
+		case "C_Sign", "C_SignFinal":
+			if len(call.signature) == 0 {
+				return nil // a length query, with no signature buffer
+			}
+			data := call.data
+			if call.function == "C_SignFinal" {
+				data = pending[call.session]
+			}
+			mechanism := mechanisms[call.session]
+			delete(mechanisms, call.session)
+			delete(pending, call.session)
+
+			sig, err := pkcs11Signature(mechanism, data, call.signature, order)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", call.line, err)
+			}
+			if sig != nil {
+				sig.Timestamp = call.timestamp
+				signatures = append(signatures, sig)
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var call *pkcs11Call
+	var buffer *[]byte // the buffer whose hex dump is being read
+	size := 0          // its length
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// A dump has a line per 16 bytes, after its buffer's line; a NULL buffer has none
+		if buffer != nil && len(*buffer) < size && pkcs11DumpLine.MatchString(line) {
+			fields := strings.Fields(line)
+			for _, field := range fields[1:min(len(fields), 1+16, 1+size-len(*buffer))] {
+				b, err := hex.DecodeString(field)
+				if err != nil || len(b) != 1 {
+					return nil, fmt.Errorf("line %d: invalid hex dump byte %q", lineNumber, field)
+				}
+				*buffer = append(*buffer, b[0])
+			}
+			continue
+		}
+		if buffer != nil && len(*buffer) > 0 && len(*buffer) < size {
+			return nil, fmt.Errorf("line %d: hex dump ends after %d of %d bytes", lineNumber, len(*buffer), size)
+		}
+		buffer = nil
+
+		if m := pkcs11CallLine.FindStringSubmatch(line); m != nil {
+			if err := finish(call); err != nil {
+				return nil, err
+			}
+			call = &pkcs11Call{line: lineNumber, function: m[1]}
+			continue
+		}
+		if call == nil {
+			continue
+		}
+
+		switch {
+		case call.timestamp.IsZero() && len(line) >= len(pkcs11Timestamp) && line[0] >= '0' && line[0] <= '9':
+			if t, err := time.Parse(pkcs11Timestamp, line[:len(pkcs11Timestamp)]); err == nil {
+				call.timestamp = t
+			}
+		case strings.HasPrefix(line, "[in] hSession = "):
+			call.session = strings.TrimPrefix(line, "[in] hSession = ")
+		case pkcs11Mechanism.MatchString(line):
+			call.mechanism = pkcs11Mechanism.FindStringSubmatch(line)[1]
+		case strings.HasPrefix(line, "Returned:"):
+			call.ok = strings.Contains(line, "CKR_OK")
+		default:
+			m := pkcs11BufferLine.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			size, _ = strconv.Atoi(m[3])
+			switch m[2] {
+			case "pData", "pPart":
+				call.data, buffer = nil, &call.data
+			case "pSignature":
+				call.signature, buffer = nil, &call.signature
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	if buffer != nil && len(*buffer) > 0 && len(*buffer) < size {
+		return nil, fmt.Errorf("line %d: hex dump ends after %d of %d bytes", lineNumber, len(*buffer), size)
+	}
+	if err := finish(call); err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
+
+// pkcs11Hashes are the hashes of the CKM_ECDSA_SHA* mechanisms.
+var pkcs11Hashes = map[string]crypto.Hash{
+	"CKM_ECDSA_SHA1":   crypto.SHA1,
+	"CKM_ECDSA_SHA224": crypto.SHA224,
+	"CKM_ECDSA_SHA256": crypto.SHA256,
+	"CKM_ECDSA_SHA384": crypto.SHA384,
+	"CKM_ECDSA_SHA512": crypto.SHA512,
+}
+
+// pkcs11Signature returns the signature of a signing operation with mechanism over data,
+// or nil if the mechanism is not ECDSA. ECDSA with a hash the module does not implement
+// (CKM_ECDSA_SHA3_*) is an error rather than skipped.
+func pkcs11Signature(mechanism string, data, signature []byte, order *big.Int) (*Signature, error) {
+	digest := data
+	if mechanism != "CKM_ECDSA" {
+		hash, ok := pkcs11Hashes[mechanism]
+		if !ok && strings.HasPrefix(mechanism, "CKM_ECDSA") {
+			return nil, fmt.Errorf("unsupported mechanism %s", mechanism)
+		}
+		if !ok {
+			return nil, nil
+		}
+		h := hash.New()
+		h.Write(data)
+		digest = h.Sum(nil)
+	}
+	if len(digest) == 0 {
+		return nil, fmt.Errorf("%s: no data signed", mechanism)
+	}
+	if len(signature) == 0 || len(signature)%2 != 0 {
+		return nil, fmt.Errorf("%s: signature of %d bytes is not r||s", mechanism, len(signature))
+	}
+	half := len(signature) / 2
+	return &Signature{
+		Z: TruncateDigest(digest, order),
+		R: new(big.Int).SetBytes(signature[:half]),
+		S: new(big.Int).SetBytes(signature[half:]),
+	}, nil
+}
+
+// TruncateDigest converts a message digest to the integer z an ECDSA signature over a
+// curve of the given order signs, mod the order. A digest longer than the order keeps
+// its leftmost bits, as many as the order has (SEC1 section 4.1.3, step 5); a 384-bit
+// digest signed with a 256-bit key is not reduced mod n.
+func TruncateDigest(digest []byte, order *big.Int) *big.Int {
+	bits := order.BitLen()
+	if size := (bits + 7) / 8; len(digest) > size {
+		digest = digest[:size]
+	}
+	z := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - bits; excess > 0 {
+		z.Rsh(z, uint(excess))
+	}
+	return z.Mod(z, order)
+}
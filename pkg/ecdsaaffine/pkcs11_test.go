@@ -0,0 +1,174 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// spyBuffer writes a buffer as pkcs11-spy dumps it: a line with its length, then 16
+// bytes per line with their ASCII rendering.
+func spyBuffer(b *strings.Builder, direction, name string, data []byte) {
+	fmt.Fprintf(b, "[%s] %s[ulDataLen] 0x7ffd5c3e1a40 / %d\n", direction, name, len(data))
+	for offset := 0; offset < len(data); offset += 16 {
+		line := data[offset:min(offset+16, len(data))]
+		hexBytes := make([]string, len(line))
+		for k, c := range line {
+			hexBytes[k] = fmt.Sprintf("%02X", c)
+		}
+		// The ASCII column of a short line could pass for more bytes
+		fmt.Fprintf(b, "    %08X  %-48s AB CD\n", offset, strings.Join(hexBytes, " "))
+	}
+}
+
+// spyCall writes one call of a pkcs11-spy log.
+func spyCall(b *strings.Builder, n int, function string, body func(), rv string) {
+	fmt.Fprintf(b, "%d: %s\n2024-03-01 10:00:%02d.250\n[in] hSession = 0x1\n", n, function, n)
+	body()
+	fmt.Fprintf(b, "Returned:  %s\n\n", rv)
+}
+
+func rawSignature(sig *Signature) []byte {
+	raw := make([]byte, 64)
+	sig.R.FillBytes(raw[:32])
+	sig.S.FillBytes(raw[32:])
+	return raw
+}
+
+func TestPKCS11Parser(t *testing.T) {
+	d := big.NewInt(0x5ec7e7)
+	digest := sha256.Sum256([]byte("first"))
+	message := []byte("second, signed in two parts by the token")
+	hashed := sha256.Sum256(message)
+	first := signWithNonce(d, big.NewInt(4242), new(big.Int).SetBytes(digest[:]))
+	second := signWithNonce(d, big.NewInt(4242+17), new(big.Int).SetBytes(hashed[:]))
+
+	var log strings.Builder
+	n := 0
+	call := func(function string, body func(), rv string) {
+		n++
+		spyCall(&log, n, function, body, rv)
+	}
+	// CKM_ECDSA over a digest, with a length query first
+	call("C_SignInit", func() { log.WriteString("[in] pMechanism->type=CKM_ECDSA\n[in] hKey = 0x2\n") }, "0 CKR_OK")
+	call("C_Sign", func() {
+		spyBuffer(&log, "in", "pData", digest[:])
+		log.WriteString("[out] pSignature[*pulSignatureLen] (nil) / 64\n")
+	}, "0 CKR_OK")
+	call("C_Sign", func() {
+		spyBuffer(&log, "in", "pData", digest[:])
+		spyBuffer(&log, "out", "pSignature", rawSignature(first))
+	}, "0 CKR_OK")
+	// RSA and failed calls are skipped
+	call("C_SignInit", func() { log.WriteString("[in] pMechanism->type=CKM_SHA256_RSA_PKCS\n") }, "0 CKR_OK")
+	call("C_Sign", func() {
+		spyBuffer(&log, "in", "pData", []byte("rsa"))
+		spyBuffer(&log, "out", "pSignature", make([]byte, 256))
+	}, "0 CKR_OK")
+	call("C_SignInit", func() { log.WriteString("[in] pMechanism->type=CKM_ECDSA\n") }, "0 CKR_OK")
+	call("C_Sign", func() { spyBuffer(&log, "in", "pData", digest[:]) }, "0x101 CKR_USER_NOT_LOGGED_IN")
+	// CKM_ECDSA_SHA256 in two parts, hashed by the token
+	call("C_SignInit", func() { log.WriteString("[in] pMechanism->type=CKM_ECDSA_SHA256\n") }, "0 CKR_OK")
+	call("C_SignUpdate", func() { spyBuffer(&log, "in", "pPart", message[:10]) }, "0 CKR_OK")
+	call("C_SignUpdate", func() { spyBuffer(&log, "in", "pPart", message[10:]) }, "0 CKR_OK")
+	call("C_SignFinal", func() { spyBuffer(&log, "out", "pSignature", rawSignature(second)) }, "0 CKR_OK")
+
+	signatures, err := (&PKCS11Parser{}).Parse(strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != 2 {
+		t.Fatalf("Expected 2 signatures, got %d", len(signatures))
+	}
+	for k, want := range []*Signature{first, second} {
+		got := signatures[k]
+		if got.Z.Cmp(want.Z) != 0 || got.R.Cmp(want.R) != 0 || got.S.Cmp(want.S) != 0 {
+			t.Errorf("Signature %d: got (z, r, s) = (%x, %x, %x), want (%x, %x, %x)", k, got.Z, got.R, got.S, want.Z, want.R, want.S)
+		}
+	}
+	if got := signatures[1].Timestamp.Format("15:04:05.000"); got != "10:00:11.250" {
+		t.Errorf("Expected the C_SignFinal time, got %s", got)
+	}
+
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, "")
+	if err != nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Errorf("Expected the key from the trace, got %v, %v", result, err)
+	}
+}
+
+func TestPKCS11Parser_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(b *strings.Builder)
+		want string
+	}{
+		{"truncated dump", func(b *strings.Builder) {
+			spyCall(b, 1, "C_SignInit", func() { b.WriteString("[in] pMechanism->type=CKM_ECDSA\n") }, "0 CKR_OK")
+			spyCall(b, 2, "C_Sign", func() {
+				spyBuffer(b, "in", "pData", make([]byte, 32))
+				b.WriteString("[out] pSignature[*pulSignatureLen] 0x7ffd / 64\n    00000000  01 02\n")
+			}, "0 CKR_OK")
+		}, "hex dump ends after 2 of 64 bytes"},
+		{"odd signature", func(b *strings.Builder) {
+			spyCall(b, 1, "C_SignInit", func() { b.WriteString("[in] pMechanism->type=CKM_ECDSA\n") }, "0 CKR_OK")
+			spyCall(b, 2, "C_Sign", func() {
+				spyBuffer(b, "in", "pData", make([]byte, 32))
+				spyBuffer(b, "out", "pSignature", make([]byte, 63))
+			}, "0 CKR_OK")
+		}, "not r||s"},
+		{"SHA-3", func(b *strings.Builder) {
+			spyCall(b, 1, "C_SignInit", func() { b.WriteString("[in] pMechanism->type=CKM_ECDSA_SHA3_256\n") }, "0 CKR_OK")
+			spyCall(b, 2, "C_Sign", func() {
+				spyBuffer(b, "in", "pData", []byte("data"))
+				spyBuffer(b, "out", "pSignature", make([]byte, 64))
+			}, "0 CKR_OK")
+		}, "unsupported mechanism"},
+	}
+	for _, tt := range tests {
+		var log strings.Builder
+		tt.log(&log)
+		if _, err := (&PKCS11Parser{}).Parse(strings.NewReader(log.String())); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+// TestTruncateDigest checks the z of a SHA-512 digest signed on P-256 against a signature
+// made by crypto/ecdsa, by verifying it with that z.
+func TestTruncateDigest(t *testing.T) {
+	curve := elliptic.P256()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha512.Sum512([]byte("pre-hashed with a longer hash"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := curve.Params().N
+	z := TruncateDigest(digest[:], n)
+	w := new(big.Int).ModInverse(s, n)
+	u1 := new(big.Int).Mul(z, w)
+	u2 := new(big.Int).Mul(r, w)
+	x1, y1 := curve.ScalarBaseMult(u1.Mod(u1, n).Bytes())
+	x2, y2 := curve.ScalarMult(key.X, key.Y, u2.Mod(u2, n).Bytes())
+	x, _ := curve.Add(x1, y1, x2, y2)
+	if x.Mod(x, n).Cmp(r) != 0 {
+		t.Errorf("Signature does not verify with z = %x", z)
+	}
+
+	// A digest no longer than the order is only reduced
+	short := []byte{0x01, 0x02}
+	if got := TruncateDigest(short, n); got.Int64() != 0x0102 {
+		t.Errorf("Expected 0x0102, got %x", got)
+	}
+}
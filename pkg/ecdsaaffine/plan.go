@@ -0,0 +1,304 @@
+package ecdsaaffine
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// maxPlannedPhases bounds the range phases planned for an ExpansionPolicy, which may
+// never end on its own.
+const maxPlannedPhases = 1000
+
+// SearchPlan is what a recovery would run, in order, enumerated by Client.Plan without
+// searching. Candidate counts are the worst case, when no phase finds the key, before
+// RangeConfig.CandidateFilter and the point screening skip any.
+type SearchPlan struct {
+	Strategy   string          `json:"strategy"`
+	Signatures int             `json:"signatures"`
+	Searches   []PlannedSearch `json:"searches"`   // one per signer, or per pair set with WithPairs
+	Candidates float64         `json:"candidates"` // over every search
+}
+
+// PlannedSearch is one run of the strategy, on the signatures at Indices.
+type PlannedSearch struct {
+	Indices    []int          `json:"indices,omitempty"` // dataset positions; empty for the whole dataset
+	Target     string         `json:"target,omitempty"`  // hex verification target; empty if there is none
+	Phases     []PlannedPhase `json:"phases"`
+	Candidates float64        `json:"candidates"`
+}
+
+// PlannedPhase is one phase of a planned search.
+type PlannedPhase struct {
+	Name     string    `json:"name"`
+	Patterns []Pattern `json:"patterns,omitempty"` // pattern phases, with a and b centered
+	ARange   [2]int    `json:"a_range"`            // zero for other phases
+	BRange   [2]int    `json:"b_range"`
+
+	// Pairs is the number of signature pairs searched; ExcludedPairs were skipped as
+	// searched by the strategy's Report (for pattern phases, summed over the patterns)
+	Pairs         int     `json:"pairs"`
+	ExcludedPairs int     `json:"excluded_pairs,omitempty"`
+	Candidates    float64 `json:"candidates"`
+	Parallel      bool    `json:"parallel,omitempty"`
+}
+
+// planner is implemented by the strategies that can enumerate their phases.
+type planner interface {
+	plan(signatures []*Signature, publicKey []byte) []PlannedPhase
+}
+
+// Plan returns the plan of a recovery from signatures in a file, as RecoverKey would run
+// it, without searching. Use it to check a configuration before a long search.
+func (c *Client) Plan(source string, publicKeyHex string) (*SearchPlan, error) {
+	signatures, err := c.parser.ParseSignatures(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	return c.PlanFromSignatures(signatures, publicKeyHex)
+}
+
+// PlanFromSignatures is Plan for in-memory signatures, as RecoverKeyFromSignatures would
+// run it. It fails for strategies that choose their work as they go (BSGS, kangaroo and
+// most third-party strategies).
+func (c *Client) PlanFromSignatures(signatures []*Signature, publicKeyHex string) (*SearchPlan, error) {
+	if len(signatures) < 2 {
+		return nil, fmt.Errorf("need at least 2 signatures, got %d", len(signatures))
+	}
+	if !canPlan(c.strategy) {
+		return nil, fmt.Errorf("strategy %s cannot plan its search", c.strategy.Name())
+	}
+	p := c.strategy.(planner)
+
+	var publicKey []byte
+	if publicKeyHex != "" {
+		var err error
+		publicKey, err = ParseTarget(publicKeyHex, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &SearchPlan{Strategy: c.strategy.Name(), Signatures: len(signatures)}
+	if len(c.pairs) > 0 {
+		if err := c.checkPairs(len(signatures)); err != nil {
+			return nil, err
+		}
+		for _, pair := range c.pairs {
+			group := &SignatureGroup{
+				Signatures: []*Signature{signatures[pair[0]], signatures[pair[1]]},
+				Indices:    []int{pair[0], pair[1]},
+			}
+			plan.Searches = append(plan.Searches, c.planByKey(p, group, publicKey)...)
+		}
+	} else {
+		plan.Searches = c.planByKey(p, &SignatureGroup{Signatures: signatures}, publicKey)
+	}
+	for _, search := range plan.Searches {
+		plan.Candidates += search.Candidates
+	}
+	return plan, nil
+}
+
+// planByKey plans the searches searchByKey runs on the signatures of group.
+func (c *Client) planByKey(p planner, group *SignatureGroup, publicKey []byte) []PlannedSearch {
+	search := func(signatures []*Signature, indices []int, target []byte) PlannedSearch {
+		planned := PlannedSearch{Indices: indices, Target: hex.EncodeToString(target), Phases: p.plan(signatures, target)}
+		for _, phase := range planned.Phases {
+			planned.Candidates += phase.Candidates
+		}
+		return planned
+	}
+	// remap translates positions in group to dataset positions
+	remap := func(indices []int) []int {
+		if group.Indices == nil {
+			return indices
+		}
+		remapped := make([]int, len(indices))
+		for k, i := range indices {
+			remapped[k] = group.Indices[i]
+		}
+		return remapped
+	}
+
+	groups := GroupByPublicKey(group.Signatures)
+	if len(groups) == 1 {
+		if len(publicKey) == 0 {
+			publicKey = groups[0].PublicKey
+		}
+		return []PlannedSearch{search(group.Signatures, group.Indices, publicKey)}
+	}
+	var searches []PlannedSearch
+	for _, g := range groups {
+		if len(g.Signatures) < 2 || (len(publicKey) > 0 && !sameSigner(g.PublicKey, publicKey)) {
+			continue
+		}
+		target := g.PublicKey
+		if len(publicKey) > 0 {
+			target = publicKey
+		}
+		searches = append(searches, search(g.Signatures, remap(g.Indices), target))
+	}
+	return searches
+}
+
+func (s *SmartBruteForceStrategy) plan(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	phases := []PlannedPhase{s.planSameNonce(signatures)}
+	phases = append(phases, s.planPatterns(signatures, publicKey)...)
+	return append(phases, s.planAdaptive(signatures, publicKey)...)
+}
+
+func (s *SameNonceStrategy) plan(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	return []PlannedPhase{s.planSameNonce(signatures)}
+}
+
+func (s *PatternStrategy) plan(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	return s.planPatterns(signatures, publicKey)
+}
+
+func (s *RangeStrategy) plan(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	return []PlannedPhase{s.planRange(signatures, publicKey, rangePhase{s.RangeConfig.ARange, s.RangeConfig.BRange, "Range search"})}
+}
+
+func (s *AdaptiveStrategy) plan(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	return s.planAdaptive(signatures, publicKey)
+}
+
+// plan concatenates the plans of the chain's strategies, which canPlan checked.
+func (s *ChainStrategy) plan(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	var phases []PlannedPhase
+	for _, strategy := range s.Strategies {
+		phases = append(phases, strategy.(planner).plan(signatures, publicKey)...)
+	}
+	return phases
+}
+
+// canPlan reports whether strategy implements planner, and for a chain, whether all of
+// its strategies do.
+func canPlan(strategy BruteForceStrategy) bool {
+	if chain, ok := strategy.(*ChainStrategy); ok {
+		for _, s := range chain.Strategies {
+			if !canPlan(s) {
+				return false
+			}
+		}
+		return true
+	}
+	_, ok := strategy.(planner)
+	return ok
+}
+
+// planSameNonce plans phase 0, which recovers a key from each pair with identical r.
+func (s *SmartBruteForceStrategy) planSameNonce(signatures []*Signature) PlannedPhase {
+	phase := PlannedPhase{Name: "Phase 0: same nonce reuse", Pairs: len(signatures) * (len(signatures) - 1) / 2}
+	for i := range signatures {
+		for j := i + 1; j < len(signatures); j++ {
+			if signatures[i].R.Cmp(signatures[j].R) == 0 {
+				phase.Candidates++
+			}
+		}
+	}
+	return phase
+}
+
+// planPatterns plans the phases patternPhases runs.
+func (s *SmartBruteForceStrategy) planPatterns(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	var phases []PlannedPhase
+	pairs := len(signatures) * (len(signatures) - 1) / 2
+	if s.PatternConfig.Hypotheses != nil {
+		phase := PlannedPhase{Name: "Phase 0b: metadata hypotheses", Pairs: pairs}
+		for i := range signatures {
+			for j := i + 1; j < len(signatures); j++ {
+				phase.Candidates += float64(len(s.PatternConfig.Hypotheses(signatures[i], signatures[j])))
+			}
+		}
+		phases = append(phases, phase)
+	}
+	if s.PatternConfig.SuggestedPatterns {
+		if suggested := suggestedPatterns(signatures); len(suggested) > 0 {
+			phases = append(phases, s.planPatternList("Phase 0c: suggested patterns", suggested, signatures, publicKey))
+		}
+	}
+	if s.PatternConfig.IncludeCommonPatterns {
+		phases = append(phases, s.planPatternList(commonPatternsPhase, s.getCommonPatterns(), signatures, publicKey))
+	}
+	if len(s.PatternConfig.CustomPatterns) > 0 {
+		phases = append(phases, s.planPatternList("Phase 2: custom patterns", s.PatternConfig.CustomPatterns, signatures, publicKey))
+	}
+	return phases
+}
+
+// planPatternList plans a phase trying each pattern on every pair, as tryPattern does.
+func (s *SmartBruteForceStrategy) planPatternList(name string, patterns []Pattern, signatures []*Signature, publicKey []byte) PlannedPhase {
+	n := len(signatures)
+	phase := PlannedPhase{Name: name, Pairs: n * (n - 1) / 2, Parallel: n*(n-1)/2 >= patternParallelThreshold}
+	for _, pattern := range patterns {
+		a, b := centered(pattern.A), centered(pattern.B)
+		phase.Patterns = append(phase.Patterns, Pattern{A: a, B: b, Name: pattern.Name, Priority: pattern.Priority})
+
+		reverse := s.RangeConfig.BothDirections && a.CmpAbs(big.NewInt(1)) > 0
+		searched := phase.Pairs
+		if aRange, bRange, reportable := patternRange(a, b); reportable {
+			excluded := s.Report.excluded(signatures, publicKey, aRange, bRange, false, reverse)
+			for i := 0; i < n; i++ {
+				for j := i + 1; j < n; j++ {
+					if excluded(i, j) {
+						searched--
+						phase.ExcludedPairs++
+					}
+				}
+			}
+		}
+		if reverse {
+			searched *= 2
+		}
+		phase.Candidates += float64(searched)
+	}
+	return phase
+}
+
+// planAdaptive plans the phases adaptiveRangeSearch runs when none finds the key. An
+// ExpansionPolicy sees phases that took no time.
+func (s *SmartBruteForceStrategy) planAdaptive(signatures []*Signature, publicKey []byte) []PlannedPhase {
+	var policy ExpansionPolicy = phaseList(s.rangePhases())
+	if s.Expansion != nil {
+		policy = s.Expansion
+	}
+	stats := SearchStats{
+		Signatures: len(signatures),
+		Pairs:      min(len(signatures)*(len(signatures)-1)/2, s.RangeConfig.MaxPairs),
+	}
+	var prev []PhaseResult
+	var phases []PlannedPhase
+	for len(prev) < maxPlannedPhases {
+		aRange, bRange, ok := policy.NextRange(prev, stats)
+		if !ok {
+			break
+		}
+		r := rangePhase{aRange, bRange, fmt.Sprintf("Expansion phase %d", len(prev)+1)}
+		if list, ok := policy.(phaseList); ok {
+			r.name = list[len(prev)].name
+		}
+		phases = append(phases, s.planRange(signatures, publicKey, r))
+
+		combinations := s.rangeCombinations(r.aRange, r.bRange)
+		prev = append(prev, PhaseResult{Name: r.name, ARange: r.aRange, BRange: r.bRange, Combinations: combinations})
+		stats.Candidates += int64(combinations) * int64(stats.Pairs)
+	}
+	return phases
+}
+
+// planRange plans one range phase, as searchRange runs it.
+func (s *SmartBruteForceStrategy) planRange(signatures []*Signature, publicKey []byte, r rangePhase) PlannedPhase {
+	pairs, searched := s.rangePairs(signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs)
+	combinations := s.rangeCombinations(r.aRange, r.bRange)
+	return PlannedPhase{
+		Name:          r.name,
+		ARange:        r.aRange,
+		BRange:        r.bRange,
+		Pairs:         len(pairs),
+		ExcludedPairs: searched - len(pairs),
+		Candidates:    float64(combinations) * float64(len(pairs)),
+		Parallel:      combinations > parallelThreshold,
+	}
+}
@@ -0,0 +1,99 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestClient_Plan(t *testing.T) {
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+
+	plan, err := NewClient().PlanFromSignatures(signatures, hex.EncodeToString(publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Searches) != 1 {
+		t.Fatalf("Expected one search, got %d", len(plan.Searches))
+	}
+	phases := plan.Searches[0].Phases
+	strategy := NewSmartBruteForceStrategy()
+	ranges := strategy.rangePhases()
+	if len(phases) != 3+len(ranges) {
+		t.Fatalf("Expected same nonce, hypotheses, common patterns and %d range phases, got %d phases", len(ranges), len(phases))
+	}
+	if phases[0].Name != "Phase 0: same nonce reuse" || phases[1].Name != "Phase 0b: metadata hypotheses" || phases[2].Name != "Phase 1: common patterns" {
+		t.Errorf("Unexpected pattern phases %q, %q, %q", phases[0].Name, phases[1].Name, phases[2].Name)
+	}
+	if len(phases[2].Patterns) != len(strategy.getCommonPatterns()) || phases[2].Pairs != 10 {
+		t.Errorf("Expected every common pattern on 10 pairs, got %d patterns on %d pairs", len(phases[2].Patterns), phases[2].Pairs)
+	}
+
+	var total float64
+	for k, r := range ranges {
+		phase := phases[3+k]
+		want := float64(strategy.rangeCombinations(r.aRange, r.bRange) * 10)
+		if phase.Name != r.name || phase.ARange != r.aRange || phase.Pairs != 10 || phase.Candidates != want {
+			t.Errorf("Phase %d: got %+v, want %s on 10 pairs with %.0f candidates", k, phase, r.name, want)
+		}
+	}
+	for _, phase := range phases {
+		total += phase.Candidates
+	}
+	if plan.Candidates != total || plan.Searches[0].Candidates != total {
+		t.Errorf("Expected %.0f candidates in total, got %.0f", total, plan.Candidates)
+	}
+}
+
+func TestClient_Plan_MatchesSearch(t *testing.T) {
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	unrelated := signatures[2:]
+
+	// The plan counts the candidates the search tests, and follows the report
+	report := &SearchReport{}
+	for run, want := range []struct{ pairs, excluded int }{{2, 0}, {1, 2}} {
+		strategy, m := reportTestStrategy(report, [2]int{-50, 50}, 2)
+		plan, err := NewClient(WithStrategy(strategy)).PlanFromSignatures(unrelated, hex.EncodeToString(publicKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		phase := plan.Searches[0].Phases[len(plan.Searches[0].Phases)-1]
+		if phase.Pairs != want.pairs || phase.ExcludedPairs != want.excluded {
+			t.Errorf("Run %d: planned %d pairs (%d excluded), want %d (%d)", run, phase.Pairs, phase.ExcludedPairs, want.pairs, want.excluded)
+		}
+
+		strategy.Search(context.Background(), unrelated, publicKey)
+		if got := m.Snapshot(); got.Pairs != int64(phase.Pairs) || float64(got.Candidates) != phase.Candidates {
+			t.Errorf("Run %d: planned %d pairs and %.0f candidates, searched %d and %d", run, phase.Pairs, phase.Candidates, got.Pairs, got.Candidates)
+		}
+	}
+}
+
+func TestClient_Plan_Pairs(t *testing.T) {
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	client := NewClient(WithStrategy(NewChainStrategy(NewSameNonceStrategy(), NewRangeStrategy([2]int{1, 1}, [2]int{0, 10}))), WithPairs([2]int{0, 1}, [2]int{2, 4}))
+
+	plan, err := client.PlanFromSignatures(signatures, hex.EncodeToString(publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Searches) != 2 || plan.Searches[1].Indices[0] != 2 || plan.Searches[1].Indices[1] != 4 {
+		t.Fatalf("Expected a search per pair, got %+v", plan.Searches)
+	}
+	for _, search := range plan.Searches {
+		if len(search.Phases) != 2 || search.Phases[1].Name != "Range search" || search.Candidates != 11 {
+			t.Errorf("Expected same nonce and an 11-candidate range search, got %+v", search)
+		}
+	}
+}
+
+func TestClient_Plan_Unsupported(t *testing.T) {
+	signatures, _ := reportTestSignatures(big.NewInt(0xc0ffee))
+	for _, strategy := range []BruteForceStrategy{NewBSGSStrategy(), NewChainStrategy(NewSameNonceStrategy(), NewBSGSStrategy())} {
+		if _, err := NewClient(WithStrategy(strategy)).PlanFromSignatures(signatures, ""); err == nil || !strings.Contains(err.Error(), "cannot plan") {
+			t.Errorf("%s: expected an error, got %v", strategy.Name(), err)
+		}
+	}
+}
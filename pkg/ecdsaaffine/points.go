@@ -0,0 +1,264 @@
+package ecdsaaffine
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/tablefile"
+)
+
+// LiftR returns the curve point with x-coordinate r and even y.
+// The signature only fixes R up to sign, so the nonce point is either the result or its negation.
+// (r values that were reduced mod n, i.e. x >= n, occur with probability ~2^-128 and are not handled.)
+func LiftR(r *big.Int) (*secp256k1.JacobianPoint, error) {
+	if r.Sign() <= 0 || r.BitLen() > 256 {
+		return nil, errors.New("r value out of range")
+	}
+	var x, y secp256k1.FieldVal
+	if overflow := x.SetByteSlice(r.Bytes()); overflow {
+		return nil, errors.New("r value exceeds field prime")
+	}
+	if !secp256k1.DecompressY(&x, false, &y) {
+		return nil, errors.New("r is not the x-coordinate of a curve point")
+	}
+	var one secp256k1.FieldVal
+	one.SetInt(1)
+	point := secp256k1.MakeJacobianPoint(&x, &y, &one)
+	return &point, nil
+}
+
+// OffsetSolver solves D = b*G for |b| <= Bound with baby-step giant-step.
+// The baby-step table is built once and reused across signature pairs; Save and
+// LoadOffsetSolver keep it on disk across runs.
+type OffsetSolver struct {
+	Bound int64
+
+	m     int64
+	baby  babySteps               // low 64 bits of x(j*G) -> j, for 1 <= j < m
+	giant secp256k1.JacobianPoint // -m*G
+	shift secp256k1.JacobianPoint // Bound*G, maps b into [0, 2*Bound]
+}
+
+// babySteps is the baby-step lookup: an in-memory map or a mapped table file.
+type babySteps interface {
+	Lookup(key uint64) (int64, bool)
+}
+
+type babyMap map[uint64]uint32
+
+func (b babyMap) Lookup(key uint64) (int64, bool) {
+	j, ok := b[key]
+	return int64(j), ok
+}
+
+// MaxOffsetBound is the largest bound an OffsetSolver accepts: 2^48, a baby-step table
+// of about 24M entries. Past it the table outgrows memory, and near 2^62 the giant-step
+// arithmetic overflows int64.
+const MaxOffsetBound = int64(1) << 48
+
+// ErrOffsetBound is returned for an OffsetSolver bound above MaxOffsetBound.
+var ErrOffsetBound = errors.New("offset bound exceeds MaxOffsetBound (2^48)")
+
+// NewOffsetSolver builds the baby-step table for |b| <= bound, which must not exceed
+// MaxOffsetBound. Time and memory grow with sqrt(2*bound): about 1.5M entries for 2^40.
+func NewOffsetSolver(bound int64) (*OffsetSolver, error) {
+	o, err := newOffsetSolver(bound)
+	if err != nil {
+		return nil, err
+	}
+
+	var G secp256k1.JacobianPoint
+	scalarBaseMult(big.NewInt(1), &G)
+
+	baby := make(babyMap, o.m)
+	point := G
+	for j := int64(1); j < o.m; j++ {
+		affine := point
+		affine.ToAffine()
+		baby[xKey(&affine)] = uint32(j)
+		secp256k1.AddNonConst(&point, &G, &point)
+	}
+	o.baby = baby
+	return o, nil
+}
+
+// LoadOffsetSolver opens a baby-step table written by Save. The table is memory-mapped,
+// so loading is immediate regardless of its size; call Close when done.
+func LoadOffsetSolver(path string, bound int64) (*OffsetSolver, error) {
+	o, err := newOffsetSolver(bound)
+	if err != nil {
+		return nil, err
+	}
+	table, err := tablefile.OpenExpect(path, o.tableHeader())
+	if err != nil {
+		return nil, err
+	}
+	o.baby = table
+	return o, nil
+}
+
+// Save writes the baby-step table to path for reuse with LoadOffsetSolver.
+func (o *OffsetSolver) Save(path string) error {
+	var entries []tablefile.Entry
+	switch baby := o.baby.(type) {
+	case babyMap:
+		entries = make([]tablefile.Entry, 0, len(baby))
+		for key, j := range baby {
+			entries = append(entries, tablefile.Entry{Key: key, Value: int64(j)})
+		}
+	case *tablefile.Table:
+		entries = make([]tablefile.Entry, baby.Len())
+		for i := range entries {
+			entries[i] = baby.Entry(i)
+		}
+	}
+	return tablefile.Write(path, o.tableHeader(), entries)
+}
+
+// Close releases a table opened by LoadOffsetSolver.
+func (o *OffsetSolver) Close() error {
+	if table, ok := o.baby.(*tablefile.Table); ok {
+		return table.Close()
+	}
+	return nil
+}
+
+// newOffsetSolver sets up everything but the baby-step table.
+func newOffsetSolver(bound int64) (*OffsetSolver, error) {
+	if bound > MaxOffsetBound {
+		return nil, fmt.Errorf("%w: %d", ErrOffsetBound, bound)
+	}
+	if bound < 1 {
+		bound = 1
+	}
+	span := 2*bound + 1
+	m := int64(1)
+	for m*m < span {
+		m++
+	}
+
+	o := &OffsetSolver{Bound: bound, m: m}
+	scalarBaseMult(big.NewInt(m), &o.giant)
+	o.giant.Y.Negate(1).Normalize()
+	scalarBaseMult(big.NewInt(bound), &o.shift)
+	return o, nil
+}
+
+func (o *OffsetSolver) tableHeader() tablefile.Header {
+	return tablefile.Header{
+		Kind:   tablefile.KindBabySteps,
+		Curve:  "secp256k1",
+		Params: [4]int64{o.Bound, o.m},
+	}
+}
+
+// Solve returns candidate offsets b for k2 = k1 + b with |b| <= Bound.
+//
+// Since r only fixes R up to sign, both R2 - R1 and R2 + R1 are solved and each solution
+// is returned with both signs. Callers must confirm a candidate by recovering the key.
+func (o *OffsetSolver) Solve(sig1, sig2 *Signature) []*big.Int {
+	P1, err := LiftR(sig1.R)
+	if err != nil {
+		return nil
+	}
+	P2, err := LiftR(sig2.R)
+	if err != nil {
+		return nil
+	}
+
+	negP1 := *P1
+	negP1.Y.Negate(1).Normalize()
+
+	var candidates []*big.Int
+	for _, other := range []*secp256k1.JacobianPoint{&negP1, P1} {
+		var D secp256k1.JacobianPoint
+		secp256k1.AddNonConst(P2, other, &D)
+		if b, ok := o.solvePoint(&D); ok {
+			candidates = append(candidates, big.NewInt(b))
+			if b != 0 {
+				candidates = append(candidates, big.NewInt(-b))
+			}
+		}
+	}
+	return candidates
+}
+
+// solvePoint finds b with D = b*G and |b| <= Bound.
+func (o *OffsetSolver) solvePoint(D *secp256k1.JacobianPoint) (int64, bool) {
+	var gamma secp256k1.JacobianPoint
+	secp256k1.AddNonConst(D, &o.shift, &gamma)
+
+	for i := int64(0); i*o.m <= 2*o.Bound; i++ {
+		affine := gamma
+		affine.ToAffine()
+
+		var js []int64
+		if isInfinity(&gamma) {
+			js = []int64{0}
+		} else if j, ok := o.baby.Lookup(xKey(&affine)); ok {
+			// x(j*G) = x(-j*G): gamma is one of the two
+			js = []int64{j, -j}
+		}
+		for _, j := range js {
+			b := i*o.m + j - o.Bound
+			if b >= -o.Bound && b <= o.Bound && pointEquals(D, big.NewInt(b)) {
+				return b, true
+			}
+		}
+		secp256k1.AddNonConst(&gamma, &o.giant, &gamma)
+	}
+	return 0, false
+}
+
+// NonceMatchesR reports whether the nonce implied by privateKey reproduces the signature's r.
+// This confirms a recovered key when no public key is available.
+func NonceMatchesR(sig *Signature, privateKey *big.Int) bool {
+	k, err := RecoverNonce(sig, privateKey)
+	if err != nil || k.Sign() == 0 {
+		return false
+	}
+	return nonceProducesR(sig, k)
+}
+
+// nonceProducesR reports whether the x coordinate of k*G is the signature's r.
+func nonceProducesR(sig *Signature, k *big.Int) bool {
+	var R secp256k1.JacobianPoint
+	scalarBaseMult(k, &R)
+	R.ToAffine()
+	x := new(big.Int).SetBytes(R.X.Bytes()[:])
+	x.Mod(x, Secp256k1CurveOrder)
+	return x.Cmp(sig.R) == 0
+}
+
+// scalarBaseMult computes v*G (v reduced mod n, negative values allowed).
+func scalarBaseMult(v *big.Int, result *secp256k1.JacobianPoint) {
+	var k secp256k1.ModNScalar
+	reduced := new(big.Int).Mod(v, Secp256k1CurveOrder)
+	k.SetByteSlice(reduced.Bytes())
+	secp256k1.ScalarBaseMultNonConst(&k, result)
+}
+
+// pointEquals reports whether D = b*G.
+func pointEquals(D *secp256k1.JacobianPoint, b *big.Int) bool {
+	var expected secp256k1.JacobianPoint
+	scalarBaseMult(b, &expected)
+	if isInfinity(&expected) || isInfinity(D) {
+		return isInfinity(&expected) && isInfinity(D)
+	}
+	a := *D
+	a.ToAffine()
+	expected.ToAffine()
+	return a.X.Equals(&expected.X) && a.Y.Equals(&expected.Y)
+}
+
+func isInfinity(p *secp256k1.JacobianPoint) bool {
+	return (p.X.IsZero() && p.Y.IsZero()) || p.Z.IsZero()
+}
+
+// xKey returns the low 64 bits of an affine point's x-coordinate.
+func xKey(p *secp256k1.JacobianPoint) uint64 {
+	return binary.BigEndian.Uint64(p.X.Bytes()[24:])
+}
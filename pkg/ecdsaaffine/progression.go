@@ -0,0 +1,128 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Progression is a run of consecutive signatures, in dataset order, whose nonces step by
+// a constant. A and B give the relationship of its first pair as RecoverPrivateKey reads
+// the signatures, k[Start+1] = A*k[Start] + B: A is -1 when one of the two s values was
+// negated, as low-s normalization does, and B is the step up to sign.
+type Progression struct {
+	Start int // first signature of the run
+	End   int // last signature of the run
+	A     *big.Int
+	B     *big.Int // centered into (-n/2, n/2]
+}
+
+// FindProgressions finds the runs of three or more consecutive signatures whose nonces
+// are in arithmetic progression. r only gives R up to sign, so a run is seen as
+// x(2*R2) = x(R1 ± R3) on the lifted points of each triple. The step hides behind the
+// difference of two R points, a discrete log; the key and the step are solved from the
+// first three signatures of the run instead, whatever the size of the step. Runs of the
+// same r are left to the same nonce check.
+func FindProgressions(signatures []*Signature) []Progression {
+	points := make([]*secp256k1.JacobianPoint, len(signatures))
+	for i, sig := range signatures {
+		if p, err := LiftR(sig.R); err == nil {
+			points[i] = p
+		}
+	}
+
+	var progressions []Progression
+	for i := 0; i+2 < len(signatures); {
+		end := i + 1
+		for end+1 < len(signatures) && equalStepsX(points[end-1], points[end], points[end+1]) {
+			end++
+		}
+		if end < i+2 {
+			i++
+			continue
+		}
+		if a, b, ok := progressionStep(signatures[i], signatures[i+1], signatures[i+2]); ok && b.Sign() != 0 {
+			progressions = append(progressions, Progression{Start: i, End: end, A: a, B: b})
+		}
+		i = end
+	}
+	return progressions
+}
+
+// equalStepsX reports whether 2*P2 and P1 + P3 or P1 - P3 share an x-coordinate, which
+// holds for the lifted points of three nonces in arithmetic progression whatever the
+// signs the lift gave them.
+func equalStepsX(P1, P2, P3 *secp256k1.JacobianPoint) bool {
+	if P1 == nil || P2 == nil || P3 == nil {
+		return false
+	}
+	var double, sum, diff secp256k1.JacobianPoint
+	secp256k1.DoubleNonConst(P2, &double)
+	secp256k1.AddNonConst(P1, P3, &sum)
+	negP3 := *P3
+	negP3.Y.Negate(1).Normalize()
+	secp256k1.AddNonConst(P1, &negP3, &diff)
+	if isInfinity(&double) {
+		return false
+	}
+	double.ToAffine()
+	for _, p := range []*secp256k1.JacobianPoint{&sum, &diff} {
+		if isInfinity(p) {
+			continue
+		}
+		p.ToAffine()
+		if p.X.Equals(&double.X) {
+			return true
+		}
+	}
+	return false
+}
+
+// progressionStep solves the key from three signatures whose nonces are in arithmetic
+// progression, and returns the relationship k2 = a*k1 + b between the nonces the first
+// two signatures' equations give. Each nonce k_i = (z_i + r_i*d)/s_i may be the negated
+// one, so k1 ± 2*k2 ± k3 = 0 is solved for d in each combination of signs, keeping the d
+// whose nonces reproduce all three r values.
+func progressionStep(sig1, sig2, sig3 *Signature) (a, b *big.Int, ok bool) {
+	n := Secp256k1CurveOrder
+	var u, w [3]*big.Int
+	for i, sig := range []*Signature{sig1, sig2, sig3} {
+		sInv := new(big.Int).ModInverse(sig.S, n)
+		if sInv == nil {
+			return nil, nil, false
+		}
+		u[i] = new(big.Int).Mul(sig.Z, sInv)
+		w[i] = new(big.Int).Mul(sig.R, sInv)
+	}
+
+	for _, signs := range [][2]int64{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		c := [3]*big.Int{big.NewInt(1), big.NewInt(-2 * signs[0]), big.NewInt(signs[1])}
+		num, den := new(big.Int), new(big.Int)
+		for i := range c {
+			num.Sub(num, new(big.Int).Mul(c[i], u[i]))
+			den.Add(den, new(big.Int).Mul(c[i], w[i]))
+		}
+		denInv := new(big.Int).ModInverse(den.Mod(den, n), n)
+		if denInv == nil {
+			continue
+		}
+		d := num.Mul(num, denInv)
+		d.Mod(d, n)
+		if d.Sign() == 0 {
+			continue
+		}
+
+		var k [3]*big.Int
+		for i := range k {
+			k[i] = new(big.Int).Mul(w[i], d)
+			k[i].Add(k[i], u[i]).Mod(k[i], n)
+		}
+		if !nonceProducesR(sig1, k[0]) || !nonceProducesR(sig2, k[1]) || !nonceProducesR(sig3, k[2]) {
+			continue
+		}
+		a = big.NewInt(signs[0])
+		b = new(big.Int).Mul(a, k[0])
+		return a, centered(b.Sub(k[1], b)), true
+	}
+	return nil, nil, false
+}
@@ -0,0 +1,53 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestFindProgressions(t *testing.T) {
+	// A step far beyond the range phases, behind an unrelated signature
+	d := big.NewInt(0xa9)
+	step := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 100), big.NewInt(7))
+	k := big.NewInt(987654321)
+	signatures := []*Signature{signWithNonce(d, big.NewInt(123456789), HashMessage([]byte("unrelated")))}
+	for i := 0; i < 4; i++ {
+		signatures = append(signatures, signWithNonce(d, k, HashMessage([]byte{byte(i)})))
+		k = new(big.Int).Add(k, step)
+	}
+
+	progressions := FindProgressions(signatures)
+	if len(progressions) != 1 {
+		t.Fatalf("Expected one progression, got %+v", progressions)
+	}
+	if p := progressions[0]; p.Start != 1 || p.End != 4 || p.A.Int64() != 1 || p.B.Cmp(step) != 0 {
+		t.Errorf("Expected signatures 1 to 4 with k2 = k1 + %s, got %+v", step, p)
+	}
+	if progressions := FindProgressions(signatures[:3]); len(progressions) != 0 {
+		t.Errorf("Expected no progression in two related signatures, got %+v", progressions)
+	}
+
+	// Negating s, as low-s normalization does, negates the nonce the signature's equation
+	// gives: the first pair is then k2 = -k1 - step
+	signatures[2].S = new(big.Int).Sub(Secp256k1CurveOrder, signatures[2].S)
+	progressions = FindProgressions(signatures)
+	if len(progressions) != 1 || progressions[0].A.Int64() != -1 || new(big.Int).Neg(progressions[0].B).Cmp(step) != 0 {
+		t.Fatalf("Expected k2 = -k1 - %s for the normalized signature, got %+v", step, progressions)
+	}
+
+	// Suggested as a pattern, the progression recovers the key
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{SuggestedPatterns: true})
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	result := strategy.Search(context.Background(), signatures, publicKey)
+	if result == nil || !result.Verified || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the suggested progression to recover the key, got %+v", result)
+	}
+	if result.SignaturePair != [2]int{1, 2} {
+		t.Errorf("Expected the first pair of the progression, got %v", result.SignaturePair)
+	}
+}
@@ -0,0 +1,75 @@
+package ecdsaaffine
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// proofDomain prefixes every proof challenge, so a proof signature can never be replayed
+// as a transaction or message signature.
+const proofDomain = "ecdsa-affine proof of compromise\n"
+
+// CompromiseProof shows that a key was recovered without revealing it: the recovered key
+// signs a challenge chosen by the verifier (for example a bug bounty report ID). Anyone can
+// check it against the victim's public key with VerifyCompromiseProof or standard
+// secp256k1 ECDSA tooling over ProofDigest(Challenge).
+type CompromiseProof struct {
+	PublicKey []byte // Compressed public key of the recovered key (33 bytes)
+	Challenge []byte // Verifier-chosen challenge
+	Signature []byte // DER-encoded ECDSA signature over ProofDigest(Challenge)
+}
+
+// ProofDigest returns the SHA-256 digest signed by a proof: the challenge prefixed with
+// a fixed domain string.
+func ProofDigest(challenge []byte) []byte {
+	h := sha256.Sum256(append([]byte(proofDomain), challenge...))
+	return h[:]
+}
+
+// ProveCompromise signs challenge with a recovered private key (RFC 6979 nonces).
+func ProveCompromise(privateKey *big.Int, challenge []byte) (*CompromiseProof, error) {
+	if privateKey == nil || privateKey.Sign() <= 0 || privateKey.Cmp(Secp256k1CurveOrder) >= 0 {
+		return nil, errors.New("private key out of valid range")
+	}
+	if len(challenge) == 0 {
+		return nil, errors.New("challenge must not be empty")
+	}
+	var keyBytes [32]byte
+	privateKey.FillBytes(keyBytes[:])
+	priv := secp256k1.PrivKeyFromBytes(keyBytes[:])
+	defer priv.Zero()
+
+	signature := ecdsa.Sign(priv, ProofDigest(challenge))
+	return &CompromiseProof{
+		PublicKey: priv.PubKey().SerializeCompressed(),
+		Challenge: append([]byte(nil), challenge...),
+		Signature: signature.Serialize(),
+	}, nil
+}
+
+// VerifyCompromiseProof checks that proof is a valid signature over its challenge by the
+// holder of publicKey, in any encoding ParsePublicKey accepts (including an Ethereum address).
+func VerifyCompromiseProof(proof *CompromiseProof, publicKey []byte) error {
+	pub, err := secp256k1.ParsePubKey(proof.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid proof public key: %w", err)
+	}
+	if ok, err := matchesPublicKey(pub, publicKey); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("proof is for a different public key")
+	}
+	signature, err := ecdsa.ParseDERSignature(proof.Signature)
+	if err != nil {
+		return err
+	}
+	if !signature.Verify(ProofDigest(proof.Challenge), pub) {
+		return errors.New("proof signature does not verify")
+	}
+	return nil
+}
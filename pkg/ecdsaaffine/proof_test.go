@@ -0,0 +1,38 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestProveCompromise(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	proof, err := ProveCompromise(d, []byte("bounty-report-1234"))
+	if err != nil {
+		t.Fatalf("ProveCompromise failed: %v", err)
+	}
+	if !bytes.Equal(proof.PublicKey, publicKey) {
+		t.Errorf("Expected proof for %x, got %x", publicKey, proof.PublicKey)
+	}
+	if err := VerifyCompromiseProof(proof, publicKey); err != nil {
+		t.Errorf("Expected proof to verify: %v", err)
+	}
+
+	if err := VerifyCompromiseProof(proof, EthereumAddress(d)); err != nil {
+		t.Errorf("Expected proof to verify against the key's Ethereum address: %v", err)
+	}
+
+	other := secp256k1.PrivKeyFromBytes(big.NewInt(12345).Bytes()).PubKey().SerializeCompressed()
+	if err := VerifyCompromiseProof(proof, other); err == nil {
+		t.Error("Expected proof to fail for another public key")
+	}
+	proof.Challenge = []byte("bounty-report-1235")
+	if err := VerifyCompromiseProof(proof, publicKey); err == nil {
+		t.Error("Expected proof to fail for another challenge")
+	}
+}
@@ -0,0 +1,102 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/sha3"
+)
+
+// EthereumAddressLen is the length of an Ethereum address, the last 20 bytes of the
+// Keccak-256 hash of the uncompressed public key.
+const EthereumAddressLen = 20
+
+// ParsePublicKey normalizes a secp256k1 public key to the 33-byte compressed form the
+// strategies compare against. Accepted encodings:
+//   - 33 bytes: compressed (0x02/0x03 prefix)
+//   - 65 bytes: uncompressed (0x04) or hybrid (0x06/0x07)
+//   - 64 bytes: raw X || Y without a prefix, as Ethereum tooling stores keys
+//   - 20 bytes: an Ethereum address, returned unchanged; VerifyRecoveredKey compares it
+//     with the address of the recovered key
+//
+// The other verification targets ParseTarget produces (x-only keys, Bitcoin output
+// scripts and key sets) are validated and returned unchanged.
+func ParsePublicKey(publicKey []byte) ([]byte, error) {
+	if isTarget(publicKey) {
+		if err := validateTarget(publicKey); err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), publicKey...), nil
+	}
+
+	switch len(publicKey) {
+	case EthereumAddressLen:
+		return append([]byte(nil), publicKey...), nil
+	case 64:
+		publicKey = append([]byte{0x04}, publicKey...)
+	case 33, 65:
+	default:
+		return nil, fmt.Errorf("public key must be 33 (compressed), 65 (uncompressed or hybrid) or 64 (raw X||Y) bytes, or a 20-byte Ethereum address; got %d", len(publicKey))
+	}
+
+	pub, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return pub.SerializeCompressed(), nil
+}
+
+// ParsePublicKeyHex decodes a hex public key or Ethereum address (optional 0x prefix)
+// and normalizes it with ParsePublicKey.
+func ParsePublicKeyHex(publicKeyHex string) ([]byte, error) {
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(publicKeyHex, "0x"), "0X"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return ParsePublicKey(publicKey)
+}
+
+// EthereumAddress returns the Ethereum address of the key with the given private key.
+func EthereumAddress(privateKey *big.Int) []byte {
+	var d secp256k1.ModNScalar
+	d.SetByteSlice(privateKey.Bytes())
+	return ethereumAddress(secp256k1.NewPrivateKey(&d).PubKey())
+}
+
+// ethereumAddress hashes the uncompressed public key without its 0x04 prefix.
+func ethereumAddress(pub *secp256k1.PublicKey) []byte {
+	digest := keccak256(pub.SerializeUncompressed()[1:])
+	return digest[32-EthereumAddressLen:]
+}
+
+// keccak256 returns the legacy Keccak-256 digest of data (the original Keccak padding,
+// not FIPS 202 SHA3-256), as Ethereum uses it.
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// matchesPublicKey reports whether pub is the key encoded by publicKey, in any encoding
+// ParsePublicKey accepts.
+func matchesPublicKey(pub *secp256k1.PublicKey, publicKey []byte) (bool, error) {
+	switch len(publicKey) {
+	case 33:
+		// Compare the encodings directly; this is the hot path during range searches
+		return bytes.Equal(pub.SerializeCompressed(), publicKey), nil
+	case EthereumAddressLen:
+		return bytes.Equal(ethereumAddress(pub), publicKey), nil
+	}
+	if isTarget(publicKey) {
+		return matchesTarget(pub, publicKey)
+	}
+	normalized, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(pub.SerializeCompressed(), normalized), nil
+}
@@ -0,0 +1,96 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	pub := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey()
+	compressed := pub.SerializeCompressed()
+	uncompressed := pub.SerializeUncompressed()
+	hybrid := append([]byte{0x06 | compressed[0]&1}, uncompressed[1:]...)
+
+	for name, encoded := range map[string][]byte{
+		"compressed":   compressed,
+		"uncompressed": uncompressed,
+		"hybrid":       hybrid,
+		"raw X||Y":     uncompressed[1:],
+	} {
+		got, err := ParsePublicKey(encoded)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, compressed) {
+			t.Errorf("%s: normalized to %x, expected %x", name, got, compressed)
+		}
+		if ok, err := VerifyRecoveredKey(d, encoded); !ok || err != nil {
+			t.Errorf("%s: VerifyRecoveredKey = %v, %v", name, ok, err)
+		}
+	}
+
+	badHybrid := append([]byte{0x06 | ^compressed[0]&1}, uncompressed[1:]...)
+	offCurve := append([]byte(nil), uncompressed...)
+	offCurve[64] ^= 1
+	for name, encoded := range map[string][]byte{
+		"hybrid with wrong parity": badHybrid,
+		"point off the curve":      offCurve,
+		"compressed prefix on 65":  append([]byte{0x02}, uncompressed[1:]...),
+		"wrong length":             compressed[:32],
+	} {
+		if _, err := ParsePublicKey(encoded); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEthereumAddress(t *testing.T) {
+	// The well-known address of private key 1
+	want, _ := hex.DecodeString("7e5f4552091a69125d5dfcb7b8c2659029395bdf")
+	if got := EthereumAddress(big.NewInt(1)); !bytes.Equal(got, want) {
+		t.Fatalf("Expected address %x, got %x", want, got)
+	}
+
+	parsed, err := ParsePublicKeyHex("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	if err != nil || !bytes.Equal(parsed, want) {
+		t.Fatalf("ParsePublicKeyHex(address) = %x, %v", parsed, err)
+	}
+	if ok, err := VerifyRecoveredKey(big.NewInt(1), parsed); !ok || err != nil {
+		t.Errorf("Expected key 1 to match its address: %v", err)
+	}
+	if ok, _ := VerifyRecoveredKey(big.NewInt(2), parsed); ok {
+		t.Error("Expected key 2 not to match the address of key 1")
+	}
+}
+
+func TestClient_RecoverKeyFromSignatures_PublicKeyFormats(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, new(big.Int).Add(k1, big.NewInt(1)), HashMessage([]byte("message 2"))),
+	}
+	uncompressed := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeUncompressed()
+
+	for name, publicKeyHex := range map[string]string{
+		"uncompressed":     hex.EncodeToString(uncompressed),
+		"raw X||Y":         "0x" + hex.EncodeToString(uncompressed[1:]),
+		"Ethereum address": "0x" + hex.EncodeToString(EthereumAddress(d)),
+	} {
+		result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, publicKeyHex)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if result.PrivateKey.Cmp(d) != 0 || !result.Verified {
+			t.Errorf("%s: expected verified key %s, got %s (verified=%v)", name, d, result.PrivateKey, result.Verified)
+		}
+	}
+}
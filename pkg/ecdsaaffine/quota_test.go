@@ -0,0 +1,122 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func quotaTestStrategy(report *SearchReport, bRange [2]int) *SmartBruteForceStrategy {
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig = RangeConfig{ARange: [2]int{1, 1}, BRange: bRange, MaxPairs: 100, NumWorkers: 2}
+	strategy.Report = report
+	return strategy
+}
+
+func TestSmartBruteForceStrategy_MaxCandidates(t *testing.T) {
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	unrelated := signatures[2:] // three pairs
+
+	// Sequential: 10000 candidates a pair, stopped in the third pair
+	report := &SearchReport{}
+	strategy := quotaTestStrategy(report, [2]int{0, 9999})
+	strategy.RangeConfig.MaxCandidates = 25000
+	if result := strategy.Search(context.Background(), unrelated, publicKey); result != nil {
+		t.Fatalf("Unexpected key %+v", result)
+	}
+	if !strategy.QuotaExceeded() {
+		t.Fatal("Expected the quota to be exceeded")
+	}
+	if len(report.Tested) != 1 || report.Tested[0].Pairs != 2 {
+		t.Errorf("Expected the two finished pairs in the report, got %+v", report.Tested)
+	}
+
+	// Parallel: 200000 candidates a pair over two workers
+	report = &SearchReport{}
+	strategy = quotaTestStrategy(report, [2]int{0, 199999})
+	strategy.RangeConfig.MaxCandidates = 500000
+	strategy.Search(context.Background(), unrelated, publicKey)
+	if !strategy.QuotaExceeded() {
+		t.Fatal("Expected the quota to be exceeded")
+	}
+	if len(report.Tested) != 1 || report.Tested[0].Pairs < 1 || report.Tested[0].Pairs > 2 {
+		t.Errorf("Expected the pairs both workers finished in the report, got %+v", report.Tested)
+	}
+
+	// A search within its quota is not affected
+	strategy = quotaTestStrategy(nil, [2]int{0, 99})
+	strategy.RangeConfig.MaxCandidates = 1000
+	strategy.Search(context.Background(), unrelated, publicKey)
+	if strategy.QuotaExceeded() {
+		t.Error("Expected a search of 300 candidates to stay within a quota of 1000")
+	}
+}
+
+func TestClient_MaxCPUTime(t *testing.T) {
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	strategy := quotaTestStrategy(nil, [2]int{0, 1 << 30})
+	strategy.RangeConfig.MaxCPUTime = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := NewClient(WithStrategy(strategy)).RecoverKeyFromSignatures(context.Background(), signatures[2:], hex.EncodeToString(publicKey))
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the search to stop soon after 50ms of CPU time, took %v", elapsed)
+	}
+}
+
+func TestSmartBruteForceStrategy_SkipOverBudget(t *testing.T) {
+	// The nonces of the last three signatures differ by b = 115678912, in reach of Phase 4 only
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	report := &SearchReport{}
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig = DefaultRangeConfig()
+	strategy.RangeConfig.NumWorkers = 2
+	strategy.RangeConfig.BothDirections = false
+	strategy.RangeConfig.SkipOverBudget = true
+	strategy.RangeConfig.Rate = SearchRate{Workers: 2, Candidates: 100000, Elapsed: time.Second}
+	strategy.Report = report
+
+	// At 50000/sec per worker, Phases 2a to 3b take under a second; Phase 3c is estimated
+	// at 16.5s and Phase 4 at days
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	if result := strategy.Search(ctx, signatures[2:], publicKey); result != nil {
+		t.Fatalf("Expected Phase 4 to be skipped, got key %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the search to skip the phases it could not finish, took %v", elapsed)
+	}
+
+	if len(report.Skipped) != 2 {
+		t.Fatalf("Expected Phases 3c and 4 to be skipped, got %+v", report.Skipped)
+	}
+	skipped := report.Skipped[0]
+	if skipped.Name != "Phase 3c: wider a, larger b" || skipped.Pairs != 3 || skipped.Estimate != 16500300*time.Microsecond {
+		t.Errorf("Unexpected skipped phase %+v", skipped)
+	}
+	if skipped.Budget <= 0 || skipped.Budget > 10*time.Second {
+		t.Errorf("Expected the budget left before Phase 3c, got %v", skipped.Budget)
+	}
+	if report.Skipped[1].Name != "Phase 4: very wide search" || skipped.Target != hex.EncodeToString(publicKey) {
+		t.Errorf("Unexpected skipped phases %+v", report.Skipped)
+	}
+	if len(report.Tested) != 5 {
+		t.Errorf("Expected the five phases that ran in the report, got %d", len(report.Tested))
+	}
+
+	// Without a deadline nothing is skipped, and the rate is never calibrated
+	strategy = NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig = RangeConfig{ARange: [2]int{1, 1}, BRange: [2]int{0, 99}, MaxPairs: 100, NumWorkers: 2, SkipOverBudget: true}
+	strategy.Report = &SearchReport{}
+	strategy.Search(context.Background(), signatures[2:], publicKey)
+	if len(strategy.Report.Skipped) != 0 || strategy.rate != (SearchRate{}) {
+		t.Errorf("Expected no skipped phase and no calibration, got %+v and rate %+v", strategy.Report.Skipped, strategy.rate)
+	}
+}
@@ -3,9 +3,11 @@ package ecdsaaffine
 import (
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
 )
 
 // Secp256k1CurveOrder is the order of the secp256k1 curve
@@ -24,7 +26,11 @@ var Secp256k1CurveOrder, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFF
 // Returns:
 //   - Private key if recovery successful, error otherwise
 func RecoverPrivateKey(sig1, sig2 *Signature, a, b *big.Int) (*big.Int, error) {
-	n := Secp256k1CurveOrder
+	return recoverPrivateKey(sig1, sig2, a, b, Secp256k1CurveOrder)
+}
+
+// recoverPrivateKey is RecoverPrivateKey in the group of order n.
+func recoverPrivateKey(sig1, sig2 *Signature, a, b, n *big.Int) (*big.Int, error) {
 
 	// Calculate numerator: (a * s2 * z1 - s1 * z2 + b * s1 * s2) mod n
 	as2z1 := new(big.Int).Mul(a, sig2.S)
@@ -74,19 +80,27 @@ func HashMessage(message []byte) *big.Int {
 	return z
 }
 
+// HashEthereumMessage hashes a message as eth_sign and personal_sign do (EIP-191):
+// Keccak-256 of "\x19Ethereum Signed Message:\n", the message length in decimal, and the
+// message. Use it as JSONParser.Hash or with WithHasher for r || s || v signatures from
+// Ethereum wallets.
+func HashEthereumMessage(message []byte) *big.Int {
+	prefixed := append([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))), message...)
+	z := new(big.Int).SetBytes(keccak256(prefixed))
+	z.Mod(z, Secp256k1CurveOrder)
+	return z
+}
+
 // VerifyRecoveredKey verifies that a recovered private key matches the given public key.
 //
 // Args:
 //   - privateKey: Recovered private key
-//   - publicKeyBytes: Public key in compressed format (33 bytes)
+//   - publicKeyBytes: Public key in any encoding ParsePublicKey accepts (compressed,
+//     uncompressed, hybrid, raw 64-byte X||Y) or a 20-byte Ethereum address
 //
 // Returns:
 //   - True if the private key matches the public key, false otherwise
 func VerifyRecoveredKey(privateKey *big.Int, publicKeyBytes []byte) (bool, error) {
-	if len(publicKeyBytes) != 33 {
-		return false, errors.New("public key must be 33 bytes (compressed format)")
-	}
-
 	privKey := new(big.Int).Set(privateKey)
 	if privKey.Cmp(big.NewInt(0)) <= 0 || privKey.Cmp(Secp256k1CurveOrder) >= 0 {
 		return false, errors.New("private key out of valid range")
@@ -105,20 +119,296 @@ func VerifyRecoveredKey(privateKey *big.Int, publicKeyBytes []byte) (bool, error
 	// Get the private key as a secp256k1 private key
 	privKeySecp256k1 := secp256k1.PrivKeyFromBytes(privKeyBytes)
 
-	// Get the public key
-	pubKey := privKeySecp256k1.PubKey()
-
-	// Serialize as compressed
-	recoveredPubKey := pubKey.SerializeCompressed()
-
 	// Compare with provided public key (mismatch is a normal result, not an error)
-	if len(recoveredPubKey) != len(publicKeyBytes) {
+	return matchesPublicKey(privKeySecp256k1.PubKey(), publicKeyBytes)
+}
+
+// VerifySignature performs full ECDSA verification of a parsed signature: it checks that
+// (r, s) is a valid signature of z by the given public key, not only that some key
+// matches. Verifying a dataset before recovery shows whether z was computed the way the
+// signer hashed the message; a wrong z verifies against no key.
+//
+// The key is compared as in VerifyRecoveredKey, so any encoding ParsePublicKey accepts,
+// an Ethereum address, or a key set from ParseTarget works: the signature is valid for
+// exactly the keys r^-1 * (s*R - z*G), for the two points R with x-coordinate r.
+//
+// Args:
+//   - sig: Parsed signature
+//   - publicKey: Signer's key; nil uses sig.SignerKey()
+//
+// Returns:
+//   - True if the signature verifies, false otherwise (including r or s outside [1, n-1])
+//   - Error if there is no key to verify against or it cannot be parsed
+func VerifySignature(sig *Signature, publicKey []byte) (bool, error) {
+	if publicKey == nil {
+		publicKey = sig.SignerKey()
+	}
+	if len(publicKey) == 0 {
+		return false, errors.New("no public key to verify against")
+	}
+	n := Secp256k1CurveOrder
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
 		return false, nil
 	}
-	for i := range publicKeyBytes {
-		if recoveredPubKey[i] != publicKeyBytes[i] {
-			return false, nil
+	R, err := LiftR(sig.R)
+	if err != nil {
+		return false, nil
+	}
+
+	// Q = r^-1*s*R - r^-1*z*G, for R and -R
+	rInv := new(big.Int).ModInverse(sig.R, n)
+	u1 := new(big.Int).Mul(rInv, sig.S)
+	u2 := new(big.Int).Mul(rInv, sig.Z)
+	u2.Neg(u2)
+	var k1 secp256k1.ModNScalar
+	k1.SetByteSlice(u1.Mod(u1, n).Bytes())
+	var zG secp256k1.JacobianPoint
+	scalarBaseMult(u2, &zG)
+
+	for _, odd := range []bool{false, true} {
+		point := *R
+		if odd {
+			point.Y.Negate(1).Normalize()
+		}
+		var Q secp256k1.JacobianPoint
+		secp256k1.ScalarMultNonConst(&k1, &point, &Q)
+		secp256k1.AddNonConst(&Q, &zG, &Q)
+		if isInfinity(&Q) {
+			continue
+		}
+		Q.ToAffine()
+		ok, err := matchesPublicKey(secp256k1.NewPublicKey(&Q.X, &Q.Y), publicKey)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// RecoverNonce computes the nonce used for a signature once the private key is known.
+//
+// From s = k^-1 * (z + r*d) mod n:
+// k = (z + r*d) / s mod n
+//
+// Args:
+//   - sig: Signature produced by the private key
+//   - privateKey: Known or recovered private key
+//
+// Returns:
+//   - Nonce k if successful, error otherwise
+func RecoverNonce(sig *Signature, privateKey *big.Int) (*big.Int, error) {
+	return recoverNonce(sig, privateKey, Secp256k1CurveOrder)
+}
+
+// recoverNonce is RecoverNonce in the group of order n.
+func recoverNonce(sig *Signature, privateKey, n *big.Int) (*big.Int, error) {
+
+	sInv := new(big.Int).ModInverse(sig.S, n)
+	if sInv == nil {
+		return nil, errors.New("failed to compute modular inverse of s")
+	}
+
+	k := new(big.Int).Mul(sig.R, privateKey)
+	k.Add(k, sig.Z)
+	k.Mul(k, sInv)
+	k.Mod(k, n)
+
+	return k, nil
+}
+
+// RecoverPrivateKeyFromKnownNonce recovers the private key from a single signature whose
+// nonce is known outright (e.g. leaked in logs or read from a debugger).
+//
+// From s = k^-1 * (z + r*d) mod n:
+// d = (s*k - z) / r mod n
+//
+// The nonce must reproduce the signature's r. Its negation n-k does too but yields a
+// different key, so verify the result against the public key when it is known.
+func RecoverPrivateKeyFromKnownNonce(sig *Signature, k *big.Int) (*big.Int, error) {
+	n := Secp256k1CurveOrder
+
+	nonce := new(big.Int).Mod(k, n)
+	if nonce.Sign() == 0 {
+		return nil, errors.New("nonce must be nonzero mod n")
+	}
+	if !nonceProducesR(sig, nonce) {
+		return nil, errors.New("nonce does not reproduce the signature's r")
+	}
+
+	rInv := new(big.Int).ModInverse(sig.R, n)
+	if rInv == nil {
+		return nil, errors.New("failed to compute modular inverse of r")
+	}
+
+	priv := new(big.Int).Mul(sig.S, nonce)
+	priv.Sub(priv, sig.Z)
+	priv.Mul(priv, rInv)
+	priv.Mod(priv, n)
+	if priv.Sign() == 0 {
+		return nil, errors.New("recovered private key is zero")
+	}
+
+	return priv, nil
+}
+
+// RecoverTwoKeys recovers the private keys of two signers whose nonces are affinely
+// related, as when both draw from the same flawed RNG sequence: sig1 and sig3 are by key 1,
+// sig2 and sig4 by key 2, and the hypothesis is k2 = a*k1 + b and k4 = a*k3 + b.
+//
+// Each signature gives its nonce as a linear function of its signer's key,
+// k = (z + r*d) / s, so each related pair is a line d2 = alpha*d1 + beta mod n:
+// alpha = a*s2*r1 / (s1*r2), beta = (a*s2*z1/s1 + b*s2 - z2) / r2. The two lines meet at
+// (d1, d2). As with RecoverPrivateKey, a wrong hypothesis gives wrong keys rather than an
+// error, so verify them against the signers' public keys.
+//
+// Args:
+//   - sig1, sig3: Two signatures by the first key
+//   - sig2, sig4: Two signatures by the second key
+//   - a, b: Affine relationship (k2 = a*k1 + b and k4 = a*k3 + b)
+//
+// Returns:
+//   - Private keys d1 and d2 if recovery successful, error otherwise
+func RecoverTwoKeys(sig1, sig2, sig3, sig4 *Signature, a, b *big.Int) (*big.Int, *big.Int, error) {
+	line1, err := twoKeyLine(sig1, sig2, a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	line2, err := twoKeyLine(sig3, sig4, a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return intersectLines(line1, line2)
+}
+
+// RecoverTwoKeysSharedNonce recovers the private keys of two signers that used the same
+// nonces (common with copy-pasted signer code): sig1 and sig3 are by key 1, sig2 and sig4
+// by key 2, sig1 and sig2 share one nonce and sig3 and sig4 another. It is RecoverTwoKeys
+// with a = 1, b = 0; one shared nonce alone only ties the keys together.
+//
+// Identical r also means nonces k and n-k, as after low-s normalization, so each pair is
+// tried both ways and the keys are verified against the signatures' signer keys (see
+// Signature.SignerKey), which must be known. With one key already known, the other
+// follows from a single shared nonce: RecoverNonce, then RecoverPrivateKeyFromKnownNonce.
+//
+// Returns:
+//   - Private keys d1 and d2 if both verify, error otherwise
+func RecoverTwoKeysSharedNonce(sig1, sig2, sig3, sig4 *Signature) (*big.Int, *big.Int, error) {
+	key1, key2 := sig1.SignerKey(), sig2.SignerKey()
+	if len(key1) == 0 || len(key2) == 0 {
+		return nil, nil, errors.New("signer keys of sig1 and sig2 are required")
+	}
+	if sig1.R.Cmp(sig2.R) != 0 || sig3.R.Cmp(sig4.R) != 0 {
+		return nil, nil, errors.New("sig1 and sig2, and sig3 and sig4, must have the same r")
+	}
+	if sig1.R.Cmp(sig3.R) == 0 {
+		return nil, nil, errors.New("the two shared nonces must differ")
+	}
+
+	for _, a1 := range []int64{1, -1} {
+		for _, a2 := range []int64{1, -1} {
+			line1, err := twoKeyLine(sig1, sig2, big.NewInt(a1), big.NewInt(0))
+			if err != nil {
+				return nil, nil, err
+			}
+			line2, err := twoKeyLine(sig3, sig4, big.NewInt(a2), big.NewInt(0))
+			if err != nil {
+				return nil, nil, err
+			}
+			d1, d2, err := intersectLines(line1, line2)
+			if err != nil || d1.Sign() == 0 || d2.Sign() == 0 {
+				continue
+			}
+			if ok, _ := VerifyRecoveredKey(d1, key1); !ok {
+				continue
+			}
+			if ok, _ := VerifyRecoveredKey(d2, key2); ok {
+				return d1, d2, nil
+			}
 		}
 	}
-	return true, nil
+	return nil, nil, errors.New("no pair of keys verifies: the signatures do not share nonces as given")
+}
+
+// twoKeyLine returns alpha and beta with d2 = alpha*d1 + beta mod n for a signature by
+// key 1 and one by key 2 with nonces related by k2 = a*k1 + b.
+func twoKeyLine(sig1, sig2 *Signature, a, b *big.Int) ([2]*big.Int, error) {
+	n := Secp256k1CurveOrder
+
+	s1Inv := new(big.Int).ModInverse(sig1.S, n)
+	r2Inv := new(big.Int).ModInverse(sig2.R, n)
+	if s1Inv == nil || r2Inv == nil {
+		return [2]*big.Int{}, errors.New("failed to compute modular inverse of r or s")
+	}
+
+	// a*s2/s1, the coefficient of k1 = (z1 + r1*d1) / s1 in d2
+	as2s1 := new(big.Int).Mul(a, sig2.S)
+	as2s1.Mul(as2s1, s1Inv)
+
+	alpha := new(big.Int).Mul(as2s1, sig1.R)
+	alpha.Mul(alpha, r2Inv)
+	alpha.Mod(alpha, n)
+
+	beta := new(big.Int).Mul(as2s1, sig1.Z)
+	bs2 := new(big.Int).Mul(b, sig2.S)
+	beta.Add(beta, bs2)
+	beta.Sub(beta, sig2.Z)
+	beta.Mul(beta, r2Inv)
+	beta.Mod(beta, n)
+
+	return [2]*big.Int{alpha, beta}, nil
+}
+
+// intersectLines solves d2 = alpha*d1 + beta for two lines (alpha, beta) mod n.
+func intersectLines(line1, line2 [2]*big.Int) (*big.Int, *big.Int, error) {
+	n := Secp256k1CurveOrder
+
+	// d1 = (beta2 - beta1) / (alpha1 - alpha2) mod n
+	denominator := new(big.Int).Sub(line1[0], line2[0])
+	denominator.Mod(denominator, n)
+	if denominator.Sign() == 0 {
+		return nil, nil, errors.New("denominator is zero: the two pairs give the same relation between the keys")
+	}
+	denominatorInv := new(big.Int).ModInverse(denominator, n)
+	if denominatorInv == nil {
+		return nil, nil, errors.New("failed to compute modular inverse")
+	}
+
+	d1 := new(big.Int).Sub(line2[1], line1[1])
+	d1.Mul(d1, denominatorInv)
+	d1.Mod(d1, n)
+
+	d2 := new(big.Int).Mul(line1[0], d1)
+	d2.Add(d2, line1[1])
+	d2.Mod(d2, n)
+
+	return d1, d2, nil
+}
+
+// RecoverNonces computes the nonce of every signature using the private key in result.
+// Analysts can use the nonces to characterize the flawed RNG (see pkg/prngrecovery).
+func RecoverNonces(result *RecoveryResult, signatures []*Signature) ([]*big.Int, error) {
+	if result == nil || result.PrivateKey == nil {
+		return nil, errors.New("result has no private key")
+	}
+
+	nonces := make([]*big.Int, len(signatures))
+	for i, sig := range signatures {
+		k, err := RecoverNonce(sig, result.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+		nonces[i] = k
+	}
+	return nonces, nil
+}
+
+// AnalyzeNonces computes the nonce of every signature with RecoverNonces and runs the
+// nonce relationship analysis on them (nonceanalysis.Analyze), which identifies the
+// flawed generator's pattern: reused nonce, counter, per-session seed or affine recurrence.
+func AnalyzeNonces(result *RecoveryResult, signatures []*Signature, opts nonceanalysis.Options) ([]*big.Int, *nonceanalysis.Report, error) {
+	nonces, err := RecoverNonces(result, signatures)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nonces, nonceanalysis.Analyze(nonces, Secp256k1CurveOrder, opts), nil
 }
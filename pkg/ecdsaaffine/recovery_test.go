@@ -1,8 +1,14 @@
 package ecdsaaffine
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"math/big"
 	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
 )
 
 func TestRecoverPrivateKey(t *testing.T) {
@@ -227,3 +233,187 @@ func TestVerifyRecoveredKey_InvalidPublicKey(t *testing.T) {
 		t.Error("Expected error for invalid public key length")
 	}
 }
+
+func TestRecoverPrivateKeyFromKnownNonce(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	sig := signWithNonce(d, k, HashMessage([]byte("message 1")))
+
+	priv, err := RecoverPrivateKeyFromKnownNonce(sig, k)
+	if err != nil {
+		t.Fatalf("RecoverPrivateKeyFromKnownNonce: %v", err)
+	}
+	if priv.Cmp(d) != 0 {
+		t.Errorf("Expected %s, got %s", d.Text(16), priv.Text(16))
+	}
+
+	// The negated nonce reproduces r but not the key
+	negated := new(big.Int).Sub(Secp256k1CurveOrder, k)
+	if priv, err := RecoverPrivateKeyFromKnownNonce(sig, negated); err != nil || priv.Cmp(d) == 0 {
+		t.Errorf("Expected a different key from the negated nonce, got %v, %v", priv, err)
+	}
+
+	for _, wrong := range []*big.Int{new(big.Int).Add(k, big.NewInt(1)), big.NewInt(0)} {
+		if _, err := RecoverPrivateKeyFromKnownNonce(sig, wrong); err == nil {
+			t.Errorf("Expected an error for nonce %s", wrong.Text(16))
+		}
+	}
+}
+
+func TestRecoverTwoKeys(t *testing.T) {
+	// Two signers drawing alternately from one RNG sequence k' = 3*k + 7
+	d1, d2 := big.NewInt(0xdeadbeef), big.NewInt(0xc0ffee)
+	k, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	var signatures []*Signature
+	for i := 0; i < 4; i++ {
+		d := []*big.Int{d1, d2}[i%2]
+		signatures = append(signatures, signWithNonce(d, k, HashMessage([]byte(fmt.Sprintf("message %d", i)))))
+		k = new(big.Int).Mul(k, big.NewInt(3))
+		k.Add(k, big.NewInt(7))
+		k.Mod(k, Secp256k1CurveOrder)
+	}
+
+	got1, got2, err := RecoverTwoKeys(signatures[0], signatures[1], signatures[2], signatures[3], big.NewInt(3), big.NewInt(7))
+	if err != nil {
+		t.Fatalf("RecoverTwoKeys: %v", err)
+	}
+	if got1.Cmp(d1) != 0 || got2.Cmp(d2) != 0 {
+		t.Errorf("Expected keys %s and %s, got %s and %s", d1, d2, got1, got2)
+	}
+
+	// A wrong hypothesis gives keys that do not verify
+	got1, _, err = RecoverTwoKeys(signatures[0], signatures[1], signatures[2], signatures[3], big.NewInt(3), big.NewInt(8))
+	if err == nil && got1.Cmp(d1) == 0 {
+		t.Error("Expected a wrong hypothesis not to give the key")
+	}
+
+	// The same pair twice leaves one equation for two keys
+	if _, _, err := RecoverTwoKeys(signatures[0], signatures[1], signatures[0], signatures[1], big.NewInt(3), big.NewInt(7)); err == nil {
+		t.Error("Expected an error for the same pair twice")
+	}
+}
+
+func TestRecoverTwoKeysSharedNonce(t *testing.T) {
+	signatures, keys := sharedNonceDataset()
+	d1, d2, err := RecoverTwoKeysSharedNonce(signatures[0], signatures[1], signatures[2], signatures[3])
+	if err != nil {
+		t.Fatalf("RecoverTwoKeysSharedNonce: %v", err)
+	}
+	if d1.Cmp(keys[0]) != 0 || d2.Cmp(keys[1]) != 0 {
+		t.Errorf("Expected keys %s and %s, got %s and %s", keys[0], keys[1], d1, d2)
+	}
+
+	// The pairs must share r, and the two nonces must differ
+	if _, _, err := RecoverTwoKeysSharedNonce(signatures[0], signatures[3], signatures[2], signatures[1]); err == nil {
+		t.Error("Expected an error for pairs with different r")
+	}
+	if _, _, err := RecoverTwoKeysSharedNonce(signatures[0], signatures[1], signatures[0], signatures[1]); err == nil {
+		t.Error("Expected an error for a single shared nonce")
+	}
+
+	// Without signer keys the solution cannot be checked
+	anonymous := *signatures[1]
+	anonymous.PublicKey = nil
+	if _, _, err := RecoverTwoKeysSharedNonce(signatures[0], &anonymous, signatures[2], signatures[3]); err == nil {
+		t.Error("Expected an error without the second signer's key")
+	}
+}
+
+func TestRecoverNonces(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2 := new(big.Int).Add(k1, big.NewInt(1))
+
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+
+	result := &RecoveryResult{PrivateKey: d}
+	nonces, err := RecoverNonces(result, signatures)
+	if err != nil {
+		t.Fatalf("RecoverNonces: %v", err)
+	}
+	if nonces[0].Cmp(k1) != 0 || nonces[1].Cmp(k2) != 0 {
+		t.Errorf("Nonce mismatch: got [%s, %s]", nonces[0].Text(16), nonces[1].Text(16))
+	}
+
+	if _, err := RecoverNonces(&RecoveryResult{}, signatures); err == nil {
+		t.Error("Expected error for result without private key")
+	}
+}
+
+func TestAnalyzeNonces(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	var signatures []*Signature
+	for i := int64(0); i < 4; i++ {
+		nonce := new(big.Int).Add(k, big.NewInt(5*i))
+		signatures = append(signatures, signWithNonce(d, nonce, HashMessage([]byte(fmt.Sprintf("message %d", i)))))
+	}
+
+	nonces, report, err := AnalyzeNonces(&RecoveryResult{PrivateKey: d}, signatures, nonceanalysis.DefaultOptions())
+	if err != nil {
+		t.Fatalf("AnalyzeNonces: %v", err)
+	}
+	if len(nonces) != 4 || nonces[3].Cmp(new(big.Int).Add(k, big.NewInt(15))) != 0 {
+		t.Errorf("Unexpected nonces %v", nonces)
+	}
+	if report.Pattern != nonceanalysis.PatternConstantStep || report.Step.Int64() != 5 {
+		t.Errorf("Expected a constant step of 5, got %s (step %v)", report.Pattern, report.Step)
+	}
+
+	if _, _, err := AnalyzeNonces(nil, signatures, nonceanalysis.DefaultOptions()); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	d := big.NewInt(123456789)
+	priv := secp256k1.PrivKeyFromBytes(d.Bytes())
+	publicKey := priv.PubKey().SerializeCompressed()
+
+	// A standard RFC 6979 signature
+	digest := sha256.Sum256([]byte("verify me"))
+	compact := ecdsa.SignCompact(priv, digest[:], true) // <recovery flag><R><S>
+	sig := &Signature{
+		Z: new(big.Int).SetBytes(digest[:]),
+		R: new(big.Int).SetBytes(compact[1:33]),
+		S: new(big.Int).SetBytes(compact[33:]),
+	}
+	for name, key := range map[string][]byte{
+		"compressed":       publicKey,
+		"uncompressed":     priv.PubKey().SerializeUncompressed(),
+		"ethereum address": EthereumAddress(d),
+	} {
+		if ok, err := VerifySignature(sig, key); err != nil || !ok {
+			t.Errorf("Expected the signature to verify against the %s key (%v)", name, err)
+		}
+	}
+
+	// Flawed nonces verify like any other; the signature's own key is used by default
+	flawed := signWithNonce(d, big.NewInt(42), big.NewInt(7))
+	flawed.PublicKey = publicKey
+	if ok, err := VerifySignature(flawed, nil); err != nil || !ok {
+		t.Errorf("Expected a signature with a raw nonce to verify (%v)", err)
+	}
+
+	wrongZ := *sig
+	wrongZ.Z = HashMessage([]byte("verify me"))
+	wrongZ.Z.Add(wrongZ.Z, big.NewInt(1))
+	if ok, _ := VerifySignature(&wrongZ, publicKey); ok {
+		t.Error("Expected a wrong z to fail")
+	}
+	other := secp256k1.PrivKeyFromBytes([]byte{7}).PubKey().SerializeCompressed()
+	if ok, _ := VerifySignature(sig, other); ok {
+		t.Error("Expected a different public key to fail")
+	}
+	zeroS := *sig
+	zeroS.S = big.NewInt(0)
+	if ok, _ := VerifySignature(&zeroS, publicKey); ok {
+		t.Error("Expected s = 0 to be rejected")
+	}
+	if _, err := VerifySignature(sig, nil); err == nil {
+		t.Error("Expected an error without a public key")
+	}
+}
@@ -0,0 +1,117 @@
+package ecdsaaffine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registered strategies and parsers, by name. Third-party packages register theirs from
+// an init function, as database/sql drivers do, so programs (and the CLI, through Go
+// plugins) select them by name without this package knowing them:
+//
+//	func init() {
+//		ecdsaaffine.RegisterStrategy("hsm-lcg", func() ecdsaaffine.BruteForceStrategy {
+//			return &HSMStrategy{}
+//		})
+//	}
+var (
+	registryMu sync.RWMutex
+	strategies = make(map[string]func() BruteForceStrategy)
+	parsers    = make(map[string]func() SignatureParser)
+)
+
+func init() {
+	RegisterStrategy("smart", func() BruteForceStrategy { return NewSmartBruteForceStrategy() })
+	RegisterStrategy("same-nonce", func() BruteForceStrategy { return NewSameNonceStrategy() })
+	RegisterStrategy("patterns", func() BruteForceStrategy { return NewPatternStrategy() })
+	RegisterStrategy("adaptive", func() BruteForceStrategy { return NewAdaptiveStrategy() })
+	RegisterStrategy("bsgs", func() BruteForceStrategy { return NewBSGSStrategy() })
+
+	RegisterParser("json", func() SignatureParser {
+		return &JSONParser{MessageField: "message", RField: "r", SField: "s", ZField: "z"}
+	})
+	RegisterParser("csv", func() SignatureParser {
+		return &CSVParser{MessageCol: "message", RCol: "r", SCol: "s", ZCol: "z"}
+	})
+	RegisterParser("store", func() SignatureParser { return &StoreParser{} })
+	RegisterParser("pkcs11", func() SignatureParser { return &PKCS11Parser{} })
+	RegisterParser("keystore", func() SignatureParser { return &KeystoreParser{} })
+}
+
+// RegisterStrategy makes a strategy available by name; factory returns a new instance
+// with its default settings for each NewStrategy call. It panics if the name is empty,
+// factory is nil or the name is already registered.
+func RegisterStrategy(name string, factory func() BruteForceStrategy) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" || factory == nil {
+		panic("ecdsaaffine: RegisterStrategy needs a name and a factory")
+	}
+	if _, dup := strategies[name]; dup {
+		panic("ecdsaaffine: RegisterStrategy called twice for " + name)
+	}
+	strategies[name] = factory
+}
+
+// RegisterParser makes a signature parser available by name, as RegisterStrategy does
+// for strategies.
+func RegisterParser(name string, factory func() SignatureParser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" || factory == nil {
+		panic("ecdsaaffine: RegisterParser needs a name and a factory")
+	}
+	if _, dup := parsers[name]; dup {
+		panic("ecdsaaffine: RegisterParser called twice for " + name)
+	}
+	parsers[name] = factory
+}
+
+// NewStrategy returns a new instance of the strategy registered as name. The package
+// registers "smart", "same-nonce", "patterns", "adaptive" and "bsgs".
+func NewStrategy(name string) (BruteForceStrategy, error) {
+	registryMu.RLock()
+	factory, ok := strategies[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (registered: %v)", name, Strategies())
+	}
+	return factory(), nil
+}
+
+// NewParser returns a new instance of the parser registered as name. The package
+// registers "json" and "csv" (reading a "z" field or column if present, else hashing
+// "message"), "store", "pkcs11" and "keystore".
+func NewParser(name string) (SignatureParser, error) {
+	registryMu.RLock()
+	factory, ok := parsers[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (registered: %v)", name, Parsers())
+	}
+	return factory(), nil
+}
+
+// Strategies returns the registered strategy names, sorted.
+func Strategies() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return sortedNames(strategies)
+}
+
+// Parsers returns the registered parser names, sorted.
+func Parsers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return sortedNames(parsers)
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
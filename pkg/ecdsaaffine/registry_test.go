@@ -0,0 +1,57 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type registryTestStrategy struct{}
+
+func (registryTestStrategy) Name() string { return "registry test" }
+
+func (registryTestStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"smart", "same-nonce", "patterns", "adaptive", "bsgs"} {
+		if _, err := NewStrategy(name); err != nil {
+			t.Errorf("Built-in strategy %s: %v", name, err)
+		}
+	}
+	parser, err := NewParser("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := parser.(*JSONParser); !ok || p.ZField != "z" {
+		t.Errorf("Expected a JSONParser reading z, got %#v", parser)
+	}
+
+	RegisterStrategy("registry-test", func() BruteForceStrategy { return registryTestStrategy{} })
+	strategy, err := NewStrategy("registry-test")
+	if err != nil || strategy.Name() != "registry test" {
+		t.Fatalf("Expected the registered strategy, got %v (%v)", strategy, err)
+	}
+	found := false
+	for _, name := range Strategies() {
+		found = found || name == "registry-test"
+	}
+	if !found {
+		t.Errorf("Strategies() does not list the registered strategy: %v", Strategies())
+	}
+
+	if _, err := NewStrategy("no-such-strategy"); err == nil || !strings.Contains(err.Error(), "smart") {
+		t.Errorf("Expected an error listing the registered strategies, got %v", err)
+	}
+	if _, err := NewParser("no-such-format"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic registering a name twice")
+		}
+	}()
+	RegisterParser("json", func() SignatureParser { return &JSONParser{} })
+}
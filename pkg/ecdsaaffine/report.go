@@ -0,0 +1,359 @@
+package ecdsaaffine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SearchReport records the (relationship range, signature pair) combinations a
+// SmartBruteForceStrategy searched without finding the key. Given to a later search of
+// the same dataset (SmartBruteForceStrategy.Report), it acts as an exclusion set: pairs
+// whose combinations a phase would repeat are skipped, and the phase moves on to pairs
+// not searched yet.
+//
+// Signatures are identified by a hash of (r, s, z) rather than by position, so a report
+// stays valid when the dataset is reordered or grows. Exclusions only apply to searches
+// verifying against the same target the report was recorded with.
+//
+// Skipped lists the range phases a search left out because they were predicted not to
+// finish in its budget (RangeConfig.SkipOverBudget). They are not exclusions: a later
+// search with a larger budget runs them.
+//
+// A report is the checkpoint of a long campaign, so its JSON is a versioned schema that
+// outlives releases: it is written with "version": SearchReportVersion, and every release
+// reads the reports of all versions up to its own (see ParseSearchReport). Within a
+// version, fields are only ever added, and only fields whose absence leaves exclusions
+// sound: a release that does not know a field ignores it, and at worst searches again
+// what the field would have excluded. Any other change bumps the version, with a
+// migration of the older reports.
+type SearchReport struct {
+	Tested  []TestedRange  `json:"tested"`
+	Skipped []SkippedPhase `json:"skipped,omitempty"`
+
+	mu  sync.Mutex
+	pos []map[string]int // position of each signature ID in Tested[i].Signatures, built on first use
+}
+
+// TestedRange is an (a, b) rectangle searched without result on the first Pairs pairs
+// (i < j, in order) of Signatures, in both directions if BothDirections is set.
+type TestedRange struct {
+	Target         string   `json:"target,omitempty"` // hex verification target; empty if there was none
+	ARange         [2]int64 `json:"a_range"`
+	BRange         [2]int64 `json:"b_range"`
+	SkipZeroA      bool     `json:"skip_zero_a,omitempty"`
+	BothDirections bool     `json:"both_directions,omitempty"`
+	Signatures     []string `json:"signatures"` // signature IDs in search order (see signatureID)
+	Pairs          int      `json:"pairs"`
+}
+
+// SkippedPhase is a range phase skipped because its estimate, Estimate, exceeded the
+// Budget left when it was due to start.
+type SkippedPhase struct {
+	Target   string        `json:"target,omitempty"`
+	Name     string        `json:"name"`
+	ARange   [2]int64      `json:"a_range"`
+	BRange   [2]int64      `json:"b_range"`
+	Pairs    int           `json:"pairs"`
+	Estimate time.Duration `json:"estimate"`
+	Budget   time.Duration `json:"budget"`
+}
+
+// SearchReportVersion is the schema version of the search reports this release writes.
+// Reports written before the schema was versioned, without a "version", are version 1.
+const SearchReportVersion = 1
+
+// ErrSearchReportVersion is returned for a report of a newer schema version than
+// SearchReportVersion, written by a later release. Such a report is refused rather than
+// half understood; search with that release, or start a new report.
+var ErrSearchReportVersion = errors.New("search report written by a newer release")
+
+// searchReportMigrations[v-1] rewrites the top-level fields of a version v report into
+// version v+1, so that every migration runs in turn up to SearchReportVersion.
+var searchReportMigrations = []func(fields map[string]json.RawMessage) error{}
+
+// LoadSearchReport reads a report written by SearchReport.Save, of any version up to
+// SearchReportVersion (see ParseSearchReport).
+func LoadSearchReport(path string) (*SearchReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	report, err := ParseSearchReport(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse search report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// ParseSearchReport is LoadSearchReport for a file's contents. A report of an earlier
+// version is migrated to SearchReportVersion, which Save then writes; one of a later
+// version fails with ErrSearchReportVersion.
+func ParseSearchReport(data []byte) (*SearchReport, error) {
+	var report SearchReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// searchReportFile is the JSON of a SearchReport: its fields, after the version.
+type searchReportFile struct {
+	Version int `json:"version"`
+	*searchReportFields
+}
+
+// searchReportFields has the fields and JSON tags of SearchReport without its methods.
+type searchReportFields SearchReport
+
+// MarshalJSON writes the report as a version SearchReportVersion report.
+func (r *SearchReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(searchReportFile{SearchReportVersion, (*searchReportFields)(r)})
+}
+
+// UnmarshalJSON reads a report of any version up to SearchReportVersion, migrating it.
+func (r *SearchReport) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	version := 1
+	if raw, ok := fields["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return fmt.Errorf("invalid search report version: %w", err)
+		}
+	}
+	switch {
+	case version > SearchReportVersion:
+		return fmt.Errorf("%w: version %d, this release reads up to version %d", ErrSearchReportVersion, version, SearchReportVersion)
+	case version < 1:
+		return fmt.Errorf("invalid search report version %d", version)
+	}
+	for v := version; v < SearchReportVersion; v++ {
+		if err := searchReportMigrations[v-1](fields); err != nil {
+			return fmt.Errorf("migrating search report version %d to %d: %w", v, v+1, err)
+		}
+	}
+
+	delete(fields, "version")
+	migrated, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(migrated, (*searchReportFields)(r))
+}
+
+// Save writes the report to path through a temporary file, so an interrupted save never
+// loses the report it replaces.
+func (r *SearchReport) Save(path string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// record adds a rectangle searched without result on the first pairs pairs of signatures.
+// It is safe to call on a nil report.
+func (r *SearchReport) record(signatures []*Signature, publicKey []byte, aRange, bRange [2]int64, skipZeroA, bothDirections bool, pairs int) {
+	if r == nil || pairs == 0 {
+		return
+	}
+	tested := TestedRange{
+		Target:         hex.EncodeToString(publicKey),
+		ARange:         aRange,
+		BRange:         bRange,
+		SkipZeroA:      skipZeroA,
+		BothDirections: bothDirections,
+		Signatures:     signatureIDs(signatures),
+		Pairs:          pairs,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Runs repeating a search of the same dataset extend its entry instead of adding one
+	for k := range r.Tested {
+		existing := &r.Tested[k]
+		if existing.Target == tested.Target && existing.ARange == aRange && existing.BRange == bRange &&
+			existing.SkipZeroA == skipZeroA && existing.BothDirections == bothDirections && slices.Equal(existing.Signatures, tested.Signatures) {
+			existing.Pairs = max(existing.Pairs, pairs)
+			return
+		}
+	}
+	r.Tested = append(r.Tested, tested)
+}
+
+// skip notes a range phase skipped for lack of budget, replacing an earlier note of the
+// same phase. It is safe to call on a nil report.
+func (r *SearchReport) skip(publicKey []byte, phase PhaseEstimate, pairs int, budget time.Duration) {
+	if r == nil {
+		return
+	}
+	skipped := SkippedPhase{
+		Target:   hex.EncodeToString(publicKey),
+		Name:     phase.Name,
+		ARange:   int64Range(phase.ARange),
+		BRange:   int64Range(phase.BRange),
+		Pairs:    pairs,
+		Estimate: phase.Duration,
+		Budget:   budget,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k := range r.Skipped {
+		existing := &r.Skipped[k]
+		if existing.Target == skipped.Target && existing.ARange == skipped.ARange && existing.BRange == skipped.BRange {
+			*existing = skipped
+			return
+		}
+	}
+	r.Skipped = append(r.Skipped, skipped)
+}
+
+// excluded returns a function reporting whether the report shows that the whole (a, b)
+// rectangle was already searched on the pair (i, j) of signatures. With a nil report, or
+// none of its ranges covering the rectangle, the function always returns false.
+func (r *SearchReport) excluded(signatures []*Signature, publicKey []byte, aRange, bRange [2]int64, skipZeroA, bothDirections bool) func(i, j int) bool {
+	none := func(i, j int) bool { return false }
+	if r == nil {
+		return none
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.pos) < len(r.Tested) {
+		pos := make(map[string]int, len(r.Tested[len(r.pos)].Signatures))
+		for k, id := range r.Tested[len(r.pos)].Signatures {
+			pos[id] = k
+		}
+		r.pos = append(r.pos, pos)
+	}
+
+	target := hex.EncodeToString(publicKey)
+	type covering struct {
+		pos   map[string]int
+		n     int
+		pairs int
+	}
+	var covers []covering
+	for k, tested := range r.Tested {
+		if tested.Target == target && tested.covers(aRange, bRange, skipZeroA, bothDirections) {
+			covers = append(covers, covering{r.pos[k], len(tested.Signatures), tested.Pairs})
+		}
+	}
+	if len(covers) == 0 {
+		return none
+	}
+
+	ids := signatureIDs(signatures)
+	return func(i, j int) bool {
+		for _, c := range covers {
+			pi, ok := c.pos[ids[i]]
+			if !ok {
+				continue
+			}
+			// The pair's direction matters: k1 belongs to the earlier signature
+			if pj, ok := c.pos[ids[j]]; ok && pi < pj && pairIndex(pi, pj, c.n) < c.pairs {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// covers reports whether t's rectangle contains the given one.
+func (t *TestedRange) covers(aRange, bRange [2]int64, skipZeroA, bothDirections bool) bool {
+	if aRange[0] < t.ARange[0] || aRange[1] > t.ARange[1] || bRange[0] < t.BRange[0] || bRange[1] > t.BRange[1] {
+		return false
+	}
+	if bothDirections && !t.BothDirections {
+		return false
+	}
+	// a = 0 was not searched if t skipped it
+	return !t.SkipZeroA || skipZeroA || aRange[0] > 0 || aRange[1] < 0
+}
+
+// pairIndex is the position of the pair (i, j), i < j, among the pairs of n signatures
+// enumerated as (0, 1), (0, 2), ..., (1, 2), ...
+func pairIndex(i, j, n int) int {
+	return i*n - i*(i+1)/2 + (j - i - 1)
+}
+
+// pairAt is the inverse of pairIndex: the pair at position p among the pairs of n signatures.
+func pairAt(p, n int) (i, j int) {
+	for i = 0; p >= n-1-i; i++ {
+		p -= n - 1 - i
+	}
+	return i, i + 1 + p
+}
+
+// signatureIDs identifies signatures by a hash of (r, s, z).
+func signatureIDs(signatures []*Signature) []string {
+	ids := make([]string, len(signatures))
+	for k, sig := range signatures {
+		ids[k] = signatureID(sig)
+	}
+	return ids
+}
+
+func signatureID(sig *Signature) string {
+	var buf [96]byte
+	fillScalar(buf[0:32], sig.R)
+	fillScalar(buf[32:64], sig.S)
+	fillScalar(buf[64:96], sig.Z)
+	sum := sha256.Sum256(buf[:])
+	return hex.EncodeToString(sum[:8])
+}
+
+// fillScalar writes v big-endian into dst, reduced modulo 2^256 if it is larger.
+func fillScalar(dst []byte, v *big.Int) {
+	if v == nil {
+		return
+	}
+	if v.Sign() < 0 || v.BitLen() > 8*len(dst) {
+		v = new(big.Int).Mod(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(dst))))
+	}
+	v.FillBytes(dst)
+}
+
+// patternRange is the single-point rectangle of a pattern, or false if a or b does not
+// fit in an int64.
+func patternRange(a, b *big.Int) (aRange, bRange [2]int64, ok bool) {
+	if !a.IsInt64() || !b.IsInt64() {
+		return aRange, bRange, false
+	}
+	return [2]int64{a.Int64(), a.Int64()}, [2]int64{b.Int64(), b.Int64()}, true
+}
+
+func int64Range(r [2]int) [2]int64 {
+	return [2]int64{int64(r[0]), int64(r[1])}
+}
@@ -0,0 +1,200 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/metrics"
+)
+
+// reportTestSignatures returns signatures by d whose first two nonces are related by
+// k2 = k1 + 7 and whose others are unrelated, with d's public key.
+func reportTestSignatures(d *big.Int) ([]*Signature, []byte) {
+	var nonces = []int64{1000001, 1000008, 7777777, 123456789, 987654321}
+	signatures := make([]*Signature, len(nonces))
+	for i, k := range nonces {
+		signatures[i] = signWithNonce(d, big.NewInt(k), HashMessage([]byte{byte(i)}))
+	}
+	var scalar secp256k1.ModNScalar
+	scalar.SetByteSlice(d.Bytes())
+	return signatures, secp256k1.NewPrivateKey(&scalar).PubKey().SerializeCompressed()
+}
+
+func reportTestStrategy(report *SearchReport, bRange [2]int, maxPairs int) (*SmartBruteForceStrategy, *metrics.Search) {
+	strategy := NewSmartBruteForceStrategy().
+		WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	strategy.RangeConfig = RangeConfig{ARange: [2]int{1, 2}, BRange: bRange, MaxPairs: maxPairs, NumWorkers: 2, SkipZeroA: true}
+	strategy.Report = report
+	strategy.Metrics = metrics.NewSearch("secp256k1")
+	return strategy, strategy.Metrics
+}
+
+func TestSearchReport_SkipsSearchedPairs(t *testing.T) {
+	d := big.NewInt(0xc0ffee)
+	signatures, publicKey := reportTestSignatures(d)
+	unrelated := signatures[2:]
+
+	// Each run searches the next MaxPairs pairs the report has not seen
+	report := &SearchReport{}
+	for run, want := range []struct{ searched, recorded int }{{2, 2}, {1, 3}} {
+		strategy, m := reportTestStrategy(report, [2]int{-50, 50}, 2)
+		if result := strategy.Search(context.Background(), unrelated, publicKey); result != nil {
+			t.Fatalf("Run %d: unexpected key %+v", run, result)
+		}
+		if pairs := m.Snapshot().Pairs; pairs != int64(want.searched) {
+			t.Errorf("Run %d: searched %d pairs, want %d", run, pairs, want.searched)
+		}
+		if recorded := report.Tested[len(report.Tested)-1].Pairs; recorded != want.recorded {
+			t.Errorf("Run %d: recorded %d pairs as searched, want %d", run, recorded, want.recorded)
+		}
+	}
+
+	if len(report.Tested) != 1 {
+		t.Errorf("Repeated runs left %d entries, want them merged into 1", len(report.Tested))
+	}
+
+	// Everything is searched now
+	strategy, m := reportTestStrategy(report, [2]int{-50, 50}, 2)
+	strategy.Search(context.Background(), unrelated, publicKey)
+	if pairs := m.Snapshot().Pairs; pairs != 0 {
+		t.Errorf("Searched %d pairs the report covers", pairs)
+	}
+
+	// A narrower rectangle is covered too; a wider one is not
+	strategy, m = reportTestStrategy(report, [2]int{0, 10}, 2)
+	strategy.Search(context.Background(), unrelated, publicKey)
+	if pairs := m.Snapshot().Pairs; pairs != 0 {
+		t.Errorf("Searched %d pairs of a covered sub-range", pairs)
+	}
+	strategy, m = reportTestStrategy(report, [2]int{-60, 50}, 2)
+	strategy.Search(context.Background(), unrelated, publicKey)
+	if pairs := m.Snapshot().Pairs; pairs != 2 {
+		t.Errorf("Searched %d pairs of a wider range, want 2", pairs)
+	}
+}
+
+func TestSearchReport_Exclusion(t *testing.T) {
+	d := big.NewInt(0xc0ffee)
+	signatures, publicKey := reportTestSignatures(d)
+
+	// A report claiming the key's pair was searched hides the key
+	report := &SearchReport{}
+	report.record(signatures, publicKey, [2]int64{1, 1}, [2]int64{0, 200000}, false, false, 1)
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadSearchReport(path)
+	if err != nil {
+		t.Fatalf("LoadSearchReport: %v", err)
+	}
+
+	for _, bRange := range [][2]int{{0, 10}, {0, 200000}} { // sequential and parallel phases
+		strategy, m := reportTestStrategy(loaded, bRange, 1)
+		strategy.RangeConfig.ARange = [2]int{1, 1}
+		if result := strategy.Search(context.Background(), signatures[:2], publicKey); result != nil {
+			t.Errorf("b in %v: found the key in an excluded pair", bRange)
+		}
+		if pairs := m.Snapshot().Pairs; pairs != 0 {
+			t.Errorf("b in %v: searched %d excluded pairs", bRange, pairs)
+		}
+	}
+
+	// The exclusion is bound to the target and to the pair's direction
+	otherKey := secp256k1.NewPrivateKey(new(secp256k1.ModNScalar).SetInt(2)).PubKey().SerializeCompressed()
+	if loaded.excluded(signatures, otherKey, [2]int64{1, 1}, [2]int64{0, 10}, false, false)(0, 1) {
+		t.Error("An exclusion recorded for another target applied")
+	}
+	reversed := []*Signature{signatures[1], signatures[0]}
+	if loaded.excluded(reversed, publicKey, [2]int64{1, 1}, [2]int64{0, 10}, false, false)(0, 1) {
+		t.Error("An exclusion applied to the reversed pair")
+	}
+
+	// Without the report the key is found
+	strategy, _ := reportTestStrategy(nil, [2]int{0, 10}, 1)
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	result := strategy.Search(context.Background(), signatures[:2], publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Errorf("Expected the key without a report, got %+v", result)
+	}
+}
+
+func TestTestedRange_Covers(t *testing.T) {
+	tested := TestedRange{ARange: [2]int64{-5, 5}, BRange: [2]int64{0, 100}, SkipZeroA: true}
+	tests := []struct {
+		aRange, bRange [2]int64
+		skipZeroA      bool
+		bothDirections bool
+		want           bool
+	}{
+		{[2]int64{1, 5}, [2]int64{0, 100}, false, false, true},
+		{[2]int64{-5, 5}, [2]int64{10, 20}, true, false, true},
+		{[2]int64{-5, 5}, [2]int64{10, 20}, false, false, false}, // a = 0 was skipped
+		{[2]int64{1, 6}, [2]int64{0, 100}, true, false, false},
+		{[2]int64{1, 5}, [2]int64{-1, 100}, true, false, false},
+		{[2]int64{1, 5}, [2]int64{0, 100}, true, true, false}, // searched in one direction only
+	}
+	for _, tt := range tests {
+		if got := tested.covers(tt.aRange, tt.bRange, tt.skipZeroA, tt.bothDirections); got != tt.want {
+			t.Errorf("covers(%v, %v, %v, %v) = %v, want %v", tt.aRange, tt.bRange, tt.skipZeroA, tt.bothDirections, got, tt.want)
+		}
+	}
+
+	tested.BothDirections = true
+	if !tested.covers([2]int64{1, 5}, [2]int64{0, 100}, true, true) || !tested.covers([2]int64{1, 5}, [2]int64{0, 100}, true, false) {
+		t.Error("Expected a range searched in both directions to cover either search")
+	}
+}
+
+func TestSearchReport_Version(t *testing.T) {
+	if len(searchReportMigrations) != SearchReportVersion-1 {
+		t.Fatalf("Expected a migration to each version up to %d, got %d", SearchReportVersion, len(searchReportMigrations))
+	}
+
+	signatures, publicKey := reportTestSignatures(big.NewInt(0xc0ffee))
+	report := &SearchReport{}
+	report.record(signatures, publicKey, [2]int64{1, 1}, [2]int64{0, 100}, false, false, 3)
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf(`{"version":%d,"tested":[`, SearchReportVersion); !strings.HasPrefix(string(data), want) {
+		t.Errorf("Expected the report to start with %s, got %s", want, data)
+	}
+
+	// A report from before the schema was versioned is version 1, and fields added later
+	// within a version are ignored by the releases that do not know them
+	unversioned := `{"tested": [{"a_range": [1, 1], "b_range": [0, 100], "signatures": ["a", "b"], "pairs": 1, "added_later": true}]}`
+	parsed, err := ParseSearchReport([]byte(unversioned))
+	if err != nil {
+		t.Fatalf("ParseSearchReport: %v", err)
+	}
+	if len(parsed.Tested) != 1 || parsed.Tested[0].BRange != [2]int64{0, 100} || parsed.Tested[0].Pairs != 1 {
+		t.Errorf("Unexpected report %+v", parsed)
+	}
+
+	// A round trip keeps the report
+	parsed, err = ParseSearchReport(data)
+	if err != nil {
+		t.Fatalf("ParseSearchReport: %v", err)
+	}
+	if len(parsed.Tested) != 1 || !reflect.DeepEqual(parsed.Tested, report.Tested) {
+		t.Errorf("Round trip changed the report: %+v", parsed.Tested)
+	}
+
+	newer := fmt.Sprintf(`{"version": %d, "tested": []}`, SearchReportVersion+1)
+	if _, err := ParseSearchReport([]byte(newer)); !errors.Is(err, ErrSearchReportVersion) {
+		t.Errorf("Expected ErrSearchReportVersion for a newer report, got %v", err)
+	}
+	if _, err := ParseSearchReport([]byte(`{"version": 0}`)); err == nil {
+		t.Error("Expected an error for version 0")
+	}
+}
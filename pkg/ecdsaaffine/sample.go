@@ -0,0 +1,163 @@
+package ecdsaaffine
+
+import (
+	"sort"
+	"time"
+)
+
+// SampleOptions configures SampleSignatures.
+type SampleOptions struct {
+	// Size is the number of signatures to select, besides those kept for a repeated r.
+	// Fewer are selected when some spans of a signer's time have no signatures.
+	Size int
+
+	// Run is the number of consecutive signatures of a signer taken at each sampled
+	// point (default: 2). Flawed signers relate nonces of neighbouring signatures, which
+	// a sample of isolated signatures would separate.
+	Run int
+}
+
+// Sample is a subset of a dataset selected by SampleSignatures.
+type Sample struct {
+	Signatures []*Signature
+	Indices    []int // position of each signature in the dataset, ascending
+	DuplicateR int   // signatures kept because another signature has the same r
+}
+
+// SampleSignatures selects a representative subset of a large dataset, so a quick
+// search can triage it before the full search is committed to. Every signature whose r
+// appears more than once is kept, even beyond opts.Size, as a repeated r gives the key
+// away. The rest of the sample is taken in runs of consecutive signatures, spread over
+// each signer's signatures in proportion to their number: by time when every signature
+// has a timestamp, else by dataset position. Within each stretch the run starts at the
+// signature whose r prefix the sample has least, so a signer whose nonces cluster in
+// a few values is not sampled from one cluster only.
+func SampleSignatures(signatures []*Signature, opts SampleOptions) *Sample {
+	run := opts.Run
+	if run < 1 {
+		run = 2
+	}
+	selected := make([]bool, len(signatures))
+	sample := &Sample{}
+
+	rCount := make(map[string]int)
+	for _, sig := range signatures {
+		rCount[string(sig.R.Bytes())]++
+	}
+	for i, sig := range signatures {
+		if rCount[string(sig.R.Bytes())] > 1 {
+			selected[i] = true
+			sample.DuplicateR++
+		}
+	}
+
+	timed := true
+	for _, sig := range signatures {
+		if sig.Timestamp.IsZero() {
+			timed = false
+			break
+		}
+	}
+	var prefixes [256]int // sampled signatures by the top byte of r
+	remaining := opts.Size
+	groups := GroupByPublicKey(signatures)
+	for k, group := range groups {
+		order := append([]int(nil), group.Indices...)
+		if timed {
+			sort.SliceStable(order, func(a, b int) bool {
+				return signatures[order[a]].Timestamp.Before(signatures[order[b]].Timestamp)
+			})
+		}
+		// The last group takes what rounding left, so the sizes add up to opts.Size
+		quota := opts.Size * len(order) / len(signatures)
+		if k == len(groups)-1 {
+			quota = remaining
+		}
+		quota = min(quota, remaining)
+		remaining -= sampleGroup(signatures, order, quota, run, timed, selected, &prefixes)
+	}
+
+	for i, ok := range selected {
+		if ok {
+			sample.Indices = append(sample.Indices, i)
+			sample.Signatures = append(sample.Signatures, signatures[i])
+		}
+	}
+	return sample
+}
+
+// sampleGroup selects up to quota signatures of order, a signer's signatures in time or
+// dataset order, in runs starting at evenly spread points, and returns how many it
+// selected.
+func sampleGroup(signatures []*Signature, order []int, quota, run int, timed bool, selected []bool, prefixes *[256]int) int {
+	take := func(i int) int {
+		if selected[i] {
+			return 0
+		}
+		selected[i] = true
+		if r := signatures[i].R.Bytes(); len(r) == 32 {
+			prefixes[r[0]]++
+		} else {
+			prefixes[0]++
+		}
+		return 1
+	}
+	taken := 0
+	if quota >= len(order) {
+		for _, i := range order {
+			taken += take(i)
+		}
+		return taken
+	}
+
+	points := (quota + run - 1) / run
+	for p := 0; p < points && taken < quota; p++ {
+		// The stretch of order this point covers: equal time spans, or equal counts
+		lo, hi := p*len(order)/points, (p+1)*len(order)/points
+		if timed {
+			first, last := signatures[order[0]].Timestamp, signatures[order[len(order)-1]].Timestamp
+			span := last.Sub(first)
+			start := first.Add(time.Duration(int64(span) / int64(points) * int64(p)))
+			end := first.Add(time.Duration(int64(span) / int64(points) * int64(p+1)))
+			lo = sort.Search(len(order), func(k int) bool { return !signatures[order[k]].Timestamp.Before(start) })
+			hi = sort.Search(len(order), func(k int) bool { return signatures[order[k]].Timestamp.After(end) })
+			if p == points-1 {
+				hi = len(order)
+			}
+		}
+		if lo >= hi {
+			continue // no signatures in this span of time
+		}
+
+		// Prefer the start whose r prefix is rarest in the sample, then the stretch's middle
+		best, bestCount, bestDistance := -1, 0, 0
+		middle := (lo + hi) / 2
+		for k := lo; k < hi; k++ {
+			i := order[k]
+			if selected[i] {
+				continue
+			}
+			prefix := 0
+			if r := signatures[i].R.Bytes(); len(r) == 32 {
+				prefix = int(r[0])
+			}
+			distance := k - middle
+			if distance < 0 {
+				distance = -distance
+			}
+			if best < 0 || prefixes[prefix] < bestCount || (prefixes[prefix] == bestCount && distance < bestDistance) {
+				best, bestCount, bestDistance = k, prefixes[prefix], distance
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		// A run steps over signatures already sampled for a repeated r
+		for k, n := best, 0; k < len(order) && n < run && taken < quota; k++ {
+			added := take(order[k])
+			n += added
+			taken += added
+		}
+	}
+	return taken
+}
@@ -0,0 +1,106 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// sampleTestSignatures returns count signatures with random z, r and s; sampling does
+// not check them.
+func sampleTestSignatures(random *rand.Rand, count int) []*Signature {
+	signatures := make([]*Signature, count)
+	for i := range signatures {
+		signatures[i] = &Signature{
+			Z: new(big.Int).Rand(random, Secp256k1CurveOrder),
+			R: new(big.Int).Rand(random, Secp256k1CurveOrder),
+			S: new(big.Int).Rand(random, Secp256k1CurveOrder),
+		}
+	}
+	return signatures
+}
+
+func TestSampleSignatures(t *testing.T) {
+	signatures := sampleTestSignatures(rand.New(rand.NewSource(1)), 200)
+	signatures[150].R = signatures[7].R
+
+	sample := SampleSignatures(signatures, SampleOptions{Size: 20})
+	if sample.DuplicateR != 2 || len(sample.Signatures) != 22 {
+		t.Fatalf("Expected 20 signatures and the repeated r pair, got %d with %d for repeated r", len(sample.Signatures), sample.DuplicateR)
+	}
+	has := make(map[int]bool)
+	for k, i := range sample.Indices {
+		if k > 0 && i <= sample.Indices[k-1] {
+			t.Fatalf("Indices are not ascending: %v", sample.Indices)
+		}
+		if sample.Signatures[k] != signatures[i] {
+			t.Errorf("Signature %d is not dataset signature %d", k, i)
+		}
+		has[i] = true
+	}
+	if !has[7] || !has[150] {
+		t.Error("Expected the repeated r pair to be kept")
+	}
+	// Runs of two neighbours, spread over the whole dataset
+	for _, i := range sample.Indices {
+		if i != 7 && i != 150 && !has[i-1] && !has[i+1] {
+			t.Errorf("Signature %d was sampled without a neighbour", i)
+		}
+	}
+	if sample.Indices[0] >= 20 || sample.Indices[len(sample.Indices)-1] < 180 {
+		t.Errorf("Expected the sample to span the dataset, got %v", sample.Indices)
+	}
+}
+
+func TestSampleSignatures_TimeSpread(t *testing.T) {
+	signatures := sampleTestSignatures(rand.New(rand.NewSource(2)), 200)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// A burst of 150 signatures in the first hour, then 50 over four days
+	for i, sig := range signatures {
+		if i < 150 {
+			sig.Timestamp = start.Add(time.Duration(i) * 20 * time.Second)
+		} else {
+			sig.Timestamp = start.Add(time.Hour + time.Duration(i-150)*2*time.Hour)
+		}
+	}
+
+	sample := SampleSignatures(signatures, SampleOptions{Size: 10})
+	late := 0
+	for _, i := range sample.Indices {
+		if i >= 150 {
+			late++
+		}
+	}
+	if len(sample.Indices) != 10 || late < 7 {
+		t.Errorf("Expected 10 signatures mostly after the burst, got %d with %d after it: %v", len(sample.Indices), late, sample.Indices)
+	}
+}
+
+func TestSampleSignatures_Signers(t *testing.T) {
+	signatures := sampleTestSignatures(rand.New(rand.NewSource(3)), 200)
+	keys := [][]byte{
+		secp256k1.PrivKeyFromBytes([]byte{1}).PubKey().SerializeCompressed(),
+		secp256k1.PrivKeyFromBytes([]byte{2}).PubKey().SerializeCompressed(),
+	}
+	for i, sig := range signatures {
+		sig.PublicKey = keys[min(i/150, 1)]
+	}
+
+	sample := SampleSignatures(signatures, SampleOptions{Size: 20, Run: 1})
+	second := 0
+	for _, sig := range sample.Signatures {
+		if string(sig.PublicKey) == string(keys[1]) {
+			second++
+		}
+	}
+	if len(sample.Signatures) != 20 || second != 5 {
+		t.Errorf("Expected 15 and 5 signatures from the two signers, got %d in all and %d from the second", len(sample.Signatures), second)
+	}
+
+	if all := SampleSignatures(signatures, SampleOptions{Size: 500}); len(all.Signatures) != 200 {
+		t.Errorf("Expected the whole dataset for a larger size, got %d", len(all.Signatures))
+	}
+}
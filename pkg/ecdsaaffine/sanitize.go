@@ -0,0 +1,119 @@
+package ecdsaaffine
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// SanitizeOptions selects the signer context Sanitize keeps. The zero value keeps z, r
+// and s only, which is all a search needs when each signer's dataset is shared on its
+// own.
+type SanitizeOptions struct {
+	PublicKeys  bool // keep Signature.PublicKey
+	RecoveryIDs bool // keep Signature.RecoveryID, from which anyone can recover the signer's key
+	Timestamps  bool // keep Signature.Timestamp
+	Sequences   bool // keep Signature.Sequence and BlockHeight, which MetadataHypotheses needs
+}
+
+// Sanitize returns copies of signatures that keep z, r and s and the context opts
+// selects, for datasets shared with third parties. Parsed signatures carry the hash z,
+// never the message, and fields the parser does not know were dropped while parsing, so
+// written out with WriteSignaturesJSON the copies reveal nothing else. Check the result
+// with ValidateSanitized.
+func Sanitize(signatures []*Signature, opts SanitizeOptions) []*Signature {
+	sanitized := make([]*Signature, len(signatures))
+	for i, sig := range signatures {
+		clean := &Signature{Z: new(big.Int).Set(sig.Z), R: new(big.Int).Set(sig.R), S: new(big.Int).Set(sig.S)}
+		if opts.PublicKeys {
+			clean.PublicKey = sig.PublicKey
+		}
+		if opts.RecoveryIDs {
+			clean.RecoveryID = sig.RecoveryID
+		}
+		if opts.Timestamps {
+			clean.Timestamp = sig.Timestamp
+		}
+		if opts.Sequences {
+			clean.Sequence, clean.BlockHeight = sig.Sequence, sig.BlockHeight
+		}
+		sanitized[i] = clean
+	}
+	return sanitized
+}
+
+// SanitizeReport is the result of ValidateSanitized.
+type SanitizeReport struct {
+	Signatures   int
+	Verified     int // signatures that verify under their signer's public key
+	Unverifiable int // signatures with no public key to verify against
+	Invalid      int // signatures that did not verify before sanitizing either
+
+	// Warnings describe what a search of the sanitized dataset can no longer do
+	Warnings []string
+}
+
+// ValidateSanitized checks that sanitized, as read back from wherever it was written,
+// still supports recovery as original does: the same z, r and s in the same order, each
+// signature verifying under its signer's key (publicKey, or the original signature's
+// PublicKey), and the same repeated r values. It fails if any of these broke, and warns
+// about what the dropped context costs: signers a search can no longer tell apart and
+// metadata hypotheses it can no longer try.
+func ValidateSanitized(original, sanitized []*Signature, publicKey []byte) (*SanitizeReport, error) {
+	if len(sanitized) != len(original) {
+		return nil, fmt.Errorf("sanitized dataset has %d signatures, original has %d", len(sanitized), len(original))
+	}
+	report := &SanitizeReport{Signatures: len(sanitized)}
+	metadata := false
+	for i, sig := range sanitized {
+		orig := original[i]
+		if sig.Z.Cmp(orig.Z) != 0 || sig.R.Cmp(orig.R) != 0 || sig.S.Cmp(orig.S) != 0 {
+			return nil, fmt.Errorf("signature %d: z, r or s changed", i)
+		}
+		if (orig.Sequence != nil && sig.Sequence == nil) || (orig.BlockHeight != nil && sig.BlockHeight == nil) {
+			metadata = true
+		}
+
+		// A key recovered from the recovery id always verifies, so only given keys count
+		key := publicKey
+		if key == nil {
+			key = orig.PublicKey
+		}
+		if len(key) == 0 {
+			report.Unverifiable++
+			continue
+		}
+		ok, err := VerifySignature(sig, key)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+		if ok {
+			report.Verified++
+			continue
+		}
+		if ok, _ := VerifySignature(orig, key); ok {
+			return nil, fmt.Errorf("signature %d no longer verifies under its signer's key", i)
+		}
+		report.Invalid++
+	}
+
+	before, after := AnalyzeDataset(original), AnalyzeDataset(sanitized)
+	if after.DuplicateR < before.DuplicateR {
+		return nil, fmt.Errorf("sanitized dataset has %d repeated r values, original has %d", after.DuplicateR, before.DuplicateR)
+	}
+	keyed := 0
+	for _, signer := range before.Signers {
+		if len(signer.PublicKey) > 0 {
+			keyed++
+		}
+	}
+	if keyed > 1 && len(after.Signers) < len(before.Signers) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the %d signers are no longer told apart: a search pairs signatures of different signers unless each signer's dataset is searched on its own", keyed))
+	}
+	if metadata {
+		report.Warnings = append(report.Warnings, "sequence numbers and block heights were dropped: the metadata hypotheses phase no longer runs")
+	}
+	if report.Invalid > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d signatures did not verify under their signer's key before sanitizing either", report.Invalid))
+	}
+	return report, nil
+}
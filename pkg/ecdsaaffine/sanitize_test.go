@@ -0,0 +1,88 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestSanitize(t *testing.T) {
+	signatures := contextTestSignatures(big.NewInt(0x5a417e), 6)
+	sanitized := Sanitize(signatures, SanitizeOptions{Timestamps: true})
+
+	var buf bytes.Buffer
+	if err := WriteSignaturesJSON(&buf, sanitized); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"public_key", "recovery_id", "sequence", "block_height"} {
+		if strings.Contains(buf.String(), field) {
+			t.Errorf("Expected %s to be dropped", field)
+		}
+	}
+	parser, _ := NewParser("json")
+	written, err := parser.(*JSONParser).Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written[0].Timestamp.IsZero() {
+		t.Error("Expected timestamps to be kept")
+	}
+
+	report, err := ValidateSanitized(signatures, written, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Even signatures carry the public key, so those verify
+	if report.Verified != 3 || report.Unverifiable != 3 || report.Invalid != 0 {
+		t.Errorf("Expected 3 verified and 3 unverifiable, got %+v", report)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "metadata hypotheses") {
+		t.Errorf("Expected a warning about the dropped sequences, got %q", report.Warnings)
+	}
+
+	// The sanitized dataset still yields the key
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), written, "")
+	if err != nil || result.PrivateKey.Cmp(big.NewInt(0x5a417e)) != 0 {
+		t.Errorf("Expected the key from the sanitized dataset, got %v, %v", result, err)
+	}
+}
+
+func TestValidateSanitized(t *testing.T) {
+	d := big.NewInt(0x5a417e)
+	signatures := storeTestSignatures(d, 4)
+	other := storeTestSignatures(big.NewInt(0x07e4), 2)
+	for _, sig := range signatures {
+		sig.PublicKey = secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	}
+	for _, sig := range other {
+		sig.PublicKey = secp256k1.PrivKeyFromBytes(big.NewInt(0x07e4).Bytes()).PubKey().SerializeCompressed()
+	}
+	dataset := append(signatures, other...)
+
+	report, err := ValidateSanitized(dataset, Sanitize(dataset, SanitizeOptions{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verified != 6 || len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "2 signers") {
+		t.Errorf("Expected 6 verified and a warning about merged signers, got %+v", report)
+	}
+
+	changed := Sanitize(dataset, SanitizeOptions{PublicKeys: true})
+	changed[2].Z.Add(changed[2].Z, big.NewInt(1))
+	if _, err := ValidateSanitized(dataset, changed, nil); err == nil || !strings.Contains(err.Error(), "signature 2") {
+		t.Errorf("Expected an error for the changed z, got %v", err)
+	}
+	if _, err := ValidateSanitized(dataset, changed[1:], nil); err == nil {
+		t.Error("Expected an error for a dropped signature")
+	}
+
+	// A dataset that did not verify to begin with is reported, not rejected
+	dataset[0].S.Add(dataset[0].S, big.NewInt(1))
+	if report, err := ValidateSanitized(dataset, Sanitize(dataset, SanitizeOptions{PublicKeys: true}), nil); err != nil || report.Invalid != 1 {
+		t.Errorf("Expected 1 invalid signature, got %+v, %v", report, err)
+	}
+}
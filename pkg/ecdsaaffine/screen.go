@@ -0,0 +1,95 @@
+package ecdsaaffine
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// fieldC is 2^256 mod p for the secp256k1 field prime p = 2^256 - 2^32 - 977.
+const fieldC = 0x1000003d1
+
+// fieldLimbs is a secp256k1 field element reduced below p, as four 64-bit words least
+// significant first: the form screenX takes its points in.
+type fieldLimbs [4]uint64
+
+// setField sets l to the normalized field value f.
+func (l *fieldLimbs) setField(f *secp256k1.FieldVal) {
+	var buf [32]byte
+	f.PutBytesUnchecked(buf[:])
+	for i := range l {
+		l[i] = binary.BigEndian.Uint64(buf[24-8*i:])
+	}
+}
+
+// screenX sets match[i], for every i < len(match), to whether the Jacobian point with
+// coordinates X = xs[i] and Z = zs[i] has the affine x coordinate x, that is whether
+// xs[i] = x·zs[i]^2 mod p. It is the keyStepper's congruence check on a window of
+// candidates; on amd64 and arm64 it runs in assembly unless built with the purego tag,
+// and screenXGeneric is the portable version.
+func screenX(x *fieldLimbs, xs, zs []fieldLimbs, match []bool) {
+	if len(match) == 0 {
+		return
+	}
+	_, _ = xs[len(match)-1], zs[len(match)-1]
+	screenXNative(x, xs, zs, match)
+}
+
+// screenXGeneric is screenX on math/bits.
+func screenXGeneric(x *fieldLimbs, xs, zs []fieldLimbs, match []bool) {
+	for i := range match {
+		z2 := mulModP(&zs[i], &zs[i])
+		match[i] = mulModP(x, &z2) == xs[i]
+	}
+}
+
+// mulModP returns a·b mod p, reduced below p.
+func mulModP(a, b *fieldLimbs) fieldLimbs {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var c uint64
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j], c = bits.Add64(t[i+j], lo, 0)
+			carry = hi + c
+		}
+		t[i+4] = carry
+	}
+
+	// 2^256 = fieldC mod p, so the high half folds into the low half times fieldC
+	var r fieldLimbs
+	var carry, c uint64
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(t[i+4], fieldC)
+		lo, c = bits.Add64(lo, carry, 0)
+		hi += c
+		r[i], c = bits.Add64(t[i], lo, 0)
+		carry = hi + c
+	}
+	// The carry is below 2^34; folding it can wrap past 2^256 once more, leaving a
+	// value small enough that a second fold cannot
+	hi, lo := bits.Mul64(carry, fieldC)
+	r[0], c = bits.Add64(r[0], lo, 0)
+	r[1], c = bits.Add64(r[1], hi, c)
+	r[2], c = bits.Add64(r[2], 0, c)
+	r[3], c = bits.Add64(r[3], 0, c)
+	r[0], c = bits.Add64(r[0], fieldC*c, 0)
+	r[1], c = bits.Add64(r[1], 0, c)
+	r[2], c = bits.Add64(r[2], 0, c)
+	r[3], _ = bits.Add64(r[3], 0, c)
+
+	// r < 2^256 < 2p, and r >= p exactly when r + fieldC carries out
+	var s fieldLimbs
+	s[0], c = bits.Add64(r[0], fieldC, 0)
+	s[1], c = bits.Add64(r[1], 0, c)
+	s[2], c = bits.Add64(r[2], 0, c)
+	s[3], c = bits.Add64(r[3], 0, c)
+	if c != 0 {
+		return s
+	}
+	return r
+}
@@ -0,0 +1,89 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// MULROW0 sets R8-R12 to a·b[0], for a at (SI) and b at (DI).
+#define MULROW0 \
+	MOVQ 0(DI), CX; \
+	MOVQ 0(SI), AX; MULQ CX; MOVQ AX, R8; MOVQ DX, R9; \
+	MOVQ 8(SI), AX; MULQ CX; ADDQ AX, R9; ADCQ $0, DX; MOVQ DX, R10; \
+	MOVQ 16(SI), AX; MULQ CX; ADDQ AX, R10; ADCQ $0, DX; MOVQ DX, R11; \
+	MOVQ 24(SI), AX; MULQ CX; ADDQ AX, R11; ADCQ $0, DX; MOVQ DX, R12
+
+// MULROW adds a·b[i] (b[i] at off(DI)) to t0-t3 and sets t4 to the carry.
+#define MULROW(off, t0, t1, t2, t3, t4) \
+	MOVQ off(DI), CX; \
+	MOVQ 0(SI), AX; MULQ CX; ADDQ AX, t0; ADCQ $0, DX; MOVQ DX, BX; \
+	MOVQ 8(SI), AX; MULQ CX; ADDQ BX, AX; ADCQ $0, DX; ADDQ AX, t1; ADCQ $0, DX; MOVQ DX, BX; \
+	MOVQ 16(SI), AX; MULQ CX; ADDQ BX, AX; ADCQ $0, DX; ADDQ AX, t2; ADCQ $0, DX; MOVQ DX, BX; \
+	MOVQ 24(SI), AX; MULQ CX; ADDQ BX, AX; ADCQ $0, DX; ADDQ AX, t3; ADCQ $0, DX; MOVQ DX, t4
+
+// MULMODP sets R8-R11 to a·b mod p, reduced below p, for a at (SI) and b at (DI), as
+// mulModP does. It clobbers AX, BX, CX, DX and R12-R15.
+#define MULMODP \
+	MULROW0; \
+	MULROW(8, R9, R10, R11, R12, R13); \
+	MULROW(16, R10, R11, R12, R13, R14); \
+	MULROW(24, R11, R12, R13, R14, R15); \
+	MOVQ $0x1000003d1, CX; \
+	MOVQ R12, AX; MULQ CX; ADDQ AX, R8; ADCQ $0, DX; MOVQ DX, BX; \
+	MOVQ R13, AX; MULQ CX; ADDQ BX, AX; ADCQ $0, DX; ADDQ AX, R9; ADCQ $0, DX; MOVQ DX, BX; \
+	MOVQ R14, AX; MULQ CX; ADDQ BX, AX; ADCQ $0, DX; ADDQ AX, R10; ADCQ $0, DX; MOVQ DX, BX; \
+	MOVQ R15, AX; MULQ CX; ADDQ BX, AX; ADCQ $0, DX; ADDQ AX, R11; ADCQ $0, DX; \
+	MOVQ DX, AX; MULQ CX; ADDQ AX, R8; ADCQ DX, R9; ADCQ $0, R10; ADCQ $0, R11; \
+	SBBQ BX, BX; ANDQ CX, BX; ADDQ BX, R8; ADCQ $0, R9; ADCQ $0, R10; ADCQ $0, R11; \
+	MOVQ R8, R12; MOVQ R9, R13; MOVQ R10, R14; MOVQ R11, R15; \
+	ADDQ CX, R12; ADCQ $0, R13; ADCQ $0, R14; ADCQ $0, R15; \
+	CMOVQCS R12, R8; CMOVQCS R13, R9; CMOVQCS R14, R10; CMOVQCS R15, R11
+
+// func screenXAsm(x, xs, zs *fieldLimbs, match *bool, n int)
+//
+// The frame holds z^2 at 0(SP) and the zs, xs and match pointers and the count left at
+// 32(SP) to 56(SP), since the multiplication uses every other general register.
+TEXT ·screenXAsm(SB), NOSPLIT, $64-40
+	MOVQ n+32(FP), AX
+	TESTQ AX, AX
+	JZ done
+	MOVQ AX, 56(SP)
+	MOVQ zs+16(FP), AX
+	MOVQ AX, 32(SP)
+	MOVQ xs+8(FP), AX
+	MOVQ AX, 40(SP)
+	MOVQ match+24(FP), AX
+	MOVQ AX, 48(SP)
+
+loop:
+	// z^2
+	MOVQ 32(SP), SI
+	MOVQ SI, DI
+	MULMODP
+	MOVQ R8, 0(SP)
+	MOVQ R9, 8(SP)
+	MOVQ R10, 16(SP)
+	MOVQ R11, 24(SP)
+
+	// x·z^2
+	LEAQ 0(SP), SI
+	MOVQ x+0(FP), DI
+	MULMODP
+
+	// Both sides are reduced below p, so they are congruent exactly when equal
+	MOVQ 40(SP), SI
+	XORQ 0(SI), R8
+	XORQ 8(SI), R9
+	XORQ 16(SI), R10
+	XORQ 24(SI), R11
+	ORQ R9, R8
+	ORQ R11, R10
+	ORQ R10, R8
+	MOVQ 48(SP), DI
+	SETEQ (DI)
+
+	ADDQ $32, 32(SP)
+	ADDQ $32, 40(SP)
+	INCQ 48(SP)
+	DECQ 56(SP)
+	JNZ loop
+
+done:
+	RET
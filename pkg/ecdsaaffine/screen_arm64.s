@@ -0,0 +1,89 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// MULROW0 sets R5-R9 to a·b0, for a in R0-R3.
+#define MULROW0(b0) \
+	MUL b0, R0, R5; UMULH b0, R0, R15; \
+	MUL b0, R1, R13; UMULH b0, R1, R14; ADDS R15, R13, R6; ADC ZR, R14, R15; \
+	MUL b0, R2, R13; UMULH b0, R2, R14; ADDS R15, R13, R7; ADC ZR, R14, R15; \
+	MUL b0, R3, R13; UMULH b0, R3, R14; ADDS R15, R13, R8; ADC ZR, R14, R9
+
+// MULROW adds a·bi to t0-t3 and sets t4 to the carry, for a in R0-R3.
+#define MULROW(bi, t0, t1, t2, t3, t4) \
+	MUL bi, R0, R13; UMULH bi, R0, R14; ADDS R13, t0, t0; ADC ZR, R14, R15; \
+	MUL bi, R1, R13; UMULH bi, R1, R14; ADDS R15, R13, R13; ADC ZR, R14, R14; ADDS R13, t1, t1; ADC ZR, R14, R15; \
+	MUL bi, R2, R13; UMULH bi, R2, R14; ADDS R15, R13, R13; ADC ZR, R14, R14; ADDS R13, t2, t2; ADC ZR, R14, R15; \
+	MUL bi, R3, R13; UMULH bi, R3, R14; ADDS R15, R13, R13; ADC ZR, R14, R14; ADDS R13, t3, t3; ADC ZR, R14, t4
+
+// FOLD adds hi·fieldC (fieldC in R16) plus the carry in R15 to lo and sets R15 to the
+// carry out.
+#define FOLD(hi, lo) \
+	MUL R16, hi, R13; UMULH R16, hi, R14; ADDS R15, R13, R13; ADC ZR, R14, R14; ADDS R13, lo, lo; ADC ZR, R14, R15
+
+// MULMODP sets R5-R8 to a·b mod p, reduced below p, for a in R0-R3 and b in b0-b3, as
+// mulModP does. fieldC must be in R16; it clobbers R9-R15.
+#define MULMODP(b0, b1, b2, b3) \
+	MULROW0(b0); \
+	MULROW(b1, R6, R7, R8, R9, R10); \
+	MULROW(b2, R7, R8, R9, R10, R11); \
+	MULROW(b3, R8, R9, R10, R11, R12); \
+	MOVD ZR, R15; \
+	FOLD(R9, R5); \
+	FOLD(R10, R6); \
+	FOLD(R11, R7); \
+	FOLD(R12, R8); \
+	MUL R16, R15, R13; UMULH R16, R15, R14; \
+	ADDS R13, R5, R5; ADCS R14, R6, R6; ADCS ZR, R7, R7; ADCS ZR, R8, R8; \
+	CSEL CS, R16, ZR, R13; \
+	ADDS R13, R5, R5; ADCS ZR, R6, R6; ADCS ZR, R7, R7; ADC ZR, R8, R8; \
+	ADDS R16, R5, R9; ADCS ZR, R6, R10; ADCS ZR, R7, R11; ADCS ZR, R8, R12; \
+	CSEL CS, R9, R5, R5; CSEL CS, R10, R6, R6; CSEL CS, R11, R7, R7; CSEL CS, R12, R8, R8
+
+// func screenXAsm(x, xs, zs *fieldLimbs, match *bool, n int)
+TEXT ·screenXAsm(SB), NOSPLIT, $0-40
+	MOVD x+0(FP), R4
+	MOVD xs+8(FP), R23
+	MOVD zs+16(FP), R24
+	MOVD match+24(FP), R25
+	MOVD n+32(FP), R17
+	CBZ R17, done
+	LDP (R4), (R19, R20)
+	LDP 16(R4), (R21, R22)
+	MOVD $0x1000003d1, R16
+
+loop:
+	// z^2
+	LDP (R24), (R0, R1)
+	LDP 16(R24), (R2, R3)
+	MULMODP(R0, R1, R2, R3)
+
+	// x·z^2
+	MOVD R5, R0
+	MOVD R6, R1
+	MOVD R7, R2
+	MOVD R8, R3
+	MULMODP(R19, R20, R21, R22)
+
+	// Both sides are reduced below p, so they are congruent exactly when equal
+	LDP (R23), (R13, R14)
+	EOR R13, R5, R5
+	EOR R14, R6, R6
+	LDP 16(R23), (R13, R14)
+	EOR R13, R7, R7
+	EOR R14, R8, R8
+	ORR R6, R5, R5
+	ORR R8, R7, R7
+	ORR R7, R5, R5
+	CMP $0, R5
+	CSET EQ, R13
+	MOVB R13, (R25)
+
+	ADD $32, R23
+	ADD $32, R24
+	ADD $1, R25
+	SUB $1, R17
+	CBNZ R17, loop
+
+done:
+	RET
@@ -0,0 +1,11 @@
+//go:build (amd64 || arm64) && !purego
+
+package ecdsaaffine
+
+// screenXNative is screenX in assembly, for n = len(match) > 0 candidates.
+func screenXNative(x *fieldLimbs, xs, zs []fieldLimbs, match []bool) {
+	screenXAsm(x, &xs[0], &zs[0], &match[0], len(match))
+}
+
+//go:noescape
+func screenXAsm(x, xs, zs *fieldLimbs, match *bool, n int)
@@ -0,0 +1,7 @@
+//go:build (!amd64 && !arm64) || purego
+
+package ecdsaaffine
+
+func screenXNative(x *fieldLimbs, xs, zs []fieldLimbs, match []bool) {
+	screenXGeneric(x, xs, zs, match)
+}
@@ -0,0 +1,95 @@
+package ecdsaaffine
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// screenTestValues returns field elements that exercise the reductions: small values,
+// values just below p and random ones.
+func screenTestValues(random *rand.Rand, n int) []*big.Int {
+	p := secp256k1.Params().P
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(fieldC)}
+	for _, d := range []int64{1, 2, fieldC} {
+		values = append(values, new(big.Int).Sub(p, big.NewInt(d)))
+	}
+	values = append(values, new(big.Int).Lsh(big.NewInt(1), 255), new(big.Int).Lsh(big.NewInt(1), 128))
+	for len(values) < n {
+		values = append(values, new(big.Int).Rand(random, p))
+	}
+	return values
+}
+
+func limbsOf(v *big.Int) fieldLimbs {
+	var f secp256k1.FieldVal
+	f.SetByteSlice(v.Bytes())
+	var l fieldLimbs
+	l.setField(&f)
+	return l
+}
+
+func TestMulModP(t *testing.T) {
+	p := secp256k1.Params().P
+	values := screenTestValues(rand.New(rand.NewSource(1)), 64)
+	for _, a := range values {
+		for _, b := range values {
+			al, bl := limbsOf(a), limbsOf(b)
+			want := limbsOf(new(big.Int).Mod(new(big.Int).Mul(a, b), p))
+			if got := mulModP(&al, &bl); got != want {
+				t.Fatalf("mulModP(%x, %x) = %x, want %x", a, b, got, want)
+			}
+		}
+	}
+}
+
+// TestScreenX checks screenX, in assembly where the platform has it, against
+// screenXGeneric on points that match the target and on ones that do not.
+func TestScreenX(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	var target secp256k1.JacobianPoint
+	var scalar secp256k1.ModNScalar
+	scalar.SetInt(0xdeadbeef)
+	secp256k1.ScalarBaseMultNonConst(&scalar, &target)
+	target.ToAffine()
+	var x fieldLimbs
+	x.setField(&target.X)
+
+	values := screenTestValues(random, 200)
+	var xs, zs []fieldLimbs
+	for i, z := range values {
+		zs = append(zs, limbsOf(z))
+		if i%3 == 0 {
+			// X = x·Z^2, the Jacobian form of a point on the target's x
+			var zf, xf secp256k1.FieldVal
+			zf.SetByteSlice(z.Bytes())
+			xf.SquareVal(&zf).Mul(&target.X).Normalize()
+			var l fieldLimbs
+			l.setField(&xf)
+			xs = append(xs, l)
+		} else {
+			xs = append(xs, limbsOf(values[random.Intn(len(values))]))
+		}
+	}
+
+	for _, n := range []int{0, 1, 7, len(zs)} {
+		got, want := make([]bool, n), make([]bool, n)
+		screenX(&x, xs, zs, got)
+		screenXGeneric(&x, xs, zs, want)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("n=%d: screenX[%d] = %v, screenXGeneric = %v", n, i, got[i], want[i])
+			}
+		}
+	}
+
+	match := make([]bool, len(zs))
+	screenXGeneric(&x, xs, zs, match)
+	for i, m := range match {
+		if m != (i%3 == 0) {
+			t.Errorf("screenXGeneric[%d] = %v, want %v", i, m, i%3 == 0)
+		}
+	}
+}
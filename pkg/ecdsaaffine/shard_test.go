@@ -0,0 +1,274 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/metrics"
+)
+
+func TestRangeSpace_Deal(t *testing.T) {
+	space := newRangeSpace([2]int{-2, 3}, [2]int{-10, 10}, true)
+	if space.aValues[0] != 1 {
+		t.Errorf("Expected a=1 first, got %v", space.aValues)
+	}
+	if space.size() != 5*21 {
+		t.Fatalf("Expected %d combinations, got %d", 5*21, space.size())
+	}
+
+	for _, tc := range []struct {
+		n     int
+		chunk int64
+	}{{1, 1024}, {3, 4}, {7, 2}, {8, 1024}, {1000, 1024}} {
+		seen := make(map[[2]int64]bool)
+		deal := space.deal(tc.n, tc.chunk)
+		if deal.workers > tc.n || deal.chunk > tc.chunk {
+			t.Fatalf("n=%d chunk=%d: dealt %d workers chunks of %d", tc.n, tc.chunk, deal.workers, deal.chunk)
+		}
+		for w := 0; w < deal.workers; w++ {
+			// Every worker starts within the first round of chunks, so on a=1 while the
+			// round fits in it
+			round := int64(deal.workers) * deal.chunk
+			if start, _, ok := deal.at(w, 0); !ok || start >= round {
+				t.Fatalf("n=%d: worker %d starts at %d", tc.n, w, start)
+			} else if a, _ := space.at(start); a != 1 && round <= space.bCount {
+				t.Errorf("n=%d: worker %d starts on a=%d, expected a=1", tc.n, w, a)
+			}
+			last := int64(-1)
+			for k := 0; ; k++ {
+				start, end, ok := deal.at(w, k)
+				if !ok {
+					break
+				}
+				if start <= last {
+					t.Fatalf("n=%d: worker %d chunks out of order", tc.n, w)
+				}
+				for idx := start; idx < end; idx++ {
+					a, b := space.at(idx)
+					if a == 0 {
+						t.Fatalf("n=%d: a=0 not skipped", tc.n)
+					}
+					if seen[[2]int64{a, b}] {
+						t.Fatalf("n=%d: (%d, %d) dealt twice", tc.n, a, b)
+					}
+					seen[[2]int64{a, b}] = true
+				}
+				last = start
+			}
+		}
+		if int64(len(seen)) != space.size() {
+			t.Errorf("n=%d: chunks cover %d distinct combinations, expected %d", tc.n, len(seen), space.size())
+		}
+	}
+}
+
+func TestPairAt(t *testing.T) {
+	for _, n := range []int{2, 3, 7} {
+		p := 0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				if gi, gj := pairAt(p, n); gi != i || gj != j {
+					t.Errorf("pairAt(%d, %d) = (%d, %d), want (%d, %d)", p, n, gi, gj, i, j)
+				}
+				p++
+			}
+		}
+	}
+}
+
+// TestSmartBruteForceStrategy_TryPattern_Parallel tries k2 = k1 + 1 on enough pairs to
+// use parallel workers. Pairs (5, 9) and (140, 141) both match; deterministic mode must
+// report the earlier one, as the sequential search does.
+func TestSmartBruteForceStrategy_TryPattern_Parallel(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := make([]*Signature, 142)
+	for i := range signatures {
+		k := int64(1000 * (i + 1))
+		switch i {
+		case 9:
+			k = 6001
+		case 141:
+			k = 141001
+		}
+		signatures[i] = signWithNonce(d, big.NewInt(k), HashMessage([]byte(fmt.Sprintf("message %d", i))))
+	}
+	if pairs := len(signatures) * (len(signatures) - 1) / 2; pairs < patternParallelThreshold {
+		t.Fatalf("%d pairs do not reach the parallel threshold", pairs)
+	}
+	one := big.NewInt(1)
+
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.Deterministic = true
+	for _, workers := range []int{1, 3, 8} {
+		strategy.RangeConfig.NumWorkers = workers
+		result := strategy.tryPattern(context.Background(), signatures, publicKey, one, one, "counter_+1")
+		if result == nil || result.PrivateKey.Cmp(d) != 0 || result.SignaturePair != [2]int{5, 9} {
+			t.Errorf("Expected the key from pair (5, 9) with %d workers, got %+v", workers, result)
+		}
+	}
+
+	strategy.RangeConfig.Deterministic = false
+	strategy.Metrics = metrics.NewSearch("secp256k1")
+	result := strategy.tryPattern(context.Background(), signatures, publicKey, one, one, "counter_+1")
+	if result == nil || (result.SignaturePair != [2]int{5, 9} && result.SignaturePair != [2]int{140, 141}) {
+		t.Errorf("Expected the key from a matching pair, got %+v", result)
+	}
+	if candidates := strategy.Metrics.Snapshot().Candidates; candidates == 0 {
+		t.Error("Expected the pattern's candidates in the metrics")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := strategy.tryPattern(ctx, signatures, publicKey, one, one, "counter_+1"); result != nil {
+		t.Error("Expected no result from a cancelled pattern")
+	}
+}
+
+func TestSmartBruteForceStrategy_RangeSearch(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2 := new(big.Int).Mul(k1, big.NewInt(3))
+	k2.Add(k2, big.NewInt(-57))
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.SkipZeroA = true
+
+	for _, workers := range []int{1, 3, 8} {
+		result, _ := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{-4, 4}, [2]int{-100, 100}, 10, workers)
+		if result == nil {
+			t.Fatalf("Expected recovery with %d workers", workers)
+		}
+		if result.PrivateKey.Cmp(d) != 0 || result.Relationship.A.Int64() != 3 || result.Relationship.B.Int64() != -57 {
+			t.Errorf("Unexpected result with %d workers: a=%s b=%s", workers, result.Relationship.A, result.Relationship.B)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result, _ := strategy.rangeSearch(ctx, signatures, publicKey, [2]int{-4, 4}, [2]int{-100, 100}, 10, 4); result != nil {
+		t.Error("Expected no result from a cancelled search")
+	}
+}
+
+func TestSmartBruteForceStrategy_RangeSearch_NoPublicKey(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	k2 := new(big.Int).Mul(k1, big.NewInt(3))
+	k2.Add(k2, big.NewInt(-57))
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.SkipZeroA = true
+
+	// The key that reproduces both r values is reported, unverified
+	result, _ := strategy.rangeSearch(context.Background(), signatures, nil, [2]int{-4, 4}, [2]int{-100, 100}, 10, 4)
+	if result == nil {
+		t.Fatal("Expected recovery without a public key")
+	}
+	if result.PrivateKey.Cmp(d) != 0 || result.Verified || result.Relationship.A.Int64() != 3 || result.Relationship.B.Int64() != -57 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+// TestSmartBruteForceStrategy_RangeSearch_Deterministic uses nonces 10 and 50, which
+// satisfy k2 = a*k1 + b for several (a, b) in range, so unordered workers may report any
+// of them. Deterministic mode must always report a=1, b=40, the first in enumeration order.
+func TestSmartBruteForceStrategy_RangeSearch_Deterministic(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(10), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(50), HashMessage([]byte("message 2"))),
+	}
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.SkipZeroA = true
+	strategy.RangeConfig.Deterministic = true
+
+	for _, workers := range []int{1, 2, 3, 8, 8, 8} {
+		result, _ := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{-5, 5}, [2]int{-50, 50}, 10, workers)
+		if result == nil {
+			t.Fatalf("Expected recovery with %d workers", workers)
+		}
+		if result.Relationship.A.Int64() != 1 || result.Relationship.B.Int64() != 40 {
+			t.Errorf("Expected a=1 b=40 with %d workers, got a=%s b=%s", workers, result.Relationship.A, result.Relationship.B)
+		}
+	}
+}
+
+func TestSmartBruteForceStrategy_RangeSearch_Metrics(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(1000001), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(7777777), HashMessage([]byte("message 2"))),
+		signWithNonce(d, big.NewInt(3333333), HashMessage([]byte("message 3"))),
+	}
+	strategy := NewSmartBruteForceStrategy()
+	strategy.Metrics = metrics.NewSearch("secp256k1")
+
+	_, tested := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{1, 2}, [2]int{-50, 50}, 10, 3)
+	var out strings.Builder
+	strategy.Metrics.WriteTo(&out)
+	for _, want := range []string{
+		fmt.Sprintf(`affine_candidates_tested_total{curve="secp256k1"} %d`, tested),
+		`affine_pairs_tested_total{curve="secp256k1"} 3`,
+		`affine_workers{curve="secp256k1"} 0`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Metrics missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// TestSmartBruteForceStrategy_RangeSearch_NoLeaks runs searches that end by finding the key
+// (with several workers racing to report it) and by cancellation, and checks that every
+// worker and ticker goroutine has exited. Run with -race.
+func TestSmartBruteForceStrategy_RangeSearch_NoLeaks(t *testing.T) {
+	signatures, publicKey := leakTestSignatures()
+	strategy := NewSmartBruteForceStrategy()
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 5; i++ {
+		if result, _ := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{1, 4}, [2]int{-100, 100}, 10, 8); result == nil {
+			t.Fatal("Expected recovery")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		strategy.rangeSearch(ctx, signatures[:1:1], publicKey, [2]int{1, 4}, [2]int{-100, 100}, 10, 8)
+		strategy.rangeSearch(ctx, signatures, nil, [2]int{1, 1}, [2]int{0, 1 << 30}, 10, 8)
+		cancel()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Leaked %d goroutines", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// leakTestSignatures returns a pair with k2 = 2*k1 + 7 and the matching public key.
+func leakTestSignatures() ([]*Signature, []byte) {
+	d := big.NewInt(0xdeadbeef)
+	k1 := big.NewInt(1000001)
+	k2 := new(big.Int).Add(new(big.Int).Mul(k1, big.NewInt(2)), big.NewInt(7))
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, k2, HashMessage([]byte("message 2"))),
+	}
+	return signatures, secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+}
@@ -1,6 +1,9 @@
 package ecdsaaffine
 
-import "math/big"
+import (
+	"math/big"
+	"time"
+)
 
 // Signature represents an ECDSA signature with message hash.
 // This is the core type used throughout the package.
@@ -8,6 +11,14 @@ type Signature struct {
 	Z *big.Int // Message hash (SHA-256 of message, mod n)
 	R *big.Int // r component of the signature
 	S *big.Int // s component of the signature
+
+	// Optional signer context, set by parsers when the dataset has it
+	PublicKey   []byte    // Signer public key in any ParsePublicKey encoding, or an Ethereum address
+	RecoveryID  *int      // Public key recovery id 0-3 (from Ethereum v); nil if unknown
+	Timestamp   time.Time // When the signature was made (e.g. block time); zero if unknown
+	Sequence    *int64    // Position in the signer's sequence (e.g. account nonce); nil if unknown
+	BlockHeight *int64    // Block the signature was included in; nil if unknown
+	Message     []byte    // Signed message, if the dataset has it (Z is its hash); nil if unknown
 }
 
 // AffineRelationship represents the relationship between two nonces.
@@ -21,8 +32,10 @@ type AffineRelationship struct {
 type RecoveryResult struct {
 	PrivateKey    *big.Int           // Recovered private key
 	Relationship  AffineRelationship // The affine relationship found (k2 = a*k1 + b)
-	SignaturePair [2]int             // Indices of the signature pair used
+	SignaturePair [2]int             // Indices of the signature pair used, k1's first (reversed if found by RangeConfig.BothDirections)
 	Verified      bool                // Whether the key was verified against a public key
 	Pattern       string              // Human-readable pattern description
+	Corroborating int                 // Other signatures by the signer whose r the key reproduces (see WithCrossCheck)
+	CrossChecked  int                 // Other signatures the cross-check looked at (0 if it did not run)
 }
 
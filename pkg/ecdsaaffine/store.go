@@ -0,0 +1,520 @@
+package ecdsaaffine
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/tablefile"
+)
+
+// Signature store file layout (all integers big-endian):
+//
+//	magic      8 bytes  "ECDSASIG"
+//	version    4 bytes  1, or 2 for records with the signer context
+//	count      8 bytes
+//	public key 33 bytes compressed, all zero if unknown
+//	records    count * 96 bytes: z || r || s, 32 bytes each
+//
+// A version 2 record follows z || r || s with the optional Signature fields, 156 bytes
+// in all:
+//
+//	flags       1 byte   which of the fields below are set (storeHas*)
+//	recovery id 1 byte
+//	timestamp   8 bytes  Unix nanoseconds
+//	sequence    8 bytes
+//	block       8 bytes
+//	public key  1 byte length, then 33 bytes: a compressed key or an address, zero padded
+//
+// A version 3 record follows those with the signed message, 168 bytes in all:
+//
+//	message offset 8 bytes  into the message section
+//	message length 4 bytes
+//
+// and the messages follow the last record back to back, in the message section.
+const (
+	storeMagic      = "ECDSASIG"
+	storeVersion    = 1
+	storeHeaderSize = 8 + 4 + 8 + 33
+	storeRecordSize = 96
+
+	storeContextVersion    = 2
+	storeContextRecordSize = storeRecordSize + 1 + 1 + 8 + 8 + 8 + 1 + 33
+
+	storeMessageVersion    = 3
+	storeMessageRecordSize = storeContextRecordSize + 8 + 4
+)
+
+// Flags of a version 2 record.
+const (
+	storeHasRecoveryID = 1 << iota
+	storeHasTimestamp
+	storeHasSequence
+	storeHasBlockHeight
+	storeHasPublicKey
+	storeHasMessage
+)
+
+// SignatureStore is a memory-mapped file of fixed-size signature records.
+// Signatures are decoded on access, so a store with millions of signatures costs
+// no heap beyond the records actually in use.
+type SignatureStore struct {
+	// PublicKey is the compressed public key recorded in the store, or nil
+	PublicKey []byte
+
+	data       []byte // records only
+	messages   []byte // message section (version 3)
+	count      int
+	recordSize int
+	release    func() error
+}
+
+// OpenSignatureStore maps a store written by SignatureStoreWriter or WriteSignatureStore.
+func OpenSignatureStore(path string) (*SignatureStore, error) {
+	data, release, err := tablefile.MapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < storeHeaderSize || string(data[:8]) != storeMagic {
+		release()
+		return nil, fmt.Errorf("%s: not a signature store", path)
+	}
+	recordSize := storeRecordSize
+	switch v := binary.BigEndian.Uint32(data[8:]); v {
+	case storeVersion:
+	case storeContextVersion:
+		recordSize = storeContextRecordSize
+	case storeMessageVersion:
+		recordSize = storeMessageRecordSize
+	default:
+		release()
+		return nil, fmt.Errorf("%s: unsupported signature store version %d", path, v)
+	}
+	count := binary.BigEndian.Uint64(data[12:])
+	records := data[storeHeaderSize:]
+	// Compare without multiplying first: count*recordSize can wrap around to the file size
+	size := uint64(len(records))
+	if count > size/uint64(recordSize) || recordSize != storeMessageRecordSize && size != count*uint64(recordSize) {
+		release()
+		return nil, fmt.Errorf("%s: truncated signature store: header says %d signatures", path, count)
+	}
+
+	end := int(count) * recordSize
+	store := &SignatureStore{data: records[:end], messages: records[end:], count: int(count), recordSize: recordSize, release: release}
+	if pub := data[20:storeHeaderSize]; pub[0] != 0 {
+		store.PublicKey = append([]byte(nil), pub...)
+	}
+	return store, nil
+}
+
+// Len returns the number of signatures.
+func (s *SignatureStore) Len() int {
+	return s.count
+}
+
+// HasSignerContext reports whether the store records the signer context of each
+// signature (public key, recovery id, timestamp, sequence and block height, and since
+// version 3 the message), as written with WithStoreSignerContext.
+func (s *SignatureStore) HasSignerContext() bool {
+	return s.recordSize != storeRecordSize
+}
+
+// Signature decodes the i-th signature.
+func (s *SignatureStore) Signature(i int) *Signature {
+	rec := s.data[i*s.recordSize : (i+1)*s.recordSize]
+	sig := &Signature{
+		Z: new(big.Int).SetBytes(rec[0:32]),
+		R: new(big.Int).SetBytes(rec[32:64]),
+		S: new(big.Int).SetBytes(rec[64:96]),
+	}
+	if s.recordSize != storeRecordSize {
+		decodeSignerContext(rec[storeRecordSize:], sig)
+	}
+	if s.recordSize == storeMessageRecordSize && rec[storeRecordSize]&storeHasMessage != 0 {
+		offset := binary.BigEndian.Uint64(rec[storeContextRecordSize:])
+		length := uint64(binary.BigEndian.Uint32(rec[storeContextRecordSize+8:]))
+		// A message outside the section (a corrupt record) is left unknown
+		if offset <= uint64(len(s.messages)) && length <= uint64(len(s.messages))-offset {
+			sig.Message = append([]byte{}, s.messages[offset:offset+length]...)
+		}
+	}
+	return sig
+}
+
+// decodeSignerContext sets the optional fields of sig from the tail of a version 2 record.
+func decodeSignerContext(rec []byte, sig *Signature) {
+	flags := rec[0]
+	if flags&storeHasRecoveryID != 0 {
+		id := int(rec[1])
+		sig.RecoveryID = &id
+	}
+	if flags&storeHasTimestamp != 0 {
+		sig.Timestamp = time.Unix(0, int64(binary.BigEndian.Uint64(rec[2:]))).UTC()
+	}
+	if flags&storeHasSequence != 0 {
+		n := int64(binary.BigEndian.Uint64(rec[10:]))
+		sig.Sequence = &n
+	}
+	if flags&storeHasBlockHeight != 0 {
+		n := int64(binary.BigEndian.Uint64(rec[18:]))
+		sig.BlockHeight = &n
+	}
+	if flags&storeHasPublicKey != 0 {
+		sig.PublicKey = append([]byte(nil), rec[27:27+int(rec[26])]...)
+	}
+}
+
+// encodeSignerContext writes the optional fields of sig to the tail of a version 2 record.
+func encodeSignerContext(rec []byte, sig *Signature) error {
+	var flags byte
+	if sig.RecoveryID != nil {
+		if *sig.RecoveryID < 0 || *sig.RecoveryID > 3 {
+			return fmt.Errorf("recovery id %d out of range", *sig.RecoveryID)
+		}
+		flags |= storeHasRecoveryID
+		rec[1] = byte(*sig.RecoveryID)
+	}
+	if !sig.Timestamp.IsZero() {
+		flags |= storeHasTimestamp
+		binary.BigEndian.PutUint64(rec[2:], uint64(sig.Timestamp.UnixNano()))
+	}
+	if sig.Sequence != nil {
+		flags |= storeHasSequence
+		binary.BigEndian.PutUint64(rec[10:], uint64(*sig.Sequence))
+	}
+	if sig.BlockHeight != nil {
+		flags |= storeHasBlockHeight
+		binary.BigEndian.PutUint64(rec[18:], uint64(*sig.BlockHeight))
+	}
+	if len(sig.PublicKey) > 0 {
+		publicKey, err := ParsePublicKey(sig.PublicKey)
+		if err != nil {
+			return err
+		}
+		if len(publicKey) > 33 {
+			return fmt.Errorf("public key of %d bytes does not fit a store record", len(publicKey))
+		}
+		flags |= storeHasPublicKey
+		rec[26] = byte(len(publicKey))
+		copy(rec[27:], publicKey)
+	}
+	rec[0] = flags
+	return nil
+}
+
+// Slice decodes signatures [start, end).
+func (s *SignatureStore) Slice(start, end int) []*Signature {
+	if start < 0 {
+		start = 0
+	}
+	if end > s.count {
+		end = s.count
+	}
+	var signatures []*Signature
+	for i := start; i < end; i++ {
+		signatures = append(signatures, s.Signature(i))
+	}
+	return signatures
+}
+
+// Iter returns an iterator over all signatures.
+func (s *SignatureStore) Iter() *SignatureIterator {
+	return &SignatureIterator{store: s, index: -1}
+}
+
+// Close unmaps the store.
+func (s *SignatureStore) Close() error {
+	if s.release == nil {
+		return nil
+	}
+	err := s.release()
+	s.release = nil
+	s.data = nil
+	return err
+}
+
+// SignatureIterator walks a SignatureStore in order.
+//
+//	it := store.Iter()
+//	for it.Next() {
+//	    sig := it.Signature()
+//	}
+type SignatureIterator struct {
+	store *SignatureStore
+	index int
+}
+
+// Next advances to the next signature and reports whether there is one.
+func (it *SignatureIterator) Next() bool {
+	if it.index+1 >= it.store.count {
+		return false
+	}
+	it.index++
+	return true
+}
+
+// Index returns the position of the current signature in the store.
+func (it *SignatureIterator) Index() int {
+	return it.index
+}
+
+// Signature decodes the current signature.
+func (it *SignatureIterator) Signature() *Signature {
+	return it.store.Signature(it.index)
+}
+
+// SignatureStoreWriter streams signatures into a store file, so conversion never holds
+// the whole dataset in memory.
+type SignatureStoreWriter struct {
+	file    *os.File
+	w       *bufio.Writer
+	count   uint64
+	err     error
+	context bool // write version 3 records
+
+	// Messages are spooled to a temporary file next to the store and appended after the
+	// last record on Close
+	messages      *os.File
+	messageWriter *bufio.Writer
+	messageSize   uint64
+}
+
+// StoreOption configures a SignatureStoreWriter.
+type StoreOption func(*SignatureStoreWriter)
+
+// WithStoreSignerContext records each signature's public key, recovery id, timestamp,
+// sequence, block height and message, in 168-byte records instead of 96 and a message
+// section. A store written with it is a lossless binary copy of a parsed dataset, so
+// test fixtures and benchmark datasets load without parsing JSON.
+func WithStoreSignerContext() StoreOption {
+	return func(sw *SignatureStoreWriter) {
+		sw.context = true
+	}
+}
+
+// NewSignatureStoreWriter creates a store at path. publicKey (33 bytes, compressed) is optional.
+func NewSignatureStoreWriter(path string, publicKey []byte, opts ...StoreOption) (*SignatureStoreWriter, error) {
+	if len(publicKey) != 0 && len(publicKey) != 33 {
+		return nil, fmt.Errorf("public key must be 33 bytes (compressed format), got %d", len(publicKey))
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	sw := &SignatureStoreWriter{file: file, w: bufio.NewWriter(file)}
+	for _, opt := range opts {
+		opt(sw)
+	}
+
+	version := uint32(storeVersion)
+	if sw.context {
+		version = storeMessageVersion
+	}
+	header := make([]byte, storeHeaderSize)
+	copy(header, storeMagic)
+	binary.BigEndian.PutUint32(header[8:], version)
+	copy(header[20:], publicKey)
+	if _, err := sw.w.Write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return sw, nil
+}
+
+// Write appends one signature.
+func (sw *SignatureStoreWriter) Write(sig *Signature) error {
+	if sw.err != nil {
+		return sw.err
+	}
+	var buf [storeMessageRecordSize]byte
+	rec := buf[:storeRecordSize]
+	for i, v := range []*big.Int{sig.Z, sig.R, sig.S} {
+		if v == nil || v.Sign() < 0 || v.BitLen() > 256 {
+			return fmt.Errorf("signature %d: value out of range", sw.count)
+		}
+		v.FillBytes(rec[i*32 : (i+1)*32])
+	}
+	if sw.context {
+		rec = buf[:]
+		if err := encodeSignerContext(rec[storeRecordSize:], sig); err != nil {
+			return fmt.Errorf("signature %d: %w", sw.count, err)
+		}
+		if sig.Message != nil {
+			if err := sw.writeMessage(rec, sig.Message); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := sw.w.Write(rec); err != nil {
+		sw.err = err
+		return err
+	}
+	sw.count++
+	return nil
+}
+
+// writeMessage spools message and points the record rec at it.
+func (sw *SignatureStoreWriter) writeMessage(rec []byte, message []byte) error {
+	if uint64(len(message)) > math.MaxUint32 {
+		return fmt.Errorf("signature %d: message of %d bytes does not fit a store record", sw.count, len(message))
+	}
+	if sw.messages == nil {
+		dir, name := filepath.Split(sw.file.Name())
+		file, err := os.CreateTemp(dir, name+".messages-*")
+		if err != nil {
+			sw.err = err
+			return err
+		}
+		sw.messages, sw.messageWriter = file, bufio.NewWriter(file)
+	}
+	if _, err := sw.messageWriter.Write(message); err != nil {
+		sw.err = err
+		return err
+	}
+	rec[storeRecordSize] |= storeHasMessage
+	binary.BigEndian.PutUint64(rec[storeContextRecordSize:], sw.messageSize)
+	binary.BigEndian.PutUint32(rec[storeContextRecordSize+8:], uint32(len(message)))
+	sw.messageSize += uint64(len(message))
+	return nil
+}
+
+// Close writes the signature count and closes the file.
+func (sw *SignatureStoreWriter) Close() error {
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+	if sw.messages != nil {
+		if sw.err == nil {
+			sw.err = sw.messageWriter.Flush()
+		}
+		if sw.err == nil {
+			_, sw.err = sw.messages.Seek(0, io.SeekStart)
+		}
+		if sw.err == nil {
+			_, sw.err = io.Copy(sw.file, sw.messages)
+		}
+		sw.messages.Close()
+		os.Remove(sw.messages.Name())
+	}
+	if sw.err == nil {
+		var count [8]byte
+		binary.BigEndian.PutUint64(count[:], sw.count)
+		_, sw.err = sw.file.WriteAt(count[:], 12)
+	}
+	if err := sw.file.Close(); sw.err == nil {
+		sw.err = err
+	}
+	return sw.err
+}
+
+// WriteSignatureStore writes signatures to a new store at path.
+func WriteSignatureStore(path string, signatures []*Signature, publicKey []byte, opts ...StoreOption) error {
+	sw, err := NewSignatureStoreWriter(path, publicKey, opts...)
+	if err != nil {
+		return err
+	}
+	for _, sig := range signatures {
+		if err := sw.Write(sig); err != nil {
+			sw.Close()
+			return err
+		}
+	}
+	return sw.Close()
+}
+
+// StoreParser implements SignatureParser for signature store files, so a store can be
+// used anywhere a JSON or CSV file is accepted. Client.RecoverKey does not parse a store
+// with it: it searches the store window by window (see RecoverKeyFromStore).
+type StoreParser struct {
+	// Window is the number of signatures per window Client.RecoverKey searches at a
+	// time (0 = 256)
+	Window int
+}
+
+// ParseSignatures loads every signature from a store file. Stores are memory-mapped, so
+// unlike JSON and CSV files they must be local.
+func (p *StoreParser) ParseSignatures(path string) ([]*Signature, error) {
+	store, err := p.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.Slice(0, store.Len()), nil
+}
+
+// open opens a local store file.
+func (p *StoreParser) open(path string) (*SignatureStore, error) {
+	if source.IsRemote(path) {
+		return nil, fmt.Errorf("%s: signature stores must be local files", path)
+	}
+	return OpenSignatureStore(path)
+}
+
+// RecoverKeyFromStore runs the client's strategy over consecutive windows of a store.
+// Flawed signers relate nonces of signatures produced close together, so windows of a
+// few hundred signatures (overlapping by half) keep the pairwise search tractable on
+// datasets far too large to search as a whole. Signatures are decoded once each, as the
+// store's iterator reaches them, so memory holds one window whatever the store's size.
+//
+// Args:
+//   - store: Opened signature store
+//   - window: Signatures per window (0 = 256)
+//   - publicKeyHex: Optional public key (falls back to the store's public key)
+//
+// Returns:
+//   - RecoveryResult with SignaturePair indexing into the store, error otherwise
+func (c *Client) RecoverKeyFromStore(ctx context.Context, store *SignatureStore, window int, publicKeyHex string) (*RecoveryResult, error) {
+	if store.Len() < 2 {
+		return nil, fmt.Errorf("need at least 2 signatures, got %d", store.Len())
+	}
+	if window < 2 {
+		window = 256
+	}
+	if publicKeyHex == "" && store.PublicKey != nil {
+		publicKeyHex = fmt.Sprintf("%x", store.PublicKey)
+	}
+
+	step := window / 2
+	it := store.Iter()
+	signatures := make([]*Signature, 0, window)
+	for start := 0; ; start += step {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Slide the window: keep its second half and read up to a full one
+		if start > 0 {
+			signatures = append(signatures[:0], signatures[step:]...)
+		}
+		added := 0
+		for len(signatures) < window && it.Next() {
+			signatures = append(signatures, it.Signature())
+			added++
+		}
+		if added == 0 && start > 0 || len(signatures) < 2 {
+			break
+		}
+
+		result, err := c.RecoverKeyFromSignatures(ctx, signatures, publicKeyHex)
+		if err == nil {
+			result.SignaturePair[0] += start
+			result.SignaturePair[1] += start
+			return result, nil
+		}
+		if errors.Is(err, ErrQuotaExceeded) {
+			return nil, err
+		}
+		if len(signatures) < window {
+			break
+		}
+	}
+	return nil, errors.New("failed to recover private key")
+}
@@ -0,0 +1,147 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// storeTestSignatures signs count messages with nonces base, base+7, base+14, ...
+func storeTestSignatures(d *big.Int, count int) []*Signature {
+	base, _ := new(big.Int).SetString("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4", 16)
+	signatures := make([]*Signature, count)
+	for i := range signatures {
+		k := new(big.Int).Add(base, big.NewInt(int64(7*i)))
+		signatures[i] = signWithNonce(d, k, HashMessage([]byte{byte(i), byte(i >> 8)}))
+	}
+	return signatures
+}
+
+func TestSignatureStore_RoundTrip(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := storeTestSignatures(d, 50)
+	path := filepath.Join(t.TempDir(), "signatures.store")
+
+	if err := WriteSignatureStore(path, signatures, publicKey); err != nil {
+		t.Fatalf("WriteSignatureStore failed: %v", err)
+	}
+	store, err := OpenSignatureStore(path)
+	if err != nil {
+		t.Fatalf("OpenSignatureStore failed: %v", err)
+	}
+	defer store.Close()
+
+	if store.Len() != len(signatures) {
+		t.Fatalf("Expected %d signatures, got %d", len(signatures), store.Len())
+	}
+	if string(store.PublicKey) != string(publicKey) {
+		t.Errorf("Expected public key %x, got %x", publicKey, store.PublicKey)
+	}
+
+	it := store.Iter()
+	n := 0
+	for it.Next() {
+		got, want := it.Signature(), signatures[it.Index()]
+		if got.Z.Cmp(want.Z) != 0 || got.R.Cmp(want.R) != 0 || got.S.Cmp(want.S) != 0 {
+			t.Errorf("Signature %d does not round-trip", it.Index())
+		}
+		n++
+	}
+	if n != len(signatures) {
+		t.Errorf("Iterator visited %d signatures, expected %d", n, len(signatures))
+	}
+
+	if got := store.Slice(45, 100); len(got) != 5 {
+		t.Errorf("Expected Slice to clamp to 5 signatures, got %d", len(got))
+	}
+}
+
+func TestSignatureStore_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "signatures.store")
+	if err := WriteSignatureStore(path, storeTestSignatures(big.NewInt(1), 3), nil); err != nil {
+		t.Fatalf("WriteSignatureStore failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	truncated := filepath.Join(dir, "truncated.store")
+	os.WriteFile(truncated, data[:len(data)-1], 0o644)
+	if _, err := OpenSignatureStore(truncated); err == nil {
+		t.Error("Expected error for truncated store")
+	}
+
+	garbage := filepath.Join(dir, "garbage.store")
+	os.WriteFile(garbage, []byte(`{"signatures": []}`), 0o644)
+	if _, err := OpenSignatureStore(garbage); err == nil {
+		t.Error("Expected error for non-store file")
+	}
+
+	store, err := OpenSignatureStore(path)
+	if err != nil {
+		t.Fatalf("OpenSignatureStore failed: %v", err)
+	}
+	defer store.Close()
+	if store.PublicKey != nil {
+		t.Errorf("Expected no public key, got %x", store.PublicKey)
+	}
+}
+
+func TestClient_RecoverKeyFromStore(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	// Unrelated nonces everywhere except a counter pair at 70 and 71
+	signatures := make([]*Signature, 100)
+	for i := range signatures {
+		k := new(big.Int).Lsh(big.NewInt(int64(1000+i)), 200)
+		if i == 71 {
+			k = new(big.Int).Lsh(big.NewInt(1000+70), 200)
+			k.Add(k, big.NewInt(300))
+		}
+		signatures[i] = signWithNonce(d, k, HashMessage([]byte{byte(i)}))
+	}
+	path := filepath.Join(t.TempDir(), "signatures.store")
+	if err := WriteSignatureStore(path, signatures, publicKey); err != nil {
+		t.Fatalf("WriteSignatureStore failed: %v", err)
+	}
+	store, err := OpenSignatureStore(path)
+	if err != nil {
+		t.Fatalf("OpenSignatureStore failed: %v", err)
+	}
+	defer store.Close()
+
+	client := NewClient().WithStrategy(&BSGSStrategy{Bound: 1 << 10, MaxPairs: 200})
+	result, err := client.RecoverKeyFromStore(context.Background(), store, 16, "")
+	if err != nil {
+		t.Fatalf("RecoverKeyFromStore failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(d) != 0 {
+		t.Errorf("Expected private key %s, got %s", d, result.PrivateKey)
+	}
+	if result.SignaturePair != [2]int{70, 71} {
+		t.Errorf("Expected signature pair [70 71], got %v", result.SignaturePair)
+	}
+	if !result.Verified {
+		t.Error("Expected the store's public key to verify the result")
+	}
+
+	// RecoverKey searches a store through the same windows, of any size
+	for _, window := range []int{5, 16} {
+		client.WithParser(&StoreParser{Window: window})
+		result, err := client.RecoverKey(context.Background(), path, "")
+		if err != nil {
+			t.Fatalf("RecoverKey with a %d-signature window failed: %v", window, err)
+		}
+		if result.PrivateKey.Cmp(d) != 0 || result.SignaturePair != [2]int{70, 71} {
+			t.Errorf("Window %d: expected the key from pair [70 71], got pair %v", window, result.SignaturePair)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package ecdsaaffine
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"math/big"
+	"time"
 )
 
 // BruteForceStrategy defines the interface for custom brute-force strategies.
@@ -25,12 +28,76 @@ type Pattern struct {
 	Priority int   // Lower priority = tested first
 }
 
+// OrderMinus returns n - c, for writing a pattern the way the signer computes it: b = n - c
+// for a counter that wraps around the group order, a = n - 1 for nonces computed as n - k.
+// Pattern{A: OrderMinus(1), B: OrderMinus(3)} is k2 = n - k1 - 3. A and B are reduced mod n
+// to the value of least absolute value before they are tried (here a = -1, b = -3), which
+// is also how results, reports and CandidateFilter see them.
+func OrderMinus(c int64) *big.Int {
+	return new(big.Int).Sub(Secp256k1CurveOrder, big.NewInt(c))
+}
+
+// PatternsForSteps returns a counter pattern (a = 1) for each step, in the order given:
+// k2 = k1 + step, named like the common patterns ("counter_+17", "counter_-3") and
+// prioritized 1, 2, ... so that they are tried in that order. Steps may be negative.
+func PatternsForSteps(steps []int64) []Pattern {
+	patterns := make([]Pattern, 0, len(steps))
+	for i, step := range steps {
+		patterns = append(patterns, Pattern{
+			A:        big.NewInt(1),
+			B:        big.NewInt(step),
+			Name:     fmt.Sprintf("counter_%+d", step),
+			Priority: i + 1,
+		})
+	}
+	return patterns
+}
+
+// PatternsForRange returns the patterns k2 = a*k1 + b for b from bMin to bMax in steps
+// of stride (a stride below 1 is taken as 1), prioritized in that order. The bounds need
+// not be symmetric and either may be negative; a may be negative too. Patterns with a = 1
+// are named like counters ("counter_-2"), the others "affine_a<a>_b<b>". Each b is one
+// pattern, so wide ranges belong in RangeConfig instead.
+func PatternsForRange(a, bMin, bMax, stride int64) []Pattern {
+	if stride < 1 {
+		stride = 1
+	}
+	var patterns []Pattern
+	for b := bMin; b <= bMax; b += stride {
+		name := fmt.Sprintf("affine_a%d_b%+d", a, b)
+		if a == 1 {
+			name = fmt.Sprintf("counter_%+d", b)
+		}
+		patterns = append(patterns, Pattern{
+			A:        big.NewInt(a),
+			B:        big.NewInt(b),
+			Name:     name,
+			Priority: len(patterns) + 1,
+		})
+		if b > bMax-stride {
+			break // b + stride would pass bMax, or overflow
+		}
+	}
+	return patterns
+}
+
+// centered returns v mod n mapped into (-n/2, n/2].
+func centered(v *big.Int) *big.Int {
+	d := new(big.Int).Mod(v, Secp256k1CurveOrder)
+	if d.Cmp(new(big.Int).Rsh(Secp256k1CurveOrder, 1)) > 0 {
+		d.Sub(d, Secp256k1CurveOrder)
+	}
+	return d
+}
+
 // RangeConfig configures the search range for brute-force operations.
 type RangeConfig struct {
-	// ARange defines the range for a values [Min, Max] (inclusive)
+	// ARange defines the range for a values [Min, Max] (inclusive). Values are mod n:
+	// a = -1 is a = n - 1, nonces computed as n - k.
 	ARange [2]int
 
-	// BRange defines the range for b values [Min, Max] (inclusive)
+	// BRange defines the range for b values [Min, Max] (inclusive). Values are mod n:
+	// b = -c is b = n - c, as from a counter that wraps around the group order.
 	BRange [2]int
 
 	// MaxPairs limits the number of signature pairs to test
@@ -39,10 +106,80 @@ type RangeConfig struct {
 	// NumWorkers controls parallelization (0 = auto-detect)
 	NumWorkers int
 
+	// AutoTune, if set, measures the first parallel range phase for this long before it
+	// runs, on the signatures searched, under worker counts around NumWorkers and several
+	// chunk sizes, and searches with the fastest (see AutoTune and SearchStats.Tuning).
+	// 0 = off
+	AutoTune time.Duration
+
 	// SkipZeroA skips a=0 (which is wasteful)
 	SkipZeroA bool
+
+	// BothDirections also tries each relationship with |a| >= 2 the other way round on
+	// each pair (i, j), i < j: k_i = a*k_j + b, reported as SignaturePair [j, i]. For
+	// a = 1 and a = -1 the other direction is the same relationship with b negated or
+	// unchanged, which a b range symmetric around 0 already covers.
+	BothDirections bool
+
+	// Deterministic makes parallel range searches report the match a sequential scan would
+	// find first (lowest pair, then a with a=1 first, then b), so repeated runs give identical results.
+	// It costs at most one pair's worth of extra work per worker.
+	Deterministic bool
+
+	// MaxRate caps the range search at this many candidates/sec across all workers (0 = unlimited)
+	MaxRate float64
+
+	// MaxCPUPercent caps each worker's busy time as a percentage of wall time (0 = unlimited)
+	MaxCPUPercent int
+
+	// MaxCandidates stops the range search of the strategy after this many (a, b)
+	// candidates in total, across phases and searches (0 = unlimited); see ErrQuotaExceeded
+	MaxCandidates int64
+
+	// MaxCPUTime stops the range search of the strategy once its workers have been busy
+	// this long in total, summed over workers (0 = unlimited); see ErrQuotaExceeded
+	MaxCPUTime time.Duration
+
+	// SkipOverBudget skips range phases that are predicted not to finish in the remaining
+	// budget, the context deadline or what is left of MaxCPUTime, instead of starting them
+	// (see WillComplete). Skipped phases are noted in the strategy's Report.
+	SkipOverBudget bool
+
+	// Rate is the search rate SkipOverBudget predicts phase durations with; if zero, a
+	// short calibration run (MeasureSearchRate) measures it before the first range phase
+	Rate SearchRate
+
+	// ThrottleControl, if set, changes MaxRate and MaxCPUPercent, or pauses the search, while it runs.
+	// Close it once the strategy is no longer used.
+	ThrottleControl <-chan ThrottleSetting
+
+	// SkipPhase, if set, abandons the pattern or range phase running when a value arrives
+	// and moves on to the next one. Values sent between phases are dropped.
+	SkipPhase <-chan struct{}
+
+	// CandidateFilter, if set, is asked before each pattern and range candidate is tried
+	// and skips those it rejects
+	CandidateFilter CandidateFilter
+
+	// KeyCacheSize is how many recently rejected key values all workers remember, so a key
+	// recovered again from another candidate is not verified twice (0 = no cache)
+	KeyCacheSize int
+
+	// Verification selects how candidate keys are checked (default FastVerification)
+	Verification VerificationBackend
+
+	// Arithmetic selects the modular arithmetic candidate keys are recovered with
+	// (default AutoArithmetic)
+	Arithmetic ArithmeticBackend
 }
 
+// CandidateFilter reports whether the relationship k2 = a*k1 + b is worth trying on a
+// signature pair (indexes into the signatures being searched), encoding domain knowledge
+// such as "b is a multiple of 1000" or "b is a plausible time difference". It runs in the
+// search's inner loop, on all workers at once: it must be fast, safe for concurrent use,
+// and must not modify a or b.
+type CandidateFilter func(a, b *big.Int, pair [2]int) bool
+
 // DefaultRangeConfig returns a sensible default configuration.
 func DefaultRangeConfig() RangeConfig {
 	return RangeConfig{
@@ -51,6 +188,8 @@ func DefaultRangeConfig() RangeConfig {
 		MaxPairs:  100,
 		NumWorkers: 0, // Auto-detect
 		SkipZeroA: true,
+		BothDirections: true,
+		KeyCacheSize: 1 << 16,
 	}
 }
 
@@ -61,6 +200,19 @@ type PatternConfig struct {
 
 	// IncludeCommonPatterns includes built-in common patterns
 	IncludeCommonPatterns bool
+
+	// Hypotheses, if set, proposes relationships for each signature pair that are tried
+	// before any pattern (e.g. MetadataHypotheses)
+	Hypotheses HypothesisGenerator
+
+	// SuggestedPatterns tries the patterns SuggestPatterns reads from the dataset's
+	// statistics on every pair, after the hypotheses and before the common patterns
+	SuggestedPatterns bool
+
+	// Inject, if set, adds patterns to a running search, e.g. from a job API or a
+	// reloaded pattern file. They are tried, by priority, at the next safe point: between
+	// phases, or during a range phase with its workers held at their next batch.
+	Inject <-chan []Pattern
 }
 
 // DefaultPatternConfig returns a configuration with common patterns enabled.
@@ -68,6 +220,8 @@ func DefaultPatternConfig() PatternConfig {
 	return PatternConfig{
 		CustomPatterns:        []Pattern{},
 		IncludeCommonPatterns: true,
+		Hypotheses:            MetadataHypotheses,
+		SuggestedPatterns:     true,
 	}
 }
 
@@ -110,3 +264,10 @@ func defaultCommonPatterns() []Pattern {
 	}
 }
 
+// loggerOrDefault returns l, or the standard logger if l is nil.
+func loggerOrDefault(l *log.Logger) *log.Logger {
+	if l == nil {
+		return log.Default()
+	}
+	return l
+}
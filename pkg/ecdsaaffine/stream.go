@@ -0,0 +1,194 @@
+package ecdsaaffine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// DefaultStreamWindow is the number of recent signatures a Detector keeps per signer.
+const DefaultStreamWindow = 64
+
+// SignatureEvent is the message format of a signature stream: one JSON object per
+// message, as a producer publishes each signature it observes (e.g. to a Kafka topic or
+// NATS subject). The fields are those of the JSON signature files (see JSONParser), but
+// public_key is required, since a Detector keeps a window per signer.
+type SignatureEvent struct {
+	ID        string     `json:"id,omitempty"`      // producer's reference, e.g. a transaction hash
+	PublicKey string     `json:"public_key"`        // signer public key or Ethereum address, hex
+	Z         string     `json:"z,omitempty"`       // message hash, encoded as in signature files
+	Message   string     `json:"message,omitempty"` // hashed with HashMessage when z is empty
+	R         string     `json:"r"`
+	S         string     `json:"s"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Sequence  *int64     `json:"sequence,omitempty"` // position in the signer's sequence (e.g. account nonce)
+}
+
+// NewSignatureEvent returns the event a producer publishes for a signature with signer
+// context (Signature.PublicKey must be set). ParseSignatureEvent decodes it.
+func NewSignatureEvent(sig *Signature, id string) *SignatureEvent {
+	event := &SignatureEvent{
+		ID:        id,
+		PublicKey: fmt.Sprintf("%x", sig.PublicKey),
+		Z:         fmt.Sprintf("0x%064x", sig.Z),
+		R:         fmt.Sprintf("0x%064x", sig.R),
+		S:         fmt.Sprintf("0x%064x", sig.S),
+		Sequence:  sig.Sequence,
+	}
+	if !sig.Timestamp.IsZero() {
+		event.Timestamp = &sig.Timestamp
+	}
+	return event
+}
+
+// ParseSignatureEvent decodes one event and returns its signature and ID. As in signature
+// files, z, r and s may also be JSON numbers, and the optional fields JSONParser reads
+// (v, timestamp, sequence, block_height) are kept.
+func ParseSignatureEvent(data []byte) (*Signature, string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var item map[string]interface{}
+	if err := decoder.Decode(&item); err != nil {
+		return nil, "", err
+	}
+	id, _ := item["id"].(string)
+	if item["public_key"] == nil {
+		return nil, id, errors.New("event has no public_key")
+	}
+	sig, err := (&JSONParser{ZField: "z"}).parseItem(item)
+	return sig, id, err
+}
+
+// Detection is a key recovered from a signer's stream of signatures.
+type Detection struct {
+	PublicKey []byte          // the signer's key as given in its events
+	Result    *RecoveryResult // from a Detector, SignaturePair counts the signer's events from 0
+	EventIDs  [2]string       // IDs of the two events the key was recovered from
+}
+
+// Detector turns a client into a real-time weak nonce detector: it takes signature
+// events as they are consumed from a message broker, keeps a sliding window of each
+// signer's most recent signatures, and runs the client's strategy on every pair a new
+// signature forms with its signer's window, as WatchSignatures does for files. Like
+// there, the strategy should be one whose search of a pair ends quickly. Once a signer's
+// key is recovered, its later signatures are no longer searched.
+//
+// Memory grows with the number of signers times the window. A Detector is not safe for
+// concurrent use; consume a partition or subscription with one goroutine.
+type Detector struct {
+	// Logger receives the events Consume skips (default log.Default())
+	Logger *log.Logger
+
+	client  *Client
+	window  int
+	signers map[string]*signerWindow
+}
+
+// signerWindow is one signer's recent signatures.
+type signerWindow struct {
+	signatures []*Signature
+	ids        []string
+	first      int // position in the signer's stream of signatures[0]
+	recovered  bool
+}
+
+// NewDetector creates a detector that keeps window signatures per signer
+// (0 = DefaultStreamWindow).
+func NewDetector(client *Client, window int) *Detector {
+	if window <= 0 {
+		window = DefaultStreamWindow
+	}
+	return &Detector{client: client, window: window, signers: make(map[string]*signerWindow)}
+}
+
+// Add takes the signature of one event, with signer context, and returns the signer's
+// key if the signature gives it away together with one in the signer's window, or nil.
+func (d *Detector) Add(ctx context.Context, sig *Signature, id string) (*Detection, error) {
+	if sig.PublicKey == nil {
+		return nil, errors.New("signature has no signer public key")
+	}
+	w := d.signers[string(sig.PublicKey)]
+	if w == nil {
+		w = &signerWindow{}
+		d.signers[string(sig.PublicKey)] = w
+	}
+	if w.recovered {
+		return nil, nil
+	}
+	if len(w.signatures) == d.window {
+		w.signatures = append(w.signatures[:0], w.signatures[1:]...)
+		w.ids = append(w.ids[:0], w.ids[1:]...)
+		w.first++
+	}
+	w.signatures = append(w.signatures, sig)
+	w.ids = append(w.ids, id)
+
+	j := len(w.signatures) - 1
+	for i := 0; i < j; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := d.client.searchPair(ctx, w.signatures, i, j, sig.PublicKey)
+		if result == nil || !result.Verified {
+			continue
+		}
+		w.recovered = true
+		detection := &Detection{
+			PublicKey: sig.PublicKey,
+			Result:    result,
+			EventIDs:  [2]string{w.ids[result.SignaturePair[0]], w.ids[result.SignaturePair[1]]},
+		}
+		result.SignaturePair[0] += w.first
+		result.SignaturePair[1] += w.first
+		return detection, nil
+	}
+	return nil, nil
+}
+
+// Consume reads newline-delimited SignatureEvents from r until it ends or ctx is done,
+// calling found for every key recovered. Malformed events are logged and skipped, so one
+// bad producer does not stop detection. Consuming a broker's output as lines (e.g.
+// "kcat -C -t signatures -u" or "nats sub signatures --raw") needs no client library.
+func (d *Detector) Consume(ctx context.Context, r io.Reader, found func(*Detection)) error {
+	return consumeEvents(ctx, r, d.Logger, func(sig *Signature, id string) (*Detection, error) {
+		return d.Add(ctx, sig, id)
+	}, found)
+}
+
+// consumeEvents reads newline-delimited SignatureEvents from r and passes each to add,
+// calling found for every key add recovers.
+func consumeEvents(ctx context.Context, r io.Reader, logger *log.Logger, add func(*Signature, string) (*Detection, error), found func(*Detection)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		sig, id, err := ParseSignatureEvent(scanner.Bytes())
+		if err != nil {
+			loggerOrDefault(logger).Printf("Skipping event %d: %v", line, err)
+			continue
+		}
+		detection, err := add(sig, id)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			loggerOrDefault(logger).Printf("Skipping event %d: %v", line, err)
+			continue
+		}
+		if detection != nil {
+			found(detection)
+		}
+	}
+	return scanner.Err()
+}
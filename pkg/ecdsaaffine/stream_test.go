@@ -0,0 +1,117 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// signedEvent returns the event for a signature by d with nonce k.
+func signedEvent(t *testing.T, d *big.Int, k int64, id string) string {
+	t.Helper()
+	sig := signWithNonce(d, big.NewInt(k), HashMessage([]byte(id)))
+	sig.PublicKey = secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	data, err := json.Marshal(NewSignatureEvent(sig, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+func TestDetector_Consume(t *testing.T) {
+	healthy := big.NewInt(0xa11ce)
+	weak := big.NewInt(0xb0b)
+	client := NewClient(WithStrategy(NewPatternStrategy()), WithLogger(log.New(io.Discard, "", 0)))
+
+	// Two signers interleaved; the weak one uses a counter nonce, with a malformed event
+	// from another producer in between
+	stream := signedEvent(t, healthy, 104729, "tx-1") +
+		signedEvent(t, weak, 7000001, "tx-2") +
+		"not an event\n" +
+		signedEvent(t, healthy, 8675309, "tx-3") +
+		signedEvent(t, weak, 5550123, "tx-4") +
+		signedEvent(t, weak, 7000002, "tx-5") +
+		signedEvent(t, weak, 7000003, "tx-6")
+
+	var detections []*Detection
+	err := NewDetector(client, 0).Consume(context.Background(), strings.NewReader(stream), func(d *Detection) {
+		detections = append(detections, d)
+	})
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if len(detections) != 1 {
+		t.Fatalf("Expected one detection, got %d", len(detections))
+	}
+	d := detections[0]
+	if d.Result.PrivateKey.Cmp(weak) != 0 || !d.Result.Verified {
+		t.Errorf("Expected the weak signer's key, got %+v", d.Result)
+	}
+	if d.EventIDs != [2]string{"tx-2", "tx-5"} || d.Result.SignaturePair != [2]int{0, 2} {
+		t.Errorf("Expected events tx-2 and tx-5 (signer positions 0 and 2), got %v %v", d.EventIDs, d.Result.SignaturePair)
+	}
+}
+
+func TestDetector_Window(t *testing.T) {
+	d := big.NewInt(0xc0ffee)
+	client := NewClient(WithStrategy(NewPatternStrategy()), WithLogger(log.New(io.Discard, "", 0)))
+	detector := NewDetector(client, 2)
+	ctx := context.Background()
+
+	// The related signature has left the window by the time its partner arrives
+	events := []string{
+		signedEvent(t, d, 31337, "a"),
+		signedEvent(t, d, 1234577, "b"),
+		signedEvent(t, d, 99991, "c"),
+		signedEvent(t, d, 31338, "d"),
+		signedEvent(t, d, 31339, "e"),
+	}
+	for i, line := range events {
+		sig, id, err := ParseSignatureEvent([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		detection, err := detector.Add(ctx, sig, id)
+		if err != nil {
+			t.Fatalf("Add(%s): %v", id, err)
+		}
+		if i < 4 && detection != nil {
+			t.Fatalf("Unexpected detection at event %s: %+v", id, detection)
+		}
+		if i == 4 {
+			if detection == nil || detection.Result.SignaturePair != [2]int{3, 4} {
+				t.Fatalf("Expected a detection from signer positions 3 and 4, got %+v", detection)
+			}
+		}
+	}
+
+	if _, _, err := ParseSignatureEvent([]byte(`{"id": "x", "z": 3, "r": 1, "s": 2}`)); err == nil {
+		t.Error("Expected an error for an event without public_key")
+	}
+}
+
+func TestSignatureEvent_RoundTrip(t *testing.T) {
+	d := big.NewInt(0x1234)
+	sig := signWithNonce(d, big.NewInt(42), big.NewInt(7))
+	sig.PublicKey = secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	data, err := json.Marshal(NewSignatureEvent(sig, "x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, id, err := ParseSignatureEvent(data)
+	if err != nil || id != "x" {
+		t.Fatalf("ParseSignatureEvent: %q, %v", id, err)
+	}
+	if decoded.Z.Cmp(sig.Z) != 0 || decoded.R.Cmp(sig.R) != 0 || decoded.S.Cmp(sig.S) != 0 ||
+		fmt.Sprintf("%x", decoded.PublicKey) != fmt.Sprintf("%x", sig.PublicKey) {
+		t.Errorf("Round trip changed the signature: %+v", decoded)
+	}
+}
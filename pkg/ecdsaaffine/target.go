@@ -0,0 +1,235 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/base58"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/bech32"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Bitcoin base58 address versions
+const (
+	p2pkhMainnet = 0x00
+	p2shMainnet  = 0x05
+	p2pkhTestnet = 0x6f
+	p2shTestnet  = 0xc4
+)
+
+// ParseTarget resolves the way a user identifies the victim key to a verification
+// target for VerifyRecoveredKey and the strategies. Besides the hex encodings
+// ParsePublicKeyHex accepts (public keys and Ethereum addresses), it accepts:
+//   - Bitcoin addresses: P2PKH (1..., m/n...), P2SH-wrapped P2WPKH (3..., 2...), P2WPKH
+//     (bc1q..., tb1q...) and key-path-only P2TR (bc1p..., tb1p...), as output scripts
+//   - extended public keys (xpub, ypub, zpub, tpub, ...), as the set of keys
+//     DeriveXpubKeys derives with the given depth and gap
+//   - bech32 Nostr keys (npub1...), as 32-byte x-only keys
+//
+// depth and gap <= 0 mean DefaultXpubDepth and DefaultXpubGap.
+func ParseTarget(s string, depth, gap int) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty public key")
+	}
+	lower := strings.ToLower(s)
+
+	switch {
+	case strings.HasPrefix(lower, "npub1"):
+		hrp, data, variant, err := bech32.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid npub: %w", err)
+		}
+		if hrp != "npub" || variant != bech32.Bech32 {
+			return nil, errors.New("invalid npub: wrong prefix or checksum variant")
+		}
+		key, err := bech32.ConvertBits(data, 5, 8, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid npub: %w", err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid npub: key is %d bytes, expected 32", len(key))
+		}
+		return ParsePublicKey(key)
+	case strings.HasPrefix(lower, "bc1"), strings.HasPrefix(lower, "tb1"), strings.HasPrefix(lower, "bcrt1"):
+		return segwitTarget(lower[:strings.LastIndexByte(lower, '1')], s)
+	case isHex(strings.TrimPrefix(lower, "0x")):
+		return ParsePublicKeyHex(s)
+	}
+
+	payload, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized public key, address or extended key: %w", err)
+	}
+	switch len(payload) {
+	case 21:
+		return base58AddressTarget(payload)
+	case 78:
+		derived, err := deriveXpubKeys(payload, depth, gap)
+		if err != nil {
+			return nil, err
+		}
+		target := make([]byte, 0, 33*len(derived))
+		for _, key := range derived {
+			target = append(target, key.PublicKey...)
+		}
+		return target, nil
+	}
+	return nil, fmt.Errorf("unrecognized base58check payload of %d bytes", len(payload))
+}
+
+// addTweak returns pub + tweak*G.
+func addTweak(pub *secp256k1.PublicKey, tweak *secp256k1.ModNScalar) (*secp256k1.PublicKey, error) {
+	var tweakPoint, point, sum secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(tweak, &tweakPoint)
+	pub.AsJacobian(&point)
+	secp256k1.AddNonConst(&point, &tweakPoint, &sum)
+	if (sum.X.IsZero() && sum.Y.IsZero()) || sum.Z.IsZero() {
+		return nil, errors.New("tweaked key is the point at infinity")
+	}
+	sum.ToAffine()
+	return secp256k1.NewPublicKey(&sum.X, &sum.Y), nil
+}
+
+// segwitTarget converts a P2WPKH or P2TR address to its output script.
+func segwitTarget(hrp, address string) ([]byte, error) {
+	version, program, err := bech32.DecodeSegwit(hrp, address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid segwit address: %w", err)
+	}
+	switch {
+	case version == 0 && len(program) == 20:
+		return append([]byte{0x00, 0x14}, program...), nil
+	case version == 1 && len(program) == 32:
+		return append([]byte{0x51, 0x20}, program...), nil
+	}
+	return nil, fmt.Errorf("unsupported segwit address (witness version %d, %d-byte program): only P2WPKH and P2TR pay to a single key", version, len(program))
+}
+
+// base58AddressTarget converts a P2PKH or P2SH address payload to its output script.
+func base58AddressTarget(payload []byte) ([]byte, error) {
+	switch payload[0] {
+	case p2pkhMainnet, p2pkhTestnet:
+		script := append([]byte{0x76, 0xa9, 0x14}, payload[1:]...)
+		return append(script, 0x88, 0xac), nil
+	case p2shMainnet, p2shTestnet:
+		script := append([]byte{0xa9, 0x14}, payload[1:]...)
+		return append(script, 0x87), nil
+	}
+	return nil, fmt.Errorf("unsupported address version 0x%02x", payload[0])
+}
+
+// isTarget reports whether publicKey is one of the non-key verification targets:
+//   - 32 bytes: an x-only key (BIP-340, Nostr), matched on the X coordinate
+//   - 25 bytes: a P2PKH script, matched against the compressed and uncompressed key
+//   - 23 bytes: a P2SH script, matched as P2SH-wrapped P2WPKH
+//   - 22 bytes: a P2WPKH script
+//   - 34 bytes: a P2TR script, matched as a BIP-86 key-path-only output
+//   - two or more concatenated 33-byte compressed keys, matching any of them
+func isTarget(publicKey []byte) bool {
+	n := len(publicKey)
+	switch {
+	case n == 32:
+		return true
+	case n == 25:
+		return bytes.HasPrefix(publicKey, []byte{0x76, 0xa9, 0x14}) && bytes.HasSuffix(publicKey, []byte{0x88, 0xac})
+	case n == 23:
+		return bytes.HasPrefix(publicKey, []byte{0xa9, 0x14}) && publicKey[22] == 0x87
+	case n == 22:
+		return bytes.HasPrefix(publicKey, []byte{0x00, 0x14})
+	case n == 34:
+		return bytes.HasPrefix(publicKey, []byte{0x51, 0x20})
+	}
+	return n >= 66 && n%33 == 0
+}
+
+// validateTarget checks that the keys in an x-only key or key set are on the curve.
+func validateTarget(publicKey []byte) error {
+	switch {
+	case len(publicKey) == 32:
+		if _, err := secp256k1.ParsePubKey(append([]byte{0x02}, publicKey...)); err != nil {
+			return fmt.Errorf("invalid x-only public key: %w", err)
+		}
+	case len(publicKey)%33 == 0:
+		for i := 0; i < len(publicKey); i += 33 {
+			if _, err := secp256k1.ParsePubKey(publicKey[i : i+33]); err != nil {
+				return fmt.Errorf("invalid key %d in key set: %w", i/33, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchesTarget reports whether pub satisfies a non-key verification target.
+func matchesTarget(pub *secp256k1.PublicKey, target []byte) (bool, error) {
+	compressed := pub.SerializeCompressed()
+	switch len(target) {
+	case 32:
+		return bytes.Equal(compressed[1:], target), nil
+	case 25:
+		h := hash160(compressed)
+		u := hash160(pub.SerializeUncompressed())
+		return bytes.Equal(h, target[3:23]) || bytes.Equal(u, target[3:23]), nil
+	case 23:
+		redeem := append([]byte{0x00, 0x14}, hash160(compressed)...)
+		return bytes.Equal(hash160(redeem), target[2:22]), nil
+	case 22:
+		return bytes.Equal(hash160(compressed), target[2:]), nil
+	case 34:
+		output, err := taprootOutputKey(pub)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(output, target[2:]), nil
+	}
+	for i := 0; i < len(target); i += 33 {
+		if bytes.Equal(compressed, target[i:i+33]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hash160 is RIPEMD-160(SHA-256(data)).
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
+
+// taprootOutputKey returns the x-only BIP-86 output key for internal key pub: the
+// even-Y internal key tweaked by tagged_hash("TapTweak", x).
+func taprootOutputKey(pub *secp256k1.PublicKey) ([]byte, error) {
+	x := pub.SerializeCompressed()[1:]
+	internal, err := secp256k1.ParsePubKey(append([]byte{0x02}, x...))
+	if err != nil {
+		return nil, err
+	}
+
+	tag := sha256.Sum256([]byte("TapTweak"))
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(x)
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(h.Sum(nil)); overflow {
+		return nil, errors.New("taproot tweak exceeds the group order")
+	}
+	output, err := addTweak(internal, &tweak)
+	if err != nil {
+		return nil, err
+	}
+	return output.SerializeCompressed()[1:], nil
+}
+
+// isHex reports whether s is a non-empty string of hex digits.
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return s != "" && err == nil
+}
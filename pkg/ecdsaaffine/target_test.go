@@ -0,0 +1,90 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/bech32"
+)
+
+func TestParseTarget_Addresses(t *testing.T) {
+	// Addresses and keys of private key 1
+	for name, s := range map[string]string{
+		"P2PKH (compressed)":   "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		"P2PKH (uncompressed)": "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm",
+		"P2SH-P2WPKH":          "3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN",
+		"P2WPKH":               "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		"P2WPKH (uppercase)":   "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+		"P2TR":                 "bc1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5sspknck9",
+		"Ethereum address":     "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+		"x-only hex":           "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	} {
+		target, err := ParseTarget(s, 0, 0)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if ok, err := VerifyRecoveredKey(big.NewInt(1), target); !ok || err != nil {
+			t.Errorf("%s: expected key 1 to match (%v)", name, err)
+		}
+		if ok, _ := VerifyRecoveredKey(big.NewInt(2), target); ok {
+			t.Errorf("%s: expected key 2 not to match", name)
+		}
+		// The target survives the hex round trip the CLI makes through the client
+		if parsed, err := ParsePublicKeyHex(hex.EncodeToString(target)); err != nil || !bytes.Equal(parsed, target) {
+			t.Errorf("%s: hex round trip = %x, %v", name, parsed, err)
+		}
+	}
+
+	for name, s := range map[string]string{
+		"mistyped P2PKH":       "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMh",
+		"P2WSH":                "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3",
+		"private extended key": "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		"wrong npub prefix":    "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5",
+		"garbage":              "not a key",
+	} {
+		if _, err := ParseTarget(s, 0, 0); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseTarget_Npub(t *testing.T) {
+	// NIP-19 example
+	target, err := ParseTarget("npub10elfcs4fr0l0r8af98jlmgdh9c8tcxjvz9qkw038js35mp4dma8qzvjptg", 0, 0)
+	if err != nil {
+		t.Fatalf("ParseTarget failed: %v", err)
+	}
+	if want := "7e7e9c42a91bfef19fa929e5fda1b72e0ebc1a4c1141673e2794234d86addf4e"; hex.EncodeToString(target) != want {
+		t.Errorf("npub = %x, expected %s", target, want)
+	}
+}
+
+func TestClient_RecoverKeyFromSignatures_Address(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	k1, _ := new(big.Int).SetString("5d2f7ce2a4b7f3eab6f1d5c8e2f3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5", 16)
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, new(big.Int).Add(k1, big.NewInt(1)), HashMessage([]byte("message 2"))),
+	}
+
+	pub := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey()
+	p2tr, err := taprootOutputKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, _ := bech32.ConvertBits(p2tr, 8, 5, true)
+	address := bech32.Encode("bc", append([]byte{1}, program...), bech32.Bech32m)
+
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, address)
+	if err != nil {
+		t.Fatalf("RecoverKeyFromSignatures failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(d) != 0 || !result.Verified {
+		t.Errorf("Expected verified key %s, got %s (verified=%v)", d, result.PrivateKey, result.Verified)
+	}
+}
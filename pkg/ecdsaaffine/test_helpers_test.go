@@ -9,12 +9,22 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
+//go:generate go run ../../cmd/recovery generate-fixtures --out testdata/fixtures --seed 1
+
 // fixturesDir returns the path to the fixtures directory (works regardless of test cwd).
+// When the Python-generated fixtures are missing, it returns testdata/fixtures, a seeded
+// set generated in Go by pkg/flawedsigner (see the go:generate line above).
 func fixturesDir() string {
 	_, f, _, _ := runtime.Caller(0)
-	return filepath.Join(filepath.Dir(f), "..", "..", "fixtures")
+	dir := filepath.Join(filepath.Dir(f), "..", "..", "fixtures")
+	if _, err := os.Stat(filepath.Join(dir, "test_key_info.json")); err == nil {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(f), "testdata", "fixtures")
 }
 
 // loadTestKeyInfo reads the test key information from fixtures/test_key_info.json
@@ -72,8 +82,25 @@ func hexDecode(s string) ([]byte, error) {
 	return hex.DecodeString(s)
 }
 
-// loadTestSignatures loads test signatures from the fixtures directory
+// loadTestSignatures loads test signatures from the fixtures directory, through the
+// binary copy LoadCachedSignatures keeps in the user cache directory
 func loadTestSignatures(filename string) ([]*Signature, error) {
 	parser := &JSONParser{ZField: "z"}
-	return parser.ParseSignatures(filepath.Join(fixturesDir(), filename))
+	return LoadCachedSignatures(filepath.Join(fixturesDir(), filename), "", parser)
+}
+
+// signWithNonce creates a signature over hash z with private key d and an explicit nonce k.
+func signWithNonce(d, k, z *big.Int) *Signature {
+	n := Secp256k1CurveOrder
+
+	// r = (k*G).x mod n
+	r := secp256k1.PrivKeyFromBytes(k.Bytes()).PubKey().X()
+	r.Mod(r, n)
+
+	s := new(big.Int).Mul(r, d)
+	s.Add(s, z)
+	s.Mul(s, new(big.Int).ModInverse(k, n))
+	s.Mod(s, n)
+
+	return &Signature{Z: new(big.Int).Set(z), R: r, S: s}
 }
@@ -0,0 +1,127 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestThrottle_Wait(t *testing.T) {
+	if newThrottle(DefaultRangeConfig()) != nil {
+		t.Fatal("Expected no throttle for the default config")
+	}
+
+	// 1000 candidates/sec: two batches of 100 take ~200ms
+	config := DefaultRangeConfig()
+	config.MaxRate = 1000
+	limiter := newThrottle(config)
+	if n := limiter.batchSize(); n != 50 {
+		t.Errorf("Expected batches of 50 at 1000/sec, got %d", n)
+	}
+	start := time.Now()
+	limiter.wait(context.Background(), 100, 0)
+	limiter.wait(context.Background(), 100, 0)
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected ~200ms for 200 candidates at 1000/sec, got %v", elapsed)
+	}
+
+	// 25% CPU: 30ms busy requires 90ms idle
+	config = DefaultRangeConfig()
+	config.MaxCPUPercent = 25
+	limiter = newThrottle(config)
+	start = time.Now()
+	limiter.wait(context.Background(), 1, 30*time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected ~90ms idle at 25%% CPU, got %v", elapsed)
+	}
+
+	// Cancellation interrupts the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	limiter.wait(ctx, 1, time.Hour)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected a cancelled wait to return immediately, got %v", elapsed)
+	}
+}
+
+func TestThrottle_Control(t *testing.T) {
+	control := make(chan ThrottleSetting)
+	defer close(control)
+	config := DefaultRangeConfig()
+	config.ThrottleControl = control
+	limiter := newThrottle(config)
+	if limiter == nil {
+		t.Fatal("Expected a throttle when a control channel is set")
+	}
+	if n := limiter.batchSize(); n != rangeFlushInterval {
+		t.Errorf("Expected unthrottled batches of %d, got %d", rangeFlushInterval, n)
+	}
+
+	control <- ThrottleSetting{MaxRate: 200}
+	deadline := time.Now().Add(time.Second)
+	for limiter.batchSize() != 10 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Throttle did not follow the control channel, batch size %d", limiter.batchSize())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestThrottle_Pause(t *testing.T) {
+	control := make(chan ThrottleSetting)
+	config := DefaultRangeConfig()
+	config.ThrottleControl = control
+	limiter := newThrottle(config)
+
+	control <- ThrottleSetting{Paused: true}
+	control <- ThrottleSetting{Paused: true} // sync: the first setting has been applied
+	done := make(chan struct{})
+	go func() {
+		limiter.wait(context.Background(), 1, 0)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected a paused search to block")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	control <- ThrottleSetting{}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the search to resume")
+	}
+
+	// Closing the control channel never leaves the workers paused
+	control <- ThrottleSetting{Paused: true}
+	close(control)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	limiter.wait(ctx, 1, 0)
+	if ctx.Err() != nil {
+		t.Error("Expected closing the control channel to resume the search")
+	}
+}
+
+func TestSmartBruteForceStrategy_RangeSearch_MaxRate(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(1000001), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(7777777), HashMessage([]byte("message 2"))),
+	}
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.MaxRate = 1000
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, tested := strategy.rangeSearch(ctx, signatures, publicKey, [2]int{1, 1}, [2]int{0, 1 << 30}, 1, 2)
+	if tested == 0 || tested > 500 {
+		t.Errorf("Expected ~300 candidates in 300ms at 1000/sec, got %d", tested)
+	}
+}
@@ -0,0 +1,153 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"io"
+	"log"
+	"runtime"
+	"time"
+)
+
+// tuneChunkSizes are the chunk sizes AutoTune tries after the worker count.
+var tuneChunkSizes = []int64{256, rangeFlushInterval, 4096}
+
+// tuneTrials is how many trial runs AutoTune splits its duration into.
+const tuneTrials = 5
+
+// Tuning is a parallel range search configuration, as chosen by AutoTune.
+type Tuning struct {
+	// Workers is the number of parallel workers
+	Workers int
+
+	// ChunkSize is how many candidates a worker tries between flushing its counts and
+	// checking for cancellation
+	ChunkSize int64
+
+	// Rate is the throughput measured with this configuration
+	Rate SearchRate
+}
+
+// AutoTune measures the parallel range search's throughput, as MeasureSearchRate does,
+// under a few worker counts and chunk sizes and returns the fastest configuration. It
+// tries half, once and twice the given worker count at the default chunk size, then
+// smaller and larger chunks with the fastest count, splitting duration evenly between
+// the trials. RangeConfig.AutoTune runs the same trials on the search's own signatures
+// and range instead of the synthetic pair.
+//
+// Args:
+//   - duration: How long to measure in total
+//   - workers: Worker count to tune around (0 = auto-detect based on CPU cores)
+func AutoTune(ctx context.Context, duration time.Duration, workers int) Tuning {
+	quiet := log.New(io.Discard, "", 0)
+	return autoTune(ctx, duration, workers, func(ctx context.Context, trial time.Duration, workers int, chunk int64) SearchRate {
+		return measureSearchRate(ctx, trial, workers, chunk, quiet)
+	})
+}
+
+// autoTune is AutoTune with each trial run by measure.
+func autoTune(ctx context.Context, duration time.Duration, workers int, measure func(ctx context.Context, trial time.Duration, workers int, chunk int64) SearchRate) Tuning {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	trial := duration / tuneTrials
+
+	var best Tuning
+	try := func(workers int, chunk int64) {
+		if ctx.Err() != nil {
+			return
+		}
+		rate := measure(ctx, trial, workers, chunk)
+		if best.Workers == 0 || rate.PerSecond() > best.Rate.PerSecond() {
+			best = Tuning{Workers: workers, ChunkSize: chunk, Rate: rate}
+		}
+	}
+
+	counts := []int{workers}
+	if workers > 1 {
+		counts = append(counts, workers/2)
+	}
+	counts = append(counts, 2*workers)
+	for _, n := range counts {
+		try(n, rangeFlushInterval)
+	}
+	for _, chunk := range tuneChunkSizes {
+		if chunk != rangeFlushInterval {
+			try(best.Workers, chunk)
+		}
+	}
+	if best.Workers == 0 {
+		best = Tuning{Workers: workers, ChunkSize: rangeFlushInterval}
+	}
+	return best
+}
+
+// tune runs the AutoTune trials once, before the first parallel range phase, if
+// RangeConfig.AutoTune is set. The trials search r on the search's signatures and public
+// key (see tuneTrial), so they time the kernel the phase runs: its verification,
+// arithmetic, candidate filter and a values.
+func (s *SmartBruteForceStrategy) tune(ctx context.Context, signatures []*Signature, publicKey []byte, r rangePhase) {
+	if s.RangeConfig.AutoTune <= 0 {
+		return
+	}
+	s.tuneOnce.Do(func() {
+		s.logger().Printf("Auto-tuning the range search on %s for %v", r.name, s.RangeConfig.AutoTune)
+		s.tuning = autoTune(ctx, s.RangeConfig.AutoTune, s.RangeConfig.NumWorkers, func(ctx context.Context, trial time.Duration, workers int, chunk int64) SearchRate {
+			return s.tuneTrial(ctx, trial, signatures, publicKey, r, workers, chunk)
+		})
+		s.logger().Printf("Auto-tuned: %d workers, chunks of %d candidates (%.0f candidates/sec)",
+			s.tuning.Workers, s.tuning.ChunkSize, s.tuning.Rate.PerSecond())
+	})
+}
+
+// tuneTrial runs the parallel range search of r for duration, over again if it finishes
+// sooner, with workers workers flushing every chunk candidates, and reports its
+// throughput. The trial runs on a copy of the strategy's RangeConfig without quotas or
+// ThrottleControl, and without Metrics or Report, so it leaves the search's state alone.
+// It stops early if it finds the key, which the phase then finds again.
+func (s *SmartBruteForceStrategy) tuneTrial(ctx context.Context, duration time.Duration, signatures []*Signature, publicKey []byte, r rangePhase, workers int, chunk int64) SearchRate {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	trial := &SmartBruteForceStrategy{
+		RangeConfig: s.RangeConfig,
+		Logger:      log.New(io.Discard, "", 0),
+		tuning:      Tuning{Workers: workers, ChunkSize: chunk},
+	}
+	trial.RangeConfig.MaxCandidates, trial.RangeConfig.MaxCPUTime = 0, 0
+	trial.RangeConfig.ThrottleControl, trial.RangeConfig.SkipPhase = nil, nil
+
+	var tested int64
+	start := time.Now()
+	for ctx.Err() == nil {
+		result, n := trial.rangeSearch(ctx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs, workers)
+		tested += n
+		if result != nil || n == 0 {
+			break
+		}
+	}
+	return SearchRate{Workers: workers, Candidates: tested, Elapsed: time.Since(start)}
+}
+
+// workers returns the parallel range search's worker count: the tuned one, if any, or
+// RangeConfig.NumWorkers (0 = auto-detect).
+func (s *SmartBruteForceStrategy) workers() int {
+	if s.tuning.Workers > 0 {
+		return s.tuning.Workers
+	}
+	return s.RangeConfig.NumWorkers
+}
+
+// chunkSize returns how many candidates range search workers try between flushes: the
+// tuned chunk size, if any, or rangeFlushInterval.
+func (s *SmartBruteForceStrategy) chunkSize() int64 {
+	if s.tuning.ChunkSize > 0 {
+		return s.tuning.ChunkSize
+	}
+	return rangeFlushInterval
+}
+
+// Tuned returns the configuration RangeConfig.AutoTune chose, or the zero Tuning if it has
+// not run. Call it once Search has returned.
+func (s *SmartBruteForceStrategy) Tuned() Tuning {
+	return s.tuning
+}
@@ -0,0 +1,104 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestAutoTune(t *testing.T) {
+	tuning := AutoTune(context.Background(), 250*time.Millisecond, 2)
+	if tuning.Workers != 1 && tuning.Workers != 2 && tuning.Workers != 4 {
+		t.Errorf("Workers = %d, want 1, 2 or 4", tuning.Workers)
+	}
+	if tuning.ChunkSize != 256 && tuning.ChunkSize != rangeFlushInterval && tuning.ChunkSize != 4096 {
+		t.Errorf("ChunkSize = %d, want one of %v", tuning.ChunkSize, tuneChunkSizes)
+	}
+	if tuning.Rate.Workers != tuning.Workers || tuning.Rate.Candidates == 0 {
+		t.Errorf("Unexpected rate %+v", tuning.Rate)
+	}
+
+	// A cancelled context measures nothing but still returns a usable configuration
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if tuning := AutoTune(ctx, time.Second, 3); tuning.Workers != 3 || tuning.ChunkSize != rangeFlushInterval {
+		t.Errorf("Cancelled AutoTune = %+v, want 3 workers and the default chunk size", tuning)
+	}
+}
+
+func TestSmartBruteForceStrategy_AutoTune(t *testing.T) {
+	d := big.NewInt(0x5eed)
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(424242), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(424242+150000), HashMessage([]byte("message 2"))),
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	policy := &recordingPolicy{Policy: &GeometricExpansion{ARange: [2]int{1, 1}, Start: 10, Factor: 10, Max: 1000000}}
+	strategy := expansionTestStrategy(policy)
+	strategy.RangeConfig.AutoTune = 100 * time.Millisecond
+	result := strategy.Search(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the key from the b = 150000 phase, got %+v", result)
+	}
+
+	// Tuning runs before the first parallel phase, b in [-100000, 100000]
+	tuned := strategy.Tuned()
+	if tuned.Workers == 0 || tuned.ChunkSize == 0 {
+		t.Fatalf("Expected a tuned configuration, got %+v", tuned)
+	}
+	if len(policy.stats) != 6 {
+		t.Fatalf("Policy called %d times, want 6", len(policy.stats))
+	}
+	if stats := policy.stats[4]; stats.Tuning != (Tuning{}) {
+		t.Errorf("Tuning reported before the first parallel phase: %+v", stats.Tuning)
+	}
+	if stats := policy.stats[5]; stats.Tuning != tuned {
+		t.Errorf("Stats report tuning %+v, want %+v", stats.Tuning, tuned)
+	}
+}
+
+func TestSmartBruteForceStrategy_TuneOnSearch(t *testing.T) {
+	d := big.NewInt(0x5eed)
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(424242), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(999331), HashMessage([]byte("message 2"))),
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+
+	// The trials search the phase's range on the search's signatures, through its filter
+	var calls atomic.Int64
+	var outside atomic.Bool
+	strategy := NewSmartBruteForceStrategy()
+	strategy.Logger = log.New(io.Discard, "", 0)
+	strategy.RangeConfig.AutoTune = 100 * time.Millisecond
+	strategy.RangeConfig.CandidateFilter = func(a, b *big.Int, pair [2]int) bool {
+		calls.Add(1)
+		if a.Int64() != 3 || b.Int64() < -5000 || b.Int64() > 5000 {
+			outside.Store(true)
+		}
+		return true
+	}
+	r := rangePhase{[2]int{3, 3}, [2]int{-5000, 5000}, "test phase"}
+	strategy.tune(context.Background(), signatures, publicKey, r)
+
+	tuned := strategy.Tuned()
+	if tuned.Workers == 0 || tuned.Rate.Candidates == 0 {
+		t.Fatalf("Expected a tuned configuration, got %+v", tuned)
+	}
+	if calls.Load() == 0 {
+		t.Error("Trials never reached the search's CandidateFilter")
+	}
+	if outside.Load() {
+		t.Error("Trials tried candidates outside the phase's range")
+	}
+	if rate := strategy.searchRate(context.Background()); rate != tuned.Rate {
+		t.Errorf("searchRate = %+v, want the tuned rate %+v", rate, tuned.Rate)
+	}
+}
@@ -0,0 +1,96 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/testvectors"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("Output differs from %s (run go test -update if the change is intended):\n%s", path, got)
+	}
+}
+
+// TestVectors recovers the key of every embedded test vector, first with its known
+// relationship and then with a bounded search, whose results must match the golden file.
+func TestVectors(t *testing.T) {
+	strategy := NewSmartBruteForceStrategy().WithRangeConfig(RangeConfig{
+		ARange:        [2]int{-3, 3},
+		BRange:        [2]int{-16, 16},
+		MaxPairs:      10,
+		SkipZeroA:     true,
+		Deterministic: true,
+	})
+	client := NewClient(WithStrategy(strategy), WithLogger(log.New(io.Discard, "", 0)))
+	dir := t.TempDir()
+
+	var golden strings.Builder
+	for _, v := range testvectors.ECDSA() {
+		path, err := v.WriteSignatures(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signatures, err := (&JSONParser{}).ParseSignatures(path)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+		publicKey, err := ParsePublicKeyHex(v.PublicKey)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+
+		if v.Recoverable {
+			key, err := RecoverPrivateKey(signatures[v.Pair[0]], signatures[v.Pair[1]], big.NewInt(v.A), big.NewInt(v.B))
+			if err != nil || key.Cmp(v.Key()) != 0 {
+				t.Errorf("%s: the known relationship does not give the key (%v)", v.Name, err)
+			}
+			if ok, _ := VerifyRecoveredKey(v.Key(), publicKey); !ok {
+				t.Errorf("%s: the key does not verify", v.Name)
+			}
+		}
+
+		result, err := client.RecoverKey(context.Background(), path, v.PublicKey)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&golden, "%s: not recovered\n", v.Name)
+			if v.Recoverable {
+				t.Errorf("%s: %v", v.Name, err)
+			}
+		default:
+			fmt.Fprintf(&golden, "%s: %s k2 = %s*k1 + %s pair %v verified=%t\n", v.Name,
+				result.Pattern, result.Relationship.A, result.Relationship.B, result.SignaturePair, result.Verified)
+			if !v.Recoverable || result.PrivateKey.Cmp(v.Key()) != 0 {
+				t.Errorf("%s: recovered a wrong key", v.Name)
+			}
+		}
+	}
+	checkGolden(t, "vectors.golden", golden.String())
+}
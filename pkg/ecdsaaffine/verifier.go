@@ -0,0 +1,119 @@
+package ecdsaaffine
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// VerificationBackend selects how the brute-force strategy checks candidate keys
+// (RangeConfig.Verification, Client.WithVerification). Both backends multiply the base
+// point with the library's variable-time code over precomputed tables; neither pays
+// for constant-time arithmetic.
+type VerificationBackend int
+
+const (
+	// FastVerification checks keys with a KeyVerifier and screens range candidates by
+	// point addition (see keyStepper). It is the default.
+	FastVerification VerificationBackend = iota
+
+	// ReferenceVerification checks every candidate key with VerifyRecoveredKey, which
+	// derives and serializes the key's public key: several times slower, for
+	// cross-checking a search's results.
+	ReferenceVerification
+)
+
+// KeyVerifier checks recovered private keys against one verification target, parsed
+// once. For a public key, a check is a base point multiplication and a comparison with
+// the target point in Jacobian coordinates (X == x·Z², Y == y·Z³), without converting
+// the product to affine coordinates or serializing it. Other targets (Ethereum
+// addresses, x-only keys, Bitcoin scripts, key sets) are checked as VerifyRecoveredKey
+// does. A KeyVerifier is safe for concurrent use, so one serves all search workers.
+type KeyVerifier struct {
+	target   []byte
+	point    *secp256k1.JacobianPoint // the target as a point (Z = 1), nil if it is not a public key
+	rejected *keyCache                // keys known not to match, if set
+}
+
+// NewKeyVerifier parses a verification target in any encoding ParsePublicKey accepts.
+func NewKeyVerifier(publicKey []byte) (*KeyVerifier, error) {
+	if len(publicKey) == 0 {
+		return nil, errors.New("no public key to verify against")
+	}
+	if _, err := ParsePublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	return newKeyVerifier(publicKey), nil
+}
+
+// newReferenceVerifier returns a KeyVerifier that checks every key with VerifyRecoveredKey.
+func newReferenceVerifier(publicKey []byte) *KeyVerifier {
+	return &KeyVerifier{target: publicKey}
+}
+
+// newKeyVerifier is NewKeyVerifier for targets the strategies were given as is: an
+// invalid target yields a verifier that accepts no key, as VerifyRecoveredKey does.
+func newKeyVerifier(publicKey []byte) *KeyVerifier {
+	v := &KeyVerifier{target: publicKey}
+	if isTarget(publicKey) || len(publicKey) == EthereumAddressLen {
+		return v
+	}
+	normalized, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return v
+	}
+	pub, err := secp256k1.ParsePubKey(normalized)
+	if err != nil {
+		return v
+	}
+	v.point = new(secp256k1.JacobianPoint)
+	pub.AsJacobian(v.point)
+	return v
+}
+
+// Verify reports whether privateKey is the target's key. Keys outside [1, n-1] never are.
+func (v *KeyVerifier) Verify(privateKey *big.Int) bool {
+	if privateKey.Sign() <= 0 || privateKey.Cmp(Secp256k1CurveOrder) >= 0 {
+		return false
+	}
+	var key [32]byte
+	privateKey.FillBytes(key[:])
+	return v.verifyKey(&key)
+}
+
+// verifyKey is Verify for a key in [1, n-1] as 32 big-endian bytes. For a public key
+// target it does not allocate.
+func (v *KeyVerifier) verifyKey(key *[32]byte) bool {
+	if v.rejected.contains(key) {
+		return false
+	}
+
+	var verified bool
+	if v.point == nil {
+		verified, _ = VerifyRecoveredKey(new(big.Int).SetBytes(key[:]), v.target)
+	} else {
+		var d secp256k1.ModNScalar
+		d.SetBytes(key)
+		var p secp256k1.JacobianPoint
+		secp256k1.ScalarBaseMultNonConst(&d, &p)
+		verified = v.matches(&p)
+	}
+	if !verified {
+		v.rejected.add(key)
+	}
+	return verified
+}
+
+// matches compares a normalized Jacobian point with the target point.
+func (v *KeyVerifier) matches(p *secp256k1.JacobianPoint) bool {
+	if p.Z.IsZero() {
+		return false
+	}
+	var z2, want secp256k1.FieldVal
+	z2.SquareVal(&p.Z)
+	if !want.Mul2(&v.point.X, &z2).Normalize().Equals(&p.X) {
+		return false
+	}
+	return want.Mul2(&v.point.Y, &z2).Mul(&p.Z).Normalize().Equals(&p.Y)
+}
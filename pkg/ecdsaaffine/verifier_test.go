@@ -0,0 +1,76 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestKeyVerifier(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	pub := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey()
+	negated := new(big.Int).Sub(Secp256k1CurveOrder, d) // same x, other y
+
+	for name, target := range map[string][]byte{
+		"compressed":   pub.SerializeCompressed(),
+		"uncompressed": pub.SerializeUncompressed(),
+		"raw":          pub.SerializeUncompressed()[1:],
+		"address":      ethereumAddress(pub),
+	} {
+		verifier, err := NewKeyVerifier(target)
+		if err != nil {
+			t.Fatalf("%s: NewKeyVerifier: %v", name, err)
+		}
+		if (verifier.point != nil) != (name != "address") {
+			t.Errorf("%s: point parsed = %v", name, verifier.point != nil)
+		}
+		for _, tt := range []struct {
+			key  *big.Int
+			want bool
+		}{
+			{d, true},
+			{new(big.Int).Add(d, big.NewInt(1)), false},
+			{negated, false},
+			{big.NewInt(0), false},
+			{Secp256k1CurveOrder, false},
+		} {
+			if got := verifier.Verify(tt.key); got != tt.want {
+				t.Errorf("%s: Verify(%x) = %v, want %v", name, tt.key, got, tt.want)
+			}
+		}
+	}
+
+	for _, target := range [][]byte{nil, {0x02, 0x01}} {
+		if _, err := NewKeyVerifier(target); err == nil {
+			t.Errorf("Expected an error for target %x", target)
+		}
+	}
+}
+
+func TestClient_WithVerification(t *testing.T) {
+	d := big.NewInt(0xdeadbeef)
+	publicKey := secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed()
+	signatures := []*Signature{
+		signWithNonce(d, big.NewInt(1000), HashMessage([]byte("message 1"))),
+		signWithNonce(d, big.NewInt(1000+777), HashMessage([]byte("message 2"))),
+	}
+
+	// The backend carries over to a strategy set afterwards
+	strategy := NewSmartBruteForceStrategy()
+	client := NewClient().WithVerification(ReferenceVerification).WithStrategy(strategy)
+	if strategy.RangeConfig.Verification != ReferenceVerification {
+		t.Fatal("Expected the strategy to use reference verification")
+	}
+	verifier := strategy.verifier(publicKey)
+	if verifier.point != nil || newKeyStepper(signatures[0], signatures[1], verifier, nil) != nil {
+		t.Error("Expected reference verification without point comparison or stepping")
+	}
+
+	result, err := client.RecoverKeyFromSignatures(context.Background(), signatures, hex.EncodeToString(publicKey))
+	if err != nil || result.PrivateKey.Cmp(d) != 0 || !result.Verified {
+		t.Errorf("Expected the key with reference verification, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,260 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultWatchInterval is how often WatchSignatures checks for new records by default.
+const DefaultWatchInterval = 5 * time.Second
+
+// WatchOptions configures WatchSignatures.
+type WatchOptions struct {
+	// Interval between polls for new signatures (default DefaultWatchInterval)
+	Interval time.Duration
+
+	// Window pairs each new signature with at most this many signatures before it
+	// (0 = all). Flawed signers relate nonces of signatures produced close together
+	// (see RecoverKeyFromStore).
+	Window int
+
+	// OnPoll, if set, is called after every poll that found new signatures
+	OnPoll func(WatchProgress)
+}
+
+// WatchProgress reports one poll of WatchSignatures that found new signatures.
+type WatchProgress struct {
+	Signatures int // signatures read so far
+	New        int // signatures added by this poll
+	Pairs      int // new signature pairs searched
+}
+
+// WatchSignatures follows a signature file that grows by appending, or a directory of
+// such files, as harvesting pipelines write them, and runs the client's strategy on each
+// new signature pair as records arrive. A pair is searched once: each poll pairs only the
+// new signatures, with each other and with those read before, so the strategy's patterns
+// are never re-run on pairs already tested. Each pair is searched on its own (as with
+// WithPairs), so the strategy should be one whose search of a pair ends quickly, such as
+// a PatternStrategy or RangeStrategy; the full smart strategy's widest ranges do not.
+//
+// Files ending in .csv are read as CSV, with a header line; any other file as JSON Lines
+// (one signature object per line). The client's parser supplies the field names if it
+// is a JSONParser or CSVParser. Only complete lines are read, so a record being written
+// is picked up by the next poll. In a directory, files are read in name order and new
+// files are added as they appear.
+//
+// WatchSignatures returns when a key is verified, against publicKeyHex or the signer keys
+// in the records; unverified candidates are logged and the watch goes on. It returns
+// ctx's error when ctx is done. The result's SignaturePair indexes the signatures in the
+// order they were read.
+func (c *Client) WatchSignatures(ctx context.Context, path string, publicKeyHex string, opts WatchOptions) (*RecoveryResult, error) {
+	var publicKey []byte
+	if publicKeyHex != "" {
+		var err error
+		publicKey, err = ParseTarget(publicKeyHex, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	w := &watcher{path: path, files: make(map[string]*tailedFile)}
+	w.json, _ = c.parser.(*JSONParser)
+	if w.json == nil {
+		w.json = &JSONParser{ZField: "z"}
+	}
+	w.csv, _ = c.parser.(*CSVParser)
+	if w.csv == nil {
+		w.csv = &CSVParser{ZCol: "z"}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		tested := len(w.signatures)
+		if err := w.poll(); err != nil {
+			return nil, err
+		}
+
+		pairs := 0
+		for j := tested; j < len(w.signatures); j++ {
+			first := 0
+			if opts.Window > 0 {
+				first = max(0, j-opts.Window)
+			}
+			for i := first; i < j; i++ {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				pairs++
+				result := c.searchPair(ctx, w.signatures, i, j, publicKey)
+				if result == nil {
+					continue
+				}
+				if result.Verified {
+					return result, nil
+				}
+				loggerOrDefault(c.logger).Printf("Unverified candidate from signatures %d and %d (%s), still watching", i, j, result.Pattern)
+			}
+		}
+		if len(w.signatures) > tested && opts.OnPoll != nil {
+			opts.OnPoll(WatchProgress{Signatures: len(w.signatures), New: len(w.signatures) - tested, Pairs: pairs})
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// watcher reads the records appended to watched files since the last poll.
+type watcher struct {
+	path       string
+	json       *JSONParser
+	csv        *CSVParser
+	files      map[string]*tailedFile
+	order      []string
+	signatures []*Signature
+}
+
+// tailedFile is the read position in one watched file.
+type tailedFile struct {
+	offset  int64
+	line    int
+	csv     bool
+	columns *csvColumns // set once a CSV file's header is read
+}
+
+// poll appends the signatures in the complete lines written since the last poll.
+func (w *watcher) poll() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(w.path)
+		if err != nil {
+			return err
+		}
+		var added []string
+		for _, entry := range entries {
+			name := filepath.Join(w.path, entry.Name())
+			if _, ok := w.files[name]; ok || !entry.Type().IsRegular() || !watchedFile(name) {
+				continue
+			}
+			added = append(added, name)
+		}
+		sort.Strings(added)
+		for _, name := range added {
+			w.add(name)
+		}
+	} else if len(w.order) == 0 {
+		w.add(w.path)
+	}
+
+	for _, name := range w.order {
+		if err := w.read(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchedFile reports whether a file in a watched directory holds signatures.
+func watchedFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".ndjson", ".jsonl", ".json":
+		return true
+	}
+	return false
+}
+
+// add starts following a file from its beginning.
+func (w *watcher) add(name string) {
+	w.files[name] = &tailedFile{csv: strings.EqualFold(filepath.Ext(name), ".csv")}
+	w.order = append(w.order, name)
+}
+
+// read parses the complete lines of one file after its read position.
+func (w *watcher) read(name string) error {
+	f := w.files[name]
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < f.offset {
+		return fmt.Errorf("%s shrank from %d to %d bytes; watched files must only be appended to", name, f.offset, info.Size())
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+	if len(data) == 0 {
+		return nil
+	}
+	f.offset += int64(len(data))
+
+	for _, line := range strings.Split(string(data[:len(data)-1]), "\n") {
+		f.line++
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sig, err := w.parseLine(f, line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, f.line, err)
+		}
+		if sig != nil {
+			w.signatures = append(w.signatures, sig)
+		}
+	}
+	return nil
+}
+
+// parseLine parses one line of a file, returning nil for a CSV header.
+func (w *watcher) parseLine(f *tailedFile, line string) (*Signature, error) {
+	if !f.csv {
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+		var item map[string]interface{}
+		if err := decoder.Decode(&item); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return w.json.parseItem(item)
+	}
+
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.TrimLeadingSpace = true
+	record, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record: %w", err)
+	}
+	if f.columns == nil {
+		f.columns, err = w.csv.columns(record)
+		return nil, err
+	}
+	return f.columns.parse(record)
+}
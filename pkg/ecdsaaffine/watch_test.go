@@ -0,0 +1,131 @@
+package ecdsaaffine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// watchResult runs WatchSignatures in the background.
+func watchResult(ctx context.Context, client *Client, path, publicKeyHex string, progress chan<- WatchProgress) <-chan *RecoveryResult {
+	results := make(chan *RecoveryResult, 1)
+	go func() {
+		result, _ := client.WatchSignatures(ctx, path, publicKeyHex, WatchOptions{
+			Interval: 10 * time.Millisecond,
+			OnPoll:   func(p WatchProgress) { progress <- p },
+		})
+		results <- result
+	}()
+	return results
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_WatchSignatures(t *testing.T) {
+	d := big.NewInt(0x5eed)
+	k := big.NewInt(987654321)
+	publicKeyHex := fmt.Sprintf("%x", secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	line := func(k int64, message string) string {
+		sig := signWithNonce(d, big.NewInt(k), HashMessage([]byte(message)))
+		return fmt.Sprintf(`{"z":"0x%064x","r":"0x%064x","s":"0x%064x"}`+"\n", sig.Z, sig.R, sig.S)
+	}
+	client := NewClient(WithStrategy(NewPatternStrategy()), WithLogger(log.New(io.Discard, "", 0)))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Unrelated nonces first, then a counter nonce written in two parts
+	path := filepath.Join(t.TempDir(), "signatures.ndjson")
+	appendFile(t, path, line(111111, "a")+line(k.Int64(), "b"))
+	progress := make(chan WatchProgress, 10)
+	results := watchResult(ctx, client, path, publicKeyHex, progress)
+
+	if p := <-progress; p.Signatures != 2 || p.New != 2 || p.Pairs != 1 {
+		t.Errorf("Unexpected first poll %+v", p)
+	}
+	appendFile(t, path, line(4242421, "c"))
+	if p := <-progress; p.Signatures != 3 || p.New != 1 || p.Pairs != 2 {
+		t.Errorf("Expected only the new pairs searched, got %+v", p)
+	}
+	next := line(k.Int64()+1, "d")
+	appendFile(t, path, next[:40])
+	time.Sleep(50 * time.Millisecond)
+	appendFile(t, path, next[40:])
+
+	result := <-results
+	if result == nil || !result.Verified || result.PrivateKey.Cmp(d) != 0 {
+		t.Fatalf("Expected the verified key, got %+v", result)
+	}
+	if result.SignaturePair != [2]int{1, 3} {
+		t.Errorf("Expected pair [1 3], got %v", result.SignaturePair)
+	}
+}
+
+func TestClient_WatchSignatures_Directory(t *testing.T) {
+	d := big.NewInt(0xd1d1)
+	k := big.NewInt(13579)
+	publicKeyHex := fmt.Sprintf("%x", secp256k1.PrivKeyFromBytes(d.Bytes()).PubKey().SerializeCompressed())
+	csvFile := func(k int64, message string) string {
+		sig := signWithNonce(d, big.NewInt(k), HashMessage([]byte(message)))
+		return fmt.Sprintf("z,r,s\n0x%064x,0x%064x,0x%064x\n", sig.Z, sig.R, sig.S)
+	}
+	client := NewClient(WithStrategy(NewPatternStrategy()), WithLogger(log.New(io.Discard, "", 0)))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	appendFile(t, filepath.Join(dir, "batch-001.csv"), csvFile(k.Int64(), "a"))
+	appendFile(t, filepath.Join(dir, "notes.txt"), "not signatures\n")
+	progress := make(chan WatchProgress, 10)
+	results := watchResult(ctx, client, dir, publicKeyHex, progress)
+
+	if p := <-progress; p.Signatures != 1 || p.Pairs != 0 {
+		t.Errorf("Unexpected first poll %+v", p)
+	}
+	appendFile(t, filepath.Join(dir, "batch-002.csv"), csvFile(k.Int64()+1, "b"))
+
+	result := <-results
+	if result == nil || !result.Verified || result.SignaturePair != [2]int{0, 1} {
+		t.Fatalf("Expected the verified key from pair [0 1], got %+v", result)
+	}
+}
+
+func TestClient_WatchSignatures_Errors(t *testing.T) {
+	client := NewClient(WithStrategy(NewPatternStrategy()))
+	ctx := context.Background()
+
+	if _, err := client.WatchSignatures(ctx, filepath.Join(t.TempDir(), "missing.ndjson"), "", WatchOptions{}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "signatures.ndjson")
+	appendFile(t, path, "{\"z\":\"0x1\",\"r\":\"0x2\",\"s\":\"0x3\"}\nnot json\n")
+	if _, err := client.WatchSignatures(ctx, path, "", WatchOptions{}); err == nil {
+		t.Error("Expected an error for a malformed line")
+	}
+
+	// The watch ends with the context
+	os.WriteFile(path, nil, 0o600)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	if _, err := client.WatchSignatures(ctx, path, "", WatchOptions{Interval: 5 * time.Millisecond}); err != context.DeadlineExceeded {
+		t.Errorf("Expected the context's error, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package ecdsaaffine
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/base58"
+)
+
+const (
+	// DefaultXpubGap is the number of children derived at each level below an extended
+	// public key, the address gap limit wallets use.
+	DefaultXpubGap = 20
+
+	// DefaultXpubDepth is the number of levels derived below an extended public key.
+	// Two levels cover the receive and change chains (m/0/i, m/1/i) of an account xpub.
+	DefaultXpubDepth = 2
+
+	// maxXpubKeys bounds the key set; every candidate key is compared against all of it.
+	maxXpubKeys = 1 << 16
+)
+
+// Extended public key versions (xpub, ypub, zpub and their testnet counterparts); the
+// version only tells wallets which address type to derive, the keys are the same.
+var xpubVersions = map[uint32]string{
+	0x0488b21e: "xpub", 0x049d7cb2: "ypub", 0x04b24746: "zpub",
+	0x043587cf: "tpub", 0x044a5262: "upub", 0x045f1cf6: "vpub",
+}
+
+// DerivedKey is a public key derived from an extended public key.
+type DerivedKey struct {
+	Path      string // non-hardened path relative to the extended key, e.g. "m/0/5" ("m" is the key itself)
+	PublicKey []byte // compressed public key
+}
+
+// IsExtendedPublicKey reports whether s is a base58check BIP-32 extended public key.
+func IsExtendedPublicKey(s string) bool {
+	payload, err := base58.CheckDecode(strings.TrimSpace(s))
+	if err != nil || len(payload) != 78 {
+		return false
+	}
+	_, ok := xpubVersions[binary.BigEndian.Uint32(payload[:4])]
+	return ok
+}
+
+// DeriveXpubKeys derives the extended key itself and every descendant m/i1/.../id for
+// d = 1..depth with each index below gap, in breadth-first order: gap + gap^2 + ... keys.
+// Only non-hardened children can be derived from a public key. depth and gap <= 0 mean
+// DefaultXpubDepth and DefaultXpubGap.
+func DeriveXpubKeys(xpub string, depth, gap int) ([]DerivedKey, error) {
+	payload, err := base58.CheckDecode(strings.TrimSpace(xpub))
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %w", err)
+	}
+	if len(payload) != 78 {
+		return nil, fmt.Errorf("invalid extended public key: %d bytes, expected 78", len(payload))
+	}
+	return deriveXpubKeys(payload, depth, gap)
+}
+
+// FindXpubPath returns the path of the descendant of xpub whose key is privateKey's
+// public key, searching as DeriveXpubKeys does. ok is false if no derived key matches.
+func FindXpubPath(xpub string, depth, gap int, privateKey *big.Int) (path string, ok bool, err error) {
+	derived, err := DeriveXpubKeys(xpub, depth, gap)
+	if err != nil {
+		return "", false, err
+	}
+	var d secp256k1.ModNScalar
+	d.SetByteSlice(privateKey.Bytes())
+	compressed := string(secp256k1.NewPrivateKey(&d).PubKey().SerializeCompressed())
+	for _, key := range derived {
+		if string(key.PublicKey) == compressed {
+			return key.Path, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// deriveXpubKeys derives keys from a serialized BIP-32 extended public key.
+func deriveXpubKeys(payload []byte, depth, gap int) ([]DerivedKey, error) {
+	if depth <= 0 {
+		depth = DefaultXpubDepth
+	}
+	if gap <= 0 {
+		gap = DefaultXpubGap
+	}
+	if _, ok := xpubVersions[binary.BigEndian.Uint32(payload[:4])]; !ok {
+		return nil, fmt.Errorf("unsupported extended key version %x (private extended keys are not accepted)", payload[:4])
+	}
+	total, level := 1, 1
+	for d := 0; d < depth; d++ {
+		level *= gap
+		total += level
+		if total > maxXpubKeys {
+			return nil, fmt.Errorf("depth %d with gap %d derives more than %d keys", depth, gap, maxXpubKeys)
+		}
+	}
+
+	key, err := secp256k1.ParsePubKey(payload[45:78])
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %w", err)
+	}
+
+	type node struct {
+		path      string
+		key       *secp256k1.PublicKey
+		chainCode []byte
+	}
+	derived := []DerivedKey{{Path: "m", PublicKey: key.SerializeCompressed()}}
+	levelNodes := []node{{path: "m", key: key, chainCode: payload[13:45]}}
+	for d := 0; d < depth; d++ {
+		var next []node
+		for _, parent := range levelNodes {
+			for i := uint32(0); i < uint32(gap); i++ {
+				// An invalid child (probability ~2^-127) is skipped, as BIP-32 prescribes
+				child, chainCode, err := childKey(parent.key, parent.chainCode, i)
+				if err != nil {
+					continue
+				}
+				path := parent.path + "/" + strconv.FormatUint(uint64(i), 10)
+				derived = append(derived, DerivedKey{Path: path, PublicKey: child.SerializeCompressed()})
+				next = append(next, node{path: path, key: child, chainCode: chainCode})
+			}
+		}
+		levelNodes = next
+	}
+	return derived, nil
+}
+
+// childKey is BIP-32 CKDpub for a non-hardened index.
+func childKey(parent *secp256k1.PublicKey, chainCode []byte, index uint32) (*secp256k1.PublicKey, []byte, error) {
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(parent.SerializeCompressed())
+	mac.Write(binary.BigEndian.AppendUint32(nil, index))
+	sum := mac.Sum(nil)
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, nil, fmt.Errorf("child %d: tweak exceeds the group order", index)
+	}
+	child, err := addTweak(parent, &tweak)
+	if err != nil {
+		return nil, nil, fmt.Errorf("child %d: %w", index, err)
+	}
+	return child, sum[32:], nil
+}
@@ -0,0 +1,96 @@
+package ecdsaaffine
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+)
+
+// BIP-32 test vector 1: m/0H and its child m/0H/1, derivable without private keys
+const (
+	testXpub      = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+	testXpubChild = "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"
+)
+
+func TestDeriveXpubKeys(t *testing.T) {
+	child, err := DeriveXpubKeys(testXpubChild, 1, 3)
+	if err != nil {
+		t.Fatalf("DeriveXpubKeys failed: %v", err)
+	}
+	derived, err := DeriveXpubKeys(testXpub, 2, 3)
+	if err != nil {
+		t.Fatalf("DeriveXpubKeys failed: %v", err)
+	}
+	if len(derived) != 1+3+9 {
+		t.Fatalf("Expected %d keys, got %d", 1+3+9, len(derived))
+	}
+
+	wantPaths := []string{"m", "m/0", "m/1", "m/2", "m/0/0", "m/0/1"}
+	for i, want := range wantPaths {
+		if derived[i].Path != want {
+			t.Errorf("derived[%d].Path = %q, expected %q", i, derived[i].Path, want)
+		}
+	}
+	if derived[len(derived)-1].Path != "m/2/2" {
+		t.Errorf("Last path = %q, expected m/2/2", derived[len(derived)-1].Path)
+	}
+	if !bytes.Equal(derived[2].PublicKey, child[0].PublicKey) {
+		t.Errorf("m/1 = %x, expected the key of the BIP-32 vector %x", derived[2].PublicKey, child[0].PublicKey)
+	}
+	// m/1/j of the parent is m/j of the child
+	if !bytes.Equal(derived[4+3+1].PublicKey, child[2].PublicKey) {
+		t.Errorf("m/1/1 = %x, expected %x", derived[4+3+1].PublicKey, child[2].PublicKey)
+	}
+
+	if _, err := DeriveXpubKeys(testXpub, 4, 20); err == nil {
+		t.Error("Expected an error for a key set over the limit")
+	}
+	if IsExtendedPublicKey("xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi") {
+		t.Error("Expected a private extended key to be rejected")
+	}
+	if !IsExtendedPublicKey(testXpub) {
+		t.Error("Expected the test xpub to be recognized")
+	}
+}
+
+func TestFindXpubPath(t *testing.T) {
+	// Private key of m/0H/1 in BIP-32 test vector 1, i.e. m/1 below testXpub
+	d, _ := new(big.Int).SetString("3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", 16)
+
+	path, ok, err := FindXpubPath(testXpub, 2, 3, d)
+	if err != nil || !ok || path != "m/1" {
+		t.Errorf("FindXpubPath = %q, %v, %v; expected m/1", path, ok, err)
+	}
+	if _, ok, err := FindXpubPath(testXpub, 2, 3, big.NewInt(1)); ok || err != nil {
+		t.Errorf("FindXpubPath(1) = %v, %v; expected no match", ok, err)
+	}
+
+	target, err := ParseTarget(testXpub, 2, 3)
+	if err != nil {
+		t.Fatalf("ParseTarget failed: %v", err)
+	}
+	if len(target) != 33*(1+3+9) {
+		t.Fatalf("Target holds %d bytes, expected %d", len(target), 33*(1+3+9))
+	}
+	if ok, err := VerifyRecoveredKey(d, target); !ok || err != nil {
+		t.Errorf("Expected the descendant's key to match the xpub target (%v)", err)
+	}
+}
+
+func TestClient_RecoverKeyFromSignatures_Xpub(t *testing.T) {
+	d, _ := new(big.Int).SetString("3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", 16)
+	k1, _ := new(big.Int).SetString("6e3f8df3b5c8f4fbc7f2e6d9f3f4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6", 16)
+	signatures := []*Signature{
+		signWithNonce(d, k1, HashMessage([]byte("message 1"))),
+		signWithNonce(d, new(big.Int).Add(k1, big.NewInt(1)), HashMessage([]byte("message 2"))),
+	}
+
+	result, err := NewClient().RecoverKeyFromSignatures(context.Background(), signatures, testXpub)
+	if err != nil {
+		t.Fatalf("RecoverKeyFromSignatures failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(d) != 0 || !result.Verified {
+		t.Errorf("Expected verified key %x, got %x (verified=%v)", d, result.PrivateKey, result.Verified)
+	}
+}
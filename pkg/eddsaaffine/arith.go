@@ -0,0 +1,147 @@
+package eddsaaffine
+
+import (
+	"math/big"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/modn"
+)
+
+// ArithmeticBackend selects the modular arithmetic the range search recovers candidate
+// keys with (RangeConfig.Arithmetic). Both backends recover the same keys.
+type ArithmeticBackend int
+
+const (
+	// AutoArithmetic uses FixedArithmetic on platforms with a 64×64-bit multiply
+	// instruction (amd64, arm64 and other 64-bit platforms) and BigArithmetic elsewhere.
+	// It is the default.
+	AutoArithmetic ArithmeticBackend = iota
+
+	// BigArithmetic recovers every candidate key with RecoverPrivateKey (math/big), which
+	// also hashes both signatures' challenges again for each candidate.
+	BigArithmetic
+
+	// FixedArithmetic recovers candidate keys on fixed-width 256-bit Montgomery residues
+	// mod L: the challenges are hashed once per pair and the denominator's inverse
+	// computed once per a, leaving one multiplication and no allocation per candidate
+	// until a key is returned.
+	FixedArithmetic
+)
+
+// fixed reports whether the backend resolves to FixedArithmetic on this platform.
+func (b ArithmeticBackend) fixed() bool {
+	return b == FixedArithmetic || b == AutoArithmetic && modn.Native()
+}
+
+// pairRecovery recovers keys from one signature pair, as RecoverPrivateKey does, in
+// fixed-width arithmetic:
+//
+//	x = (s2 - a·s1 - b) / (h2 - a·h1) mod L
+//
+// For a fixed a, s2 - a·s1 and the denominator's inverse do not depend on b; they are
+// kept for calls with the same a (the range search's enumeration order). A pairRecovery
+// is not safe for concurrent use: each search worker has its own.
+type pairRecovery struct {
+	s1, s2, h1, h2 modn.Elem
+
+	a     int64
+	ready bool      // a is set
+	valid bool      // the denominator for a is invertible
+	base  modn.Elem // s2 - a·s1
+	inv   modn.Elem // (h2 - a·h1)^-1
+}
+
+// newPairRecovery hashes the pair's challenges for r2 = a·r1 + b.
+func newPairRecovery(sig1, sig2 *Signature) *pairRecovery {
+	l := modn.Ed25519L
+	p := &pairRecovery{}
+	l.SetBig(&p.s1, sig1.S)
+	l.SetBig(&p.s2, sig2.S)
+	l.SetBig(&p.h1, ComputeH(sig1.R, sig1.PublicKey, sig1.Message))
+	l.SetBig(&p.h2, ComputeH(sig2.R, sig2.PublicKey, sig2.Message))
+	return p
+}
+
+// pairRecovery returns a pairRecovery for (sig1, sig2), or nil when the strategy
+// recovers keys with BigArithmetic.
+func (s *SmartBruteForceStrategy) pairRecovery(sig1, sig2 *Signature) *pairRecovery {
+	if !s.RangeConfig.Arithmetic.fixed() {
+		return nil
+	}
+	return newPairRecovery(sig1, sig2)
+}
+
+// setA prepares the terms that depend on a alone, and reports whether any key solves
+// the pair for it.
+func (p *pairRecovery) setA(a int64) bool {
+	if p.ready && p.a == a {
+		return p.valid
+	}
+	l := modn.Ed25519L
+	p.a, p.ready, p.valid = a, true, false
+
+	var aElem, den modn.Elem
+	l.SetInt64(&aElem, a)
+	l.Mul(&den, &aElem, &p.h1)
+	l.Sub(&den, &p.h2, &den)
+	if l.Inverse(&p.inv, &den) == nil {
+		return false
+	}
+	l.Mul(&p.base, &aElem, &p.s1)
+	l.Sub(&p.base, &p.s2, &p.base)
+	p.valid = true
+	return true
+}
+
+// key sets key to the key for (a, b) as 32 big-endian bytes, and reports whether there
+// is a key in [1, L-1]. It does not allocate.
+func (p *pairRecovery) key(a, b int64, key *[32]byte) bool {
+	if !p.setA(a) {
+		return false
+	}
+	l := modn.Ed25519L
+	var x modn.Elem
+	l.SetInt64(&x, b)
+	l.Sub(&x, &p.base, &x)
+	l.Mul(&x, &x, &p.inv)
+	if x.IsZero() {
+		return false
+	}
+	*key = l.Bytes(&x)
+	return true
+}
+
+// recoverRangeKey recovers the key for r2 = a·r1 + b from the pair (sig1, sig2) with
+// recovery, or with RecoverPrivateKey when recovery is nil. It returns nil when no key
+// in [1, L-1] solves the pair.
+func recoverRangeKey(sig1, sig2 *Signature, recovery *pairRecovery, a, b int64) *big.Int {
+	if recovery != nil {
+		var key [32]byte
+		if !recovery.key(a, b, &key) {
+			return nil
+		}
+		return new(big.Int).SetBytes(key[:])
+	}
+	priv, err := RecoverPrivateKey(sig1, sig2, big.NewInt(a), big.NewInt(b))
+	if err != nil || priv.Sign() <= 0 || priv.Cmp(Ed25519CurveOrder) >= 0 {
+		return nil
+	}
+	return priv
+}
+
+// verifiedRangeKey is recoverRangeKey for a candidate the verifier must accept: it
+// returns the key if the verifier accepts it, or nil. With a pairRecovery, a candidate
+// whose key is rejected costs no allocation.
+func verifiedRangeKey(verifier *KeyVerifier, sig1, sig2 *Signature, recovery *pairRecovery, a, b int64) *big.Int {
+	if recovery == nil {
+		priv := recoverRangeKey(sig1, sig2, nil, a, b)
+		if priv == nil || !verifier.Verify(priv) {
+			return nil
+		}
+		return priv
+	}
+	var key [32]byte
+	if !recovery.key(a, b, &key) || !verifier.verifyKey(&key) {
+		return nil
+	}
+	return new(big.Int).SetBytes(key[:])
+}
@@ -0,0 +1,151 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestPairRecovery_MatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() *big.Int { return new(big.Int).Rand(rng, Ed25519CurveOrder) }
+
+	a := big.NewInt(0xdeadbeef)
+	valid := []*Signature{
+		signWithNonce(a, big.NewInt(1000), []byte("message 1")),
+		signWithNonce(a, big.NewInt(3*1000-41), []byte("message 2")),
+	}
+	pairs := [][2]*Signature{
+		{valid[0], valid[1]},
+		{valid[1], valid[0]},
+		// The same signature twice has no key for a = 1 (zero denominator)
+		{valid[0], valid[0]},
+	}
+	for i := 0; i < 8; i++ {
+		// Arbitrary values, s not reduced mod L
+		sig1 := *valid[0]
+		sig1.R, sig1.S = random(), new(big.Int).Add(random(), Ed25519CurveOrder)
+		sig2 := *valid[1]
+		sig2.R, sig2.S = random(), random()
+		pairs = append(pairs, [2]*Signature{&sig1, &sig2})
+	}
+
+	as := []int64{-100, -3, -1, 0, 1, 2, 3, 977, math.MinInt64, math.MaxInt64}
+	bs := []int64{-1000, -41, -1, 0, 1, 41, 123456789, math.MinInt64, math.MaxInt64}
+	for p, pair := range pairs {
+		recovery := newPairRecovery(pair[0], pair[1])
+		for _, coeff := range as {
+			for _, offset := range bs {
+				want, err := RecoverPrivateKey(pair[0], pair[1], big.NewInt(coeff), big.NewInt(offset))
+				var key [32]byte
+				if !recovery.key(coeff, offset, &key) {
+					if err == nil && want.Sign() != 0 {
+						t.Fatalf("pair %d, a=%d, b=%d: fixed has no key, big has %v", p, coeff, offset, want)
+					}
+					continue
+				}
+				if err != nil || new(big.Int).SetBytes(key[:]).Cmp(want) != 0 {
+					t.Fatalf("pair %d, a=%d, b=%d: fixed = %x, big = %v, %v", p, coeff, offset, key, want, err)
+				}
+
+				fixed := recoverRangeKey(pair[0], pair[1], recovery, coeff, offset)
+				reference := recoverRangeKey(pair[0], pair[1], nil, coeff, offset)
+				if (fixed == nil) != (reference == nil) || fixed != nil && fixed.Cmp(reference) != 0 {
+					t.Fatalf("pair %d, a=%d, b=%d: recoverRangeKey fixed = %v, big = %v", p, coeff, offset, fixed, reference)
+				}
+			}
+		}
+	}
+
+	if got := recoverRangeKey(valid[0], valid[1], newPairRecovery(valid[0], valid[1]), 3, -41); got == nil || got.Cmp(a) != 0 {
+		t.Errorf("recoverRangeKey(3, -41) = %v, want %v", got, a)
+	}
+}
+
+// TestPairRecovery_Allocs checks a rejected candidate costs no allocation with
+// FixedArithmetic: screened by its R points, recovered and verified.
+func TestPairRecovery_Allocs(t *testing.T) {
+	a := big.NewInt(0xdeadbeef)
+	sig1 := signWithNonce(a, big.NewInt(1000), []byte("message 1"))
+	sig2 := signWithNonce(a, big.NewInt(1777), []byte("message 2"))
+	verifier := newKeyVerifier(publicKeyFor(a))
+	recovery := newPairRecovery(sig1, sig2)
+	points := (&SmartBruteForceStrategy{RangeConfig: RangeConfig{PointFilter: true}}).decodeRPoints([]*Signature{sig1, sig2})
+	verifier.Verify(a) // fill the scratch pool
+
+	b := int64(0)
+	allocs := testing.AllocsPerRun(100, func() {
+		b++
+		if affineRHoldsInt64(points[0], points[1], 2, b) {
+			t.Fatal("Unexpected R point match")
+		}
+		if verifiedRangeKey(verifier, sig1, sig2, recovery, 2, b) != nil {
+			t.Fatal("Unexpected key")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations per candidate, want 0", allocs)
+	}
+}
+
+func TestAffineRHoldsInt64(t *testing.T) {
+	a := big.NewInt(0xdeadbeef)
+	sig1 := signWithNonce(a, big.NewInt(5000), []byte("message 1"))
+	sig2 := signWithNonce(a, new(big.Int).Sub(Ed25519CurveOrder, big.NewInt(2*5000+7)), []byte("message 2"))
+	R1, _ := DecodeR(sig1.R)
+	R2, _ := DecodeR(sig2.R)
+	for _, coeff := range []int64{-2, 2, math.MinInt64} {
+		for _, offset := range []int64{-7, 7, math.MaxInt64} {
+			want := affineRHolds(R1, R2, big.NewInt(coeff), big.NewInt(offset))
+			if got := affineRHoldsInt64(R1, R2, coeff, offset); got != want || got != (coeff == -2 && offset == -7) {
+				t.Errorf("affineRHoldsInt64(%d, %d) = %v, affineRHolds = %v", coeff, offset, got, want)
+			}
+		}
+	}
+}
+
+func TestSmartBruteForceStrategy_Arithmetic(t *testing.T) {
+	a := big.NewInt(0xdeadbeef)
+	publicKey := publicKeyFor(a)
+	signatures := []*Signature{
+		signWithNonce(a, big.NewInt(5000), []byte("message 1")),
+		signWithNonce(a, big.NewInt(7*5000+23), []byte("message 2")),
+	}
+
+	for _, backend := range []ArithmeticBackend{AutoArithmetic, BigArithmetic, FixedArithmetic} {
+		config := DefaultRangeConfig()
+		config.Arithmetic = backend
+		strategy := NewSmartBruteForceStrategy().WithRangeConfig(config)
+		if (strategy.pairRecovery(signatures[0], signatures[1]) != nil) != backend.fixed() {
+			t.Errorf("backend %d: pairRecovery does not match fixed() = %v", backend, backend.fixed())
+		}
+
+		aRange, bRange := [2]int{-10, 10}, [2]int{0, 50}
+		for name, result := range map[string]*RecoveryResult{
+			"sequential": strategy.rangeSearchSequential(context.Background(), signatures, publicKey, aRange, bRange, 1),
+			"parallel":   strategy.rangeSearchParallel(context.Background(), signatures, publicKey, aRange, bRange, 1, 4),
+		} {
+			if result == nil || result.PrivateKey.Cmp(a) != 0 || result.Relationship.A.Int64() != 7 || result.Relationship.B.Int64() != 23 {
+				t.Errorf("backend %d, %s: got %+v", backend, name, result)
+			}
+		}
+	}
+}
+
+func BenchmarkRecoverRangeKey(b *testing.B) {
+	a := big.NewInt(0xdeadbeef)
+	sig1 := signWithNonce(a, big.NewInt(1000), []byte("message 1"))
+	sig2 := signWithNonce(a, big.NewInt(1777), []byte("message 2"))
+	for _, backend := range []struct {
+		name     string
+		recovery *pairRecovery
+	}{{"big", nil}, {"fixed", newPairRecovery(sig1, sig2)}} {
+		b.Run(backend.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				recoverRangeKey(sig1, sig2, backend.recovery, 1, int64(i))
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package eddsaaffine
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// benchSignatures returns count signatures with unrelated nonces, so no search phase finds the key.
+func benchSignatures(a *big.Int, count int) []*Signature {
+	var signatures []*Signature
+	for i := 0; i < count; i++ {
+		r := new(big.Int).Lsh(big.NewInt(int64(1000+i)), 200)
+		r.Add(r, big.NewInt(int64(i*i*7919)))
+		signatures = append(signatures, signWithNonce(a, r, []byte{'m', byte(i)}))
+	}
+	return signatures
+}
+
+// quietLogs silences the search progress logging for the duration of a benchmark.
+func quietLogs(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+func BenchmarkRecoverPrivateKey(b *testing.B) {
+	signatures := benchSignatures(big.NewInt(0xdeadbeef), 2)
+	a, offset := big.NewInt(3), big.NewInt(-57)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RecoverPrivateKey(signatures[0], signatures[1], a, offset)
+	}
+}
+
+func BenchmarkVerifyRecoveredKey(b *testing.B) {
+	key := big.NewInt(0xdeadbeef)
+	publicKey := publicKeyFor(key)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyRecoveredKey(key, publicKey)
+	}
+}
+
+func BenchmarkKeyVerifier(b *testing.B) {
+	key := big.NewInt(0xdeadbeef)
+	verifier, _ := NewKeyVerifier(publicKeyFor(key))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifier.Verify(key)
+	}
+}
+
+func BenchmarkKeyVerifier_Parallel(b *testing.B) {
+	key := big.NewInt(0xdeadbeef)
+	verifier, _ := NewKeyVerifier(publicKeyFor(key))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			verifier.Verify(key)
+		}
+	})
+}
+
+func BenchmarkTryCommonPatterns(b *testing.B) {
+	quietLogs(b)
+	key := big.NewInt(0xdeadbeef)
+	publicKey := publicKeyFor(key)
+	signatures := benchSignatures(key, 5)
+	strategy := NewSmartBruteForceStrategy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if strategy.tryCommonPatterns(context.Background(), signatures, publicKey) != nil {
+			b.Fatal("Unexpected recovery")
+		}
+	}
+}
+
+func BenchmarkRangeSearch(b *testing.B) {
+	quietLogs(b)
+	key := big.NewInt(0xdeadbeef)
+	publicKey := publicKeyFor(key)
+	signatures := benchSignatures(key, 2)
+	strategy := NewSmartBruteForceStrategy()
+	var tested int64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, n := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{1, 2}, [2]int{-500, 500}, 1, 0)
+		tested += n
+	}
+	b.ReportMetric(float64(tested)/b.Elapsed().Seconds(), "candidates/sec")
+}
+
+// TestMeasureSearchRate checks the measurement itself; throughput regressions are tracked
+// with BenchmarkRangeSearch, not with a floor that a loaded CI machine can miss.
+func TestMeasureSearchRate(t *testing.T) {
+	rate := MeasureSearchRate(context.Background(), 200*time.Millisecond, 1)
+	if rate.Workers != 1 {
+		t.Errorf("Expected 1 worker, got %d", rate.Workers)
+	}
+	if rate.Candidates == 0 || rate.Elapsed <= 0 {
+		t.Errorf("Expected candidates tried over some time, got %+v", rate)
+	}
+	if est := rate.Estimate(rate.PerSecond() * 10); est < 9*time.Second || est > 11*time.Second {
+		t.Errorf("Expected Estimate of 10s worth of candidates to be ~10s, got %v", est)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"math/big"
 	"runtime"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"filippo.io/edwards25519"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/lifecycle"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/metrics"
 )
 
 // SmartBruteForceStrategy implements a multi-phase brute-force strategy
@@ -16,6 +19,16 @@ import (
 type SmartBruteForceStrategy struct {
 	RangeConfig   RangeConfig
 	PatternConfig PatternConfig
+
+	// Metrics, if set, is updated as the search runs (see pkg/metrics)
+	Metrics *metrics.Search
+
+	throttleOnce sync.Once
+	limiter      *throttle
+	quotaOnce    sync.Once
+	budget       *quota
+	tuneOnce     sync.Once
+	tuning       Tuning
 }
 
 // NewSmartBruteForceStrategy creates a new smart brute-force strategy with default settings.
@@ -38,6 +51,32 @@ func (s *SmartBruteForceStrategy) WithPatternConfig(config PatternConfig) *Smart
 	return s
 }
 
+// rangeThrottle returns the throttle shared by all range searches of this strategy, so
+// changes received on ThrottleControl carry over from one phase to the next.
+func (s *SmartBruteForceStrategy) rangeThrottle() *throttle {
+	s.throttleOnce.Do(func() {
+		s.limiter = newThrottle(s.RangeConfig)
+		if s.limiter == nil && s.PatternConfig.Inject != nil {
+			s.limiter = &throttle{} // held while injected patterns are tried
+		}
+	})
+	return s.limiter
+}
+
+// rangeQuota returns the quota shared by all range searches of this strategy.
+func (s *SmartBruteForceStrategy) rangeQuota() *quota {
+	s.quotaOnce.Do(func() {
+		s.budget = newQuota(s.RangeConfig)
+	})
+	return s.budget
+}
+
+// QuotaExceeded reports whether the strategy's searches stopped at RangeConfig.MaxCandidates
+// or MaxCPUTime.
+func (s *SmartBruteForceStrategy) QuotaExceeded() bool {
+	return s.rangeQuota().exceeded()
+}
+
 // Name returns the name of this strategy.
 func (s *SmartBruteForceStrategy) Name() string {
 	return "SmartBruteForce"
@@ -45,6 +84,15 @@ func (s *SmartBruteForceStrategy) Name() string {
 
 // Search implements the BruteForceStrategy interface.
 func (s *SmartBruteForceStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	result := s.search(ctx, signatures, publicKey)
+	if result != nil {
+		s.Metrics.KeyFound()
+	}
+	return result
+}
+
+// search runs the search phases in order.
+func (s *SmartBruteForceStrategy) search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
 	if len(signatures) < 2 {
 		return nil
 	}
@@ -52,15 +100,30 @@ func (s *SmartBruteForceStrategy) Search(ctx context.Context, signatures []*Sign
 	log.Printf("Starting EdDSA key recovery search with %d signatures", len(signatures))
 
 	// Phase 0: Check for same nonce reuse (fastest)
+	s.Metrics.SetPhase("Phase 0: same nonce reuse")
 	log.Println("Phase 0: Checking for same nonce reuse...")
 	if result := s.checkSameNonceReuse(signatures, publicKey); result != nil {
 		log.Printf("✅ Found same nonce reuse in signatures [%d, %d]", result.SignaturePair[0], result.SignaturePair[1])
 		return result
 	}
 	log.Println("No same nonce reuse found")
+	if result := s.tryInjected(ctx, signatures, publicKey); result != nil {
+		return result
+	}
+
+	// Phase 0b: Try the steps of nonces in arithmetic progression
+	if s.PatternConfig.Progressions {
+		s.Metrics.SetPhase("Phase 0b: arithmetic progressions")
+		log.Println("Phase 0b: Looking for nonces in arithmetic progression...")
+		if result := s.tryProgressions(ctx, signatures, publicKey); result != nil {
+			log.Printf("✅ Found progression '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+			return result
+		}
+	}
 
 	// Phase 1: Try common patterns
 	if s.PatternConfig.IncludeCommonPatterns {
+		s.Metrics.SetPhase(commonPatternsPhase)
 		log.Println("Phase 1: Trying common patterns...")
 		if result := s.tryCommonPatterns(ctx, signatures, publicKey); result != nil {
 			log.Printf("✅ Found pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
@@ -71,6 +134,7 @@ func (s *SmartBruteForceStrategy) Search(ctx context.Context, signatures []*Sign
 
 	// Phase 2: Try custom patterns
 	if len(s.PatternConfig.CustomPatterns) > 0 {
+		s.Metrics.SetPhase("Phase 2: custom patterns")
 		log.Printf("Phase 2: Trying %d custom patterns...", len(s.PatternConfig.CustomPatterns))
 		if result := s.tryCustomPatterns(ctx, signatures, publicKey); result != nil {
 			log.Printf("✅ Found custom pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
@@ -79,11 +143,143 @@ func (s *SmartBruteForceStrategy) Search(ctx context.Context, signatures []*Sign
 		log.Println("No custom patterns matched")
 	}
 
-	// Phase 3: Adaptive range search
-	log.Println("Phase 3: Starting adaptive range search (brute-force)...")
+	if result := s.tryInjected(ctx, signatures, publicKey); result != nil {
+		return result
+	}
+
+	// Phase 3: Solve counter offsets (a=1) on the curve with baby-step giant-step
+	if s.RangeConfig.CounterOffsetBound > 0 {
+		s.Metrics.SetPhase("Phase 3: counter offset BSGS")
+		log.Printf("Phase 3: Solving R2 - R1 = b*B for |b| <= %d...", s.RangeConfig.CounterOffsetBound)
+		bsgs := &BSGSStrategy{Bound: s.RangeConfig.CounterOffsetBound, MaxPairs: s.RangeConfig.MaxPairs}
+		if result := bsgs.Search(ctx, signatures, publicKey); result != nil {
+			log.Printf("✅ Found counter offset '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+			return result
+		}
+		log.Println("No counter offset found")
+	}
+
+	if result := s.tryInjected(ctx, signatures, publicKey); result != nil {
+		return result
+	}
+
+	// Phase 4: Derive b from signature triples (no b iteration)
+	if s.RangeConfig.DeriveB && len(signatures) >= 3 {
+		s.Metrics.SetPhase("Phase 4: derived b")
+		log.Printf("Phase 4: Deriving b from signature triples for a in [%d, %d]...", s.RangeConfig.ARange[0], s.RangeConfig.ARange[1])
+		if result := s.deriveOffsetSearch(ctx, signatures, publicKey); result != nil {
+			log.Printf("✅ Derived pattern '%s' in signatures [%d, %d]", result.Pattern, result.SignaturePair[0], result.SignaturePair[1])
+			return result
+		}
+		log.Println("No small b derived from signature triples")
+	}
+
+	// Phase 5: Adaptive range search
+	log.Println("Phase 5: Starting adaptive range search (brute-force)...")
 	return s.adaptiveRangeSearch(ctx, signatures, publicKey)
 }
 
+// decodeRPoints decodes every signature's R once for the point filter.
+// Returns nil when the filter is disabled; entries are nil for R values that do not decode.
+func (s *SmartBruteForceStrategy) decodeRPoints(signatures []*Signature) []*edwards25519.Point {
+	if !s.RangeConfig.PointFilter {
+		return nil
+	}
+	points := make([]*edwards25519.Point, len(signatures))
+	for i, sig := range signatures {
+		if p, err := DecodeR(sig.R); err == nil {
+			points[i] = p
+		}
+	}
+	return points
+}
+
+// rejectedByRPoints reports whether R_j = a*R_i + b*B fails for the pair (i, j).
+// Pairs whose R values could not be decoded are never rejected.
+func (s *SmartBruteForceStrategy) rejectedByRPoints(points []*edwards25519.Point, i, j int, a, b *big.Int) bool {
+	if points == nil || points[i] == nil || points[j] == nil {
+		return false
+	}
+	return !affineRHolds(points[i], points[j], a, b)
+}
+
+// rejectedByRPointsInt64 is rejectedByRPoints for the range search's int64 a and b,
+// without allocation.
+func (s *SmartBruteForceStrategy) rejectedByRPointsInt64(points []*edwards25519.Point, i, j int, a, b int64) bool {
+	if points == nil || points[i] == nil || points[j] == nil {
+		return false
+	}
+	return !affineRHoldsInt64(points[i], points[j], a, b)
+}
+
+// deriveOffsetSearch solves (private key, b) for every a in ARange over signature triples
+// (i, j, k) with i < j < k, accepting a solution only when |b| is small.
+// A random triple or wrong a produces b uniformly distributed mod q, so a small b is a
+// strong signal on its own; the public key, if provided, makes the result definitive.
+func (s *SmartBruteForceStrategy) deriveOffsetSearch(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	bits := s.RangeConfig.MaxDerivedBBits
+	if bits <= 0 {
+		bits = 64
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+
+	maxTriples := s.RangeConfig.MaxPairs
+	if maxTriples <= 0 {
+		maxTriples = DefaultRangeConfig().MaxPairs
+	}
+
+	tripleCount := 0
+	for i := 0; i < len(signatures) && tripleCount < maxTriples; i++ {
+		for j := i + 1; j < len(signatures) && tripleCount < maxTriples; j++ {
+			for k := j + 1; k < len(signatures) && tripleCount < maxTriples; k++ {
+				select {
+				case <-ctx.Done():
+					return nil
+				default:
+				}
+				tripleCount++
+
+				for a := s.RangeConfig.ARange[0]; a <= s.RangeConfig.ARange[1]; a++ {
+					if a == 0 && s.RangeConfig.SkipZeroA {
+						continue
+					}
+					aBig := big.NewInt(int64(a))
+
+					priv, b, err := DeriveKeyAndOffset(signatures[i], signatures[j], signatures[k], aBig)
+					if err != nil {
+						continue
+					}
+					if new(big.Int).Abs(b).Cmp(bound) > 0 {
+						continue
+					}
+					if priv.Sign() <= 0 || priv.Cmp(Ed25519CurveOrder) >= 0 {
+						continue
+					}
+
+					verified := false
+					if len(publicKey) > 0 {
+						verified, _ = VerifyRecoveredKey(priv, publicKey)
+						if !verified {
+							continue
+						}
+					}
+
+					log.Printf("Derived b=%s for a=%d from signature triple [%d, %d, %d]", b.Text(10), a, i, j, k)
+					return &RecoveryResult{
+						PrivateKey:    priv,
+						Relationship:  AffineRelationship{A: aBig, B: b},
+						SignaturePair: [2]int{i, j},
+						Verified:      verified,
+						Pattern:       fmt.Sprintf("derived_a%d_b%s", a, b.Text(10)),
+					}
+				}
+			}
+		}
+	}
+	log.Printf("Checked %d signature triples", tripleCount)
+	return nil
+}
+
 // checkSameNonceReuse checks for identical R values (same nonce reuse).
 // IMPORTANT: Same R values don't guarantee same nonce - we must verify the recovered key.
 // This function tries ALL pairs with same R and returns the first one that verifies.
@@ -164,6 +360,28 @@ func (s *SmartBruteForceStrategy) tryCommonPatterns(ctx context.Context, signatu
 	return nil
 }
 
+// tryProgressions tries b = step for each distinct step FindProgressions detects.
+func (s *SmartBruteForceStrategy) tryProgressions(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	progressions := FindProgressions(signatures)
+	log.Printf("Found %d nonce progressions", len(progressions))
+
+	tried := make(map[string]bool)
+	for _, progression := range progressions {
+		if ctx.Err() != nil {
+			return nil
+		}
+		step := progression.B.Text(10)
+		if tried[step] {
+			continue
+		}
+		tried[step] = true
+		if result := s.tryPattern(signatures, publicKey, big.NewInt(1), progression.B, "progression_b"+step); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
 // tryCustomPatterns tries user-defined custom patterns.
 func (s *SmartBruteForceStrategy) tryCustomPatterns(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
 	for _, pattern := range s.PatternConfig.CustomPatterns {
@@ -181,13 +399,62 @@ func (s *SmartBruteForceStrategy) tryCustomPatterns(ctx context.Context, signatu
 }
 
 // tryPattern tries a specific (a, b) pattern across ALL signature pairs.
-// IMPORTANT: This checks every pair (i, j) where i < j, regardless of R values.
+// IMPORTANT: This checks every pair (i, j) where i < j, regardless of R values, and with
+// RangeConfig.BothDirections and |a| >= 2 also r_i = a*r_j + b.
 // Each pair is tested independently - we don't assume all pairs have the same relationship.
 func (s *SmartBruteForceStrategy) tryPattern(signatures []*Signature, publicKey []byte, a, b *big.Int, patternName string) *RecoveryResult {
+	a, b = centered(a), centered(b)
 	totalPairs := len(signatures) * (len(signatures) - 1) / 2
 	log.Printf("Trying pattern '%s' (a=%s, b=%s) on all %d signature pairs", patternName, a.Text(10), b.Text(10), totalPairs)
 	checkedPairs := 0
 	lastLogTime := time.Now()
+	points := s.decodeRPoints(signatures)
+	verifier := newKeyVerifier(publicKey)
+	reverse := s.RangeConfig.BothDirections && a.CmpAbs(big.NewInt(1)) > 0
+
+	// attempt tries the pattern as r_second = a*r_first + b
+	attempt := func(first, second int) *RecoveryResult {
+		// r2 = a*r1 + b implies R2 = a*R1 + b*B; reject candidates failing that
+		// before scalar recovery (no hashing, and exact even without a public key)
+		if s.rejectedByRPoints(points, first, second, a, b) {
+			return nil
+		}
+
+		// Try to recover private key using this pattern for this pair
+		priv, err := RecoverPrivateKey(signatures[first], signatures[second], a, b)
+		if err != nil {
+			// Recovery failed (e.g., denominator zero) - try next pair
+			return nil
+		}
+
+		// Check if recovered key is in valid range
+		if priv.Sign() <= 0 || priv.Cmp(Ed25519CurveOrder) >= 0 {
+			// Key out of range - try next pair
+			return nil
+		}
+
+		// Verify recovered key against public key
+		verified := false
+		if len(publicKey) > 0 {
+			verified = verifier.Verify(priv)
+			if !verified {
+				// Verification failed - this pair doesn't match this pattern, try next pair
+				return nil
+			}
+		} else {
+			// No public key provided - cannot verify in real-world scenario
+			// Set verified to false since we cannot confirm the key is correct
+			verified = false
+		}
+
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: a, B: b},
+			SignaturePair: [2]int{first, second},
+			Verified:      verified,
+			Pattern:       patternName,
+		}
+	}
 	
 	// Check ALL pairs (i, j) where i < j
 	for i := 0; i < len(signatures); i++ {
@@ -200,50 +467,16 @@ func (s *SmartBruteForceStrategy) tryPattern(signatures []*Signature, publicKey
 				log.Printf("  Progress: checked %d/%d pairs (%.1f%%)", checkedPairs, totalPairs, float64(checkedPairs)/float64(totalPairs)*100)
 				lastLogTime = now
 			}
-			
-			// NOTE: We cannot validate the affine relationship on R points directly
-			// because R is a curve point (32 bytes, compressed format), not a scalar.
-			// The affine relationship r2 = a*r1 + b holds on the nonce scalars, not on R points.
-			// We cannot extract the nonce scalar from R (that's the discrete log problem).
-			// Instead, we try the recovery and verify the result - if the relationship holds,
-			// the recovery will produce the correct private key.
-
-			// Try to recover private key using this pattern for this pair
-			priv, err := RecoverPrivateKey(signatures[i], signatures[j], a, b)
-			if err != nil {
-				// Recovery failed (e.g., denominator zero) - try next pair
-				continue
-			}
 
-			// Check if recovered key is in valid range
-			if priv.Sign() <= 0 || priv.Cmp(Ed25519CurveOrder) >= 0 {
-				// Key out of range - try next pair
-				continue
+			result := attempt(i, j)
+			if result == nil && reverse {
+				result = attempt(j, i)
 			}
-
-			// Verify recovered key against public key
-			verified := false
-			if len(publicKey) > 0 {
-				verified, _ = VerifyRecoveredKey(priv, publicKey)
-				if !verified {
-					// Verification failed - this pair doesn't match this pattern, try next pair
-					continue
-				}
-			} else {
-				// No public key provided - cannot verify in real-world scenario
-				// Set verified to false since we cannot confirm the key is correct
-				verified = false
-			}
-
-			// Found a verified match for this pattern!
-			log.Printf("✅ Found key with pattern '%s' after checking %d/%d pairs (signature pair [%d, %d])", 
-				patternName, checkedPairs, totalPairs, i, j)
-			return &RecoveryResult{
-				PrivateKey:    priv,
-				Relationship:  AffineRelationship{A: a, B: b},
-				SignaturePair: [2]int{i, j},
-				Verified:      verified,
-				Pattern:       patternName,
+			if result != nil {
+				// Found a verified match for this pattern!
+				log.Printf("✅ Found key with pattern '%s' after checking %d/%d pairs (signature pair [%d, %d])", 
+					patternName, checkedPairs, totalPairs, result.SignaturePair[0], result.SignaturePair[1])
+				return result
 			}
 		}
 	}
@@ -252,13 +485,28 @@ func (s *SmartBruteForceStrategy) tryPattern(signatures []*Signature, publicKey
 	return nil
 }
 
-// adaptiveRangeSearch performs an adaptive range search with expanding ranges.
-func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
-	ranges := []struct {
-		aRange [2]int
-		bRange [2]int
-		name   string
-	}{
+// commonPatternsPhase is the phase name the search reports (see Metrics) while it tries
+// the common patterns.
+const commonPatternsPhase = "Phase 1: common patterns"
+
+// parallelThreshold is the number of combinations per pair above which a range phase
+// runs on parallel workers; smaller phases are faster sequentially.
+const parallelThreshold = 100000
+
+// rangePhase is one step of the adaptive range search.
+type rangePhase struct {
+	aRange [2]int
+	bRange [2]int
+	name   string
+}
+
+// rangePhases returns the ranges adaptiveRangeSearch walks through, in order.
+// A configured range different from the defaults replaces the built-in schedule.
+func (s *SmartBruteForceStrategy) rangePhases() []rangePhase {
+	if s.RangeConfig.ARange != [2]int{-100, 100} || s.RangeConfig.BRange != [2]int{-100, 100} {
+		return []rangePhase{{s.RangeConfig.ARange, s.RangeConfig.BRange, "Custom range"}}
+	}
+	return []rangePhase{
 		{[2]int{1, 1}, [2]int{-10, 100}, "Phase 2a: a=1, small b"},
 		{[2]int{1, 1}, [2]int{-100, 1000}, "Phase 2b: a=1, medium b"},
 		{[2]int{1, 1}, [2]int{-1000, 10000}, "Phase 2c: a=1, larger b"},
@@ -267,17 +515,31 @@ func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signa
 		{[2]int{1, 10}, [2]int{-5000, 50000}, "Phase 3c: wider a, larger b"},
 		{[2]int{1, 100}, [2]int{-500000, 500000000}, "Phase 4 very wide search"},
 	}
+}
 
-	// Use the configured range if it's different from defaults
-	if s.RangeConfig.ARange != [2]int{-100, 100} || s.RangeConfig.BRange != [2]int{-100, 100} {
-		ranges = []struct {
-			aRange [2]int
-			bRange [2]int
-			name   string
-		}{
-			{s.RangeConfig.ARange, s.RangeConfig.BRange, "Custom range"},
-		}
+// rangeCombinations returns the number of (a, b) combinations per signature pair, counting
+// those tried in both directions twice.
+func (s *SmartBruteForceStrategy) rangeCombinations(aRange, bRange [2]int) int {
+	aCount := aRange[1] - aRange[0] + 1
+	if s.RangeConfig.SkipZeroA && aRange[0] <= 0 && aRange[1] >= 0 {
+		aCount--
 	}
+	if s.bothDirections(aRange) {
+		aCount += max(0, aRange[1]-max(aRange[0], 2)+1) + max(0, min(aRange[1], -2)-aRange[0]+1)
+	}
+	bCount := bRange[1] - bRange[0] + 1
+	return aCount * bCount
+}
+
+// bothDirections reports whether the range search tries relationships with a in aRange the
+// other way round too (RangeConfig.BothDirections), which it does for |a| >= 2.
+func (s *SmartBruteForceStrategy) bothDirections(aRange [2]int) bool {
+	return s.RangeConfig.BothDirections && (aRange[0] < -1 || aRange[1] > 1)
+}
+
+// adaptiveRangeSearch performs an adaptive range search with expanding ranges.
+func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	ranges := s.rangePhases()
 
 	for _, r := range ranges {
 		select {
@@ -285,24 +547,32 @@ func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signa
 			return nil
 		default:
 		}
-
-		aCount := r.aRange[1] - r.aRange[0] + 1
-		if s.RangeConfig.SkipZeroA && r.aRange[0] <= 0 && r.aRange[1] >= 0 {
-			aCount--
+		if s.rangeQuota().exceeded() {
+			log.Println("Search quota exceeded, stopping the range search")
+			return nil
 		}
-		bCount := r.bRange[1] - r.bRange[0] + 1
-		totalCombinations := aCount * bCount
+		if result := s.tryInjected(ctx, signatures, publicKey); result != nil {
+			return result
+		}
+
+		totalCombinations := s.rangeCombinations(r.aRange, r.bRange)
+		s.Metrics.SetPhase(r.name)
 		log.Printf("%s: searching a in [%d, %d], b in [%d, %d] (~%d combinations)", r.name, r.aRange[0], r.aRange[1], r.bRange[0], r.bRange[1], totalCombinations)
 
 		// Use sequential search for smaller ranges (faster due to no goroutine overhead)
 		// Use parallel for larger ranges (Phase 3c and beyond)
-		useParallel := totalCombinations > 100000 // Threshold: use parallel for >100k combinations
+		useParallel := totalCombinations > parallelThreshold
 
+		phaseCtx, stopWatching := s.watchInjections(ctx, signatures, publicKey)
 		var result *RecoveryResult
 		if useParallel {
-			result = s.rangeSearchParallel(ctx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs, s.RangeConfig.NumWorkers)
+			s.tune(phaseCtx, signatures, publicKey, r)
+			result = s.rangeSearchParallel(phaseCtx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs, s.workers())
 		} else {
-			result = s.rangeSearchSequential(ctx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs)
+			result = s.rangeSearchSequential(phaseCtx, signatures, publicKey, r.aRange, r.bRange, s.RangeConfig.MaxPairs)
+		}
+		if injected := stopWatching(); result == nil {
+			result = injected
 		}
 
 		if result != nil {
@@ -317,6 +587,56 @@ func (s *SmartBruteForceStrategy) adaptiveRangeSearch(ctx context.Context, signa
 
 // rangeSearchSequential performs a sequential brute-force search (faster for smaller ranges).
 func (s *SmartBruteForceStrategy) rangeSearchSequential(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs int) *RecoveryResult {
+	points := s.decodeRPoints(signatures)
+	verifier := newKeyVerifier(publicKey)
+	limiter := s.rangeThrottle()
+	budget := s.rangeQuota()
+	batch := limiter.batchSizeFor(s.chunkSize())
+	var pending int64
+	resumed := time.Now()
+	s.Metrics.WorkerStarted()
+	defer s.Metrics.WorkerStopped()
+	defer func() {
+		s.Metrics.AddCandidates(pending)
+		s.Metrics.AddBusy(time.Since(resumed))
+	}()
+
+	// try checks r_second = a*r_first + b, recovered with the pair's pairRecovery in that
+	// order (nil for BigArithmetic)
+	try := func(first, second int, recovery *pairRecovery, a, b int64) *RecoveryResult {
+		// r2 = a*r1 + b implies R2 = a*R1 + b*B; reject candidates failing that
+		// before scalar recovery (no hashing, and exact even without a public key)
+		if s.rejectedByRPointsInt64(points, first, second, a, b) {
+			return nil
+		}
+
+		var priv *big.Int
+		verified := false
+		if len(publicKey) > 0 {
+			priv = verifiedRangeKey(verifier, signatures[first], signatures[second], recovery, a, b)
+			if priv == nil {
+				return nil
+			}
+			verified = true
+		} else {
+			// No public key provided - cannot verify in real-world scenario
+			// Set verified to false since we cannot confirm the key is correct
+			priv = recoverRangeKey(signatures[first], signatures[second], recovery, a, b)
+			if priv == nil {
+				return nil
+			}
+		}
+
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: big.NewInt(a), B: big.NewInt(b)},
+			SignaturePair: [2]int{first, second},
+			Verified:      verified,
+			Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
+		}
+	}
+	reverse := s.bothDirections(aRange)
+
 	pairCount := 0
 	for i := 0; i < len(signatures) && pairCount < maxPairs; i++ {
 		select {
@@ -327,49 +647,37 @@ func (s *SmartBruteForceStrategy) rangeSearchSequential(ctx context.Context, sig
 
 		for j := i + 1; j < len(signatures) && pairCount < maxPairs; j++ {
 			pairCount++
+			s.Metrics.AddPairs(1)
+			recovery := s.pairRecovery(signatures[i], signatures[j])
+			var backRecovery *pairRecovery
+			if reverse {
+				backRecovery = s.pairRecovery(signatures[j], signatures[i])
+			}
 
 			for a := aRange[0]; a <= aRange[1]; a++ {
 				if s.RangeConfig.SkipZeroA && a == 0 {
 					continue
 				}
-				aBig := big.NewInt(int64(a))
 				for b := bRange[0]; b <= bRange[1]; b++ {
-					bBig := big.NewInt(int64(b))
-
-					// NOTE: We cannot validate the affine relationship on R points directly
-					// because R is a curve point (32 bytes, compressed format), not a scalar.
-					// The affine relationship r2 = a*r1 + b holds on the nonce scalars, not on R points.
-					// We cannot extract the nonce scalar from R (that's the discrete log problem).
-					// Instead, we try the recovery and verify the result - if the relationship holds,
-					// the recovery will produce the correct private key.
-
-					priv, err := RecoverPrivateKey(signatures[i], signatures[j], aBig, bBig)
-					if err != nil {
-						continue
+					if pending++; pending >= batch {
+						s.Metrics.AddCandidates(pending)
+						s.Metrics.AddBusy(time.Since(resumed))
+						allowed := budget.spend(pending, time.Since(resumed))
+						limiter.wait(ctx, pending, time.Since(resumed))
+						pending = 0
+						batch = limiter.batchSizeFor(s.chunkSize())
+						resumed = time.Now()
+						if ctx.Err() != nil || !allowed {
+							return nil
+						}
 					}
-
-					if priv.Sign() <= 0 || priv.Cmp(Ed25519CurveOrder) >= 0 {
-						continue
+					if result := try(i, j, recovery, int64(a), int64(b)); result != nil {
+						return result
 					}
-
-					verified := false
-					if len(publicKey) > 0 {
-						verified, _ = VerifyRecoveredKey(priv, publicKey)
-						if !verified {
-							continue
+					if reverse && (a < -1 || a > 1) {
+						if result := try(j, i, backRecovery, int64(a), int64(b)); result != nil {
+							return result
 						}
-					} else {
-						// No public key provided - cannot verify in real-world scenario
-						// Set verified to false since we cannot confirm the key is correct
-						verified = false
-					}
-
-					return &RecoveryResult{
-						PrivateKey:    priv,
-						Relationship:  AffineRelationship{A: aBig, B: bBig},
-						SignaturePair: [2]int{i, j},
-						Verified:      verified,
-						Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
 					}
 				}
 			}
@@ -380,208 +688,183 @@ func (s *SmartBruteForceStrategy) rangeSearchSequential(ctx context.Context, sig
 
 // rangeSearchParallel performs a parallel brute-force search (faster for larger ranges).
 func (s *SmartBruteForceStrategy) rangeSearchParallel(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs, numWorkers int) *RecoveryResult {
-	return s.rangeSearch(ctx, signatures, publicKey, aRange, bRange, maxPairs, numWorkers)
+	result, _ := s.rangeSearch(ctx, signatures, publicKey, aRange, bRange, maxPairs, numWorkers)
+	return result
 }
 
 // rangeSearch performs a brute-force search over a specific range using parallel workers.
-func (s *SmartBruteForceStrategy) rangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs, numWorkers int) *RecoveryResult {
-	testedPairs := int64(0)
-	resultChan := make(chan *RecoveryResult, 1)
-	workChan := make(chan [2]int, numWorkers*100)
-
-	// Log search parameters
-	log.Printf("Brute-force search: a in [%d, %d], b in [%d, %d], max %d pairs", aRange[0], aRange[1], bRange[0], bRange[1], maxPairs)
-
-	// Generate work
-	go func() {
-		defer close(workChan)
-		pairCount := 0
-		for i := 0; i < len(signatures) && pairCount < maxPairs; i++ {
-			for j := i + 1; j < len(signatures) && pairCount < maxPairs; j++ {
-				select {
-				case <-ctx.Done():
-					return
-				case workChan <- [2]int{i, j}:
-					pairCount++
-				}
-			}
+//
+// The (a, b) grid is dealt statically: each worker owns every workers-th chunk of it (see
+// rangeDeal) and scans its chunks for every signature pair in turn, so there is no work
+// channel to contend on, no combination is tried twice, and all workers search the
+// front of the grid (a=1) first. Workers keep a private count, flushed to their own
+// counter every chunkSize() combinations (fewer when throttled), which is also
+// when they apply the throttle and check for cancellation.
+// Workers run in a lifecycle.Group, so the search always drains them (and stops the
+// progress ticker) before returning, and reports how many combinations were tried.
+func (s *SmartBruteForceStrategy) rangeSearch(ctx context.Context, signatures []*Signature, publicKey []byte, aRange, bRange [2]int, maxPairs, numWorkers int) (*RecoveryResult, int64) {
+	var pairs [][2]int
+	for i := 0; i < len(signatures) && len(pairs) < maxPairs; i++ {
+		for j := i + 1; j < len(signatures) && len(pairs) < maxPairs; j++ {
+			pairs = append(pairs, [2]int{i, j})
 		}
-	}()
+	}
+	points := s.decodeRPoints(signatures)
+	verifier := newKeyVerifier(publicKey)
+
+	reverse := s.bothDirections(aRange)
+
+	space := newRangeSpace(aRange, bRange, s.RangeConfig.SkipZeroA)
+	if len(pairs) == 0 || space.size() == 0 {
+		return nil, 0
+	}
 
-	// Start workers
 	if numWorkers == 0 {
 		numWorkers = runtime.NumCPU()
 	}
-	log.Printf("Using %d parallel workers", numWorkers)
-
-	var wg sync.WaitGroup
-	var found int32
-
-	// Progress logging goroutine
-	progressDone := make(chan struct{})
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-progressDone:
-				return
-			case <-ticker.C:
-				tested := atomic.LoadInt64(&testedPairs)
-				if tested > 0 {
-					log.Printf("Progress: tested %d combinations...", tested)
-				}
-			}
+	deal := space.deal(numWorkers, s.chunkSize())
+	counters := make(workerCounters, deal.workers)
+	log.Printf("Using %d parallel workers (chunks of %d combinations, dealt in turn)", deal.workers, deal.chunk)
+
+	// try checks a single (a, b) candidate on a pair, as r_pair[1] = a*r_pair[0] + b,
+	// recovered with the worker's pairRecovery for the pair in that order (nil for
+	// BigArithmetic).
+	// Without a public key a candidate cannot be confirmed here, so it is skipped.
+	try := func(pair [2]int, recovery *pairRecovery, a, b int64) *RecoveryResult {
+		// r2 = a*r1 + b implies R2 = a*R1 + b*B; reject candidates failing that
+		// before scalar recovery (no hashing, and exact even without a public key)
+		if s.rejectedByRPointsInt64(points, pair[0], pair[1], a, b) {
+			return nil
+		}
+		if len(publicKey) == 0 {
+			return nil
 		}
-	}()
 
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case pair, ok := <-workChan:
-					if !ok {
-						return
-					}
-					if atomic.LoadInt32(&found) == 1 {
-						return
-					}
+		priv := verifiedRangeKey(verifier, signatures[pair[0]], signatures[pair[1]], recovery, a, b)
+		if priv == nil {
+			return nil
+		}
+		return &RecoveryResult{
+			PrivateKey:    priv,
+			Relationship:  AffineRelationship{A: big.NewInt(a), B: big.NewInt(b)},
+			SignaturePair: pair,
+			Verified:      true,
+			Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
+		}
+	}
 
-					// Try a=1 first (most common case)
-					for a := aRange[0]; a <= aRange[1]; a++ {
-						if atomic.LoadInt32(&found) == 1 {
-							return
-						}
-						if a == 0 && s.RangeConfig.SkipZeroA {
-							continue
-						}
+	limiter := s.rangeThrottle()
+	budget := s.rangeQuota()
+
+	// The first worker to find the key stops the others; workerCtx is done when the
+	// search is cancelled or the key is found. In deterministic mode, a match is ordered
+	// by its position in the range's enumeration (pair, then a, then b) and workers only stop
+	// once nothing they have left can come earlier, so the reported match never depends
+	// on scheduling.
+	workers, workerCtx := lifecycle.WithContext(ctx)
+	first := &lifecycle.Lowest[*RecoveryResult]{}
+	if !s.RangeConfig.Deterministic {
+		first.OnFirst = workers.Stop
+	}
+	ordinal := func(p int, idx int64) int64 {
+		if !s.RangeConfig.Deterministic {
+			return 0
+		}
+		return int64(p)*space.size() + idx
+	}
 
-						// Prioritize a=1
-						if a != 1 && aRange[0] <= 1 && aRange[1] >= 1 {
-							// Try a=1 first
-							for b := bRange[0]; b <= bRange[1]; b++ {
-								if atomic.LoadInt32(&found) == 1 {
-									return
-								}
-								atomic.AddInt64(&testedPairs, 1)
-
-								aBig := big.NewInt(int64(1))
-								bBig := big.NewInt(int64(b))
-
-								// NOTE: We cannot validate the affine relationship on R points directly
-								// because R is a curve point (32 bytes, compressed format), not a scalar.
-								// The affine relationship r2 = a*r1 + b holds on the nonce scalars, not on R points.
-								// We cannot extract the nonce scalar from R (that's the discrete log problem).
-								// Instead, we try the recovery and verify the result - if the relationship holds,
-								// the recovery will produce the correct private key.
-
-								priv, err := RecoverPrivateKey(signatures[pair[0]], signatures[pair[1]], aBig, bBig)
-								if err == nil && priv.Sign() > 0 && priv.Cmp(Ed25519CurveOrder) < 0 {
-									// Verify recovered key against public key (required for real-world use)
-									verified := false
-									if len(publicKey) > 0 {
-										verified, _ = VerifyRecoveredKey(priv, publicKey)
-									} else {
-										// No public key provided - cannot verify in real-world scenario
-										// Skip this key since we cannot confirm it's correct
-										continue
-									}
-
-									if verified {
-										if atomic.CompareAndSwapInt32(&found, 0, 1) {
-											resultChan <- &RecoveryResult{
-												PrivateKey:    priv,
-												Relationship:  AffineRelationship{A: aBig, B: bBig},
-												SignaturePair: [2]int{pair[0], pair[1]},
-												Verified:      verified,
-												Pattern:       fmt.Sprintf("brute_force_a%d_b%d", 1, b),
-											}
-										}
-										return
-									}
-								}
+	for w := 0; w < deal.workers; w++ {
+		w := w
+		workers.Go(func() error {
+			s.Metrics.WorkerStarted()
+			defer s.Metrics.WorkerStopped()
+
+			var pending int64
+			batch := limiter.batchSizeFor(s.chunkSize())
+			resumed := time.Now()
+			defer func() {
+				counters.add(w, pending)
+				s.Metrics.AddCandidates(pending)
+				s.Metrics.AddBusy(time.Since(resumed))
+			}()
+
+			for p, pair := range pairs {
+				if workerCtx.Err() != nil || first.Below(ordinal(p, int64(w)*deal.chunk)) {
+					return nil
+				}
+				recovery := s.pairRecovery(signatures[pair[0]], signatures[pair[1]])
+				back := [2]int{pair[1], pair[0]}
+				var backRecovery *pairRecovery
+				if reverse {
+					backRecovery = s.pairRecovery(signatures[back[0]], signatures[back[1]])
+				}
+				for k := 0; ; k++ {
+					start, end, ok := deal.at(w, k)
+					if !ok {
+						break
+					}
+					for idx := start; idx < end; idx++ {
+						if pending++; pending >= batch {
+							counters.add(w, pending)
+							s.Metrics.AddCandidates(pending)
+							s.Metrics.AddBusy(time.Since(resumed))
+							allowed := budget.spend(pending, time.Since(resumed))
+							limiter.wait(workerCtx, pending, time.Since(resumed))
+							pending = 0
+							batch = limiter.batchSizeFor(s.chunkSize())
+							resumed = time.Now()
+							if !allowed {
+								workers.Stop()
+							}
+							if workerCtx.Err() != nil || first.Below(ordinal(p, idx)) {
+								return nil
 							}
 						}
 
-						// Try current a value
-						for b := bRange[0]; b <= bRange[1]; b++ {
-							if atomic.LoadInt32(&found) == 1 {
-								return
-							}
-							atomic.AddInt64(&testedPairs, 1)
-
-							aBig := big.NewInt(int64(a))
-							bBig := big.NewInt(int64(b))
-
-							// NOTE: We cannot validate the affine relationship on R points directly
-							// because R is a curve point (32 bytes, compressed format), not a scalar.
-							// The affine relationship r2 = a*r1 + b holds on the nonce scalars, not on R points.
-							// We cannot extract the nonce scalar from R (that's the discrete log problem).
-							// Instead, we try the recovery and verify the result - if the relationship holds,
-							// the recovery will produce the correct private key.
-
-							priv, err := RecoverPrivateKey(signatures[pair[0]], signatures[pair[1]], aBig, bBig)
-							if err == nil && priv.Sign() > 0 && priv.Cmp(Ed25519CurveOrder) < 0 {
-								// Verify recovered key against public key (required for real-world use)
-								verified := false
-								if len(publicKey) > 0 {
-									verified, _ = VerifyRecoveredKey(priv, publicKey)
-								} else {
-									// No public key provided - cannot verify in real-world scenario
-									// Skip this key since we cannot confirm it's correct
-									continue
-								}
-
-								if verified {
-									if atomic.CompareAndSwapInt32(&found, 0, 1) {
-										resultChan <- &RecoveryResult{
-											PrivateKey:    priv,
-											Relationship:  AffineRelationship{A: aBig, B: bBig},
-											SignaturePair: [2]int{pair[0], pair[1]},
-											Verified:      verified,
-											Pattern:       fmt.Sprintf("brute_force_a%d_b%d", a, b),
-										}
-									}
-									return
-								}
-							}
+						a, b := space.at(idx)
+						result := try(pair, recovery, a, b)
+						if result == nil && reverse && (a < -1 || a > 1) {
+							result = try(back, backRecovery, a, b)
+						}
+						if result != nil {
+							first.Offer(ordinal(p, idx), result)
+							return nil
 						}
 					}
 				}
+				// Pairs are searched in lockstep; one worker counts them
+				if w == 0 {
+					s.Metrics.AddPairs(1)
+				}
 			}
-		}()
+			return nil
+		})
 	}
 
-	// Wait for result or completion
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	progress := lifecycle.NewTicker(5*time.Second, func() {
+		if tested := counters.total(); tested > 0 {
+			log.Printf("Progress: tested %d combinations...", tested)
+		}
+	})
 
-	select {
-	case result := <-resultChan:
-		close(progressDone) // Stop progress logging
-		tested := atomic.LoadInt64(&testedPairs)
+	workers.Wait()
+	progress.Stop()
+	tested := counters.total()
+
+	if result, ok := first.Get(); ok {
 		log.Printf("✅ Found key after testing %d combinations (a=%s, b=%s, pair=[%d,%d])",
 			tested, result.Relationship.A.Text(10), result.Relationship.B.Text(10),
 			result.SignaturePair[0], result.SignaturePair[1])
-		return result
-	case <-ctx.Done():
-		close(progressDone) // Stop progress logging
-		tested := atomic.LoadInt64(&testedPairs)
+		return result, tested
+	}
+	if budget.exceeded() {
+		log.Printf("Search quota exceeded after testing %d combinations", tested)
+		return nil, tested
+	}
+	if ctx.Err() != nil {
 		log.Printf("Search cancelled after testing %d combinations", tested)
-		return nil
-	case <-done:
-		close(progressDone) // Stop progress logging
-		tested := atomic.LoadInt64(&testedPairs)
-		log.Printf("Search completed: tested %d combinations, no key found", tested)
-		return nil
+		return nil, tested
 	}
+	log.Printf("Search completed: tested %d combinations, no key found", tested)
+	return nil, tested
 }
@@ -2,6 +2,7 @@ package eddsaaffine
 
 import (
 	"context"
+	"math"
 	"math/big"
 	"testing"
 )
@@ -159,6 +160,54 @@ func TestSmartBruteForceStrategy_WithPatternConfig(t *testing.T) {
 	}
 }
 
+func TestPatternsForSteps(t *testing.T) {
+	patterns := PatternsForSteps([]int64{17, -3})
+	if len(patterns) != 2 {
+		t.Fatalf("got %d patterns, want 2", len(patterns))
+	}
+	for i, want := range []struct {
+		b    int64
+		name string
+	}{{17, "counter_+17"}, {-3, "counter_-3"}} {
+		p := patterns[i]
+		if p.A.Int64() != 1 || p.B.Int64() != want.b || p.Name != want.name || p.Priority != i+1 {
+			t.Errorf("pattern %d = {a=%s b=%s %q priority %d}, want {a=1 b=%d %q priority %d}",
+				i, p.A, p.B, p.Name, p.Priority, want.b, want.name, i+1)
+		}
+	}
+}
+
+func TestPatternsForRange(t *testing.T) {
+	tests := []struct {
+		a, bMin, bMax, stride int64
+		wantB                 []int64
+		wantFirst             string
+	}{
+		{1, -2, 5, 3, []int64{-2, 1, 4}, "counter_-2"},
+		{-1, -10, -8, 0, []int64{-10, -9, -8}, "affine_a-1_b-10"},
+		{3, 0, 0, 1, []int64{0}, "affine_a3_b+0"},
+		{1, 5, 4, 1, nil, ""},
+		{1, math.MaxInt64 - 1, math.MaxInt64, 1, []int64{math.MaxInt64 - 1, math.MaxInt64}, "counter_+9223372036854775806"},
+	}
+	for _, tt := range tests {
+		patterns := PatternsForRange(tt.a, tt.bMin, tt.bMax, tt.stride)
+		if len(patterns) != len(tt.wantB) {
+			t.Errorf("PatternsForRange(%d, %d, %d, %d): got %d patterns, want %d",
+				tt.a, tt.bMin, tt.bMax, tt.stride, len(patterns), len(tt.wantB))
+			continue
+		}
+		for i, p := range patterns {
+			if p.A.Int64() != tt.a || p.B.Int64() != tt.wantB[i] || p.Priority != i+1 {
+				t.Errorf("PatternsForRange(%d, %d, %d, %d)[%d] = {a=%s b=%s priority %d}",
+					tt.a, tt.bMin, tt.bMax, tt.stride, i, p.A, p.B, p.Priority)
+			}
+		}
+		if len(patterns) > 0 && patterns[0].Name != tt.wantFirst {
+			t.Errorf("first pattern named %q, want %q", patterns[0].Name, tt.wantFirst)
+		}
+	}
+}
+
 func TestDefaultRangeConfig(t *testing.T) {
 	config := DefaultRangeConfig()
 
@@ -177,6 +226,9 @@ func TestDefaultRangeConfig(t *testing.T) {
 	if !config.SkipZeroA {
 		t.Error("Expected SkipZeroA to be true")
 	}
+	if !config.BothDirections {
+		t.Error("Expected BothDirections to be true")
+	}
 }
 
 func TestDefaultPatternConfig(t *testing.T) {
@@ -196,3 +248,129 @@ func TestSmartBruteForceStrategy_Name(t *testing.T) {
 		t.Errorf("Expected name 'SmartBruteForce', got '%s'", strategy.Name())
 	}
 }
+
+func TestSmartBruteForceStrategy_Search_DerivedOffset(t *testing.T) {
+	a := big.NewInt(0x7654321)
+	r, _ := new(big.Int).SetString("0b1c2d3e4f5061728394a5b6c7d8e9fa0b1c2d3e4f5061728394a5b6c7d8e9f", 16)
+
+	// b is far outside every brute-force range
+	offset := big.NewInt(987654321)
+	var signatures []*Signature
+	for i := 0; i < 4; i++ {
+		signatures = append(signatures, signWithNonce(a, r, []byte{byte('a' + i)}))
+		r = new(big.Int).Mul(r, big.NewInt(3))
+		r.Add(r, offset)
+		r.Mod(r, Ed25519CurveOrder)
+	}
+
+	patternConfig := DefaultPatternConfig()
+	patternConfig.IncludeCommonPatterns = false
+	rangeConfig := DefaultRangeConfig()
+	rangeConfig.ARange = [2]int{1, 5}
+	rangeConfig.BRange = [2]int{0, 0}
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(patternConfig).WithRangeConfig(rangeConfig)
+
+	result := strategy.Search(context.Background(), signatures, signatures[0].PublicKey)
+	if result == nil {
+		t.Fatal("Expected derived recovery")
+	}
+	if result.PrivateKey.Cmp(a) != 0 {
+		t.Errorf("Expected private key %s, got %s", a, result.PrivateKey)
+	}
+	if result.Relationship.A.Cmp(big.NewInt(3)) != 0 || result.Relationship.B.Cmp(offset) != 0 {
+		t.Errorf("Expected a=3, b=%s, got a=%s, b=%s", offset, result.Relationship.A, result.Relationship.B)
+	}
+	if !result.Verified {
+		t.Error("Expected result verified against public key")
+	}
+}
+
+func TestSmartBruteForceStrategy_BothDirections(t *testing.T) {
+	// The earlier signature's nonce is derived from the later one's: r1 = 3*r2 + 7
+	a := big.NewInt(0xc0ffee)
+	r2 := big.NewInt(4242424242)
+	r1 := new(big.Int).Add(new(big.Int).Mul(r2, big.NewInt(3)), big.NewInt(7))
+	signatures := []*Signature{
+		signWithNonce(a, r1, []byte("message 1")),
+		signWithNonce(a, r2, []byte("message 2")),
+	}
+
+	// Sequential and parallel range phases
+	for _, bRange := range [][2]int{{-100, 100}, {-60000, 60000}} {
+		patternConfig := DefaultPatternConfig()
+		patternConfig.IncludeCommonPatterns = false
+		rangeConfig := DefaultRangeConfig()
+		rangeConfig.ARange = [2]int{1, 3}
+		rangeConfig.BRange = bRange
+		rangeConfig.CounterOffsetBound = 0
+		strategy := NewSmartBruteForceStrategy().WithPatternConfig(patternConfig).WithRangeConfig(rangeConfig)
+
+		result := strategy.Search(context.Background(), signatures, signatures[0].PublicKey)
+		if result == nil || result.PrivateKey.Cmp(a) != 0 {
+			t.Fatalf("b in %v: expected the key, got %+v", bRange, result)
+		}
+		if result.SignaturePair != [2]int{1, 0} || result.Relationship.A.Int64() != 3 || result.Relationship.B.Int64() != 7 {
+			t.Errorf("b in %v: expected r_0 = 3*r_1 + 7 on pair [1 0], got a=%s b=%s on %v",
+				bRange, result.Relationship.A, result.Relationship.B, result.SignaturePair)
+		}
+
+		strategy.RangeConfig.BothDirections = false
+		if result := strategy.Search(context.Background(), signatures, signatures[0].PublicKey); result != nil {
+			t.Errorf("b in %v: expected no key in one direction, got %+v", bRange, result)
+		}
+	}
+
+	// Pattern phases
+	strategy := NewSmartBruteForceStrategy()
+	result := strategy.tryPattern(signatures, signatures[0].PublicKey, big.NewInt(3), big.NewInt(7), "triple_+7")
+	if result == nil || result.SignaturePair != [2]int{1, 0} || result.PrivateKey.Cmp(a) != 0 {
+		t.Errorf("Expected the pattern to match on pair [1 0], got %+v", result)
+	}
+}
+
+func TestSmartBruteForceStrategy_OrderMinus(t *testing.T) {
+	a := big.NewInt(0xc0ffee)
+
+	// Nonces computed as L - r, less 3: r2 = (L - 1)*r1 + (L - 3)
+	r1 := big.NewInt(4242424242)
+	r2 := new(big.Int).Sub(OrderMinus(3), r1)
+	signatures := []*Signature{
+		signWithNonce(a, r1, []byte("message 1")),
+		signWithNonce(a, r2, []byte("message 2")),
+	}
+	publicKey := signatures[0].PublicKey
+
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{
+		CustomPatterns: []Pattern{{A: OrderMinus(1), B: OrderMinus(3), Name: "negate_-3"}},
+	})
+	result := strategy.tryCustomPatterns(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(a) != 0 {
+		t.Fatalf("Expected the key from the L - c pattern, got %+v", result)
+	}
+	if result.Relationship.A.Int64() != -1 || result.Relationship.B.Int64() != -3 || result.SignaturePair != [2]int{0, 1} {
+		t.Errorf("Expected a=-1 b=-3 on pair [0 1], got a=%s b=%s on %v",
+			result.Relationship.A, result.Relationship.B, result.SignaturePair)
+	}
+
+	// The range search finds the same relationship from negative a and b
+	patternConfig := DefaultPatternConfig()
+	patternConfig.IncludeCommonPatterns = false
+	rangeConfig := DefaultRangeConfig()
+	rangeConfig.ARange = [2]int{-1, -1}
+	rangeConfig.BRange = [2]int{-5, -1}
+	rangeConfig.CounterOffsetBound = 0
+	strategy = NewSmartBruteForceStrategy().WithPatternConfig(patternConfig).WithRangeConfig(rangeConfig)
+	if result := strategy.Search(context.Background(), signatures, publicKey); result == nil || result.Relationship.B.Int64() != -3 {
+		t.Errorf("Expected the range search to find b=-3, got %+v", result)
+	}
+
+	// A counter that wraps around L is an ordinary small offset
+	signatures = []*Signature{
+		signWithNonce(a, OrderMinus(2), []byte("message 1")),
+		signWithNonce(a, big.NewInt(3), []byte("message 2")),
+	}
+	result = NewSmartBruteForceStrategy().tryCommonPatterns(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(a) != 0 || result.Relationship.B.Int64() != 5 {
+		t.Errorf("Expected the wrapped counter to match counter_+5, got %+v", result)
+	}
+}
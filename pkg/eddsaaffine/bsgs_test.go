@@ -0,0 +1,85 @@
+package eddsaaffine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/tablefile"
+)
+
+func TestBSGSStrategy_Search(t *testing.T) {
+	a := big.NewInt(987654)
+	strategy := NewBSGSStrategy().WithBound(1 << 24)
+
+	for _, step := range []int64{5000000, -12345678} {
+		signatures := testNonceChain(a, big.NewInt(1), big.NewInt(step), 2)
+
+		for _, pub := range [][]byte{signatures[0].PublicKey, nil} {
+			result := strategy.Search(context.Background(), signatures, pub)
+			if result == nil {
+				t.Fatalf("Expected recovery for step %d (public key: %v)", step, pub != nil)
+			}
+			if result.PrivateKey.Cmp(a) != 0 {
+				t.Errorf("Expected private key %s, got %s", a, result.PrivateKey)
+			}
+			if result.Relationship.B.Int64() != step {
+				t.Errorf("Expected b=%d, got %s", step, result.Relationship.B)
+			}
+		}
+	}
+}
+
+func TestBSGSStrategy_Search_OutOfBound(t *testing.T) {
+	signatures := testNonceChain(big.NewInt(987654), big.NewInt(1), big.NewInt(100000), 2)
+
+	if result := NewBSGSStrategy().WithBound(1000).Search(context.Background(), signatures, nil); result != nil {
+		t.Errorf("Expected no result for offset outside the bound, got b=%s", result.Relationship.B)
+	}
+}
+
+func TestOffsetSolver_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baby.tbl")
+	built, err := NewOffsetSolver(1 << 20)
+	if err != nil {
+		t.Fatalf("NewOffsetSolver: %v", err)
+	}
+	if err := built.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	solver, err := LoadOffsetSolver(path, 1<<20)
+	if err != nil {
+		t.Fatalf("LoadOffsetSolver: %v", err)
+	}
+	defer solver.Close()
+
+	signatures := testNonceChain(big.NewInt(987654), big.NewInt(1), big.NewInt(-654321), 2)
+	if b, ok := solver.Solve(signatures[0], signatures[1]); !ok || b.Int64() != -654321 {
+		t.Errorf("Expected loaded table to solve b=-654321, got %v", b)
+	}
+
+	if _, err := LoadOffsetSolver(path, 1<<22); !errors.Is(err, tablefile.ErrMismatch) {
+		t.Errorf("Expected ErrMismatch for a different bound, got %v", err)
+	}
+}
+
+func TestOffsetSolver_BoundTooLarge(t *testing.T) {
+	for _, bound := range []int64{MaxOffsetBound + 1, 1 << 62, math.MaxInt64} {
+		if _, err := NewOffsetSolver(bound); !errors.Is(err, ErrOffsetBound) {
+			t.Errorf("NewOffsetSolver(%d): expected ErrOffsetBound, got %v", bound, err)
+		}
+		if _, err := LoadOffsetSolver(filepath.Join(t.TempDir(), "baby.tbl"), bound); !errors.Is(err, ErrOffsetBound) {
+			t.Errorf("LoadOffsetSolver(%d): expected ErrOffsetBound, got %v", bound, err)
+		}
+	}
+
+	// The strategy reports nothing rather than searching with a broken table
+	signatures := testNonceChain(big.NewInt(987654), big.NewInt(1), big.NewInt(100000), 2)
+	if result := NewBSGSStrategy().WithBound(1<<62).Search(context.Background(), signatures, nil); result != nil {
+		t.Errorf("Expected no result for a bound above MaxOffsetBound, got %+v", result)
+	}
+}
@@ -2,16 +2,15 @@ package eddsaaffine
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
 	"math/big"
-	"strings"
 )
 
 // Client provides a high-level API for EdDSA key recovery operations.
 type Client struct {
 	strategy BruteForceStrategy
 	parser   SignatureParser
+	curve    *Curve
 }
 
 // NewClient creates a new client with default settings.
@@ -38,8 +37,9 @@ func (c *Client) WithParser(parser SignatureParser) *Client {
 //
 // Args:
 //   - ctx: Context for cancellation.
-//   - source: Path to signature file (JSON or CSV).
-//   - publicKeyHex: Optional public key in hex format for verification.
+//   - source: Path or URL (https://, s3://, gs://) of signature file (JSON or CSV).
+//   - publicKeyHex: Optional public key for verification: hex, or the contents of an
+//     OpenSSH, PEM or JWK public key file (see ParsePublicKey).
 //
 // Returns:
 //   - RecoveryResult if successful, error otherwise.
@@ -53,26 +53,28 @@ func (c *Client) RecoverKey(ctx context.Context, source string, publicKeyHex str
 
 // RecoverKeyFromSignatures attempts to recover a private key from in-memory signatures.
 // Use this when you have already parsed signatures (e.g. from your own parser or API).
-// Public key is optional; when provided, the recovered key is verified.
+// Public key is optional; when provided, the recovered key is verified. Otherwise it is
+// verified against the signatures' own PublicKey. Signatures by different signers are
+// never paired; use RecoverKeysFromSignatures to get a result for every signer.
+// Ed25519 signatures or keys that CheckSignature or CheckPublicKey reject fail the call.
 func (c *Client) RecoverKeyFromSignatures(ctx context.Context, signatures []*Signature, publicKeyHex string) (*RecoveryResult, error) {
 	if len(signatures) < 2 {
 		return nil, fmt.Errorf("need at least 2 signatures, got %d", len(signatures))
 	}
 
-	var publicKey []byte
-	if publicKeyHex != "" {
-		var err error
-		publicKey, err = hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse public key: %w", err)
-		}
-		if len(publicKey) != 32 {
-			return nil, fmt.Errorf("public key must be 32 bytes (Ed25519 format), got %d", len(publicKey))
-		}
+	publicKey, err := c.parsePublicKey(publicKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkInputs(signatures, publicKey); err != nil {
+		return nil, err
 	}
 
-	result := c.strategy.Search(ctx, signatures, publicKey)
+	result := c.searchByKey(ctx, signatures, publicKey)
 	if result == nil {
+		if s, ok := c.strategy.(*SmartBruteForceStrategy); ok && s.QuotaExceeded() {
+			return nil, fmt.Errorf("failed to recover private key: %w", ErrQuotaExceeded)
+		}
 		return nil, fmt.Errorf("failed to recover private key")
 	}
 	return result, nil
@@ -82,10 +84,10 @@ func (c *Client) RecoverKeyFromSignatures(ctx context.Context, signatures []*Sig
 //
 // Args:
 //   - ctx: Context for cancellation.
-//   - source: Path to signature file.
+//   - source: Path or URL of signature file.
 //   - a: Affine coefficient (r2 = a*r1 + b).
 //   - b: Affine offset (r2 = a*r1 + b).
-//   - publicKeyHex: Optional public key for verification.
+//   - publicKeyHex: Optional public key for verification (defaults to the signatures' own PublicKey).
 //
 // Returns:
 //   - RecoveryResult if successful, error otherwise.
@@ -101,23 +103,33 @@ func (c *Client) RecoverKeyWithKnownRelationship(ctx context.Context, source str
 	}
 
 	// Parse public key if provided
-	var publicKey []byte
-	if publicKeyHex != "" {
-		publicKey, err = hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse public key: %w", err)
-		}
-		if len(publicKey) != 32 {
-			return nil, fmt.Errorf("public key must be 32 bytes (Ed25519 format), got %d", len(publicKey))
-		}
+	publicKey, err := c.parsePublicKey(publicKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkInputs(signatures, publicKey); err != nil {
+		return nil, err
 	}
 
 	// Try all signature pairs
 	aBig := big.NewInt(a)
 	bBig := big.NewInt(b)
 
+	if c.curve != nil {
+		result := NewCurveStrategy(c.curve).TryRelationship(ctx, signatures, nil, publicKey, aBig, bBig)
+		if result == nil {
+			return nil, fmt.Errorf("failed to recover private key with known relationship a=%d, b=%d on curve %s", a, b, c.curve.name())
+		}
+		result.Pattern = fmt.Sprintf("known_a%d_b%d", a, b)
+		return result, nil
+	}
+
 	for i := 0; i < len(signatures); i++ {
 		for j := i + 1; j < len(signatures); j++ {
+			pairKey, ok := verificationKey(signatures[i], signatures[j], publicKey)
+			if !ok {
+				continue
+			}
 			priv, err := RecoverPrivateKey(signatures[i], signatures[j], aBig, bBig)
 			if err != nil {
 				continue
@@ -125,8 +137,8 @@ func (c *Client) RecoverKeyWithKnownRelationship(ctx context.Context, source str
 
 			// Verify recovered key against public key (required for real-world use)
 			verified := false
-			if len(publicKey) > 0 {
-				verified, _ = VerifyRecoveredKey(priv, publicKey)
+			if len(pairKey) > 0 {
+				verified, _ = VerifyRecoveredKey(priv, pairKey)
 				if !verified {
 					continue
 				}
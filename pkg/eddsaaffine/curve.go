@@ -0,0 +1,334 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+)
+
+// curveExplainChecks caps the signatures a candidate key must explain on a curve with a
+// generator; on a group of order q a wrong key explains each with probability 1/q.
+const curveExplainChecks = 8
+
+// Curve is a group other than Ed25519 to run the recovery math in, such as the toy
+// groups with small orders used to teach the attack and in CTFs, where s = r + h·x mod
+// Order. Only the order is required; the generator and Verify decide how candidate keys
+// are checked.
+//
+//	// Schnorr group: 4 has order 1019 mod 2039
+//	curve := &eddsaaffine.Curve{
+//		Name: "toy", Order: big.NewInt(1019),
+//		Generator: func(k *big.Int) []byte {
+//			return new(big.Int).Exp(big.NewInt(4), k, big.NewInt(2039)).FillBytes(make([]byte, 2))
+//		},
+//	}
+//	client := eddsaaffine.NewClient().WithCurve(curve)
+type Curve struct {
+	// Name is used in messages (default "custom")
+	Name string
+
+	// Order is the order of the group, the modulus of the signing equation
+	Order *big.Int
+
+	// Generator, if set, encodes k·B for the group's base point B; a signature's R is
+	// the encoding read as a little-endian integer, as for Ed25519. With it a candidate
+	// key must reproduce the R values of the signatures, and match the public key
+	// Generator(x) if there is one. Without it there is no point arithmetic, and a
+	// candidate is accepted when the same relationship gives the same key for the next
+	// signature too, as with counter nonces.
+	Generator func(k *big.Int) []byte
+
+	// Challenge, if set, computes h for a signature; the default is SHA-512(R || A || M)
+	// as in ComputeH, reduced mod Order
+	Challenge func(sig *Signature) *big.Int
+
+	// Verify, if set, decides whether a candidate key is the signer's instead, and a key
+	// it accepts is reported as verified. publicKey is the key given for the search, or
+	// the signatures' own, and may be nil.
+	Verify func(privateKey *big.Int, publicKey []byte) bool
+}
+
+// Validate checks that the curve has an order greater than one.
+func (c *Curve) Validate() error {
+	if c.Order == nil || c.Order.Cmp(big.NewInt(1)) <= 0 {
+		return errors.New("curve has no order")
+	}
+	return nil
+}
+
+// name returns the curve's name for messages.
+func (c *Curve) name() string {
+	if c.Name == "" {
+		return "custom"
+	}
+	return c.Name
+}
+
+// challenge returns h for a signature on the curve.
+func (c *Curve) challenge(sig *Signature) *big.Int {
+	if c.Challenge != nil {
+		return new(big.Int).Mod(c.Challenge(sig), c.Order)
+	}
+	return computeH(sig.R, sig.PublicKey, sig.Message, c.Order)
+}
+
+// Sign signs message with key x and nonce r: R = Generator(r), s = r + h·x mod Order.
+// publicKey is the A hashed into h (default Generator(x)). Use it to make datasets with
+// chosen nonces on the curve. The curve must have a generator.
+func (c *Curve) Sign(x, r *big.Int, message, publicKey []byte) *Signature {
+	if publicKey == nil {
+		publicKey = c.Generator(x)
+	}
+	sig := &Signature{R: c.encodedR(r), Message: message, PublicKey: publicKey}
+	s := new(big.Int).Mul(c.challenge(sig), x)
+	s.Add(s, r).Mod(s, c.Order)
+	sig.S = s
+	return sig
+}
+
+// encodedR returns Generator(r) as a signature's R: its bytes read little-endian.
+func (c *Curve) encodedR(r *big.Int) *big.Int {
+	return new(big.Int).SetBytes(reversedBytes(c.Generator(new(big.Int).Mod(r, c.Order))))
+}
+
+// RecoverPrivateKey is the package's RecoverPrivateKey in the curve's group.
+func (c *Curve) RecoverPrivateKey(sig1, sig2 *Signature, a, b *big.Int) (*big.Int, error) {
+	return recoverPrivateKey(sig1.S, sig2.S, c.challenge(sig1), c.challenge(sig2), a, b, c.Order)
+}
+
+// RecoverNonce is the package's RecoverNonce in the curve's group.
+func (c *Curve) RecoverNonce(sig *Signature, privateKey *big.Int) (*big.Int, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("signature is missing R or s")
+	}
+	r := new(big.Int).Mul(c.challenge(sig), privateKey)
+	r.Sub(sig.S, r).Mod(r, c.Order)
+	return r, nil
+}
+
+// RecoverPrivateKeyFromKnownNonce is the package's RecoverPrivateKeyFromKnownNonce in
+// the curve's group. With a generator the nonce must reproduce the signature's R.
+func (c *Curve) RecoverPrivateKeyFromKnownNonce(sig *Signature, r *big.Int) (*big.Int, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("signature is missing R or s")
+	}
+	if c.Generator != nil && c.encodedR(r).Cmp(sig.R) != 0 {
+		return nil, errors.New("nonce does not reproduce the signature's R")
+	}
+	hInv := new(big.Int).ModInverse(c.challenge(sig), c.Order)
+	if hInv == nil {
+		return nil, errors.New("failed to compute modular inverse of H(R||A||M)")
+	}
+	x := new(big.Int).Sub(sig.S, r)
+	x.Mul(x, hInv).Mod(x, c.Order)
+	if x.Sign() == 0 {
+		return nil, errors.New("recovered private key is zero")
+	}
+	return x, nil
+}
+
+// explains reports whether key x reproduces the signature's R through the nonce it
+// implies.
+func (c *Curve) explains(sig *Signature, x *big.Int) bool {
+	r, err := c.RecoverNonce(sig, x)
+	return err == nil && c.encodedR(r).Cmp(sig.R) == 0
+}
+
+// check decides whether x, recovered from signatures i and j with r_j = a*r_i + b, is
+// the signer's key, and whether that was verified (see Curve).
+func (c *Curve) check(x *big.Int, signatures []*Signature, i, j int, a, b *big.Int, publicKey []byte) (ok, verified bool) {
+	if x.Sign() == 0 {
+		return false, false
+	}
+	if c.Verify != nil {
+		ok = c.Verify(x, publicKey)
+		return ok, ok
+	}
+	if c.Generator == nil {
+		// The next signature must continue the relationship with the same key
+		if j+1 >= len(signatures) || !bytes.Equal(signatures[j].PublicKey, signatures[j+1].PublicKey) {
+			return false, false
+		}
+		next, err := c.RecoverPrivateKey(signatures[j], signatures[j+1], a, b)
+		return err == nil && next.Cmp(x) == 0, false
+	}
+
+	if !c.explains(signatures[i], x) || !c.explains(signatures[j], x) {
+		return false, false
+	}
+	checked := 0
+	for k := 0; k < len(signatures) && checked < curveExplainChecks; k++ {
+		if k == i || k == j || !bytes.Equal(signatures[k].PublicKey, signatures[i].PublicKey) {
+			continue
+		}
+		if !c.explains(signatures[k], x) {
+			return false, false
+		}
+		checked++
+	}
+	if len(publicKey) > 0 {
+		ok = bytes.Equal(c.Generator(x), publicKey)
+		return ok, ok
+	}
+	return true, false
+}
+
+// CurveStrategy searches for affinely related nonces on a Curve, in plain modular
+// arithmetic: repeated R values, then Patterns, then every (a, b) in ARange × BRange,
+// over the first MaxPairs pairs by the same signer. It is meant for small datasets on
+// toy groups; Ed25519 datasets are searched far faster by SmartBruteForceStrategy.
+type CurveStrategy struct {
+	Curve *Curve
+
+	// Patterns are tried before the range (default: CommonPatterns)
+	Patterns []Pattern
+
+	// ARange and BRange are the a and b searched after the patterns (a = 0 is skipped)
+	ARange, BRange [2]int
+
+	// MaxPairs limits the number of signature pairs to test
+	MaxPairs int
+}
+
+// NewCurveStrategy creates a curve strategy with the common patterns and a, b in
+// [-100, 100].
+func NewCurveStrategy(curve *Curve) *CurveStrategy {
+	return &CurveStrategy{
+		Curve:    curve,
+		Patterns: CommonPatterns(),
+		ARange:   [2]int{-100, 100},
+		BRange:   [2]int{-100, 100},
+		MaxPairs: 100,
+	}
+}
+
+// Name returns the name of this strategy.
+func (s *CurveStrategy) Name() string {
+	return "Curve"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *CurveStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if len(signatures) < 2 || s.Curve == nil {
+		return nil
+	}
+	if err := s.Curve.Validate(); err != nil {
+		log.Printf("Curve %s: %v", s.Curve.name(), err)
+		return nil
+	}
+	pairs := s.pairs(signatures)
+
+	log.Printf("Searching curve %s: repeated R, %d patterns, then a in [%d, %d], b in [%d, %d] on %d pairs",
+		s.Curve.name(), len(s.Patterns), s.ARange[0], s.ARange[1], s.BRange[0], s.BRange[1], len(pairs))
+	var repeated [][2]int
+	for _, pair := range pairs {
+		if signatures[pair[0]].R.Cmp(signatures[pair[1]].R) == 0 {
+			repeated = append(repeated, pair)
+		}
+	}
+	if len(repeated) > 0 {
+		if result := s.TryRelationship(ctx, signatures, repeated, publicKey, big.NewInt(1), big.NewInt(0)); result != nil {
+			result.Pattern = "same_nonce"
+			return result
+		}
+	}
+	for _, pattern := range s.Patterns {
+		if result := s.TryRelationship(ctx, signatures, pairs, publicKey, pattern.A, pattern.B); result != nil {
+			result.Pattern = pattern.Name
+			return result
+		}
+	}
+	for a := s.ARange[0]; a <= s.ARange[1]; a++ {
+		if a == 0 {
+			continue
+		}
+		for b := s.BRange[0]; b <= s.BRange[1]; b++ {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if result := s.TryRelationship(ctx, signatures, pairs, publicKey, big.NewInt(int64(a)), big.NewInt(int64(b))); result != nil {
+				result.Pattern = fmt.Sprintf("range_a%d_b%d", a, b)
+				return result
+			}
+		}
+	}
+	return nil
+}
+
+// pairs returns the first MaxPairs pairs (i < j) of signatures by the same signer.
+func (s *CurveStrategy) pairs(signatures []*Signature) [][2]int {
+	maxPairs := s.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = 100
+	}
+	var pairs [][2]int
+	for i := 0; i < len(signatures) && len(pairs) < maxPairs; i++ {
+		for j := i + 1; j < len(signatures) && len(pairs) < maxPairs; j++ {
+			if bytes.Equal(signatures[i].PublicKey, signatures[j].PublicKey) {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
+
+// TryRelationship tries r_j = a*r_i + b on each of pairs (all pairs by the same signer
+// if nil) and returns the first key the curve accepts, or nil. Keys are checked against
+// publicKey, or else the pair's own. The result's Pattern is left for the caller.
+func (s *CurveStrategy) TryRelationship(ctx context.Context, signatures []*Signature, pairs [][2]int, publicKey []byte, a, b *big.Int) *RecoveryResult {
+	if pairs == nil {
+		pairs = s.pairs(signatures)
+	}
+	for _, pair := range pairs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		i, j := pair[0], pair[1]
+		pairKey, ok := verificationKey(signatures[i], signatures[j], publicKey)
+		if !ok {
+			continue
+		}
+		x, err := s.Curve.RecoverPrivateKey(signatures[i], signatures[j], a, b)
+		if err != nil {
+			continue
+		}
+		if ok, verified := s.Curve.check(x, signatures, i, j, a, b, pairKey); ok {
+			return &RecoveryResult{
+				PrivateKey:    x,
+				Relationship:  AffineRelationship{A: new(big.Int).Set(a), B: new(big.Int).Set(b)},
+				SignaturePair: [2]int{i, j},
+				Verified:      verified,
+			}
+		}
+	}
+	return nil
+}
+
+// WithCurve runs the client's recovery in curve's group instead of Ed25519's, with a
+// CurveStrategy unless one is set afterwards. Public keys given to the client are then
+// hex of curve's encoding. RecoverKeys and the two-key recoveries stay Ed25519-only.
+func (c *Client) WithCurve(curve *Curve) *Client {
+	c.curve = curve
+	c.strategy = NewCurveStrategy(curve)
+	return c
+}
+
+// parsePublicKey parses a public key given to a client method: hex of the curve's
+// encoding with WithCurve, else anything ParsePublicKey accepts.
+func (c *Client) parsePublicKey(publicKeyHex string) ([]byte, error) {
+	if publicKeyHex == "" {
+		return nil, nil
+	}
+	if c.curve == nil {
+		return ParsePublicKey([]byte(publicKeyHex))
+	}
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key for curve %s: %w", c.curve.name(), err)
+	}
+	return publicKey, nil
+}
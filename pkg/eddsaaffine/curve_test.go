@@ -0,0 +1,105 @@
+package eddsaaffine
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// toyCurve is the Schnorr group of order 1019 generated by 4 mod 2039, with elements
+// encoded as 2 big-endian bytes.
+func toyCurve() *Curve {
+	return &Curve{
+		Name: "toy", Order: big.NewInt(1019),
+		Generator: func(k *big.Int) []byte {
+			return new(big.Int).Exp(big.NewInt(4), k, big.NewInt(2039)).FillBytes(make([]byte, 2))
+		},
+	}
+}
+
+// toyCurveSignatures signs count messages with key x and nonces r0, r0+step, ... on curve.
+func toyCurveSignatures(curve *Curve, x, r0, step int64, count int) []*Signature {
+	signatures := make([]*Signature, count)
+	for i := range signatures {
+		r := big.NewInt(r0 + step*int64(i))
+		signatures[i] = curve.Sign(big.NewInt(x), r, []byte(fmt.Sprintf("toy %d", i)), nil)
+	}
+	return signatures
+}
+
+func TestCurve_Ed25519(t *testing.T) {
+	// Ed25519 as a Curve signs like the package's test signer
+	curve := &Curve{
+		Order: Ed25519CurveOrder,
+		Generator: func(k *big.Int) []byte {
+			return edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(k)).Bytes()
+		},
+	}
+	x, r, message := big.NewInt(0xc0ffee), big.NewInt(0x5eed), []byte("curve")
+	sig := curve.Sign(x, r, message, nil)
+	if ref := signWithNonce(x, r, message); sig.R.Cmp(ref.R) != 0 || sig.S.Cmp(ref.S) != 0 {
+		t.Errorf("Sign differs from the Ed25519 signer")
+	}
+}
+
+func TestClient_WithCurve(t *testing.T) {
+	curve := toyCurve()
+	signatures := toyCurveSignatures(curve, 77, 10, 3, 6)
+	publicKey := hex.EncodeToString(curve.Generator(big.NewInt(77)))
+	ctx := context.Background()
+
+	client := NewClient().WithCurve(curve)
+	result, err := client.RecoverKeyFromSignatures(ctx, signatures, publicKey)
+	if err != nil || result.PrivateKey.Int64() != 77 || !result.Verified || result.Pattern != "counter_+3" {
+		t.Fatalf("Expected the key verified, got %+v, %v", result, err)
+	}
+	narrow := NewCurveStrategy(curve)
+	narrow.ARange, narrow.BRange = [2]int{-2, 2}, [2]int{-10, 10}
+	if _, err := client.WithStrategy(narrow).RecoverKeyFromSignatures(ctx, signatures, hex.EncodeToString(curve.Generator(big.NewInt(78)))); err == nil {
+		t.Error("Expected no key for another signer's public key")
+	}
+
+	// Without a generator the chain must continue with the same key
+	orderOnly := &Curve{Order: curve.Order}
+	result, err = NewClient().WithCurve(orderOnly).RecoverKeyFromSignatures(ctx, signatures, "")
+	if err != nil || result.PrivateKey.Int64() != 77 || result.Verified {
+		t.Errorf("Expected the key unverified, got %+v, %v", result, err)
+	}
+
+	// A Verify callback decides instead
+	orderOnly.Verify = func(privateKey *big.Int, publicKey []byte) bool { return privateKey.Int64() == 77 }
+	result, err = NewClient().WithCurve(orderOnly).RecoverKeyFromSignatures(ctx, signatures[:2], "")
+	if err != nil || !result.Verified {
+		t.Errorf("Expected Verify to accept the key from one pair, got %+v, %v", result, err)
+	}
+}
+
+func TestClient_WithCurve_KnownRelationship(t *testing.T) {
+	curve := toyCurve()
+	signatures := []*Signature{
+		curve.Sign(big.NewInt(500), big.NewInt(7), []byte("first"), nil),
+		curve.Sign(big.NewInt(500), big.NewInt(2*7+26), []byte("second"), nil),
+	}
+
+	var items []string
+	for _, sig := range signatures {
+		items = append(items, fmt.Sprintf(`{"message": "%s", "r": "%d", "s": "%d", "public_key": "%x"}`,
+			sig.Message, sig.R, sig.S, sig.PublicKey))
+	}
+	path := filepath.Join(t.TempDir(), "toy.json")
+	if err := os.WriteFile(path, []byte("["+strings.Join(items, ",")+"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewClient().WithCurve(curve).RecoverKeyWithKnownRelationship(context.Background(), path, 2, 26, "")
+	if err != nil || result.PrivateKey.Int64() != 500 || !result.Verified || result.Pattern != "known_a2_b26" {
+		t.Errorf("Expected the key verified against the signatures' own, got %+v, %v", result, err)
+	}
+}
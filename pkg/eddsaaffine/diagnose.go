@@ -0,0 +1,261 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SignatureFields are the fields of one signature as written in a dataset, before any
+// parsing. R and S may be given separately or together as Signature; values of only
+// digits are taken as JSON numbers.
+type SignatureFields struct {
+	R         string `json:"r,omitempty"`
+	S         string `json:"s,omitempty"`
+	Signature string `json:"signature,omitempty"` // the 64-byte signature R || S, hex
+	Message   string `json:"message"`
+	PublicKey string `json:"public_key"`
+}
+
+// How Interpretation reads r and s.
+const (
+	ReadInteger = "integer" // the little-endian integer of the 32 bytes, written as a number (JSONParser's reading of r and s)
+	ReadBytes   = "bytes"   // the 32 bytes in signature order, written as hex (JSONParser's reading of signature)
+)
+
+// How Interpretation reads the message.
+const (
+	MessageText   = "text"
+	MessageHex    = "hex"
+	MessageBase64 = "base64"
+)
+
+// Interpretation is one way of reading SignatureFields, and whether the signature
+// verifies when read that way.
+type Interpretation struct {
+	R                 string `json:"r"`                   // ReadInteger or ReadBytes
+	S                 string `json:"s"`                   // ReadInteger or ReadBytes
+	SReduced          bool   `json:"s_reduced"`           // s was not below L and was reduced mod L
+	Message           string `json:"message"`             // MessageText, MessageHex or MessageBase64
+	PublicKeyReversed bool   `json:"public_key_reversed"` // the public key's bytes were reversed
+	Verifies          bool   `json:"verifies"`
+
+	signature *Signature
+}
+
+// Diagnosis is the result of Diagnose.
+type Diagnosis struct {
+	Parser Interpretation   `json:"parser"` // how JSONParser reads the fields
+	Tried  []Interpretation `json:"tried"`
+
+	// Match is the first interpretation under which the signature verifies (the parser's,
+	// if it does), or nil
+	Match *Interpretation `json:"match,omitempty"`
+
+	// Fixes lists what to change in the dataset so JSONParser reads the signature as Match
+	Fixes []string `json:"fixes,omitempty"`
+
+	// Fixed holds the fields as JSONParser reads them correctly, when Match is set
+	Fixed *SignatureFields `json:"fixed,omitempty"`
+
+	// Endianness is the JSONParser.Endianness that reads r and s as Match does, or ""
+	// when there is no match or r and s need different readings
+	Endianness Endianness `json:"endianness,omitempty"`
+}
+
+// Diagnose tries every plausible reading of one signature's fields (r and s as integers
+// or as raw bytes, s reduced mod L, the message as text, hex or base64, the public key's
+// bytes reversed) and reports which one verifies as a standard Ed25519 signature. Mixed-up
+// encodings are the most common reason key recovery finds nothing; when JSONParser's
+// reading does not verify, the Diagnosis says how to rewrite the dataset.
+//
+// Flawed signatures with raw, unclamped nonces verify like any other: S*B = R + h*A holds
+// whatever the nonce.
+func Diagnose(fields SignatureFields) (*Diagnosis, error) {
+	rField, sField := fields.R, fields.S
+	if fields.Signature != "" {
+		raw, err := hex.DecodeString(strings.TrimPrefix(fields.Signature, "0x"))
+		if err != nil || len(raw) != 64 {
+			return nil, errors.New("signature must be 64 bytes (R || S) in hex")
+		}
+		rField, sField = hex.EncodeToString(raw[:32]), hex.EncodeToString(raw[32:])
+	}
+	if rField == "" || sField == "" || fields.PublicKey == "" {
+		return nil, errors.New("need r and s (or signature) and public_key")
+	}
+
+	rReadings := scalarReadings(rField)
+	sReadings := scalarReadings(sField)
+	if len(rReadings) == 0 {
+		return nil, fmt.Errorf("r is neither a number nor 32 bytes of hex: %q", rField)
+	}
+	if len(sReadings) == 0 {
+		return nil, fmt.Errorf("s is neither a number nor 32 bytes of hex: %q", sField)
+	}
+	publicKey, err := ParsePublicKey([]byte(fields.PublicKey))
+	if err != nil {
+		// A key with its bytes reversed is usually not a valid point at all
+		raw, hexErr := hex.DecodeString(strings.TrimPrefix(fields.PublicKey, "0x"))
+		if hexErr != nil || len(raw) != 32 {
+			return nil, err
+		}
+		if _, reversedErr := ParsePublicKey([]byte(hex.EncodeToString(reversedBytes(raw)))); reversedErr != nil {
+			return nil, err
+		}
+		publicKey = raw
+	}
+	parserScalar := ReadInteger
+	if fields.Signature != "" {
+		parserScalar = ReadBytes
+	}
+	messages := messageReadings(fields.Message)
+	parserMessage := decodeMessage(fields.Message)
+
+	d := &Diagnosis{}
+	for _, r := range rReadings {
+		for _, s := range sReadings {
+			for _, m := range messages {
+				for _, reversed := range []bool{false, true} {
+					in := Interpretation{R: r.how, S: s.how, Message: m.how, PublicKeyReversed: reversed}
+					pub := publicKey
+					if reversed {
+						pub = reversedBytes(publicKey)
+					}
+					sv := s.value
+					if sv.Cmp(Ed25519CurveOrder) >= 0 {
+						in.SReduced = true
+						sv = new(big.Int).Mod(sv, Ed25519CurveOrder)
+					}
+					in.signature = &Signature{R: r.value, S: sv, Message: m.value, PublicKey: pub}
+					in.Verifies, _ = VerifySignature(in.signature, nil)
+
+					// JSONParser's reading goes first, so it is the match whenever it verifies
+					if d.Parser.R == "" && r.how == parserScalar && s.how == parserScalar && !reversed && bytes.Equal(m.value, parserMessage) {
+						d.Parser = in
+						d.Tried = append([]Interpretation{in}, d.Tried...)
+						continue
+					}
+					d.Tried = append(d.Tried, in)
+				}
+			}
+		}
+	}
+
+	for i := range d.Tried {
+		if d.Tried[i].Verifies {
+			d.Match = &d.Tried[i]
+			break
+		}
+	}
+	if d.Match != nil {
+		if fields.Signature == "" {
+			d.Endianness = matchEndianness(*d.Match)
+		}
+		d.Fixes = fixes(*d.Match, parserScalar, fields.Message)
+		sig := d.Match.signature
+		d.Fixed = &SignatureFields{
+			R:         fmt.Sprintf("0x%064x", sig.R),
+			S:         fmt.Sprintf("0x%064x", sig.S),
+			Message:   "0x" + hex.EncodeToString(sig.Message),
+			PublicKey: hex.EncodeToString(sig.PublicKey),
+		}
+	}
+	return d, nil
+}
+
+type reading[T any] struct {
+	how   string
+	value T
+}
+
+// scalarReadings returns r or s as JSONParser reads it and, if it is 32 bytes of hex, as
+// those bytes in signature order.
+func scalarReadings(field string) []reading[*big.Int] {
+	var readings []reading[*big.Int]
+	var value interface{} = field
+	if strings.Trim(field, "0123456789") == "" {
+		value = json.Number(field)
+	}
+	if v, err := parseBigInt(value); err == nil && v.Sign() >= 0 && v.BitLen() <= 256 {
+		readings = append(readings, reading[*big.Int]{ReadInteger, v})
+	}
+	if raw, err := hex.DecodeString(strings.TrimPrefix(field, "0x")); err == nil && len(raw) == 32 {
+		readings = append(readings, reading[*big.Int]{ReadBytes, new(big.Int).SetBytes(reversedBytes(raw))})
+	}
+	return readings
+}
+
+// messageReadings returns the message as text and, where it decodes, as hex and base64.
+func messageReadings(field string) []reading[[]byte] {
+	readings := []reading[[]byte]{{MessageText, []byte(field)}}
+	if raw, err := hex.DecodeString(strings.TrimPrefix(field, "0x")); err == nil && field != "" {
+		readings = append(readings, reading[[]byte]{MessageHex, raw})
+	}
+	if raw, err := base64.StdEncoding.DecodeString(field); err == nil && field != "" {
+		readings = append(readings, reading[[]byte]{MessageBase64, raw})
+	}
+	return readings
+}
+
+// fixes describes how match differs from the parser's reading of the fields.
+func fixes(match Interpretation, parserScalar, message string) []string {
+	var out []string
+	for _, f := range []struct {
+		name, how string
+	}{{"r", match.R}, {"s", match.S}} {
+		switch {
+		case f.how == parserScalar:
+		case f.how == ReadBytes && matchEndianness(match) == LittleEndian:
+			out = append(out, fmt.Sprintf("%s holds the 32 bytes in signature order, but JSONParser reads hex as a big-endian integer by default: set its Endianness to LittleEndian (--endianness little) or reverse them", f.name))
+		case f.how == ReadBytes:
+			out = append(out, fmt.Sprintf("%s holds the 32 bytes in signature order, but JSONParser expects the little-endian integer of those bytes: reverse them", f.name))
+		}
+	}
+	if match.SReduced {
+		out = append(out, "s is not below the group order L; it verifies reduced mod L, but strict verifiers reject it")
+	}
+	if parsed := decodeMessage(message); !bytes.Equal(parsed, match.signature.Message) {
+		how := "hex"
+		if bytes.Equal(parsed, []byte(message)) {
+			how = "text"
+		}
+		out = append(out, fmt.Sprintf("message is %s, but JSONParser reads it as %s: write it as 0x-prefixed hex", describeMessage(match.Message), how))
+	}
+	if match.PublicKeyReversed {
+		out = append(out, "public_key has its bytes reversed")
+	}
+	return out
+}
+
+// matchEndianness returns the JSONParser.Endianness that reads both r and s as match
+// does, or "" if they are read differently.
+func matchEndianness(match Interpretation) Endianness {
+	switch {
+	case match.R != match.S:
+		return ""
+	case match.R == ReadBytes:
+		return LittleEndian
+	default:
+		return BigEndian
+	}
+}
+
+func describeMessage(how string) string {
+	if how == MessageText {
+		return "text"
+	}
+	return how + "-encoded"
+}
+
+func reversedBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package eddsaaffine
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// wireBytes returns the 32 bytes of r or s in signature order.
+func wireBytes(v *big.Int) []byte {
+	be := make([]byte, 32)
+	v.FillBytes(be)
+	return reversedBytes(be)
+}
+
+func TestDiagnose(t *testing.T) {
+	a := big.NewInt(424242)
+	message := []byte("diagnose me")
+	sig := signWithNonce(a, big.NewInt(987654321), message)
+	r, s := wireBytes(sig.R), wireBytes(sig.S)
+	publicKey := hex.EncodeToString(sig.PublicKey)
+
+	tests := []struct {
+		name        string
+		fields      SignatureFields
+		parserWorks bool
+		match       Interpretation
+		fixes       int
+	}{
+		{
+			name:        "parser format",
+			fields:      SignatureFields{R: fmt.Sprintf("0x%064x", sig.R), S: fmt.Sprintf("0x%064x", sig.S), Message: string(message), PublicKey: publicKey},
+			parserWorks: true,
+			match:       Interpretation{R: ReadInteger, S: ReadInteger, Message: MessageText},
+		},
+		{
+			name:        "raw signature bytes",
+			fields:      SignatureFields{Signature: hex.EncodeToString(append(r, s...)), Message: "0x" + hex.EncodeToString(message), PublicKey: publicKey},
+			parserWorks: true,
+			match:       Interpretation{R: ReadBytes, S: ReadBytes, Message: MessageHex},
+		},
+		{
+			name:   "raw r and s bytes",
+			fields: SignatureFields{R: hex.EncodeToString(r), S: hex.EncodeToString(s), Message: "0x" + hex.EncodeToString(message), PublicKey: publicKey},
+			match:  Interpretation{R: ReadBytes, S: ReadBytes, Message: MessageHex},
+			fixes:  2,
+		},
+		{
+			name:   "base64 message",
+			fields: SignatureFields{R: sig.R.String(), S: sig.S.String(), Message: base64.StdEncoding.EncodeToString(message), PublicKey: publicKey},
+			match:  Interpretation{R: ReadInteger, S: ReadInteger, Message: MessageBase64},
+			fixes:  1,
+		},
+		{
+			name:   "reversed public key",
+			fields: SignatureFields{R: sig.R.String(), S: sig.S.String(), Message: string(message), PublicKey: hex.EncodeToString(reversedBytes(sig.PublicKey))},
+			match:  Interpretation{R: ReadInteger, S: ReadInteger, Message: MessageText, PublicKeyReversed: true},
+			fixes:  1,
+		},
+		{
+			name:        "unreduced s",
+			fields:      SignatureFields{R: sig.R.String(), S: new(big.Int).Add(sig.S, Ed25519CurveOrder).String(), Message: string(message), PublicKey: publicKey},
+			parserWorks: true,
+			match:       Interpretation{R: ReadInteger, S: ReadInteger, SReduced: true, Message: MessageText},
+			fixes:       1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Diagnose(tt.fields)
+			if err != nil {
+				t.Fatalf("Diagnose: %v", err)
+			}
+			if d.Parser.Verifies != tt.parserWorks {
+				t.Errorf("Expected the parser's reading to verify: %v, got %v", tt.parserWorks, d.Parser.Verifies)
+			}
+			if d.Match == nil {
+				t.Fatal("Expected a matching interpretation")
+			}
+			got := *d.Match
+			got.Verifies, got.signature = false, nil
+			if got != tt.match {
+				t.Errorf("Expected match %+v, got %+v", tt.match, got)
+			}
+			if len(d.Fixes) != tt.fixes {
+				t.Errorf("Expected %d fixes, got %q", tt.fixes, d.Fixes)
+			}
+
+			// The fixed fields parse to a signature that verifies as is
+			fixed, err := Diagnose(*d.Fixed)
+			if err != nil || !fixed.Parser.Verifies {
+				t.Errorf("Fixed fields %+v do not verify with the parser's reading (%v)", d.Fixed, err)
+			}
+		})
+	}
+}
+
+func TestDiagnose_NoMatch(t *testing.T) {
+	sig := signWithNonce(big.NewInt(424242), big.NewInt(987654321), []byte("signed"))
+	d, err := Diagnose(SignatureFields{R: sig.R.String(), S: sig.S.String(), Message: "not signed", PublicKey: hex.EncodeToString(sig.PublicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Match != nil || d.Fixed != nil || len(d.Tried) == 0 {
+		t.Errorf("Expected no match among %d interpretations, got %+v", len(d.Tried), d.Match)
+	}
+
+	for _, fields := range []SignatureFields{
+		{R: "1", S: "2", Message: "m"},
+		{Signature: "abcd", Message: "m", PublicKey: hex.EncodeToString(sig.PublicKey)},
+		{R: "not a number", S: "2", Message: "m", PublicKey: hex.EncodeToString(sig.PublicKey)},
+	} {
+		if _, err := Diagnose(fields); err == nil {
+			t.Errorf("Expected an error for %+v", fields)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/eta"
+)
+
+// calibrationDuration is how long EstimateSearch measures the search rate.
+const calibrationDuration = 300 * time.Millisecond
+
+// Approximate heap cost of the search's per-signature and per-pair state.
+const (
+	signatureBytes = 384 // Signature with R, S, message and public key
+	pointBytes     = 160 // decoded R point for the point filter
+	pairBytes      = 16  // [2]int pair index
+	babyStepBytes  = 40  // baby-step map entry including map overhead
+)
+
+// PhaseEstimate is the size and worst-case duration of one search phase.
+type PhaseEstimate struct {
+	Name       string
+	Phase      string // the phase name the search reports in its Metrics while it runs
+	ARange     [2]int // zero for pattern phases
+	BRange     [2]int // zero for pattern phases
+	Candidates float64
+	Parallel   bool
+	Duration   time.Duration
+}
+
+// SearchEstimate is the size, worst-case wall time and memory of a SmartBruteForceStrategy search.
+// The baby-step giant-step and b derivation phases take seconds and only count toward memory.
+type SearchEstimate struct {
+	// Pairs is the number of signature pairs searched
+	Pairs int
+
+	// Phases lists the pattern and range phases in search order
+	Phases []PhaseEstimate
+
+	// Candidates is the total number of (a, b) candidates over all phases
+	Candidates float64
+
+	// Duration is the worst-case wall time, i.e. when no phase finds the key
+	Duration time.Duration
+
+	// MemoryBytes is the approximate peak heap the search needs
+	MemoryBytes int64
+
+	// Rate is the calibrated search rate the durations are based on
+	Rate SearchRate
+}
+
+// EstimateSearch estimates the cost of searching numSignatures signatures with config,
+// using a short calibration run to measure this machine's search rate.
+// Use it before launching a wide search: Phase 4-sized ranges take days to weeks.
+func EstimateSearch(config RangeConfig, numSignatures int) SearchEstimate {
+	rate := MeasureSearchRate(context.Background(), calibrationDuration, config.NumWorkers)
+	return EstimateSearchWithRate(config, numSignatures, rate)
+}
+
+// EstimateSearchWithRate is EstimateSearch with a known search rate (e.g. from MeasureSearchRate).
+func EstimateSearchWithRate(config RangeConfig, numSignatures int, rate SearchRate) SearchEstimate {
+	s := NewSmartBruteForceStrategy().WithRangeConfig(config)
+
+	pairs := numSignatures * (numSignatures - 1) / 2
+	if config.MaxPairs > 0 && pairs > config.MaxPairs {
+		pairs = config.MaxPairs
+	}
+	if pairs < 0 {
+		pairs = 0
+	}
+
+	// Sequential phases run on a single worker; throttling caps both
+	parallelRate, sequentialRate := eta.Rates(rate.PerSecond(), rate.Workers, config.MaxCPUPercent, config.MaxRate)
+
+	estimate := SearchEstimate{
+		Pairs:       pairs,
+		Rate:        rate,
+		MemoryBytes: int64(numSignatures)*signatureBytes + int64(pairs)*pairBytes,
+	}
+	if config.PointFilter {
+		estimate.MemoryBytes += int64(numSignatures) * pointBytes
+	}
+	if config.CounterOffsetBound > 0 {
+		if solver, err := newOffsetSolver(config.CounterOffsetBound); err == nil {
+			estimate.MemoryBytes += solver.m * babyStepBytes
+		}
+	}
+
+	if s.PatternConfig.IncludeCommonPatterns {
+		patterns := 0
+		for _, p := range s.getCommonPatterns() {
+			patterns++
+			if s.RangeConfig.BothDirections && centered(p.A).CmpAbs(big.NewInt(1)) > 0 {
+				patterns++
+			}
+		}
+		candidates := float64(patterns * pairs)
+		estimate.Phases = append(estimate.Phases, PhaseEstimate{
+			Name:       "Common patterns",
+			Phase:      commonPatternsPhase,
+			Candidates: candidates,
+			Duration:   eta.Duration(candidates, sequentialRate),
+		})
+	}
+	for _, r := range s.rangePhases() {
+		candidates := float64(s.rangeCombinations(r.aRange, r.bRange)) * float64(pairs)
+		parallel := s.rangeCombinations(r.aRange, r.bRange) > parallelThreshold
+		perSecond := sequentialRate
+		if parallel {
+			perSecond = parallelRate
+		}
+		estimate.Phases = append(estimate.Phases, PhaseEstimate{
+			Name:       r.name,
+			Phase:      r.name,
+			ARange:     r.aRange,
+			BRange:     r.bRange,
+			Candidates: candidates,
+			Parallel:   parallel,
+			Duration:   eta.Duration(candidates, perSecond),
+		})
+	}
+
+	for _, phase := range estimate.Phases {
+		estimate.Candidates += phase.Candidates
+		estimate.Duration = eta.Add(estimate.Duration, phase.Duration)
+	}
+	return estimate
+}
@@ -0,0 +1,85 @@
+package eddsaaffine
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEstimateSearchWithRate(t *testing.T) {
+	rate := SearchRate{Workers: 4, Candidates: 4000, Elapsed: time.Second}
+	estimate := EstimateSearchWithRate(DefaultRangeConfig(), 10, rate)
+
+	if estimate.Pairs != 45 {
+		t.Errorf("Expected 45 pairs, got %d", estimate.Pairs)
+	}
+	if len(estimate.Phases) != 8 {
+		t.Fatalf("Expected common patterns and 7 range phases, got %d phases", len(estimate.Phases))
+	}
+
+	// Phase 2a: 111 combinations per pair, sequential at 1000/sec
+	phase := estimate.Phases[1]
+	if phase.Candidates != 111*45 || phase.Parallel {
+		t.Errorf("Unexpected Phase 2a estimate: %+v", phase)
+	}
+	if phase.Duration != 4995*time.Millisecond {
+		t.Errorf("Expected Phase 2a to take 4.995s, got %v", phase.Duration)
+	}
+
+	// Phase 4: 100 * 500500001 combinations per pair, a >= 2 in both directions, parallel
+	// at 4000/sec
+	phase = estimate.Phases[7]
+	if !phase.Parallel || phase.Candidates != 199*500500001*45 {
+		t.Errorf("Unexpected Phase 4 estimate: %+v", phase)
+	}
+	if estimate.Duration < 7*24*time.Hour {
+		t.Errorf("Expected a default search to take weeks at this rate, got %v", estimate.Duration)
+	}
+	if estimate.MemoryBytes <= 0 {
+		t.Errorf("Expected a memory estimate, got %d", estimate.MemoryBytes)
+	}
+
+	// A custom range replaces the schedule; MaxPairs caps the pairs
+	config := DefaultRangeConfig()
+	config.ARange = [2]int{1, 2}
+	config.BRange = [2]int{0, 9}
+	config.MaxPairs = 5
+	estimate = EstimateSearchWithRate(config, 10, rate)
+	if estimate.Pairs != 5 || len(estimate.Phases) != 2 || estimate.Phases[1].Candidates != 150 {
+		t.Errorf("Unexpected custom range estimate: %+v", estimate)
+	}
+
+	// One direction only: a = 2 is tried once per pair
+	config.BothDirections = false
+	estimate = EstimateSearchWithRate(config, 10, rate)
+	if estimate.Phases[1].Candidates != 100 {
+		t.Errorf("Expected 100 candidates in one direction, got %v", estimate.Phases[1].Candidates)
+	}
+}
+
+func TestEstimateSearchWithRate_Throttled(t *testing.T) {
+	rate := SearchRate{Workers: 4, Candidates: 4000, Elapsed: time.Second}
+	config := DefaultRangeConfig()
+	config.MaxRate = 500
+	estimate := EstimateSearchWithRate(config, 10, rate)
+
+	// Phase 2a: 4995 candidates, capped at 500/sec
+	if phase := estimate.Phases[1]; phase.Duration != 9990*time.Millisecond {
+		t.Errorf("Expected throttled Phase 2a to take 9.99s, got %v", phase.Duration)
+	}
+}
+
+// TestEstimateSearchWithRate_Saturates estimates a default search at one candidate a
+// minute, longer than a time.Duration holds: durations must saturate, not wrap negative.
+func TestEstimateSearchWithRate_Saturates(t *testing.T) {
+	rate := SearchRate{Workers: 1, Candidates: 1, Elapsed: time.Minute}
+	estimate := EstimateSearchWithRate(DefaultRangeConfig(), 1000, rate)
+	for _, phase := range estimate.Phases {
+		if phase.Duration < 0 {
+			t.Errorf("%s: negative duration %v", phase.Name, phase.Duration)
+		}
+	}
+	if estimate.Duration != time.Duration(math.MaxInt64) {
+		t.Errorf("Expected the total to saturate, got %v", estimate.Duration)
+	}
+}
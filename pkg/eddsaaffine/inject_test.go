@@ -0,0 +1,49 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSmartBruteForceStrategy_Inject(t *testing.T) {
+	a := big.NewInt(424242)
+	publicKey := publicKeyFor(a)
+	signatures := []*Signature{
+		signWithNonce(a, big.NewInt(1000001), []byte("message 1")),
+		signWithNonce(a, big.NewInt(7777777), []byte("message 2")),
+	}
+	step := PatternsForSteps([]int64{7777777 - 1000001})
+	newStrategy := func(bRange [2]int, inject <-chan []Pattern) *SmartBruteForceStrategy {
+		strategy := NewSmartBruteForceStrategy().WithPatternConfig(PatternConfig{Inject: inject})
+		strategy.RangeConfig = RangeConfig{ARange: [2]int{1, 1}, BRange: bRange, MaxPairs: 1, NumWorkers: 2}
+		return strategy
+	}
+
+	// Injected before the search: tried at the first safe point, before any range phase
+	inject := make(chan []Pattern, 1)
+	inject <- step
+	result := newStrategy([2]int{0, 9}, inject).Search(context.Background(), signatures, publicKey)
+	if result == nil || result.Pattern != step[0].Name {
+		t.Fatalf("Expected the injected pattern to find the key, got %+v", result)
+	}
+
+	// Injected while the range phase runs: the phase is interrupted for the key
+	inject = make(chan []Pattern)
+	defer close(inject)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		inject <- step
+	}()
+	result = newStrategy([2]int{0, math.MaxInt32}, inject).Search(ctx, signatures, publicKey)
+	if result == nil || result.Pattern != step[0].Name {
+		t.Fatalf("Expected the pattern injected during the range phase to find the key, got %+v", result)
+	}
+	if ctx.Err() != nil {
+		t.Error("Expected the key before the timeout")
+	}
+}
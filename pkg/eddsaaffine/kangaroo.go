@@ -0,0 +1,454 @@
+package eddsaaffine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+
+	"filippo.io/edwards25519"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/lifecycle"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/tablefile"
+)
+
+// DistinguishedPoint is a tame kangaroo position whose key has DistinguishedBits low zero bits.
+// The point equals Log*B.
+type DistinguishedPoint struct {
+	Key uint64
+	Log int64
+}
+
+// DPStore holds tame distinguished points. Tame walks depend only on the curve, the interval
+// and the jump set, so a store can be shared across signature pairs (and persisted).
+// Implementations must be safe for concurrent use.
+type DPStore interface {
+	// Put records p and returns a previously stored point with the same key, if any.
+	Put(p DistinguishedPoint) (DistinguishedPoint, bool)
+
+	// Get returns the stored point with the given key, if any.
+	Get(key uint64) (DistinguishedPoint, bool)
+}
+
+// MemoryDPStore is an in-memory DPStore.
+type MemoryDPStore struct {
+	mu     sync.RWMutex
+	points map[uint64]int64
+}
+
+// NewMemoryDPStore creates an empty in-memory store.
+func NewMemoryDPStore() *MemoryDPStore {
+	return &MemoryDPStore{points: make(map[uint64]int64)}
+}
+
+// Put implements DPStore.
+func (m *MemoryDPStore) Put(p DistinguishedPoint) (DistinguishedPoint, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.points[p.Key]; ok {
+		return DistinguishedPoint{Key: p.Key, Log: v}, true
+	}
+	m.points[p.Key] = p.Log
+	return DistinguishedPoint{}, false
+}
+
+// Get implements DPStore.
+func (m *MemoryDPStore) Get(key uint64) (DistinguishedPoint, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.points[key]
+	return DistinguishedPoint{Key: key, Log: v}, ok
+}
+
+// Points returns a snapshot of the stored points.
+func (m *MemoryDPStore) Points() []DistinguishedPoint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	points := make([]DistinguishedPoint, 0, len(m.points))
+	for key, v := range m.points {
+		points = append(points, DistinguishedPoint{Key: key, Log: v})
+	}
+	return points
+}
+
+// Len returns the number of stored points.
+func (m *MemoryDPStore) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.points)
+}
+
+// kangarooTargets is the number of targets Solve walks for each pair (R2 - R1).
+const kangarooTargets = 1
+
+// KangarooSolver solves D = b*B for b in [Lower, Upper] with Pollard's kangaroo (lambda)
+// method. It needs O(sqrt(Upper-Lower)) group operations like BSGS, but only stores
+// distinguished points, and its walks run in parallel.
+type KangarooSolver struct {
+	// Lower and Upper bound the interval searched for b (inclusive)
+	Lower, Upper int64
+
+	// Workers is the number of parallel tame/wild walkers (0 = runtime.NumCPU())
+	Workers int
+
+	// DistinguishedBits is the number of low zero key bits marking a distinguished point
+	// (0 = chosen from the interval width and worker count)
+	DistinguishedBits uint
+
+	// Seed offsets the walkers' random restarts; a single-worker solve with the same seed
+	// takes the same walks on every run
+	Seed int64
+
+	// Store holds tame distinguished points; reuse it across Solve calls with the same
+	// interval and worker count to skip repeated tame work
+	Store DPStore
+}
+
+// NewKangarooSolver creates a solver for b in [lower, upper] with an in-memory store.
+func NewKangarooSolver(lower, upper int64) *KangarooSolver {
+	return &KangarooSolver{
+		Lower: lower,
+		Upper: upper,
+		Store: NewMemoryDPStore(),
+	}
+}
+
+// Solve returns b in [Lower, Upper] with R2 - R1 = b*B, i.e. the step of a counter nonce.
+func (k *KangarooSolver) Solve(ctx context.Context, sig1, sig2 *Signature) (*big.Int, bool) {
+	R1, err := DecodeR(sig1.R)
+	if err != nil {
+		return nil, false
+	}
+	R2, err := DecodeR(sig2.R)
+	if err != nil {
+		return nil, false
+	}
+	D := edwards25519.NewIdentityPoint().Subtract(R2, R1)
+	_, b, ok := k.SolvePoints(ctx, []*edwards25519.Point{D})
+	if !ok {
+		return nil, false
+	}
+	return big.NewInt(b), true
+}
+
+// SolvePoints finds b in [Lower, Upper] with targets[i] = b*B for any target.
+// Wild kangaroos for all targets share one tame herd.
+//
+// Returns:
+//   - Index of the solved target, b, and true if found before the step limit
+func (k *KangarooSolver) SolvePoints(ctx context.Context, targets []*edwards25519.Point) (int, int64, bool) {
+	if len(targets) == 0 || k.Upper < k.Lower {
+		return 0, 0, false
+	}
+	width := k.Upper - k.Lower
+	workers, dpBits := k.resolve(len(targets))
+	store := k.Store
+	if store == nil {
+		store = NewMemoryDPStore()
+	}
+
+	sqrtW := math.Sqrt(float64(width) + 1)
+	herd := float64(workers * len(targets))
+	dpMask := uint64(1)<<dpBits - 1
+
+	// Jump sizes 2^0..2^(n-1) with mean close to herd*sqrt(W)/4
+	target := math.Max(1, herd*sqrtW/4)
+	var sizes []int64
+	for n := 1; n < 62; n++ {
+		sizes = sizes[:0]
+		for i := 0; i < n; i++ {
+			sizes = append(sizes, int64(1)<<uint(i))
+		}
+		if float64(int64(1)<<uint(n)-1)/float64(n) >= target {
+			break
+		}
+	}
+	jumps := make([]*edwards25519.Point, len(sizes))
+	for i, size := range sizes {
+		jumps[i] = edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(big.NewInt(size)))
+	}
+	maxSteps := int64(16*sqrtW/float64(workers)) + 16*int64(dpMask+1)
+
+	identity := edwards25519.NewIdentityPoint()
+
+	group, ctx := lifecycle.WithContext(ctx)
+
+	var (
+		mu    sync.Mutex
+		wild  = make(map[uint64][2]int64) // key -> (target index, distance)
+		found bool
+		index int
+		b     int64
+	)
+	// resolve verifies a tame/wild collision exactly and records the result
+	resolve := func(t int, tameLog, wildDist int64) {
+		candidate := tameLog - wildDist
+		expected := edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(big.NewInt(candidate)))
+		if candidate < k.Lower || candidate > k.Upper || expected.Equal(targets[t]) != 1 {
+			return
+		}
+		mu.Lock()
+		if !found {
+			found, index, b = true, t, candidate
+		}
+		mu.Unlock()
+		group.Stop()
+	}
+
+	walk := func(w int) {
+		rng := rand.New(rand.NewSource(k.Seed + int64(w) + 1))
+		spacing := int64(math.Max(1, target/herd))
+
+		type walker struct {
+			point *edwards25519.Point
+			dist  int64 // tame: log of point; wild: distance from target
+			t     int   // -1 for tame
+		}
+		newTame := func(offset int64) walker {
+			var wk walker
+			wk.t = -1
+			wk.dist = k.Lower + width/2 + offset
+			wk.point = edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(big.NewInt(wk.dist)))
+			return wk
+		}
+		newWild := func(t int, offset int64) walker {
+			var wk walker
+			wk.t = t
+			wk.dist = offset
+			wk.point = edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(big.NewInt(offset)))
+			wk.point.Add(wk.point, targets[t])
+			return wk
+		}
+
+		walkers := []walker{newTame(int64(w) * spacing)}
+		for t := range targets {
+			walkers = append(walkers, newWild(t, int64(w)*spacing))
+		}
+
+		for step := int64(0); step < maxSteps; step++ {
+			if step&1023 == 0 && ctx.Err() != nil {
+				return
+			}
+			for i := range walkers {
+				wk := &walkers[i]
+				if wk.point.Equal(identity) == 1 {
+					if wk.t >= 0 {
+						resolve(wk.t, 0, wk.dist)
+					}
+					return
+				}
+				key := pointKey(wk.point)
+
+				if key&dpMask == 0 {
+					if wk.t < 0 {
+						if _, dup := store.Put(DistinguishedPoint{Key: key, Log: wk.dist}); dup {
+							// Following an existing tame path: restart elsewhere
+							*wk = newTame(rng.Int63n(width/2 + 1))
+							continue
+						}
+						mu.Lock()
+						hit, ok := wild[key]
+						mu.Unlock()
+						if ok {
+							resolve(int(hit[0]), wk.dist, hit[1])
+						}
+					} else {
+						if tame, ok := store.Get(key); ok {
+							resolve(wk.t, tame.Log, wk.dist)
+						}
+						mu.Lock()
+						_, dup := wild[key]
+						if !dup {
+							wild[key] = [2]int64{int64(wk.t), wk.dist}
+						}
+						mu.Unlock()
+						if dup {
+							*wk = newWild(wk.t, rng.Int63n(width/2+1))
+							continue
+						}
+					}
+				}
+
+				j := (key >> 32) % uint64(len(jumps))
+				wk.point.Add(wk.point, jumps[j])
+				wk.dist += sizes[j]
+			}
+		}
+	}
+	for w := 0; w < workers; w++ {
+		w := w
+		group.Go(func() error {
+			walk(w)
+			return nil
+		})
+	}
+	group.Wait()
+
+	return index, b, found
+}
+
+// resolve returns the worker count and distinguished-point bits used for a solve with
+// the given number of targets; both determine the tame walks.
+func (k *KangarooSolver) resolve(targets int) (int, uint) {
+	workers := k.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	dpBits := k.DistinguishedBits
+	if dpBits == 0 {
+		// About 32 distinguished points per walker over the expected walk length
+		sqrtW := math.Sqrt(float64(k.Upper-k.Lower) + 1)
+		if per := sqrtW / float64(workers*targets) / 32; per > 2 {
+			dpBits = uint(math.Log2(per))
+		}
+	}
+	return workers, dpBits
+}
+
+// SaveStore writes the tame distinguished points to path for reuse with LoadKangarooSolver.
+// The file records the interval, worker count and distinguished bits, which together fix
+// the tame walks; the store must be a *MemoryDPStore.
+func (k *KangarooSolver) SaveStore(path string) error {
+	store, ok := k.Store.(*MemoryDPStore)
+	if !ok {
+		return fmt.Errorf("cannot save store of type %T", k.Store)
+	}
+	points := store.Points()
+	entries := make([]tablefile.Entry, len(points))
+	for i, p := range points {
+		entries[i] = tablefile.Entry{Key: p.Key, Value: p.Log}
+	}
+	return tablefile.Write(path, k.tableHeader(), entries)
+}
+
+// LoadKangarooSolver restores a solver and its tame distinguished points written by SaveStore.
+func LoadKangarooSolver(path string) (*KangarooSolver, error) {
+	table, err := tablefile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer table.Close()
+
+	h := table.Header
+	if h.Kind != tablefile.KindDistinguishedPoints || h.Curve != "ed25519" {
+		return nil, fmt.Errorf("%s: %w", path, tablefile.ErrMismatch)
+	}
+	k := NewKangarooSolver(h.Params[0], h.Params[1])
+	k.Workers = int(h.Params[2])
+	k.DistinguishedBits = uint(h.Params[3])
+
+	store := k.Store.(*MemoryDPStore)
+	for i := 0; i < table.Len(); i++ {
+		e := table.Entry(i)
+		store.points[e.Key] = e.Value
+	}
+	return k, nil
+}
+
+func (k *KangarooSolver) tableHeader() tablefile.Header {
+	workers, dpBits := k.resolve(kangarooTargets)
+	return tablefile.Header{
+		Kind:   tablefile.KindDistinguishedPoints,
+		Curve:  "ed25519",
+		Params: [4]int64{k.Lower, k.Upper, int64(workers), int64(dpBits)},
+	}
+}
+
+// KangarooStrategy finds counter nonces (k2 = k1 + b) with b in a large interval using
+// Pollard's kangaroo method. Compared to BSGSStrategy it needs almost no memory and scales
+// with Workers, at the cost of a probabilistic running time.
+type KangarooStrategy struct {
+	Solver *KangarooSolver
+
+	// MaxPairs limits the number of signature pairs to test
+	MaxPairs int
+
+	// StorePath, if set, is where tame distinguished points are loaded from before the
+	// first search and saved to after each search, so later campaigns reuse tame work
+	StorePath string
+
+	once sync.Once
+}
+
+// NewKangarooStrategy creates a kangaroo strategy for b in [lower, upper].
+func NewKangarooStrategy(lower, upper int64) *KangarooStrategy {
+	return &KangarooStrategy{
+		Solver:   NewKangarooSolver(lower, upper),
+		MaxPairs: 100,
+	}
+}
+
+// Name returns the name of this strategy.
+func (s *KangarooStrategy) Name() string {
+	return "Kangaroo"
+}
+
+// Search implements the BruteForceStrategy interface.
+func (s *KangarooStrategy) Search(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	if s.StorePath != "" {
+		s.once.Do(s.loadStore)
+		defer func() {
+			if err := s.Solver.SaveStore(s.StorePath); err != nil {
+				log.Printf("Failed to save distinguished points: %v", err)
+			}
+		}()
+	}
+
+	maxPairs := s.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = 100
+	}
+
+	pairCount := 0
+	for i := 0; i < len(signatures) && pairCount < maxPairs; i++ {
+		for j := i + 1; j < len(signatures) && pairCount < maxPairs; j++ {
+			if ctx.Err() != nil {
+				return nil
+			}
+			pairCount++
+
+			if b, ok := s.Solver.Solve(ctx, signatures[i], signatures[j]); ok {
+				if result := confirmCounterOffset(signatures, i, j, b, publicKey, "kangaroo_counter"); result != nil {
+					return result
+				}
+			}
+		}
+	}
+	log.Printf("Kangaroo: checked %d pairs, no counter offset with b in [%d, %d]", pairCount, s.Solver.Lower, s.Solver.Upper)
+	return nil
+}
+
+// loadStore adds the tame distinguished points saved at StorePath to the solver's store
+// if they cover the same interval.
+func (s *KangarooStrategy) loadStore() {
+	loaded, err := LoadKangarooSolver(s.StorePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Ignoring distinguished points: %v", err)
+		}
+		return
+	}
+	if loaded.Lower != s.Solver.Lower || loaded.Upper != s.Solver.Upper {
+		log.Printf("Ignoring distinguished points in %s: saved for [%d, %d]", s.StorePath, loaded.Lower, loaded.Upper)
+		return
+	}
+
+	// The solver keeps the caller's worker count and seed: points saved from walks with
+	// another worker count (and so another jump set) are still valid, fewer are just met
+	points := loaded.Store.(*MemoryDPStore).Points()
+	if s.Solver.Store == nil {
+		s.Solver.Store = NewMemoryDPStore()
+	}
+	for _, p := range points {
+		s.Solver.Store.Put(p)
+	}
+	log.Printf("Loaded %d tame distinguished points from %s", len(points), s.StorePath)
+	if workers, _ := s.Solver.resolve(kangarooTargets); workers != loaded.Workers {
+		log.Printf("Distinguished points in %s were saved with %d workers, searching with %d: fewer of them lie on this search's walks", s.StorePath, loaded.Workers, workers)
+	}
+}
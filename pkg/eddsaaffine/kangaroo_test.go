@@ -0,0 +1,97 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+func TestKangarooStrategy_Search(t *testing.T) {
+	a := big.NewInt(987654)
+
+	for _, step := range []int64{200000000, -123456789} {
+		signatures := testNonceChain(a, big.NewInt(1), big.NewInt(step), 2)
+
+		strategy := NewKangarooStrategy(-1<<28, 1<<28)
+		strategy.Solver.Workers = 2
+		result := strategy.Search(context.Background(), signatures, nil)
+		if result == nil {
+			t.Fatalf("Expected recovery for step %d", step)
+		}
+		if result.PrivateKey.Cmp(a) != 0 {
+			t.Errorf("Expected private key %s, got %s", a, result.PrivateKey)
+		}
+		if result.Relationship.B.Int64() != step {
+			t.Errorf("Expected b=%d, got %s", step, result.Relationship.B)
+		}
+	}
+}
+
+func TestKangarooSolver_ReusesTameStore(t *testing.T) {
+	store := NewMemoryDPStore()
+	solver := NewKangarooSolver(0, 1<<26)
+	solver.Workers = 2
+	solver.Store = store
+
+	for _, step := range []int64{12345678, 56789012} {
+		signatures := testNonceChain(big.NewInt(987654), big.NewInt(1), big.NewInt(step), 2)
+		b, ok := solver.Solve(context.Background(), signatures[0], signatures[1])
+		if !ok || b.Int64() != step {
+			t.Errorf("Expected b=%d, got %v", step, b)
+		}
+	}
+	if store.Len() == 0 {
+		t.Error("Expected tame distinguished points in the shared store")
+	}
+}
+
+func TestKangarooStrategy_StorePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tame.tbl")
+	a := big.NewInt(987654)
+
+	for _, step := range []int64{12345678, 56789012} {
+		strategy := NewKangarooStrategy(0, 1<<26)
+		strategy.Solver.Workers = 2
+		strategy.StorePath = path
+
+		signatures := testNonceChain(a, big.NewInt(1), big.NewInt(step), 2)
+		result := strategy.Search(context.Background(), signatures, nil)
+		if result == nil || result.Relationship.B.Int64() != step {
+			t.Fatalf("Expected recovery for step %d", step)
+		}
+	}
+
+	loaded, err := LoadKangarooSolver(path)
+	if err != nil {
+		t.Fatalf("LoadKangarooSolver: %v", err)
+	}
+	if loaded.Lower != 0 || loaded.Upper != 1<<26 || loaded.Workers != 2 {
+		t.Errorf("Parameters not restored: %+v", loaded)
+	}
+	if loaded.Store.(*MemoryDPStore).Len() == 0 {
+		t.Error("Expected saved tame distinguished points")
+	}
+}
+
+func TestKangarooStrategy_LoadStoreKeepsWorkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tame.tbl")
+	saved := NewKangarooSolver(0, 1<<26)
+	saved.Workers = 1
+	saved.Store.(*MemoryDPStore).Put(DistinguishedPoint{Key: 0xabc000, Log: 12345})
+	if err := saved.SaveStore(path); err != nil {
+		t.Fatalf("SaveStore: %v", err)
+	}
+
+	strategy := NewKangarooStrategy(0, 1<<26)
+	strategy.Solver.Workers = 3
+	strategy.Solver.Seed = 7
+	strategy.StorePath = path
+	strategy.loadStore()
+	if strategy.Solver.Workers != 3 || strategy.Solver.Seed != 7 {
+		t.Errorf("Expected the caller's workers and seed to stay, got %+v", strategy.Solver)
+	}
+	if p, ok := strategy.Solver.Store.Get(0xabc000); !ok || p.Log != 12345 {
+		t.Errorf("Expected the saved point in the solver's store, got %+v, %v", p, ok)
+	}
+}
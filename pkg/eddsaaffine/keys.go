@@ -0,0 +1,233 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// SignatureGroup is the signatures in a dataset made by one signer.
+type SignatureGroup struct {
+	PublicKey  []byte       // per-signature public key shared by the group (nil if the records have none)
+	Signatures []*Signature // signatures in dataset order
+	Indices    []int        // position of each signature in the original dataset
+}
+
+// GroupByPublicKey splits signatures by their per-signature PublicKey, in order of first
+// appearance. Datasets such as Solana transaction dumps mix many signers; only signatures
+// by the same signer can share a nonce relationship, so each group is searched on its own.
+func GroupByPublicKey(signatures []*Signature) []*SignatureGroup {
+	var groups []*SignatureGroup
+	byKey := make(map[string]*SignatureGroup)
+	for i, sig := range signatures {
+		group, ok := byKey[string(sig.PublicKey)]
+		if !ok {
+			group = &SignatureGroup{PublicKey: sig.PublicKey}
+			byKey[string(sig.PublicKey)] = group
+			groups = append(groups, group)
+		}
+		group.Signatures = append(group.Signatures, sig)
+		group.Indices = append(group.Indices, i)
+	}
+	return groups
+}
+
+// KeyResult is the outcome of recovery for one signer of a mixed-key dataset.
+type KeyResult struct {
+	PublicKey  []byte          // the signer's public key (nil for signatures without one)
+	Signatures int             // number of signatures by this signer
+	Result     *RecoveryResult // nil if no key was recovered; SignaturePair indexes the whole dataset
+}
+
+// RecoverKeys recovers keys from a file whose signatures may come from many signers.
+// See RecoverKeysFromSignatures.
+func (c *Client) RecoverKeys(ctx context.Context, source string) ([]*KeyResult, error) {
+	signatures, err := c.parser.ParseSignatures(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signatures: %w", err)
+	}
+	return c.RecoverKeysFromSignatures(ctx, signatures)
+}
+
+// RecoverKeysFromSignatures groups signatures by their per-signature public key and runs
+// the strategy on each group, verifying candidates against the group's key. Signers that
+// share a nonce with another signer are then recovered from it, even with a single
+// signature (see FindSharedNonces). It returns one KeyResult per signer, in order of
+// first appearance; signers whose key was not recovered are reported with a nil Result.
+func (c *Client) RecoverKeysFromSignatures(ctx context.Context, signatures []*Signature) ([]*KeyResult, error) {
+	if len(signatures) < 2 {
+		return nil, fmt.Errorf("need at least 2 signatures, got %d", len(signatures))
+	}
+	if err := c.checkInputs(signatures, nil); err != nil {
+		return nil, err
+	}
+
+	var results []*KeyResult
+	groups := GroupByPublicKey(signatures)
+	for _, group := range groups {
+		keyResult := &KeyResult{PublicKey: group.PublicKey, Signatures: len(group.Signatures)}
+		results = append(results, keyResult)
+		if len(group.Signatures) < 2 {
+			continue
+		}
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		keyResult.Result = group.remap(c.strategy.Search(ctx, group.Signatures, group.PublicKey))
+	}
+	recoverSharedNonces(signatures, groups, results)
+	return results, nil
+}
+
+// FindSharedNonces returns the pairs of signatures (i < j, indexes into signatures) by
+// different signers (see GroupByPublicKey) with the same R: the two signers used the same
+// nonce. Signatures without a public key are skipped.
+func FindSharedNonces(signatures []*Signature) [][2]int {
+	signer := signerIndex(GroupByPublicKey(signatures), len(signatures))
+	var pairs [][2]int
+	byR := make(map[string][]int)
+	for j, sig := range signatures {
+		if signer[j] < 0 || sig.R == nil {
+			continue
+		}
+		r := string(sig.R.Bytes())
+		for _, i := range byR[r] {
+			if signer[i] != signer[j] {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+		byR[r] = append(byR[r], j)
+	}
+	return pairs
+}
+
+// signerIndex maps each signature to its group, or -1 for the group without public key.
+func signerIndex(groups []*SignatureGroup, n int) []int {
+	signer := make([]int, n)
+	for g, group := range groups {
+		for _, i := range group.Indices {
+			signer[i] = g
+			if len(group.PublicKey) == 0 {
+				signer[i] = -1
+			}
+		}
+	}
+	return signer
+}
+
+// recoverSharedNonces fills in the results of signers that share a nonce with another
+// signer (see FindSharedNonces), including signers with a single signature: a recovered
+// key gives the shared nonce and with it the other signer's key, and two nonces shared by
+// the same two signers give both keys (RecoverTwoKeysSharedNonce). It repeats until no
+// more keys are found, so a key propagates along chains of signers.
+func recoverSharedNonces(signatures []*Signature, groups []*SignatureGroup, results []*KeyResult) {
+	pairs := FindSharedNonces(signatures)
+	signer := signerIndex(groups, len(signatures))
+	for progress := true; progress; {
+		progress = false
+		for _, pair := range pairs {
+			for _, p := range [][2]int{pair, {pair[1], pair[0]}} {
+				known, unknown := results[signer[p[0]]], results[signer[p[1]]]
+				if known.Result == nil || unknown.Result != nil {
+					continue
+				}
+				r, err := RecoverNonce(signatures[p[0]], known.Result.PrivateKey)
+				if err != nil {
+					continue
+				}
+				a, err := RecoverPrivateKeyFromKnownNonce(signatures[p[1]], r)
+				if err != nil {
+					continue
+				}
+				if ok, _ := VerifyRecoveredKey(a, unknown.PublicKey); ok {
+					unknown.Result = sharedNonceResult(a, p, "shared_nonce_cross_key")
+					progress = true
+				}
+			}
+		}
+
+		for x, p := range pairs {
+			for _, q := range pairs[x+1:] {
+				if signer[p[0]] == signer[q[1]] && signer[p[1]] == signer[q[0]] {
+					q = [2]int{q[1], q[0]}
+				}
+				if signer[p[0]] != signer[q[0]] || signer[p[1]] != signer[q[1]] {
+					continue
+				}
+				first, second := results[signer[p[0]]], results[signer[p[1]]]
+				if first.Result != nil || second.Result != nil {
+					continue
+				}
+				a1, a2, err := RecoverTwoKeysSharedNonce(signatures[p[0]], signatures[p[1]], signatures[q[0]], signatures[q[1]])
+				if err != nil {
+					continue
+				}
+				first.Result = sharedNonceResult(a1, p, "shared_nonce_two_keys")
+				second.Result = sharedNonceResult(a2, p, "shared_nonce_two_keys")
+				progress = true
+			}
+		}
+	}
+}
+
+// sharedNonceResult is the result for a key recovered from a nonce shared across signers.
+func sharedNonceResult(privateKey *big.Int, pair [2]int, pattern string) *RecoveryResult {
+	return &RecoveryResult{
+		PrivateKey:    privateKey,
+		Relationship:  AffineRelationship{A: big.NewInt(1), B: big.NewInt(0)},
+		SignaturePair: pair,
+		Verified:      true,
+		Pattern:       pattern,
+	}
+}
+
+// searchByKey runs the strategy on signatures, honouring per-signature public keys.
+// A dataset with one signer is searched as a whole, verified against publicKey or else
+// the signer's own key. With several signers, each group is searched separately (only
+// the group matching publicKey, if one is given) and the first recovered key is returned.
+func (c *Client) searchByKey(ctx context.Context, signatures []*Signature, publicKey []byte) *RecoveryResult {
+	groups := GroupByPublicKey(signatures)
+	if len(groups) == 1 {
+		if len(publicKey) == 0 {
+			publicKey = groups[0].PublicKey
+		}
+		return c.strategy.Search(ctx, signatures, publicKey)
+	}
+
+	for _, group := range groups {
+		if len(group.Signatures) < 2 || (len(publicKey) > 0 && !bytes.Equal(group.PublicKey, publicKey)) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if result := group.remap(c.strategy.Search(ctx, group.Signatures, group.PublicKey)); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// remap translates a result's SignaturePair from group positions to dataset positions.
+func (g *SignatureGroup) remap(result *RecoveryResult) *RecoveryResult {
+	if result == nil {
+		return nil
+	}
+	remapped := *result
+	remapped.SignaturePair = [2]int{g.Indices[result.SignaturePair[0]], g.Indices[result.SignaturePair[1]]}
+	return &remapped
+}
+
+// verificationKey returns the key to verify a pair's recovered key against: publicKey if
+// given, otherwise the pair's shared per-signature key. ok is false for pairs made by
+// different signers, which cannot share a nonce relationship.
+func verificationKey(sig1, sig2 *Signature, publicKey []byte) (key []byte, ok bool) {
+	if !bytes.Equal(sig1.PublicKey, sig2.PublicKey) {
+		return nil, false
+	}
+	if len(publicKey) > 0 {
+		return publicKey, true
+	}
+	return sig1.PublicKey, true
+}
@@ -0,0 +1,143 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// mixedKeyDataset interleaves signatures by three signers, as in a transaction dump:
+// signer 1 uses counter nonces, signer 2 reuses a nonce, signer 3 signs once. All chains
+// start from the same nonce, so pairs across signers share R and must never be paired.
+func mixedKeyDataset() ([]*Signature, []*big.Int) {
+	keys := []*big.Int{big.NewInt(424242), big.NewInt(777777), big.NewInt(999)}
+	counter := testNonceChain(keys[0], big.NewInt(1), big.NewInt(1), 3)
+	reused := testNonceChain(keys[1], big.NewInt(1), big.NewInt(0), 2)
+	single := testNonceChain(keys[2], big.NewInt(1), big.NewInt(1), 1)
+	return []*Signature{counter[0], reused[0], single[0], counter[1], reused[1], counter[2]}, keys
+}
+
+func TestGroupByPublicKey(t *testing.T) {
+	signatures, keys := mixedKeyDataset()
+	groups := GroupByPublicKey(signatures)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 signers, got %d", len(groups))
+	}
+	want := [][]int{{0, 3, 5}, {1, 4}, {2}}
+	for g, group := range groups {
+		if !bytes.Equal(group.PublicKey, publicKeyFor(keys[g])) {
+			t.Errorf("Group %d has the wrong public key", g)
+		}
+		if len(group.Indices) != len(want[g]) {
+			t.Fatalf("Group %d: expected indices %v, got %v", g, want[g], group.Indices)
+		}
+		for i, idx := range group.Indices {
+			if idx != want[g][i] || group.Signatures[i] != signatures[idx] {
+				t.Errorf("Group %d: expected indices %v, got %v", g, want[g], group.Indices)
+			}
+		}
+	}
+}
+
+func TestClient_RecoverKeysFromSignatures(t *testing.T) {
+	signatures, keys := mixedKeyDataset()
+	results, err := NewClient().RecoverKeysFromSignatures(context.Background(), signatures)
+	if err != nil {
+		t.Fatalf("RecoverKeysFromSignatures failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	for g, keyResult := range results[:2] {
+		result := keyResult.Result
+		if result == nil {
+			t.Fatalf("Signer %d: no key recovered", g)
+		}
+		if result.PrivateKey.Cmp(keys[g]) != 0 || !result.Verified {
+			t.Errorf("Signer %d: expected verified key %s, got %s (verified=%v)", g, keys[g], result.PrivateKey, result.Verified)
+		}
+		for _, idx := range result.SignaturePair {
+			if !bytes.Equal(signatures[idx].PublicKey, keyResult.PublicKey) {
+				t.Errorf("Signer %d: pair %v is not indexed into the dataset", g, result.SignaturePair)
+			}
+		}
+	}
+	// Signer 3 signed once, with the nonce of signer 1's first signature
+	if result := results[2].Result; result == nil || results[2].Signatures != 1 || result.PrivateKey.Cmp(keys[2]) != 0 ||
+		result.SignaturePair != [2]int{0, 2} || result.Pattern != "shared_nonce_cross_key" {
+		t.Errorf("Expected signer 3's key from the nonce shared with signature 0, got %+v", results[2])
+	}
+}
+
+// sharedNonceDataset is two signers that signed with the same two unrelated nonces.
+func sharedNonceDataset() ([]*Signature, []*big.Int) {
+	keys := []*big.Int{big.NewInt(424242), big.NewInt(777777)}
+	nonces := []*big.Int{big.NewInt(0x1234567890), big.NewInt(0xfedcba987654)}
+	var signatures []*Signature
+	for i, r := range nonces {
+		for signer, a := range keys {
+			signatures = append(signatures, signWithNonce(a, r, []byte(fmt.Sprintf("signer %d message %d", signer, i))))
+		}
+	}
+	return signatures, keys
+}
+
+func TestFindSharedNonces(t *testing.T) {
+	signatures, _ := mixedKeyDataset()
+	// Signatures 0, 1, 2 and 4 use the same nonce; 1 and 4 are by the same signer
+	want := [][2]int{{0, 1}, {0, 2}, {1, 2}, {0, 4}, {2, 4}}
+	if got := FindSharedNonces(signatures); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected shared nonces %v, got %v", want, got)
+	}
+}
+
+func TestClient_RecoverKeysFromSignatures_SharedNonces(t *testing.T) {
+	signatures, keys := sharedNonceDataset()
+
+	// Each signer's own nonces are unrelated, so only the shared nonces give the keys
+	strategy := NewSmartBruteForceStrategy().
+		WithPatternConfig(PatternConfig{}).
+		WithRangeConfig(RangeConfig{ARange: [2]int{1, 1}, BRange: [2]int{0, 0}})
+	results, err := NewClient().WithStrategy(strategy).RecoverKeysFromSignatures(context.Background(), signatures)
+	if err != nil {
+		t.Fatalf("RecoverKeysFromSignatures failed: %v", err)
+	}
+	for g, keyResult := range results {
+		result := keyResult.Result
+		if result == nil || result.PrivateKey.Cmp(keys[g]) != 0 || !result.Verified || result.Pattern != "shared_nonce_two_keys" {
+			t.Errorf("Signer %d: expected key %s from the shared nonces, got %+v", g, keys[g], result)
+		}
+	}
+}
+
+func TestClient_RecoverKeyFromSignatures_MixedKeys(t *testing.T) {
+	signatures, keys := mixedKeyDataset()
+	client := NewClient()
+
+	// Without a public key, the first signer with a recoverable key is returned, verified
+	// against its own key
+	result, err := client.RecoverKeyFromSignatures(context.Background(), signatures, "")
+	if err != nil {
+		t.Fatalf("RecoverKeyFromSignatures failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(keys[0]) != 0 || !result.Verified {
+		t.Errorf("Expected verified key %s, got %s (verified=%v)", keys[0], result.PrivateKey, result.Verified)
+	}
+
+	// A public key selects its signer's signatures
+	result, err = client.RecoverKeyFromSignatures(context.Background(), signatures, hex.EncodeToString(publicKeyFor(keys[1])))
+	if err != nil {
+		t.Fatalf("RecoverKeyFromSignatures failed: %v", err)
+	}
+	if result.PrivateKey.Cmp(keys[1]) != 0 || result.SignaturePair != [2]int{1, 4} {
+		t.Errorf("Expected key %s from pair [1, 4], got %s from %v", keys[1], result.PrivateKey, result.SignaturePair)
+	}
+
+	if _, err := client.RecoverKeyFromSignatures(context.Background(), signatures, hex.EncodeToString(publicKeyFor(keys[2]))); err == nil {
+		t.Error("Expected no key for the signer with one signature")
+	}
+}
@@ -1,12 +1,15 @@
 package eddsaaffine
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
-	"os"
 	"strings"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/source"
 )
 
 // SignatureParser defines the interface for parsing signatures from various sources.
@@ -21,23 +24,62 @@ type JSONParser struct {
 	RField       string // Field name for r (default: "r")
 	SField       string // Field name for s (default: "s")
 	PublicKeyField string // Field name for public_key (default: "public_key")
+	SignatureField string // Field name for the 64-byte signature R || S, read when r and s are absent (default: "signature")
+	Endianness Endianness // Byte order of hex r and s values (default: BigEndian)
+}
+
+// Endianness is how JSONParser reads r and s values written as hex. Numbers are
+// integers either way.
+type Endianness string
+
+const (
+	// BigEndian reads hex as the integer, most significant byte first. The integer is the
+	// little-endian reading of the encoded R or S, as the fixtures and ComputeH have it.
+	BigEndian Endianness = "big"
+	// LittleEndian reads hex as the encoded bytes in signature order, as in a raw 64-byte
+	// Ed25519 signature R || S split in two.
+	LittleEndian Endianness = "little"
+)
+
+// ParseEndianness parses "big" or "little", case-insensitively; "" is BigEndian.
+func ParseEndianness(s string) (Endianness, error) {
+	switch e := Endianness(strings.ToLower(s)); e {
+	case "", BigEndian:
+		return BigEndian, nil
+	case LittleEndian:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unknown endianness %q (want big or little)", s)
+	}
 }
 
-// ParseSignatures parses signatures from a JSON file.
+// ParseSignatures parses signatures from a JSON file, given as a path or as an http(s)://,
+// s3:// or gs:// URI (streamed, with the service's standard credentials).
 //
 // Expected format:
 // [
 //   {"message": "hex_string", "r": "hex_string", "s": "hex_string", "public_key": "hex_string"},
+//   {"message": "hex_string", "signature": "hex_or_base64_R||S", "public_key": "hex_string"},
 //   ...
 // ]
 func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
-	file, err := os.Open(jsonFile)
+	file, err := source.Open(jsonFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 	defer file.Close()
 
-	decoder := json.NewDecoder(file)
+	return p.Parse(file)
+}
+
+// Parse parses signatures in the format ParseSignatures reads from r, for input that is
+// not in a file (e.g. in a browser, see cmd/wasm).
+func (p *JSONParser) Parse(r io.Reader) ([]*Signature, error) {
+	if _, err := ParseEndianness(string(p.Endianness)); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
 	decoder.UseNumber() // Preserve large numbers as json.Number instead of float64
 
 	var items []map[string]interface{}
@@ -63,6 +105,10 @@ func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
 	if publicKeyField == "" {
 		publicKeyField = "public_key"
 	}
+	signatureField := p.SignatureField
+	if signatureField == "" {
+		signatureField = "signature"
+	}
 
 	for _, item := range items {
 		sig := &Signature{}
@@ -72,15 +118,7 @@ func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
 			var message []byte
 			switch v := msgVal.(type) {
 			case string:
-				// Try hex decode first
-				if strings.HasPrefix(v, "0x") || len(v) > 20 {
-					message, err = hex.DecodeString(strings.TrimPrefix(v, "0x"))
-					if err != nil {
-						message = []byte(v)
-					}
-				} else {
-					message = []byte(v)
-				}
+				message = decodeMessage(v)
 			case []byte:
 				message = v
 			default:
@@ -91,31 +129,37 @@ func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
 			return nil, fmt.Errorf("missing message field")
 		}
 
-		// Get r
-		rVal, ok := item[rField]
-		if !ok {
+		// Get r and s, or the whole signature R || S
+		rVal, hasR := item[rField]
+		sVal, hasS := item[sField]
+		sigVal, hasSig := item[signatureField]
+		switch {
+		case hasR && hasS:
+			r, err := p.parseScalar(rVal)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse r: %w", err)
+			}
+			s, err := p.parseScalar(sVal)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse s: %w", err)
+			}
+			sig.R, sig.S = r, s
+		case hasSig:
+			r, s, err := splitSignature(sigVal)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse signature: %w", err)
+			}
+			sig.R, sig.S = r, s
+		case !hasR:
 			return nil, fmt.Errorf("missing r field")
-		}
-		r, err := parseBigInt(rVal)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse r: %w", err)
-		}
-		sig.R = r
-
-		// Get s (can be hex string like "0x..." or number)
-		sVal, ok := item[sField]
-		if !ok {
+		default:
 			return nil, fmt.Errorf("missing s field")
 		}
-		s, err := parseBigInt(sVal)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse s: %w", err)
-		}
-		sig.S = s
 
 		// Get public key (optional)
 		if pubKeyVal, ok := item[publicKeyField]; ok {
 			var publicKey []byte
+			var err error
 			switch v := pubKeyVal.(type) {
 			case string:
 				publicKey, err = hex.DecodeString(strings.TrimPrefix(v, "0x"))
@@ -136,6 +180,57 @@ func (p *JSONParser) ParseSignatures(jsonFile string) ([]*Signature, error) {
 	return signatures, nil
 }
 
+// decodeMessage reads a message field: hex if it has a 0x prefix or is longer than 20
+// characters and decodes as hex, text otherwise.
+func decodeMessage(v string) []byte {
+	if strings.HasPrefix(v, "0x") || len(v) > 20 {
+		if message, err := hex.DecodeString(strings.TrimPrefix(v, "0x")); err == nil {
+			return message
+		}
+	}
+	return []byte(v)
+}
+
+// splitSignature splits a 64-byte Ed25519 signature R || S, in hex or base64, into the
+// little-endian integers of its halves. The bytes are always in signature order, whatever
+// the parser's Endianness.
+func splitSignature(val interface{}) (*big.Int, *big.Int, error) {
+	v, ok := val.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("signature field must be a string")
+	}
+	v = strings.TrimSpace(v)
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(v, "0x"), "0X"))
+	if err != nil {
+		if raw, err = base64.StdEncoding.DecodeString(v); err != nil {
+			return nil, nil, fmt.Errorf("signature is neither hex nor base64")
+		}
+	}
+	if len(raw) != 64 {
+		return nil, nil, fmt.Errorf("signature is %d bytes, want 64 (R || S)", len(raw))
+	}
+	r := new(big.Int).SetBytes(reversedBytes(raw[:32]))
+	s := new(big.Int).SetBytes(reversedBytes(raw[32:]))
+	return r, s, nil
+}
+
+// parseScalar parses r or s: hex strings in p.Endianness, anything else as parseBigInt does.
+func (p *JSONParser) parseScalar(val interface{}) (*big.Int, error) {
+	v, ok := val.(string)
+	if !ok || p.Endianness != LittleEndian {
+		return parseBigInt(val)
+	}
+	s := strings.TrimSpace(v)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid little-endian hex: %s", v)
+	}
+	return new(big.Int).SetBytes(reversedBytes(raw)), nil
+}
+
 // parseBigInt parses a big integer from various formats (hex string, decimal string, json.Number).
 func parseBigInt(val interface{}) (*big.Int, error) {
 	switch v := val.(type) {
@@ -1,7 +1,11 @@
 package eddsaaffine
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -59,3 +63,75 @@ func TestJSONParser_ParseSignatures_InvalidFile(t *testing.T) {
 		t.Error("Expected error for nonexistent file")
 	}
 }
+
+func TestJSONParser_Endianness(t *testing.T) {
+	signatures, err := loadTestSignatures("test_eddsa_signatures_counter.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []string
+	for _, sig := range signatures {
+		items = append(items, fmt.Sprintf(`{"message": "0x%x", "r": "%x", "s": "0x%x", "public_key": "%x"}`,
+			sig.Message, wireBytes(sig.R), wireBytes(sig.S), sig.PublicKey))
+	}
+	data := "[" + strings.Join(items, ",") + "]"
+
+	parsed, err := (&JSONParser{Endianness: LittleEndian}).Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range parsed {
+		if sig.R.Cmp(signatures[i].R) != 0 || sig.S.Cmp(signatures[i].S) != 0 {
+			t.Errorf("Signature %d: little-endian r and s read as %x, %x", i, sig.R, sig.S)
+		}
+	}
+
+	d, err := Diagnose(SignatureFields{
+		R: hex.EncodeToString(wireBytes(signatures[0].R)), S: hex.EncodeToString(wireBytes(signatures[0].S)),
+		Message: "0x" + hex.EncodeToString(signatures[0].Message), PublicKey: hex.EncodeToString(signatures[0].PublicKey),
+	})
+	if err != nil || d.Endianness != LittleEndian {
+		t.Errorf("Expected Diagnose to detect little-endian r and s, got %q, %v", d.Endianness, err)
+	}
+
+	if _, err := (&JSONParser{Endianness: "middle"}).Parse(strings.NewReader(data)); err == nil {
+		t.Error("Expected an error for an unknown endianness")
+	}
+}
+
+func TestJSONParser_SignatureBlob(t *testing.T) {
+	signatures, err := loadTestSignatures("test_eddsa_signatures_counter.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []string
+	for i, sig := range signatures {
+		blob := append(wireBytes(sig.R), wireBytes(sig.S)...)
+		encoded := hex.EncodeToString(blob)
+		if i%2 == 1 {
+			encoded = base64.StdEncoding.EncodeToString(blob)
+		}
+		items = append(items, fmt.Sprintf(`{"message": "0x%x", "signature": "%s", "public_key": "%x"}`, sig.Message, encoded, sig.PublicKey))
+	}
+
+	// Endianness applies to r and s only; the blob is always in signature order
+	parsed, err := (&JSONParser{Endianness: LittleEndian}).Parse(strings.NewReader("[" + strings.Join(items, ",") + "]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range parsed {
+		if sig.R.Cmp(signatures[i].R) != 0 || sig.S.Cmp(signatures[i].S) != 0 {
+			t.Errorf("Signature %d: R || S split into %x, %x", i, sig.R, sig.S)
+		}
+	}
+
+	for _, item := range []string{
+		`{"message": "m", "signature": "abcd"}`,
+		`{"message": "m", "signature": "not a signature"}`,
+		`{"message": "m", "r": "0x01"}`,
+	} {
+		if _, err := (&JSONParser{}).Parse(strings.NewReader("[" + item + "]")); err == nil {
+			t.Errorf("Expected an error for %s", item)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package eddsaaffine
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"github.com/mahdiidarabi/ecdsa-affine/internal/modn"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/tablefile"
+)
+
+// DecodeR converts a signature's R value (little-endian integer of the point encoding)
+// back into a curve point.
+func DecodeR(r *big.Int) (*edwards25519.Point, error) {
+	if r.Sign() < 0 || r.BitLen() > 256 {
+		return nil, errors.New("R value out of range")
+	}
+	rBytes := make([]byte, 32)
+	rBytesBE := r.Bytes()
+	for i := 0; i < len(rBytesBE); i++ {
+		rBytes[i] = rBytesBE[len(rBytesBE)-1-i]
+	}
+	return edwards25519.NewIdentityPoint().SetBytes(rBytes)
+}
+
+// CheckAffineR reports whether R2 = a*R1 + b*B holds on the curve, which is implied by
+// r2 = a*r1 + b on the nonces. This rejects a wrong (a, b) candidate without touching the
+// s values and without any hashing.
+//
+// Returns false if either R does not decode to a valid point.
+func CheckAffineR(sig1, sig2 *Signature, a, b *big.Int) bool {
+	R1, err := DecodeR(sig1.R)
+	if err != nil {
+		return false
+	}
+	R2, err := DecodeR(sig2.R)
+	if err != nil {
+		return false
+	}
+	return affineRHolds(R1, R2, a, b)
+}
+
+// affineRHolds checks R2 = a*R1 + b*B.
+func affineRHolds(R1, R2 *edwards25519.Point, a, b *big.Int) bool {
+	expected := edwards25519.NewIdentityPoint().VarTimeDoubleScalarBaseMult(scalarFromBigInt(a), R1, scalarFromBigInt(b))
+	return expected.Equal(R2) == 1
+}
+
+// affineRHoldsInt64 is affineRHolds for int64 a and b, without allocation.
+func affineRHoldsInt64(R1, R2 *edwards25519.Point, a, b int64) bool {
+	var sa, sb edwards25519.Scalar
+	var expected edwards25519.Point
+	expected.VarTimeDoubleScalarBaseMult(scalarFromInt64(&sa, a), R1, scalarFromInt64(&sb, b))
+	return expected.Equal(R2) == 1
+}
+
+// SolveCounterOffset finds b with R2 - R1 = b*B and |b| <= bound using baby-step giant-step,
+// i.e. it recovers the step of a counter nonce (r2 = r1 + b) in O(sqrt(bound)) point operations.
+//
+// For repeated calls over many pairs, use NewOffsetSolver to build the baby-step table once.
+//
+// Returns:
+//   - b and true if found, nil and false otherwise (also for bound > MaxOffsetBound)
+func SolveCounterOffset(sig1, sig2 *Signature, bound int64) (*big.Int, bool) {
+	solver, err := NewOffsetSolver(bound)
+	if err != nil {
+		return nil, false
+	}
+	return solver.Solve(sig1, sig2)
+}
+
+// OffsetSolver solves R2 - R1 = b*B for |b| <= Bound with baby-step giant-step.
+// The baby-step table is built once and reused across signature pairs; Save and
+// LoadOffsetSolver keep it on disk across runs.
+type OffsetSolver struct {
+	Bound int64
+
+	m     int64
+	baby  babySteps           // low 64 bits of the encoding of j*B -> j, for 0 <= j < m
+	giant *edwards25519.Point // -m*B
+	shift *edwards25519.Point // Bound*B, maps b into [0, 2*Bound]
+}
+
+// babySteps is the baby-step lookup: an in-memory map or a mapped table file.
+type babySteps interface {
+	Lookup(key uint64) (int64, bool)
+}
+
+type babyMap map[uint64]uint32
+
+func (b babyMap) Lookup(key uint64) (int64, bool) {
+	j, ok := b[key]
+	return int64(j), ok
+}
+
+// MaxOffsetBound is the largest bound an OffsetSolver accepts: 2^48, a baby-step table
+// of about 24M entries. Past it the table outgrows memory, and near 2^62 the giant-step
+// arithmetic overflows int64.
+const MaxOffsetBound = int64(1) << 48
+
+// ErrOffsetBound is returned for an OffsetSolver bound above MaxOffsetBound.
+var ErrOffsetBound = errors.New("offset bound exceeds MaxOffsetBound (2^48)")
+
+// NewOffsetSolver builds the baby-step table for |b| <= bound, which must not exceed
+// MaxOffsetBound. Time and memory grow with sqrt(2*bound): about 1.5M entries for 2^40.
+func NewOffsetSolver(bound int64) (*OffsetSolver, error) {
+	o, err := newOffsetSolver(bound)
+	if err != nil {
+		return nil, err
+	}
+
+	baby := make(babyMap, o.m)
+	B := edwards25519.NewGeneratorPoint()
+	point := edwards25519.NewIdentityPoint()
+	for j := int64(0); j < o.m; j++ {
+		baby[pointKey(point)] = uint32(j)
+		point.Add(point, B)
+	}
+	o.baby = baby
+	return o, nil
+}
+
+// LoadOffsetSolver opens a baby-step table written by Save. The table is memory-mapped,
+// so loading is immediate regardless of its size; call Close when done.
+func LoadOffsetSolver(path string, bound int64) (*OffsetSolver, error) {
+	o, err := newOffsetSolver(bound)
+	if err != nil {
+		return nil, err
+	}
+	table, err := tablefile.OpenExpect(path, o.tableHeader())
+	if err != nil {
+		return nil, err
+	}
+	o.baby = table
+	return o, nil
+}
+
+// Save writes the baby-step table to path for reuse with LoadOffsetSolver.
+func (o *OffsetSolver) Save(path string) error {
+	var entries []tablefile.Entry
+	switch baby := o.baby.(type) {
+	case babyMap:
+		entries = make([]tablefile.Entry, 0, len(baby))
+		for key, j := range baby {
+			entries = append(entries, tablefile.Entry{Key: key, Value: int64(j)})
+		}
+	case *tablefile.Table:
+		entries = make([]tablefile.Entry, baby.Len())
+		for i := range entries {
+			entries[i] = baby.Entry(i)
+		}
+	}
+	return tablefile.Write(path, o.tableHeader(), entries)
+}
+
+// Close releases a table opened by LoadOffsetSolver.
+func (o *OffsetSolver) Close() error {
+	if table, ok := o.baby.(*tablefile.Table); ok {
+		return table.Close()
+	}
+	return nil
+}
+
+// newOffsetSolver sets up everything but the baby-step table.
+func newOffsetSolver(bound int64) (*OffsetSolver, error) {
+	if bound > MaxOffsetBound {
+		return nil, fmt.Errorf("%w: %d", ErrOffsetBound, bound)
+	}
+	if bound < 1 {
+		bound = 1
+	}
+	span := 2*bound + 1
+	m := int64(1)
+	for m*m < span {
+		m++
+	}
+
+	giant := edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(big.NewInt(m)))
+	giant.Negate(giant)
+
+	return &OffsetSolver{
+		Bound: bound,
+		m:     m,
+		giant: giant,
+		shift: edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(big.NewInt(bound))),
+	}, nil
+}
+
+func (o *OffsetSolver) tableHeader() tablefile.Header {
+	return tablefile.Header{
+		Kind:   tablefile.KindBabySteps,
+		Curve:  "ed25519",
+		Params: [4]int64{o.Bound, o.m},
+	}
+}
+
+// Solve returns b with R2 - R1 = b*B and |b| <= Bound.
+func (o *OffsetSolver) Solve(sig1, sig2 *Signature) (*big.Int, bool) {
+	R1, err := DecodeR(sig1.R)
+	if err != nil {
+		return nil, false
+	}
+	R2, err := DecodeR(sig2.R)
+	if err != nil {
+		return nil, false
+	}
+
+	// gamma = R2 - R1 + Bound*B = (b + Bound)*B with b + Bound in [0, 2*Bound]
+	D := edwards25519.NewIdentityPoint().Subtract(R2, R1)
+	gamma := edwards25519.NewIdentityPoint().Add(D, o.shift)
+
+	for i := int64(0); i*o.m <= 2*o.Bound; i++ {
+		if j, ok := o.baby.Lookup(pointKey(gamma)); ok {
+			b := big.NewInt(i*o.m + j - o.Bound)
+			// The table is keyed on 64 bits of the encoding; confirm the hit exactly
+			expected := edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(b))
+			if new(big.Int).Abs(b).Cmp(big.NewInt(o.Bound)) <= 0 && expected.Equal(D) == 1 {
+				return b, true
+			}
+		}
+		gamma.Add(gamma, o.giant)
+	}
+	return nil, false
+}
+
+// pointKey returns the low 64 bits of a point's encoding.
+func pointKey(p *edwards25519.Point) uint64 {
+	return binary.LittleEndian.Uint64(p.Bytes()[:8])
+}
+
+// scalarFromBigInt converts a value mod the curve order into an edwards25519 scalar.
+func scalarFromBigInt(v *big.Int) *edwards25519.Scalar {
+	le := make([]byte, 32)
+	be := new(big.Int).Mod(v, Ed25519CurveOrder).Bytes()
+	for i := 0; i < len(be); i++ {
+		le[i] = be[len(be)-1-i]
+	}
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(le)
+	return s
+}
+
+// scalarFromInt64 sets s to v mod the curve order and returns s.
+func scalarFromInt64(s *edwards25519.Scalar, v int64) *edwards25519.Scalar {
+	var e modn.Elem
+	modn.Ed25519L.SetInt64(&e, v)
+	return setScalarBytes(s, modn.Ed25519L.Bytes(&e))
+}
+
+// setScalarBytes sets s to the value of 32 big-endian bytes below the curve order and
+// returns s.
+func setScalarBytes(s *edwards25519.Scalar, be [32]byte) *edwards25519.Scalar {
+	var le [32]byte
+	for i := range le {
+		le[i] = be[31-i]
+	}
+	s.SetCanonicalBytes(le[:])
+	return s
+}
@@ -0,0 +1,73 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func testNonceChain(a, coeff, offset *big.Int, count int) []*Signature {
+	r, _ := new(big.Int).SetString("05a4b3c2d1e0f9e8d7c6b5a4938271605a4b3c2d1e0f9e8d7c6b5a493827160", 16)
+	var signatures []*Signature
+	for i := 0; i < count; i++ {
+		signatures = append(signatures, signWithNonce(a, r, []byte{byte('m'), byte(i)}))
+		r = new(big.Int).Mul(r, coeff)
+		r.Add(r, offset)
+		r.Mod(r, Ed25519CurveOrder)
+	}
+	return signatures
+}
+
+func TestCheckAffineR(t *testing.T) {
+	signatures := testNonceChain(big.NewInt(424242), big.NewInt(5), big.NewInt(-77), 2)
+
+	if !CheckAffineR(signatures[0], signatures[1], big.NewInt(5), big.NewInt(-77)) {
+		t.Error("Expected R2 = 5*R1 - 77*B to hold")
+	}
+	if CheckAffineR(signatures[0], signatures[1], big.NewInt(5), big.NewInt(-76)) {
+		t.Error("Expected wrong b to be rejected")
+	}
+	if CheckAffineR(signatures[0], signatures[1], big.NewInt(1), big.NewInt(-77)) {
+		t.Error("Expected wrong a to be rejected")
+	}
+}
+
+func TestSolveCounterOffset(t *testing.T) {
+	for _, step := range []int64{13511, -9, 1} {
+		signatures := testNonceChain(big.NewInt(424242), big.NewInt(1), big.NewInt(step), 2)
+		b, ok := SolveCounterOffset(signatures[0], signatures[1], 1<<16)
+		if !ok {
+			t.Fatalf("Expected offset %d to be found", step)
+		}
+		if b.Int64() != step {
+			t.Errorf("Expected b=%d, got %s", step, b)
+		}
+	}
+
+	// Offset outside the bound
+	signatures := testNonceChain(big.NewInt(424242), big.NewInt(1), big.NewInt(5000), 2)
+	if _, ok := SolveCounterOffset(signatures[0], signatures[1], 1000); ok {
+		t.Error("Expected no solution for offset outside the bound")
+	}
+}
+
+func TestSmartBruteForceStrategy_Search_CounterOffsetWithoutPublicKey(t *testing.T) {
+	a := big.NewInt(987654)
+	signatures := testNonceChain(a, big.NewInt(1), big.NewInt(700001), 3)
+
+	patternConfig := DefaultPatternConfig()
+	patternConfig.IncludeCommonPatterns = false
+	strategy := NewSmartBruteForceStrategy().WithPatternConfig(patternConfig)
+
+	// Without a public key the point check is what makes the result exact
+	result := strategy.Search(context.Background(), signatures, nil)
+	if result == nil {
+		t.Fatal("Expected counter offset recovery")
+	}
+	if result.PrivateKey.Cmp(a) != 0 {
+		t.Errorf("Expected private key %s, got %s", a, result.PrivateKey)
+	}
+	if result.Relationship.B.Int64() != 700001 {
+		t.Errorf("Expected b=700001, got %s", result.Relationship.B)
+	}
+}
@@ -0,0 +1,56 @@
+package eddsaaffine
+
+import (
+	"math/big"
+
+	"filippo.io/edwards25519"
+)
+
+// Progression is a run of consecutive signatures, in dataset order, whose nonces step by
+// a constant: r[i+1] = r[i] + B for Start <= i < End.
+type Progression struct {
+	Start int      // first signature of the run
+	End   int      // last signature of the run
+	B     *big.Int // the step, centered into (-q/2, q/2]
+}
+
+// FindProgressions finds the runs of three or more consecutive signatures whose nonces
+// are in arithmetic progression, seen as R points with a constant difference:
+// R[i+1] - R[i] = R[i+2] - R[i+1]. That difference is b*B, so b itself is a discrete log;
+// it is derived from the first three signatures of the run instead (DeriveKeyAndOffset
+// with a = 1), whatever its size. Runs of the same R are left to the same nonce check.
+func FindProgressions(signatures []*Signature) []Progression {
+	points := make([]*edwards25519.Point, len(signatures))
+	for i, sig := range signatures {
+		if p, err := DecodeR(sig.R); err == nil {
+			points[i] = p
+		}
+	}
+
+	var progressions []Progression
+	for i := 0; i+2 < len(signatures); {
+		end := i + 1
+		for end+1 < len(signatures) && equalSteps(points[end-1], points[end], points[end+1]) {
+			end++
+		}
+		if end < i+2 {
+			i++
+			continue
+		}
+		_, b, err := DeriveKeyAndOffset(signatures[i], signatures[i+1], signatures[i+2], big.NewInt(1))
+		if err == nil && b.Sign() != 0 {
+			progressions = append(progressions, Progression{Start: i, End: end, B: b})
+		}
+		i = end
+	}
+	return progressions
+}
+
+// equalSteps reports whether R2 - R1 = R3 - R2, i.e. 2*R2 = R1 + R3.
+func equalSteps(R1, R2, R3 *edwards25519.Point) bool {
+	if R1 == nil || R2 == nil || R3 == nil {
+		return false
+	}
+	double := edwards25519.NewIdentityPoint().Add(R2, R2)
+	return double.Equal(edwards25519.NewIdentityPoint().Add(R1, R3)) == 1
+}
@@ -0,0 +1,43 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func TestFindProgressions(t *testing.T) {
+	// A step far beyond the range phases and the counter offset bound
+	a := big.NewInt(0xfeed)
+	step := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 100), big.NewInt(7))
+	r := big.NewInt(987654321)
+	signatures := []*Signature{signWithNonce(a, big.NewInt(123456789), []byte("unrelated"))}
+	for _, message := range []string{"one", "two", "three", "four"} {
+		signatures = append(signatures, signWithNonce(a, r, []byte(message)))
+		r = new(big.Int).Add(r, step)
+	}
+
+	progressions := FindProgressions(signatures)
+	if len(progressions) != 1 {
+		t.Fatalf("Expected one progression, got %+v", progressions)
+	}
+	if p := progressions[0]; p.Start != 1 || p.End != 4 || p.B.Cmp(step) != 0 {
+		t.Errorf("Expected signatures 1 to 4 with step %s, got %+v", step, p)
+	}
+	if progressions := FindProgressions(signatures[:3]); len(progressions) != 0 {
+		t.Errorf("Expected no progression in two related signatures, got %+v", progressions)
+	}
+
+	strategy := NewSmartBruteForceStrategy()
+	strategy.PatternConfig.IncludeCommonPatterns = false
+	strategy.RangeConfig.ARange = [2]int{1, 1}
+	strategy.RangeConfig.BRange = [2]int{0, 0}
+	strategy.RangeConfig.CounterOffsetBound = 0
+	result := strategy.Search(context.Background(), signatures, publicKeyFor(a))
+	if result == nil || !result.Verified || result.PrivateKey.Cmp(a) != 0 {
+		t.Fatalf("Expected the progression step to recover the key, got %+v", result)
+	}
+	if result.Pattern != "progression_b"+step.Text(10) || result.SignaturePair != [2]int{1, 2} {
+		t.Errorf("Unexpected result %s on %v", result.Pattern, result.SignaturePair)
+	}
+}
@@ -0,0 +1,89 @@
+package eddsaaffine
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"filippo.io/edwards25519"
+)
+
+// proofDomain prefixes every proof challenge, so a proof signature can never be replayed
+// as a transaction or message signature.
+const proofDomain = "eddsa-affine proof of compromise\n"
+
+// CompromiseProof shows that a key was recovered without revealing it: the recovered key
+// signs a challenge chosen by the verifier (for example a bug bounty report ID). The
+// signature is a standard Ed25519 signature over ProofMessage(Challenge), so
+// crypto/ed25519.Verify or any Ed25519 tool can check it.
+type CompromiseProof struct {
+	PublicKey []byte // Public key A of the recovered scalar (32 bytes)
+	Challenge []byte // Verifier-chosen challenge
+	Signature []byte // Ed25519 signature (R || S) over ProofMessage(Challenge)
+}
+
+// ProofMessage returns the message signed by a proof: the challenge prefixed with a
+// fixed domain string.
+func ProofMessage(challenge []byte) []byte {
+	return append([]byte(proofDomain), challenge...)
+}
+
+// ProveCompromise signs challenge with a recovered signing scalar.
+//
+// Recovery yields the scalar a rather than the Ed25519 seed, so the signature is built
+// directly: r = H(a || M) mod L, R = r*B, S = r + H(R || A || M)*a mod L. The result
+// verifies like any Ed25519 signature.
+func ProveCompromise(privateKey *big.Int, challenge []byte) (*CompromiseProof, error) {
+	if privateKey == nil || privateKey.Sign() <= 0 || privateKey.Cmp(Ed25519CurveOrder) >= 0 {
+		return nil, errors.New("private key out of valid range")
+	}
+	if len(challenge) == 0 {
+		return nil, errors.New("challenge must not be empty")
+	}
+	a := scalarFromBigInt(privateKey)
+	publicKey := edwards25519.NewIdentityPoint().ScalarBaseMult(a).Bytes()
+	message := ProofMessage(challenge)
+
+	// Deterministic nonce from the scalar and message, as RFC 8032 derives it from the seed
+	nonceHash := sha512.New()
+	nonceHash.Write([]byte("eddsa-affine proof nonce"))
+	nonceHash.Write(a.Bytes())
+	nonceHash.Write(message)
+	r, err := edwards25519.NewScalar().SetUniformBytes(nonceHash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	R := edwards25519.NewIdentityPoint().ScalarBaseMult(r).Bytes()
+
+	challengeHash := sha512.New()
+	challengeHash.Write(R)
+	challengeHash.Write(publicKey)
+	challengeHash.Write(message)
+	k, err := edwards25519.NewScalar().SetUniformBytes(challengeHash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	S := edwards25519.NewScalar().MultiplyAdd(k, a, r)
+
+	return &CompromiseProof{
+		PublicKey: publicKey,
+		Challenge: append([]byte(nil), challenge...),
+		Signature: append(R, S.Bytes()...),
+	}, nil
+}
+
+// VerifyCompromiseProof checks that proof is a valid signature over its challenge by the
+// holder of publicKey (32 bytes).
+func VerifyCompromiseProof(proof *CompromiseProof, publicKey []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("public key must be 32 bytes")
+	}
+	if string(proof.PublicKey) != string(publicKey) {
+		return errors.New("proof is for a different public key")
+	}
+	if !ed25519.Verify(publicKey, ProofMessage(proof.Challenge), proof.Signature) {
+		return errors.New("proof signature does not verify")
+	}
+	return nil
+}
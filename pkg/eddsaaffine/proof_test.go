@@ -0,0 +1,35 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+)
+
+func TestProveCompromise(t *testing.T) {
+	a := big.NewInt(424242)
+	publicKey := publicKeyFor(a)
+
+	proof, err := ProveCompromise(a, []byte("bounty-report-1234"))
+	if err != nil {
+		t.Fatalf("ProveCompromise failed: %v", err)
+	}
+	if !bytes.Equal(proof.PublicKey, publicKey) {
+		t.Errorf("Expected proof for %x, got %x", publicKey, proof.PublicKey)
+	}
+	if !ed25519.Verify(publicKey, ProofMessage(proof.Challenge), proof.Signature) {
+		t.Error("Expected a standard Ed25519 signature")
+	}
+	if err := VerifyCompromiseProof(proof, publicKey); err != nil {
+		t.Errorf("Expected proof to verify: %v", err)
+	}
+
+	if err := VerifyCompromiseProof(proof, publicKeyFor(big.NewInt(12345))); err == nil {
+		t.Error("Expected proof to fail for another public key")
+	}
+	proof.Challenge = []byte("bounty-report-1235")
+	if err := VerifyCompromiseProof(proof, publicKey); err == nil {
+		t.Error("Expected proof to fail for another challenge")
+	}
+}
@@ -0,0 +1,159 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+// propertySeed seeds the property tests. It is fixed so a failing case reproduces on every
+// run; change it locally to explore other cases.
+const propertySeed = 1848
+
+// propertyCases returns how many random cases each property test runs.
+func propertyCases() int {
+	if testing.Short() {
+		return 5
+	}
+	return 25
+}
+
+// signedChain signs count random messages with key, using nonces k_{i+1} = a*k_i + b from
+// start, and returns the signatures in this package's form with the nonces used.
+func signedChain(t *testing.T, rng *rand.Rand, key *flawedsigner.EdDSAKey, start, a, b *big.Int, count int) ([]*Signature, []*big.Int) {
+	t.Helper()
+	messages := make([][]byte, count)
+	for i := range messages {
+		messages[i] = make([]byte, 1+rng.Intn(64))
+		rng.Read(messages[i])
+	}
+	signed, err := key.Sign(messages, flawedsigner.AffineFrom(start, a, b))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	signatures := make([]*Signature, len(signed))
+	nonces := make([]*big.Int, len(signed))
+	for i, sig := range signed {
+		signatures[i] = &Signature{R: sig.R, S: sig.S, Message: sig.Message, PublicKey: sig.PublicKey}
+		nonces[i] = sig.Nonce
+	}
+	return signatures, nonces
+}
+
+// randomStart returns a random start nonce, a quarter of the time within 2^16 of the curve
+// order so that a*k + b wraps around it.
+func randomStart(rng *rand.Rand) *big.Int {
+	if rng.Intn(4) == 0 {
+		return new(big.Int).Sub(Ed25519CurveOrder, big.NewInt(1+rng.Int63n(1<<16)))
+	}
+	k, _ := flawedsigner.Random(rng).Next(Ed25519CurveOrder)
+	return k
+}
+
+// randomNonZero returns a random integer in [lo, hi] other than zero.
+func randomNonZero(rng *rand.Rand, lo, hi int64) int64 {
+	for {
+		if v := lo + rng.Int63n(hi-lo+1); v != 0 {
+			return v
+		}
+	}
+}
+
+func TestProperty_RecoverPrivateKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(propertySeed))
+
+	for i := 0; i < propertyCases(); i++ {
+		key, _ := flawedsigner.NewEdDSAKey(rng)
+		a := big.NewInt(randomNonZero(rng, -1000, 1000))
+		b := big.NewInt(rng.Int63n(1<<33) - 1<<32)
+		signatures, nonces := signedChain(t, rng, key, randomStart(rng), a, b, 2)
+
+		priv, err := RecoverPrivateKey(signatures[0], signatures[1], a, b)
+		if err != nil || priv.Cmp(key.Scalar) != 0 {
+			t.Fatalf("case %d (a=%s, b=%s): recovered %v, %v", i, a, b, priv, err)
+		}
+		recovered, err := RecoverNonces(&RecoveryResult{PrivateKey: priv}, signatures)
+		if err != nil || recovered[0].Cmp(nonces[0]) != 0 || recovered[1].Cmp(nonces[1]) != 0 {
+			t.Errorf("case %d (a=%s, b=%s): wrong nonces recovered", i, a, b)
+		}
+	}
+}
+
+// TestProperty_RecoverPrivateKey_EdgeCases covers values the static fixtures never reach:
+// keys and nonces at the ends of [1, L-1], and a and b given as residues near L rather
+// than as small negative integers.
+func TestProperty_RecoverPrivateKey_EdgeCases(t *testing.T) {
+	n := Ed25519CurveOrder
+	nMinus := func(v int64) *big.Int { return new(big.Int).Sub(n, big.NewInt(v)) }
+	random, _ := flawedsigner.Random(rand.New(rand.NewSource(propertySeed))).Next(n)
+
+	tests := []struct {
+		name     string
+		d, start *big.Int
+		a, b     *big.Int // relationship used to sign
+		recoverA *big.Int // relationship passed to RecoverPrivateKey
+		recoverB *big.Int
+	}{
+		{"nonce wraps past L", random, nMinus(1), big.NewInt(2), big.NewInt(5), big.NewInt(2), big.NewInt(5)},
+		{"negative a from nonce 1", random, big.NewInt(1), big.NewInt(-1), big.NewInt(7), big.NewInt(-1), big.NewInt(7)},
+		{"negative b to small nonce", random, big.NewInt(100), big.NewInt(1), big.NewInt(-99), big.NewInt(1), big.NewInt(-99)},
+		{"b as residue L-b", random, random, big.NewInt(3), big.NewInt(-12345), big.NewInt(3), nMinus(12345)},
+		{"a as residue L-1", random, random, big.NewInt(-1), big.NewInt(42), nMinus(1), big.NewInt(42)},
+		{"b larger than L", random, random, big.NewInt(1), big.NewInt(9), big.NewInt(1), new(big.Int).Add(n, big.NewInt(9))},
+		{"scalar L-1", nMinus(1), random, big.NewInt(5), big.NewInt(-3), big.NewInt(5), big.NewInt(-3)},
+		{"scalar 1", big.NewInt(1), nMinus(2), big.NewInt(-2), big.NewInt(-1), big.NewInt(-2), big.NewInt(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(propertySeed))
+			key := &flawedsigner.EdDSAKey{Scalar: tt.d, Public: publicKeyFor(tt.d)}
+			signatures, _ := signedChain(t, rng, key, tt.start, tt.a, tt.b, 2)
+
+			priv, err := RecoverPrivateKey(signatures[0], signatures[1], tt.recoverA, tt.recoverB)
+			if err != nil {
+				t.Fatalf("RecoverPrivateKey failed: %v", err)
+			}
+			if priv.Cmp(tt.d) != 0 {
+				t.Errorf("Expected %s, got %s", tt.d, priv)
+			}
+			if ok, err := VerifyRecoveredKey(priv, key.Public); !ok || err != nil {
+				t.Errorf("Recovered key does not match the public key: %v", err)
+			}
+		})
+	}
+}
+
+func TestProperty_SmartBruteForceStrategy(t *testing.T) {
+	rng := rand.New(rand.NewSource(propertySeed))
+	config := DefaultRangeConfig()
+	config.ARange = [2]int{-6, 6}
+	config.BRange = [2]int{-300, 300}
+	strategy := NewSmartBruteForceStrategy().WithRangeConfig(config)
+
+	for i := 0; i < propertyCases(); i++ {
+		key, _ := flawedsigner.NewEdDSAKey(rng)
+		a := big.NewInt(randomNonZero(rng, int64(config.ARange[0]), int64(config.ARange[1])))
+		b := big.NewInt(int64(config.BRange[0]) + rng.Int63n(int64(config.BRange[1]-config.BRange[0]+1)))
+		signatures, nonces := signedChain(t, rng, key, randomStart(rng), a, b, 3)
+
+		result := strategy.Search(context.Background(), signatures, key.Public)
+		if result == nil {
+			t.Fatalf("case %d (a=%s, b=%s): no key recovered", i, a, b)
+		}
+		if result.PrivateKey.Cmp(key.Scalar) != 0 || !result.Verified {
+			t.Fatalf("case %d (a=%s, b=%s): wrong key (verified=%v)", i, a, b, result.Verified)
+		}
+		// Any reported relationship must hold between the nonces actually used
+		i1, i2 := result.SignaturePair[0], result.SignaturePair[1]
+		k2 := new(big.Int).Mul(result.Relationship.A, nonces[i1])
+		k2.Add(k2, result.Relationship.B)
+		if k2.Mod(k2, Ed25519CurveOrder).Cmp(nonces[i2]) != 0 {
+			t.Errorf("case %d (a=%s, b=%s): reported a=%s b=%s does not relate nonces %d and %d",
+				i, a, b, result.Relationship.A, result.Relationship.B, i1, i2)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/edwards25519"
+)
+
+// sshKeyType is the OpenSSH key type name of Ed25519 keys.
+const sshKeyType = "ssh-ed25519"
+
+// ParsePublicKey reads an Ed25519 public key in the formats users typically have, and
+// returns the raw 32-byte key the strategies compare against:
+//   - hex (optional 0x prefix)
+//   - an OpenSSH public key line ("ssh-ed25519 AAAA... comment"), as in .pub and
+//     authorized_keys files; the first ssh-ed25519 line is used
+//   - PEM: "PUBLIC KEY" (PKIX, as written by openssl pkey -pubout) or a "CERTIFICATE"
+//   - a JWK ({"kty":"OKP","crv":"Ed25519","x":...}) or a JWK Set; the first Ed25519 key is used
+func ParsePublicKey(data []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	var (
+		publicKey []byte
+		err       error
+	)
+	switch {
+	case strings.HasPrefix(text, "-----BEGIN"):
+		publicKey, err = parsePEMPublicKey([]byte(text))
+	case strings.HasPrefix(text, "{"):
+		publicKey, err = parseJWKPublicKey([]byte(text))
+	case strings.Contains(text, sshKeyType+" ") || strings.HasPrefix(text, "ssh-"):
+		publicKey, err = parseSSHPublicKey(text)
+	default:
+		publicKey, err = hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(text, "0x"), "0X"))
+		if err != nil {
+			err = fmt.Errorf("failed to parse public key: not hex, an OpenSSH line, PEM or JWK: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be 32 bytes (Ed25519 format), got %d", len(publicKey))
+	}
+	if _, err := edwards25519.NewIdentityPoint().SetBytes(publicKey); err != nil {
+		return nil, fmt.Errorf("invalid Ed25519 public key: %w", err)
+	}
+	return publicKey, nil
+}
+
+// LoadPublicKeyFile reads a public key file in any format ParsePublicKey accepts.
+func LoadPublicKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return publicKey, nil
+}
+
+// parseSSHPublicKey decodes the base64 wire blob after "ssh-ed25519": the key type and the
+// key, each as a uint32 length followed by the bytes (RFC 4253 section 6.6, RFC 8709).
+func parseSSHPublicKey(text string) ([]byte, error) {
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] != sshKeyType {
+				continue
+			}
+			blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid OpenSSH public key: %w", err)
+			}
+			keyType, rest, ok := readSSHString(blob)
+			if !ok || string(keyType) != sshKeyType {
+				return nil, errors.New("invalid OpenSSH public key: key type mismatch")
+			}
+			publicKey, rest, ok := readSSHString(rest)
+			if !ok || len(rest) != 0 {
+				return nil, errors.New("invalid OpenSSH public key: malformed key data")
+			}
+			return publicKey, nil
+		}
+	}
+	return nil, errors.New("no ssh-ed25519 public key found (only Ed25519 OpenSSH keys are supported)")
+}
+
+// readSSHString reads one length-prefixed string from an SSH wire blob.
+func readSSHString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
+
+// parsePEMPublicKey decodes the first PEM block, a PKIX public key or a certificate.
+func parsePEMPublicKey(data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM public key")
+	}
+
+	var key any
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PEM public key: %w", err)
+		}
+		key = parsed
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PEM certificate: %w", err)
+		}
+		key = cert.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q (expected PUBLIC KEY or CERTIFICATE)", block.Type)
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("PEM key is %T, not Ed25519", key)
+	}
+	return publicKey, nil
+}
+
+// jwk holds the fields of an OKP JSON Web Key (RFC 8037); Keys is set for a JWK Set.
+type jwk struct {
+	Kty  string `json:"kty"`
+	Crv  string `json:"crv"`
+	X    string `json:"x"`
+	Keys []jwk  `json:"keys"`
+}
+
+// parseJWKPublicKey decodes a JWK, or the first Ed25519 key in a JWK Set.
+func parseJWKPublicKey(data []byte) ([]byte, error) {
+	var key jwk
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&key); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+
+	candidates := []jwk{key}
+	if key.Keys != nil {
+		candidates = key.Keys
+	}
+	for _, k := range candidates {
+		if k.Kty != "OKP" || k.Crv != "Ed25519" {
+			continue
+		}
+		publicKey, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.X, "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK x: %w", err)
+		}
+		return publicKey, nil
+	}
+	return nil, errors.New("no Ed25519 key in JWK (expected kty OKP, crv Ed25519)")
+}
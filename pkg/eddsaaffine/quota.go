@@ -0,0 +1,50 @@
+package eddsaaffine
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQuotaExceeded is wrapped by the error of a recovery whose strategy stopped at
+// RangeConfig.MaxCandidates or MaxCPUTime without finding the key.
+var ErrQuotaExceeded = errors.New("search quota exceeded")
+
+// quota enforces RangeConfig.MaxCandidates and MaxCPUTime. Workers report after every
+// batch of candidates, as they do to the throttle; once either limit is reached, every
+// worker stops at its next batch and no further range phase starts.
+type quota struct {
+	maxCandidates int64
+	maxBusy       int64 // nanoseconds
+
+	candidates atomic.Int64
+	busy       atomic.Int64
+	exhausted  atomic.Bool
+}
+
+// newQuota returns the quota for config, or nil if the search is unlimited.
+func newQuota(config RangeConfig) *quota {
+	if config.MaxCandidates <= 0 && config.MaxCPUTime <= 0 {
+		return nil
+	}
+	return &quota{maxCandidates: config.MaxCandidates, maxBusy: int64(config.MaxCPUTime)}
+}
+
+// spend records n candidates tried in busy time and reports whether the search may go
+// on. A nil quota always allows it.
+func (q *quota) spend(n int64, busy time.Duration) bool {
+	if q == nil {
+		return true
+	}
+	candidates := q.candidates.Add(n)
+	spent := q.busy.Add(int64(busy))
+	if (q.maxCandidates > 0 && candidates >= q.maxCandidates) || (q.maxBusy > 0 && spent >= q.maxBusy) {
+		q.exhausted.Store(true)
+	}
+	return !q.exhausted.Load()
+}
+
+// exceeded reports whether the quota is used up.
+func (q *quota) exceeded() bool {
+	return q != nil && q.exhausted.Load()
+}
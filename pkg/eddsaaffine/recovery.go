@@ -3,9 +3,11 @@ package eddsaaffine
 import (
 	"crypto/sha512"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"filippo.io/edwards25519"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
 )
 
 // Ed25519CurveOrder is the order of the Ed25519 curve
@@ -44,15 +46,18 @@ var Ed25519CurveOrder, _ = new(big.Int).SetString("72370055773322622139731865630
 // Returns:
 //   - Private key if recovery successful, error otherwise
 func RecoverPrivateKey(sig1, sig2 *Signature, a, b *big.Int) (*big.Int, error) {
-	q := Ed25519CurveOrder
-
 	// Compute H(R||A||M) for both signatures
 	h1 := ComputeH(sig1.R, sig1.PublicKey, sig1.Message)
 	h2 := ComputeH(sig2.R, sig2.PublicKey, sig2.Message)
+	return recoverPrivateKey(sig1.S, sig2.S, h1, h2, a, b, Ed25519CurveOrder)
+}
 
+// recoverPrivateKey solves RecoverPrivateKey's equation in the group of order q, for
+// signatures with s values s1, s2 and challenges h1, h2.
+func recoverPrivateKey(s1, s2, h1, h2, a, b, q *big.Int) (*big.Int, error) {
 	// Calculate numerator: (s2 - a_coeff * s1 - b_offset) mod q
-	as1 := new(big.Int).Mul(a, sig1.S)
-	numerator := new(big.Int).Sub(sig2.S, as1)
+	as1 := new(big.Int).Mul(a, s1)
+	numerator := new(big.Int).Sub(s2, as1)
 	numerator.Sub(numerator, b)
 	numerator.Mod(numerator, q)
 
@@ -79,6 +84,75 @@ func RecoverPrivateKey(sig1, sig2 *Signature, a, b *big.Int) (*big.Int, error) {
 	return priv, nil
 }
 
+// DeriveKeyAndOffset solves for both the private key and the affine offset b when three
+// signatures share the same nonce relationship: r2 = a*r1 + b and r3 = a*r2 + b.
+//
+// R is a point encoding and is not linear in the nonce, so b cannot be read off the
+// R values. It can, however, be solved from the signature equations:
+//
+//	s2 - a*s1 = b + x*(h2 - a*h1)
+//	s3 - a*s2 = b + x*(h3 - a*h2)
+//
+// Subtracting eliminates b:
+//
+//	x = ((s3 - a*s2) - (s2 - a*s1)) / ((h3 - a*h2) - (h2 - a*h1)) mod q
+//	b = (s2 - a*s1) - x*(h2 - a*h1) mod q
+//
+// A wrong a (or signatures that are not part of one chain) still yields a solution, but
+// b is then a uniformly random value mod q; callers should check that b is small and
+// verify the key against the public key.
+//
+// Args:
+//   - sig1, sig2, sig3: Three signatures from the same nonce chain
+//   - a: Affine coefficient (r2 = a*r1 + b)
+//
+// Returns:
+//   - Private key and offset b (centered into (-q/2, q/2]) if successful, error otherwise
+func DeriveKeyAndOffset(sig1, sig2, sig3 *Signature, a *big.Int) (*big.Int, *big.Int, error) {
+	q := Ed25519CurveOrder
+
+	h1 := ComputeH(sig1.R, sig1.PublicKey, sig1.Message)
+	h2 := ComputeH(sig2.R, sig2.PublicKey, sig2.Message)
+	h3 := ComputeH(sig3.R, sig3.PublicKey, sig3.Message)
+
+	// e1 = s2 - a*s1, e2 = s3 - a*s2
+	e1 := new(big.Int).Mul(a, sig1.S)
+	e1.Sub(sig2.S, e1)
+	e2 := new(big.Int).Mul(a, sig2.S)
+	e2.Sub(sig3.S, e2)
+
+	// g1 = h2 - a*h1, g2 = h3 - a*h2
+	g1 := new(big.Int).Mul(a, h1)
+	g1.Sub(h2, g1)
+	g2 := new(big.Int).Mul(a, h2)
+	g2.Sub(h3, g2)
+
+	numerator := new(big.Int).Sub(e2, e1)
+	numerator.Mod(numerator, q)
+	denominator := new(big.Int).Sub(g2, g1)
+	denominator.Mod(denominator, q)
+
+	if denominator.Sign() == 0 {
+		return nil, nil, errors.New("denominator is zero: cannot derive offset")
+	}
+	denominatorInv := new(big.Int).ModInverse(denominator, q)
+	if denominatorInv == nil {
+		return nil, nil, errors.New("failed to compute modular inverse")
+	}
+
+	priv := new(big.Int).Mul(numerator, denominatorInv)
+	priv.Mod(priv, q)
+
+	b := new(big.Int).Mul(priv, g1)
+	b.Sub(e1, b)
+	b.Mod(b, q)
+	if b.Cmp(new(big.Int).Rsh(q, 1)) > 0 {
+		b.Sub(b, q)
+	}
+
+	return priv, b, nil
+}
+
 // ComputeH computes H(R||A||M) for EdDSA signature verification.
 //
 // Args:
@@ -89,6 +163,11 @@ func RecoverPrivateKey(sig1, sig2 *Signature, a, b *big.Int) (*big.Int, error) {
 // Returns:
 //   - Hash value as integer mod curve order
 func ComputeH(r *big.Int, publicKey, message []byte) *big.Int {
+	return computeH(r, publicKey, message, Ed25519CurveOrder)
+}
+
+// computeH is ComputeH reduced mod q.
+func computeH(r *big.Int, publicKey, message []byte, q *big.Int) *big.Int {
 	// Convert r to 32 bytes (little-endian for Ed25519)
 	// big.Int.Bytes() returns big-endian bytes, so we need to convert to little-endian
 	rBytes := make([]byte, 32)
@@ -118,7 +197,7 @@ func ComputeH(r *big.Int, publicKey, message []byte) *big.Int {
 		byteVal.Lsh(byteVal, uint(i*8))
 		hInt.Add(hInt, byteVal)
 	}
-	hInt.Mod(hInt, Ed25519CurveOrder)
+	hInt.Mod(hInt, q)
 
 	return hInt
 }
@@ -183,3 +262,252 @@ func VerifyRecoveredKey(privateKey *big.Int, publicKey []byte) (bool, error) {
 	// Compare computed and expected public keys
 	return computedPubKey.Equal(expectedPubKey) == 1, nil
 }
+
+// VerifySignature checks a parsed signature with the standard Ed25519 equation:
+// [S]B == R + [h]A, where h = H(R || A || M) mod q.
+//
+// Run over a dataset before recovery, it shows whether the parser read R, S and the
+// message as they were signed; recovery on misread signatures finds nothing. Signatures
+// from flawed signers verify like any other, whatever their nonces.
+//
+// Args:
+//   - sig: Parsed signature
+//   - publicKey: Signer's public key (32 bytes); nil uses sig.PublicKey
+//
+// Returns:
+//   - True if the signature verifies, false otherwise (including S >= q, which strict
+//     verifiers reject)
+//   - Error if the public key or R is not a valid point encoding
+func VerifySignature(sig *Signature, publicKey []byte) (bool, error) {
+	if publicKey == nil {
+		publicKey = sig.PublicKey
+	}
+	if len(publicKey) != 32 {
+		return false, errors.New("public key must be 32 bytes")
+	}
+	A, err := edwards25519.NewIdentityPoint().SetBytes(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("invalid public key: %w", err)
+	}
+	R, err := DecodeR(sig.R)
+	if err != nil {
+		return false, err
+	}
+	if sig.S.Sign() < 0 || sig.S.Cmp(Ed25519CurveOrder) >= 0 {
+		return false, nil
+	}
+
+	h := ComputeH(sig.R, publicKey, sig.Message)
+	sB := edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(sig.S))
+	rhs := edwards25519.NewIdentityPoint().ScalarMult(scalarFromBigInt(h), A)
+	rhs.Add(R, rhs)
+	return sB.Equal(rhs) == 1, nil
+}
+
+// RecoverNonce computes the nonce used for a signature once the private key is known.
+//
+// From s = r + H(R||A||M) * a mod q:
+// r = s - H(R||A||M) * a mod q
+//
+// Args:
+//   - sig: Signature produced by the private key
+//   - privateKey: Known or recovered private key scalar
+//
+// Returns:
+//   - Nonce scalar r if successful, error otherwise
+func RecoverNonce(sig *Signature, privateKey *big.Int) (*big.Int, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("signature is missing R or s")
+	}
+	q := Ed25519CurveOrder
+
+	h := ComputeH(sig.R, sig.PublicKey, sig.Message)
+	r := new(big.Int).Mul(h, privateKey)
+	r.Sub(sig.S, r)
+	r.Mod(r, q)
+
+	return r, nil
+}
+
+// RecoverPrivateKeyFromKnownNonce recovers the private key scalar from a single signature
+// whose nonce is known outright (e.g. leaked in logs or read from a debugger).
+//
+// From s = r + H(R||A||M) * a mod q:
+// a = (s - r) / H(R||A||M) mod q
+//
+// The nonce must reproduce the signature's R (R = r*B).
+func RecoverPrivateKeyFromKnownNonce(sig *Signature, r *big.Int) (*big.Int, error) {
+	if sig.R == nil || sig.S == nil {
+		return nil, errors.New("signature is missing R or s")
+	}
+	q := Ed25519CurveOrder
+
+	R, err := DecodeR(sig.R)
+	if err != nil {
+		return nil, fmt.Errorf("invalid R: %w", err)
+	}
+	if edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(r)).Equal(R) != 1 {
+		return nil, errors.New("nonce does not reproduce the signature's R")
+	}
+
+	h := ComputeH(sig.R, sig.PublicKey, sig.Message)
+	hInv := new(big.Int).ModInverse(h, q)
+	if hInv == nil {
+		return nil, errors.New("failed to compute modular inverse of H(R||A||M)")
+	}
+
+	priv := new(big.Int).Sub(sig.S, r)
+	priv.Mul(priv, hInv)
+	priv.Mod(priv, q)
+	if priv.Sign() == 0 {
+		return nil, errors.New("recovered private key is zero")
+	}
+
+	return priv, nil
+}
+
+// RecoverTwoKeys recovers the private key scalars of two signers whose nonces are
+// affinely related, as when both draw from the same flawed RNG sequence: sig1 and sig3
+// are by key 1, sig2 and sig4 by key 2, and the hypothesis is r2 = a*r1 + b and
+// r4 = a*r3 + b.
+//
+// Each signature gives its nonce as a linear function of its signer's key,
+// r = s - H(R||A||M)*x, so each related pair is a line x2 = alpha*x1 + beta mod q:
+// alpha = a*h1/h2, beta = (s2 - a*s1 - b)/h2. The two lines meet at (x1, x2). As with
+// RecoverPrivateKey, a wrong hypothesis gives wrong keys rather than an error, so verify
+// them against the signers' public keys.
+//
+// Args:
+//   - sig1, sig3: Two signatures by the first key
+//   - sig2, sig4: Two signatures by the second key
+//   - a, b: Affine relationship (r2 = a*r1 + b and r4 = a*r3 + b)
+//
+// Returns:
+//   - Private key scalars x1 and x2 if recovery successful, error otherwise
+func RecoverTwoKeys(sig1, sig2, sig3, sig4 *Signature, a, b *big.Int) (*big.Int, *big.Int, error) {
+	for _, sig := range []*Signature{sig1, sig2, sig3, sig4} {
+		if sig.R == nil || sig.S == nil {
+			return nil, nil, errors.New("signature is missing R or s")
+		}
+	}
+	line1, err := twoKeyLine(sig1, sig2, a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	line2, err := twoKeyLine(sig3, sig4, a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q := Ed25519CurveOrder
+
+	// x1 = (beta2 - beta1) / (alpha1 - alpha2) mod q
+	denominator := new(big.Int).Sub(line1[0], line2[0])
+	denominator.Mod(denominator, q)
+	if denominator.Sign() == 0 {
+		return nil, nil, errors.New("denominator is zero: the two pairs give the same relation between the keys")
+	}
+	denominatorInv := new(big.Int).ModInverse(denominator, q)
+	if denominatorInv == nil {
+		return nil, nil, errors.New("failed to compute modular inverse")
+	}
+
+	x1 := new(big.Int).Sub(line2[1], line1[1])
+	x1.Mul(x1, denominatorInv)
+	x1.Mod(x1, q)
+
+	x2 := new(big.Int).Mul(line1[0], x1)
+	x2.Add(x2, line1[1])
+	x2.Mod(x2, q)
+
+	return x1, x2, nil
+}
+
+// RecoverTwoKeysSharedNonce recovers the private key scalars of two signers that used the
+// same nonces (common with copy-pasted signer code): sig1 and sig3 are by key 1, sig2 and
+// sig4 by key 2, sig1 and sig2 share one nonce and sig3 and sig4 another. It is
+// RecoverTwoKeys with a = 1, b = 0, checked against the signatures' public keys; one
+// shared nonce alone only ties the keys together. With one key already known, the other
+// follows from a single shared nonce: RecoverNonce, then RecoverPrivateKeyFromKnownNonce.
+//
+// Returns:
+//   - Private key scalars a1 and a2, verified against the signatures' public keys, or an error
+func RecoverTwoKeysSharedNonce(sig1, sig2, sig3, sig4 *Signature) (*big.Int, *big.Int, error) {
+	for _, sig := range []*Signature{sig1, sig2, sig3, sig4} {
+		if sig.R == nil || sig.S == nil {
+			return nil, nil, errors.New("signature is missing R or s")
+		}
+	}
+	if sig1.R.Cmp(sig2.R) != 0 || sig3.R.Cmp(sig4.R) != 0 {
+		return nil, nil, errors.New("sig1 and sig2, and sig3 and sig4, must have the same R")
+	}
+	if sig1.R.Cmp(sig3.R) == 0 {
+		return nil, nil, errors.New("the two shared nonces must differ")
+	}
+
+	a1, a2, err := RecoverTwoKeys(sig1, sig2, sig3, sig4, big.NewInt(1), big.NewInt(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok, _ := VerifyRecoveredKey(a1, sig1.PublicKey); !ok {
+		return nil, nil, errors.New("recovered key does not match sig1's public key: the signatures do not share nonces as given")
+	}
+	if ok, _ := VerifyRecoveredKey(a2, sig2.PublicKey); !ok {
+		return nil, nil, errors.New("recovered key does not match sig2's public key: the signatures do not share nonces as given")
+	}
+	return a1, a2, nil
+}
+
+// twoKeyLine returns alpha and beta with x2 = alpha*x1 + beta mod q for a signature by
+// key 1 and one by key 2 with nonces related by r2 = a*r1 + b.
+func twoKeyLine(sig1, sig2 *Signature, a, b *big.Int) ([2]*big.Int, error) {
+	q := Ed25519CurveOrder
+
+	h1 := ComputeH(sig1.R, sig1.PublicKey, sig1.Message)
+	h2 := ComputeH(sig2.R, sig2.PublicKey, sig2.Message)
+	h2Inv := new(big.Int).ModInverse(h2, q)
+	if h2Inv == nil {
+		return [2]*big.Int{}, errors.New("failed to compute modular inverse of H(R||A||M)")
+	}
+
+	alpha := new(big.Int).Mul(a, h1)
+	alpha.Mul(alpha, h2Inv)
+	alpha.Mod(alpha, q)
+
+	beta := new(big.Int).Mul(a, sig1.S)
+	beta.Sub(sig2.S, beta)
+	beta.Sub(beta, b)
+	beta.Mul(beta, h2Inv)
+	beta.Mod(beta, q)
+
+	return [2]*big.Int{alpha, beta}, nil
+}
+
+// RecoverNonces computes the nonce of every signature using the private key in result.
+// Analysts can use the nonces to characterize the flawed RNG (see pkg/prngrecovery).
+func RecoverNonces(result *RecoveryResult, signatures []*Signature) ([]*big.Int, error) {
+	if result == nil || result.PrivateKey == nil {
+		return nil, errors.New("result has no private key")
+	}
+
+	nonces := make([]*big.Int, len(signatures))
+	for i, sig := range signatures {
+		r, err := RecoverNonce(sig, result.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+		nonces[i] = r
+	}
+	return nonces, nil
+}
+
+// AnalyzeNonces computes the nonce of every signature with RecoverNonces and runs the
+// nonce relationship analysis on them (nonceanalysis.Analyze), which identifies the
+// flawed generator's pattern: reused nonce, counter, per-session seed or affine recurrence.
+func AnalyzeNonces(result *RecoveryResult, signatures []*Signature, opts nonceanalysis.Options) ([]*big.Int, *nonceanalysis.Report, error) {
+	nonces, err := RecoverNonces(result, signatures)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nonces, nonceanalysis.Analyze(nonces, Ed25519CurveOrder, opts), nil
+}
@@ -1,8 +1,12 @@
 package eddsaaffine
 
 import (
+	"crypto/ed25519"
+	"fmt"
 	"math/big"
 	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
 )
 
 func TestRecoverPrivateKey_Counter(t *testing.T) {
@@ -159,3 +163,198 @@ func TestVerifyRecoveredKey_InvalidKey(t *testing.T) {
 		t.Error("Wrong key should not verify")
 	}
 }
+
+func TestRecoverPrivateKeyFromKnownNonce(t *testing.T) {
+	a := big.NewInt(0x1234567)
+	r, _ := new(big.Int).SetString("0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9", 16)
+	sig := signWithNonce(a, r, []byte("message 1"))
+
+	priv, err := RecoverPrivateKeyFromKnownNonce(sig, r)
+	if err != nil {
+		t.Fatalf("RecoverPrivateKeyFromKnownNonce: %v", err)
+	}
+	if priv.Cmp(a) != 0 {
+		t.Errorf("Expected %s, got %s", a.Text(16), priv.Text(16))
+	}
+
+	wrong := new(big.Int).Add(r, big.NewInt(1))
+	if _, err := RecoverPrivateKeyFromKnownNonce(sig, wrong); err == nil {
+		t.Error("Expected an error for a nonce that does not reproduce R")
+	}
+}
+
+func TestRecoverTwoKeys(t *testing.T) {
+	// Two signers drawing alternately from one RNG sequence r' = 3*r + 7
+	x1, x2 := big.NewInt(424242), big.NewInt(777777)
+	r := big.NewInt(0x1234567890)
+	var signatures []*Signature
+	for i := 0; i < 4; i++ {
+		x := []*big.Int{x1, x2}[i%2]
+		signatures = append(signatures, signWithNonce(x, r, []byte(fmt.Sprintf("message %d", i))))
+		r = new(big.Int).Mul(r, big.NewInt(3))
+		r.Add(r, big.NewInt(7))
+	}
+
+	got1, got2, err := RecoverTwoKeys(signatures[0], signatures[1], signatures[2], signatures[3], big.NewInt(3), big.NewInt(7))
+	if err != nil {
+		t.Fatalf("RecoverTwoKeys: %v", err)
+	}
+	if got1.Cmp(x1) != 0 || got2.Cmp(x2) != 0 {
+		t.Errorf("Expected keys %s and %s, got %s and %s", x1, x2, got1, got2)
+	}
+
+	// A wrong hypothesis gives keys that do not verify
+	got1, _, err = RecoverTwoKeys(signatures[0], signatures[1], signatures[2], signatures[3], big.NewInt(3), big.NewInt(8))
+	if err == nil && got1.Cmp(x1) == 0 {
+		t.Error("Expected a wrong hypothesis not to give the key")
+	}
+
+	// The same pair twice leaves one equation for two keys
+	if _, _, err := RecoverTwoKeys(signatures[0], signatures[1], signatures[0], signatures[1], big.NewInt(3), big.NewInt(7)); err == nil {
+		t.Error("Expected an error for the same pair twice")
+	}
+}
+
+func TestRecoverTwoKeysSharedNonce(t *testing.T) {
+	signatures, keys := sharedNonceDataset()
+	a1, a2, err := RecoverTwoKeysSharedNonce(signatures[0], signatures[1], signatures[2], signatures[3])
+	if err != nil {
+		t.Fatalf("RecoverTwoKeysSharedNonce: %v", err)
+	}
+	if a1.Cmp(keys[0]) != 0 || a2.Cmp(keys[1]) != 0 {
+		t.Errorf("Expected keys %s and %s, got %s and %s", keys[0], keys[1], a1, a2)
+	}
+
+	// The pairs must share R, and the two nonces must differ
+	if _, _, err := RecoverTwoKeysSharedNonce(signatures[0], signatures[3], signatures[2], signatures[1]); err == nil {
+		t.Error("Expected an error for pairs with different R")
+	}
+	if _, _, err := RecoverTwoKeysSharedNonce(signatures[0], signatures[1], signatures[0], signatures[1]); err == nil {
+		t.Error("Expected an error for a single shared nonce")
+	}
+}
+
+func TestRecoverNonces(t *testing.T) {
+	a := big.NewInt(0x1234567)
+	r1, _ := new(big.Int).SetString("0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9", 16)
+	r2 := new(big.Int).Add(r1, big.NewInt(13511))
+
+	signatures := []*Signature{
+		signWithNonce(a, r1, []byte("message 1")),
+		signWithNonce(a, r2, []byte("message 2")),
+	}
+
+	result := &RecoveryResult{PrivateKey: a}
+	nonces, err := RecoverNonces(result, signatures)
+	if err != nil {
+		t.Fatalf("RecoverNonces: %v", err)
+	}
+	if nonces[0].Cmp(r1) != 0 || nonces[1].Cmp(r2) != 0 {
+		t.Errorf("Nonce mismatch: got [%s, %s]", nonces[0].Text(16), nonces[1].Text(16))
+	}
+
+	if _, err := RecoverNonces(nil, signatures); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
+
+func TestAnalyzeNonces(t *testing.T) {
+	a := big.NewInt(0x1234567)
+	r, _ := new(big.Int).SetString("0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9", 16)
+	var signatures []*Signature
+	for i := int64(0); i < 4; i++ {
+		nonce := new(big.Int).Add(r, big.NewInt(5*i))
+		signatures = append(signatures, signWithNonce(a, nonce, []byte(fmt.Sprintf("message %d", i))))
+	}
+
+	nonces, report, err := AnalyzeNonces(&RecoveryResult{PrivateKey: a}, signatures, nonceanalysis.DefaultOptions())
+	if err != nil {
+		t.Fatalf("AnalyzeNonces: %v", err)
+	}
+	if len(nonces) != 4 || nonces[3].Cmp(new(big.Int).Add(r, big.NewInt(15))) != 0 {
+		t.Errorf("Unexpected nonces %v", nonces)
+	}
+	if report.Pattern != nonceanalysis.PatternConstantStep || report.Step.Int64() != 5 {
+		t.Errorf("Expected a constant step of 5, got %s (step %v)", report.Pattern, report.Step)
+	}
+
+	if _, _, err := AnalyzeNonces(nil, signatures, nonceanalysis.DefaultOptions()); err == nil {
+		t.Error("Expected error for nil result")
+	}
+}
+
+func TestDeriveKeyAndOffset(t *testing.T) {
+	a := big.NewInt(0x7654321)
+	coeff := big.NewInt(3)
+	offset := big.NewInt(987654321)
+
+	r, _ := new(big.Int).SetString("0b1c2d3e4f5061728394a5b6c7d8e9fa0b1c2d3e4f5061728394a5b6c7d8e9f", 16)
+	var signatures []*Signature
+	for i := 0; i < 3; i++ {
+		signatures = append(signatures, signWithNonce(a, r, []byte{byte('a' + i)}))
+		r = new(big.Int).Mul(r, coeff)
+		r.Add(r, offset)
+		r.Mod(r, Ed25519CurveOrder)
+	}
+
+	priv, b, err := DeriveKeyAndOffset(signatures[0], signatures[1], signatures[2], coeff)
+	if err != nil {
+		t.Fatalf("DeriveKeyAndOffset: %v", err)
+	}
+	if priv.Cmp(a) != 0 {
+		t.Errorf("Expected private key %s, got %s", a, priv)
+	}
+	if b.Cmp(offset) != 0 {
+		t.Errorf("Expected b=%s, got %s", offset, b)
+	}
+
+	// A wrong a yields a large, unrelated offset
+	_, b, err = DeriveKeyAndOffset(signatures[0], signatures[1], signatures[2], big.NewInt(2))
+	if err != nil {
+		t.Fatalf("DeriveKeyAndOffset: %v", err)
+	}
+	if new(big.Int).Abs(b).BitLen() <= 64 {
+		t.Errorf("Expected large b for wrong a, got %s", b)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	// A standard signature, read as the parser reads it: R and S as little-endian integers
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = 42
+	key := ed25519.NewKeyFromSeed(seed)
+	publicKey := []byte(key.Public().(ed25519.PublicKey))
+	message := []byte("verify me")
+	raw := ed25519.Sign(key, message)
+	sig := &Signature{
+		R:         new(big.Int).SetBytes(reversedBytes(raw[:32])),
+		S:         new(big.Int).SetBytes(reversedBytes(raw[32:])),
+		Message:   message,
+		PublicKey: publicKey,
+	}
+
+	if ok, err := VerifySignature(sig, nil); err != nil || !ok {
+		t.Fatalf("Expected a crypto/ed25519 signature to verify (%v)", err)
+	}
+	flawed := signWithNonce(big.NewInt(424242), big.NewInt(7), []byte("flawed"))
+	if ok, err := VerifySignature(flawed, publicKeyFor(big.NewInt(424242))); err != nil || !ok {
+		t.Errorf("Expected a signature with a raw nonce to verify (%v)", err)
+	}
+
+	tampered := *sig
+	tampered.Message = []byte("verify you")
+	if ok, _ := VerifySignature(&tampered, nil); ok {
+		t.Error("Expected a different message to fail")
+	}
+	if ok, _ := VerifySignature(sig, flawed.PublicKey); ok {
+		t.Error("Expected a different public key to fail")
+	}
+	unreduced := *sig
+	unreduced.S = new(big.Int).Add(sig.S, Ed25519CurveOrder)
+	if ok, _ := VerifySignature(&unreduced, nil); ok {
+		t.Error("Expected S >= q to be rejected")
+	}
+	if _, err := VerifySignature(sig, []byte{1, 2, 3}); err == nil {
+		t.Error("Expected an error for a short public key")
+	}
+}
@@ -0,0 +1,99 @@
+package eddsaaffine
+
+import "sync/atomic"
+
+// rangeFlushInterval is how many combinations a worker tries between flushing its
+// counter and checking for cancellation.
+const rangeFlushInterval = 1024
+
+// rangeSpace flattens the (a, b) grid of a range search into indices 0..size()-1.
+// a=1 comes first when it is in range (the most common case), then the remaining a
+// values in order; b runs fastest.
+type rangeSpace struct {
+	aValues []int64
+	bMin    int64
+	bCount  int64
+}
+
+func newRangeSpace(aRange, bRange [2]int, skipZeroA bool) rangeSpace {
+	var aValues []int64
+	if aRange[0] <= 1 && aRange[1] >= 1 {
+		aValues = append(aValues, 1)
+	}
+	for a := aRange[0]; a <= aRange[1]; a++ {
+		if a == 1 || (a == 0 && skipZeroA) {
+			continue
+		}
+		aValues = append(aValues, int64(a))
+	}
+	bCount := int64(bRange[1]) - int64(bRange[0]) + 1
+	if bCount < 0 {
+		bCount = 0
+	}
+	return rangeSpace{aValues: aValues, bMin: int64(bRange[0]), bCount: bCount}
+}
+
+func (r rangeSpace) size() int64 {
+	return int64(len(r.aValues)) * r.bCount
+}
+
+// at returns the (a, b) combination at index idx.
+func (r rangeSpace) at(idx int64) (a, b int64) {
+	return r.aValues[idx/r.bCount], r.bMin + idx%r.bCount
+}
+
+// rangeDeal deals a rangeSpace to workers in chunks, round robin: worker w owns chunks
+// w, w+workers, w+2*workers and so on. Every worker starts at the front of the space, so
+// the search as a whole runs through it in order, and all workers search a=1 first.
+type rangeDeal struct {
+	size, chunk int64
+	workers     int
+}
+
+// deal splits the space into chunks of at most chunk combinations, fewer if that would
+// leave some of the n workers without one, and deals them to at most n workers.
+func (r rangeSpace) deal(n int, chunk int64) rangeDeal {
+	size := r.size()
+	if perWorker := (size + int64(n) - 1) / int64(n); perWorker < chunk {
+		chunk = perWorker
+	}
+	if chunk < 1 {
+		chunk = 1
+	}
+	workers := (size + chunk - 1) / chunk
+	if workers > int64(n) {
+		workers = int64(n)
+	}
+	return rangeDeal{size: size, chunk: chunk, workers: int(workers)}
+}
+
+// at returns worker w's k-th chunk [start, end); ok is false once the worker has none
+// left.
+func (d rangeDeal) at(w, k int) (start, end int64, ok bool) {
+	start = (int64(k)*int64(d.workers) + int64(w)) * d.chunk
+	if start >= d.size {
+		return 0, 0, false
+	}
+	return start, min(start+d.chunk, d.size), true
+}
+
+// workerCounters holds one combination counter per worker. Each counter sits on its own
+// cache line so workers never contend; the progress ticker sums them.
+type workerCounters []paddedCounter
+
+type paddedCounter struct {
+	n int64
+	_ [56]byte
+}
+
+func (c workerCounters) add(w int, n int64) {
+	atomic.AddInt64(&c[w].n, n)
+}
+
+func (c workerCounters) total() int64 {
+	var total int64
+	for w := range c {
+		total += atomic.LoadInt64(&c[w].n)
+	}
+	return total
+}
@@ -0,0 +1,147 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRangeSpace_Deal(t *testing.T) {
+	space := newRangeSpace([2]int{-2, 3}, [2]int{-10, 10}, true)
+	if space.aValues[0] != 1 {
+		t.Errorf("Expected a=1 first, got %v", space.aValues)
+	}
+	if space.size() != 5*21 {
+		t.Fatalf("Expected %d combinations, got %d", 5*21, space.size())
+	}
+
+	for _, tc := range []struct {
+		n     int
+		chunk int64
+	}{{1, 1024}, {3, 4}, {7, 2}, {8, 1024}, {1000, 1024}} {
+		seen := make(map[[2]int64]bool)
+		deal := space.deal(tc.n, tc.chunk)
+		if deal.workers > tc.n || deal.chunk > tc.chunk {
+			t.Fatalf("n=%d chunk=%d: dealt %d workers chunks of %d", tc.n, tc.chunk, deal.workers, deal.chunk)
+		}
+		for w := 0; w < deal.workers; w++ {
+			// Every worker starts within the first round of chunks, so on a=1 while the
+			// round fits in it
+			round := int64(deal.workers) * deal.chunk
+			if start, _, ok := deal.at(w, 0); !ok || start >= round {
+				t.Fatalf("n=%d: worker %d starts at %d", tc.n, w, start)
+			} else if a, _ := space.at(start); a != 1 && round <= space.bCount {
+				t.Errorf("n=%d: worker %d starts on a=%d, expected a=1", tc.n, w, a)
+			}
+			last := int64(-1)
+			for k := 0; ; k++ {
+				start, end, ok := deal.at(w, k)
+				if !ok {
+					break
+				}
+				if start <= last {
+					t.Fatalf("n=%d: worker %d chunks out of order", tc.n, w)
+				}
+				for idx := start; idx < end; idx++ {
+					a, b := space.at(idx)
+					if a == 0 {
+						t.Fatalf("n=%d: a=0 not skipped", tc.n)
+					}
+					if seen[[2]int64{a, b}] {
+						t.Fatalf("n=%d: (%d, %d) dealt twice", tc.n, a, b)
+					}
+					seen[[2]int64{a, b}] = true
+				}
+				last = start
+			}
+		}
+		if int64(len(seen)) != space.size() {
+			t.Errorf("n=%d: chunks cover %d distinct combinations, expected %d", tc.n, len(seen), space.size())
+		}
+	}
+}
+
+func TestSmartBruteForceStrategy_RangeSearch(t *testing.T) {
+	a := big.NewInt(424242)
+	publicKey := publicKeyFor(a)
+	signatures := testNonceChain(a, big.NewInt(3), big.NewInt(-57), 2)
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.SkipZeroA = true
+
+	for _, workers := range []int{1, 3, 8} {
+		result, _ := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{-4, 4}, [2]int{-100, 100}, 10, workers)
+		if result == nil {
+			t.Fatalf("Expected recovery with %d workers", workers)
+		}
+		if result.PrivateKey.Cmp(a) != 0 || result.Relationship.A.Int64() != 3 || result.Relationship.B.Int64() != -57 {
+			t.Errorf("Unexpected result with %d workers: a=%s b=%s", workers, result.Relationship.A, result.Relationship.B)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result, _ := strategy.rangeSearch(ctx, signatures, publicKey, [2]int{-4, 4}, [2]int{-100, 100}, 10, 4); result != nil {
+		t.Error("Expected no result from a cancelled search")
+	}
+}
+
+// TestSmartBruteForceStrategy_RangeSearch_Deterministic uses nonces 10 and 50, which
+// satisfy r2 = a*r1 + b for several (a, b) in range, so unordered workers may report any
+// of them. Deterministic mode must always report a=1, b=40, the first in enumeration order.
+func TestSmartBruteForceStrategy_RangeSearch_Deterministic(t *testing.T) {
+	a := big.NewInt(424242)
+	publicKey := publicKeyFor(a)
+	signatures := []*Signature{
+		signWithNonce(a, big.NewInt(10), []byte("message 1")),
+		signWithNonce(a, big.NewInt(50), []byte("message 2")),
+	}
+	strategy := NewSmartBruteForceStrategy()
+	strategy.RangeConfig.SkipZeroA = true
+	strategy.RangeConfig.Deterministic = true
+
+	for _, workers := range []int{1, 2, 3, 8, 8, 8} {
+		result, _ := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{-5, 5}, [2]int{-50, 50}, 10, workers)
+		if result == nil {
+			t.Fatalf("Expected recovery with %d workers", workers)
+		}
+		if result.Relationship.A.Int64() != 1 || result.Relationship.B.Int64() != 40 {
+			t.Errorf("Expected a=1 b=40 with %d workers, got a=%s b=%s", workers, result.Relationship.A, result.Relationship.B)
+		}
+	}
+}
+
+// TestSmartBruteForceStrategy_RangeSearch_NoLeaks runs searches that end by finding the key
+// (with several workers racing to report it) and by cancellation, and checks that every
+// worker and ticker goroutine has exited. Run with -race.
+func TestSmartBruteForceStrategy_RangeSearch_NoLeaks(t *testing.T) {
+	signatures, publicKey := leakTestSignatures()
+	strategy := NewSmartBruteForceStrategy()
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 5; i++ {
+		if result, _ := strategy.rangeSearch(context.Background(), signatures, publicKey, [2]int{1, 4}, [2]int{-100, 100}, 10, 8); result == nil {
+			t.Fatal("Expected recovery")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		strategy.rangeSearch(ctx, signatures[:1:1], publicKey, [2]int{1, 4}, [2]int{-100, 100}, 10, 8)
+		strategy.rangeSearch(ctx, signatures, nil, [2]int{1, 1}, [2]int{0, 1 << 30}, 10, 8)
+		cancel()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Leaked %d goroutines", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// leakTestSignatures returns a pair with r2 = 2*r1 + 7 and the matching public key.
+func leakTestSignatures() ([]*Signature, []byte) {
+	a := big.NewInt(424242)
+	return testNonceChain(a, big.NewInt(2), big.NewInt(7), 2), publicKeyFor(a)
+}
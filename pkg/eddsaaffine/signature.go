@@ -22,7 +22,7 @@ type AffineRelationship struct {
 type RecoveryResult struct {
 	PrivateKey    *big.Int           // Recovered private key
 	Relationship  AffineRelationship // The affine relationship found (r2 = a*r1 + b)
-	SignaturePair [2]int             // Indices of the signature pair used
+	SignaturePair [2]int             // Indices of the signature pair used, r1's first (reversed if found by RangeConfig.BothDirections)
 	Verified      bool                // Whether the key was verified against a public key
 	Pattern       string              // Human-readable pattern description
 }
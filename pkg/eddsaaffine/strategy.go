@@ -2,7 +2,9 @@ package eddsaaffine
 
 import (
 	"context"
+	"fmt"
 	"math/big"
+	"time"
 )
 
 // BruteForceStrategy defines the interface for custom brute-force strategies.
@@ -25,12 +27,76 @@ type Pattern struct {
 	Priority int   // Lower priority = tested first
 }
 
+// OrderMinus returns L - c, for writing a pattern the way the signer computes it: b = L - c
+// for a counter that wraps around the group order, a = L - 1 for nonces computed as L - r.
+// Pattern{A: OrderMinus(1), B: OrderMinus(3)} is r2 = L - r1 - 3. A and B are reduced mod L
+// to the value of least absolute value before they are tried (here a = -1, b = -3), which
+// is also how the result's Relationship reports them.
+func OrderMinus(c int64) *big.Int {
+	return new(big.Int).Sub(Ed25519CurveOrder, big.NewInt(c))
+}
+
+// PatternsForSteps returns a counter pattern (a = 1) for each step, in the order given:
+// r2 = r1 + step, named like the common patterns ("counter_+17", "counter_-3") and
+// prioritized 1, 2, ... so that they are tried in that order. Steps may be negative.
+func PatternsForSteps(steps []int64) []Pattern {
+	patterns := make([]Pattern, 0, len(steps))
+	for i, step := range steps {
+		patterns = append(patterns, Pattern{
+			A:        big.NewInt(1),
+			B:        big.NewInt(step),
+			Name:     fmt.Sprintf("counter_%+d", step),
+			Priority: i + 1,
+		})
+	}
+	return patterns
+}
+
+// PatternsForRange returns the patterns r2 = a*r1 + b for b from bMin to bMax in steps
+// of stride (a stride below 1 is taken as 1), prioritized in that order. The bounds need
+// not be symmetric and either may be negative; a may be negative too. Patterns with a = 1
+// are named like counters ("counter_-2"), the others "affine_a<a>_b<b>". Each b is one
+// pattern, so wide ranges belong in RangeConfig instead.
+func PatternsForRange(a, bMin, bMax, stride int64) []Pattern {
+	if stride < 1 {
+		stride = 1
+	}
+	var patterns []Pattern
+	for b := bMin; b <= bMax; b += stride {
+		name := fmt.Sprintf("affine_a%d_b%+d", a, b)
+		if a == 1 {
+			name = fmt.Sprintf("counter_%+d", b)
+		}
+		patterns = append(patterns, Pattern{
+			A:        big.NewInt(a),
+			B:        big.NewInt(b),
+			Name:     name,
+			Priority: len(patterns) + 1,
+		})
+		if b > bMax-stride {
+			break // b + stride would pass bMax, or overflow
+		}
+	}
+	return patterns
+}
+
+// centered returns v mod L mapped into (-L/2, L/2].
+func centered(v *big.Int) *big.Int {
+	d := new(big.Int).Mod(v, Ed25519CurveOrder)
+	if d.Cmp(new(big.Int).Rsh(Ed25519CurveOrder, 1)) > 0 {
+		d.Sub(d, Ed25519CurveOrder)
+	}
+	return d
+}
+
 // RangeConfig configures the search range for brute-force operations.
 type RangeConfig struct {
-	// ARange defines the range for a values [Min, Max] (inclusive)
+	// ARange defines the range for a values [Min, Max] (inclusive). Values are mod L:
+	// a = -1 is a = L - 1, nonces computed as L - r.
 	ARange [2]int
 
-	// BRange defines the range for b values [Min, Max] (inclusive)
+	// BRange defines the range for b values [Min, Max] (inclusive). Values are mod L:
+	// b = -c is b = L - c, as from a counter that wraps around the group order.
 	BRange [2]int
 
 	// MaxPairs limits the number of signature pairs to test
@@ -39,8 +105,60 @@ type RangeConfig struct {
 	// NumWorkers controls parallelization (0 = auto-detect)
 	NumWorkers int
 
+	// AutoTune, if set, measures the first parallel range phase for this long before it
+	// runs, on the signatures searched, under worker counts around NumWorkers and several
+	// chunk sizes, and searches with the fastest (see AutoTune and Tuned). 0 = off
+	AutoTune time.Duration
+
 	// SkipZeroA skips a=0 (which is wasteful)
 	SkipZeroA bool
+
+	// BothDirections also tries each relationship with |a| >= 2 the other way round on
+	// each pair (i, j), i < j: r_i = a*r_j + b, reported as SignaturePair [j, i]. For
+	// a = 1 and a = -1 the other direction is the same relationship with b negated or
+	// unchanged, which a b range symmetric around 0 already covers.
+	BothDirections bool
+
+	// Deterministic makes parallel range searches report the match a sequential scan would
+	// find first (lowest pair, then a with a=1 first, then b), so repeated runs give identical results.
+	// It costs at most one pair's worth of extra work per worker.
+	Deterministic bool
+
+	// MaxRate caps the range search at this many candidates/sec across all workers (0 = unlimited)
+	MaxRate float64
+
+	// MaxCPUPercent caps each worker's busy time as a percentage of wall time (0 = unlimited)
+	MaxCPUPercent int
+
+	// MaxCandidates stops the range search of the strategy after this many (a, b)
+	// candidates in total, across phases and searches (0 = unlimited); see ErrQuotaExceeded
+	MaxCandidates int64
+
+	// MaxCPUTime stops the range search of the strategy once its workers have been busy
+	// this long in total, summed over workers (0 = unlimited); see ErrQuotaExceeded
+	MaxCPUTime time.Duration
+
+	// ThrottleControl, if set, changes MaxRate and MaxCPUPercent, or pauses the search, while it runs.
+	// Close it once the strategy is no longer used.
+	ThrottleControl <-chan ThrottleSetting
+
+	// DeriveB solves b directly from signature triples for each a in ARange
+	// instead of iterating over b (requires at least 3 signatures from one nonce chain)
+	DeriveB bool
+
+	// MaxDerivedBBits bounds |b| accepted from derivation (0 = 64 bits)
+	MaxDerivedBBits int
+
+	// PointFilter checks R2 = a*R1 + b*B on the curve before attempting scalar recovery
+	PointFilter bool
+
+	// CounterOffsetBound bounds |b| for the a=1 baby-step giant-step solve of R2 - R1 = b*B
+	// (0 = skip this phase). Memory and time grow with sqrt(2*bound).
+	CounterOffsetBound int64
+
+	// Arithmetic selects the modular arithmetic candidate keys are recovered with
+	// (default AutoArithmetic)
+	Arithmetic ArithmeticBackend
 }
 
 // DefaultRangeConfig returns a sensible default configuration.
@@ -51,6 +169,11 @@ func DefaultRangeConfig() RangeConfig {
 		MaxPairs:  100,
 		NumWorkers: 0, // Auto-detect
 		SkipZeroA: true,
+		BothDirections: true,
+		DeriveB:   true,
+
+		PointFilter:        true,
+		CounterOffsetBound: 1 << 20,
 	}
 }
 
@@ -61,6 +184,15 @@ type PatternConfig struct {
 
 	// IncludeCommonPatterns includes built-in common patterns
 	IncludeCommonPatterns bool
+
+	// Progressions tries the steps of the nonce progressions FindProgressions detects
+	// first, right after the same nonce check
+	Progressions bool
+
+	// Inject, if set, adds patterns to a running search, e.g. from a job API or a
+	// reloaded pattern file. They are tried, by priority, at the next safe point: between
+	// phases, or during a range phase with its workers held at their next batch.
+	Inject <-chan []Pattern
 }
 
 // DefaultPatternConfig returns a configuration with common patterns enabled.
@@ -68,6 +200,7 @@ func DefaultPatternConfig() PatternConfig {
 	return PatternConfig{
 		CustomPatterns:        []Pattern{},
 		IncludeCommonPatterns: true,
+		Progressions:          true,
 	}
 }
 
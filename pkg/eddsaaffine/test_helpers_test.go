@@ -4,16 +4,27 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"filippo.io/edwards25519"
 )
 
+//go:generate go run ../../cmd/recovery generate-fixtures --out testdata/fixtures --seed 1
+
 // fixturesDir returns the path to the fixtures directory (works regardless of test cwd).
+// When the Python-generated fixtures are missing, it returns testdata/fixtures, a seeded
+// set generated in Go by pkg/flawedsigner (see the go:generate line above).
 func fixturesDir() string {
 	_, f, _, _ := runtime.Caller(0)
-	return filepath.Join(filepath.Dir(f), "..", "..", "fixtures")
+	dir := filepath.Join(filepath.Dir(f), "..", "..", "fixtures")
+	if _, err := os.Stat(filepath.Join(dir, "test_eddsa_key_info.json")); err == nil {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(f), "testdata", "fixtures")
 }
 
 // loadTestKeyInfo reads the test key information from fixtures/test_eddsa_key_info.json
@@ -71,3 +82,28 @@ func loadTestSignatures(filename string) ([]*Signature, error) {
 	parser := &JSONParser{}
 	return parser.ParseSignatures(filepath.Join(fixturesDir(), filename))
 }
+
+// publicKeyFor computes the public key A = a*B for a signing scalar.
+func publicKeyFor(a *big.Int) []byte {
+	return edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(a)).Bytes()
+}
+
+// signWithNonce creates a signature over message with signing scalar a and an explicit nonce r.
+func signWithNonce(a, r *big.Int, message []byte) *Signature {
+	publicKey := publicKeyFor(a)
+	rPoint := edwards25519.NewIdentityPoint().ScalarBaseMult(scalarFromBigInt(r)).Bytes()
+
+	// R is stored as the little-endian integer of its encoding
+	rInt := new(big.Int)
+	for i := len(rPoint) - 1; i >= 0; i-- {
+		rInt.Lsh(rInt, 8)
+		rInt.Or(rInt, big.NewInt(int64(rPoint[i])))
+	}
+
+	h := ComputeH(rInt, publicKey, message)
+	s := new(big.Int).Mul(h, a)
+	s.Add(s, r)
+	s.Mod(s, Ed25519CurveOrder)
+
+	return &Signature{R: rInt, S: s, Message: message, PublicKey: publicKey}
+}
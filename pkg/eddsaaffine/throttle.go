@@ -0,0 +1,168 @@
+package eddsaaffine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ThrottleSetting is a throttle change sent on RangeConfig.ThrottleControl while a search runs.
+// Zero values lift the corresponding limit.
+type ThrottleSetting struct {
+	// MaxRate caps candidates/sec across all workers
+	MaxRate float64
+
+	// MaxCPUPercent caps each worker's busy time as a percentage of wall time
+	MaxCPUPercent int
+
+	// Paused stops the workers at their next batch, keeping their place, until a setting
+	// with Paused false arrives (used by job queues to preempt a search)
+	Paused bool
+}
+
+// throttle enforces a ThrottleSetting in the range search workers.
+// Workers report after every batch of candidates and sleep for as long as the
+// stricter of the two limits requires.
+type throttle struct {
+	mu      sync.Mutex
+	setting ThrottleSetting
+	next    time.Time     // earliest time the rate limit allows further work
+	held    int           // holds taken by the strategy itself (see hold)
+	resumed chan struct{} // closed when the search is resumed; nil unless paused or held
+}
+
+// newThrottle returns the throttle for config, or nil if the search is unthrottled.
+// With a control channel, the throttle follows its settings until the channel is closed.
+func newThrottle(config RangeConfig) *throttle {
+	initial := ThrottleSetting{MaxRate: config.MaxRate, MaxCPUPercent: config.MaxCPUPercent}
+	if config.ThrottleControl == nil && !initial.limited() {
+		return nil
+	}
+
+	t := &throttle{setting: initial}
+	if config.ThrottleControl != nil {
+		go func() {
+			for setting := range config.ThrottleControl {
+				t.set(setting)
+			}
+			t.set(ThrottleSetting{}) // never leave the workers paused
+		}()
+	}
+	return t
+}
+
+// set applies a setting received while the search runs.
+func (t *throttle) set(setting ThrottleSetting) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setting = setting
+	t.update()
+}
+
+// hold stops the workers at their next batch, as a pause does, until release is called;
+// holds and pauses sent on ThrottleControl are independent of each other.
+func (t *throttle) hold() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.held++
+	t.update()
+}
+
+// release ends a hold.
+func (t *throttle) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.held--
+	t.update()
+}
+
+// update stops or resumes the workers after a change of the pause state or the holds.
+// The caller holds t.mu.
+func (t *throttle) update() {
+	stopped := t.setting.Paused || t.held > 0
+	switch {
+	case stopped && t.resumed == nil:
+		t.resumed = make(chan struct{})
+	case !stopped && t.resumed != nil:
+		close(t.resumed)
+		t.resumed = nil
+	}
+}
+
+func (s ThrottleSetting) limited() bool {
+	return s.MaxRate > 0 || (s.MaxCPUPercent > 0 && s.MaxCPUPercent < 100)
+}
+
+// wait blocks a worker that just tried n candidates in busy time, until both limits allow it
+// to continue and the search is not paused, or ctx is done. A nil throttle never blocks.
+func (t *throttle) wait(ctx context.Context, n int64, busy time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	if resumed := t.resumed; resumed != nil {
+		t.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-resumed:
+		}
+		t.mu.Lock()
+	}
+	setting := t.setting
+	now := time.Now()
+	var delay time.Duration
+	if setting.MaxRate > 0 {
+		if t.next.Before(now) {
+			t.next = now
+		}
+		t.next = t.next.Add(time.Duration(float64(n) / setting.MaxRate * float64(time.Second)))
+		delay = t.next.Sub(now)
+	}
+	t.mu.Unlock()
+
+	if p := setting.MaxCPUPercent; p > 0 && p < 100 {
+		if idle := busy * time.Duration(100-p) / time.Duration(p); idle > delay {
+			delay = idle
+		}
+	}
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// batchSize returns how many candidates a worker tries between calls to wait: about 50ms
+// of work at the rate limit, so low rates don't run in long bursts.
+func (t *throttle) batchSize() int64 {
+	return t.batchSizeFor(rangeFlushInterval)
+}
+
+// batchSizeFor is batchSize for workers that flush every chunk candidates unthrottled.
+func (t *throttle) batchSizeFor(chunk int64) int64 {
+	if t == nil {
+		return chunk
+	}
+	t.mu.Lock()
+	rate := t.setting.MaxRate
+	t.mu.Unlock()
+
+	if rate <= 0 {
+		return chunk
+	}
+	n := int64(rate / 20)
+	if n < 1 {
+		n = 1
+	}
+	if n > chunk {
+		n = chunk
+	}
+	return n
+}
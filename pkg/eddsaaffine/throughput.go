@@ -0,0 +1,84 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"runtime"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/internal/eta"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+// SearchRate is a measured brute-force throughput.
+type SearchRate struct {
+	// Workers is the number of parallel workers used
+	Workers int
+
+	// Candidates is the number of (a, b) candidates tried
+	Candidates int64
+
+	// Elapsed is the wall-clock time spent
+	Elapsed time.Duration
+}
+
+// PerSecond returns candidates tried per second.
+func (r SearchRate) PerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Candidates) / r.Elapsed.Seconds()
+}
+
+// Estimate returns how long trying the given number of candidates takes at this rate,
+// capped at the longest time.Duration.
+func (r SearchRate) Estimate(candidates float64) time.Duration {
+	return eta.Duration(candidates, r.PerSecond())
+}
+
+// MeasureSearchRate runs the parallel range search for duration on a synthetic signature pair
+// and reports its throughput. The nonces are unrelated, so every candidate is rejected by the
+// R point filter (or, with the filter off, by the public key), i.e. the rate of a search that
+// has not found the key yet.
+//
+// Args:
+//   - duration: How long to search
+//   - workers: Number of parallel workers (0 = auto-detect based on CPU cores)
+func MeasureSearchRate(ctx context.Context, duration time.Duration, workers int) SearchRate {
+	return measureSearchRate(ctx, duration, workers, rangeFlushInterval)
+}
+
+// measureSearchRate is MeasureSearchRate with workers flushing every chunk candidates.
+func measureSearchRate(ctx context.Context, duration time.Duration, workers int, chunk int64) SearchRate {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	signatures, otherKey := throughputSignatures()
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	strategy := NewSmartBruteForceStrategy()
+	strategy.tuning = Tuning{Workers: workers, ChunkSize: chunk}
+	start := time.Now()
+	_, tested := strategy.rangeSearch(ctx, signatures, otherKey, [2]int{1, 1}, [2]int{-1 << 30, 1 << 30}, 1, workers)
+	return SearchRate{Workers: workers, Candidates: tested, Elapsed: time.Since(start)}
+}
+
+// throughputSignatures returns the synthetic pair MeasureSearchRate searches, with nonces
+// r and 2r, and a public key that matches no candidate.
+func throughputSignatures() ([]*Signature, []byte) {
+	key := flawedsigner.NewEdDSAKeyFromSeed(bytes.Repeat([]byte{0x5e}, 32))
+	r, _ := new(big.Int).SetString("05a4b3c2d1e0f9e8d7c6b5a4938271605a4b3c2d1e0f9e8d7c6b5a493827160", 16)
+	// Sign only fails for a nonce source that runs dry, which AffineFrom never does
+	signed, _ := key.Sign([][]byte{[]byte("throughput 1"), []byte("throughput 2")}, flawedsigner.AffineFrom(r, big.NewInt(2), big.NewInt(0)))
+
+	signatures := make([]*Signature, len(signed))
+	for i, sig := range signed {
+		signatures[i] = &Signature{R: sig.R, S: sig.S, Message: sig.Message, PublicKey: sig.PublicKey}
+	}
+	otherKey := flawedsigner.NewEdDSAKeyFromSeed(make([]byte, 32))
+	return signatures, otherKey.Public
+}
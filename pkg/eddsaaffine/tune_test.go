@@ -0,0 +1,72 @@
+package eddsaaffine
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestAutoTune(t *testing.T) {
+	tuning := AutoTune(context.Background(), 250*time.Millisecond, 2)
+	if tuning.Workers != 1 && tuning.Workers != 2 && tuning.Workers != 4 {
+		t.Errorf("Workers = %d, want 1, 2 or 4", tuning.Workers)
+	}
+	if tuning.ChunkSize != 256 && tuning.ChunkSize != rangeFlushInterval && tuning.ChunkSize != 4096 {
+		t.Errorf("ChunkSize = %d, want one of %v", tuning.ChunkSize, tuneChunkSizes)
+	}
+	if tuning.Rate.Workers != tuning.Workers || tuning.Rate.Candidates == 0 {
+		t.Errorf("Unexpected rate %+v", tuning.Rate)
+	}
+
+	// A cancelled context measures nothing but still returns a usable configuration
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if tuning := AutoTune(ctx, time.Second, 3); tuning.Workers != 3 || tuning.ChunkSize != rangeFlushInterval {
+		t.Errorf("Cancelled AutoTune = %+v, want 3 workers and the default chunk size", tuning)
+	}
+}
+
+func TestSmartBruteForceStrategy_AutoTune(t *testing.T) {
+	a := big.NewInt(0x5eed)
+	signatures := []*Signature{
+		signWithNonce(a, big.NewInt(424242), []byte("message 1")),
+		signWithNonce(a, big.NewInt(424242+500), []byte("message 2")),
+	}
+	publicKey := publicKeyFor(a)
+
+	// One parallel phase, tuned on the search's own pair before it runs
+	config := DefaultRangeConfig()
+	config.ARange = [2]int{1, 1}
+	config.BRange = [2]int{0, parallelThreshold}
+	config.DeriveB = false
+	config.CounterOffsetBound = 0
+	config.AutoTune = 100 * time.Millisecond
+	strategy := NewSmartBruteForceStrategy().
+		WithRangeConfig(config).
+		WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	result := strategy.Search(context.Background(), signatures, publicKey)
+	if result == nil || result.PrivateKey.Cmp(a) != 0 {
+		t.Fatalf("Expected the key from b = 500, got %+v", result)
+	}
+
+	tuned := strategy.Tuned()
+	if tuned.Workers == 0 || tuned.ChunkSize == 0 || tuned.Rate.Candidates == 0 {
+		t.Fatalf("Expected a tuned configuration, got %+v", tuned)
+	}
+	if strategy.workers() != tuned.Workers || strategy.chunkSize() != tuned.ChunkSize {
+		t.Errorf("Search runs with %d workers and chunks of %d, want %+v", strategy.workers(), strategy.chunkSize(), tuned)
+	}
+
+	// Without AutoTune nothing is measured
+	config.AutoTune = 0
+	plain := NewSmartBruteForceStrategy().
+		WithRangeConfig(config).
+		WithPatternConfig(PatternConfig{IncludeCommonPatterns: false})
+	if result := plain.Search(context.Background(), signatures, publicKey); result == nil {
+		t.Fatal("Expected the key without AutoTune")
+	}
+	if tuned := plain.Tuned(); tuned != (Tuning{}) {
+		t.Errorf("Tuned() = %+v without AutoTune, want the zero Tuning", tuned)
+	}
+}
@@ -0,0 +1,125 @@
+package eddsaaffine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+)
+
+// Errors for Ed25519 inputs that some deployed verifiers accept but recovery cannot use
+// as they are. Left in a dataset they make the search miss the key or verify nothing,
+// silently, so the client rejects them up front (see CheckSignature and CheckPublicKey).
+var (
+	// ErrNonCanonicalR is returned for an R encoded with y >= p or with the sign bit set
+	// for x = 0. R is hashed as encoded but compared as a point, so a reused nonce under
+	// two encodings would go unnoticed.
+	ErrNonCanonicalR = errors.New("R is not a canonical point encoding (RFC 8032 section 5.1.3)")
+	// ErrSNotReduced is returned for s >= L, usually a misread S (byte order, see
+	// Diagnose) or a malleated copy of another signature.
+	ErrSNotReduced = errors.New("s is not reduced mod L (RFC 8032 section 5.1.7)")
+	// ErrSmallOrderR is returned for an R in the torsion subgroup: the nonce is 0 mod L.
+	ErrSmallOrderR = errors.New("R has small order: the nonce is 0 mod L")
+	// ErrTorsionR is returned for R = r*B + T with T of small order. Such R values do not
+	// follow the nonce relation, so the point filters and discrete-log searches would
+	// reject the right candidate.
+	ErrTorsionR = errors.New("R has a small-order (torsion) component")
+	// ErrSmallOrderPublicKey is returned for a public key in the torsion subgroup, which
+	// is not a*B for any key a.
+	ErrSmallOrderPublicKey = errors.New("public key has small order")
+	// ErrMixedOrderPublicKey is returned for a public key A = a*B + T with T of small
+	// order: the recovered a would never verify against it.
+	ErrMixedOrderPublicKey = errors.New("public key has a small-order (torsion) component")
+)
+
+// lMinusOne is L - 1 as a scalar, for the torsion check [L]P = [L-1]P + P.
+var lMinusOne = scalarFromBigInt(new(big.Int).Sub(Ed25519CurveOrder, big.NewInt(1)))
+
+// CheckSignature reports whether an Ed25519 signature's R and S are usable for recovery:
+// R must be a canonical encoding of a point in the prime-order subgroup and S must be
+// below L. The signature's public key is checked separately, with CheckPublicKey.
+func CheckSignature(sig *Signature) error {
+	if sig.R == nil || sig.S == nil {
+		return errors.New("signature is missing R or s")
+	}
+	if sig.S.Sign() < 0 || sig.S.Cmp(Ed25519CurveOrder) >= 0 {
+		return ErrSNotReduced
+	}
+	R, err := DecodeR(sig.R)
+	if err != nil {
+		return fmt.Errorf("invalid R: %w", err)
+	}
+	if !bytes.Equal(R.Bytes(), reversedBytes(sig.R.FillBytes(make([]byte, 32)))) {
+		return ErrNonCanonicalR
+	}
+	switch {
+	case hasSmallOrder(R):
+		return ErrSmallOrderR
+	case hasTorsion(R):
+		return ErrTorsionR
+	}
+	return nil
+}
+
+// CheckPublicKey reports whether an Ed25519 public key can verify a recovered key: a
+// canonical encoding of a point in the prime-order subgroup other than the identity.
+func CheckPublicKey(publicKey []byte) error {
+	if len(publicKey) != 32 {
+		return errors.New("public key must be 32 bytes")
+	}
+	A, err := edwards25519.NewIdentityPoint().SetBytes(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	switch {
+	case !bytes.Equal(A.Bytes(), publicKey):
+		return errors.New("public key is not a canonical point encoding")
+	case hasSmallOrder(A):
+		return ErrSmallOrderPublicKey
+	case hasTorsion(A):
+		return ErrMixedOrderPublicKey
+	}
+	return nil
+}
+
+// checkInputs rejects the signatures and public keys CheckSignature and CheckPublicKey
+// reject, naming the first offending signature. Each distinct public key is checked once.
+// Custom curves have their own encodings and are not checked.
+func (c *Client) checkInputs(signatures []*Signature, publicKey []byte) error {
+	if c.curve != nil {
+		return nil
+	}
+	if len(publicKey) > 0 {
+		if err := CheckPublicKey(publicKey); err != nil {
+			return err
+		}
+	}
+	checked := make(map[string]bool)
+	for i, sig := range signatures {
+		if err := CheckSignature(sig); err != nil {
+			return fmt.Errorf("signature %d: %w", i, err)
+		}
+		if len(sig.PublicKey) == 0 || checked[string(sig.PublicKey)] {
+			continue
+		}
+		if err := CheckPublicKey(sig.PublicKey); err != nil {
+			return fmt.Errorf("signature %d: %w", i, err)
+		}
+		checked[string(sig.PublicKey)] = true
+	}
+	return nil
+}
+
+// hasSmallOrder reports whether [8]p is the identity.
+func hasSmallOrder(p *edwards25519.Point) bool {
+	return edwards25519.NewIdentityPoint().MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// hasTorsion reports whether p is outside the prime-order subgroup: [L]p is not the
+// identity.
+func hasTorsion(p *edwards25519.Point) bool {
+	lp := edwards25519.NewIdentityPoint().VarTimeDoubleScalarBaseMult(lMinusOne, p, edwards25519.NewScalar())
+	return lp.Add(lp, p).Equal(edwards25519.NewIdentityPoint()) != 1
+}
@@ -0,0 +1,55 @@
+package eddsaaffine
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+func TestClient_RejectsEdgeCases(t *testing.T) {
+	dir := t.TempDir()
+	if err := flawedsigner.WriteEdDSAEdgeCaseFixtures(dir, flawedsigner.NewSeededReader(5)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]error{
+		"non_canonical_r": ErrNonCanonicalR,
+		"small_order_r":   ErrSmallOrderR,
+		"torsion_r":       ErrTorsionR,
+		"s_not_reduced":   ErrSNotReduced,
+		"mixed_order_key": ErrMixedOrderPublicKey,
+	}
+	ctx := context.Background()
+	for _, kind := range flawedsigner.EdDSAEdgeCases {
+		path := filepath.Join(dir, "test_eddsa_edge_"+kind+".json")
+		if _, err := NewClient().RecoverKey(ctx, path, ""); !errors.Is(err, want[kind]) {
+			t.Errorf("%s: expected %v, got %v", kind, want[kind], err)
+		}
+		if _, err := NewClient().RecoverKeyWithKnownRelationship(ctx, path, 1, 1, ""); !errors.Is(err, want[kind]) {
+			t.Errorf("%s: known relationship: expected %v, got %v", kind, want[kind], err)
+		}
+	}
+}
+
+func TestCheckSignature(t *testing.T) {
+	signatures, err := loadTestSignatures("test_eddsa_signatures_counter.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range signatures {
+		if err := CheckSignature(sig); err != nil {
+			t.Errorf("Signature %d: %v", i, err)
+		}
+		if err := CheckPublicKey(sig.PublicKey); err != nil {
+			t.Errorf("Signature %d: public key: %v", i, err)
+		}
+	}
+
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if err := CheckPublicKey(identity); !errors.Is(err, ErrSmallOrderPublicKey) {
+		t.Errorf("Expected ErrSmallOrderPublicKey for the identity, got %v", err)
+	}
+}
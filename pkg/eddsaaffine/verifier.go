@@ -0,0 +1,83 @@
+package eddsaaffine
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"filippo.io/edwards25519"
+)
+
+// KeyVerifier checks recovered private scalars against one Ed25519 public key, decoded
+// once. VerifyRecoveredKey decodes the key (a square root) and allocates a scalar and
+// two points on every call; a KeyVerifier encodes the scalar canonically into reused
+// buffers, multiplies the base point with the library's precomputed tables (faster than
+// its variable-time wNAF multiplication, which also doubles through every bit) and compares
+// the product with the decoded key, taking its scratch space from a pool. It is safe for
+// concurrent use, so one serves all search workers.
+type KeyVerifier struct {
+	target  *edwards25519.Point
+	scratch sync.Pool
+}
+
+// verifyScratch is the per-call state of a KeyVerifier.
+type verifyScratch struct {
+	buf    [32]byte
+	scalar edwards25519.Scalar
+	point  edwards25519.Point
+}
+
+// NewKeyVerifier decodes a 32-byte Ed25519 public key.
+func NewKeyVerifier(publicKey []byte) (*KeyVerifier, error) {
+	if len(publicKey) != 32 {
+		return nil, errors.New("public key must be 32 bytes")
+	}
+	target, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	v := &KeyVerifier{target: target}
+	v.scratch.New = func() any { return new(verifyScratch) }
+	return v, nil
+}
+
+// newKeyVerifier is NewKeyVerifier for keys the strategies were given as is: an invalid
+// key yields nil, whose Verify accepts no key, as VerifyRecoveredKey does.
+func newKeyVerifier(publicKey []byte) *KeyVerifier {
+	v, err := NewKeyVerifier(publicKey)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// Verify reports whether privateKey is the public key's scalar. Scalars outside
+// [1, L-1] never are.
+func (v *KeyVerifier) Verify(privateKey *big.Int) bool {
+	if v == nil || privateKey.Sign() <= 0 || privateKey.Cmp(Ed25519CurveOrder) >= 0 {
+		return false
+	}
+	var key [32]byte
+	privateKey.FillBytes(key[:])
+	return v.verifyKey(&key)
+}
+
+// verifyKey is Verify for a scalar in [1, L-1] as 32 big-endian bytes. It does not
+// allocate.
+func (v *KeyVerifier) verifyKey(key *[32]byte) bool {
+	if v == nil {
+		return false
+	}
+	scratch := v.scratch.Get().(*verifyScratch)
+	defer v.scratch.Put(scratch)
+
+	// Canonical little-endian encoding of a scalar below L
+	for i := range scratch.buf {
+		scratch.buf[i] = key[31-i]
+	}
+	if _, err := scratch.scalar.SetCanonicalBytes(scratch.buf[:]); err != nil {
+		return false
+	}
+	scratch.point.ScalarBaseMult(&scratch.scalar)
+	return scratch.point.Equal(v.target) == 1
+}
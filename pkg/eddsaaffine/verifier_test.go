@@ -0,0 +1,53 @@
+package eddsaaffine
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestKeyVerifier(t *testing.T) {
+	key := big.NewInt(0xdeadbeef)
+	verifier, err := NewKeyVerifier(publicKeyFor(key))
+	if err != nil {
+		t.Fatalf("NewKeyVerifier: %v", err)
+	}
+
+	for _, tt := range []struct {
+		key  *big.Int
+		want bool
+	}{
+		{key, true},
+		{new(big.Int).Add(key, big.NewInt(1)), false},
+		{new(big.Int).Add(key, Ed25519CurveOrder), false}, // same point, not a canonical scalar
+		{big.NewInt(0), false},
+	} {
+		if got := verifier.Verify(tt.key); got != tt.want {
+			t.Errorf("Verify(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	// Workers share one verifier; run with -race
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if verifier.Verify(big.NewInt(int64(w*100 + i + 1))) {
+					t.Errorf("Verified a wrong key")
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, publicKey := range [][]byte{nil, make([]byte, 31)} {
+		if _, err := NewKeyVerifier(publicKey); err == nil {
+			t.Errorf("Expected an error for public key %x", publicKey)
+		}
+	}
+	if newKeyVerifier(nil).Verify(key) {
+		t.Error("Expected an invalid key's verifier to accept no key")
+	}
+}
@@ -0,0 +1,19 @@
+// Package engagement renders the report of a key recovery engagement: the dataset
+// summary, the analysis findings, the recovery result with the private key redacted, the
+// nonce relationship diagram, the corroborating evidence and the tool configuration and
+// version, as HTML (print it to PDF from a browser) or Markdown.
+//
+// # Quick Start
+//
+//	report := &engagement.Report{
+//		Dataset:     "signatures.json",
+//		ToolVersion: "v1.4.0",
+//		Analysis:    ecdsaaffine.AnalyzeDataset(signatures),
+//		Result:      result,
+//	}
+//	report.AddEvidence(signatures) // confidence, corroboration and the nonce relationships
+//	report.WriteHTML(os.Stdout)
+//
+// The private key is shown only as its first and last bytes and its public key, unless
+// RevealKey is set.
+package engagement
@@ -0,0 +1,306 @@
+package engagement
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
+)
+
+// Report is an engagement report. Every part but the dataset is optional and left out
+// of the rendering when unset.
+type Report struct {
+	Title     string    // default "Key recovery report"
+	Generated time.Time // default the time of rendering
+
+	Dataset       string // signature file path or URL
+	DatasetSHA256 string // see auditlog.FingerprintFile
+
+	ToolVersion string
+	Config      map[string]string // search configuration, e.g. the command-line flags
+
+	Analysis *ecdsaaffine.DatasetAnalysis
+
+	// Result is the recovered key, nil if the search failed with Err
+	Result *ecdsaaffine.RecoveryResult
+	Err    error
+
+	Confidence    *ecdsaaffine.Confidence // the result checked against its signatures (see AddEvidence)
+	Relationships *nonceanalysis.Report   // the relationships of the nonces the key gives
+
+	RevealKey bool // print the private key in full instead of redacted
+}
+
+// AddEvidence fills in the result's Confidence and the relationships of the nonces it
+// gives from the signatures it was recovered from. It does nothing without a result.
+func (r *Report) AddEvidence(signatures []*ecdsaaffine.Signature) error {
+	if r.Result == nil {
+		return nil
+	}
+	confidence := ecdsaaffine.ScoreResult(r.Result, signatures)
+	r.Confidence = &confidence
+	nonces, err := ecdsaaffine.RecoverNonces(r.Result, signatures)
+	if err != nil {
+		return err
+	}
+	r.Relationships = nonceanalysis.Analyze(nonces, ecdsaaffine.Secp256k1CurveOrder, nonceanalysis.DefaultOptions())
+	return nil
+}
+
+// RedactKey shows a private key as its first and last two bytes, e.g. "0x1a2b…9f0e".
+func RedactKey(key *big.Int) string {
+	h := fmt.Sprintf("%064x", key)
+	return "0x" + h[:4] + "…" + h[len(h)-4:]
+}
+
+// section is one titled part of the report: label/value rows, then notes.
+type section struct {
+	Title string
+	Rows  [][2]string
+	Notes []string
+}
+
+// sections builds the report's content once for both renderings.
+func (r *Report) sections() []section {
+	var out []section
+
+	dataset := section{Title: "Dataset"}
+	dataset.Rows = append(dataset.Rows, [2]string{"Source", r.Dataset})
+	if r.DatasetSHA256 != "" {
+		dataset.Rows = append(dataset.Rows, [2]string{"SHA-256", r.DatasetSHA256})
+	}
+	if a := r.Analysis; a != nil {
+		dataset.Rows = append(dataset.Rows,
+			[2]string{"Signatures", fmt.Sprint(a.Signatures)},
+			[2]string{"Signers", fmt.Sprint(len(a.Signers))},
+			[2]string{"Duplicate r", fmt.Sprint(a.DuplicateR)},
+			[2]string{"Repeated messages", fmt.Sprint(a.RepeatedMessages)},
+			[2]string{"Probable nonce source", a.NonceSource})
+		if a.Timestamped > 0 {
+			dataset.Rows = append(dataset.Rows, [2]string{"Signed", fmt.Sprintf("%s to %s",
+				a.FirstSeen.Format(time.RFC3339), a.LastSeen.Format(time.RFC3339))})
+		}
+	}
+	out = append(out, dataset)
+
+	if a := r.Analysis; a != nil && len(a.Strategies) > 0 {
+		findings := section{Title: "Analysis findings"}
+		for _, s := range a.Strategies {
+			verdict := "skip"
+			if s.Worthwhile {
+				verdict = "run"
+			}
+			findings.Rows = append(findings.Rows, [2]string{s.Name, verdict + ": " + s.Reason})
+		}
+		if a.Unattributed > 0 {
+			findings.Notes = append(findings.Notes, fmt.Sprintf("%d signatures have no signer key and were searched as one more signer.", a.Unattributed))
+		}
+		out = append(out, findings)
+	}
+
+	result := section{Title: "Recovery result"}
+	if r.Result == nil {
+		outcome := "No key recovered"
+		if r.Err != nil {
+			outcome += ": " + r.Err.Error()
+		}
+		result.Rows = append(result.Rows, [2]string{"Outcome", outcome})
+	} else {
+		res := r.Result
+		key := RedactKey(res.PrivateKey)
+		if r.RevealKey {
+			key = fmt.Sprintf("0x%064x", res.PrivateKey)
+		}
+		publicKey := secp256k1.PrivKeyFromBytes(res.PrivateKey.Bytes()).PubKey().SerializeCompressed()
+		verified := "no (no public key to verify against)"
+		if res.Verified {
+			verified = "yes, against the public key"
+		}
+		result.Rows = append(result.Rows,
+			[2]string{"Outcome", "Private key recovered"},
+			[2]string{"Private key", key},
+			[2]string{"Public key", fmt.Sprintf("%x", publicKey)},
+			[2]string{"Verified", verified},
+			[2]string{"Relationship", fmt.Sprintf("k2 = %s*k1 + %s", res.Relationship.A, res.Relationship.B)},
+			[2]string{"Pattern", res.Pattern},
+			[2]string{"Signature pair", fmt.Sprintf("%d, %d", res.SignaturePair[0], res.SignaturePair[1])})
+	}
+	out = append(out, result)
+
+	if r.Result != nil && (r.Confidence != nil || r.Result.CrossChecked > 0) {
+		evidence := section{Title: "Corroborating evidence"}
+		if c := r.Confidence; c != nil {
+			evidence.Rows = append(evidence.Rows,
+				[2]string{"Confidence", fmt.Sprintf("%.2f", c.Score)},
+				[2]string{"Signatures explained", fmt.Sprintf("%d of %d checked", c.Explained, c.Checked)},
+				[2]string{"Nonce pairs following the pattern", fmt.Sprint(c.PatternPairs)})
+		}
+		if res := r.Result; res.CrossChecked > 0 {
+			evidence.Rows = append(evidence.Rows, [2]string{"Cross-check", fmt.Sprintf("%d of %d other signatures by the signer", res.Corroborating, res.CrossChecked)})
+		}
+		evidence.Notes = append(evidence.Notes, "A key explains a signature when the nonce it implies reproduces the signature's r.")
+		out = append(out, evidence)
+	}
+
+	if rel := r.Relationships; rel != nil {
+		relationships := section{Title: "Nonce relationships"}
+		relationships.Rows = append(relationships.Rows,
+			[2]string{"Generator pattern", rel.Pattern},
+			[2]string{"Sessions", fmt.Sprint(len(rel.Sessions))})
+		if rel.Step != nil {
+			relationships.Rows = append(relationships.Rows, [2]string{"Step", rel.Step.String()})
+		}
+		if rel.A != nil && rel.B != nil {
+			relationships.Rows = append(relationships.Rows, [2]string{"Recurrence", fmt.Sprintf("k[i+1] = %s*k[i] + %s", rel.A, rel.B)})
+		}
+		out = append(out, relationships)
+	}
+
+	if r.ToolVersion != "" || len(r.Config) > 0 {
+		tool := section{Title: "Tool configuration"}
+		if r.ToolVersion != "" {
+			tool.Rows = append(tool.Rows, [2]string{"Version", r.ToolVersion})
+		}
+		names := make([]string, 0, len(r.Config))
+		for name := range r.Config {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			tool.Rows = append(tool.Rows, [2]string{name, r.Config[name]})
+		}
+		out = append(out, tool)
+	}
+	return out
+}
+
+// diagramEdges returns the edges the relationship diagram draws: as in WriteDOT, only
+// consecutive links when there are many.
+func diagramEdges(rel *nonceanalysis.Report) []nonceanalysis.Edge {
+	var edges []nonceanalysis.Edge
+	for _, e := range rel.Edges {
+		if e.To != e.From+1 && len(rel.Edges) > 2*len(rel.Differences) {
+			continue
+		}
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+func (r *Report) title() string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return "Key recovery report"
+}
+
+func (r *Report) generated() time.Time {
+	if !r.Generated.IsZero() {
+		return r.Generated.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// WriteMarkdown renders the report as Markdown, with the relationship diagram as a
+// Mermaid graph.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\nGenerated %s\n", r.title(), r.generated().Format(time.RFC3339))
+	for _, s := range r.sections() {
+		fmt.Fprintf(&sb, "\n## %s\n\n| | |\n|---|---|\n", s.Title)
+		for _, row := range s.Rows {
+			fmt.Fprintf(&sb, "| %s | %s |\n", markdownCell(row[0]), markdownCell(row[1]))
+		}
+		for _, note := range s.Notes {
+			fmt.Fprintf(&sb, "\n%s\n", note)
+		}
+		if s.Title == "Nonce relationships" {
+			sb.WriteString("\n```mermaid\ngraph LR\n")
+			for _, e := range diagramEdges(r.Relationships) {
+				fmt.Fprintf(&sb, "  s%d[\"sig %d\"] -->|%+d| s%d[\"sig %d\"]\n", e.From, e.From, e.Difference, e.To, e.To)
+			}
+			sb.WriteString("```\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// WriteHTML renders the report as a self-contained HTML page, with the relationship
+// diagram as inline SVG. It prints cleanly, e.g. to PDF.
+func (r *Report) WriteHTML(w io.Writer) error {
+	data := struct {
+		Title     string
+		Generated string
+		Sections  []section
+		Diagram   template.HTML
+	}{r.title(), r.generated().Format(time.RFC3339), r.sections(), ""}
+	if r.Relationships != nil {
+		data.Diagram = template.HTML(diagramSVG(r.Relationships))
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// diagramSVG draws the signatures as a row of nodes with the small differences between
+// their nonces as labeled arcs above them.
+func diagramSVG(rel *nonceanalysis.Report) string {
+	const spacing, y = 70, 140
+	n := len(rel.Differences)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`, spacing*n+40, y+30)
+	for _, e := range diagramEdges(rel) {
+		x1, x2 := 40+spacing*e.From, 40+spacing*e.To
+		h := y - 20 - 12*(e.To-e.From)
+		if h < 20 {
+			h = 20
+		}
+		fmt.Fprintf(&sb, `<path d="M %d %d Q %d %d %d %d" fill="none" stroke="#c0392b"/>`, x1, y-12, (x1+x2)/2, h, x2, y-12)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle">%+d</text>`, (x1+x2)/2, (h+y-12)/2-2, e.Difference)
+	}
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, `<circle cx="%d" cy="%d" r="12" fill="#ecf0f1" stroke="#34495e"/><text x="%d" y="%d" text-anchor="middle">%d</text>`, 40+spacing*i, y, 40+spacing*i, y+4, i)
+	}
+	sb.WriteString("</svg>")
+	return sb.String()
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+td { border-bottom: 1px solid #ddd; padding: 0.3em 0.6em; vertical-align: top; }
+td:first-child { width: 30%; color: #555; }
+td:last-child { font-family: monospace; word-break: break-all; }
+section { page-break-inside: avoid; }
+.diagram { overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{.Generated}}</p>
+{{range .Sections}}<section>
+<h2>{{.Title}}</h2>
+<table>
+{{range .Rows}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{range .Notes}}<p>{{.}}</p>
+{{end}}{{if and (eq .Title "Nonce relationships") $.Diagram}}<div class="diagram">{{$.Diagram}}</div>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
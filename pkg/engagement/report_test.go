@@ -0,0 +1,120 @@
+package engagement
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+// counterReport returns a report of a key recovered from counter nonces.
+func counterReport(t *testing.T) (*Report, *big.Int) {
+	t.Helper()
+	key, _ := flawedsigner.NewECDSAKey(flawedsigner.NewSeededReader(1895))
+	signed, err := key.Sign(flawedsigner.ECDSAMessages(4), flawedsigner.AffineFrom(big.NewInt(1000), big.NewInt(1), big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signatures []*ecdsaaffine.Signature
+	for _, sig := range signed {
+		signatures = append(signatures, &ecdsaaffine.Signature{Z: sig.Z, R: sig.R, S: sig.S})
+	}
+	d, err := ecdsaaffine.RecoverPrivateKey(signatures[0], signatures[1], big.NewInt(1), big.NewInt(1))
+	if err != nil || d.Cmp(key.D) != 0 {
+		t.Fatalf("Recovered a different key (%v)", err)
+	}
+
+	report := &Report{
+		Generated:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Dataset:     "signatures.json",
+		ToolVersion: "v0.0.0-test",
+		Config:      map[string]string{"smart-brute": "true", "a-range": "-100,100"},
+		Analysis:    ecdsaaffine.AnalyzeDataset(signatures),
+		Result: &ecdsaaffine.RecoveryResult{
+			PrivateKey:    d,
+			Relationship:  ecdsaaffine.AffineRelationship{A: big.NewInt(1), B: big.NewInt(1)},
+			SignaturePair: [2]int{0, 1},
+			Pattern:       "counter_+1",
+		},
+	}
+	if err := report.AddEvidence(signatures); err != nil {
+		t.Fatal(err)
+	}
+	return report, d
+}
+
+func TestReport_WriteMarkdown(t *testing.T) {
+	report, d := counterReport(t)
+	var sb strings.Builder
+	if err := report.WriteMarkdown(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	full := fmt.Sprintf("%064x", d)
+	if strings.Contains(out, full) {
+		t.Error("The private key is printed in full")
+	}
+	for _, want := range []string{
+		"# Key recovery report",
+		"Generated 2026-01-02T03:04:05Z",
+		RedactKey(d),
+		"| Confidence | 1.00 |",
+		"| Signatures explained | 4 of 4 checked |",
+		"| Generator pattern | constant_step |",
+		"s0[\"sig 0\"] -->|+1| s1[\"sig 1\"]",
+		"| a-range | -100,100 |",
+		"| Version | v0.0.0-test |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Missing %q in:\n%s", want, out)
+		}
+	}
+
+	report.RevealKey = true
+	sb.Reset()
+	report.WriteMarkdown(&sb)
+	if !strings.Contains(sb.String(), full) {
+		t.Error("RevealKey does not print the private key")
+	}
+}
+
+func TestReport_WriteHTML(t *testing.T) {
+	report, d := counterReport(t)
+	report.Title = "Fleet <audit>"
+	var sb strings.Builder
+	if err := report.WriteHTML(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	if strings.Contains(out, "<audit>") || !strings.Contains(out, "Fleet &lt;audit&gt;") {
+		t.Error("The title is not escaped")
+	}
+	if !strings.Contains(out, "<svg") || strings.Count(out, "<circle") != 4 {
+		t.Error("Expected a relationship diagram with a node per signature")
+	}
+	if strings.Contains(out, fmt.Sprintf("%064x", d)) || !strings.Contains(out, RedactKey(d)) {
+		t.Error("The private key is not redacted")
+	}
+}
+
+func TestReport_NoKey(t *testing.T) {
+	report := &Report{Dataset: "signatures.json", Err: errors.New("failed to recover private key")}
+	if err := report.AddEvidence(nil); err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	report.WriteMarkdown(&sb)
+	out := sb.String()
+	if !strings.Contains(out, "No key recovered: failed to recover private key") {
+		t.Errorf("Expected the failure in the report:\n%s", out)
+	}
+	if strings.Contains(out, "Corroborating evidence") || strings.Contains(out, "Tool configuration") {
+		t.Errorf("Expected only the dataset and result sections:\n%s", out)
+	}
+}
@@ -0,0 +1,16 @@
+// Package flawedsigner simulates signers with broken nonce generation, so tests, demos and
+// benchmarks can create vulnerable ECDSA (secp256k1) and EdDSA (Ed25519) signature sets
+// without the Python scripts in scripts/.
+//
+// A Nonces value yields a flawed nonce sequence (same nonce, counter, hardcoded step,
+// affine a*k+b, 64-bit LCG, truncated random); ECDSAKey.Sign and EdDSAKey.Sign use it in
+// place of a secure nonce:
+//
+//	key, _ := flawedsigner.NewECDSAKey(rand.Reader)
+//	nonces, _ := flawedsigner.Affine(rand.Reader, big.NewInt(2), big.NewInt(1))
+//	signatures, _ := key.Sign(messages, nonces)
+//
+// WriteFixtures writes the fixture set the package tests use, in the same JSON formats
+// the Python generators produce. The package deliberately does not import the recovery
+// packages, so it can serve their tests.
+package flawedsigner
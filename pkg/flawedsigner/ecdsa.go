@@ -0,0 +1,104 @@
+package flawedsigner
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Secp256k1Order is the order of the secp256k1 group.
+var Secp256k1Order, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+
+// ECDSAKey is a secp256k1 signing key.
+type ECDSAKey struct {
+	D *big.Int // private key
+}
+
+// NewECDSAKey generates a key from r.
+func NewECDSAKey(r io.Reader) (*ECDSAKey, error) {
+	d, err := randomScalar(r, Secp256k1Order)
+	if err != nil {
+		return nil, err
+	}
+	return &ECDSAKey{D: d}, nil
+}
+
+// PublicKey returns the compressed public key (33 bytes).
+func (k *ECDSAKey) PublicKey() []byte {
+	return secp256k1.PrivKeyFromBytes(scalarBytes(k.D)).PubKey().SerializeCompressed()
+}
+
+// ECDSASignature is a signature with the message hash z, as in the JSON fixtures.
+type ECDSASignature struct {
+	Message    []byte
+	Z, R, S    *big.Int
+	NonceIndex int      // position in the signed sequence
+	Nonce      *big.Int // the nonce used; never written to JSON
+}
+
+// MarshalJSON writes the fixture format read by ecdsaaffine.JSONParser.
+func (s *ECDSASignature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Message    string   `json:"message"`
+		Z          *big.Int `json:"z"`
+		R          *big.Int `json:"r"`
+		S          *big.Int `json:"s"`
+		NonceIndex int      `json:"nonce_index"`
+	}{string(s.Message), s.Z, s.R, s.S, s.NonceIndex})
+}
+
+// HashMessage is SHA-256 of message as an integer mod the group order, as used for z.
+func HashMessage(message []byte) *big.Int {
+	h := sha256.Sum256(message)
+	z := new(big.Int).SetBytes(h[:])
+	return z.Mod(z, Secp256k1Order)
+}
+
+// Sign signs each message with the next nonce from nonces:
+// r = (k*G).x mod n, s = k^-1 * (z + r*d) mod n.
+func (k *ECDSAKey) Sign(messages [][]byte, nonces Nonces) ([]*ECDSASignature, error) {
+	n := Secp256k1Order
+	signatures := make([]*ECDSASignature, 0, len(messages))
+	for i, message := range messages {
+		nonce, err := nonces.Next(n)
+		if err != nil {
+			return nil, err
+		}
+		if nonce.Sign() == 0 {
+			return nil, fmt.Errorf("signature %d: zero nonce", i)
+		}
+
+		r := secp256k1.PrivKeyFromBytes(scalarBytes(nonce)).PubKey().X()
+		r.Mod(r, n)
+		if r.Sign() == 0 {
+			return nil, errors.New("degenerate nonce (r = 0)")
+		}
+		z := HashMessage(message)
+
+		s := new(big.Int).Mul(r, k.D)
+		s.Add(s, z)
+		s.Mul(s, new(big.Int).ModInverse(nonce, n))
+		s.Mod(s, n)
+
+		signatures = append(signatures, &ECDSASignature{
+			Message:    message,
+			Z:          z,
+			R:          r,
+			S:          s,
+			NonceIndex: i,
+			Nonce:      nonce,
+		})
+	}
+	return signatures, nil
+}
+
+// scalarBytes returns v as 32 big-endian bytes.
+func scalarBytes(v *big.Int) []byte {
+	b := make([]byte, 32)
+	return v.FillBytes(b)
+}
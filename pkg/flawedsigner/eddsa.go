@@ -0,0 +1,146 @@
+package flawedsigner
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"filippo.io/edwards25519"
+)
+
+// Ed25519Order is the order L of the Ed25519 prime-order subgroup.
+var Ed25519Order, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// EdDSAKey is an Ed25519 key: the RFC 8032 seed and the clamped signing scalar derived
+// from it.
+type EdDSAKey struct {
+	Seed   []byte   // 32-byte seed
+	Scalar *big.Int // signing scalar a (what key recovery returns)
+	Public []byte   // public key A = a*B (32 bytes)
+}
+
+// NewEdDSAKey generates a key from r.
+func NewEdDSAKey(r io.Reader) (*EdDSAKey, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return nil, err
+	}
+	return NewEdDSAKeyFromSeed(seed), nil
+}
+
+// NewEdDSAKeyFromSeed derives the signing scalar (SHA-512, clamp, reduce mod L) and public
+// key from a 32-byte seed.
+func NewEdDSAKeyFromSeed(seed []byte) *EdDSAKey {
+	h := sha512.Sum512(seed)
+	h[0] &= 0xf8
+	h[31] &= 0x7f
+	h[31] |= 0x40
+	scalar := new(big.Int).SetBytes(reverse(h[:32]))
+	scalar.Mod(scalar, Ed25519Order)
+
+	return &EdDSAKey{
+		Seed:   append([]byte(nil), seed...),
+		Scalar: scalar,
+		Public: ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey),
+	}
+}
+
+// EdDSASignature is a signature as stored in the JSON fixtures: R is the little-endian
+// integer of the encoded point R.
+type EdDSASignature struct {
+	Message   []byte
+	R, S      *big.Int
+	PublicKey []byte
+	Nonce     *big.Int // the nonce scalar used; nil for standard signatures, never written to JSON
+}
+
+// MarshalJSON writes the fixture format read by eddsaaffine.JSONParser.
+func (s *EdDSASignature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Message   string `json:"message"`
+		R         string `json:"r"`
+		S         string `json:"s"`
+		PublicKey string `json:"public_key"`
+	}{
+		"0x" + hex.EncodeToString(s.Message),
+		"0x" + s.R.Text(16),
+		"0x" + s.S.Text(16),
+		hex.EncodeToString(s.PublicKey),
+	})
+}
+
+// Sign signs each message with the next nonce r from nonces, used raw (never clamped):
+// R = r*B, S = r + H(R || A || M)*a mod L.
+func (k *EdDSAKey) Sign(messages [][]byte, nonces Nonces) ([]*EdDSASignature, error) {
+	signatures := make([]*EdDSASignature, 0, len(messages))
+	for _, message := range messages {
+		nonce, err := nonces.Next(Ed25519Order)
+		if err != nil {
+			return nil, err
+		}
+		R := edwards25519.NewIdentityPoint().ScalarBaseMult(scalar(nonce)).Bytes()
+		signature, err := k.sign(nonce, R, k.Public, message)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// sign computes S = nonce + H(R || A || M)*a mod L for the encoded R and public key A.
+func (k *EdDSAKey) sign(nonce *big.Int, R, public, message []byte) (*EdDSASignature, error) {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(public)
+	h.Write(message)
+	challenge, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	S := edwards25519.NewScalar().MultiplyAdd(challenge, scalar(k.Scalar), scalar(nonce))
+
+	return &EdDSASignature{
+		Message:   message,
+		R:         new(big.Int).SetBytes(reverse(R)),
+		S:         new(big.Int).SetBytes(reverse(S.Bytes())),
+		PublicKey: public,
+		Nonce:     nonce,
+	}, nil
+}
+
+// SignStandard signs with RFC 8032 Ed25519 (deterministic nonces), for fixtures that must
+// not be recoverable.
+func (k *EdDSAKey) SignStandard(messages [][]byte) []*EdDSASignature {
+	priv := ed25519.NewKeyFromSeed(k.Seed)
+	signatures := make([]*EdDSASignature, 0, len(messages))
+	for _, message := range messages {
+		sig := ed25519.Sign(priv, message)
+		signatures = append(signatures, &EdDSASignature{
+			Message:   message,
+			R:         new(big.Int).SetBytes(reverse(sig[:32])),
+			S:         new(big.Int).SetBytes(reverse(sig[32:])),
+			PublicKey: k.Public,
+		})
+	}
+	return signatures
+}
+
+// scalar converts v (reduced mod L) to an edwards25519 scalar.
+func scalar(v *big.Int) *edwards25519.Scalar {
+	le := reverse(new(big.Int).Mod(v, Ed25519Order).FillBytes(make([]byte, 32)))
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(le)
+	return s
+}
+
+// reverse returns b in reverse byte order (little-endian <-> big-endian).
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package flawedsigner
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"filippo.io/edwards25519"
+)
+
+// EdDSAEdgeCases lists the names accepted by EdDSAKey.SignEdgeCase: Ed25519 signatures
+// that deployed verifiers disagree on (RFC 8032 section 5.1.7 rejects them, lax or
+// cofactored verifiers accept them).
+//   - non_canonical_r: the first R is the identity encoded with y = p + 1 (nonce 0)
+//   - small_order_r: the first R is a point of order 8 (nonce 0 mod L)
+//   - torsion_r: every R is r*B plus a point of order 8
+//   - s_not_reduced: every S has L added, the malleated form of the signature
+//   - mixed_order_key: the public key is a*B plus a point of order 8
+var EdDSAEdgeCases = []string{"non_canonical_r", "small_order_r", "torsion_r", "s_not_reduced", "mixed_order_key"}
+
+// order8Point is a point of order 8, the torsion the edge cases add.
+func order8Point() *edwards25519.Point {
+	b, _ := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	p, err := edwards25519.NewIdentityPoint().SetBytes(b)
+	if err != nil {
+		panic("flawedsigner: invalid order-8 point")
+	}
+	return p
+}
+
+// nonCanonicalIdentity is the identity encoded with y = p + 1 = 2^255 - 18.
+func nonCanonicalIdentity() []byte {
+	b := make([]byte, 32)
+	for i := range b {
+		b[i] = 0xff
+	}
+	b[0], b[31] = 0xee, 0x7f
+	return b
+}
+
+// SignEdgeCase signs like Sign, with nonces from nonces, and then bends the set into the
+// named edge case (see EdDSAEdgeCases). Every signature still passes some verifier: the
+// cofactored equation [8][S]B = [8]R + [8][h]A, or the cofactorless one for
+// non_canonical_r and s_not_reduced. The Nonce field of a replaced R is 0.
+func (k *EdDSAKey) SignEdgeCase(kind string, messages [][]byte, nonces Nonces) ([]*EdDSASignature, error) {
+	public := k.Public
+	switch kind {
+	case "non_canonical_r", "small_order_r", "torsion_r", "s_not_reduced":
+	case "mixed_order_key":
+		A, err := edwards25519.NewIdentityPoint().SetBytes(k.Public)
+		if err != nil {
+			return nil, err
+		}
+		public = A.Add(A, order8Point()).Bytes()
+	default:
+		return nil, fmt.Errorf("flawedsigner: unknown edge case %q (expected one of %v)", kind, EdDSAEdgeCases)
+	}
+
+	signatures := make([]*EdDSASignature, 0, len(messages))
+	for i, message := range messages {
+		nonce, err := nonces.Next(Ed25519Order)
+		if err != nil {
+			return nil, err
+		}
+		point := edwards25519.NewIdentityPoint().ScalarBaseMult(scalar(nonce))
+		R := point.Bytes()
+		switch {
+		case kind == "non_canonical_r" && i == 0:
+			nonce, R = new(big.Int), nonCanonicalIdentity()
+		case kind == "small_order_r" && i == 0:
+			nonce, R = new(big.Int), order8Point().Bytes()
+		case kind == "torsion_r":
+			R = point.Add(point, order8Point()).Bytes()
+		}
+		signature, err := k.sign(nonce, R, public, message)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "s_not_reduced" {
+			signature.S.Add(signature.S, Ed25519Order)
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// WriteEdDSAEdgeCaseFixtures writes one counter-nonce set of five signatures per edge
+// case to dir, as test_eddsa_edge_<case>.json, all by one key described in
+// test_eddsa_edge_key_info.json. Recovery must reject each set rather than report a key.
+func WriteEdDSAEdgeCaseFixtures(dir string, r io.Reader) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	key, err := NewEdDSAKey(r)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(dir, "test_eddsa_edge_key_info.json", map[string]any{
+		"private_key":    key.Scalar,
+		"public_key_hex": hex.EncodeToString(key.Public),
+	}); err != nil {
+		return err
+	}
+	for _, kind := range EdDSAEdgeCases {
+		nonces, err := Counter(r)
+		if err != nil {
+			return err
+		}
+		signatures, err := key.SignEdgeCase(kind, EdDSAMessages(5), nonces)
+		if err != nil {
+			return err
+		}
+		if err := writeJSON(dir, "test_eddsa_edge_"+kind+".json", signatures); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package flawedsigner
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// NewSeededReader returns a deterministic random source, so a fixture set can be
+// regenerated byte for byte. Never use it for real keys.
+func NewSeededReader(seed int64) io.Reader {
+	return rand.New(rand.NewSource(seed))
+}
+
+// ECDSAMessages are the messages signed in the ECDSA fixtures.
+func ECDSAMessages(count int) [][]byte {
+	messages := make([][]byte, count)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("Transaction %d: Send %d ETH", i+1, i+1))
+	}
+	return messages
+}
+
+// EdDSAMessages are the messages signed in the EdDSA fixtures.
+func EdDSAMessages(count int) [][]byte {
+	messages := make([][]byte, count)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("Test message %d", i))
+	}
+	return messages
+}
+
+// fixtureSet names a fixture file and the flaw its nonces have.
+type fixtureSet struct {
+	file string
+	flaw Flaw
+}
+
+var ecdsaFixtures = []fixtureSet{
+	{"test_signatures_same_nonce.json", Flaw{Kind: "same_nonce"}},
+	{"test_signatures_counter.json", Flaw{Kind: "counter"}},
+	{"test_signatures_hardcoded_step.json", Flaw{Kind: "hardcoded_step", Step: 12345}},
+	{"test_signatures_affine.json", Flaw{Kind: "affine", A: 2, B: 1}},
+	{"test_signatures_affine_3x_plus_5.json", Flaw{Kind: "affine", A: 3, B: 5}},
+	{"test_signatures_lcg.json", Flaw{Kind: "lcg"}},
+	{"test_signatures_truncated.json", Flaw{Kind: "truncated", Bits: 64}},
+}
+
+var eddsaFixtures = []fixtureSet{
+	{"test_eddsa_signatures_same_nonce.json", Flaw{Kind: "same_nonce"}},
+	{"test_eddsa_signatures_counter.json", Flaw{Kind: "counter"}},
+	{"test_eddsa_signatures_hardcoded_step.json", Flaw{Kind: "hardcoded_step", Step: 13511}},
+	{"test_eddsa_signatures_affine.json", Flaw{Kind: "affine", A: 2, B: 1}},
+	{"test_eddsa_signatures_lcg.json", Flaw{Kind: "lcg"}},
+	{"test_eddsa_signatures_truncated.json", Flaw{Kind: "truncated", Bits: 64}},
+}
+
+// WriteFixtures writes the fixture set to dir: the files the Python generators produce
+// (test_key_info.json, test_signatures_*.json, test_eddsa_*.json) plus LCG and truncated
+// nonce sets. Each curve uses one key for all its sets, and each set has five signatures.
+// With a reader from NewSeededReader the output is identical on every run.
+func WriteFixtures(dir string, r io.Reader) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	ecdsaKey, err := NewECDSAKey(r)
+	if err != nil {
+		return err
+	}
+	pub := hex.EncodeToString(ecdsaKey.PublicKey())
+	if err := writeJSON(dir, "test_key_info.json", map[string]any{
+		"private_key":           ecdsaKey.D,
+		"public_key_hex":        pub,
+		"public_key_compressed": pub,
+	}); err != nil {
+		return err
+	}
+	for _, set := range ecdsaFixtures {
+		nonces, err := set.flaw.Nonces(r)
+		if err != nil {
+			return err
+		}
+		signatures, err := ecdsaKey.Sign(ECDSAMessages(5), nonces)
+		if err != nil {
+			return fmt.Errorf("%s: %w", set.file, err)
+		}
+		if err := writeJSON(dir, set.file, signatures); err != nil {
+			return err
+		}
+	}
+
+	eddsaKey, err := NewEdDSAKey(r)
+	if err != nil {
+		return err
+	}
+	// As in the Python generator, private_key is the seed read as a little-endian integer
+	if err := writeJSON(dir, "test_eddsa_key_info.json", map[string]any{
+		"private_key":    new(big.Int).SetBytes(reverse(eddsaKey.Seed)),
+		"public_key_hex": hex.EncodeToString(eddsaKey.Public),
+		"public_key":     hex.EncodeToString(eddsaKey.Public),
+	}); err != nil {
+		return err
+	}
+	for _, set := range eddsaFixtures {
+		nonces, err := set.flaw.Nonces(r)
+		if err != nil {
+			return err
+		}
+		signatures, err := eddsaKey.Sign(EdDSAMessages(5), nonces)
+		if err != nil {
+			return fmt.Errorf("%s: %w", set.file, err)
+		}
+		if err := writeJSON(dir, set.file, signatures); err != nil {
+			return err
+		}
+	}
+	return writeJSON(dir, "test_eddsa_signatures_standard.json", eddsaKey.SignStandard(EdDSAMessages(5)))
+}
+
+// writeJSON writes v to dir/name through a temporary file, so concurrent writers of the
+// same fixture never leave a partial file behind.
+func writeJSON(dir, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
@@ -0,0 +1,170 @@
+package flawedsigner
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+func TestFlaw_Nonces(t *testing.T) {
+	n := Secp256k1Order
+	for _, tt := range []struct {
+		flaw Flaw
+		a, b int64
+	}{
+		{Flaw{Kind: "same_nonce"}, 1, 0},
+		{Flaw{Kind: "counter"}, 1, 1},
+		{Flaw{Kind: "hardcoded_step", Step: 12345}, 1, 12345},
+		{Flaw{Kind: "affine", A: 3, B: -5}, 3, -5},
+	} {
+		nonces, err := tt.flaw.Nonces(NewSeededReader(1))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.flaw.Kind, err)
+		}
+		prev, _ := nonces.Next(n)
+		for i := 0; i < 4; i++ {
+			k, _ := nonces.Next(n)
+			want := new(big.Int).Mul(prev, big.NewInt(tt.a))
+			want.Add(want, big.NewInt(tt.b))
+			want.Mod(want, n)
+			if k.Cmp(want) != 0 {
+				t.Errorf("%s: nonce %d is not %d*k + %d", tt.flaw.Kind, i+1, tt.a, tt.b)
+			}
+			prev = k
+		}
+	}
+
+	wrapping := AffineFrom(new(big.Int).Sub(n, big.NewInt(1)), big.NewInt(2), big.NewInt(5))
+	if k, _ := wrapping.Next(n); k.Cmp(new(big.Int).Sub(n, big.NewInt(1))) != 0 {
+		t.Errorf("AffineFrom: first nonce %s, expected n-1", k)
+	}
+	if k, _ := wrapping.Next(n); k.Int64() != 3 {
+		t.Errorf("AffineFrom: 2*(n-1) + 5 should wrap to 3, got %s", k)
+	}
+
+	truncated, _ := Flaw{Kind: "truncated", Bits: 32}.Nonces(NewSeededReader(1))
+	for i := 0; i < 10; i++ {
+		if k, _ := truncated.Next(n); k.BitLen() > 32 || k.Sign() <= 0 {
+			t.Errorf("Truncated nonce %s out of range", k)
+		}
+	}
+	if _, err := (Flaw{Kind: "bogus"}).Nonces(NewSeededReader(1)); err == nil {
+		t.Error("Expected an error for an unknown flaw")
+	}
+}
+
+func TestECDSAKey_Sign(t *testing.T) {
+	key, _ := NewECDSAKey(NewSeededReader(1))
+	nonces, _ := Counter(NewSeededReader(2))
+	signatures, err := key.Sign(ECDSAMessages(3), nonces)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	pub, err := secp256k1.ParsePubKey(key.PublicKey())
+	if err != nil {
+		t.Fatalf("Invalid public key: %v", err)
+	}
+	for i, sig := range signatures {
+		var r, s secp256k1.ModNScalar
+		r.SetByteSlice(sig.R.Bytes())
+		s.SetByteSlice(sig.S.Bytes())
+		if !ecdsa.NewSignature(&r, &s).Verify(scalarBytes(sig.Z), pub) {
+			t.Errorf("Signature %d does not verify", i)
+		}
+	}
+	if new(big.Int).Sub(signatures[1].Nonce, signatures[0].Nonce).Int64() != 1 {
+		t.Error("Expected counter nonces")
+	}
+}
+
+func TestEdDSAKey_Sign(t *testing.T) {
+	key, _ := NewEdDSAKey(NewSeededReader(1))
+	nonces, _ := HardcodedStep(NewSeededReader(2), 13511)
+	signatures, err := key.Sign(EdDSAMessages(3), nonces)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	signatures = append(signatures, key.SignStandard(EdDSAMessages(2))...)
+	for i, sig := range signatures {
+		encoded := append(reverse(sig.R.FillBytes(make([]byte, 32))), reverse(sig.S.FillBytes(make([]byte, 32)))...)
+		if !ed25519.Verify(key.Public, sig.Message, encoded) {
+			t.Errorf("Signature %d does not verify", i)
+		}
+	}
+}
+
+func TestEdDSAKey_SignEdgeCase(t *testing.T) {
+	key, _ := NewEdDSAKey(NewSeededReader(1))
+	for _, kind := range EdDSAEdgeCases {
+		nonces, _ := Counter(NewSeededReader(2))
+		signatures, err := key.SignEdgeCase(kind, EdDSAMessages(3), nonces)
+		if err != nil {
+			t.Fatalf("%s: SignEdgeCase failed: %v", kind, err)
+		}
+		for i, sig := range signatures {
+			// Every edge case passes the cofactored equation [8][S]B = [8]R + [8][h]A
+			R, errR := edwards25519.NewIdentityPoint().SetBytes(reverse(sig.R.FillBytes(make([]byte, 32))))
+			A, errA := edwards25519.NewIdentityPoint().SetBytes(sig.PublicKey)
+			if errR != nil || errA != nil {
+				t.Fatalf("%s: signature %d does not decode: %v, %v", kind, i, errR, errA)
+			}
+			digest := sha512.Sum512(append(append(reverse(sig.R.FillBytes(make([]byte, 32))), sig.PublicKey...), sig.Message...))
+			h, _ := edwards25519.NewScalar().SetUniformBytes(digest[:])
+			lhs := edwards25519.NewIdentityPoint().ScalarBaseMult(scalar(sig.S))
+			rhs := edwards25519.NewIdentityPoint().ScalarMult(h, A)
+			rhs.Add(rhs, R)
+			if lhs.MultByCofactor(lhs).Equal(rhs.MultByCofactor(rhs)) != 1 {
+				t.Errorf("%s: signature %d fails cofactored verification", kind, i)
+			}
+		}
+
+		first := signatures[0]
+		switch kind {
+		case "non_canonical_r":
+			if !bytes.Equal(reverse(first.R.Bytes()), nonCanonicalIdentity()) {
+				t.Errorf("non_canonical_r: first R is %x", first.R)
+			}
+		case "s_not_reduced":
+			if first.S.Cmp(Ed25519Order) < 0 {
+				t.Errorf("s_not_reduced: S = %x is below L", first.S)
+			}
+		case "mixed_order_key":
+			if bytes.Equal(first.PublicKey, key.Public) {
+				t.Errorf("mixed_order_key: public key unchanged")
+			}
+		}
+	}
+	if _, err := key.SignEdgeCase("bogus", EdDSAMessages(1), Random(NewSeededReader(3))); err == nil {
+		t.Error("Expected an error for an unknown edge case")
+	}
+}
+
+func TestWriteFixtures_Deterministic(t *testing.T) {
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	if err := WriteFixtures(dir1, NewSeededReader(7)); err != nil {
+		t.Fatalf("WriteFixtures failed: %v", err)
+	}
+	if err := WriteFixtures(dir2, NewSeededReader(7)); err != nil {
+		t.Fatalf("WriteFixtures failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir1, "*.json"))
+	if len(files) != 2+len(ecdsaFixtures)+len(eddsaFixtures)+1 {
+		t.Errorf("Expected %d fixture files, got %d", 2+len(ecdsaFixtures)+len(eddsaFixtures)+1, len(files))
+	}
+	for _, f := range files {
+		a, _ := os.ReadFile(f)
+		b, _ := os.ReadFile(filepath.Join(dir2, filepath.Base(f)))
+		if !bytes.Equal(a, b) {
+			t.Errorf("%s differs between runs with the same seed", filepath.Base(f))
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package flawedsigner
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Nonces yields the nonce sequence of a flawed signer. Next returns the nonce for the
+// next signature, reduced mod order (the curve order of the signing key).
+type Nonces interface {
+	Next(order *big.Int) (*big.Int, error)
+}
+
+// randomScalar returns a value in [1, order) read from r. It reduces 512 random bits
+// rather than using crypto/rand.Int, so a seeded reader always yields the same fixtures.
+func randomScalar(r io.Reader, order *big.Int) (*big.Int, error) {
+	var buf [64]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(buf[:])
+	k.Mod(k, new(big.Int).Sub(order, big.NewInt(1)))
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// affineNonces implements k_{i+1} = a*k_i + b mod order, starting from start, or from a
+// random k_0 if start is nil.
+type affineNonces struct {
+	rand    io.Reader
+	a, b    *big.Int
+	start   *big.Int
+	current *big.Int
+}
+
+func (n *affineNonces) Next(order *big.Int) (*big.Int, error) {
+	if n.current == nil {
+		k := n.start
+		if k == nil {
+			var err error
+			if k, err = randomScalar(n.rand, order); err != nil {
+				return nil, err
+			}
+		}
+		n.current = new(big.Int).Mod(k, order)
+		return new(big.Int).Set(n.current), nil
+	}
+	n.current.Mul(n.current, n.a)
+	n.current.Add(n.current, n.b)
+	n.current.Mod(n.current, order)
+	return new(big.Int).Set(n.current), nil
+}
+
+// Affine returns nonces with k_{i+1} = a*k_i + b, from a start nonce read from r.
+func Affine(r io.Reader, a, b *big.Int) (Nonces, error) {
+	if r == nil {
+		return nil, fmt.Errorf("flawedsigner: nil random source")
+	}
+	return &affineNonces{rand: r, a: new(big.Int).Set(a), b: new(big.Int).Set(b)}, nil
+}
+
+// AffineFrom returns nonces with k_{i+1} = a*k_i + b starting from k_0 = start, for
+// placing nonces at chosen values such as just below the group order.
+func AffineFrom(start, a, b *big.Int) Nonces {
+	return &affineNonces{a: new(big.Int).Set(a), b: new(big.Int).Set(b), start: new(big.Int).Set(start)}
+}
+
+// SameNonce returns a single random nonce reused for every signature (a=1, b=0).
+func SameNonce(r io.Reader) (Nonces, error) {
+	return Affine(r, big.NewInt(1), big.NewInt(0))
+}
+
+// Counter returns nonces k_i = k_0 + i.
+func Counter(r io.Reader) (Nonces, error) {
+	return HardcodedStep(r, 1)
+}
+
+// HardcodedStep returns nonces k_i = k_0 + i*step.
+func HardcodedStep(r io.Reader, step int64) (Nonces, error) {
+	return Affine(r, big.NewInt(1), big.NewInt(step))
+}
+
+// lcgNonces is a 64-bit linear congruential generator whose state is used as the nonce,
+// as in signers that draw nonces from a non-cryptographic PRNG.
+type lcgNonces struct {
+	state, multiplier, increment uint64
+}
+
+func (n *lcgNonces) Next(order *big.Int) (*big.Int, error) {
+	n.state = n.state*n.multiplier + n.increment
+	k := new(big.Int).SetUint64(n.state)
+	return k.Mod(k, order), nil
+}
+
+// LCG returns nonces from the 64-bit LCG state_{i+1} = multiplier*state_i + increment
+// mod 2^64, seeded from r. Multiplier and increment of 0 select Knuth's MMIX constants.
+//
+// Consecutive nonces are affine over the integers only up to the 2^64 wrap, so this flaw
+// is recovered through the PRNG (pkg/prngrecovery) rather than a small (a, b) search.
+func LCG(r io.Reader, multiplier, increment uint64) (Nonces, error) {
+	if multiplier == 0 && increment == 0 {
+		multiplier, increment = 6364136223846793005, 1442695040888963407
+	}
+	var seed [8]byte
+	if _, err := io.ReadFull(r, seed[:]); err != nil {
+		return nil, err
+	}
+	state := new(big.Int).SetBytes(seed[:]).Uint64()
+	return &lcgNonces{state: state, multiplier: multiplier, increment: increment}, nil
+}
+
+// truncatedNonces draws random nonces with only bits bits of entropy.
+type truncatedNonces struct {
+	rand io.Reader
+	max  *big.Int
+}
+
+func (n *truncatedNonces) Next(order *big.Int) (*big.Int, error) {
+	max := n.max
+	if max.Cmp(order) > 0 {
+		max = order
+	}
+	return randomScalar(n.rand, max)
+}
+
+// Truncated returns random nonces below 2^bits, as produced by signers that fill only part
+// of the nonce (for example a 64-bit random value zero-extended to 256 bits).
+func Truncated(r io.Reader, bits int) (Nonces, error) {
+	if bits < 2 {
+		return nil, fmt.Errorf("flawedsigner: truncated nonces need at least 2 bits, got %d", bits)
+	}
+	return &truncatedNonces{rand: r, max: new(big.Int).Lsh(big.NewInt(1), uint(bits))}, nil
+}
+
+// Random returns independent uniform nonces: a correct signer, useful as a negative
+// control in tests.
+func Random(r io.Reader) Nonces {
+	return &truncatedNonces{rand: r, max: new(big.Int).Lsh(big.NewInt(1), 512)}
+}
+
+// Flaw selects a nonce flaw by name, as in the generate-fixtures command.
+type Flaw struct {
+	Kind string // same_nonce, counter, hardcoded_step, affine, lcg, truncated or random
+	A, B int64  // affine: k_{i+1} = A*k_i + B
+	Step int64  // hardcoded_step
+	Bits int    // truncated
+}
+
+// FlawKinds lists the names accepted in Flaw.Kind.
+var FlawKinds = []string{"same_nonce", "counter", "hardcoded_step", "affine", "lcg", "truncated", "random"}
+
+// Nonces returns the nonce sequence for the flaw, drawing randomness from r.
+func (f Flaw) Nonces(r io.Reader) (Nonces, error) {
+	switch f.Kind {
+	case "same_nonce":
+		return SameNonce(r)
+	case "counter":
+		return Counter(r)
+	case "hardcoded_step":
+		return HardcodedStep(r, f.Step)
+	case "affine":
+		return Affine(r, big.NewInt(f.A), big.NewInt(f.B))
+	case "lcg":
+		return LCG(r, 0, 0)
+	case "truncated":
+		return Truncated(r, f.Bits)
+	case "random":
+		return Random(r), nil
+	default:
+		return nil, fmt.Errorf("flawedsigner: unknown flaw %q (expected one of %v)", f.Kind, FlawKinds)
+	}
+}
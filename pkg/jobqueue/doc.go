@@ -0,0 +1,24 @@
+// Package jobqueue runs key recovery requests as background jobs and serves them over a
+// small REST API, for the daemon mode of the CLI (recovery serve).
+//
+// A Queue runs each submitted request with its RunFunc on a fixed number of workers; a
+// job is queued, running, then done, failed or cancelled. Jobs have priorities: an
+// urgent job (an active incident) preempts a background campaign of lower priority,
+// which is paused in place, keeping its progress, and resumes once a worker is free.
+// Pausing is cooperative (see Pauses). A queued or running job also takes updates, such
+// as patterns to add to its search, which it reads as it runs (see Updates). Jobs and their results live in memory: the
+// oldest finished jobs are dropped beyond Options.Keep.
+//
+//	q := jobqueue.New(run, jobqueue.Options{Workers: 2})
+//	http.Handle("/v1/", jobqueue.NewHandler(q))
+//	...
+//	q.Shutdown(ctx) // stop accepting, let running jobs finish until ctx is done
+//
+// The API:
+//
+//	POST   /v1/jobs               submit the request body (?priority=n, default 0); 202 with the job
+//	GET    /v1/jobs               list the jobs, oldest first, without their results
+//	GET    /v1/jobs/{id}          one job, with its result once finished
+//	DELETE /v1/jobs/{id}          cancel a queued or running job
+//	POST   /v1/jobs/{id}/updates  send the request body to an unfinished job as an update; 202
+package jobqueue
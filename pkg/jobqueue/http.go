@@ -0,0 +1,121 @@
+package jobqueue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MaxRequestBytes bounds the body of a submitted job.
+const MaxRequestBytes = 64 << 20
+
+// NewHandler returns the REST API of q (see the package documentation), to be mounted
+// at /v1/.
+func NewHandler(q *Queue) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, hasID := strings.CutPrefix(r.URL.Path, "/v1/jobs/")
+		if jobID, ok := strings.CutSuffix(id, "/updates"); hasID && ok && jobID != "" && !strings.Contains(jobID, "/") {
+			if r.Method != http.MethodPost {
+				writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+				return
+			}
+			update(q, jobID, w, r)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v1/jobs" && r.Method == http.MethodPost:
+			submit(q, w, r)
+		case r.URL.Path == "/v1/jobs" && r.Method == http.MethodGet:
+			// Results can hold recovered private keys; the listing leaves them out
+			jobs := q.List()
+			for k := range jobs {
+				jobs[k].Result = nil
+			}
+			writeJSON(w, http.StatusOK, jobs)
+		case hasID && id != "" && r.Method == http.MethodGet:
+			if job, ok := q.Get(id); ok {
+				writeJSON(w, http.StatusOK, job)
+			} else {
+				writeError(w, http.StatusNotFound, ErrNotFound)
+			}
+		case hasID && id != "" && r.Method == http.MethodDelete:
+			if job, err := q.Cancel(id); err == nil {
+				writeJSON(w, http.StatusOK, job)
+			} else {
+				writeError(w, http.StatusNotFound, err)
+			}
+		case r.URL.Path == "/v1/jobs" || hasID:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func submit(q *Queue, w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if !json.Valid(body) {
+		writeError(w, http.StatusBadRequest, errors.New("request body is not valid JSON"))
+		return
+	}
+	priority := 0
+	if v := r.URL.Query().Get("priority"); v != "" {
+		if priority, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid priority: %w", err))
+			return
+		}
+	}
+	job, err := q.Submit(body, priority)
+	switch {
+	case errors.Is(err, ErrFull):
+		writeError(w, http.StatusTooManyRequests, err)
+	case err != nil:
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		w.Header().Set("Location", "/v1/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func update(q *Queue, id string, w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if !json.Valid(body) {
+		writeError(w, http.StatusBadRequest, errors.New("update body is not valid JSON"))
+		return
+	}
+	job, err := q.Update(id, body)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrFinished):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrBusy):
+		writeError(w, http.StatusTooManyRequests, err)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,73 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	q := New(blockingRun(nil), Options{})
+	defer q.Shutdown(context.Background())
+	server := httptest.NewServer(NewHandler(q))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/v1/jobs", "application/json", strings.NewReader(`{"curve":"ecdsa"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job Job
+	json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "/v1/jobs/"+job.ID {
+		t.Fatalf("Unexpected submit response %d %+v", resp.StatusCode, job)
+	}
+	waitFor(t, q, job.ID, StatusDone)
+
+	resp, _ = http.Get(server.URL + "/v1/jobs/" + job.ID)
+	json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if job.Status != StatusDone || string(job.Result) != `{"curve":"ecdsa"}` {
+		t.Errorf("Unexpected job %+v", job)
+	}
+
+	// The listing leaves results, which can hold private keys, to GET /v1/jobs/{id}
+	resp, _ = http.Get(server.URL + "/v1/jobs")
+	var jobs []Job
+	json.NewDecoder(resp.Body).Decode(&jobs)
+	resp.Body.Close()
+	if len(jobs) != 1 || jobs[0].ID != job.ID || jobs[0].Status != StatusDone || jobs[0].Result != nil {
+		t.Errorf("Unexpected listing %+v", jobs)
+	}
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/v1/jobs", "not json", http.StatusBadRequest},
+		{http.MethodGet, "/v1/jobs", "", http.StatusOK},
+		{http.MethodGet, "/v1/jobs/missing", "", http.StatusNotFound},
+		{http.MethodDelete, "/v1/jobs/missing", "", http.StatusNotFound},
+		{http.MethodDelete, "/v1/jobs/" + job.ID, "", http.StatusOK},
+		{http.MethodPut, "/v1/jobs", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/v1/other", "", http.StatusNotFound},
+		{http.MethodPost, "/v1/jobs/" + job.ID + "/updates", "{}", http.StatusConflict},
+		{http.MethodPost, "/v1/jobs/missing/updates", "{}", http.StatusNotFound},
+		{http.MethodPost, "/v1/jobs/missing/updates", "not json", http.StatusBadRequest},
+		{http.MethodGet, "/v1/jobs/" + job.ID + "/updates", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
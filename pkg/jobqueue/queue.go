@@ -0,0 +1,384 @@
+package jobqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Job states.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusPaused    = "paused" // preempted by a job of higher priority
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Errors returned by Submit, Cancel and Update.
+var (
+	ErrClosed   = errors.New("queue is shutting down")
+	ErrFull     = errors.New("queue is full")
+	ErrNotFound = errors.New("no such job")
+	ErrFinished = errors.New("job has finished")
+	ErrBusy     = errors.New("job has too many pending updates")
+)
+
+// maxPendingUpdates is the number of updates a job holds before it reads them.
+const maxPendingUpdates = 16
+
+// RunFunc runs one request and returns its result, which must be JSON. It must return
+// when ctx is cancelled, and should follow Pauses(ctx). A result returned with an error
+// (a partial result) is kept on the failed job.
+type RunFunc func(ctx context.Context, request []byte) (json.RawMessage, error)
+
+// Options configures a Queue. Zero fields take the defaults.
+type Options struct {
+	Workers  int // jobs run at once (default 1)
+	Capacity int // jobs waiting to run before Submit returns ErrFull (default 100)
+	Keep     int // finished jobs kept for GET (default 1000)
+
+	// CheckUpdate, if set, rejects updates the jobs could not use before they are queued
+	CheckUpdate func(update []byte) error
+}
+
+// Job is a snapshot of a job.
+type Job struct {
+	ID        string          `json:"id"`
+	Status    string          `json:"status"`
+	Priority  int             `json:"priority"`
+	Submitted time.Time       `json:"submitted"`
+	Started   *time.Time      `json:"started,omitempty"`
+	Finished  *time.Time      `json:"finished,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// job is a job as the queue tracks it.
+type job struct {
+	Job
+	request []byte
+	cancel  context.CancelFunc // set once started
+	pauses  chan bool          // the latest pause state, for Pauses
+	updates chan []byte        // updates not yet read, for Updates
+}
+
+type (
+	pausesKey  struct{}
+	updatesKey struct{}
+)
+
+// Pauses returns the channel on which the queue tells the job run with ctx to pause
+// (true) or resume (false), or nil outside a job. Only the latest state is kept, so a
+// job reading it late sees where it should be now. A job that never pauses keeps
+// running after it is preempted, alongside the job that preempted it.
+func Pauses(ctx context.Context) <-chan bool {
+	pauses, _ := ctx.Value(pausesKey{}).(chan bool)
+	return pauses
+}
+
+// Updates returns the channel on which the job run with ctx receives the updates sent
+// with Update, in order, or nil outside a job. Updates sent before the job started are
+// waiting on it.
+func Updates(ctx context.Context) <-chan []byte {
+	updates, _ := ctx.Value(updatesKey{}).(chan []byte)
+	return updates
+}
+
+// Queue runs jobs in the background, highest priority first and in submission order
+// within a priority. When all workers are busy, a job of higher priority than a running
+// one preempts it: the lowest-priority running job (the last started, on a tie) is
+// paused and gives up its worker, and it resumes, ahead of queued jobs of the same
+// priority, once a worker is free. All methods are safe for concurrent use.
+type Queue struct {
+	run  RunFunc
+	opts Options
+
+	mu       sync.Mutex
+	jobs     map[string]*job
+	order    []string // job IDs, oldest first
+	queued   int
+	running  int
+	finished int
+	closed   bool
+
+	wg sync.WaitGroup
+}
+
+// New returns a queue running requests with run.
+func New(run RunFunc, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Capacity <= 0 {
+		opts.Capacity = 100
+	}
+	if opts.Keep <= 0 {
+		opts.Keep = 1000
+	}
+	return &Queue{run: run, opts: opts, jobs: make(map[string]*job)}
+}
+
+// Submit queues a request with the given priority (higher runs first; 0 is normal). It
+// returns ErrFull when Capacity jobs are already waiting and ErrClosed once Shutdown was
+// called.
+func (q *Queue) Submit(request []byte, priority int) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return Job{}, ErrClosed
+	}
+	if q.queued >= q.opts.Capacity {
+		return Job{}, ErrFull
+	}
+	j := &job{
+		Job:     Job{ID: newID(), Status: StatusQueued, Priority: priority, Submitted: time.Now().UTC()},
+		request: request,
+		pauses:  make(chan bool, 1),
+		updates: make(chan []byte, maxPendingUpdates),
+	}
+	q.jobs[j.ID] = j
+	q.order = append(q.order, j.ID)
+	q.queued++
+	q.schedule()
+	return j.Job, nil
+}
+
+// Get returns the job with the given ID.
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return j.Job, true
+}
+
+// List returns all jobs, oldest first.
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, 0, len(q.order))
+	for _, id := range q.order {
+		jobs = append(jobs, q.jobs[id].Job)
+	}
+	return jobs
+}
+
+// Cancel cancels a queued, running or paused job; a finished job is left as it is.
+func (q *Queue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	switch j.Status {
+	case StatusQueued:
+		q.queued--
+		q.finish(j, StatusCancelled, nil, context.Canceled.Error())
+	case StatusRunning, StatusPaused:
+		j.cancel()
+	}
+	return j.Job, nil
+}
+
+// Update sends an update, such as patterns to add to a running search, to a queued,
+// running or paused job (see Updates). It returns ErrFinished for a finished job,
+// ErrBusy when the job has not read its last updates yet and the error of
+// Options.CheckUpdate for an update it rejects.
+func (q *Queue) Update(id string, update []byte) (Job, error) {
+	if q.opts.CheckUpdate != nil {
+		if err := q.opts.CheckUpdate(update); err != nil {
+			return Job{}, err
+		}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if j.Finished != nil {
+		return j.Job, ErrFinished
+	}
+	select {
+	case j.updates <- update:
+		return j.Job, nil
+	default:
+		return j.Job, ErrBusy
+	}
+}
+
+// Accepting reports whether Submit accepts jobs, i.e. Shutdown has not been called.
+func (q *Queue) Accepting() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return !q.closed
+}
+
+// Shutdown stops accepting jobs, cancels the queued ones and waits for the running and
+// paused ones to finish. When ctx is done first, it cancels them, waits for them to
+// return and returns ctx.Err().
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	for _, id := range append([]string(nil), q.order...) {
+		if j := q.jobs[id]; j != nil && j.Status == StatusQueued {
+			q.queued--
+			q.finish(j, StatusCancelled, nil, ErrClosed.Error())
+		}
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	for _, j := range q.jobs {
+		if j.Status == StatusRunning || j.Status == StatusPaused {
+			j.cancel()
+		}
+	}
+	q.mu.Unlock()
+	<-done
+	return ctx.Err()
+}
+
+// schedule starts or resumes the best waiting jobs on the free workers, and preempts
+// running jobs of lower priority than the best waiting one. The caller holds q.mu.
+func (q *Queue) schedule() {
+	for {
+		next := q.best()
+		if next == nil {
+			return
+		}
+		if q.running < q.opts.Workers {
+			q.start(next)
+			continue
+		}
+		victim := q.lowestRunning()
+		if victim == nil || victim.Priority >= next.Priority {
+			return
+		}
+		victim.Status = StatusPaused
+		q.running--
+		setPaused(victim, true)
+	}
+}
+
+// best returns the waiting job to run next: highest priority, paused before queued,
+// then oldest.
+func (q *Queue) best() *job {
+	var best *job
+	for _, id := range q.order {
+		j := q.jobs[id]
+		if j.Status != StatusQueued && j.Status != StatusPaused {
+			continue
+		}
+		if best == nil || j.Priority > best.Priority || (j.Priority == best.Priority && j.Status == StatusPaused && best.Status == StatusQueued) {
+			best = j
+		}
+	}
+	return best
+}
+
+// lowestRunning returns the running job to preempt first: lowest priority, then the
+// last started.
+func (q *Queue) lowestRunning() *job {
+	var lowest *job
+	for _, id := range q.order {
+		j := q.jobs[id]
+		if j.Status != StatusRunning {
+			continue
+		}
+		if lowest == nil || j.Priority < lowest.Priority || (j.Priority == lowest.Priority && !j.Started.Before(*lowest.Started)) {
+			lowest = j
+		}
+	}
+	return lowest
+}
+
+// start runs a queued job, or resumes a paused one. The caller holds q.mu.
+func (q *Queue) start(j *job) {
+	q.running++
+	if j.Status == StatusPaused {
+		j.Status = StatusRunning
+		setPaused(j, false)
+		return
+	}
+	q.queued--
+	ctx := context.WithValue(context.Background(), pausesKey{}, j.pauses)
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, updatesKey{}, j.updates))
+	started := time.Now().UTC()
+	j.Status, j.Started, j.cancel = StatusRunning, &started, cancel
+	q.wg.Add(1)
+	go q.execute(ctx, j)
+}
+
+func (q *Queue) execute(ctx context.Context, j *job) {
+	defer q.wg.Done()
+	result, err := q.run(ctx, j.request)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.Status == StatusRunning {
+		q.running--
+	}
+	switch {
+	case ctx.Err() != nil:
+		q.finish(j, StatusCancelled, nil, context.Canceled.Error())
+	case err != nil:
+		q.finish(j, StatusFailed, result, err.Error())
+	default:
+		q.finish(j, StatusDone, result, "")
+	}
+	j.cancel()
+	q.schedule()
+}
+
+// setPaused replaces the pause state waiting on j's channel with paused.
+func setPaused(j *job, paused bool) {
+	select {
+	case <-j.pauses:
+	default:
+	}
+	j.pauses <- paused
+}
+
+// finish records the outcome of j and drops the oldest finished jobs beyond Keep. The
+// caller holds q.mu.
+func (q *Queue) finish(j *job, status string, result json.RawMessage, errText string) {
+	finished := time.Now().UTC()
+	j.Status, j.Finished, j.Result, j.Error = status, &finished, result, errText
+	j.request = nil
+	q.finished++
+	for i := 0; q.finished > q.opts.Keep && i < len(q.order); {
+		if old := q.jobs[q.order[i]]; old.Finished != nil {
+			delete(q.jobs, old.ID)
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			q.finished--
+			continue
+		}
+		i++
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,312 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingRun runs "block" requests until ctx is cancelled or release is closed, fails
+// "fail" requests and echoes the rest.
+func blockingRun(release <-chan struct{}) RunFunc {
+	return func(ctx context.Context, request []byte) (json.RawMessage, error) {
+		switch string(request) {
+		case `"block"`:
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+				return json.RawMessage(`"released"`), nil
+			}
+		case `"fail"`:
+			return nil, errors.New("no key found")
+		}
+		return request, nil
+	}
+}
+
+// waitFor polls until job id has the given status.
+func waitFor(t *testing.T, q *Queue, id, status string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, _ := q.Get(id)
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job %s is %s, expected %s", id, job.Status, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// stop shuts q down without waiting for its running jobs.
+func stop(q *Queue) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Shutdown(ctx)
+}
+
+func TestQueue_RunsJobs(t *testing.T) {
+	q := New(blockingRun(nil), Options{Workers: 2})
+	defer q.Shutdown(context.Background())
+
+	ok, _ := q.Submit([]byte(`{"x":1}`), 0)
+	failed, _ := q.Submit([]byte(`"fail"`), 0)
+	if job := waitFor(t, q, ok.ID, StatusDone); string(job.Result) != `{"x":1}` || job.Started == nil || job.Finished == nil {
+		t.Errorf("Unexpected finished job %+v", job)
+	}
+	if job := waitFor(t, q, failed.ID, StatusFailed); job.Error != "no key found" {
+		t.Errorf("Unexpected failed job %+v", job)
+	}
+	if jobs := q.List(); len(jobs) != 2 || jobs[0].ID != ok.ID {
+		t.Errorf("Expected both jobs, oldest first, got %+v", jobs)
+	}
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	q := New(blockingRun(make(chan struct{})), Options{Workers: 1})
+	defer stop(q)
+
+	running, _ := q.Submit([]byte(`"block"`), 0)
+	waitFor(t, q, running.ID, StatusRunning)
+	queued, _ := q.Submit([]byte(`"block"`), 0)
+
+	if job, _ := q.Cancel(queued.ID); job.Status != StatusCancelled {
+		t.Errorf("Expected the queued job cancelled at once, got %s", job.Status)
+	}
+	q.Cancel(running.ID)
+	waitFor(t, q, running.ID, StatusCancelled)
+	if _, err := q.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueue_Full(t *testing.T) {
+	q := New(blockingRun(make(chan struct{})), Options{Workers: 1, Capacity: 1})
+	defer stop(q)
+
+	running, _ := q.Submit([]byte(`"block"`), 0)
+	waitFor(t, q, running.ID, StatusRunning)
+	if _, err := q.Submit([]byte(`"block"`), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Submit([]byte(`"block"`), 0); !errors.Is(err, ErrFull) {
+		t.Errorf("Expected ErrFull, got %v", err)
+	}
+}
+
+func TestQueue_Shutdown(t *testing.T) {
+	release := make(chan struct{})
+	q := New(blockingRun(release), Options{Workers: 1})
+	running, _ := q.Submit([]byte(`"block"`), 0)
+	waitFor(t, q, running.ID, StatusRunning)
+	queued, _ := q.Submit([]byte(`"block"`), 0)
+
+	done := make(chan error)
+	go func() { done <- q.Shutdown(context.Background()) }()
+	for q.Accepting() {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := q.Submit([]byte(`{}`), 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	close(release) // the running job finishes, the queued one never starts
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := q.Get(running.ID); job.Status != StatusDone {
+		t.Errorf("Expected the running job to finish, got %s", job.Status)
+	}
+	if job, _ := q.Get(queued.ID); job.Status != StatusCancelled {
+		t.Errorf("Expected the queued job cancelled, got %s", job.Status)
+	}
+}
+
+func TestQueue_ShutdownTimeout(t *testing.T) {
+	q := New(blockingRun(make(chan struct{})), Options{Workers: 1})
+	running, _ := q.Submit([]byte(`"block"`), 0)
+	waitFor(t, q, running.ID, StatusRunning)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline error, got %v", err)
+	}
+	if job, _ := q.Get(running.ID); job.Status != StatusCancelled {
+		t.Errorf("Expected the running job cancelled, got %s", job.Status)
+	}
+}
+
+func TestQueue_Keep(t *testing.T) {
+	q := New(blockingRun(nil), Options{Keep: 2})
+	defer q.Shutdown(context.Background())
+
+	var last Job
+	for i := 0; i < 5; i++ {
+		last, _ = q.Submit([]byte(`{}`), 0)
+		waitFor(t, q, last.ID, StatusDone)
+	}
+	if jobs := q.List(); len(jobs) != 2 || jobs[1].ID != last.ID {
+		t.Errorf("Expected the last two jobs, got %+v", jobs)
+	}
+}
+
+func TestQueue_Priority(t *testing.T) {
+	release := make(chan struct{})
+	var started []string
+	var mu sync.Mutex
+	q := New(func(ctx context.Context, request []byte) (json.RawMessage, error) {
+		mu.Lock()
+		started = append(started, string(request))
+		mu.Unlock()
+		<-release
+		return request, nil
+	}, Options{Workers: 1})
+	defer q.Shutdown(context.Background())
+
+	first, _ := q.Submit([]byte(`"first"`), 5)
+	waitFor(t, q, first.ID, StatusRunning)
+	q.Submit([]byte(`"low"`), -1)
+	q.Submit([]byte(`"normal"`), 0)
+	last, _ := q.Submit([]byte(`"high"`), 5) // equal priority: no preemption
+	if job, _ := q.Get(first.ID); job.Status != StatusRunning {
+		t.Fatalf("Expected the first job to keep running, got %s", job.Status)
+	}
+	close(release)
+	waitFor(t, q, last.ID, StatusDone)
+	for _, job := range q.List() {
+		waitFor(t, q, job.ID, StatusDone)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(started, " "); got != `"first" "high" "normal" "low"` {
+		t.Errorf("Unexpected order %s", got)
+	}
+}
+
+func TestQueue_Preemption(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}
+	// waitEvents waits for n events
+	waitEvents := func(n int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			got := len(events)
+			mu.Unlock()
+			if got >= n {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d events, got %d", n, got)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	q := New(func(ctx context.Context, request []byte) (json.RawMessage, error) {
+		name := string(request)
+		record(name + " start")
+		running := release
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case paused := <-Pauses(ctx):
+				record(fmt.Sprintf("%s paused=%v", name, paused))
+				if running = release; paused {
+					running = nil
+				}
+			case <-running:
+				record(name + " done")
+				return request, nil
+			}
+		}
+	}, Options{Workers: 1})
+	defer stop(q)
+
+	campaign, _ := q.Submit([]byte(`campaign`), 0)
+	waitEvents(1)
+	incident, _ := q.Submit([]byte(`incident`), 10)
+	if job, _ := q.Get(campaign.ID); job.Status != StatusPaused {
+		t.Fatalf("Expected the campaign to be preempted, got %s", job.Status)
+	}
+	waitEvents(3)
+
+	release <- struct{}{} // only the incident is running
+	waitFor(t, q, incident.ID, StatusDone)
+	waitFor(t, q, campaign.ID, StatusRunning)
+	waitEvents(5)
+	close(release)
+	waitFor(t, q, campaign.ID, StatusDone)
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(events[1:3]) // the campaign pauses while the incident starts
+	want := "campaign start|campaign paused=true|incident start|incident done|campaign paused=false|campaign done"
+	if got := strings.Join(events, "|"); got != want {
+		t.Errorf("Unexpected events\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestQueue_Update(t *testing.T) {
+	run := func(ctx context.Context, request []byte) (json.RawMessage, error) {
+		select {
+		case update := <-Updates(ctx):
+			return update, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	errRejected := errors.New("rejected")
+	q := New(run, Options{Workers: 1, CheckUpdate: func(update []byte) error {
+		if string(update) == `"bad"` {
+			return errRejected
+		}
+		return nil
+	}})
+	defer stop(q)
+
+	job, _ := q.Submit([]byte(`{}`), 0)
+	if _, err := q.Update(job.ID, []byte(`"bad"`)); !errors.Is(err, errRejected) {
+		t.Errorf("Expected CheckUpdate to reject the update, got %v", err)
+	}
+	if _, err := q.Update(job.ID, []byte(`{"patterns":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if job := waitFor(t, q, job.ID, StatusDone); string(job.Result) != `{"patterns":[]}` {
+		t.Errorf("Expected the job to read its update, got %+v", job)
+	}
+	if _, err := q.Update(job.ID, []byte(`{}`)); !errors.Is(err, ErrFinished) {
+		t.Errorf("Expected ErrFinished for a finished job, got %v", err)
+	}
+	if _, err := q.Update("missing", []byte(`{}`)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Updates wait on a job that does not read them, up to maxPendingUpdates
+	blocked := New(blockingRun(make(chan struct{})), Options{Workers: 1})
+	defer stop(blocked)
+	job, _ = blocked.Submit([]byte(`"block"`), 0)
+	for i := 0; i < maxPendingUpdates; i++ {
+		if _, err := blocked.Update(job.ID, []byte(`{}`)); err != nil {
+			t.Fatalf("Update %d: %v", i, err)
+		}
+	}
+	if _, err := blocked.Update(job.ID, []byte(`{}`)); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy beyond %d pending updates, got %v", maxPendingUpdates, err)
+	}
+}
@@ -0,0 +1,14 @@
+// Package metrics instruments long-running key recovery searches and exposes them in the
+// Prometheus text exposition format, so campaigns can be monitored with existing dashboards.
+//
+// A Search holds the counters and gauges of one search (or several, run one after another);
+// strategies in pkg/ecdsaaffine and pkg/eddsaaffine update it when their Metrics field is
+// set. Handler serves it at /metrics:
+//
+//	m := metrics.NewSearch("secp256k1")
+//	strategy.Metrics = m
+//	http.Handle("/metrics", m.Handler())
+//
+// All methods are safe for concurrent use and are no-ops on a nil *Search, so
+// instrumentation costs nothing when metrics are off.
+package metrics
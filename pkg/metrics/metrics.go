@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Search holds the metrics of a key recovery search.
+type Search struct {
+	curve string
+
+	candidates   int64 // (a, b) candidates tried
+	pairs        int64 // signature pairs searched
+	keysFound    int64
+	workers      int64 // workers currently running
+	busyNanos    int64 // time workers spent searching (excludes throttle sleeps)
+	phaseStarted int64 // unix nanoseconds
+	mu           sync.Mutex
+	phase        string
+}
+
+// NewSearch creates the metrics for a search on the given curve (used as a label).
+func NewSearch(curve string) *Search {
+	return &Search{curve: curve}
+}
+
+// AddCandidates records n tried (a, b) candidates.
+func (m *Search) AddCandidates(n int64) {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.candidates, n)
+}
+
+// AddPairs records n searched signature pairs.
+func (m *Search) AddPairs(n int64) {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.pairs, n)
+}
+
+// KeyFound records a recovered key.
+func (m *Search) KeyFound() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.keysFound, 1)
+}
+
+// SetPhase records the phase the search entered.
+func (m *Search) SetPhase(phase string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.phase = phase
+	m.mu.Unlock()
+	atomic.StoreInt64(&m.phaseStarted, time.Now().UnixNano())
+}
+
+// WorkerStarted records a worker starting; call WorkerStopped when it exits.
+func (m *Search) WorkerStarted() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.workers, 1)
+}
+
+// WorkerStopped records a worker exiting.
+func (m *Search) WorkerStopped() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.workers, -1)
+}
+
+// AddBusy records time a worker spent searching. Divided by wall time and the number of
+// workers, it gives worker utilization (below 1 when throttled).
+func (m *Search) AddBusy(d time.Duration) {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.busyNanos, int64(d))
+}
+
+// Snapshot is a point-in-time copy of the metrics, for progress displays.
+type Snapshot struct {
+	Candidates   int64
+	Pairs        int64
+	KeysFound    int64
+	Workers      int64
+	Busy         time.Duration
+	Phase        string
+	PhaseStarted time.Time // zero before the first phase
+}
+
+// Snapshot returns the current values of the metrics.
+func (m *Search) Snapshot() Snapshot {
+	if m == nil {
+		return Snapshot{}
+	}
+	m.mu.Lock()
+	phase := m.phase
+	m.mu.Unlock()
+
+	snapshot := Snapshot{
+		Candidates: atomic.LoadInt64(&m.candidates),
+		Pairs:      atomic.LoadInt64(&m.pairs),
+		KeysFound:  atomic.LoadInt64(&m.keysFound),
+		Workers:    atomic.LoadInt64(&m.workers),
+		Busy:       time.Duration(atomic.LoadInt64(&m.busyNanos)),
+		Phase:      phase,
+	}
+	if started := atomic.LoadInt64(&m.phaseStarted); started != 0 {
+		snapshot.PhaseStarted = time.Unix(0, started)
+	}
+	return snapshot
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func (m *Search) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	})
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Search) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	label := fmt.Sprintf(`curve="%s"`, escapeLabel(m.curve))
+
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s %s\n%s{%s} %s\n", name, help, name, kind, name, label, formatValue(value))
+	}
+	metric("affine_candidates_tested_total", "counter", "Affine (a, b) candidates tried.", float64(atomic.LoadInt64(&m.candidates)))
+	metric("affine_pairs_tested_total", "counter", "Signature pairs searched.", float64(atomic.LoadInt64(&m.pairs)))
+	metric("affine_keys_found_total", "counter", "Private keys recovered.", float64(atomic.LoadInt64(&m.keysFound)))
+	metric("affine_workers", "gauge", "Search workers currently running.", float64(atomic.LoadInt64(&m.workers)))
+	metric("affine_worker_busy_seconds_total", "counter", "Time workers spent searching, excluding throttle sleeps.", time.Duration(atomic.LoadInt64(&m.busyNanos)).Seconds())
+
+	m.mu.Lock()
+	phase := m.phase
+	m.mu.Unlock()
+	if phase != "" {
+		fmt.Fprintf(cw, "# HELP affine_search_phase Current search phase (value is the phase start time).\n# TYPE affine_search_phase gauge\n")
+		fmt.Fprintf(cw, "affine_search_phase{%s,phase=\"%s\"} %s\n", label, escapeLabel(phase),
+			formatValue(float64(atomic.LoadInt64(&m.phaseStarted))/1e9))
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
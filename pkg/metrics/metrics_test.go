@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearch_WriteTo(t *testing.T) {
+	m := NewSearch("secp256k1")
+	m.AddCandidates(1500)
+	m.AddCandidates(500)
+	m.AddPairs(3)
+	m.KeyFound()
+	m.WorkerStarted()
+	m.WorkerStarted()
+	m.WorkerStopped()
+	m.AddBusy(1500 * time.Millisecond)
+	m.SetPhase(`Phase 2a: a=1, "small" b`)
+
+	var out strings.Builder
+	if _, err := m.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE affine_candidates_tested_total counter\n",
+		`affine_candidates_tested_total{curve="secp256k1"} 2000` + "\n",
+		`affine_pairs_tested_total{curve="secp256k1"} 3` + "\n",
+		`affine_keys_found_total{curve="secp256k1"} 1` + "\n",
+		`affine_workers{curve="secp256k1"} 1` + "\n",
+		`affine_worker_busy_seconds_total{curve="secp256k1"} 1.5` + "\n",
+		`affine_search_phase{curve="secp256k1",phase="Phase 2a: a=1, \"small\" b"} `,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSearch_Handler(t *testing.T) {
+	m := NewSearch("ed25519")
+	m.AddPairs(7)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `affine_pairs_tested_total{curve="ed25519"} 7`) {
+		t.Errorf("Unexpected body:\n%s", rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "affine_search_phase") {
+		t.Error("Expected no phase metric before a phase is set")
+	}
+}
+
+func TestSearch_Nil(t *testing.T) {
+	var m *Search
+	m.AddCandidates(1)
+	m.AddPairs(1)
+	m.KeyFound()
+	m.SetPhase("phase")
+	m.WorkerStarted()
+	m.WorkerStopped()
+	m.AddBusy(time.Second)
+	if m.Snapshot() != (Snapshot{}) {
+		t.Error("Expected an empty snapshot from nil metrics")
+	}
+}
+
+func TestSearch_Snapshot(t *testing.T) {
+	m := NewSearch("secp256k1")
+	if snapshot := m.Snapshot(); !snapshot.PhaseStarted.IsZero() || snapshot.Phase != "" {
+		t.Errorf("Expected no phase before SetPhase, got %+v", snapshot)
+	}
+
+	before := time.Now()
+	m.SetPhase("Phase 2b: a=1, medium b")
+	m.AddCandidates(42)
+	m.AddPairs(2)
+	m.WorkerStarted()
+	m.AddBusy(time.Second)
+
+	snapshot := m.Snapshot()
+	if snapshot.Candidates != 42 || snapshot.Pairs != 2 || snapshot.Workers != 1 || snapshot.Busy != time.Second {
+		t.Errorf("Unexpected counters: %+v", snapshot)
+	}
+	if snapshot.Phase != "Phase 2b: a=1, medium b" || snapshot.PhaseStarted.Before(before) {
+		t.Errorf("Unexpected phase: %q started %v", snapshot.Phase, snapshot.PhaseStarted)
+	}
+}
@@ -0,0 +1,22 @@
+// Package nonceanalysis characterizes the nonce generator behind a set of signatures
+// once their nonces are known (for example after recovering the private key with
+// pkg/ecdsaaffine or pkg/eddsaaffine and calling RecoverNonces).
+//
+// The analysis computes the pairwise relationship between all nonces and identifies
+// the generator pattern: reused nonce, constant step counter, counter that resets to
+// the same base, per-session seeds with a counter inside each session, or a general
+// affine recurrence. The result is available as a structured Report and can be
+// rendered as a text matrix or as a Graphviz DOT graph for incident write-ups.
+//
+// FingerprintNonces reduces the analysis of one key to what carries over between keys
+// (the counter step or affine constants, truncated or zero-padded nonces), and Correlate
+// clusters the fingerprints of many keys by it: keys in one cluster were likely signed by
+// the same flawed library or device model.
+//
+// # Quick Start
+//
+//	nonces, _ := ecdsaaffine.RecoverNonces(result, signatures)
+//	report := nonceanalysis.Analyze(nonces, ecdsaaffine.Secp256k1CurveOrder, nonceanalysis.DefaultOptions())
+//	fmt.Println(report.Pattern)
+//	report.WriteText(os.Stdout)
+package nonceanalysis
@@ -0,0 +1,119 @@
+package nonceanalysis
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Finding says whether one signature's nonce came from the flawed generator.
+type Finding struct {
+	Index     int       `json:"index"`
+	Timestamp time.Time `json:"timestamp"` // zero if unknown
+	Weak      bool      `json:"weak"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Exposure scopes an incident: which signatures used weak nonces, and when.
+type Exposure struct {
+	Findings []Finding `json:"findings"`
+
+	// Weak is the number of signatures with weak nonces
+	Weak int `json:"weak"`
+
+	// FirstWeak and LastWeak are the earliest and latest signing times of weak
+	// signatures, zero if none has a time
+	FirstWeak time.Time `json:"first_weak"`
+	LastWeak  time.Time `json:"last_weak"`
+}
+
+// Scope flags the signatures whose nonces a flawed generator produced, given the nonces
+// of all signatures by one key (e.g. from RecoverNonces with the recovered key). A nonce
+// is weak if it is linked to another by a small difference (a reused or counter nonce),
+// if all nonces follow the affine recurrence Analyze finds, or if it is small itself
+// (|k| <= SmallBound, e.g. a truncated nonce). Any other nonce looks like proper
+// randomness. timestamps holds the signing times, if known, in the same order; it may be
+// nil or hold zero times.
+func Scope(nonces []*big.Int, timestamps []time.Time, order *big.Int, opts Options) *Exposure {
+	report := Analyze(nonces, order, opts)
+	exposure := &Exposure{Findings: make([]Finding, len(nonces))}
+
+	linked := make([]string, len(nonces))
+	for _, e := range report.Edges {
+		if linked[e.From] == "" {
+			linked[e.From] = relation(e.To, e.Difference)
+		}
+		if linked[e.To] == "" {
+			linked[e.To] = relation(e.From, new(big.Int).Neg(e.Difference))
+		}
+	}
+
+	for i, k := range nonces {
+		f := Finding{Index: i}
+		if i < len(timestamps) {
+			f.Timestamp = timestamps[i]
+		}
+		switch {
+		case report.Pattern == PatternAffine:
+			f.Reason = fmt.Sprintf("follows k[i+1] = %s*k[i] + %s", report.A, report.B)
+		case linked[i] != "":
+			f.Reason = linked[i]
+		case isSmall(centered(k, order), report.smallBound):
+			f.Reason = fmt.Sprintf("small nonce (%d bits)", new(big.Int).Abs(centered(k, order)).BitLen())
+		}
+		f.Weak = f.Reason != ""
+		exposure.Findings[i] = f
+
+		if !f.Weak {
+			continue
+		}
+		exposure.Weak++
+		if f.Timestamp.IsZero() {
+			continue
+		}
+		if exposure.FirstWeak.IsZero() || f.Timestamp.Before(exposure.FirstWeak) {
+			exposure.FirstWeak = f.Timestamp
+		}
+		if f.Timestamp.After(exposure.LastWeak) {
+			exposure.LastWeak = f.Timestamp
+		}
+	}
+	return exposure
+}
+
+// relation describes a nonce's link to signature j, whose nonce is the nonce plus d.
+func relation(j int, d *big.Int) string {
+	if d.Sign() == 0 {
+		return fmt.Sprintf("same nonce as signature %d", j)
+	}
+	if d.Sign() < 0 {
+		return fmt.Sprintf("k[%d] = k - %s", j, new(big.Int).Neg(d))
+	}
+	return fmt.Sprintf("k[%d] = k + %s", j, d)
+}
+
+// WriteText renders the weak signatures and the time window they were signed in.
+func (e *Exposure) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Weak nonce scope (%d of %d signatures weak)\n", e.Weak, len(e.Findings))
+	if !e.FirstWeak.IsZero() {
+		fmt.Fprintf(&sb, "  Weak signatures signed from %s to %s\n", e.FirstWeak.Format(time.RFC3339), e.LastWeak.Format(time.RFC3339))
+	}
+	sb.WriteString("\n")
+	for _, f := range e.Findings {
+		when := "-"
+		if !f.Timestamp.IsZero() {
+			when = f.Timestamp.Format(time.RFC3339)
+		}
+		status := "ok"
+		if f.Weak {
+			status = "WEAK: " + f.Reason
+		}
+		fmt.Fprintf(&sb, "  %4d  %-25s  %s\n", f.Index, when, status)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
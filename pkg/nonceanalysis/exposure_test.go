@@ -0,0 +1,78 @@
+package nonceanalysis
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScope(t *testing.T) {
+	base := bigHex("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4")
+	random := []*big.Int{
+		bigHex("9a8b7c6d5e4f30211203f4e5d6c7b8a99a8b7c6d5e4f30211203f4e5d6c7b8a9"),
+		bigHex("1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"),
+	}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Proper nonces, then a counter while the bug was live, then a truncated nonce
+	nonces := []*big.Int{
+		random[0],
+		base,
+		new(big.Int).Add(base, big.NewInt(1)),
+		new(big.Int).Add(base, big.NewInt(2)),
+		random[1],
+		big.NewInt(0xdeadbeef),
+	}
+	timestamps := make([]time.Time, len(nonces))
+	for i := range timestamps {
+		timestamps[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	exposure := Scope(nonces, timestamps, testOrder, DefaultOptions())
+	want := []bool{false, true, true, true, false, true}
+	for i, f := range exposure.Findings {
+		if f.Weak != want[i] {
+			t.Errorf("Signature %d: expected weak=%v, got %+v", i, want[i], f)
+		}
+	}
+	if exposure.Weak != 4 {
+		t.Errorf("Expected 4 weak signatures, got %d", exposure.Weak)
+	}
+	if !exposure.FirstWeak.Equal(timestamps[1]) || !exposure.LastWeak.Equal(timestamps[5]) {
+		t.Errorf("Expected the window %v to %v, got %v to %v", timestamps[1], timestamps[5], exposure.FirstWeak, exposure.LastWeak)
+	}
+	if got := exposure.Findings[2].Reason; got != "k[1] = k - 1" {
+		t.Errorf("Unexpected reason %q", got)
+	}
+
+	var text bytes.Buffer
+	if err := exposure.WriteText(&text); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if !strings.Contains(text.String(), "4 of 6 signatures weak") || !strings.Contains(text.String(), "2024-03-01T13:00:00Z") {
+		t.Errorf("Text report missing findings:\n%s", text.String())
+	}
+
+	// Without timestamps the window is unknown
+	exposure = Scope(nonces, nil, testOrder, DefaultOptions())
+	if exposure.Weak != 4 || !exposure.FirstWeak.IsZero() {
+		t.Errorf("Expected 4 weak signatures and no window, got %+v", exposure)
+	}
+}
+
+func TestScope_Affine(t *testing.T) {
+	k := bigHex("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+	nonces := []*big.Int{k}
+	for i := 0; i < 4; i++ {
+		next := new(big.Int).Mul(nonces[i], big.NewInt(3))
+		next.Add(next, big.NewInt(5))
+		nonces = append(nonces, next.Mod(next, testOrder))
+	}
+
+	exposure := Scope(nonces, nil, testOrder, DefaultOptions())
+	if exposure.Weak != len(nonces) {
+		t.Errorf("Expected every nonce of an affine recurrence to be weak, got %+v", exposure.Findings)
+	}
+}
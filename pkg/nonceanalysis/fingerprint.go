@@ -0,0 +1,170 @@
+package nonceanalysis
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Fingerprint describes the nonce generator behind one key in terms that carry over from
+// key to key: the generator's constants and bias, not the nonces or the sessions of one
+// deployment. Keys whose fingerprints share a Behavior were likely signed by the same
+// flawed library or device model (see Correlate).
+type Fingerprint struct {
+	// Label identifies the key, e.g. its dataset or public key
+	Label string `json:"label"`
+
+	// Signatures is the number of nonces fingerprinted
+	Signatures int `json:"signatures"`
+
+	// Pattern, Step, A and B are as in Report
+	Pattern string   `json:"pattern"`
+	Step    *big.Int `json:"step,omitempty"`
+	A       *big.Int `json:"a,omitempty"`
+	B       *big.Int `json:"b,omitempty"`
+
+	// NonceBits is the width of the largest nonce, rounded up to whole bytes
+	NonceBits int `json:"nonce_bits"`
+
+	// TrailingZeroBits is the number of low bits that are zero in every nonce, rounded
+	// down to whole bytes
+	TrailingZeroBits int `json:"trailing_zero_bits,omitempty"`
+
+	// Behavior is the part of the fingerprint compared across keys, e.g. "counter step=1"
+	// or "affine a=3 b=5, 128-bit nonces"; empty if nothing in it stands out from a
+	// sound generator
+	Behavior string `json:"behavior,omitempty"`
+}
+
+// FingerprintNonces fingerprints the nonces of one key, as recovered by RecoverNonces.
+//
+// Bias is measured in whole bytes: truncated and zero-padded nonces lose bytes, and a
+// handful of random nonces can all miss a top bit or share a low zero bit by chance.
+func FingerprintNonces(label string, nonces []*big.Int, order *big.Int, opts Options) Fingerprint {
+	report := Analyze(nonces, order, opts)
+	fp := Fingerprint{
+		Label:      label,
+		Signatures: len(nonces),
+		Pattern:    report.Pattern,
+		Step:       report.Step,
+		A:          report.A,
+		B:          report.B,
+	}
+
+	trailing := -1
+	for _, k := range nonces {
+		k = new(big.Int).Mod(k, order)
+		fp.NonceBits = max(fp.NonceBits, k.BitLen())
+		if k.Sign() != 0 && (trailing < 0 || int(k.TrailingZeroBits()) < trailing) {
+			trailing = int(k.TrailingZeroBits())
+		}
+	}
+	fp.NonceBits = (fp.NonceBits + 7) / 8 * 8
+	fp.TrailingZeroBits = max(trailing, 0) / 8 * 8
+	fp.Behavior = fp.behavior((order.BitLen() + 7) / 8 * 8)
+	return fp
+}
+
+// behavior describes the generator family and bias of fp, with fullBits the width of a
+// full nonce. Counters with the same step are one family whether or not they reset or
+// are reseeded per session: that depends on the deployment, the step on the library.
+func (fp *Fingerprint) behavior(fullBits int) string {
+	var parts []string
+	switch fp.Pattern {
+	case PatternSameNonce:
+		parts = append(parts, "reused nonce")
+	case PatternConstantStep, PatternResettingCounter, PatternPerSessionSeed:
+		parts = append(parts, "counter step="+fp.Step.String())
+	case PatternAffine:
+		parts = append(parts, fmt.Sprintf("affine a=%s b=%s", fp.A, fp.B))
+	}
+	if fp.NonceBits < fullBits {
+		parts = append(parts, fmt.Sprintf("%d-bit nonces", fp.NonceBits))
+	}
+	if fp.TrailingZeroBits > 0 {
+		parts = append(parts, fmt.Sprintf("%d low zero bits", fp.TrailingZeroBits))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Cluster is a group of keys whose fingerprints share a Behavior.
+type Cluster struct {
+	ID       int      `json:"id"`
+	Behavior string   `json:"behavior"`
+	Members  []int    `json:"members"` // indices into Correlation.Fingerprints
+	Labels   []string `json:"labels"`
+}
+
+// Correlation assigns fingerprints of different keys to clusters of a common behavior.
+type Correlation struct {
+	Fingerprints []Fingerprint `json:"fingerprints"`
+
+	// Assignments is the cluster ID of each fingerprint, or -1 for one with no Behavior
+	Assignments []int `json:"assignments"`
+
+	// Clusters lists the clusters, largest first
+	Clusters []Cluster `json:"clusters"`
+}
+
+// Correlate clusters fingerprints by Behavior, so that keys signed by the same flawed
+// library or device model end up together. A cluster of one is a behavior seen on a
+// single key; fingerprints without a Behavior are not clustered.
+func Correlate(fingerprints []Fingerprint) *Correlation {
+	correlation := &Correlation{
+		Fingerprints: fingerprints,
+		Assignments:  make([]int, len(fingerprints)),
+	}
+	index := make(map[string]int)
+	for i, fp := range fingerprints {
+		correlation.Assignments[i] = -1
+		if fp.Behavior == "" {
+			continue
+		}
+		c, ok := index[fp.Behavior]
+		if !ok {
+			c = len(correlation.Clusters)
+			index[fp.Behavior] = c
+			correlation.Clusters = append(correlation.Clusters, Cluster{Behavior: fp.Behavior})
+		}
+		correlation.Clusters[c].Members = append(correlation.Clusters[c].Members, i)
+		correlation.Clusters[c].Labels = append(correlation.Clusters[c].Labels, fp.Label)
+	}
+
+	// Largest first; ties stay in order of first appearance
+	sort.SliceStable(correlation.Clusters, func(i, j int) bool {
+		return len(correlation.Clusters[i].Members) > len(correlation.Clusters[j].Members)
+	})
+	for c := range correlation.Clusters {
+		correlation.Clusters[c].ID = c
+		for _, i := range correlation.Clusters[c].Members {
+			correlation.Assignments[i] = c
+		}
+	}
+	return correlation
+}
+
+// WriteText renders the clusters, and the keys left out of them, as plain text.
+func (c *Correlation) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Signer correlation (%d keys, %d clusters)\n", len(c.Fingerprints), len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		fmt.Fprintf(&sb, "  [%d] %s: %d keys\n", cluster.ID, cluster.Behavior, len(cluster.Members))
+		for _, label := range cluster.Labels {
+			fmt.Fprintf(&sb, "      %s\n", label)
+		}
+	}
+	var unclustered []string
+	for i, fp := range c.Fingerprints {
+		if c.Assignments[i] < 0 {
+			unclustered = append(unclustered, fp.Label)
+		}
+	}
+	if len(unclustered) > 0 {
+		fmt.Fprintf(&sb, "  No distinctive behavior: %s\n", strings.Join(unclustered, ", "))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
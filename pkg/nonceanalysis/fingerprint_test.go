@@ -0,0 +1,91 @@
+package nonceanalysis
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// steps returns count nonces from start, each step above the one before.
+func steps(start *big.Int, step int64, count int) []*big.Int {
+	nonces := []*big.Int{start}
+	for len(nonces) < count {
+		nonces = append(nonces, new(big.Int).Add(nonces[len(nonces)-1], big.NewInt(step)))
+	}
+	return nonces
+}
+
+func TestFingerprintNonces(t *testing.T) {
+	base := bigHex("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4")
+	other := bigHex("9a8b7c6d5e4f30211203f4e5d6c7b8a99a8b7c6d5e4f30211203f4e5d6c7b8a9")
+	small := bigHex("c5d6e7f8091a2b3c4d5e6f708192a3b4")
+	padded := bigHex("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f7081920000")
+	affine := []*big.Int{base}
+	for len(affine) < 4 {
+		next := new(big.Int).Mul(affine[len(affine)-1], big.NewInt(3))
+		next.Add(next, big.NewInt(5))
+		affine = append(affine, next.Mod(next, testOrder))
+	}
+
+	tests := []struct {
+		name     string
+		nonces   []*big.Int
+		behavior string
+	}{
+		{"counter", steps(base, 7, 4), "counter step=7"},
+		{"reseeded counter", append(steps(base, 7, 3), steps(other, 7, 2)...), "counter step=7"},
+		{"reused", []*big.Int{base, base}, "reused nonce"},
+		{"affine", affine, "affine a=3 b=5"},
+		{"small counter", steps(small, 1, 3), "counter step=1, 128-bit nonces"},
+		{"zero padded", []*big.Int{padded, bigHex("9a8b7c6d5e4f30211203f4e5d6c7b8a99a8b7c6d5e4f30211203f4e5d6c70000"), bigHex("1234567890abcdef1234567890abcdef1234567890abcdef1234567890ab0000")}, "16 low zero bits"},
+		{"sound", []*big.Int{base, other, bigHex("1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef")}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := FingerprintNonces(tt.name, tt.nonces, testOrder, DefaultOptions())
+			if fp.Behavior != tt.behavior {
+				t.Errorf("Expected behavior %q, got %q (%+v)", tt.behavior, fp.Behavior, fp)
+			}
+			if fp.Signatures != len(tt.nonces) {
+				t.Errorf("Expected %d signatures, got %d", len(tt.nonces), fp.Signatures)
+			}
+		})
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	base := bigHex("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4")
+	other := bigHex("9a8b7c6d5e4f30211203f4e5d6c7b8a99a8b7c6d5e4f30211203f4e5d6c7b8a9")
+	third := bigHex("1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef")
+	fingerprints := []Fingerprint{
+		FingerprintNonces("wallet-a", steps(base, 1, 4), testOrder, DefaultOptions()),
+		FingerprintNonces("wallet-b", []*big.Int{base, other, third}, testOrder, DefaultOptions()),
+		FingerprintNonces("wallet-c", steps(other, 1000, 3), testOrder, DefaultOptions()),
+		FingerprintNonces("wallet-d", append(steps(third, 1, 2), steps(other, 1, 3)...), testOrder, DefaultOptions()),
+		FingerprintNonces("wallet-e", steps(other, 1, 5), testOrder, DefaultOptions()),
+	}
+
+	correlation := Correlate(fingerprints)
+	if want := []int{0, -1, 1, 0, 0}; !reflect.DeepEqual(correlation.Assignments, want) {
+		t.Fatalf("Expected assignments %v, got %v", want, correlation.Assignments)
+	}
+	if len(correlation.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %+v", correlation.Clusters)
+	}
+	cluster := correlation.Clusters[0]
+	if cluster.Behavior != "counter step=1" || !reflect.DeepEqual(cluster.Labels, []string{"wallet-a", "wallet-d", "wallet-e"}) {
+		t.Errorf("Unexpected largest cluster %+v", cluster)
+	}
+
+	var text bytes.Buffer
+	if err := correlation.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"5 keys, 2 clusters", "[0] counter step=1: 3 keys", "[1] counter step=1000: 1 keys", "No distinctive behavior: wallet-b"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, text.String())
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package nonceanalysis
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// Generator patterns identified by Analyze.
+const (
+	PatternSameNonce        = "same_nonce"
+	PatternConstantStep     = "constant_step"
+	PatternResettingCounter = "resetting_counter"
+	PatternPerSessionSeed   = "per_session_seed"
+	PatternAffine           = "affine"
+	PatternUnrelated        = "unrelated"
+)
+
+// Options configures the relationship analysis.
+type Options struct {
+	// SmallBound is the largest |k_j - k_i| treated as a related (counter-like) difference
+	SmallBound *big.Int
+}
+
+// DefaultOptions returns options treating differences below 2^64 as related.
+func DefaultOptions() Options {
+	return Options{
+		SmallBound: new(big.Int).Lsh(big.NewInt(1), 64),
+	}
+}
+
+// Edge links two nonces whose difference is small: k[To] = k[From] + Difference.
+type Edge struct {
+	From       int      `json:"from"`
+	To         int      `json:"to"`
+	Difference *big.Int `json:"difference"`
+}
+
+// Report describes the pairwise nonce relationships and the inferred generator pattern.
+type Report struct {
+	// Differences[i][j] = k_j - k_i mod n, centered into (-n/2, n/2]
+	Differences [][]*big.Int `json:"-"`
+
+	// Pattern is the inferred generator pattern (one of the Pattern* constants)
+	Pattern string `json:"pattern"`
+
+	// Step is the counter step for constant_step, resetting_counter and per_session_seed
+	Step *big.Int `json:"step,omitempty"`
+
+	// A and B are the recurrence k_{i+1} = A*k_i + B for affine (and constant_step) patterns
+	A *big.Int `json:"a,omitempty"`
+	B *big.Int `json:"b,omitempty"`
+
+	// Sessions groups signature indices whose nonces are linked by small differences
+	Sessions [][]int `json:"sessions"`
+
+	// Edges lists all pairs with small differences
+	Edges []Edge `json:"edges"`
+
+	smallBound *big.Int
+}
+
+// Analyze computes the relationship matrix between nonces and infers the generator pattern.
+func Analyze(nonces []*big.Int, order *big.Int, opts Options) *Report {
+	if opts.SmallBound == nil {
+		opts.SmallBound = DefaultOptions().SmallBound
+	}
+
+	report := &Report{
+		Differences: make([][]*big.Int, len(nonces)),
+		smallBound:  opts.SmallBound,
+	}
+	for i := range nonces {
+		report.Differences[i] = make([]*big.Int, len(nonces))
+		for j := range nonces {
+			report.Differences[i][j] = centeredDiff(nonces[j], nonces[i], order)
+		}
+	}
+
+	for i := 0; i < len(nonces); i++ {
+		for j := i + 1; j < len(nonces); j++ {
+			d := report.Differences[i][j]
+			if isSmall(d, opts.SmallBound) {
+				report.Edges = append(report.Edges, Edge{From: i, To: j, Difference: d})
+			}
+		}
+	}
+	report.Sessions = components(len(nonces), report.Edges)
+	report.classify(nonces, order)
+	return report
+}
+
+// classify infers the generator pattern from consecutive nonce differences.
+func (r *Report) classify(nonces []*big.Int, order *big.Int) {
+	r.Pattern = PatternUnrelated
+	if len(nonces) < 2 {
+		return
+	}
+
+	deltas := make([]*big.Int, len(nonces)-1)
+	for i := range deltas {
+		deltas[i] = r.Differences[i][i+1]
+	}
+
+	if allEqual(deltas) {
+		if deltas[0].Sign() == 0 {
+			r.Pattern = PatternSameNonce
+		} else {
+			r.Pattern = PatternConstantStep
+			r.Step = deltas[0]
+		}
+		r.A = big.NewInt(1)
+		r.B = deltas[0]
+		return
+	}
+
+	if a, b, ok := affineRecurrence(nonces, order); ok {
+		r.Pattern = PatternAffine
+		r.A, r.B = a, b
+		return
+	}
+
+	// Split into runs with a common small step; a run boundary is a non-step delta
+	step := mostCommonSmall(deltas, r.smallBound)
+	if step == nil {
+		return
+	}
+	var starts []int
+	starts = append(starts, 0)
+	for i, d := range deltas {
+		if d.Cmp(step) != 0 {
+			starts = append(starts, i+1)
+		}
+	}
+	if len(starts) < 2 || len(starts) == len(nonces) {
+		return
+	}
+	r.Step = step
+
+	sameBase := true
+	for _, s := range starts[1:] {
+		if nonces[s].Cmp(nonces[starts[0]]) != 0 {
+			sameBase = false
+			break
+		}
+	}
+	if sameBase {
+		r.Pattern = PatternResettingCounter
+	} else {
+		r.Pattern = PatternPerSessionSeed
+	}
+}
+
+// affineRecurrence solves k_{i+1} = a*k_i + b from the first three nonces and checks it holds for all.
+func affineRecurrence(nonces []*big.Int, order *big.Int) (*big.Int, *big.Int, bool) {
+	if len(nonces) < 4 {
+		// Three nonces always fit some affine recurrence; require one extra to confirm
+		return nil, nil, false
+	}
+	d1 := new(big.Int).Sub(nonces[1], nonces[0])
+	d1.Mod(d1, order)
+	d2 := new(big.Int).Sub(nonces[2], nonces[1])
+	d2.Mod(d2, order)
+	inv := new(big.Int).ModInverse(d1, order)
+	if inv == nil {
+		return nil, nil, false
+	}
+	a := new(big.Int).Mul(d2, inv)
+	a.Mod(a, order)
+	b := new(big.Int).Mul(a, nonces[0])
+	b.Sub(nonces[1], b)
+	b.Mod(b, order)
+
+	for i := 0; i+1 < len(nonces); i++ {
+		next := new(big.Int).Mul(a, nonces[i])
+		next.Add(next, b)
+		next.Mod(next, order)
+		if next.Cmp(new(big.Int).Mod(nonces[i+1], order)) != 0 {
+			return nil, nil, false
+		}
+	}
+	return a, centered(b, order), true
+}
+
+// WriteText renders the difference matrix and findings as plain text.
+// Small differences are printed in decimal; unrelated pairs are shown as ".".
+func (r *Report) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Nonce relationship report (%d signatures)\n", len(r.Differences))
+	fmt.Fprintf(&sb, "  Pattern: %s\n", r.Pattern)
+	if r.Step != nil {
+		fmt.Fprintf(&sb, "  Step: %s\n", r.Step.String())
+	}
+	if r.A != nil && r.B != nil {
+		fmt.Fprintf(&sb, "  Recurrence: k[i+1] = %s*k[i] + %s\n", r.A.String(), r.B.String())
+	}
+	fmt.Fprintf(&sb, "  Sessions: %d\n", len(r.Sessions))
+	for i, session := range r.Sessions {
+		fmt.Fprintf(&sb, "    [%d] signatures %v\n", i, session)
+	}
+
+	sb.WriteString("\n  k[j] - k[i]:\n")
+	sb.WriteString("       ")
+	for j := range r.Differences {
+		fmt.Fprintf(&sb, " %8d", j)
+	}
+	sb.WriteString("\n")
+	for i, row := range r.Differences {
+		fmt.Fprintf(&sb, "  %4d ", i)
+		for _, d := range row {
+			cell := "."
+			if isSmall(d, r.smallBound) {
+				cell = d.String()
+				if len(cell) > 8 {
+					cell = "~" + cell[len(cell)-7:]
+				}
+			}
+			fmt.Fprintf(&sb, " %8s", cell)
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteDOT renders the small-difference graph in Graphviz DOT format.
+func (r *Report) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph nonces {\n")
+	fmt.Fprintf(&sb, "  label=%q;\n", "nonce pattern: "+r.Pattern)
+	for i := range r.Differences {
+		fmt.Fprintf(&sb, "  s%d [label=\"sig %d\"];\n", i, i)
+	}
+	for _, e := range r.Edges {
+		// Only draw consecutive links within a session to keep the graph readable
+		if e.To != e.From+1 && len(r.Edges) > 2*len(r.Differences) {
+			continue
+		}
+		fmt.Fprintf(&sb, "  s%d -> s%d [label=\"%+d\"];\n", e.From, e.To, e.Difference)
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// centeredDiff returns (x - y) mod n mapped into (-n/2, n/2].
+func centeredDiff(x, y, n *big.Int) *big.Int {
+	return centered(new(big.Int).Sub(x, y), n)
+}
+
+func centered(v, n *big.Int) *big.Int {
+	d := new(big.Int).Mod(v, n)
+	half := new(big.Int).Rsh(n, 1)
+	if d.Cmp(half) > 0 {
+		d.Sub(d, n)
+	}
+	return d
+}
+
+func isSmall(d, bound *big.Int) bool {
+	return new(big.Int).Abs(d).Cmp(bound) <= 0
+}
+
+func allEqual(values []*big.Int) bool {
+	for _, v := range values[1:] {
+		if v.Cmp(values[0]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mostCommonSmall returns the most frequent nonzero small value, or nil if none repeats.
+func mostCommonSmall(values []*big.Int, bound *big.Int) *big.Int {
+	counts := make(map[string]int)
+	var best *big.Int
+	bestCount := 1
+	for _, v := range values {
+		if v.Sign() == 0 || !isSmall(v, bound) {
+			continue
+		}
+		key := v.String()
+		counts[key]++
+		if counts[key] > bestCount {
+			bestCount = counts[key]
+			best = v
+		}
+	}
+	return best
+}
+
+// components returns the connected components of the small-difference graph.
+func components(n int, edges []Edge) [][]int {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	for _, e := range edges {
+		parent[find(e.From)] = find(e.To)
+	}
+
+	index := make(map[int]int)
+	var groups [][]int
+	for i := 0; i < n; i++ {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
@@ -0,0 +1,94 @@
+package nonceanalysis
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var testOrder, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+
+func bigHex(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 16)
+	return v
+}
+
+func TestAnalyze_Patterns(t *testing.T) {
+	base := bigHex("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4")
+	other := bigHex("9a8b7c6d5e4f30211203f4e5d6c7b8a99a8b7c6d5e4f30211203f4e5d6c7b8a9")
+	add := func(k *big.Int, d int64) *big.Int { return new(big.Int).Add(k, big.NewInt(d)) }
+	affine := func(k *big.Int) *big.Int {
+		v := new(big.Int).Mul(k, big.NewInt(3))
+		v.Add(v, big.NewInt(5))
+		return v.Mod(v, testOrder)
+	}
+	a1 := affine(base)
+	a2 := affine(a1)
+
+	tests := []struct {
+		name     string
+		nonces   []*big.Int
+		pattern  string
+		sessions int
+	}{
+		{"same nonce", []*big.Int{base, base, base}, PatternSameNonce, 1},
+		{"constant step", []*big.Int{base, add(base, 7), add(base, 14), add(base, 21)}, PatternConstantStep, 1},
+		{"resetting counter", []*big.Int{base, add(base, 1), add(base, 2), base, add(base, 1)}, PatternResettingCounter, 1},
+		{"per session seed", []*big.Int{base, add(base, 1), add(base, 2), other, add(other, 1)}, PatternPerSessionSeed, 2},
+		{"affine", []*big.Int{base, a1, a2, affine(a2)}, PatternAffine, 4},
+		{"unrelated", []*big.Int{base, other, bigHex("1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef")}, PatternUnrelated, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Analyze(tt.nonces, testOrder, DefaultOptions())
+			if report.Pattern != tt.pattern {
+				t.Errorf("Expected pattern %s, got %s", tt.pattern, report.Pattern)
+			}
+			if len(report.Sessions) != tt.sessions {
+				t.Errorf("Expected %d sessions, got %d (%v)", tt.sessions, len(report.Sessions), report.Sessions)
+			}
+		})
+	}
+}
+
+func TestAnalyze_AffineCoefficients(t *testing.T) {
+	k := bigHex("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")
+	nonces := []*big.Int{k}
+	for i := 0; i < 4; i++ {
+		next := new(big.Int).Mul(nonces[i], big.NewInt(2))
+		next.Sub(next, big.NewInt(9))
+		nonces = append(nonces, next.Mod(next, testOrder))
+	}
+
+	report := Analyze(nonces, testOrder, DefaultOptions())
+	if report.Pattern != PatternAffine {
+		t.Fatalf("Expected affine pattern, got %s", report.Pattern)
+	}
+	if report.A.Cmp(big.NewInt(2)) != 0 || report.B.Cmp(big.NewInt(-9)) != 0 {
+		t.Errorf("Expected a=2, b=-9, got a=%s, b=%s", report.A, report.B)
+	}
+}
+
+func TestReport_Render(t *testing.T) {
+	base := bigHex("4c1e6bd1f3a6f2d9a5e0c4b7d1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4")
+	nonces := []*big.Int{base, new(big.Int).Add(base, big.NewInt(13511)), new(big.Int).Add(base, big.NewInt(27022))}
+	report := Analyze(nonces, testOrder, DefaultOptions())
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if !strings.Contains(text.String(), "constant_step") || !strings.Contains(text.String(), "13511") {
+		t.Errorf("Text report missing findings:\n%s", text.String())
+	}
+
+	var dot bytes.Buffer
+	if err := report.WriteDOT(&dot); err != nil {
+		t.Fatalf("WriteDOT: %v", err)
+	}
+	if !strings.Contains(dot.String(), "s0 -> s1 [label=\"+13511\"]") {
+		t.Errorf("DOT graph missing edge:\n%s", dot.String())
+	}
+}
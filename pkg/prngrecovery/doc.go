@@ -0,0 +1,41 @@
+// Package prngrecovery reconstructs the internal state of common non-cryptographic
+// pseudo-random number generators from observed nonces, and predicts the nonces
+// a flawed signer will produce next.
+//
+// Once one private key has been recovered (see pkg/ecdsaaffine and pkg/eddsaaffine),
+// every nonce used by that key can be computed. If the signer drew its nonces from a
+// general-purpose PRNG, those nonces are a window into the generator's output stream,
+// and a sufficiently long window is enough to clone the generator. The clone then
+// predicts nonces used for other signatures and other keys produced by the same
+// process — turning one recovery into many.
+//
+// Supported generators:
+//
+//   - MT19937 (Python's random module, C++ std::mt19937, PHP mt_rand): 624 consecutive
+//     32-bit outputs are required.
+//   - xorshift32 / xorshift64 (Marsaglia): a single output determines the state.
+//   - Go math/rand (additive lagged Fibonacci, rngSource): 607 consecutive 64-bit
+//     outputs are required; 63-bit Int63 outputs are also supported.
+//
+// Nonces are split into generator words according to a Layout. The default layout
+// mirrors Python's random.getrandbits(256): eight 32-bit words, least significant
+// word first. Words whose bits were masked off by the signer (for example to keep
+// the nonce below the curve order) can be marked as truncated; they are treated as
+// unknown and filled in by the generator recurrence when possible.
+//
+// # Quick Start
+//
+//	import "github.com/mahdiidarabi/ecdsa-affine/pkg/prngrecovery"
+//
+//	// nonces: consecutive nonces computed from a recovered key
+//	layout := prngrecovery.DefaultLayout()
+//	matches := prngrecovery.Detect(layout, nonces)
+//	for _, m := range matches {
+//	    fmt.Printf("%s explains %d observed words\n", m.Generator, m.Verified)
+//	}
+//
+//	// Predict the next 10 nonces
+//	next, err := prngrecovery.PredictNonces(prngrecovery.MT19937(), layout, nonces, 10)
+//
+// WARNING: This package is for security research and testing purposes only.
+package prngrecovery
@@ -0,0 +1,208 @@
+package prngrecovery
+
+// Generator models a PRNG as a recurrence over its output stream.
+// Implement this interface to add support for additional generators.
+type Generator interface {
+	// Name returns a human-readable name for this generator.
+	Name() string
+
+	// WordBits returns the width in bits of a single generator output.
+	WordBits() int
+
+	// Predict returns the output at position i, computed from outputs at earlier
+	// positions in seq. It returns false when the required outputs are unknown.
+	Predict(seq []Word, i int) (uint64, bool)
+}
+
+// MT19937 parameters (Matsumoto & Nishimura).
+const (
+	mtN         = 624
+	mtM         = 397
+	mtMatrixA   = 0x9908b0df
+	mtUpperMask = 0x80000000
+	mtLowerMask = 0x7fffffff
+)
+
+type mt19937 struct{}
+
+// MT19937 returns a model of the 32-bit Mersenne Twister.
+func MT19937() Generator {
+	return mt19937{}
+}
+
+func (mt19937) Name() string  { return "mt19937" }
+func (mt19937) WordBits() int { return 32 }
+
+// Predict implements the twist recurrence on untempered state words:
+// x[k] = x[k-227] ^ twist((x[k-624] & upper) | (x[k-623] & lower)).
+func (mt19937) Predict(seq []Word, i int) (uint64, bool) {
+	if i < mtN {
+		return 0, false
+	}
+	w0, w1, wm := seq[i-mtN], seq[i-mtN+1], seq[i-mtN+mtM]
+	if !w0.Known || !w1.Known || !wm.Known {
+		return 0, false
+	}
+	x0 := mtUntemper(uint32(w0.Value))
+	x1 := mtUntemper(uint32(w1.Value))
+	xm := mtUntemper(uint32(wm.Value))
+
+	y := (x0 & mtUpperMask) | (x1 & mtLowerMask)
+	x := xm ^ (y >> 1)
+	if y&1 != 0 {
+		x ^= mtMatrixA
+	}
+	return uint64(mtTemper(x)), true
+}
+
+func mtTemper(y uint32) uint32 {
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// mtUntemper inverts mtTemper, recovering the raw state word from an output.
+func mtUntemper(y uint32) uint32 {
+	y ^= y >> 18
+	y ^= (y << 15) & 0xefc60000
+	// Invert y ^= (y << 7) & mask, 7 bits at a time
+	t := y
+	for i := 0; i < 4; i++ {
+		t = y ^ ((t << 7) & 0x9d2c5680)
+	}
+	y = t
+	// Invert y ^= y >> 11
+	t = y
+	for i := 0; i < 2; i++ {
+		t = y ^ (t >> 11)
+	}
+	return t
+}
+
+// NewMT19937Stream returns the output stream of MT19937 seeded with init_genrand(seed),
+// as used by the reference implementation and C++ std::mt19937.
+func NewMT19937Stream(seed uint32) func() uint32 {
+	var state [mtN]uint32
+	state[0] = seed
+	for i := 1; i < mtN; i++ {
+		state[i] = 1812433253*(state[i-1]^(state[i-1]>>30)) + uint32(i)
+	}
+	idx := mtN
+	return func() uint32 {
+		if idx >= mtN {
+			for k := 0; k < mtN; k++ {
+				y := (state[k] & mtUpperMask) | (state[(k+1)%mtN] & mtLowerMask)
+				x := state[(k+mtM)%mtN] ^ (y >> 1)
+				if y&1 != 0 {
+					x ^= mtMatrixA
+				}
+				state[k] = x
+			}
+			idx = 0
+		}
+		y := state[idx]
+		idx++
+		return mtTemper(y)
+	}
+}
+
+type xorshift struct {
+	bits int
+}
+
+// Xorshift32 returns a model of Marsaglia's xorshift32 (shifts 13, 17, 5).
+func Xorshift32() Generator {
+	return xorshift{bits: 32}
+}
+
+// Xorshift64 returns a model of Marsaglia's xorshift64 (shifts 13, 7, 17).
+func Xorshift64() Generator {
+	return xorshift{bits: 64}
+}
+
+func (g xorshift) Name() string {
+	if g.bits == 32 {
+		return "xorshift32"
+	}
+	return "xorshift64"
+}
+
+func (g xorshift) WordBits() int { return g.bits }
+
+// Predict steps the generator once from the previous output (the output is the state).
+func (g xorshift) Predict(seq []Word, i int) (uint64, bool) {
+	if i < 1 || !seq[i-1].Known {
+		return 0, false
+	}
+	return g.step(seq[i-1].Value), true
+}
+
+func (g xorshift) step(x uint64) uint64 {
+	if g.bits == 32 {
+		y := uint32(x)
+		y ^= y << 13
+		y ^= y >> 17
+		y ^= y << 5
+		return uint64(y)
+	}
+	x ^= x << 13
+	x ^= x >> 7
+	x ^= x << 17
+	return x
+}
+
+// Go math/rand rngSource parameters.
+const (
+	goRandLen = 607
+	goRandTap = 273
+)
+
+type goMathRand struct {
+	bits int
+}
+
+// GoMathRand returns a model of Go's math/rand source observed through Uint64.
+func GoMathRand() Generator {
+	return goMathRand{bits: 64}
+}
+
+// GoMathRandInt63 returns a model of Go's math/rand source observed through Int63,
+// which discards the top bit of every output.
+func GoMathRandInt63() Generator {
+	return goMathRand{bits: 63}
+}
+
+func (g goMathRand) Name() string {
+	if g.bits == 63 {
+		return "go_math_rand_int63"
+	}
+	return "go_math_rand"
+}
+
+func (g goMathRand) WordBits() int { return g.bits }
+
+// Predict implements the additive lagged Fibonacci recurrence o[n] = o[n-607] + o[n-273].
+func (g goMathRand) Predict(seq []Word, i int) (uint64, bool) {
+	if i < goRandLen {
+		return 0, false
+	}
+	a, b := seq[i-goRandLen], seq[i-goRandTap]
+	if !a.Known || !b.Known {
+		return 0, false
+	}
+	return (a.Value + b.Value) & wordMask(g.bits), true
+}
+
+// Generators returns all built-in generator models.
+func Generators() []Generator {
+	return []Generator{MT19937(), Xorshift32(), Xorshift64(), GoMathRand(), GoMathRandInt63()}
+}
+
+func wordMask(bits int) uint64 {
+	if bits >= 64 {
+		return ^uint64(0)
+	}
+	return (uint64(1) << uint(bits)) - 1
+}
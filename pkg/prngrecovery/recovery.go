@@ -0,0 +1,195 @@
+package prngrecovery
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInconsistent is returned when the observed outputs contradict the generator model.
+var ErrInconsistent = errors.New("observed outputs are inconsistent with generator")
+
+// Word is a single generator output. Known is false for outputs that were not
+// observed (or are unreliable) and must be reconstructed.
+type Word struct {
+	Value uint64
+	Known bool
+}
+
+// Layout describes how a flawed signer assembles a nonce from generator outputs.
+type Layout struct {
+	// WordBits is the width of each generator output (32, 63 or 64)
+	WordBits int
+
+	// WordsPerNonce is the number of consecutive outputs consumed per nonce
+	WordsPerNonce int
+
+	// MostSignificantFirst is true when the first output forms the top bits of the nonce
+	// (default false: least significant word first, like Python's getrandbits)
+	MostSignificantFirst bool
+
+	// TruncatedHighWords marks the top words of every nonce as unknown, e.g. when the
+	// signer masked off high bits to fit the nonce below the curve order
+	TruncatedHighWords int
+
+	// Order, if set, reduces predicted nonces modulo the curve order
+	Order *big.Int
+}
+
+// DefaultLayout returns the layout of Python's random.getrandbits(256):
+// eight 32-bit MT19937 outputs, least significant word first.
+func DefaultLayout() Layout {
+	return Layout{
+		WordBits:      32,
+		WordsPerNonce: 8,
+	}
+}
+
+// Match describes a generator model that explains the observed nonces.
+type Match struct {
+	Generator string // Generator name
+	Verified  int    // Number of observed words independently predicted by the model
+}
+
+// SplitNonces converts consecutive nonces into the generator output sequence they came from.
+func SplitNonces(layout Layout, nonces []*big.Int) ([]Word, error) {
+	if layout.WordBits <= 0 || layout.WordBits > 64 {
+		return nil, fmt.Errorf("invalid word size: %d bits", layout.WordBits)
+	}
+	if layout.WordsPerNonce <= 0 {
+		return nil, fmt.Errorf("invalid words per nonce: %d", layout.WordsPerNonce)
+	}
+
+	mask := new(big.Int).SetUint64(wordMask(layout.WordBits))
+	words := make([]Word, 0, len(nonces)*layout.WordsPerNonce)
+	for _, k := range nonces {
+		// Least significant word first
+		chunk := make([]Word, layout.WordsPerNonce)
+		v := new(big.Int).Set(k)
+		for w := 0; w < layout.WordsPerNonce; w++ {
+			chunk[w] = Word{
+				Value: new(big.Int).And(v, mask).Uint64(),
+				Known: w < layout.WordsPerNonce-layout.TruncatedHighWords,
+			}
+			v.Rsh(v, uint(layout.WordBits))
+		}
+		if layout.MostSignificantFirst {
+			for l, r := 0, len(chunk)-1; l < r; l, r = l+1, r-1 {
+				chunk[l], chunk[r] = chunk[r], chunk[l]
+			}
+		}
+		words = append(words, chunk...)
+	}
+	return words, nil
+}
+
+// JoinNonces converts a generator output sequence back into nonces (inverse of SplitNonces).
+// Every word must be known.
+func JoinNonces(layout Layout, words []Word) ([]*big.Int, error) {
+	if layout.WordsPerNonce <= 0 || len(words)%layout.WordsPerNonce != 0 {
+		return nil, fmt.Errorf("word count %d is not a multiple of %d", len(words), layout.WordsPerNonce)
+	}
+
+	nonces := make([]*big.Int, 0, len(words)/layout.WordsPerNonce)
+	for start := 0; start < len(words); start += layout.WordsPerNonce {
+		chunk := words[start : start+layout.WordsPerNonce]
+		k := new(big.Int)
+		for w := 0; w < layout.WordsPerNonce; w++ {
+			// Walk from the most significant word down
+			idx := layout.WordsPerNonce - 1 - w
+			if layout.MostSignificantFirst {
+				idx = w
+			}
+			if !chunk[idx].Known {
+				return nil, fmt.Errorf("word %d is unknown", start+idx)
+			}
+			k.Lsh(k, uint(layout.WordBits))
+			k.Or(k, new(big.Int).SetUint64(chunk[idx].Value))
+		}
+		if layout.Order != nil {
+			k.Mod(k, layout.Order)
+		}
+		nonces = append(nonces, k)
+	}
+	return nonces, nil
+}
+
+// Reconstruct fills unknown words in seq using the generator recurrence and extends the
+// sequence by extra outputs. It returns the completed sequence and the number of observed
+// words that the model predicted independently (a measure of confidence).
+//
+// ErrInconsistent is returned if any prediction contradicts an observed word.
+func Reconstruct(gen Generator, seq []Word, extra int) ([]Word, int, error) {
+	out := make([]Word, len(seq), len(seq)+extra)
+	copy(out, seq)
+	for i := 0; i < extra; i++ {
+		out = append(out, Word{})
+	}
+
+	verified := 0
+	for i := range out {
+		v, ok := gen.Predict(out, i)
+		if !ok {
+			continue
+		}
+		if out[i].Known {
+			if out[i].Value != v {
+				return nil, verified, fmt.Errorf("%w: %s mismatch at output %d", ErrInconsistent, gen.Name(), i)
+			}
+			verified++
+			continue
+		}
+		out[i] = Word{Value: v, Known: true}
+	}
+	return out, verified, nil
+}
+
+// PredictNonces clones the generator from consecutive observed nonces and returns the
+// next count nonces it will produce.
+func PredictNonces(gen Generator, layout Layout, nonces []*big.Int, count int) ([]*big.Int, error) {
+	if gen.WordBits() != layout.WordBits {
+		return nil, fmt.Errorf("%s produces %d-bit words, layout expects %d", gen.Name(), gen.WordBits(), layout.WordBits)
+	}
+	words, err := SplitNonces(layout, nonces)
+	if err != nil {
+		return nil, err
+	}
+
+	full, _, err := Reconstruct(gen, words, count*layout.WordsPerNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	predicted, err := JoinNonces(layout, full[len(words):])
+	if err != nil {
+		return nil, fmt.Errorf("not enough observations to clone %s: %w", gen.Name(), err)
+	}
+	return predicted, nil
+}
+
+// Detect tests every generator matching the layout's word size against the observed
+// nonces and returns those that explain them. A generator only matches if it predicted
+// at least one observed word independently; with too few observations nothing matches.
+func Detect(layout Layout, nonces []*big.Int, generators ...Generator) []Match {
+	if len(generators) == 0 {
+		generators = Generators()
+	}
+
+	words, err := SplitNonces(layout, nonces)
+	if err != nil {
+		return nil
+	}
+
+	var matches []Match
+	for _, gen := range generators {
+		if gen.WordBits() != layout.WordBits {
+			continue
+		}
+		_, verified, err := Reconstruct(gen, words, 0)
+		if err != nil || verified == 0 {
+			continue
+		}
+		matches = append(matches, Match{Generator: gen.Name(), Verified: verified})
+	}
+	return matches
+}
@@ -0,0 +1,170 @@
+package prngrecovery
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// noncesFromWords assembles nonces from a stream of words (least significant word first).
+func noncesFromWords(next func() uint64, wordBits, wordsPerNonce, count int) []*big.Int {
+	nonces := make([]*big.Int, count)
+	for i := range nonces {
+		k := new(big.Int)
+		for w := 0; w < wordsPerNonce; w++ {
+			word := new(big.Int).SetUint64(next())
+			k.Or(k, word.Lsh(word, uint(w*wordBits)))
+		}
+		nonces[i] = k
+	}
+	return nonces
+}
+
+func TestMT19937Stream_ReferenceOutput(t *testing.T) {
+	// First output of the reference implementation with init_genrand(5489)
+	next := NewMT19937Stream(5489)
+	if got := next(); got != 3499211612 {
+		t.Errorf("Expected 3499211612, got %d", got)
+	}
+}
+
+func TestMTUntemper(t *testing.T) {
+	for _, y := range []uint32{0, 1, 0xdeadbeef, 0xffffffff, 0x80000000} {
+		if got := mtUntemper(mtTemper(y)); got != y {
+			t.Errorf("untemper(temper(%#x)) = %#x", y, got)
+		}
+	}
+}
+
+func TestPredictNonces_MT19937(t *testing.T) {
+	mt := NewMT19937Stream(1234)
+	next := func() uint64 { return uint64(mt()) }
+
+	layout := DefaultLayout()
+	// 78 nonces * 8 words = 624 outputs, exactly one MT state
+	observed := noncesFromWords(next, 32, 8, 78)
+	expected := noncesFromWords(next, 32, 8, 5)
+
+	predicted, err := PredictNonces(MT19937(), layout, observed, 5)
+	if err != nil {
+		t.Fatalf("PredictNonces: %v", err)
+	}
+	for i := range expected {
+		if predicted[i].Cmp(expected[i]) != 0 {
+			t.Errorf("Nonce %d mismatch: got %s, expected %s", i, predicted[i].Text(16), expected[i].Text(16))
+		}
+	}
+}
+
+func TestPredictNonces_MT19937_NotEnoughObservations(t *testing.T) {
+	mt := NewMT19937Stream(1)
+	next := func() uint64 { return uint64(mt()) }
+	observed := noncesFromWords(next, 32, 8, 10)
+
+	if _, err := PredictNonces(MT19937(), DefaultLayout(), observed, 1); err == nil {
+		t.Error("Expected error with fewer than 624 observed outputs")
+	}
+}
+
+func TestPredictNonces_GoMathRand(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	layout := Layout{WordBits: 64, WordsPerNonce: 4}
+
+	observed := noncesFromWords(rng.Uint64, 64, 4, 152) // 608 outputs
+	expected := noncesFromWords(rng.Uint64, 64, 4, 3)
+
+	predicted, err := PredictNonces(GoMathRand(), layout, observed, 3)
+	if err != nil {
+		t.Fatalf("PredictNonces: %v", err)
+	}
+	for i := range expected {
+		if predicted[i].Cmp(expected[i]) != 0 {
+			t.Errorf("Nonce %d mismatch", i)
+		}
+	}
+}
+
+func TestPredictNonces_Xorshift64(t *testing.T) {
+	gen := Xorshift64().(xorshift)
+	state := uint64(88172645463325252)
+	next := func() uint64 {
+		state = gen.step(state)
+		return state
+	}
+	layout := Layout{WordBits: 64, WordsPerNonce: 4, MostSignificantFirst: false}
+
+	observed := noncesFromWords(next, 64, 4, 1)
+	expected := noncesFromWords(next, 64, 4, 2)
+
+	predicted, err := PredictNonces(Xorshift64(), layout, observed, 2)
+	if err != nil {
+		t.Fatalf("PredictNonces: %v", err)
+	}
+	for i := range expected {
+		if predicted[i].Cmp(expected[i]) != 0 {
+			t.Errorf("Nonce %d mismatch", i)
+		}
+	}
+}
+
+func TestSplitJoinNonces_RoundTrip(t *testing.T) {
+	k, _ := new(big.Int).SetString("c0ffee0123456789abcdef0123456789abcdef0123456789abcdef0123456789", 16)
+	for _, msf := range []bool{false, true} {
+		layout := Layout{WordBits: 32, WordsPerNonce: 8, MostSignificantFirst: msf}
+		words, err := SplitNonces(layout, []*big.Int{k})
+		if err != nil {
+			t.Fatalf("SplitNonces: %v", err)
+		}
+		joined, err := JoinNonces(layout, words)
+		if err != nil {
+			t.Fatalf("JoinNonces: %v", err)
+		}
+		if joined[0].Cmp(k) != 0 {
+			t.Errorf("Round trip mismatch (MostSignificantFirst=%v): got %s", msf, joined[0].Text(16))
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	mt := NewMT19937Stream(99)
+	next := func() uint64 { return uint64(mt()) }
+	nonces := noncesFromWords(next, 32, 8, 90)
+
+	matches := Detect(DefaultLayout(), nonces)
+	if len(matches) != 1 || matches[0].Generator != "mt19937" {
+		t.Fatalf("Expected only mt19937 to match, got %+v", matches)
+	}
+	if matches[0].Verified == 0 {
+		t.Error("Expected verified words")
+	}
+}
+
+func TestDetect_TruncatedHighWords(t *testing.T) {
+	mt := NewMT19937Stream(7)
+	next := func() uint64 { return uint64(mt()) }
+	nonces := noncesFromWords(next, 32, 8, 100)
+
+	// Mask the top word of every nonce, as a signer keeping nonces below the order might
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 224), big.NewInt(1))
+	for _, k := range nonces {
+		k.And(k, mask)
+	}
+
+	layout := DefaultLayout()
+	layout.TruncatedHighWords = 1
+	matches := Detect(layout, nonces)
+	if len(matches) != 1 || matches[0].Generator != "mt19937" {
+		t.Fatalf("Expected mt19937 to match truncated nonces, got %+v", matches)
+	}
+}
+
+func TestDetect_Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	nonces := make([]*big.Int, 100)
+	for i := range nonces {
+		nonces[i] = new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	if matches := Detect(DefaultLayout(), nonces); len(matches) != 0 {
+		t.Errorf("Expected no matches for unrelated nonces, got %+v", matches)
+	}
+}
@@ -0,0 +1,14 @@
+// Package seal encrypts recovery results so recovered keys never reach terminals, CI
+// logs or tickets in plaintext during responsible disclosure.
+//
+// Sealed results are age files (https://age-encryption.org), encrypted either to an
+// X25519 recipient or with a passphrase (scrypt), and ASCII-armored so they can be
+// pasted into an email or issue:
+//
+//	identity, recipient, _ := seal.GenerateIdentity()   // recipient keeps identity secret
+//	sealed, _ := seal.ToRecipient(resultJSON, recipient)
+//	plaintext, _ := seal.Open(sealed, identity, nil)
+//
+// Identities and recipients are age keys, so results can also be sealed to an existing
+// age recipient and opened with the age command-line tool ("age -d -i identity.txt").
+package seal
@@ -0,0 +1,142 @@
+package seal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	// IdentityPrefix and RecipientPrefix mark age X25519 identities and recipients
+	IdentityPrefix  = "AGE-SECRET-KEY-1"
+	RecipientPrefix = "age1"
+
+	// PassphraseWorkFactor is the scrypt work factor (log2 N) for passphrase sealing,
+	// age's default
+	PassphraseWorkFactor = 18
+
+	// MaxWorkFactor is the largest scrypt work factor Open accepts. The work factor is
+	// read from the sealed data, so without a bound a crafted file could make Open spend
+	// minutes and gigabytes deriving a key.
+	MaxWorkFactor = 20
+)
+
+// ErrDecrypt is returned by Open when the identity or passphrase does not open the
+// sealed data.
+var ErrDecrypt = errors.New("seal: decryption failed (wrong identity or passphrase)")
+
+// GenerateIdentity creates an age X25519 identity. The identity decrypts; the recipient
+// is given to whoever runs the recovery.
+func GenerateIdentity() (identity, recipient string, err error) {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", "", err
+	}
+	return key.String(), key.Recipient().String(), nil
+}
+
+// Recipient returns the recipient for an identity.
+func Recipient(identity string) (string, error) {
+	key, err := parseIdentity(identity)
+	if err != nil {
+		return "", err
+	}
+	return key.Recipient().String(), nil
+}
+
+// ToRecipient seals plaintext so only the holder of recipient's identity can open it.
+func ToRecipient(plaintext []byte, recipient string) ([]byte, error) {
+	key, err := age.ParseX25519Recipient(strings.TrimSpace(recipient))
+	if err != nil {
+		return nil, fmt.Errorf("seal: invalid recipient: %w", err)
+	}
+	return encrypt(plaintext, key)
+}
+
+// WithPassphrase seals plaintext under a passphrase, with scrypt at PassphraseWorkFactor.
+func WithPassphrase(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("seal: empty passphrase")
+	}
+	key, err := age.NewScryptRecipient(string(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	key.SetWorkFactor(PassphraseWorkFactor)
+	return encrypt(plaintext, key)
+}
+
+// Open decrypts sealed data, armored or binary, with identity (for recipient-sealed
+// data) or passphrase. Passphrase-sealed data with a work factor above MaxWorkFactor is
+// rejected before any key is derived.
+func Open(sealed []byte, identity string, passphrase []byte) ([]byte, error) {
+	var key age.Identity
+	switch {
+	case identity != "":
+		x25519, err := parseIdentity(identity)
+		if err != nil {
+			return nil, err
+		}
+		key = x25519
+	case len(passphrase) > 0:
+		scrypt, err := age.NewScryptIdentity(string(passphrase))
+		if err != nil {
+			return nil, err
+		}
+		scrypt.SetMaxWorkFactor(MaxWorkFactor)
+		key = scrypt
+	default:
+		return nil, errors.New("seal: an identity or a passphrase is required")
+	}
+
+	var src io.Reader = bytes.NewReader(sealed)
+	if trimmed := bytes.TrimLeft(sealed, " \t\r\n"); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(trimmed))
+	}
+	r, err := age.Decrypt(src, key)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrDecrypt
+	}
+	if err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	return plaintext, nil
+}
+
+// encrypt encrypts plaintext to recipient as an armored age file.
+func encrypt(plaintext []byte, recipient age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armored.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func parseIdentity(identity string) (*age.X25519Identity, error) {
+	key, err := age.ParseX25519Identity(strings.TrimSpace(identity))
+	if err != nil {
+		return nil, fmt.Errorf("seal: invalid identity: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,122 @@
+package seal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+var testResult = []byte(`{"private_key_hex":"0xdeadbeef","a":"1","b":"12345"}`)
+
+func TestToRecipient(t *testing.T) {
+	identity, recipient, err := GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity failed: %v", err)
+	}
+	if r, _ := Recipient(identity); r != recipient {
+		t.Errorf("Recipient(identity) = %s, expected %s", r, recipient)
+	}
+
+	sealed, err := ToRecipient(testResult, recipient)
+	if err != nil {
+		t.Fatalf("ToRecipient failed: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("deadbeef")) {
+		t.Fatal("Sealed output contains the plaintext key")
+	}
+
+	plaintext, err := Open(sealed, identity, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !bytes.Equal(plaintext, testResult) {
+		t.Errorf("Expected %s, got %s", testResult, plaintext)
+	}
+
+	other, _, _ := GenerateIdentity()
+	if _, err := Open(sealed, other, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt with the wrong identity, got %v", err)
+	}
+	if _, err := Open(sealed, "", []byte("passphrase")); err == nil {
+		t.Error("Expected an error without an identity")
+	}
+}
+
+func TestWithPassphrase(t *testing.T) {
+	sealed, err := WithPassphrase(testResult, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("WithPassphrase failed: %v", err)
+	}
+	plaintext, err := Open(sealed, "", []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !bytes.Equal(plaintext, testResult) {
+		t.Errorf("Expected %s, got %s", testResult, plaintext)
+	}
+	if _, err := Open(sealed, "", []byte("battery staple")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt with the wrong passphrase, got %v", err)
+	}
+}
+
+func TestOpen_Modified(t *testing.T) {
+	identity, recipient, _ := GenerateIdentity()
+	sealed, _ := ToRecipient(testResult, recipient)
+	binary := dearmor(t, sealed)
+	binary[len(binary)-1] ^= 1
+	if _, err := Open(binary, identity, nil); err == nil {
+		t.Error("Expected modified data to be rejected")
+	}
+}
+
+// TestOpen_WorkFactor raises the scrypt work factor recorded in a passphrase-sealed
+// result past MaxWorkFactor; Open must refuse it rather than derive the key.
+func TestOpen_WorkFactor(t *testing.T) {
+	sealed, _ := WithPassphrase(testResult, []byte("correct horse"))
+	binary := dearmor(t, sealed)
+	stanza := []byte(" " + strconv.Itoa(PassphraseWorkFactor) + "\n")
+	if !bytes.Contains(binary, stanza) {
+		t.Fatalf("No scrypt stanza with work factor %d in:\n%s", PassphraseWorkFactor, binary)
+	}
+	raised := bytes.Replace(binary, stanza, []byte(" 30\n"), 1)
+	_, err := Open(raised, "", []byte("correct horse"))
+	if err == nil || errors.Is(err, ErrDecrypt) || !strings.Contains(err.Error(), "work factor") {
+		t.Errorf("Expected a work factor error, got %v", err)
+	}
+}
+
+// TestAgeCompatible opens a sealed result with the age library directly.
+func TestAgeCompatible(t *testing.T) {
+	identity, recipient, _ := GenerateIdentity()
+	if !strings.HasPrefix(identity, IdentityPrefix) || !strings.HasPrefix(recipient, RecipientPrefix) {
+		t.Fatalf("Unexpected key formats %q, %q", identity, recipient)
+	}
+	sealed, _ := ToRecipient(testResult, recipient)
+	key, err := age.ParseX25519Identity(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(sealed)), key)
+	if err != nil {
+		t.Fatalf("age.Decrypt failed: %v", err)
+	}
+	if plaintext, _ := io.ReadAll(r); !bytes.Equal(plaintext, testResult) {
+		t.Errorf("Expected %s, got %s", testResult, plaintext)
+	}
+}
+
+// dearmor returns the binary age file of an armored one.
+func dearmor(t *testing.T, armored []byte) []byte {
+	t.Helper()
+	binary, err := io.ReadAll(armor.NewReader(bytes.NewReader(armored)))
+	if err != nil {
+		t.Fatalf("Sealed data is not armored: %v", err)
+	}
+	return binary
+}
@@ -0,0 +1,15 @@
+// Package tablefile stores precomputed discrete-log tables on disk so repeated campaigns
+// do not pay the precomputation cost on every run.
+//
+// A table is a versioned header followed by fixed-width (key, value) entries sorted by
+// key. Lookups binary-search the entries in place, so on Unix systems a table is
+// memory-mapped rather than read into the heap; a multi-gigabyte baby-step table is
+// usable immediately after Open.
+//
+// The header records the table kind, the curve and the parameters the table was built
+// with (for example the BSGS bound, or the kangaroo interval and jump set), so loaders
+// can reject a table that does not match the search they are about to run.
+//
+// Used by pkg/ecdsaaffine and pkg/eddsaaffine for BSGS baby-step tables and kangaroo
+// tame distinguished points.
+package tablefile
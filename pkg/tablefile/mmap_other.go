@@ -0,0 +1,15 @@
+//go:build !unix
+
+package tablefile
+
+import "os"
+
+// MapFile reads path into memory on platforms without mmap support.
+// The returned function is a no-op.
+func MapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
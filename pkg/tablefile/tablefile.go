@@ -0,0 +1,200 @@
+package tablefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Version is the current file format version.
+const Version = 1
+
+// Kind identifies what a table contains.
+type Kind uint32
+
+// Table kinds.
+const (
+	KindBabySteps           Kind = 1 // BSGS baby steps: key -> j
+	KindDistinguishedPoints Kind = 2 // Kangaroo tame distinguished points: key -> log
+)
+
+const (
+	magic      = "AFFNTBL\x00"
+	curveLen   = 16
+	numParams  = 4
+	headerSize = len(magic) + 4 + 4 + curveLen + 8*numParams + 8
+	entrySize  = 16
+)
+
+// ErrMismatch is returned when a table's header does not match what the caller expects.
+var ErrMismatch = errors.New("table does not match requested parameters")
+
+// Header describes a table.
+type Header struct {
+	Kind   Kind
+	Curve  string           // e.g. "secp256k1" or "ed25519" (at most 16 bytes)
+	Params [numParams]int64 // Kind-specific build parameters
+}
+
+// Entry is one (key, value) pair.
+type Entry struct {
+	Key   uint64
+	Value int64
+}
+
+// Write stores entries under header at path. Entries are sorted by key in place;
+// duplicate keys keep the first occurrence after sorting.
+func Write(path string, header Header, entries []Entry) error {
+	if len(header.Curve) > curveLen {
+		return fmt.Errorf("curve name %q longer than %d bytes", header.Curve, curveLen)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	unique := entries[:0]
+	for i, e := range entries {
+		if i > 0 && e.Key == entries[i-1].Key {
+			continue
+		}
+		unique = append(unique, e)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+
+	buf := make([]byte, headerSize)
+	copy(buf, magic)
+	off := len(magic)
+	binary.LittleEndian.PutUint32(buf[off:], Version)
+	binary.LittleEndian.PutUint32(buf[off+4:], uint32(header.Kind))
+	copy(buf[off+8:], header.Curve)
+	off += 8 + curveLen
+	for i, p := range header.Params {
+		binary.LittleEndian.PutUint64(buf[off+8*i:], uint64(p))
+	}
+	binary.LittleEndian.PutUint64(buf[off+8*numParams:], uint64(len(unique)))
+	if _, err := w.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+
+	entry := make([]byte, entrySize)
+	for _, e := range unique {
+		binary.LittleEndian.PutUint64(entry, e.Key)
+		binary.LittleEndian.PutUint64(entry[8:], uint64(e.Value))
+		if _, err := w.Write(entry); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Table is an opened table file. Entries stay in the mapped file; call Close when done.
+type Table struct {
+	Header Header
+
+	data    []byte // entries only
+	count   int
+	release func() error
+}
+
+// Open maps the table at path and validates its header.
+func Open(path string) (*Table, error) {
+	data, release, err := MapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parse(data)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t.release = release
+	return t, nil
+}
+
+// OpenExpect opens the table at path and checks its kind, curve and parameters.
+func OpenExpect(path string, expected Header) (*Table, error) {
+	t, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if t.Header != expected {
+		t.Close()
+		return nil, fmt.Errorf("%s: %w (have %+v, want %+v)", path, ErrMismatch, t.Header, expected)
+	}
+	return t, nil
+}
+
+func parse(data []byte) (*Table, error) {
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, errors.New("not a table file")
+	}
+	off := len(magic)
+	if v := binary.LittleEndian.Uint32(data[off:]); v != Version {
+		return nil, fmt.Errorf("unsupported table version %d", v)
+	}
+	var h Header
+	h.Kind = Kind(binary.LittleEndian.Uint32(data[off+4:]))
+	h.Curve = string(bytes.TrimRight(data[off+8:off+8+curveLen], "\x00"))
+	off += 8 + curveLen
+	for i := range h.Params {
+		h.Params[i] = int64(binary.LittleEndian.Uint64(data[off+8*i:]))
+	}
+	count := binary.LittleEndian.Uint64(data[off+8*numParams:])
+
+	entries := data[headerSize:]
+	// Compare without multiplying first: count*entrySize can wrap around to the file size
+	if count > uint64(len(entries))/entrySize || uint64(len(entries)) != count*entrySize {
+		return nil, fmt.Errorf("truncated table: header says %d entries, file holds %d bytes", count, len(entries))
+	}
+	return &Table{Header: h, data: entries, count: int(count)}, nil
+}
+
+// Len returns the number of entries.
+func (t *Table) Len() int {
+	return t.count
+}
+
+// Entry returns the i-th entry in key order.
+func (t *Table) Entry(i int) Entry {
+	e := t.data[i*entrySize : (i+1)*entrySize]
+	return Entry{
+		Key:   binary.LittleEndian.Uint64(e),
+		Value: int64(binary.LittleEndian.Uint64(e[8:])),
+	}
+}
+
+// Lookup returns the value stored for key.
+func (t *Table) Lookup(key uint64) (int64, bool) {
+	i := sort.Search(t.count, func(i int) bool {
+		return binary.LittleEndian.Uint64(t.data[i*entrySize:]) >= key
+	})
+	if i < t.count {
+		if e := t.Entry(i); e.Key == key {
+			return e.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Close releases the mapping.
+func (t *Table) Close() error {
+	if t.release == nil {
+		return nil
+	}
+	err := t.release()
+	t.release = nil
+	t.data = nil
+	return err
+}
@@ -0,0 +1,89 @@
+package tablefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOpen_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table.bin")
+	header := Header{Kind: KindBabySteps, Curve: "secp256k1", Params: [4]int64{1 << 20, 1449}}
+	entries := []Entry{{Key: 42, Value: 7}, {Key: 3, Value: -1}, {Key: 1 << 63, Value: 99}, {Key: 42, Value: 8}}
+
+	if err := Write(path, header, entries); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	table, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer table.Close()
+
+	if table.Header != header {
+		t.Errorf("Header mismatch: got %+v", table.Header)
+	}
+	if table.Len() != 3 {
+		t.Errorf("Expected 3 unique entries, got %d", table.Len())
+	}
+	for key, want := range map[uint64]int64{3: -1, 42: 7, 1 << 63: 99} {
+		if got, ok := table.Lookup(key); !ok || got != want {
+			t.Errorf("Lookup(%d) = %d, %v; want %d", key, got, ok, want)
+		}
+	}
+	if _, ok := table.Lookup(5); ok {
+		t.Error("Expected missing key")
+	}
+}
+
+func TestOpenExpect_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table.bin")
+	header := Header{Kind: KindDistinguishedPoints, Curve: "ed25519", Params: [4]int64{0, 1 << 30, 2, 5}}
+	if err := Write(path, header, nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	other := header
+	other.Params[1] = 1 << 31
+	if _, err := OpenExpect(path, other); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Expected ErrMismatch, got %v", err)
+	}
+	table, err := OpenExpect(path, header)
+	if err != nil {
+		t.Fatalf("OpenExpect: %v", err)
+	}
+	table.Close()
+}
+
+func TestOpen_NotATable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "garbage.bin")
+	if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("Expected error for non-table file")
+	}
+}
+
+func TestOpen_CountOverflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table.bin")
+	if err := Write(path, Header{Kind: KindBabySteps, Curve: "secp256k1"}, []Entry{{Key: 1, Value: 1}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2^60 + 1 entries of 16 bytes wrap around to the 16 bytes the file holds
+	binary.LittleEndian.PutUint64(data[headerSize-8:], 1<<60+1)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if table, err := Open(path); err == nil {
+		table.Close()
+		t.Error("Expected error for an entry count larger than the file")
+	}
+}
@@ -0,0 +1,18 @@
+// Package testvectors holds fixed key recovery test vectors for secp256k1 ECDSA and
+// Ed25519, embedded in the binary so recovery can be checked without fixture files.
+//
+// Each Vector is a short signature set from one key whose nonces follow a known affine
+// relationship k2 = a*k1 + b (or none, for a vector that must not be recovered), with
+// the key and the pair the relationship holds on:
+//
+//	for _, v := range testvectors.ECDSA() {
+//		path, _ := v.WriteSignatures(dir)
+//		result, _ := client.RecoverKey(ctx, path, v.PublicKey)
+//		// result.PrivateKey should equal v.Key()
+//	}
+//
+// The vectors were generated once with pkg/flawedsigner and are never regenerated
+// casually: "go test ./pkg/testvectors -update" rewrites them, and the package tests
+// fail if the generator no longer reproduces them byte for byte. Like flawedsigner, the
+// package does not import the recovery packages, so it can serve their tests.
+package testvectors
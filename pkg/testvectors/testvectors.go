@@ -0,0 +1,73 @@
+package testvectors
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+//go:embed vectors/*.json
+var files embed.FS
+
+// Vector is one test vector.
+type Vector struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// PrivateKey is the key recovery must return, hex: d for ECDSA, the signing scalar a
+	// (not the seed) for Ed25519
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"` // compressed secp256k1 or 32-byte Ed25519 key, hex
+
+	// Recoverable is false for vectors whose nonces are unrelated; A, B and Pair are then zero
+	Recoverable bool   `json:"recoverable"`
+	A           int64  `json:"a"`
+	B           int64  `json:"b"`
+	Pair        [2]int `json:"pair"` // signatures the relationship holds on
+
+	// Signatures in the JSON signature file format the curve's JSONParser reads
+	Signatures json.RawMessage `json:"signatures"`
+}
+
+// Key returns PrivateKey as an integer.
+func (v *Vector) Key() *big.Int {
+	key, ok := new(big.Int).SetString(v.PrivateKey, 0)
+	if !ok {
+		panic(fmt.Sprintf("testvectors: %s: invalid private_key %q", v.Name, v.PrivateKey))
+	}
+	return key
+}
+
+// WriteSignatures writes the vector's signature file to dir as <name>.json and returns
+// its path.
+func (v *Vector) WriteSignatures(dir string) (string, error) {
+	path := filepath.Join(dir, v.Name+".json")
+	return path, os.WriteFile(path, v.Signatures, 0o644)
+}
+
+// ECDSA returns the secp256k1 ECDSA vectors.
+func ECDSA() []Vector {
+	return mustLoad("vectors/ecdsa.json")
+}
+
+// EdDSA returns the Ed25519 vectors.
+func EdDSA() []Vector {
+	return mustLoad("vectors/eddsa.json")
+}
+
+// mustLoad decodes an embedded vector file. The files are fixed and checked by the
+// package tests, so a decoding error is a bug.
+func mustLoad(name string) []Vector {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	var vectors []Vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		panic(fmt.Sprintf("testvectors: %s: %v", name, err))
+	}
+	return vectors
+}
@@ -0,0 +1,170 @@
+package testvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/flawedsigner"
+)
+
+var update = flag.Bool("update", false, "regenerate vectors/*.json")
+
+// vectorSet describes one vector: the nonce sequence its three signatures use and the
+// relationship between consecutive nonces.
+type vectorSet struct {
+	name, description string
+	nonces            func(r io.Reader, order *big.Int) (flawedsigner.Nonces, error)
+	a, b              int64
+	recoverable       bool
+}
+
+func affine(a, b int64) func(io.Reader, *big.Int) (flawedsigner.Nonces, error) {
+	return func(r io.Reader, _ *big.Int) (flawedsigner.Nonces, error) {
+		return flawedsigner.Affine(r, big.NewInt(a), big.NewInt(b))
+	}
+}
+
+var sets = []vectorSet{
+	{name: "same_nonce", description: "One nonce reused for every signature", nonces: affine(1, 0), a: 1, b: 0, recoverable: true},
+	{name: "counter", description: "Nonce incremented by one", nonces: affine(1, 1), a: 1, b: 1, recoverable: true},
+	{name: "step_1000", description: "Nonce incremented by a fixed step", nonces: affine(1, 1000), a: 1, b: 1000, recoverable: true},
+	{name: "affine_2x_plus_1", description: "k2 = 2*k1 + 1", nonces: affine(2, 1), a: 2, b: 1, recoverable: true},
+	{name: "affine_3x_minus_5", description: "Negative offset, outside the common patterns", nonces: affine(3, -5), a: 3, b: -5, recoverable: true},
+	{name: "negated", description: "k2 = -k1, the nonce of the opposite point", nonces: affine(-1, 0), a: -1, b: 0, recoverable: true},
+	{
+		name:        "wrap",
+		description: "k1 = n-1, so 2*k1 + 5 wraps around the group order to 3",
+		nonces: func(_ io.Reader, order *big.Int) (flawedsigner.Nonces, error) {
+			return flawedsigner.AffineFrom(new(big.Int).Sub(order, big.NewInt(1)), big.NewInt(2), big.NewInt(5)), nil
+		},
+		a: 2, b: 5, recoverable: true,
+	},
+	{
+		name:        "random",
+		description: "Independent random nonces; no key must be recovered",
+		nonces: func(r io.Reader, _ *big.Int) (flawedsigner.Nonces, error) {
+			return flawedsigner.Random(r), nil
+		},
+	},
+}
+
+// generate signs every set with one key per curve, from a fixed seed.
+func generate() (ecdsaVectors, eddsaVectors []Vector, err error) {
+	r := flawedsigner.NewSeededReader(1883)
+	ecdsaKey, err := flawedsigner.NewECDSAKey(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	eddsaKey, err := flawedsigner.NewEdDSAKey(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vector := func(set vectorSet, key *big.Int, pub []byte, signatures any) (Vector, error) {
+		data, err := json.Marshal(signatures)
+		if err != nil {
+			return Vector{}, err
+		}
+		v := Vector{
+			Name:        set.name,
+			Description: set.description,
+			PrivateKey:  fmt.Sprintf("0x%064x", key),
+			PublicKey:   hex.EncodeToString(pub),
+			Recoverable: set.recoverable,
+			Signatures:  data,
+		}
+		if set.recoverable {
+			v.A, v.B, v.Pair = set.a, set.b, [2]int{0, 1}
+		}
+		return v, nil
+	}
+
+	for _, set := range sets {
+		nonces, err := set.nonces(r, flawedsigner.Secp256k1Order)
+		if err != nil {
+			return nil, nil, err
+		}
+		signatures, err := ecdsaKey.Sign(flawedsigner.ECDSAMessages(3), nonces)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", set.name, err)
+		}
+		v, err := vector(set, ecdsaKey.D, ecdsaKey.PublicKey(), signatures)
+		if err != nil {
+			return nil, nil, err
+		}
+		ecdsaVectors = append(ecdsaVectors, v)
+	}
+	for _, set := range sets {
+		nonces, err := set.nonces(r, flawedsigner.Ed25519Order)
+		if err != nil {
+			return nil, nil, err
+		}
+		signatures, err := eddsaKey.Sign(flawedsigner.EdDSAMessages(3), nonces)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", set.name, err)
+		}
+		v, err := vector(set, eddsaKey.Scalar, eddsaKey.Public, signatures)
+		if err != nil {
+			return nil, nil, err
+		}
+		eddsaVectors = append(eddsaVectors, v)
+	}
+	return ecdsaVectors, eddsaVectors, nil
+}
+
+func TestVectors_Reproducible(t *testing.T) {
+	ecdsaVectors, eddsaVectors, err := generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	for name, vectors := range map[string][]Vector{"ecdsa.json": ecdsaVectors, "eddsa.json": eddsaVectors} {
+		data, err := json.MarshalIndent(vectors, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, '\n')
+		if *update {
+			if err := os.WriteFile(filepath.Join("vectors", name), data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		embedded, err := files.ReadFile("vectors/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, embedded) {
+			t.Errorf("vectors/%s differs from the generator's output; run go test -update if the change is intended", name)
+		}
+	}
+}
+
+func TestVectors_Load(t *testing.T) {
+	for curve, vectors := range map[string][]Vector{"ECDSA": ECDSA(), "EdDSA": EdDSA()} {
+		if len(vectors) != len(sets) {
+			t.Errorf("%s: expected %d vectors, got %d", curve, len(sets), len(vectors))
+		}
+		for _, v := range vectors {
+			if v.Key().Sign() <= 0 || len(v.Signatures) == 0 {
+				t.Errorf("%s %s: missing key or signatures", curve, v.Name)
+			}
+		}
+	}
+
+	v := ECDSA()[0]
+	path, err := v.WriteSignatures(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, v.Signatures) {
+		t.Error("WriteSignatures wrote different signatures")
+	}
+}
@@ -0,0 +1,227 @@
+package vulnclass
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/nonceanalysis"
+)
+
+// SchemaVersion is the version of the Document layout.
+const SchemaVersion = "1.0.0"
+
+// Vulnerability kinds, used as the descriptor IDs.
+const (
+	KindNonceReuse   = "NONCE-REUSE"   // the same nonce signed two messages
+	KindCounterNonce = "COUNTER-NONCE" // nonces differ by a small constant or counter
+	KindAffineNonce  = "AFFINE-NONCE"  // nonces follow k[i+1] = a*k[i] + b
+	KindBiasedNonce  = "BIASED-NONCE"  // nonces are small (truncated or zero-padded)
+)
+
+// Severities, as in CVSS qualitative ratings.
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+)
+
+// Severity is an OSV severity entry.
+type Severity struct {
+	Type  string `json:"type"`  // always "qualitative"
+	Score string `json:"score"` // one of the Severity constants
+}
+
+// DatabaseSpecific carries the fields OSV has no place for.
+type DatabaseSpecific struct {
+	CWEIDs       []string `json:"cwe_ids"`
+	KeyRecovered bool     `json:"key_recovered"`        // the flaw was exploited to recover the key
+	Signatures   []int    `json:"signatures,omitempty"` // indices of the affected signatures
+	Remediation  string   `json:"remediation"`
+}
+
+// Vulnerability is one classified finding.
+type Vulnerability struct {
+	ID               string           `json:"id"` // one of the Kind constants
+	Summary          string           `json:"summary"`
+	Details          string           `json:"details"`
+	Severity         []Severity       `json:"severity"`
+	References       []string         `json:"references,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+// Document is the classification of one dataset.
+type Document struct {
+	SchemaVersion   string          `json:"schema_version"`
+	Generated       time.Time       `json:"generated"`
+	Dataset         string          `json:"dataset,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Evidence is what Classify works from. Every field is optional.
+type Evidence struct {
+	Dataset  string
+	Analysis *ecdsaaffine.DatasetAnalysis
+
+	// Result is the recovered key, and Nonces the nonces it gives, in dataset order
+	Result *ecdsaaffine.RecoveryResult
+	Nonces []*big.Int
+
+	Options nonceanalysis.Options // zero for nonceanalysis.DefaultOptions
+}
+
+// descriptor is the fixed text of a kind.
+type descriptor struct {
+	summary     string
+	cwe         []string
+	severity    string
+	remediation string
+}
+
+var descriptors = map[string]descriptor{
+	KindNonceReuse: {
+		summary:     "ECDSA nonce reused across signatures",
+		cwe:         []string{"CWE-323", "CWE-330"},
+		severity:    SeverityCritical,
+		remediation: "Generate nonces deterministically per RFC 6979 or from a CSPRNG for every signature. Rotate the key: anyone holding two signatures with the same r can compute it.",
+	},
+	KindCounterNonce: {
+		summary:     "ECDSA nonces generated by a counter",
+		cwe:         []string{"CWE-330", "CWE-340"},
+		severity:    SeverityCritical,
+		remediation: "Replace the counter with RFC 6979 deterministic nonces or a CSPRNG. Rotate the key: two signatures and a search over the step recover it.",
+	},
+	KindAffineNonce: {
+		summary:     "ECDSA nonces related by an affine recurrence",
+		cwe:         []string{"CWE-330", "CWE-338"},
+		severity:    SeverityCritical,
+		remediation: "Replace the nonce generator (an LCG or similar) with RFC 6979 deterministic nonces or a CSPRNG. Rotate the key: two signatures and the recurrence recover it.",
+	},
+	KindBiasedNonce: {
+		summary:     "ECDSA nonces with too few random bits",
+		cwe:         []string{"CWE-330", "CWE-331"},
+		severity:    SeverityHigh,
+		remediation: "Draw full-width nonces uniformly below the group order (or use RFC 6979). Rotate the key: lattice attacks recover it from enough signatures with biased nonces.",
+	},
+}
+
+var references = []string{
+	"https://cwe.mitre.org/data/definitions/330.html",
+	"https://www.rfc-editor.org/rfc/rfc6979",
+}
+
+// Classify maps the evidence to vulnerabilities: a repeated r in the analysis is nonce
+// reuse; the recovered key's relationship and the relationships of its nonces (see
+// nonceanalysis.Analyze) tell counters from general affine recurrences; and nonces below
+// the options' SmallBound are biased. Each kind is reported once, with the details and
+// affected signatures of all the evidence for it.
+func Classify(evidence Evidence) *Document {
+	doc := &Document{SchemaVersion: SchemaVersion, Generated: time.Now().UTC(), Dataset: evidence.Dataset, Vulnerabilities: []Vulnerability{}}
+	recovered := evidence.Result != nil
+	add := func(kind, details string, signatures []int) {
+		for i := range doc.Vulnerabilities {
+			if v := &doc.Vulnerabilities[i]; v.ID == kind {
+				v.Details += " " + details
+				v.DatabaseSpecific.Signatures = union(v.DatabaseSpecific.Signatures, signatures)
+				return
+			}
+		}
+		d := descriptors[kind]
+		doc.Vulnerabilities = append(doc.Vulnerabilities, Vulnerability{
+			ID:         kind,
+			Summary:    d.summary,
+			Details:    details,
+			Severity:   []Severity{{Type: "qualitative", Score: d.severity}},
+			References: references,
+			DatabaseSpecific: DatabaseSpecific{
+				CWEIDs:       d.cwe,
+				KeyRecovered: recovered,
+				Signatures:   signatures,
+				Remediation:  d.remediation,
+			},
+		})
+	}
+
+	if a := evidence.Analysis; a != nil && a.DuplicateR > 0 {
+		add(KindNonceReuse, fmt.Sprintf("%d signatures repeat the r value of an earlier signature by the same signer.", a.DuplicateR), nil)
+	}
+
+	// A key from one signature's known nonce (pair i, i) says nothing about the generator
+	if res := evidence.Result; res != nil && res.SignaturePair[0] != res.SignaturePair[1] && res.Relationship.A != nil && res.Relationship.B != nil {
+		a, b := res.Relationship.A, res.Relationship.B
+		pair := []int{res.SignaturePair[0], res.SignaturePair[1]}
+		relation := fmt.Sprintf("The key was recovered from signatures %d and %d with k2 = %s*k1 + %s.", pair[0], pair[1], a, b)
+		switch {
+		case a.Cmp(big.NewInt(1)) == 0 && b.Sign() == 0:
+			add(KindNonceReuse, relation, pair)
+		case a.Cmp(big.NewInt(1)) == 0:
+			add(KindCounterNonce, relation, pair)
+		default:
+			add(KindAffineNonce, relation, pair)
+		}
+	}
+
+	if len(evidence.Nonces) > 0 {
+		opts := evidence.Options
+		if opts.SmallBound == nil {
+			opts = nonceanalysis.DefaultOptions()
+		}
+		report := nonceanalysis.Analyze(evidence.Nonces, ecdsaaffine.Secp256k1CurveOrder, opts)
+		exposure := nonceanalysis.Scope(evidence.Nonces, nil, ecdsaaffine.Secp256k1CurveOrder, opts)
+		weak := weakSignatures(exposure)
+		switch report.Pattern {
+		case nonceanalysis.PatternSameNonce:
+			add(KindNonceReuse, "All nonces are the same.", weak)
+		case nonceanalysis.PatternConstantStep, nonceanalysis.PatternResettingCounter, nonceanalysis.PatternPerSessionSeed:
+			add(KindCounterNonce, fmt.Sprintf("The nonces follow the %s pattern (step %s).", report.Pattern, report.Step), weak)
+		case nonceanalysis.PatternAffine:
+			add(KindAffineNonce, fmt.Sprintf("The nonces follow k[i+1] = %s*k[i] + %s.", report.A, report.B), weak)
+		}
+		if small := smallNonces(evidence.Nonces, opts.SmallBound); len(small) > 0 {
+			add(KindBiasedNonce, fmt.Sprintf("%d of %d nonces are at most %d bits.", len(small), len(evidence.Nonces), opts.SmallBound.BitLen()), small)
+		}
+	}
+	return doc
+}
+
+// union returns the sorted indices in a or b.
+func union(a, b []int) []int {
+	seen := make(map[int]bool)
+	var out []int
+	for _, i := range append(append([]int(nil), a...), b...) {
+		if !seen[i] {
+			seen[i] = true
+			out = append(out, i)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+func weakSignatures(exposure *nonceanalysis.Exposure) []int {
+	var weak []int
+	for _, f := range exposure.Findings {
+		if f.Weak {
+			weak = append(weak, f.Index)
+		}
+	}
+	return weak
+}
+
+// smallNonces returns the indices of the nonces k with |k| <= bound (k centered mod n).
+func smallNonces(nonces []*big.Int, bound *big.Int) []int {
+	n := ecdsaaffine.Secp256k1CurveOrder
+	half := new(big.Int).Rsh(n, 1)
+	var small []int
+	for i, k := range nonces {
+		c := new(big.Int).Mod(k, n)
+		if c.Cmp(half) > 0 {
+			c.Sub(c, n)
+		}
+		if c.CmpAbs(bound) <= 0 {
+			small = append(small, i)
+		}
+	}
+	return small
+}
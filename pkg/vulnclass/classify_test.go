@@ -0,0 +1,104 @@
+package vulnclass
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mahdiidarabi/ecdsa-affine/pkg/ecdsaaffine"
+)
+
+// sequence returns count nonces from start with k[i+1] = a*k[i] + b.
+func sequence(start *big.Int, a, b int64, count int) []*big.Int {
+	nonces := []*big.Int{start}
+	for len(nonces) < count {
+		k := new(big.Int).Mul(nonces[len(nonces)-1], big.NewInt(a))
+		k.Add(k, big.NewInt(b))
+		nonces = append(nonces, k.Mod(k, ecdsaaffine.Secp256k1CurveOrder))
+	}
+	return nonces
+}
+
+func result(a, b int64, pair [2]int) *ecdsaaffine.RecoveryResult {
+	return &ecdsaaffine.RecoveryResult{
+		PrivateKey:    big.NewInt(1),
+		Relationship:  ecdsaaffine.AffineRelationship{A: big.NewInt(a), B: big.NewInt(b)},
+		SignaturePair: pair,
+	}
+}
+
+func ids(doc *Document) []string {
+	var out []string
+	for _, v := range doc.Vulnerabilities {
+		out = append(out, v.ID)
+	}
+	return out
+}
+
+func TestClassify(t *testing.T) {
+	large, _ := new(big.Int).SetString("8f3a2b1c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a", 16)
+	tests := []struct {
+		name     string
+		evidence Evidence
+		want     []string
+	}{
+		{"nothing", Evidence{Analysis: &ecdsaaffine.DatasetAnalysis{Signatures: 10}}, nil},
+		{"repeated r", Evidence{Analysis: &ecdsaaffine.DatasetAnalysis{DuplicateR: 2}}, []string{KindNonceReuse}},
+		{"same nonce key", Evidence{Result: result(1, 0, [2]int{0, 3})}, []string{KindNonceReuse}},
+		{"counter", Evidence{Result: result(1, 1, [2]int{0, 1}), Nonces: sequence(large, 1, 1, 4)}, []string{KindCounterNonce}},
+		{"affine", Evidence{Result: result(3, 7, [2]int{0, 1}), Nonces: sequence(large, 3, 7, 4)}, []string{KindAffineNonce}},
+		{"small counter", Evidence{Result: result(1, 5, [2]int{0, 1}), Nonces: sequence(big.NewInt(1000), 1, 5, 3)}, []string{KindCounterNonce, KindBiasedNonce}},
+		{"known nonce", Evidence{Result: result(1, 0, [2]int{2, 2})}, nil},
+	}
+	for _, tt := range tests {
+		doc := Classify(tt.evidence)
+		if got := ids(doc); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClassify_MergesEvidence(t *testing.T) {
+	large, _ := new(big.Int).SetString("77777777777777777777777777777777777777777777777777777777777777", 16)
+	doc := Classify(Evidence{Result: result(1, 2, [2]int{1, 2}), Nonces: sequence(large, 1, 2, 4)})
+	if len(doc.Vulnerabilities) != 1 {
+		t.Fatalf("Expected one vulnerability, got %v", ids(doc))
+	}
+	v := doc.Vulnerabilities[0]
+	if !reflect.DeepEqual(v.DatabaseSpecific.Signatures, []int{0, 1, 2, 3}) {
+		t.Errorf("Expected all four signatures affected, got %v", v.DatabaseSpecific.Signatures)
+	}
+	if !strings.Contains(v.Details, "k2 = 1*k1 + 2") || !strings.Contains(v.Details, "constant_step") {
+		t.Errorf("Expected the details of both findings, got %q", v.Details)
+	}
+	if !v.DatabaseSpecific.KeyRecovered || v.Severity[0].Score != SeverityCritical {
+		t.Errorf("Expected a critical, exploited finding, got %+v", v)
+	}
+}
+
+func TestDocument_JSON(t *testing.T) {
+	doc := Classify(Evidence{Dataset: "signatures.json", Analysis: &ecdsaaffine.DatasetAnalysis{DuplicateR: 1}})
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	vulns := decoded["vulnerabilities"].([]interface{})
+	v := vulns[0].(map[string]interface{})
+	if v["id"] != KindNonceReuse || decoded["schema_version"] != SchemaVersion {
+		t.Errorf("Unexpected document: %s", data)
+	}
+	specific := v["database_specific"].(map[string]interface{})
+	if cwe := specific["cwe_ids"].([]interface{}); cwe[0] != "CWE-323" || specific["key_recovered"] != false {
+		t.Errorf("Unexpected database_specific: %v", specific)
+	}
+
+	// No findings is an empty list, not null
+	data, _ = json.Marshal(Classify(Evidence{}))
+	if !strings.Contains(string(data), `"vulnerabilities":[]`) {
+		t.Errorf("Expected an empty list: %s", data)
+	}
+}
@@ -0,0 +1,20 @@
+// Package vulnclass classifies the nonce flaws found in a signature dataset as
+// structured vulnerability descriptors (an identifier, CWE ids, severity, the affected
+// signatures and remediation text), written as JSON for ingestion into vulnerability
+// management systems. The layout follows OSV where it fits: id, summary, details,
+// severity and references, with the CWE ids under database_specific.
+//
+// # Quick Start
+//
+//	nonces, _ := ecdsaaffine.RecoverNonces(result, signatures)
+//	doc := vulnclass.Classify(vulnclass.Evidence{
+//		Dataset:  "signatures.json",
+//		Analysis: ecdsaaffine.AnalyzeDataset(signatures),
+//		Result:   result,
+//		Nonces:   nonces,
+//	})
+//	json.NewEncoder(os.Stdout).Encode(doc)
+//
+// Without a recovered key only reused nonces can be seen (a repeated r); the nonces the
+// key gives show counters, affine recurrences and biased (small) nonces as well.
+package vulnclass